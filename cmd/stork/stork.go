@@ -10,6 +10,8 @@ import (
 	"strings"
 	"syscall"
 	"time"
+	// Timezones in schedule policies shouldn't depend on the image
+	_ "time/tzdata"
 
 	stork_driver "github.com/libopenstorage/stork/drivers"
 	"github.com/libopenstorage/stork/drivers/volume"
@@ -27,6 +29,7 @@ import (
 	"github.com/libopenstorage/stork/pkg/extender"
 	"github.com/libopenstorage/stork/pkg/groupsnapshot"
 	"github.com/libopenstorage/stork/pkg/k8sutils"
+	"github.com/libopenstorage/stork/pkg/keyprovider"
 	"github.com/libopenstorage/stork/pkg/metrics"
 	"github.com/libopenstorage/stork/pkg/migration"
 	"github.com/libopenstorage/stork/pkg/migration/controllers"
@@ -127,6 +130,11 @@ func main() {
 			Name:  "extender",
 			Usage: "Enable scheduler extender for hyperconvergence (default: true)",
 		},
+		cli.StringFlag{
+			Name:  "scoring-policy-namespace",
+			Value: "kube-system",
+			Usage: "Namespace of the stork-scoring-policies ConfigMap used by the scheduler extender",
+		},
 		cli.BoolTFlag{
 			Name:  "health-monitor",
 			Usage: "Enable health monitoring of the storage driver (default: true)",
@@ -136,6 +144,20 @@ func main() {
 			Value: 120,
 			Usage: "The interval in seconds to monitor the health of the storage driver (min: 30)",
 		},
+		cli.BoolFlag{
+			Name:  "health-monitor-volumes",
+			Usage: "Evict pods using volumes that are unavailable or detached if the storage driver reports volume health",
+		},
+		cli.Int64Flag{
+			Name:  "health-monitor-evictions-per-minute",
+			Value: 30,
+			Usage: "The maximum number of pods evicted across the cluster every minute when the storage driver goes offline",
+		},
+		cli.Int64Flag{
+			Name:  "health-monitor-node-grace-period",
+			Value: 0,
+			Usage: "The minimum time in seconds the storage driver on a node has to be offline before pods are evicted from it",
+		},
 		cli.BoolTFlag{
 			Name:  "migration-controller",
 			Usage: "Start the migration controller (default: true)",
@@ -158,6 +180,10 @@ func main() {
 			Value: defaultAdminNamespace,
 			Usage: "Namespace to be used by a cluster admin which can migrate all other namespaces (Deprecated, please use admin-namespace)",
 		},
+		cli.StringFlag{
+			Name:  "kms-vault-transit-path",
+			Usage: "Mount path of the Vault transit secrets engine to register as the \"vault\" KMS for backup encryption keys. Vault is configured with the VAULT_* environment variables (default: disabled)",
+		},
 		cli.BoolTFlag{
 			Name:  "cluster-domain-controllers",
 			Usage: "Start the cluster domain controllers (default: true)",
@@ -276,6 +302,12 @@ func run(c *cli.Context) {
 		log.SetLevel(log.DebugLevel)
 	}
 
+	if transitPath := c.String("kms-vault-transit-path"); transitPath != "" {
+		if err := keyprovider.RegisterVaultKMS(transitPath); err != nil {
+			log.Fatalf("Error registering vault kms: %v", err)
+		}
+	}
+
 	config, err := rest.InClusterConfig()
 	if err != nil {
 		log.Fatalf("Error getting cluster config: %v", err)
@@ -331,8 +363,9 @@ func run(c *cli.Context) {
 
 		if c.Bool("extender") {
 			ext = &extender.Extender{
-				Driver:   d,
-				Recorder: recorder,
+				Driver:                 d,
+				Recorder:               recorder,
+				ScoringPolicyNamespace: c.String("scoring-policy-namespace"),
 			}
 
 			if err = ext.Start(); err != nil {
@@ -341,9 +374,11 @@ func run(c *cli.Context) {
 		}
 		if c.Bool("webhook-controller") {
 			webhook = &webhookadmission.Controller{
-				Driver:       d,
-				Recorder:     recorder,
-				SkipResource: c.String("webhook-skip-resources-annotation"),
+				Driver:         d,
+				Recorder:       recorder,
+				SkipResource:   c.String("webhook-skip-resources-annotation"),
+				KubeClient:     k8sClient,
+				AdminNamespace: getAdminNamespace(c),
 			}
 			if err := webhook.Start(); err != nil {
 				log.Fatalf("error starting webhook controller: %v", err)
@@ -352,7 +387,7 @@ func run(c *cli.Context) {
 	}
 
 	runFunc := func(context.Context) {
-		runStork(mgr, d, recorder, c)
+		runStork(mgr, d, k8sClient, recorder, c)
 	}
 
 	if c.BoolT("leader-elect") {
@@ -403,7 +438,7 @@ func displayLeader(name string) {
 	log.Infof("new leader detected, current leader: %s", name)
 }
 
-func runStork(mgr manager.Manager, d volume.Driver, recorder record.EventRecorder, c *cli.Context) {
+func runStork(mgr manager.Manager, d volume.Driver, k8sClient clientset.Interface, recorder record.EventRecorder, c *cli.Context) {
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
 
@@ -420,15 +455,16 @@ func runStork(mgr manager.Manager, d volume.Driver, recorder record.EventRecorde
 	if err := resourceCollector.Init(nil); err != nil {
 		log.Fatalf("Error initializing ResourceCollector: %v", err)
 	}
-	adminNamespace := c.String("admin-namespace")
-	if adminNamespace == "" {
-		adminNamespace = c.String("migration-admin-namespace")
-	}
+	adminNamespace := getAdminNamespace(c)
 
 	monitor := &monitor.Monitor{
-		Driver:      d,
-		IntervalSec: c.Int64("health-monitor-interval"),
-		Recorder:    recorder,
+		Driver:             d,
+		IntervalSec:        c.Int64("health-monitor-interval"),
+		Recorder:           recorder,
+		KubeClient:         k8sClient,
+		EvictionsPerMinute: c.Int64("health-monitor-evictions-per-minute"),
+		NodeGracePeriodSec: c.Int64("health-monitor-node-grace-period"),
+		VolumeHealthCheck:  c.Bool("health-monitor-volumes"),
 	}
 	snapshot := &snapshot.Snapshot{
 		Driver:   d,
@@ -561,3 +597,13 @@ func runStork(mgr manager.Manager, d volume.Driver, recorder record.EventRecorde
 	}
 	os.Exit(0)
 }
+
+// getAdminNamespace returns the namespace for admin resources, falling back
+// to the deprecated migration-admin-namespace flag
+func getAdminNamespace(c *cli.Context) string {
+	adminNamespace := c.String("admin-namespace")
+	if adminNamespace == "" {
+		adminNamespace = c.String("migration-admin-namespace")
+	}
+	return adminNamespace
+}
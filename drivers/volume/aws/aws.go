@@ -70,6 +70,7 @@ type aws struct {
 	storkvolume.ClusterDomainsNotSupported
 	storkvolume.CloneNotSupported
 	storkvolume.SnapshotRestoreNotSupported
+	storkvolume.VolumeHealthNotSupported
 }
 
 func (a *aws) Init(_ interface{}) error {
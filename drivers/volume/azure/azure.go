@@ -59,6 +59,7 @@ type azure struct {
 	storkvolume.ClusterDomainsNotSupported
 	storkvolume.CloneNotSupported
 	storkvolume.SnapshotRestoreNotSupported
+	storkvolume.VolumeHealthNotSupported
 }
 
 type azureSession struct {
@@ -0,0 +1,339 @@
+package csi
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	kSnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
+	kSnapshotv1beta1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1beta1"
+	storkvolume "github.com/libopenstorage/stork/drivers/volume"
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/log"
+	"github.com/libopenstorage/stork/pkg/snapshotter"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// Volumes are cloned by provisioning a PVC in the source namespace from a
+// VolumeSnapshot of the volume, or from the PVC itself if the driver doesn't
+// support snapshots or doesn't have a snapshot class. The PV that is provisioned for it is then
+// pre-bound to the PVC in the destination namespace, which is created when
+// the resources are cloned.
+//
+// Volumes that use a WaitForFirstConsumer storage class can't be cloned since
+// the clone would only be provisioned once a pod uses it. Volumes are failed if
+// the provisioner doesn't support the data source or if they haven't been
+// provisioned within cloneVolumeTimeout of the creation of the
+// ApplicationClone.
+
+const (
+	// snapshotClonePrefix is the prefix for the snapshots and PVCs used to
+	// clone volumes
+	snapshotClonePrefix = "clone"
+	// cloneReclaimPolicyAnnotation is used to store the reclaim policy of a
+	// cloned PV while the PVC used to provision it is deleted
+	cloneReclaimPolicyAnnotation = "stork.libopenstorage.org/clone-reclaim-policy"
+	// cloneVolumeTimeout is the time after the creation of an
+	// ApplicationClone after which volumes that haven't been provisioned are
+	// failed
+	cloneVolumeTimeout = 30 * time.Minute
+	// provisioningFailedReason is the reason of the events that the CSI
+	// provisioner records for a PVC when it fails to provision it
+	provisioningFailedReason = "ProvisioningFailed"
+)
+
+// CreateVolumeClones clones the volumes for the ApplicationClone. The clones
+// are created asynchronously, so this is called until all the volumes are
+// either successful or have failed. Errors are recorded in the status of the
+// volumes so that the progress made for the other volumes is saved.
+func (c *csi) CreateVolumeClones(clone *storkapi.ApplicationClone) error {
+	for _, vInfo := range clone.Status.Volumes {
+		if vInfo.Status == storkapi.ApplicationCloneStatusSuccessful ||
+			vInfo.Status == storkapi.ApplicationCloneStatusFailed {
+			continue
+		}
+		if err := c.cloneVolume(clone, vInfo); err != nil {
+			log.ApplicationCloneLog(clone).Warnf("Error cloning volume %v: %v", vInfo.Volume, err)
+			vInfo.Reason = fmt.Sprintf("Error cloning volume: %v", err)
+		}
+		// Only the PV needs to be bound once it has been provisioned, so that
+		// is retried until it succeeds
+		if vInfo.Status != storkapi.ApplicationCloneStatusSuccessful &&
+			vInfo.Status != storkapi.ApplicationCloneStatusFailed &&
+			!vInfo.PersistentVolumeCreated &&
+			time.Now().After(clone.CreationTimestamp.Add(cloneVolumeTimeout)) {
+			vInfo.Status = storkapi.ApplicationCloneStatusFailed
+			vInfo.Reason = fmt.Sprintf("Timed out after %v: %v", cloneVolumeTimeout, vInfo.Reason)
+		}
+		if vInfo.Status == storkapi.ApplicationCloneStatusFailed {
+			c.cleanupVolumeClone(clone, vInfo)
+		}
+	}
+	return nil
+}
+
+func (c *csi) getCloneName(clone *storkapi.ApplicationClone, pvc *v1.PersistentVolumeClaim) string {
+	return fmt.Sprintf("%s-%s-%s", snapshotClonePrefix, getUIDLastSection(clone.UID), getUIDLastSection(pvc.UID))
+}
+
+// getCloneSnapshotClass returns the VolumeSnapshotClass used to clone the
+// volume, or an empty name if the volume should be cloned from its PVC. The
+// default snapshot class is created for every driver, so it is only used if
+// the driver supports snapshots and the class is for the driver of the volume.
+// A snapshot class set on the ApplicationClone is always used.
+func (c *csi) getCloneSnapshotClass(clone *storkapi.ApplicationClone, pv *v1.PersistentVolume) (string, error) {
+	if snapshotClassName, ok := clone.Annotations[optCSISnapshotClassName]; ok {
+		return snapshotClassName, nil
+	}
+	if storkvolume.IsCSIDriverWithoutSnapshotSupport(pv) {
+		return "", nil
+	}
+	snapshotClassName := c.getDefaultSnapshotClassName(pv.Spec.CSI.Driver)
+	snapshotClass, err := c.getVolumeSnapshotClass(snapshotClassName)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	var driverName string
+	switch class := snapshotClass.(type) {
+	case *kSnapshotv1.VolumeSnapshotClass:
+		driverName = class.Driver
+	case *kSnapshotv1beta1.VolumeSnapshotClass:
+		driverName = class.Driver
+	}
+	if driverName != pv.Spec.CSI.Driver {
+		return "", nil
+	}
+	return snapshotClassName, nil
+}
+
+func (c *csi) cloneVolume(clone *storkapi.ApplicationClone, vInfo *storkapi.ApplicationCloneVolumeInfo) error {
+	pvc, err := core.Instance().GetPersistentVolumeClaim(vInfo.PersistentVolumeClaim, clone.Spec.SourceNamespace)
+	if err != nil {
+		return err
+	}
+	// The PV has already been provisioned, it only needs to be bound to the
+	// PVC in the destination namespace
+	if vInfo.PersistentVolumeCreated {
+		return c.bindCloneVolume(clone, vInfo, pvc)
+	}
+
+	pv, err := core.Instance().GetPersistentVolume(vInfo.Volume)
+	if err != nil {
+		return err
+	}
+	if pv.Spec.CSI == nil {
+		vInfo.Status = storkapi.ApplicationCloneStatusFailed
+		vInfo.Reason = fmt.Sprintf("PV %v isn't a CSI volume", pv.Name)
+		return nil
+	}
+	if isWaitingForFirstConsumer(pvc) {
+		vInfo.Status = storkapi.ApplicationCloneStatusFailed
+		vInfo.Reason = fmt.Sprintf("Volumes with a %v storage class can't be cloned", storagev1.VolumeBindingWaitForFirstConsumer)
+		return nil
+	}
+
+	name := c.getCloneName(clone, pvc)
+	dataSource := &v1.TypedLocalObjectReference{
+		Kind: "PersistentVolumeClaim",
+		Name: pvc.Name,
+	}
+	snapshotClassName, err := c.getCloneSnapshotClass(clone, pv)
+	if err != nil {
+		return err
+	}
+	if snapshotClassName != "" {
+		if _, _, _, err := c.snapshotter.CreateSnapshot(
+			snapshotter.Name(name),
+			snapshotter.PVCName(pvc.Name),
+			snapshotter.PVCNamespace(pvc.Namespace),
+			snapshotter.SnapshotClassName(snapshotClassName),
+		); err != nil {
+			return err
+		}
+		vInfo.VolumeSnapshot = name
+
+		snapshotInfo, err := c.snapshotter.SnapshotStatus(name, pvc.Namespace)
+		if err != nil {
+			return err
+		}
+		switch snapshotInfo.Status {
+		case snapshotter.StatusFailed:
+			vInfo.Status = storkapi.ApplicationCloneStatusFailed
+			vInfo.Reason = fmt.Sprintf("Volume snapshot failed: %v", snapshotInfo.Reason)
+			return nil
+		case snapshotter.StatusInProgress:
+			vInfo.Reason = fmt.Sprintf("Volume snapshot in progress: %v", snapshotInfo.Reason)
+			return nil
+		}
+		snapshotAPIGroup := kSnapshotv1.GroupName
+		dataSource = &v1.TypedLocalObjectReference{
+			APIGroup: &snapshotAPIGroup,
+			Kind:     "VolumeSnapshot",
+			Name:     name,
+		}
+	}
+
+	// Only the fields used for provisioning are copied from the PVC so that
+	// its volume name, selector and data sources aren't used for the clone
+	clonePVC := &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: pvc.Namespace,
+		},
+		Spec: v1.PersistentVolumeClaimSpec{
+			AccessModes:      pvc.Spec.AccessModes,
+			Resources:        *pvc.Spec.Resources.DeepCopy(),
+			StorageClassName: pvc.Spec.StorageClassName,
+			VolumeMode:       pvc.Spec.VolumeMode,
+			DataSource:       dataSource,
+		},
+	}
+	if sc, ok := pvc.Annotations[v1.BetaStorageClassAnnotation]; ok {
+		clonePVC.Annotations = map[string]string{v1.BetaStorageClassAnnotation: sc}
+	}
+	if _, err := core.Instance().CreatePersistentVolumeClaim(clonePVC); err != nil && !k8s_errors.IsAlreadyExists(err) {
+		return err
+	}
+
+	restoreInfo, err := c.snapshotter.RestoreStatus(name, pvc.Namespace)
+	if err != nil {
+		return err
+	}
+	switch restoreInfo.Status {
+	case snapshotter.StatusFailed:
+		vInfo.Status = storkapi.ApplicationCloneStatusFailed
+		vInfo.Reason = fmt.Sprintf("Volume clone failed: %v", restoreInfo.Reason)
+		return nil
+	case snapshotter.StatusReady:
+		// The PV is bound to the destination PVC the next time so that the
+		// name of the PV is saved before the PVC used to provision it is
+		// deleted
+		vInfo.CloneVolume = restoreInfo.VolumeName
+		vInfo.PersistentVolumeCreated = true
+		vInfo.Reason = fmt.Sprintf("Volume %v provisioned for clone", restoreInfo.VolumeName)
+		return nil
+	default:
+		// The provisioner doesn't retry data sources that aren't supported by
+		// the driver, so the volume would never be provisioned
+		if message := getUnsupportedDataSourceMessage(name, pvc.Namespace); message != "" {
+			vInfo.Status = storkapi.ApplicationCloneStatusFailed
+			vInfo.Reason = fmt.Sprintf("Volume clone failed: %v", message)
+			return nil
+		}
+		vInfo.Reason = fmt.Sprintf("Volume clone in progress: %v", restoreInfo.Reason)
+		return nil
+	}
+}
+
+// getUnsupportedDataSourceMessage returns the message of the event recorded by
+// the provisioner if it doesn't support the data source of the PVC
+func getUnsupportedDataSourceMessage(name, namespace string) string {
+	events, err := core.Instance().ListEvents(namespace, metav1.ListOptions{
+		FieldSelector: fields.Set{
+			"involvedObject.kind": "PersistentVolumeClaim",
+			"involvedObject.name": name,
+		}.String(),
+	})
+	if err != nil {
+		logrus.Debugf("Error listing events for PVC %v/%v: %v", namespace, name, err)
+		return ""
+	}
+	for _, event := range events.Items {
+		if event.InvolvedObject.Kind != "PersistentVolumeClaim" ||
+			event.InvolvedObject.Name != name ||
+			event.Reason != provisioningFailedReason {
+			continue
+		}
+		if strings.Contains(strings.ToLower(event.Message), "not support") {
+			return event.Message
+		}
+	}
+	return ""
+}
+
+// bindCloneVolume binds the cloned PV to the PVC in the destination
+// namespace. The PV is retained while the PVC used to provision it is
+// deleted, and its reclaim policy is restored once it has been pre-bound.
+func (c *csi) bindCloneVolume(
+	clone *storkapi.ApplicationClone,
+	vInfo *storkapi.ApplicationCloneVolumeInfo,
+	pvc *v1.PersistentVolumeClaim,
+) error {
+	pv, err := core.Instance().GetPersistentVolume(vInfo.CloneVolume)
+	if err != nil {
+		return err
+	}
+	if pv.Spec.ClaimRef == nil ||
+		pv.Spec.ClaimRef.Namespace != clone.Spec.DestinationNamespace ||
+		pv.Spec.ClaimRef.Name != vInfo.PersistentVolumeClaim {
+		if pv.Spec.PersistentVolumeReclaimPolicy != v1.PersistentVolumeReclaimRetain {
+			if pv.Annotations == nil {
+				pv.Annotations = make(map[string]string)
+			}
+			pv.Annotations[cloneReclaimPolicyAnnotation] = string(pv.Spec.PersistentVolumeReclaimPolicy)
+			pv.Spec.PersistentVolumeReclaimPolicy = v1.PersistentVolumeReclaimRetain
+			if pv, err = core.Instance().UpdatePersistentVolume(pv); err != nil {
+				return err
+			}
+		}
+
+		name := c.getCloneName(clone, pvc)
+		if err := core.Instance().DeletePersistentVolumeClaim(name, pvc.Namespace); err != nil && !k8s_errors.IsNotFound(err) {
+			return err
+		}
+
+		pv.Spec.ClaimRef = &v1.ObjectReference{
+			Kind:       "PersistentVolumeClaim",
+			APIVersion: "v1",
+			Namespace:  clone.Spec.DestinationNamespace,
+			Name:       vInfo.PersistentVolumeClaim,
+		}
+		if policy, ok := pv.Annotations[cloneReclaimPolicyAnnotation]; ok {
+			pv.Spec.PersistentVolumeReclaimPolicy = v1.PersistentVolumeReclaimPolicy(policy)
+			delete(pv.Annotations, cloneReclaimPolicyAnnotation)
+		}
+		// The PV would be released by the PV controller if it was marked as
+		// bound by the controller
+		delete(pv.Annotations, annPVBoundByController)
+		if _, err := core.Instance().UpdatePersistentVolume(pv); err != nil {
+			return err
+		}
+	}
+
+	if vInfo.VolumeSnapshot != "" {
+		if err := c.snapshotter.DeleteSnapshot(vInfo.VolumeSnapshot, pvc.Namespace, false); err != nil {
+			log.ApplicationCloneLog(clone).Warnf("Error deleting snapshot %v used to clone volume %v: %v", vInfo.VolumeSnapshot, vInfo.Volume, err)
+		}
+	}
+	vInfo.Status = storkapi.ApplicationCloneStatusSuccessful
+	vInfo.Reason = "Volume cloned successfully"
+	return nil
+}
+
+// cleanupVolumeClone deletes the snapshot and PVC used to clone a volume that
+// failed to be cloned
+func (c *csi) cleanupVolumeClone(clone *storkapi.ApplicationClone, vInfo *storkapi.ApplicationCloneVolumeInfo) {
+	if vInfo.VolumeSnapshot != "" {
+		if err := c.snapshotter.DeleteSnapshot(vInfo.VolumeSnapshot, clone.Spec.SourceNamespace, false); err != nil {
+			log.ApplicationCloneLog(clone).Warnf("Error deleting snapshot %v for failed clone of volume %v: %v", vInfo.VolumeSnapshot, vInfo.Volume, err)
+		}
+	}
+	pvc, err := core.Instance().GetPersistentVolumeClaim(vInfo.PersistentVolumeClaim, clone.Spec.SourceNamespace)
+	if err != nil {
+		log.ApplicationCloneLog(clone).Warnf("Error getting PVC %v for failed clone: %v", vInfo.PersistentVolumeClaim, err)
+		return
+	}
+	name := c.getCloneName(clone, pvc)
+	if err := c.snapshotter.CancelRestore(name, pvc.Namespace); err != nil && !k8s_errors.IsNotFound(err) {
+		log.ApplicationCloneLog(clone).Warnf("Error deleting PVC %v for failed clone of volume %v: %v", name, vInfo.Volume, err)
+	}
+}
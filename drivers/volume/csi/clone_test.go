@@ -0,0 +1,461 @@
+//go:build unittest
+// +build unittest
+
+package csi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+	"time"
+
+	kSnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
+	kSnapshotClient "github.com/kubernetes-csi/external-snapshotter/client/v4/clientset/versioned"
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/snapshotter"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	cloneSourceNamespace = "source"
+	cloneDestNamespace   = "dest"
+	cloneSCName          = "clone-sc"
+	cloneWFFCSCName      = "clone-wffc-sc"
+)
+
+// fakeCloneSnapshotter returns the configured statuses for the snapshots
+// and restores used to clone volumes
+type fakeCloneSnapshotter struct {
+	snapshotter.Driver
+	snapshotStatus   snapshotter.SnapshotInfo
+	restoreStatus    snapshotter.RestoreInfo
+	createdSnapshots []string
+	deletedSnapshots []string
+}
+
+func (f *fakeCloneSnapshotter) CreateSnapshot(opts ...snapshotter.Option) (string, string, string, error) {
+	o := snapshotter.Options{}
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return "", "", "", err
+		}
+	}
+	f.createdSnapshots = append(f.createdSnapshots, o.Name)
+	return o.Name, o.PVCNamespace, testDriver, nil
+}
+
+func (f *fakeCloneSnapshotter) SnapshotStatus(name, namespace string) (snapshotter.SnapshotInfo, error) {
+	return f.snapshotStatus, nil
+}
+
+func (f *fakeCloneSnapshotter) DeleteSnapshot(name, namespace string, retain bool) error {
+	f.deletedSnapshots = append(f.deletedSnapshots, name)
+	return nil
+}
+
+func (f *fakeCloneSnapshotter) RestoreStatus(pvcName, namespace string) (snapshotter.RestoreInfo, error) {
+	return f.restoreStatus, nil
+}
+
+func (f *fakeCloneSnapshotter) CancelRestore(pvcName, namespace string) error {
+	return core.Instance().DeletePersistentVolumeClaim(pvcName, namespace)
+}
+
+// newTestSnapshotClient returns a snapshot client for a server that only has
+// the given VolumeSnapshotClasses
+func newTestSnapshotClient(t *testing.T, classes ...string) *kSnapshotClient.Clientset {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		name := path.Base(r.URL.Path)
+		for _, class := range classes {
+			if class == name {
+				require.NoError(t, json.NewEncoder(w).Encode(&kSnapshotv1.VolumeSnapshotClass{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "VolumeSnapshotClass",
+						APIVersion: kSnapshotv1.SchemeGroupVersion.String(),
+					},
+					ObjectMeta:     metav1.ObjectMeta{Name: name},
+					Driver:         testDriver,
+					DeletionPolicy: kSnapshotv1.VolumeSnapshotContentDelete,
+				}))
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		status := k8s_errors.NewNotFound(kSnapshotv1.Resource("volumesnapshotclasses"), name).ErrStatus
+		status.TypeMeta = metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}
+		require.NoError(t, json.NewEncoder(w).Encode(&status))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := kSnapshotClient.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+	return client
+}
+
+func newTestCloneCSI(
+	t *testing.T,
+	fakeSnapshotter *fakeCloneSnapshotter,
+	snapshotClasses []string,
+	objects ...runtime.Object,
+) *csi {
+	wffc := storagev1.VolumeBindingWaitForFirstConsumer
+	objects = append(objects,
+		&storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: cloneSCName},
+			Provisioner: testDriver,
+		},
+		&storagev1.StorageClass{
+			ObjectMeta:        metav1.ObjectMeta{Name: cloneWFFCSCName},
+			Provisioner:       testDriver,
+			VolumeBindingMode: &wffc,
+		},
+	)
+	c := newTestCSI(t, objects...)
+	c.v1SnapshotRequired = true
+	c.snapshotClient = newTestSnapshotClient(t, snapshotClasses...)
+	c.snapshotter = fakeSnapshotter
+	return c
+}
+
+func newTestClonePVC(name, volumeName, storageClass string) *v1.PersistentVolumeClaim {
+	filesystem := v1.PersistentVolumeFilesystem
+	snapshotAPIGroup := kSnapshotv1.GroupName
+	return &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cloneSourceNamespace,
+			UID:       "pvc-uid-1234",
+		},
+		Spec: v1.PersistentVolumeClaimSpec{
+			AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("2Gi")},
+			},
+			StorageClassName: &storageClass,
+			VolumeMode:       &filesystem,
+			VolumeName:       volumeName,
+			Selector:         &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+			// The source was restored from a snapshot, which shouldn't be used
+			// for the clone
+			DataSource: &v1.TypedLocalObjectReference{
+				APIGroup: &snapshotAPIGroup,
+				Kind:     "VolumeSnapshot",
+				Name:     "old-snapshot",
+			},
+		},
+		Status: v1.PersistentVolumeClaimStatus{Phase: v1.ClaimBound},
+	}
+}
+
+func newTestApplicationClone(vInfo *storkapi.ApplicationCloneVolumeInfo) *storkapi.ApplicationClone {
+	return &storkapi.ApplicationClone{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "clone",
+			Namespace:         cloneSourceNamespace,
+			UID:               "clone-uid-5678",
+			CreationTimestamp: metav1.Now(),
+		},
+		Spec: storkapi.ApplicationCloneSpec{
+			SourceNamespace:      cloneSourceNamespace,
+			DestinationNamespace: cloneDestNamespace,
+		},
+		Status: storkapi.ApplicationCloneStatus{
+			Volumes: []*storkapi.ApplicationCloneVolumeInfo{vInfo},
+		},
+	}
+}
+
+func newTestCloneVolumeInfo() *storkapi.ApplicationCloneVolumeInfo {
+	return &storkapi.ApplicationCloneVolumeInfo{
+		PersistentVolumeClaim: "pvc",
+		Volume:                "pv",
+		Status:                storkapi.ApplicationCloneStatusInProgress,
+	}
+}
+
+func TestCloneVolumeFromSnapshot(t *testing.T) {
+	fakeSnapshotter := &fakeCloneSnapshotter{
+		snapshotStatus: snapshotter.SnapshotInfo{Status: snapshotter.StatusInProgress, Reason: "creating"},
+		restoreStatus:  snapshotter.RestoreInfo{Status: snapshotter.StatusInProgress, Reason: "PVC is pending"},
+	}
+	c := newTestCloneCSI(t, fakeSnapshotter, []string{snapshotClassNamePrefix + testDriver},
+		newTestPV("pv", testDriver),
+		newTestClonePVC("pvc", "pv", cloneSCName),
+	)
+	vInfo := newTestCloneVolumeInfo()
+	clone := newTestApplicationClone(vInfo)
+	name := "clone-5678-1234"
+
+	require.NoError(t, c.cloneVolume(clone, vInfo))
+	require.Equal(t, []string{name}, fakeSnapshotter.createdSnapshots)
+	require.Equal(t, name, vInfo.VolumeSnapshot)
+	require.Equal(t, storkapi.ApplicationCloneStatusInProgress, vInfo.Status)
+	require.Contains(t, vInfo.Reason, "Volume snapshot in progress")
+	_, err := core.Instance().GetPersistentVolumeClaim(name, cloneSourceNamespace)
+	require.True(t, k8s_errors.IsNotFound(err), "PVC shouldn't be created before the snapshot is ready")
+
+	fakeSnapshotter.snapshotStatus = snapshotter.SnapshotInfo{Status: snapshotter.StatusReady}
+	require.NoError(t, c.cloneVolume(clone, vInfo))
+	require.Equal(t, storkapi.ApplicationCloneStatusInProgress, vInfo.Status)
+	require.Contains(t, vInfo.Reason, "Volume clone in progress")
+	clonePVC, err := core.Instance().GetPersistentVolumeClaim(name, cloneSourceNamespace)
+	require.NoError(t, err)
+	require.NotNil(t, clonePVC.Spec.DataSource)
+	require.Equal(t, "VolumeSnapshot", clonePVC.Spec.DataSource.Kind)
+	require.Equal(t, name, clonePVC.Spec.DataSource.Name)
+	require.Equal(t, cloneSCName, *clonePVC.Spec.StorageClassName)
+	require.Equal(t, resource.MustParse("2Gi"), clonePVC.Spec.Resources.Requests[v1.ResourceStorage])
+	require.Empty(t, clonePVC.Spec.VolumeName)
+	require.Nil(t, clonePVC.Spec.Selector)
+
+	fakeSnapshotter.restoreStatus = snapshotter.RestoreInfo{Status: snapshotter.StatusReady, VolumeName: "clone-pv"}
+	require.NoError(t, c.cloneVolume(clone, vInfo))
+	require.Equal(t, storkapi.ApplicationCloneStatusInProgress, vInfo.Status)
+	require.True(t, vInfo.PersistentVolumeCreated)
+	require.Equal(t, "clone-pv", vInfo.CloneVolume)
+}
+
+func TestCloneVolumeFromPVC(t *testing.T) {
+	fakeSnapshotter := &fakeCloneSnapshotter{
+		restoreStatus: snapshotter.RestoreInfo{Status: snapshotter.StatusInProgress},
+	}
+	c := newTestCloneCSI(t, fakeSnapshotter, nil,
+		newTestPV("pv", testDriver),
+		newTestClonePVC("pvc", "pv", cloneSCName),
+	)
+	vInfo := newTestCloneVolumeInfo()
+	clone := newTestApplicationClone(vInfo)
+	name := "clone-5678-1234"
+
+	require.NoError(t, c.cloneVolume(clone, vInfo))
+	require.Empty(t, fakeSnapshotter.createdSnapshots, "Snapshot shouldn't be created without a snapshot class")
+	require.Empty(t, vInfo.VolumeSnapshot)
+	clonePVC, err := core.Instance().GetPersistentVolumeClaim(name, cloneSourceNamespace)
+	require.NoError(t, err)
+	require.Equal(t, &v1.TypedLocalObjectReference{Kind: "PersistentVolumeClaim", Name: "pvc"}, clonePVC.Spec.DataSource)
+
+	// The provisioner doesn't retry data sources it doesn't support
+	_, err = core.Instance().CreateEvent(&v1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: "event", Namespace: cloneSourceNamespace},
+		InvolvedObject: v1.ObjectReference{
+			Kind:      "PersistentVolumeClaim",
+			Name:      name,
+			Namespace: cloneSourceNamespace,
+		},
+		Reason:  provisioningFailedReason,
+		Message: "failed to provision volume: driver does not support cloning",
+		Type:    v1.EventTypeWarning,
+	})
+	require.NoError(t, err)
+	require.NoError(t, c.cloneVolume(clone, vInfo))
+	require.Equal(t, storkapi.ApplicationCloneStatusFailed, vInfo.Status)
+	require.Contains(t, vInfo.Reason, "does not support cloning")
+}
+
+func TestGetCloneSnapshotClass(t *testing.T) {
+	vSphereDriver := "csi.vsphere.vmware.com"
+	c := newTestCloneCSI(t, &fakeCloneSnapshotter{}, []string{
+		snapshotClassNamePrefix + testDriver,
+		snapshotClassNamePrefix + vSphereDriver,
+		snapshotClassNamePrefix + "other.csi.driver",
+	})
+	clone := newTestApplicationClone(newTestCloneVolumeInfo())
+
+	name, err := c.getCloneSnapshotClass(clone, newTestPV("pv", testDriver))
+	require.NoError(t, err)
+	require.Equal(t, snapshotClassNamePrefix+testDriver, name)
+
+	// The default snapshot class is also created for drivers that don't
+	// support snapshots
+	name, err = c.getCloneSnapshotClass(clone, newTestPV("pv", vSphereDriver))
+	require.NoError(t, err)
+	require.Empty(t, name, "Driver without snapshot support should be cloned from the PVC")
+
+	// The test snapshot classes are all for testDriver
+	name, err = c.getCloneSnapshotClass(clone, newTestPV("pv", "other.csi.driver"))
+	require.NoError(t, err)
+	require.Empty(t, name, "Snapshot class for another driver shouldn't be used")
+
+	name, err = c.getCloneSnapshotClass(clone, newTestPV("pv", "missing.csi.driver"))
+	require.NoError(t, err)
+	require.Empty(t, name, "Missing snapshot class shouldn't be used")
+
+	clone.Annotations = map[string]string{optCSISnapshotClassName: "custom-class"}
+	name, err = c.getCloneSnapshotClass(clone, newTestPV("pv", vSphereDriver))
+	require.NoError(t, err)
+	require.Equal(t, "custom-class", name)
+}
+
+func TestCloneVolumeFailures(t *testing.T) {
+	nonCSIPV := &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "nfs-pv"},
+		Spec: v1.PersistentVolumeSpec{
+			PersistentVolumeSource: v1.PersistentVolumeSource{
+				NFS: &v1.NFSVolumeSource{Server: "server", Path: "/"},
+			},
+		},
+	}
+	fakeSnapshotter := &fakeCloneSnapshotter{
+		snapshotStatus: snapshotter.SnapshotInfo{Status: snapshotter.StatusFailed, Reason: "snapshot error"},
+		restoreStatus:  snapshotter.RestoreInfo{Status: snapshotter.StatusFailed, Reason: "restore error"},
+	}
+	c := newTestCloneCSI(t, fakeSnapshotter, []string{"snapshot-class"},
+		newTestPV("pv", testDriver),
+		newTestPV("wffc-pv", testDriver),
+		nonCSIPV,
+		newTestClonePVC("pvc", "pv", cloneSCName),
+		newTestClonePVC("wffc-pvc", "wffc-pv", cloneWFFCSCName),
+		newTestClonePVC("nfs-pvc", "nfs-pv", cloneSCName),
+	)
+
+	vInfo := newTestCloneVolumeInfo()
+	vInfo.PersistentVolumeClaim = "nfs-pvc"
+	vInfo.Volume = "nfs-pv"
+	require.NoError(t, c.cloneVolume(newTestApplicationClone(vInfo), vInfo))
+	require.Equal(t, storkapi.ApplicationCloneStatusFailed, vInfo.Status)
+	require.Contains(t, vInfo.Reason, "isn't a CSI volume")
+
+	vInfo = newTestCloneVolumeInfo()
+	vInfo.PersistentVolumeClaim = "wffc-pvc"
+	vInfo.Volume = "wffc-pv"
+	require.NoError(t, c.cloneVolume(newTestApplicationClone(vInfo), vInfo))
+	require.Equal(t, storkapi.ApplicationCloneStatusFailed, vInfo.Status)
+	require.Contains(t, vInfo.Reason, string(storagev1.VolumeBindingWaitForFirstConsumer))
+	require.Empty(t, fakeSnapshotter.createdSnapshots)
+
+	// Snapshot failures fail the volume
+	vInfo = newTestCloneVolumeInfo()
+	clone := newTestApplicationClone(vInfo)
+	clone.Annotations = map[string]string{optCSISnapshotClassName: "snapshot-class"}
+	require.NoError(t, c.cloneVolume(clone, vInfo))
+	require.Equal(t, storkapi.ApplicationCloneStatusFailed, vInfo.Status)
+	require.Contains(t, vInfo.Reason, "snapshot error")
+
+	// Restore failures fail the volume
+	fakeSnapshotter.snapshotStatus = snapshotter.SnapshotInfo{Status: snapshotter.StatusReady}
+	vInfo = newTestCloneVolumeInfo()
+	clone = newTestApplicationClone(vInfo)
+	clone.Annotations = map[string]string{optCSISnapshotClassName: "snapshot-class"}
+	require.NoError(t, c.cloneVolume(clone, vInfo))
+	require.Equal(t, storkapi.ApplicationCloneStatusFailed, vInfo.Status)
+	require.Contains(t, vInfo.Reason, "restore error")
+
+	// Missing PVCs are returned as errors so that they are retried
+	vInfo = newTestCloneVolumeInfo()
+	vInfo.PersistentVolumeClaim = "missing-pvc"
+	require.Error(t, c.cloneVolume(newTestApplicationClone(vInfo), vInfo))
+}
+
+func TestCreateVolumeClonesTimeout(t *testing.T) {
+	fakeSnapshotter := &fakeCloneSnapshotter{
+		restoreStatus: snapshotter.RestoreInfo{Status: snapshotter.StatusInProgress, Reason: "PVC is pending"},
+	}
+	c := newTestCloneCSI(t, fakeSnapshotter, nil,
+		newTestPV("pv", testDriver),
+		newTestClonePVC("pvc", "pv", cloneSCName),
+	)
+	name := "clone-5678-1234"
+
+	vInfo := newTestCloneVolumeInfo()
+	clone := newTestApplicationClone(vInfo)
+	require.NoError(t, c.CreateVolumeClones(clone))
+	require.Equal(t, storkapi.ApplicationCloneStatusInProgress, vInfo.Status)
+	_, err := core.Instance().GetPersistentVolumeClaim(name, cloneSourceNamespace)
+	require.NoError(t, err)
+
+	clone.CreationTimestamp = metav1.NewTime(time.Now().Add(-cloneVolumeTimeout - time.Minute))
+	require.NoError(t, c.CreateVolumeClones(clone))
+	require.Equal(t, storkapi.ApplicationCloneStatusFailed, vInfo.Status)
+	require.Contains(t, vInfo.Reason, "Timed out")
+	_, err = core.Instance().GetPersistentVolumeClaim(name, cloneSourceNamespace)
+	require.True(t, k8s_errors.IsNotFound(err), "PVC used for the clone should be deleted")
+
+	// Volumes that failed aren't cloned again
+	vInfo.Reason = ""
+	require.NoError(t, c.CreateVolumeClones(clone))
+	require.Empty(t, vInfo.Reason)
+
+	// Volumes with provisioned PVs are only bound, which is retried
+	vInfo = newTestCloneVolumeInfo()
+	vInfo.PersistentVolumeCreated = true
+	vInfo.CloneVolume = "missing-pv"
+	clone = newTestApplicationClone(vInfo)
+	clone.CreationTimestamp = metav1.NewTime(time.Now().Add(-cloneVolumeTimeout - time.Minute))
+	require.NoError(t, c.CreateVolumeClones(clone))
+	require.Equal(t, storkapi.ApplicationCloneStatusInProgress, vInfo.Status)
+	require.Contains(t, vInfo.Reason, "Error cloning volume")
+}
+
+func TestBindCloneVolume(t *testing.T) {
+	fakeSnapshotter := &fakeCloneSnapshotter{}
+	name := "clone-5678-1234"
+	clonePV := newTestPV("clone-pv", testDriver)
+	clonePV.Annotations = map[string]string{annPVBoundByController: "yes"}
+	clonePV.Spec.PersistentVolumeReclaimPolicy = v1.PersistentVolumeReclaimDelete
+	clonePV.Spec.ClaimRef = &v1.ObjectReference{
+		Kind:      "PersistentVolumeClaim",
+		Namespace: cloneSourceNamespace,
+		Name:      name,
+	}
+	c := newTestCloneCSI(t, fakeSnapshotter, nil,
+		newTestPV("pv", testDriver),
+		clonePV,
+		newTestClonePVC("pvc", "pv", cloneSCName),
+		newTestClonePVC(name, "clone-pv", cloneSCName),
+	)
+
+	vInfo := newTestCloneVolumeInfo()
+	vInfo.PersistentVolumeCreated = true
+	vInfo.CloneVolume = "clone-pv"
+	vInfo.VolumeSnapshot = name
+	clone := newTestApplicationClone(vInfo)
+	require.NoError(t, c.cloneVolume(clone, vInfo))
+	require.Equal(t, storkapi.ApplicationCloneStatusSuccessful, vInfo.Status)
+	require.Equal(t, []string{name}, fakeSnapshotter.deletedSnapshots)
+
+	pv, err := core.Instance().GetPersistentVolume("clone-pv")
+	require.NoError(t, err)
+	require.Equal(t, cloneDestNamespace, pv.Spec.ClaimRef.Namespace)
+	require.Equal(t, "pvc", pv.Spec.ClaimRef.Name)
+	require.Empty(t, pv.Spec.ClaimRef.UID)
+	require.Equal(t, v1.PersistentVolumeReclaimDelete, pv.Spec.PersistentVolumeReclaimPolicy,
+		"Reclaim policy should be restored once the PV is pre-bound")
+	require.NotContains(t, pv.Annotations, cloneReclaimPolicyAnnotation)
+	require.NotContains(t, pv.Annotations, annPVBoundByController)
+	_, err = core.Instance().GetPersistentVolumeClaim(name, cloneSourceNamespace)
+	require.True(t, k8s_errors.IsNotFound(err), "PVC used for the clone should be deleted")
+
+	// Binding is skipped if the PV is already pre-bound
+	vInfo.Status = storkapi.ApplicationCloneStatusInProgress
+	require.NoError(t, c.bindCloneVolume(clone, vInfo, newTestClonePVC("pvc", "pv", cloneSCName)))
+	require.Equal(t, storkapi.ApplicationCloneStatusSuccessful, vInfo.Status)
+	pv, err = core.Instance().GetPersistentVolume("clone-pv")
+	require.NoError(t, err)
+	require.Equal(t, "pvc", pv.Spec.ClaimRef.Name)
+
+	// The PV is retained if binding failed after it was updated
+	retainedPV := newTestPV("retained-pv", testDriver)
+	retainedPV.Annotations = map[string]string{cloneReclaimPolicyAnnotation: string(v1.PersistentVolumeReclaimDelete)}
+	retainedPV.Spec.PersistentVolumeReclaimPolicy = v1.PersistentVolumeReclaimRetain
+	_, err = core.Instance().CreatePersistentVolume(retainedPV)
+	require.NoError(t, err)
+	vInfo = newTestCloneVolumeInfo()
+	vInfo.PersistentVolumeCreated = true
+	vInfo.CloneVolume = "retained-pv"
+	require.NoError(t, c.bindCloneVolume(clone, vInfo, newTestClonePVC("pvc", "pv", cloneSCName)))
+	pv, err = core.Instance().GetPersistentVolume("retained-pv")
+	require.NoError(t, err)
+	require.Equal(t, v1.PersistentVolumeReclaimDelete, pv.Spec.PersistentVolumeReclaimPolicy)
+	require.NotContains(t, pv.Annotations, cloneReclaimPolicyAnnotation)
+}
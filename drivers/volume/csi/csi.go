@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	kSnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
 	kSnapshotv1beta1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1beta1"
@@ -15,7 +17,7 @@ import (
 	storkvolume "github.com/libopenstorage/stork/drivers/volume"
 	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	"github.com/libopenstorage/stork/pkg/applicationmanager/controllers"
-	"github.com/libopenstorage/stork/pkg/crypto"
+	"github.com/libopenstorage/stork/pkg/backupformat"
 	"github.com/libopenstorage/stork/pkg/errors"
 	"github.com/libopenstorage/stork/pkg/k8sutils"
 	"github.com/libopenstorage/stork/pkg/log"
@@ -35,6 +37,9 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	clientset "k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	storagelisters "k8s.io/client-go/listers/storage/v1"
+	storagev1beta1listers "k8s.io/client-go/listers/storage/v1beta1"
 	"k8s.io/client-go/rest"
 )
 
@@ -50,8 +55,6 @@ const (
 	snapshotObjectName = "snapshots.json"
 	// storageClassesObjectName is the object stored for storageclasses
 	storageClassesObjectName = "storageclasses.json"
-	// resourcesObjectName is the object stored for the all backup resources
-	resourcesObjectName = "resources.json"
 
 	// optCSIDriverName is an option for storing which CSI Driver a volumesnapshot was created with
 	optCSIDriverName = "csi-driver-name"
@@ -160,16 +163,25 @@ func (cbo *csiBackupObject) GetVolumeSnapshotClass(snapshotID string) (interface
 }
 
 type csi struct {
+	k8sClient          clientset.Interface
 	snapshotClient     *kSnapshotClient.Clientset
 	snapshotter        snapshotter.Driver
 	v1SnapshotRequired bool
+	nodeLister         corelisters.NodeLister
+	csiNodeLister      storagelisters.CSINodeLister
+	// capacityLister is nil if CSIStorageCapacity isn't served
+	capacityLister storagev1beta1listers.CSIStorageCapacityLister
+	// topologyLock protects the start of the listers for the topology, which
+	// are started the first time they are needed
+	topologyLock    sync.Mutex
+	topologyStarted bool
 
 	storkvolume.ClusterPairNotSupported
 	storkvolume.MigrationNotSupported
 	storkvolume.GroupSnapshotNotSupported
 	storkvolume.ClusterDomainsNotSupported
-	storkvolume.CloneNotSupported
 	storkvolume.SnapshotRestoreNotSupported
+	storkvolume.VolumeHealthNotSupported
 }
 
 func (c *csi) Init(_ interface{}) error {
@@ -184,6 +196,11 @@ func (c *csi) Init(_ interface{}) error {
 	}
 	c.snapshotClient = cs
 
+	c.k8sClient, err = clientset.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
 	c.v1SnapshotRequired, err = version.RequiresV1VolumeSnapshot()
 	if err != nil {
 		return err
@@ -551,32 +568,10 @@ func (c *csi) uploadObject(
 	if err != nil {
 		return err
 	}
-	if backupLocation.Location.EncryptionKey != "" {
-		return fmt.Errorf("EncryptionKey is deprecated, use EncryptionKeyV2 instead")
-	}
-	if backupLocation.Location.EncryptionV2Key != "" {
-		if data, err = crypto.Encrypt(data, backupLocation.Location.EncryptionV2Key); err != nil {
-			return err
-		}
-	}
 
 	objectPath := controllers.GetObjectPath(backup)
-	writer, err := bucket.NewWriter(context.TODO(), filepath.Join(objectPath, objectName), nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = writer.Write(data)
-	if err != nil {
-		closeErr := writer.Close()
-		if closeErr != nil {
-			log.ApplicationBackupLog(backup).Errorf("error closing writer for objectstore: %v", closeErr)
-		}
-		return err
-	}
-	err = writer.Close()
-	if err != nil {
-		log.ApplicationBackupLog(backup).Errorf("error closing writer for objectstore: %v", err)
+	if err := backupformat.WriteObject(context.TODO(), bucket, backupLocation, filepath.Join(objectPath, objectName), data, nil); err != nil {
+		log.ApplicationBackupLog(backup).Errorf("error uploading %v to objectstore: %v", objectName, err)
 		return err
 	}
 	return nil
@@ -1233,23 +1228,8 @@ func (c *csi) downloadObject(
 		return nil, nil
 	}
 
-	data, err := bucket.ReadAll(context.TODO(), filepath.Join(objectPath, objectName))
-	if err != nil {
-		return nil, err
-	}
-	if restoreLocation.Location.EncryptionKey != "" {
-		return nil, fmt.Errorf("EncryptionKey is deprecated, use EncryptionKeyV2 instead")
-	}
-	if restoreLocation.Location.EncryptionV2Key != "" {
-		var decryptData []byte
-		if decryptData, err = crypto.Decrypt(data, restoreLocation.Location.EncryptionV2Key); err != nil {
-			logrus.Debugf("decrypt failed with: %v and returning the data as it is", err)
-			return data, nil
-		}
-		return decryptData, nil
-	}
-
-	return data, nil
+	ctx := backupformat.WithCompression(context.TODO(), backup.Status.Compression)
+	return backupformat.ReadObject(ctx, bucket, restoreLocation, filepath.Join(objectPath, objectName))
 }
 
 // getRestoreSnapshotsAndContent retrieves the volumeSnapshots and
@@ -1290,27 +1270,42 @@ func (c *csi) getCSIBackupObject(backupName, backupNamespace string) (*csiBackup
 	return cboCommon, nil
 }
 
-// getBackupResources gets all objects in resource.json
-func (c *csi) getBackupResources(restore *storkapi.ApplicationRestore) ([]runtime.Unstructured, error) {
+// getBackupPVCs gets the PVCs from the resources of the backup. The resources
+// are streamed so that only the PVCs are held in memory.
+func (c *csi) getBackupPVCs(restore *storkapi.ApplicationRestore) ([]runtime.Unstructured, error) {
 	backup, err := storkops.Instance().GetApplicationBackup(restore.Spec.BackupName, restore.Namespace)
 	if err != nil {
 		return nil, fmt.Errorf("error getting backup resources for CSI restore: %v", err)
 	}
 
-	backupObjectBytes, err := c.downloadObject(backup, backup.Spec.BackupLocation, backup.Namespace, resourcesObjectName)
+	restoreLocation, err := storkops.Instance().GetBackupLocation(backup.Spec.BackupLocation, backup.Namespace)
 	if err != nil {
 		return nil, err
 	}
-
-	objects := make([]*unstructured.Unstructured, 0)
-	if err = json.Unmarshal(backupObjectBytes, &objects); err != nil {
+	bucket, err := objectstore.GetBucket(restoreLocation)
+	if err != nil {
+		return nil, err
+	}
+	ctx := backupformat.WithCompression(context.TODO(), backup.Status.Compression)
+	reader, err := backupformat.NewResourcesReader(ctx, bucket, restoreLocation, backup.Status.BackupPath)
+	if err != nil {
 		return nil, err
 	}
-	runtimeObjects := make([]runtime.Unstructured, 0)
-	for _, o := range objects {
-		runtimeObjects = append(runtimeObjects, o)
+	defer reader.Close() // nolint: errcheck
+
+	pvcs := make([]runtime.Unstructured, 0)
+	for {
+		object, err := reader.Next()
+		if err == io.EOF {
+			return pvcs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if object.GetObjectKind().GroupVersionKind().Kind == "PersistentVolumeClaim" {
+			pvcs = append(pvcs, object)
+		}
 	}
-	return runtimeObjects, nil
 }
 
 func (c *csi) findPVCInResources(resources []runtime.Unstructured, pvcName, pvcNamespace string) (*v1.PersistentVolumeClaim, error) {
@@ -1503,8 +1498,8 @@ func (c *csi) createRestoreSnapshotsAndPVCs(
 	var err error
 	volumeRestoreInfos := []*storkapi.ApplicationRestoreVolumeInfo{}
 
-	// Get all backed up resources to find PVC spec
-	resources, err := c.getBackupResources(restore)
+	// Get all backed up PVCs to find PVC spec
+	resources, err := c.getBackupPVCs(restore)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get backup resources: %s", err.Error())
 	}
@@ -1776,26 +1771,10 @@ func (c *csi) getDestinationNamespace(restore *storkapi.ApplicationRestore, ns s
 	return destNamespace
 }
 
-func (c *csi) InspectVolume(volumeID string) (*storkvolume.Info, error) {
-	return nil, &errors.ErrNotSupported{}
-}
-
 func (c *csi) GetClusterID() (string, error) {
 	return "", &errors.ErrNotSupported{}
 }
 
-func (c *csi) GetNodes() ([]*storkvolume.NodeInfo, error) {
-	return nil, &errors.ErrNotSupported{}
-}
-
-func (c *csi) InspectNode(id string) (*storkvolume.NodeInfo, error) {
-	return nil, &errors.ErrNotSupported{}
-}
-
-func (c *csi) GetPodVolumes(podSpec *v1.PodSpec, namespace string, includePendingWFFC bool) ([]*storkvolume.Info, []*storkvolume.Info, error) {
-	return nil, nil, &errors.ErrNotSupported{}
-}
-
 func (c *csi) GetSnapshotPlugin() snapshotVolume.Plugin {
 	return nil
 }
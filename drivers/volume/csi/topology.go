@@ -0,0 +1,427 @@
+package csi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	storkvolume "github.com/libopenstorage/stork/drivers/volume"
+	"github.com/libopenstorage/stork/pkg/errors"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	storagev1beta1 "k8s.io/api/storage/v1beta1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	k8shelper "k8s.io/component-helpers/scheduling/corev1"
+)
+
+// Nodes and volumes for CSI drivers are built from the Kubernetes objects
+// that describe their topology: the nodeAffinity of PVs, the topology keys
+// in CSINode objects and the capacity in CSIStorageCapacity objects. These
+// are read from informers since they are needed for every scheduling request.
+
+const (
+	// rackLabelKey is the node label used for the rack of a node
+	rackLabelKey = "stork.libopenstorage.org/rack"
+	// zoneTopologyKey is the part of a CSI topology key used for zones by
+	// drivers that don't use the well-known zone label
+	zoneTopologyKey = "zone"
+	// regionTopologyKey is the part of a CSI topology key used for regions
+	// by drivers that don't use the well-known region label
+	regionTopologyKey = "region"
+
+	// topologyResyncPeriod is the resync period of the informers for the
+	// objects used to build the topology
+	topologyResyncPeriod = 30 * time.Second
+	// topologySyncTimeout is how long to wait for the informers to sync when
+	// the topology cache is started
+	topologySyncTimeout = 1 * time.Minute
+	// csiStorageCapacityResource is the resource of CSIStorageCapacity
+	// objects, which aren't served by all clusters
+	csiStorageCapacityResource = "csistoragecapacities"
+
+	nodeNotReadyStatus = "Node is not ready"
+	noCSIDriversStatus = "No CSI drivers registered on node"
+)
+
+// ensureTopologyCache starts the topology cache the first time it is needed,
+// so that it isn't started in binaries that never use it. If the cache
+// doesn't sync in time it is started again the next time it is needed.
+func (c *csi) ensureTopologyCache() error {
+	c.topologyLock.Lock()
+	defer c.topologyLock.Unlock()
+	if c.topologyStarted {
+		return nil
+	}
+	stopCh := make(chan struct{})
+	if err := c.startTopologyCache(stopCh); err != nil {
+		close(stopCh)
+		return err
+	}
+	return nil
+}
+
+// startTopologyCache starts the informers for the Nodes, CSINodes and
+// CSIStorageCapacities used to build the topology of the CSI drivers, and
+// waits up to topologySyncTimeout for them to sync
+func (c *csi) startTopologyCache(stopCh <-chan struct{}) error {
+	factory := informers.NewSharedInformerFactory(c.k8sClient, topologyResyncPeriod)
+	c.nodeLister = factory.Core().V1().Nodes().Lister()
+	c.csiNodeLister = factory.Storage().V1().CSINodes().Lister()
+	c.capacityLister = nil
+	if c.isStorageCapacitySupported() {
+		c.capacityLister = factory.Storage().V1beta1().CSIStorageCapacities().Lister()
+	}
+	factory.Start(stopCh)
+	ctx, cancel := context.WithTimeout(context.Background(), topologySyncTimeout)
+	defer cancel()
+	for informerType, synced := range factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			return fmt.Errorf("error syncing cache for %v", informerType)
+		}
+	}
+	c.topologyStarted = true
+	return nil
+}
+
+// isStorageCapacitySupported returns true if CSIStorageCapacity objects are
+// served by the cluster. Only storage.k8s.io/v1beta1 is supported, which
+// isn't served by Kubernetes 1.27 and later.
+func (c *csi) isStorageCapacitySupported() bool {
+	resources, err := c.k8sClient.Discovery().ServerResourcesForGroupVersion(storagev1beta1.SchemeGroupVersion.String())
+	if err != nil {
+		logrus.Debugf("Error getting resources for %v, capacity won't be reported for nodes: %v", storagev1beta1.SchemeGroupVersion, err)
+		return false
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == csiStorageCapacityResource {
+			return true
+		}
+	}
+	return false
+}
+
+// GetNodes returns the nodes in the cluster. Nodes are online if they are
+// ready and have at least one CSI driver registered. Whether the driver of a
+// volume is registered on a node is reported by the AccessibleNodes of the
+// volume.
+//
+// CSIStorageCapacity only reports the free capacity and not the size of the
+// storage, so the Total capacity of every node is set to the largest free
+// capacity of all the nodes. The used capacity computed from it is the
+// capacity a node has less than the node with the most free capacity, not the
+// utilization of the storage on the node. Capacity is only read from the
+// storage.k8s.io/v1beta1 CSIStorageCapacity API, which isn't served by
+// Kubernetes 1.27 and later, so nodes don't have a capacity on those
+// clusters.
+func (c *csi) GetNodes() ([]*storkvolume.NodeInfo, error) {
+	if err := c.ensureTopologyCache(); err != nil {
+		return nil, err
+	}
+	nodes, err := c.nodeLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error listing nodes: %v", err)
+	}
+	csiNodes, err := c.csiNodeLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error listing CSINodes: %v", err)
+	}
+	csiNodeMap := make(map[string]*storagev1.CSINode)
+	for _, csiNode := range csiNodes {
+		csiNodeMap[csiNode.Name] = csiNode
+	}
+	capacities := c.getStorageCapacities()
+
+	var nodeInfos []*storkvolume.NodeInfo
+	var maxFree uint64
+	for _, node := range nodes {
+		nodeInfo := getNodeInfo(node, csiNodeMap[node.Name], capacities)
+		if nodeInfo.Capacity != nil && nodeInfo.Capacity.Free > maxFree {
+			maxFree = nodeInfo.Capacity.Free
+		}
+		nodeInfos = append(nodeInfos, nodeInfo)
+	}
+	for _, nodeInfo := range nodeInfos {
+		if nodeInfo.Capacity != nil {
+			nodeInfo.Capacity.Total = maxFree
+		}
+	}
+	return nodeInfos, nil
+}
+
+// InspectNode returns the node with the given name
+func (c *csi) InspectNode(id string) (*storkvolume.NodeInfo, error) {
+	nodes, err := c.GetNodes()
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes {
+		if node.StorageID == id {
+			return node, nil
+		}
+	}
+	return nil, &errors.ErrNotFound{
+		ID:   id,
+		Type: "Node",
+	}
+}
+
+// getStorageCapacities returns the CSIStorageCapacity objects in the
+// cluster. They aren't required, so errors are only logged.
+func (c *csi) getStorageCapacities() []*storagev1beta1.CSIStorageCapacity {
+	if c.capacityLister == nil {
+		return nil
+	}
+	capacities, err := c.capacityLister.List(labels.Everything())
+	if err != nil {
+		logrus.Debugf("Error listing CSIStorageCapacities, capacity won't be reported for nodes: %v", err)
+		return nil
+	}
+	return capacities
+}
+
+func getNodeInfo(
+	node *v1.Node,
+	csiNode *storagev1.CSINode,
+	capacities []*storagev1beta1.CSIStorageCapacity,
+) *storkvolume.NodeInfo {
+	nodeInfo := &storkvolume.NodeInfo{
+		StorageID:   node.Name,
+		SchedulerID: node.Name,
+		Hostname:    strings.ToLower(node.Name),
+		Rack:        node.Labels[rackLabelKey],
+		Zone:        node.Labels[v1.LabelTopologyZone],
+		Region:      node.Labels[v1.LabelTopologyRegion],
+		Status:      storkvolume.NodeOnline,
+	}
+	for _, address := range node.Status.Addresses {
+		switch address.Type {
+		case v1.NodeHostName:
+			nodeInfo.Hostname = strings.ToLower(address.Address)
+		case v1.NodeInternalIP, v1.NodeExternalIP:
+			nodeInfo.IPs = append(nodeInfo.IPs, address.Address)
+		}
+	}
+
+	// Use the topology of the CSI drivers if the node doesn't have the
+	// well-known labels
+	if csiNode != nil {
+		for _, driver := range csiNode.Spec.Drivers {
+			for _, key := range driver.TopologyKeys {
+				value, ok := node.Labels[key]
+				if !ok {
+					continue
+				}
+				if nodeInfo.Zone == "" && strings.HasSuffix(key, "/"+zoneTopologyKey) {
+					nodeInfo.Zone = value
+				} else if nodeInfo.Region == "" && strings.HasSuffix(key, "/"+regionTopologyKey) {
+					nodeInfo.Region = value
+				}
+			}
+		}
+	}
+
+	if !isNodeReady(node) {
+		nodeInfo.Status = storkvolume.NodeOffline
+		nodeInfo.RawStatus = nodeNotReadyStatus
+	} else if csiNode == nil || len(csiNode.Spec.Drivers) == 0 {
+		nodeInfo.Status = storkvolume.NodeOffline
+		nodeInfo.RawStatus = noCSIDriversStatus
+	} else {
+		var drivers []string
+		for _, driver := range csiNode.Spec.Drivers {
+			drivers = append(drivers, driver.Name)
+		}
+		nodeInfo.RawStatus = fmt.Sprintf("CSI drivers registered on node: %v", strings.Join(drivers, ", "))
+	}
+
+	// Use the largest capacity reported for the node since capacities for
+	// different storage classes can be for the same storage
+	for _, capacity := range capacities {
+		if capacity.NodeTopology == nil || capacity.Capacity == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(capacity.NodeTopology)
+		if err != nil {
+			logrus.Debugf("Invalid node topology in CSIStorageCapacity %v/%v: %v", capacity.Namespace, capacity.Name, err)
+			continue
+		}
+		if !selector.Matches(labels.Set(node.Labels)) {
+			continue
+		}
+		free := uint64(capacity.Capacity.Value())
+		if nodeInfo.Capacity == nil {
+			nodeInfo.Capacity = &storkvolume.NodeCapacity{}
+		}
+		if free > nodeInfo.Capacity.Free {
+			nodeInfo.Capacity.Free = free
+		}
+	}
+	return nodeInfo
+}
+
+// isDriverRegistered returns true if the CSI driver is registered on the node
+func isDriverRegistered(csiNode *storagev1.CSINode, driverName string) bool {
+	if csiNode == nil {
+		return false
+	}
+	for _, driver := range csiNode.Spec.Drivers {
+		if driver.Name == driverName {
+			return true
+		}
+	}
+	return false
+}
+
+func isNodeReady(node *v1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// InspectVolume returns the volume for the PV with the given name. The PV name
+// is used as the ID of the volume. The data nodes are the nodes that match the
+// nodeAffinity of the PV, so they are empty for volumes that can be accessed
+// from any node. The accessible nodes are the ready nodes that match the
+// nodeAffinity and have the CSI driver of the volume registered.
+func (c *csi) InspectVolume(volumeID string) (*storkvolume.Info, error) {
+	pv, err := core.Instance().GetPersistentVolume(volumeID)
+	if err != nil {
+		return nil, err
+	}
+	return c.getVolumeInfo(pv)
+}
+
+func (c *csi) getVolumeInfo(pv *v1.PersistentVolume) (*storkvolume.Info, error) {
+	if pv.Spec.CSI == nil {
+		return nil, &errors.ErrNotSupported{
+			Feature: "InspectVolume",
+			Reason:  fmt.Sprintf("PV %v isn't a CSI volume", pv.Name),
+		}
+	}
+	if err := c.ensureTopologyCache(); err != nil {
+		return nil, err
+	}
+
+	volumeInfo := &storkvolume.Info{
+		VolumeID:        pv.Name,
+		VolumeName:      pv.Name,
+		Labels:          pv.Labels,
+		VolumeSourceRef: pv,
+		// The volume can't be accessed from any node if its driver isn't
+		// registered on any of them
+		AccessibleNodes: []string{},
+	}
+	if size, ok := pv.Spec.Capacity[v1.ResourceStorage]; ok {
+		volumeInfo.Size = uint64(size.Value()) / (1024 * 1024 * 1024)
+	}
+
+	nodes, err := c.nodeLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error listing nodes: %v", err)
+	}
+	for _, node := range nodes {
+		if pv.Spec.NodeAffinity != nil && pv.Spec.NodeAffinity.Required != nil {
+			match, err := k8shelper.MatchNodeSelectorTerms(node, pv.Spec.NodeAffinity.Required)
+			if err != nil {
+				return nil, fmt.Errorf("error matching node affinity for PV %v: %v", pv.Name, err)
+			}
+			if !match {
+				continue
+			}
+			volumeInfo.DataNodes = append(volumeInfo.DataNodes, node.Name)
+		}
+		if !isNodeReady(node) {
+			continue
+		}
+		csiNode, err := c.csiNodeLister.Get(node.Name)
+		if err != nil && !k8s_errors.IsNotFound(err) {
+			return nil, fmt.Errorf("error getting CSINode %v: %v", node.Name, err)
+		}
+		if isDriverRegistered(csiNode, pv.Spec.CSI.Driver) {
+			volumeInfo.AccessibleNodes = append(volumeInfo.AccessibleNodes, node.Name)
+		}
+	}
+	return volumeInfo, nil
+}
+
+// GetPodVolumes returns the CSI volumes used by the pod. The second list has
+// the pending volumes that are waiting for the pod to be scheduled if
+// includePendingWFFC is true.
+func (c *csi) GetPodVolumes(podSpec *v1.PodSpec, namespace string, includePendingWFFC bool) ([]*storkvolume.Info, []*storkvolume.Info, error) {
+	var volumes []*storkvolume.Info
+	var pendingWFFCVolumes []*storkvolume.Info
+	for _, volume := range podSpec.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		pvc, err := core.Instance().GetPersistentVolumeClaim(volume.PersistentVolumeClaim.ClaimName, namespace)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if pvc.Status.Phase == v1.ClaimPending {
+			if !c.isCSIPVC(pvc) {
+				continue
+			}
+			// Only include pending volume if requested and storage class has WFFC
+			if includePendingWFFC && isWaitingForFirstConsumer(pvc) {
+				pendingWFFCVolumes = append(pendingWFFCVolumes, &storkvolume.Info{
+					VolumeName: pvc.Name,
+				})
+				continue
+			}
+			return nil, nil, &storkvolume.ErrPVCPending{
+				Name: pvc.Name,
+			}
+		}
+
+		pv, err := core.Instance().GetPersistentVolume(pvc.Spec.VolumeName)
+		if err != nil {
+			return nil, nil, err
+		}
+		if pv.Spec.CSI == nil {
+			continue
+		}
+		volumeInfo, err := c.getVolumeInfo(pv)
+		if err != nil {
+			return nil, nil, err
+		}
+		volumes = append(volumes, volumeInfo)
+	}
+	return volumes, pendingWFFCVolumes, nil
+}
+
+// isCSIPVC returns true if the storage class of an unbound PVC is
+// provisioned by a CSI driver
+func (c *csi) isCSIPVC(pvc *v1.PersistentVolumeClaim) bool {
+	sc, err := core.Instance().GetStorageClassForPVC(pvc)
+	if err != nil {
+		return false
+	}
+	_, err = c.k8sClient.StorageV1().CSIDrivers().Get(context.TODO(), sc.Provisioner, metav1.GetOptions{})
+	if err != nil {
+		if !k8s_errors.IsNotFound(err) {
+			logrus.Warnf("Error getting CSIDriver %v for pvc %v/%v: %v", sc.Provisioner, pvc.Namespace, pvc.Name, err)
+		}
+		return false
+	}
+	return true
+}
+
+func isWaitingForFirstConsumer(pvc *v1.PersistentVolumeClaim) bool {
+	sc, err := core.Instance().GetStorageClassForPVC(pvc)
+	if err != nil {
+		logrus.Warnf("Did not get the storageclass for pvc %v/%v: %v", pvc.Namespace, pvc.Name, err)
+		return false
+	}
+	return sc.VolumeBindingMode != nil && *sc.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer
+}
@@ -0,0 +1,347 @@
+//go:build unittest
+// +build unittest
+
+package csi
+
+import (
+	"testing"
+
+	storkvolume "github.com/libopenstorage/stork/drivers/volume"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	storagev1beta1 "k8s.io/api/storage/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	testDriver      = "test.csi.driver"
+	otherTestDriver = "other.csi.driver"
+	testZoneKey     = "topology.test.csi.driver/zone"
+)
+
+func newTestNode(name string, ready bool, labels map[string]string) *v1.Node {
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+		Status: v1.NodeStatus{
+			Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: status}},
+			Addresses: []v1.NodeAddress{
+				{Type: v1.NodeHostName, Address: name + ".Domain"},
+				{Type: v1.NodeInternalIP, Address: "192.168.0.1"},
+			},
+		},
+	}
+}
+
+func newTestCSINode(name string, drivers ...string) *storagev1.CSINode {
+	csiNode := &storagev1.CSINode{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}
+	for _, driver := range drivers {
+		csiNode.Spec.Drivers = append(csiNode.Spec.Drivers, storagev1.CSINodeDriver{
+			Name:         driver,
+			NodeID:       name,
+			TopologyKeys: []string{testZoneKey},
+		})
+	}
+	return csiNode
+}
+
+func newTestCapacity(name string, zone string, size string) *storagev1beta1.CSIStorageCapacity {
+	capacity := resource.MustParse(size)
+	return &storagev1beta1.CSIStorageCapacity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "kube-system",
+		},
+		NodeTopology: &metav1.LabelSelector{
+			MatchLabels: map[string]string{testZoneKey: zone},
+		},
+		StorageClassName: "test-sc",
+		Capacity:         &capacity,
+	}
+}
+
+func newTestPV(name string, driver string, zones ...string) *v1.PersistentVolume {
+	pv := &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1.PersistentVolumeSpec{
+			Capacity: v1.ResourceList{
+				v1.ResourceStorage: resource.MustParse("2Gi"),
+			},
+			PersistentVolumeSource: v1.PersistentVolumeSource{
+				CSI: &v1.CSIPersistentVolumeSource{
+					Driver:       driver,
+					VolumeHandle: name,
+				},
+			},
+		},
+	}
+	if len(zones) > 0 {
+		pv.Spec.NodeAffinity = &v1.VolumeNodeAffinity{
+			Required: &v1.NodeSelector{
+				NodeSelectorTerms: []v1.NodeSelectorTerm{{
+					MatchExpressions: []v1.NodeSelectorRequirement{{
+						Key:      testZoneKey,
+						Operator: v1.NodeSelectorOpIn,
+						Values:   zones,
+					}},
+				}},
+			},
+		}
+	}
+	return pv
+}
+
+// newTestCSI returns a CSI driver with its topology cache started on a fake
+// client with the given objects
+func newTestCSI(t *testing.T, objects ...runtime.Object) *csi {
+	fakeClient := fake.NewSimpleClientset(objects...)
+	fakeClient.Resources = []*metav1.APIResourceList{{
+		GroupVersion: storagev1beta1.SchemeGroupVersion.String(),
+		APIResources: []metav1.APIResource{{Name: csiStorageCapacityResource, Namespaced: true}},
+	}}
+	core.SetInstance(core.New(fakeClient))
+
+	c := &csi{k8sClient: fakeClient}
+	stopCh := make(chan struct{})
+	t.Cleanup(func() { close(stopCh) })
+	require.NoError(t, c.startTopologyCache(stopCh))
+	return c
+}
+
+func TestTopologyCacheStartedLazily(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(newTestNode("node1", true, nil), newTestCSINode("node1", testDriver))
+	core.SetInstance(core.New(fakeClient))
+	c := &csi{k8sClient: fakeClient}
+	require.Nil(t, c.nodeLister, "Topology cache shouldn't be started until it is needed")
+
+	nodes, err := c.GetNodes()
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+	require.True(t, c.topologyStarted)
+	require.Nil(t, c.capacityLister, "Capacity shouldn't be read if CSIStorageCapacity isn't served")
+}
+
+func TestGetNodeInfo(t *testing.T) {
+	labels := map[string]string{rackLabelKey: "rack1", testZoneKey: "zone1"}
+
+	nodeInfo := getNodeInfo(newTestNode("node1", true, labels), newTestCSINode("node1", testDriver), nil)
+	require.Equal(t, "node1", nodeInfo.StorageID)
+	require.Equal(t, "node1", nodeInfo.SchedulerID)
+	require.Equal(t, "node1.domain", nodeInfo.Hostname)
+	require.Equal(t, []string{"192.168.0.1"}, nodeInfo.IPs)
+	require.Equal(t, "rack1", nodeInfo.Rack)
+	require.Equal(t, "zone1", nodeInfo.Zone, "Zone should be set from the topology key of the driver")
+	require.Equal(t, storkvolume.NodeOnline, nodeInfo.Status)
+	require.Contains(t, nodeInfo.RawStatus, testDriver)
+	require.Nil(t, nodeInfo.Capacity)
+
+	labels[v1.LabelTopologyZone] = "well-known-zone"
+	nodeInfo = getNodeInfo(newTestNode("node1", true, labels), newTestCSINode("node1", testDriver), nil)
+	require.Equal(t, "well-known-zone", nodeInfo.Zone, "Well-known zone label should be preferred")
+
+	nodeInfo = getNodeInfo(newTestNode("node1", false, labels), newTestCSINode("node1", testDriver), nil)
+	require.Equal(t, storkvolume.NodeOffline, nodeInfo.Status)
+	require.Equal(t, nodeNotReadyStatus, nodeInfo.RawStatus)
+
+	nodeInfo = getNodeInfo(newTestNode("node1", true, labels), nil, nil)
+	require.Equal(t, storkvolume.NodeOffline, nodeInfo.Status)
+	require.Equal(t, noCSIDriversStatus, nodeInfo.RawStatus)
+
+	nodeInfo = getNodeInfo(newTestNode("node1", true, labels), newTestCSINode("node1"), nil)
+	require.Equal(t, storkvolume.NodeOffline, nodeInfo.Status)
+	require.Equal(t, noCSIDriversStatus, nodeInfo.RawStatus)
+
+	capacities := []*storagev1beta1.CSIStorageCapacity{
+		newTestCapacity("capacity1", "zone1", "10Gi"),
+		newTestCapacity("capacity2", "zone1", "20Gi"),
+		newTestCapacity("capacity3", "zone2", "30Gi"),
+		{ObjectMeta: metav1.ObjectMeta{Name: "capacity4"}},
+	}
+	nodeInfo = getNodeInfo(newTestNode("node1", true, labels), newTestCSINode("node1", testDriver), capacities)
+	require.NotNil(t, nodeInfo.Capacity)
+	require.Equal(t, uint64(20*1024*1024*1024), nodeInfo.Capacity.Free, "Largest matching capacity should be used")
+}
+
+func TestGetNodes(t *testing.T) {
+	c := newTestCSI(t,
+		newTestNode("node1", true, map[string]string{testZoneKey: "zone1"}),
+		newTestNode("node2", true, map[string]string{testZoneKey: "zone2"}),
+		newTestNode("node3", true, nil),
+		newTestCSINode("node1", testDriver),
+		newTestCSINode("node2", otherTestDriver),
+		newTestCapacity("capacity1", "zone1", "10Gi"),
+		newTestCapacity("capacity2", "zone2", "40Gi"),
+	)
+
+	nodes, err := c.GetNodes()
+	require.NoError(t, err)
+	require.Len(t, nodes, 3)
+	nodeMap := make(map[string]*storkvolume.NodeInfo)
+	for _, node := range nodes {
+		nodeMap[node.StorageID] = node
+	}
+	require.Equal(t, storkvolume.NodeOnline, nodeMap["node1"].Status)
+	require.Equal(t, storkvolume.NodeOnline, nodeMap["node2"].Status)
+	require.Equal(t, storkvolume.NodeOffline, nodeMap["node3"].Status)
+
+	require.Equal(t, &storkvolume.NodeCapacity{Total: 40 * 1024 * 1024 * 1024, Free: 10 * 1024 * 1024 * 1024}, nodeMap["node1"].Capacity)
+	require.Equal(t, &storkvolume.NodeCapacity{Total: 40 * 1024 * 1024 * 1024, Free: 40 * 1024 * 1024 * 1024}, nodeMap["node2"].Capacity)
+	require.Nil(t, nodeMap["node3"].Capacity)
+
+	node, err := c.InspectNode("node2")
+	require.NoError(t, err)
+	require.Equal(t, "node2", node.StorageID)
+	_, err = c.InspectNode("node4")
+	require.Error(t, err)
+}
+
+func TestInspectVolume(t *testing.T) {
+	nonCSIPV := &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "nfs-pv"},
+		Spec: v1.PersistentVolumeSpec{
+			PersistentVolumeSource: v1.PersistentVolumeSource{
+				NFS: &v1.NFSVolumeSource{Server: "server", Path: "/"},
+			},
+		},
+	}
+	c := newTestCSI(t,
+		newTestNode("node1", true, map[string]string{testZoneKey: "zone1"}),
+		newTestNode("node2", true, map[string]string{testZoneKey: "zone1"}),
+		newTestNode("node3", true, map[string]string{testZoneKey: "zone2"}),
+		newTestNode("node4", false, map[string]string{testZoneKey: "zone1"}),
+		newTestCSINode("node1", testDriver),
+		newTestCSINode("node2", otherTestDriver),
+		newTestCSINode("node3", testDriver, otherTestDriver),
+		newTestCSINode("node4", testDriver),
+		newTestPV("zonal-pv", testDriver, "zone1"),
+		newTestPV("any-node-pv", testDriver),
+		newTestPV("other-driver-pv", otherTestDriver),
+		newTestPV("unregistered-driver-pv", "unregistered.csi.driver"),
+		nonCSIPV,
+	)
+
+	volumeInfo, err := c.InspectVolume("zonal-pv")
+	require.NoError(t, err)
+	require.Equal(t, "zonal-pv", volumeInfo.VolumeID)
+	require.Equal(t, "zonal-pv", volumeInfo.VolumeName)
+	require.Equal(t, uint64(2), volumeInfo.Size)
+	require.ElementsMatch(t, []string{"node1", "node2", "node4"}, volumeInfo.DataNodes)
+	require.Equal(t, []string{"node1"}, volumeInfo.AccessibleNodes,
+		"Only ready nodes in the zone with the driver should be accessible")
+
+	volumeInfo, err = c.InspectVolume("any-node-pv")
+	require.NoError(t, err)
+	require.Empty(t, volumeInfo.DataNodes)
+	require.ElementsMatch(t, []string{"node1", "node3"}, volumeInfo.AccessibleNodes)
+
+	volumeInfo, err = c.InspectVolume("other-driver-pv")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"node2", "node3"}, volumeInfo.AccessibleNodes)
+
+	volumeInfo, err = c.InspectVolume("unregistered-driver-pv")
+	require.NoError(t, err)
+	require.NotNil(t, volumeInfo.AccessibleNodes)
+	require.Empty(t, volumeInfo.AccessibleNodes, "Volume shouldn't be accessible from any node")
+
+	_, err = c.InspectVolume("nfs-pv")
+	require.Error(t, err)
+	_, err = c.InspectVolume("missing-pv")
+	require.Error(t, err)
+}
+
+func TestGetPodVolumes(t *testing.T) {
+	wffc := storagev1.VolumeBindingWaitForFirstConsumer
+	scName := "wffc-sc"
+	otherSCName := "non-csi-sc"
+	newPVC := func(name, volumeName string, phase v1.PersistentVolumeClaimPhase, sc string) *v1.PersistentVolumeClaim {
+		return &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: v1.PersistentVolumeClaimSpec{
+				VolumeName:       volumeName,
+				StorageClassName: &sc,
+			},
+			Status: v1.PersistentVolumeClaimStatus{Phase: phase},
+		}
+	}
+	newPodSpec := func(claims ...string) *v1.PodSpec {
+		podSpec := &v1.PodSpec{
+			Volumes: []v1.Volume{{
+				Name:         "empty",
+				VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}},
+			}},
+		}
+		for _, claim := range claims {
+			podSpec.Volumes = append(podSpec.Volumes, v1.Volume{
+				Name: claim,
+				VolumeSource: v1.VolumeSource{
+					PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+				},
+			})
+		}
+		return podSpec
+	}
+	nonCSIPV := &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "nfs-pv"},
+		Spec: v1.PersistentVolumeSpec{
+			PersistentVolumeSource: v1.PersistentVolumeSource{
+				NFS: &v1.NFSVolumeSource{Server: "server", Path: "/"},
+			},
+		},
+	}
+
+	c := newTestCSI(t,
+		newTestNode("node1", true, map[string]string{testZoneKey: "zone1"}),
+		newTestCSINode("node1", testDriver),
+		&storagev1.CSIDriver{ObjectMeta: metav1.ObjectMeta{Name: testDriver}},
+		&storagev1.StorageClass{
+			ObjectMeta:        metav1.ObjectMeta{Name: scName},
+			Provisioner:       testDriver,
+			VolumeBindingMode: &wffc,
+		},
+		&storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: otherSCName},
+			Provisioner: "kubernetes.io/nfs",
+		},
+		newTestPV("bound-pv", testDriver, "zone1"),
+		nonCSIPV,
+		newPVC("bound-pvc", "bound-pv", v1.ClaimBound, scName),
+		newPVC("nfs-pvc", "nfs-pv", v1.ClaimBound, otherSCName),
+		newPVC("pending-pvc", "", v1.ClaimPending, scName),
+		newPVC("pending-nfs-pvc", "", v1.ClaimPending, otherSCName),
+	)
+
+	volumes, pending, err := c.GetPodVolumes(newPodSpec("bound-pvc", "nfs-pvc", "pending-nfs-pvc"), "test", false)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+	require.Len(t, volumes, 1, "Only CSI volumes should be returned")
+	require.Equal(t, "bound-pv", volumes[0].VolumeID)
+	require.Equal(t, []string{"node1"}, volumes[0].DataNodes)
+	require.Equal(t, []string{"node1"}, volumes[0].AccessibleNodes)
+
+	volumes, pending, err = c.GetPodVolumes(newPodSpec("bound-pvc", "pending-pvc"), "test", true)
+	require.NoError(t, err)
+	require.Len(t, volumes, 1)
+	require.Len(t, pending, 1)
+	require.Equal(t, "pending-pvc", pending[0].VolumeName)
+
+	_, _, err = c.GetPodVolumes(newPodSpec("bound-pvc", "pending-pvc"), "test", false)
+	require.Error(t, err)
+	_, ok := err.(*storkvolume.ErrPVCPending)
+	require.True(t, ok, "Expected ErrPVCPending, got %v", err)
+
+	_, _, err = c.GetPodVolumes(newPodSpec("missing-pvc"), "test", false)
+	require.Error(t, err)
+}
@@ -54,6 +54,7 @@ type gcp struct {
 	storkvolume.ClusterDomainsNotSupported
 	storkvolume.CloneNotSupported
 	storkvolume.SnapshotRestoreNotSupported
+	storkvolume.VolumeHealthNotSupported
 }
 
 type gcpSession struct {
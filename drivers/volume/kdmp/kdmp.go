@@ -120,6 +120,7 @@ type kdmp struct {
 	storkvolume.ClusterDomainsNotSupported
 	storkvolume.CloneNotSupported
 	storkvolume.SnapshotRestoreNotSupported
+	storkvolume.VolumeHealthNotSupported
 }
 
 func (k *kdmp) Init(_ interface{}) error {
@@ -257,6 +258,14 @@ func (k *kdmp) StartBackup(backup *storkapi.ApplicationBackup,
 	pvcs []v1.PersistentVolumeClaim,
 ) ([]*storkapi.ApplicationBackupVolumeInfo, error) {
 	log.ApplicationBackupLog(backup).Debugf("started generic backup: %v", backup.Name)
+	backupLocation, err := storkops.Instance().GetBackupLocation(backup.Spec.BackupLocation, backup.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error getting backup location %v: %v", backup.Spec.BackupLocation, err)
+	}
+	// The data mover jobs can only upload volume data to object stores
+	if backupLocation.Location.Type == storkapi.BackupLocationFile {
+		return nil, fmt.Errorf("generic backup of volumes is not supported for backup locations of type %v, use an s3, azure or google backup location instead", backupLocation.Location.Type)
+	}
 	volumeInfos := make([]*storkapi.ApplicationBackupVolumeInfo, 0)
 	for _, pvc := range pvcs {
 		if pvc.DeletionTimestamp != nil {
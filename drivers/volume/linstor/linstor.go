@@ -51,6 +51,7 @@ type linstor struct {
 	storkvolume.BackupRestoreNotSupported
 	storkvolume.CloneNotSupported
 	storkvolume.SnapshotRestoreNotSupported
+	storkvolume.VolumeHealthNotSupported
 }
 
 func (l *linstor) linstorClient() (*lclient.Client, error) {
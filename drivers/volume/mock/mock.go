@@ -153,6 +153,8 @@ func (m *Driver) ProvisionVolume(
 		VolumeName: volumeName,
 		Size:       size,
 		Labels:     labels,
+		Status:     storkvolume.VolumeHealthy,
+		RawStatus:  string(storkvolume.VolumeHealthy),
 	}
 
 	for i := 0; i < len(replicaIndexes); i++ {
@@ -179,6 +181,30 @@ func (m *Driver) UpdateNodeStatus(
 	return nil
 }
 
+// UpdateNodeCapacity Update the storage capacity for a node
+func (m *Driver) UpdateNodeCapacity(
+	nodeIndex int,
+	capacity *storkvolume.NodeCapacity,
+) error {
+	if len(m.nodes) <= nodeIndex {
+		return fmt.Errorf("node %v not found", nodeIndex)
+	}
+	m.nodes[nodeIndex].Capacity = capacity
+	return nil
+}
+
+// UpdateNodeIOLoad Update the IO load for a node
+func (m *Driver) UpdateNodeIOLoad(
+	nodeIndex int,
+	ioLoad *float64,
+) error {
+	if len(m.nodes) <= nodeIndex {
+		return fmt.Errorf("node %v not found", nodeIndex)
+	}
+	m.nodes[nodeIndex].IOLoad = ioLoad
+	return nil
+}
+
 // UpdateNodeIP Update IP for a node
 func (m *Driver) UpdateNodeIP(
 	nodeIndex int,
@@ -196,6 +222,46 @@ func (m *Driver) SetInterfaceError(err error) {
 	m.interfaceError = err
 }
 
+// UpdateVolumeStatus Update the status for a volume
+func (m *Driver) UpdateVolumeStatus(
+	volumeName string,
+	status storkvolume.VolumeStatus,
+) error {
+	volume, ok := m.volumes[volumeName]
+	if !ok {
+		return fmt.Errorf("volume %v not found", volumeName)
+	}
+	volume.Status = status
+	volume.RawStatus = string(status)
+	return nil
+}
+
+// UpdateVolumeAccessibleNodes Update the nodes from which a volume can be
+// accessed
+func (m *Driver) UpdateVolumeAccessibleNodes(
+	volumeName string,
+	nodeIndexes []int,
+) error {
+	volume, ok := m.volumes[volumeName]
+	if !ok {
+		return fmt.Errorf("volume %v not found", volumeName)
+	}
+	volume.AccessibleNodes = []string{}
+	for _, nodeIndex := range nodeIndexes {
+		if len(m.nodes) <= nodeIndex {
+			return fmt.Errorf("node %v not found", nodeIndex)
+		}
+		volume.AccessibleNodes = append(volume.AccessibleNodes, m.nodes[nodeIndex].StorageID)
+	}
+	return nil
+}
+
+// SupportsVolumeHealth returns true since the status of volumes can be set
+// for tests
+func (m Driver) SupportsVolumeHealth() bool {
+	return true
+}
+
 // InspectVolume Return information for a given volume
 func (m Driver) InspectVolume(volumeID string) (*storkvolume.Info, error) {
 	if m.interfaceError != nil {
@@ -521,9 +521,38 @@ func (p *portworx) inspectVolume(volDriver volume.VolumeDriver, volumeID string)
 	}
 
 	info.VolumeSourceRef = vols[0]
+	info.Status = p.mapVolumeStatus(vols[0])
+	info.RawStatus = fmt.Sprintf("%v/%v", vols[0].Status, vols[0].State)
 	return info, nil
 }
 
+// SupportsVolumeHealth returns true since the status of volumes is reported
+// by InspectVolume
+func (p *portworx) SupportsVolumeHealth() bool {
+	return true
+}
+
+func (p *portworx) mapVolumeStatus(vol *api.Volume) storkvolume.VolumeStatus {
+	switch vol.State {
+	case api.VolumeState_VOLUME_STATE_ERROR:
+		return storkvolume.VolumeUnavailable
+	case api.VolumeState_VOLUME_STATE_DETACHED:
+		return storkvolume.VolumeDetached
+	}
+	switch vol.Status {
+	case api.VolumeStatus_VOLUME_STATUS_UP:
+		return storkvolume.VolumeHealthy
+	case api.VolumeStatus_VOLUME_STATUS_DEGRADED:
+		return storkvolume.VolumeDegraded
+	case api.VolumeStatus_VOLUME_STATUS_DOWN:
+		fallthrough
+	case api.VolumeStatus_VOLUME_STATUS_NOT_PRESENT:
+		return storkvolume.VolumeUnavailable
+	default:
+		return storkvolume.VolumeStatusUnknown
+	}
+}
+
 func (p *portworx) mapNodeStatus(status api.Status) storkvolume.NodeStatus {
 	switch status {
 	case api.Status_STATUS_INIT:
@@ -606,9 +635,42 @@ func (p *portworx) InspectNode(id string) (*storkvolume.NodeInfo, error) {
 		Hostname:    strings.ToLower(node.Hostname),
 		Status:      p.mapNodeStatus(node.Status),
 		RawStatus:   node.Status.String(),
+		Capacity:    p.getNodeCapacity(&node),
+		IOLoad:      p.getNodeIOLoad(&node),
 	}, nil
 }
 
+// getNodeCapacity returns the total and free space in all the storage pools
+// on the node
+func (p *portworx) getNodeCapacity(node *api.Node) *storkvolume.NodeCapacity {
+	if len(node.Pools) == 0 {
+		return nil
+	}
+	capacity := &storkvolume.NodeCapacity{}
+	for i := range node.Pools {
+		pool := &node.Pools[i]
+		capacity.Total += pool.TotalSize
+		if pool.TotalSize > pool.Used {
+			capacity.Free += pool.TotalSize - pool.Used
+		}
+	}
+	if capacity.Total == 0 {
+		return nil
+	}
+	return capacity
+}
+
+// getNodeIOLoad returns the average load reported for the node
+func (p *portworx) getNodeIOLoad(node *api.Node) *float64 {
+	load := float64(node.Avgload)
+	if load < 0 {
+		load = 0
+	} else if load > 100 {
+		load = 100
+	}
+	return &load
+}
+
 func (p *portworx) GetNodes() ([]*storkvolume.NodeInfo, error) {
 	if !p.initDone {
 		if err := p.initPortworxClients(); err != nil {
@@ -636,6 +698,8 @@ func (p *portworx) GetNodes() ([]*storkvolume.NodeInfo, error) {
 			Hostname:    strings.ToLower(n.Hostname),
 			Status:      p.mapNodeStatus(n.Status),
 			RawStatus:   n.Status.String(),
+			Capacity:    p.getNodeCapacity(n),
+			IOLoad:      p.getNodeIOLoad(n),
 		}
 		nodeInfo.IPs = append(nodeInfo.IPs, n.MgmtIp)
 		nodeInfo.IPs = append(nodeInfo.IPs, n.DataIp)
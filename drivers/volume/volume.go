@@ -155,6 +155,8 @@ type Driver interface {
 	ClonePluginInterface
 	// SnapshotRestorePluginInterface Interface to do in-place restore of volumes
 	SnapshotRestorePluginInterface
+	// VolumeHealthPluginInterface Interface to report the health of volumes
+	VolumeHealthPluginInterface
 }
 
 // GroupSnapshotCreateResponse is the response for the group snapshot operation
@@ -254,6 +256,13 @@ type ClonePluginInterface interface {
 	CreateVolumeClones(*storkapi.ApplicationClone) error
 }
 
+// VolumeHealthPluginInterface Interface to report the health of volumes
+type VolumeHealthPluginInterface interface {
+	// SupportsVolumeHealth returns true if InspectVolume sets the Status of
+	// the volume
+	SupportsVolumeHealth() bool
+}
+
 // Info Information about a volume
 type Info struct {
 	// VolumeID is a unique identifier for the volume
@@ -262,6 +271,10 @@ type Info struct {
 	VolumeName string
 	// DataNodes is a list of nodes where the data for the volume resides
 	DataNodes []string
+	// AccessibleNodes is a list of nodes from which the volume can be
+	// accessed. nil if it can be accessed from any node where the driver is
+	// online
+	AccessibleNodes []string
 	// Size is the size of the volume in GB
 	Size uint64
 	// ParentID points to the ID of the parent volume for snapshots
@@ -270,8 +283,29 @@ type Info struct {
 	Labels map[string]string
 	// VolumeSourceRef is a optional reference to the source of the volume
 	VolumeSourceRef interface{}
+	// Status of the volume. Only set by drivers that support volume health
+	Status VolumeStatus
+	// RawStatus as returned by the driver
+	RawStatus string
 }
 
+// VolumeStatus Health of a volume
+type VolumeStatus string
+
+const (
+	// VolumeStatusUnknown Driver didn't report the health of the volume
+	VolumeStatusUnknown VolumeStatus = ""
+	// VolumeHealthy Volume is healthy
+	VolumeHealthy VolumeStatus = "Healthy"
+	// VolumeDegraded Volume is available but some of its replicas are down
+	VolumeDegraded VolumeStatus = "Degraded"
+	// VolumeUnavailable Volume can't be accessed since all of its replicas
+	// are down
+	VolumeUnavailable VolumeStatus = "Unavailable"
+	// VolumeDetached Volume isn't attached to any node
+	VolumeDetached VolumeStatus = "Detached"
+)
+
 // NodeStatus Status of driver on a node
 type NodeStatus string
 
@@ -305,6 +339,20 @@ type NodeInfo struct {
 	Status NodeStatus
 	// RawStatus as returned by the driver
 	RawStatus string
+	// Capacity of the storage on the node. nil if the driver doesn't report
+	// it
+	Capacity *NodeCapacity
+	// IOLoad is how busy the storage on the node is, as a percentage between
+	// 0 and 100. nil if the driver doesn't report it
+	IOLoad *float64
+}
+
+// NodeCapacity Capacity of the storage on a node
+type NodeCapacity struct {
+	// Total size of the storage in bytes
+	Total uint64
+	// Free space in the storage in bytes
+	Free uint64
 }
 
 var (
@@ -542,6 +590,15 @@ func (c *CloneNotSupported) CreateVolumeClones(*storkapi.ApplicationClone) error
 	return &errors.ErrNotSupported{}
 }
 
+// VolumeHealthNotSupported to be used by drivers that can't report the
+// health of volumes
+type VolumeHealthNotSupported struct{}
+
+// SupportsVolumeHealth returns false
+func (v *VolumeHealthNotSupported) SupportsVolumeHealth() bool {
+	return false
+}
+
 // SnapshotRestoreNotSupported to be used by drivers that don't support
 // volume snapshot restore
 type SnapshotRestoreNotSupported struct{}
@@ -0,0 +1,158 @@
+package v1alpha1
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ApplicationActivationResourceName is name for "applicationactivation" resource
+	ApplicationActivationResourceName = "applicationactivation"
+	// ApplicationActivationResourcePlural is plural for "applicationactivation" resource
+	ApplicationActivationResourcePlural = "applicationactivations"
+)
+
+// ApplicationActivationOperationType is the operation done by an
+// ApplicationActivation
+type ApplicationActivationOperationType string
+
+const (
+	// ApplicationActivationOperationFailover deactivates the applications on
+	// the remote cluster and activates the migrated applications on the local
+	// cluster
+	ApplicationActivationOperationFailover ApplicationActivationOperationType = "Failover"
+	// ApplicationActivationOperationFailback deactivates the applications on
+	// the local cluster, migrates them back to the remote cluster and
+	// activates them again there
+	ApplicationActivationOperationFailback ApplicationActivationOperationType = "Failback"
+)
+
+// ApplicationActivationSpec is the spec used to failover or failback
+// applications between the clusters of a ClusterPair
+type ApplicationActivationSpec struct {
+	// Operation is Failover or Failback. Defaults to Failover.
+	Operation ApplicationActivationOperationType `json:"operation"`
+	// ClusterPair in the same namespace that points to the remote cluster
+	ClusterPair string `json:"clusterPair"`
+	// MigrationSchedule on the remote cluster that is suspended on failover
+	// and resumed once the applications have been failed back
+	MigrationSchedule string `json:"migrationSchedule"`
+	// Namespaces with the applications to activate
+	Namespaces []string `json:"namespaces"`
+	// Steps are the order in which applications are activated. Applications
+	// are deactivated in the reverse order. All the applications are
+	// activated in one step if none are specified.
+	Steps []ApplicationActivationStep `json:"steps"`
+	// SkipRemoteDeactivation doesn't deactivate the applications on the
+	// remote cluster during a failover, for example if it is unreachable
+	SkipRemoteDeactivation bool `json:"skipRemoteDeactivation"`
+}
+
+// ApplicationActivationStep is a group of applications that are activated
+// together
+type ApplicationActivationStep struct {
+	Name string `json:"name"`
+	// Kinds of applications in this step. StatefulSets, Deployments,
+	// DeploymentConfigs, CronJobs and the kinds registered with
+	// ApplicationRegistrations that have suspend options are supported. All
+	// supported kinds are used if empty.
+	Kinds []string `json:"kinds"`
+	// Selectors are labels that applications in this step need to match
+	Selectors map[string]string `json:"selectors"`
+	// ReadinessTimeoutSeconds is how long to wait for the applications of
+	// this step to be ready before failing. Defaults to 5 minutes.
+	ReadinessTimeoutSeconds int64 `json:"readinessTimeoutSeconds"`
+}
+
+// ApplicationActivationStatusType is the status of the activation
+type ApplicationActivationStatusType string
+
+const (
+	// ApplicationActivationStatusInitial for when activation is created
+	ApplicationActivationStatusInitial ApplicationActivationStatusType = ""
+	// ApplicationActivationStatusPending for when an activated application
+	// isn't ready yet
+	ApplicationActivationStatusPending ApplicationActivationStatusType = "Pending"
+	// ApplicationActivationStatusInProgress for when activation is in progress
+	ApplicationActivationStatusInProgress ApplicationActivationStatusType = "InProgress"
+	// ApplicationActivationStatusSuccessful for when activation has completed successfully
+	ApplicationActivationStatusSuccessful ApplicationActivationStatusType = "Successful"
+	// ApplicationActivationStatusFailed for when activation has failed
+	ApplicationActivationStatusFailed ApplicationActivationStatusType = "Failed"
+)
+
+// ApplicationActivationStageType is the stage of the activation
+type ApplicationActivationStageType string
+
+const (
+	// ApplicationActivationStageInitial for when activation is created
+	ApplicationActivationStageInitial ApplicationActivationStageType = ""
+	// ApplicationActivationStageSchedule for when the migration schedule is
+	// being suspended or resumed
+	ApplicationActivationStageSchedule ApplicationActivationStageType = "Schedule"
+	// ApplicationActivationStageDeactivate for when applications are being
+	// deactivated and stopped
+	ApplicationActivationStageDeactivate ApplicationActivationStageType = "Deactivate"
+	// ApplicationActivationStageMigrate for when the applications are being
+	// migrated back to the remote cluster on failback
+	ApplicationActivationStageMigrate ApplicationActivationStageType = "Migrate"
+	// ApplicationActivationStageActivate for when applications are being
+	// activated
+	ApplicationActivationStageActivate ApplicationActivationStageType = "Activate"
+	// ApplicationActivationStageFinal for when activation has completed
+	ApplicationActivationStageFinal ApplicationActivationStageType = "Final"
+)
+
+// ApplicationActivationStatus is the status of an application activation
+type ApplicationActivationStatus struct {
+	Stage  ApplicationActivationStageType  `json:"stage"`
+	Status ApplicationActivationStatusType `json:"status"`
+	Reason string                          `json:"reason"`
+	// CurrentStep is the index of the step being activated
+	CurrentStep int `json:"currentStep"`
+	// StepStartTimestamp is when the applications of the current step were
+	// activated, or when the applications were deactivated
+	StepStartTimestamp meta.Time `json:"stepStartTimestamp"`
+	// Migration is the name of the Migration created on failback to migrate
+	// the applications back to the remote cluster
+	Migration       string                               `json:"migration,omitempty"`
+	Resources       []*ApplicationActivationResourceInfo `json:"resources"`
+	FinishTimestamp meta.Time                            `json:"finishTimestamp"`
+}
+
+// ApplicationActivationResourceInfo is the result of activating or
+// deactivating one resource
+type ApplicationActivationResourceInfo struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Cluster is Local or Remote
+	Cluster string `json:"cluster"`
+	// Step is the name of the step that the resource was activated in
+	Step string `json:"step"`
+	// Activated is false if the resource was deactivated
+	Activated bool                            `json:"activated"`
+	Status    ApplicationActivationStatusType `json:"status"`
+	Reason    string                          `json:"reason"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ApplicationActivation fails over migrated applications to the local
+// cluster, or fails them back to the remote cluster
+type ApplicationActivation struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+	Spec            ApplicationActivationSpec   `json:"spec"`
+	Status          ApplicationActivationStatus `json:"status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ApplicationActivationList is a list of ApplicationActivations
+type ApplicationActivationList struct {
+	meta.TypeMeta `json:",inline"`
+	meta.ListMeta `json:"metadata,omitempty"`
+
+	Items []ApplicationActivation `json:"items"`
+}
@@ -66,6 +66,15 @@ type ApplicationBackupStatus struct {
 	LastUpdateTimestamp metav1.Time                      `json:"lastUpdateTimestamp"`
 	FinishTimestamp     metav1.Time                      `json:"finishTimestamp"`
 	TotalSize           uint64                           `json:"totalSize"`
+	// Compression is the algorithm the objects of the backup were compressed
+	// with when they were uploaded
+	Compression BackupLocationCompressionType `json:"compression,omitempty"`
+	// EncryptionKeyID is the key encryption key the data key of the backup
+	// is wrapped with, if the backup location uses envelope encryption
+	EncryptionKeyID string `json:"encryptionKeyID,omitempty"`
+	// RuleResults are the results of the pre and post exec rules that were
+	// run for the backup
+	RuleResults []*RuleExecutionResult `json:"ruleResults,omitempty"`
 }
 
 // ObjectInfo contains info about an object being backed up or restored
@@ -18,6 +18,13 @@ type ApplicationBackupScheduleSpec struct {
 	Suspend            *bool                         `json:"suspend"`
 	ReclaimPolicy      ReclaimPolicyType             `json:"reclaimPolicy"`
 	BackupType         string                        `json:"backupType"`
+	// StartingDeadlineSeconds is how long after its scheduled time a run can
+	// still be started. Defaults to one hour. Doesn't apply to Interval
+	// policies.
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+	// CatchUpPolicy is how runs that missed their starting deadline are
+	// handled. Defaults to Skip.
+	CatchUpPolicy CatchUpPolicyType `json:"catchUpPolicy,omitempty"`
 }
 
 // ApplicationBackupTemplateSpec describes the data a ApplicationBackup should have when created
@@ -29,6 +36,8 @@ type ApplicationBackupTemplateSpec struct {
 // ApplicationBackupScheduleStatus is the status of a applicationbackup schedule
 type ApplicationBackupScheduleStatus struct {
 	Items map[SchedulePolicyType][]*ScheduledApplicationBackupStatus `json:"items"`
+	// MissedRuns are the most recent runs that were skipped or deferred
+	MissedRuns []*MissedScheduleRun `json:"missedRuns,omitempty"`
 }
 
 // ScheduledApplicationBackupStatus keeps track of the applicationbackup that was triggered by a
@@ -38,6 +47,11 @@ type ScheduledApplicationBackupStatus struct {
 	CreationTimestamp meta.Time                   `json:"creationTimestamp"`
 	FinishTimestamp   meta.Time                   `json:"finishTimestamp"`
 	Status            ApplicationBackupStatusType `json:"status"`
+	// ScheduledTimestamp is the time the run was scheduled for
+	ScheduledTimestamp meta.Time `json:"scheduledTimestamp,omitempty"`
+	// RetentionTiers are the tiers of the retention policy that retain the
+	// object
+	RetentionTiers []RetentionTierType `json:"retentionTiers,omitempty"`
 }
 
 // +genclient
@@ -0,0 +1,72 @@
+package v1alpha1
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ApplicationBackupVerificationResourceName is name for "applicationbackupverification" resource
+	ApplicationBackupVerificationResourceName = "applicationbackupverification"
+	// ApplicationBackupVerificationResourcePlural is plural for "applicationbackupverification" resource
+	ApplicationBackupVerificationResourcePlural = "applicationbackupverifications"
+)
+
+// ApplicationBackupVerificationSpec is the spec used to verify an applicationbackup
+type ApplicationBackupVerificationSpec struct {
+	// BackupName is the name of the ApplicationBackup in the same namespace
+	// to verify
+	BackupName string `json:"backupName"`
+}
+
+// ApplicationBackupVerificationStatusType is the status of the verification
+type ApplicationBackupVerificationStatusType string
+
+const (
+	// ApplicationBackupVerificationStatusInitial for when verification is created
+	ApplicationBackupVerificationStatusInitial ApplicationBackupVerificationStatusType = ""
+	// ApplicationBackupVerificationStatusInProgress for when verification is in progress
+	ApplicationBackupVerificationStatusInProgress ApplicationBackupVerificationStatusType = "InProgress"
+	// ApplicationBackupVerificationStatusSuccessful for when verification has completed successfully
+	ApplicationBackupVerificationStatusSuccessful ApplicationBackupVerificationStatusType = "Successful"
+	// ApplicationBackupVerificationStatusFailed for when verification has failed
+	ApplicationBackupVerificationStatusFailed ApplicationBackupVerificationStatusType = "Failed"
+)
+
+// ApplicationBackupVerificationStatus is the status of an applicationbackup verification
+type ApplicationBackupVerificationStatus struct {
+	Status          ApplicationBackupVerificationStatusType    `json:"status"`
+	Reason          string                                     `json:"reason"`
+	Objects         []*ApplicationBackupVerificationObjectInfo `json:"objects"`
+	FinishTimestamp meta.Time                                  `json:"finishTimestamp"`
+}
+
+// ApplicationBackupVerificationObjectInfo is the result of verifying one
+// object of the backup in the backup location
+type ApplicationBackupVerificationObjectInfo struct {
+	Name   string                                  `json:"name"`
+	Size   int64                                   `json:"size"`
+	Status ApplicationBackupVerificationStatusType `json:"status"`
+	Reason string                                  `json:"reason"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ApplicationBackupVerification downloads and checks every object of an
+// ApplicationBackup without restoring it
+type ApplicationBackupVerification struct {
+	meta.TypeMeta   `json:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty"`
+	Spec            ApplicationBackupVerificationSpec   `json:"spec"`
+	Status          ApplicationBackupVerificationStatus `json:"status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ApplicationBackupVerificationList is a list of ApplicationBackupVerifications
+type ApplicationBackupVerificationList struct {
+	meta.TypeMeta `json:",inline"`
+	meta.ListMeta `json:"metadata,omitempty"`
+
+	Items []ApplicationBackupVerification `json:"items"`
+}
@@ -66,6 +66,12 @@ type ApplicationCloneVolumeInfo struct {
 	CloneVolume           string                     `json:"cloneVolume"`
 	Status                ApplicationCloneStatusType `json:"status"`
 	Reason                string                     `json:"reason"`
+	// VolumeSnapshot is the snapshot used to clone the volume by drivers
+	// that clone volumes from snapshots
+	VolumeSnapshot string `json:"volumeSnapshot,omitempty"`
+	// PersistentVolumeCreated is set by drivers that create the PV for the
+	// cloned volume. The PV isn't cloned with the resources then.
+	PersistentVolumeCreated bool `json:"persistentVolumeCreated,omitempty"`
 }
 
 // ApplicationCloneStatusType defines status of the application being cloned
@@ -46,6 +46,32 @@ type BackupLocationItem struct {
 	RepositoryPassword string        `json:"repositoryPassword"`
 	// EncryptionV2Key will be used to pass encryption key.
 	EncryptionV2Key string `json:"encryptionV2Key"`
+	// Compression is the algorithm used to compress objects before they are
+	// encrypted and uploaded. Objects are not compressed if it is empty.
+	Compression BackupLocationCompressionType `json:"compression,omitempty"`
+	// EncryptionKeyProvider enables envelope encryption. Each backup is
+	// encrypted with its own data key, which is wrapped with a key
+	// encryption key from the provider. EncryptionV2Key is only used to
+	// read backups that were taken before the provider was configured.
+	EncryptionKeyProvider *EncryptionKeyProvider `json:"encryptionKeyProvider,omitempty"`
+}
+
+// EncryptionKeyProvider is the spec for the provider of the key encryption
+// keys used to wrap the data keys of backups
+type EncryptionKeyProvider struct {
+	Type EncryptionKeyProviderType `json:"type"`
+	// KeyID is the key encryption key used to wrap the data keys of new
+	// backups. Keys that were used before must be kept with the provider
+	// until the data keys wrapped with them have been re-wrapped.
+	KeyID string `json:"keyID"`
+	// SecretName is the secret in the namespace of the backup location with
+	// the keys for the secret provider. Each key in the secret is a key ID.
+	SecretName string `json:"secretName,omitempty"`
+	// Path is the directory with the keys for the file provider. Each file
+	// in the directory is a key ID.
+	Path string `json:"path,omitempty"`
+	// KMS is the name of the registered KMS for the kms provider
+	KMS string `json:"kms,omitempty"`
 }
 
 // ClusterItem is the spec used to store a the credentials associated with the cluster
@@ -73,6 +99,38 @@ const (
 	BackupLocationAzure BackupLocationType = "azure"
 	// BackupLocationGoogle stores the backup in Google Cloud Storage
 	BackupLocationGoogle BackupLocationType = "google"
+	// BackupLocationFile stores the backup in a directory on a filesystem
+	// that is mounted at Path in the stork pods, for example an NFS export.
+	// Volumes that are backed up with the generic (KDMP) driver can't use it
+	// since the data mover jobs only upload to object stores.
+	BackupLocationFile BackupLocationType = "file"
+)
+
+// BackupLocationCompressionType is the algorithm used to compress objects
+// uploaded to a backup location
+type BackupLocationCompressionType string
+
+const (
+	// BackupLocationCompressionNone uploads objects without compression
+	BackupLocationCompressionNone BackupLocationCompressionType = ""
+	// BackupLocationCompressionGzip compresses objects with gzip
+	BackupLocationCompressionGzip BackupLocationCompressionType = "gzip"
+	// BackupLocationCompressionZstd compresses objects with zstd, which is
+	// faster than gzip and has a better compression ratio
+	BackupLocationCompressionZstd BackupLocationCompressionType = "zstd"
+)
+
+// EncryptionKeyProviderType is the type of the provider for key encryption
+// keys
+type EncryptionKeyProviderType string
+
+const (
+	// EncryptionKeyProviderSecret reads keys from a kubernetes secret
+	EncryptionKeyProviderSecret EncryptionKeyProviderType = "secret"
+	// EncryptionKeyProviderFile reads keys from files in a directory
+	EncryptionKeyProviderFile EncryptionKeyProviderType = "file"
+	// EncryptionKeyProviderKMS wraps keys with a key management service
+	EncryptionKeyProviderKMS EncryptionKeyProviderType = "kms"
 )
 
 // ClusterType is the type of the cluster
@@ -146,6 +204,9 @@ func (bl *BackupLocation) UpdateFromSecret(client kubernetes.Interface) error {
 		if val, ok := secretConfig.Data["path"]; ok && val != nil {
 			bl.Location.Path = strings.TrimSuffix(string(val), "\n")
 		}
+		if val, ok := secretConfig.Data["compression"]; ok && val != nil {
+			bl.Location.Compression = BackupLocationCompressionType(strings.TrimSuffix(string(val), "\n"))
+		}
 	}
 	switch bl.Location.Type {
 	case BackupLocationS3:
@@ -154,6 +215,8 @@ func (bl *BackupLocation) UpdateFromSecret(client kubernetes.Interface) error {
 		return bl.getMergedAzureConfig(client)
 	case BackupLocationGoogle:
 		return bl.getMergedGoogleConfig(client)
+	case BackupLocationFile:
+		return nil
 	default:
 		return fmt.Errorf("Invalid BackupLocation type %v", bl.Location.Type)
 	}
@@ -30,6 +30,10 @@ type ClusterPairSpec struct {
 	// PlatformOptions are kubernetes platform provider related
 	// options.
 	PlatformOptions PlatformSpec `json:"platformOptions",yaml:"platformOptions"`
+	// BackupLocation is used to transfer the data of volumes that can't be
+	// migrated by the storage driver. A BackupLocation with the same name
+	// needs to exist in the namespace on both clusters.
+	BackupLocation string `json:"backupLocation"`
 }
 
 // ClusterPairStatusType is the status of the pair
@@ -28,6 +28,10 @@ type MigrationSpec struct {
 	IncludeOptionalResourceTypes []string          `json:"includeOptionalResourceTypes"`
 	SkipDeletedNamespaces        *bool             `json:"skipDeletedNamespaces"`
 	TransformSpecs               []string          `json:"transformSpecs"`
+	// StorageClassMapping maps the storage classes on the source cluster to
+	// the ones on the destination cluster for volumes that are migrated
+	// through the BackupLocation of the ClusterPair
+	StorageClassMapping map[string]string `json:"storageClassMapping"`
 }
 
 // MigrationStatus is the status of a migration operation
@@ -41,6 +45,9 @@ type MigrationStatus struct {
 	ResourceMigrationFinishTimestamp meta.Time                `json:"resourceMigrationFinishTimestamp"`
 	// Summary provides a short summary on the migration
 	Summary *MigrationSummary `json:"summary"`
+	// RuleResults are the results of the pre and post exec rules that were
+	// run for the migration
+	RuleResults []*RuleExecutionResult `json:"ruleResults,omitempty"`
 }
 
 // MigrationResourceInfo is the info for the migration of a resource
@@ -63,6 +70,9 @@ type MigrationSummary struct {
 	TotalNumberOfResources uint64 `json:"totalNumberOfResources"`
 	// NumberOfMigratedResources gives the total count of migrated k8s resources
 	NumberOfMigratedResources uint64 `json:"numOfMigratedResources"`
+	// NumberOfSkippedResources gives the count of migrated k8s resources
+	// that weren't applied since they haven't changed since the last migration
+	NumberOfSkippedResources uint64 `json:"numOfSkippedResources"`
 	// TotalBytesMigrated gives the total amount of bytes migrated across all the volumes
 	TotalBytesMigrated uint64 `json:"totalBytesMigrated"`
 	// ElapsedTimeForVolumeMigration provides the total time the
@@ -83,6 +93,9 @@ type MigrationVolumeInfo struct {
 	Status                MigrationStatusType `json:"status"`
 	BytesTotal            uint64              `json:"bytesTotal"`
 	Reason                string              `json:"reason"`
+	// DriverName is set to the generic driver for volumes that are migrated
+	// through the BackupLocation of the ClusterPair
+	DriverName string `json:"driverName"`
 }
 
 // +genclient
@@ -17,6 +17,13 @@ type MigrationScheduleSpec struct {
 	SchedulePolicyName string                `json:"schedulePolicyName"`
 	Suspend            *bool                 `json:"suspend"`
 	AutoSuspend        bool                  `json:"autoSuspend"`
+	// StartingDeadlineSeconds is how long after its scheduled time a run can
+	// still be started. Defaults to one hour. Doesn't apply to Interval
+	// policies.
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+	// CatchUpPolicy is how runs that missed their starting deadline are
+	// handled. Defaults to Skip.
+	CatchUpPolicy CatchUpPolicyType `json:"catchUpPolicy,omitempty"`
 }
 
 // MigrationTemplateSpec describes the data a Migration should have when created
@@ -29,6 +36,8 @@ type MigrationTemplateSpec struct {
 type MigrationScheduleStatus struct {
 	Items                map[SchedulePolicyType][]*ScheduledMigrationStatus `json:"items"`
 	ApplicationActivated bool                                               `json:"applicationActivated"`
+	// MissedRuns are the most recent runs that were skipped or deferred
+	MissedRuns []*MissedScheduleRun `json:"missedRuns,omitempty"`
 }
 
 // ScheduledMigrationStatus keeps track of the migration that was triggered by a
@@ -38,6 +47,11 @@ type ScheduledMigrationStatus struct {
 	CreationTimestamp meta.Time           `json:"creationTimestamp"`
 	FinishTimestamp   meta.Time           `json:"finishTimestamp"`
 	Status            MigrationStatusType `json:"status"`
+	// ScheduledTimestamp is the time the run was scheduled for
+	ScheduledTimestamp meta.Time `json:"scheduledTimestamp,omitempty"`
+	// RetentionTiers are the tiers of the retention policy that retain the
+	// object
+	RetentionTiers []RetentionTierType `json:"retentionTiers,omitempty"`
 }
 
 // +genclient
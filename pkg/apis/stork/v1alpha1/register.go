@@ -64,6 +64,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&VolumeSnapshotRestoreList{},
 		&ApplicationBackupSchedule{},
 		&ApplicationBackupScheduleList{},
+		&ApplicationBackupVerification{},
+		&ApplicationBackupVerificationList{},
+		&ApplicationActivation{},
+		&ApplicationActivationList{},
 		&DataExport{},
 		&DataExportList{},
 		&ResourceTransformation{},
@@ -29,6 +29,30 @@ const (
 	DeleteResourcePath ResourceTransformationOperationType = "delete"
 	// JsonResourcePatch will patch json in given resource spec
 	JsonResourcePatch ResourceTransformationOperationType = "jsonpatch"
+	// RegexReplaceResourcePath replaces the matches of a regular expression
+	// in the string values at the given path with value. Capture groups can
+	// be referenced in value as $1, ${name} etc
+	RegexReplaceResourcePath ResourceTransformationOperationType = "regexreplace"
+)
+
+// ResourceTransformationConditionOperator is the operator used to match the
+// value at a path of a resource
+type ResourceTransformationConditionOperator string
+
+const (
+	// ConditionOperatorExists matches if the path exists
+	ConditionOperatorExists ResourceTransformationConditionOperator = "Exists"
+	// ConditionOperatorDoesNotExist matches if the path doesn't exist
+	ConditionOperatorDoesNotExist ResourceTransformationConditionOperator = "DoesNotExist"
+	// ConditionOperatorIn matches if the value at the path is one of the
+	// values
+	ConditionOperatorIn ResourceTransformationConditionOperator = "In"
+	// ConditionOperatorNotIn matches if the path doesn't exist or the value
+	// at the path isn't one of the values
+	ConditionOperatorNotIn ResourceTransformationConditionOperator = "NotIn"
+	// ConditionOperatorMatches matches if the value at the path matches the
+	// regular expression in values
+	ConditionOperatorMatches ResourceTransformationConditionOperator = "Matches"
 )
 
 // ResourceTransformationValueType is types of value supported on
@@ -82,6 +106,9 @@ type ResourceTransformation struct {
 type ResourceTransformationStatus struct {
 	Status    ResourceTransformationStatusType `json:"status"`
 	Resources []*TransformResourceInfo         `json:"resources"`
+	// DiffsOmitted is the number of resources whose diff wasn't stored
+	// since the total size of the diffs was too large
+	DiffsOmitted int `json:"diffsOmitted,omitempty"`
 }
 
 // TransformResourceInfo is the info of resources selected
@@ -93,12 +120,21 @@ type TransformResourceInfo struct {
 	Status                ResourceTransformationStatusType `json:"status"`
 	Reason                string                           `json:"reason"`
 	Specs                 TransformSpecs                   `json:"specs"`
+	// Diff is a unified diff of the resource before and after it was
+	// transformed during the dry-run
+	Diff string `json:"diff,omitempty"`
 }
 
 // ResourceTransformationSpec is used to update k8s resources
 //before migration/restore
 type ResourceTransformationSpec struct {
 	Objects []TransformSpecs `json:"transformSpecs"`
+	// ClusterPair in the namespace of the transformation that is used for
+	// .ClusterPair in templates during the dry-run. Defaults to the
+	// ClusterPair of a Migration or MigrationSchedule that uses the
+	// transformation.
+	// +optional
+	ClusterPair string `json:"clusterPair,omitempty"`
 }
 
 // TransformSpecs specifies the patch to update selected resource
@@ -112,6 +148,21 @@ type TransformSpecs struct {
 	Selectors map[string]string `json:"selectors"`
 	// Paths collection of resource path to update
 	Paths []ResourcePaths `json:"paths"`
+	// Conditions on the fields of a resource, all of which need to match
+	// for the resource to be patched
+	Conditions []ResourceCondition `json:"conditions,omitempty"`
+}
+
+// ResourceCondition matches the value at a path of a resource
+type ResourceCondition struct {
+	// Path of the field in the resource, elements of lists can be matched
+	// with [*], for example spec.template.spec.containers[*].image. The
+	// condition matches if any of the elements match.
+	Path string `json:"path"`
+	// Operator used to match the value
+	Operator ResourceTransformationConditionOperator `json:"operator"`
+	// Values to match for the In, NotIn and Matches operators
+	Values []string `json:"values,omitempty"`
 }
 
 type TransformSpecPatch struct {
@@ -132,8 +183,23 @@ type ResourcePaths struct {
 	// Type of value specified int/bool/string/slice/keypair
 	Type ResourceTransformationValueType `json:"type"`
 	// Operation to be performed on path
-	// add/modify/delete/replace/jsonPatch
+	// add/modify/delete/replace/jsonPatch/regexreplace
 	Operation ResourceTransformationOperationType `json:"operation"`
+	// Pattern is the regular expression replaced for the regexreplace
+	// operation
+	Pattern string `json:"pattern,omitempty"`
+	// Template is a go template used to generate the value instead of
+	// Value. It is executed with .Object set to the resource, .Value set to
+	// the current value at the path, .Lookup set to Lookup and .ClusterPair
+	// set to the name, namespace, labels and annotations of the ClusterPair
+	// of the migration. Referencing a missing key of a map is an error.
+	Template string `json:"template,omitempty"`
+	// Lookup is a table that can be used in the template with the lookup
+	// function, which fails for keys that aren't in the table
+	Lookup map[string]string `json:"lookup,omitempty"`
+	// Conditions on the fields of the resource, all of which need to match
+	// for this path to be updated
+	Conditions []ResourceCondition `json:"conditions,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
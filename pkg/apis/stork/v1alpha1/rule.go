@@ -7,11 +7,25 @@ import (
 const (
 	// RuleActionCommand is a command action
 	RuleActionCommand RuleActionType = "command"
+	// RuleActionHTTP is an action that sends an HTTP request to the pods or
+	// to a service
+	RuleActionHTTP RuleActionType = "http"
 )
 
 // RuleActionType is a type for actions that are supported in a stork rule
 type RuleActionType string
 
+const (
+	// RuleActionFailurePolicyFail fails the rule if the action fails
+	RuleActionFailurePolicyFail RuleActionFailurePolicy = "fail"
+	// RuleActionFailurePolicyContinue continues with the rest of the rule if
+	// the action fails
+	RuleActionFailurePolicyContinue RuleActionFailurePolicy = "continue"
+)
+
+// RuleActionFailurePolicy is what to do when an action in a rule fails
+type RuleActionFailurePolicy string
+
 // +genclient
 // +genclient:noStatus
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -47,6 +61,128 @@ type RuleAction struct {
 	RunInSinglePod bool `json:"runInSinglePod,omitempty"`
 	// Value is the actual action value for e.g the command to run
 	Value string `json:"value"`
+	// HTTP is the request to send for http actions
+	// +optional
+	HTTP *RuleHTTPAction `json:"http,omitempty"`
+	// Timeout in seconds for the action to finish on each pod. Defaults to 15
+	// minutes for background commands, 30 seconds for each http request and
+	// no timeout for other commands.
+	// +optional
+	Timeout int64 `json:"timeout,omitempty"`
+	// Retries is the number of times the action is retried on each pod if it
+	// fails
+	// +optional
+	Retries *int `json:"retries,omitempty"`
+	// OnFailure is what to do if the action fails. Defaults to fail.
+	// +optional
+	OnFailure RuleActionFailurePolicy `json:"onFailure,omitempty"`
+}
+
+// RuleHTTPAction is an HTTP request sent by a rule action
+type RuleHTTPAction struct {
+	// Service is the name of the service in the namespace of the pods to send
+	// the request to. If empty the request is sent to each of the selected
+	// pods.
+	// +optional
+	Service string `json:"service,omitempty"`
+	// Port to send the request to
+	Port int32 `json:"port"`
+	// Scheme to use for the request, http or https. Defaults to https if
+	// TLSSecret, ServerName or InsecureSkipVerify is set and http otherwise.
+	// +optional
+	Scheme string `json:"scheme,omitempty"`
+	// Method of the request. Defaults to GET.
+	// +optional
+	Method string `json:"method,omitempty"`
+	// Path of the request
+	// +optional
+	Path string `json:"path,omitempty"`
+	// Headers to add to the request
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+	// Body of the request
+	// +optional
+	Body string `json:"body,omitempty"`
+	// ExpectedStatus are the status codes for a successful request. Defaults
+	// to any 2xx status code.
+	// +optional
+	ExpectedStatus []int `json:"expectedStatus,omitempty"`
+	// TLSSecret is the name of a secret in the namespace of the pods with the
+	// CA certificate (ca.crt) to verify the server and optionally a client
+	// certificate (tls.crt and tls.key)
+	// +optional
+	TLSSecret string `json:"tlsSecret,omitempty"`
+	// ServerName is the name used to verify the certificate of the server.
+	// Defaults to the host of the request, which is the IP of the pod if no
+	// service is set.
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+	// InsecureSkipVerify disables the verification of the certificate of the
+	// server
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// RuleExecutionStatusType is the status of running a rule or an action
+type RuleExecutionStatusType string
+
+const (
+	// RuleExecutionStatusSuccessful for rules or actions that succeeded
+	RuleExecutionStatusSuccessful RuleExecutionStatusType = "Successful"
+	// RuleExecutionStatusFailed for rules or actions that failed
+	RuleExecutionStatusFailed RuleExecutionStatusType = "Failed"
+)
+
+// RuleExecutionResult is the result of running a rule for an object
+type RuleExecutionResult struct {
+	// Rule is the name of the rule
+	Rule string `json:"rule"`
+	// Type of the rule, preExecRule or postExecRule
+	Type string `json:"type"`
+	// Namespace of the pods the rule was run on
+	Namespace string `json:"namespace"`
+	// Status of the rule
+	Status RuleExecutionStatusType `json:"status"`
+	// Reason the rule failed
+	Reason          string    `json:"reason,omitempty"`
+	StartTimestamp  meta.Time `json:"startTimestamp"`
+	FinishTimestamp meta.Time `json:"finishTimestamp"`
+	// Actions are the results of the actions of the rule on each pod. If
+	// there are too many only some of the failed actions are kept.
+	Actions []*RuleActionResult `json:"actions,omitempty"`
+	// SuccessfulActions is the number of actions that succeeded
+	SuccessfulActions int `json:"successfulActions,omitempty"`
+	// FailedActions is the number of actions that failed
+	FailedActions int `json:"failedActions,omitempty"`
+}
+
+// RuleActionResult is the result of running an action of a rule on a pod
+type RuleActionResult struct {
+	// ItemIndex is the index of the item of the action in the rule
+	ItemIndex int `json:"itemIndex"`
+	// ActionIndex is the index of the action in the rule item
+	ActionIndex int `json:"actionIndex"`
+	// Type of the action
+	Type RuleActionType `json:"type"`
+	// Pod the action was run on. Empty for http actions sent to a service.
+	Pod string `json:"pod,omitempty"`
+	// Container the action was run in
+	Container string `json:"container,omitempty"`
+	// Status of the action
+	Status RuleExecutionStatusType `json:"status"`
+	// ExitCode of the command if it could be determined
+	ExitCode *int `json:"exitCode,omitempty"`
+	// HTTPStatus is the status code of the response for http actions
+	HTTPStatus int `json:"httpStatus,omitempty"`
+	// Duration of the action including the retries
+	Duration meta.Duration `json:"duration"`
+	// Stdout is the output of the command or the body of the response,
+	// truncated to the last 1KB
+	Stdout string `json:"stdout,omitempty"`
+	// Stderr is the error output of the command, truncated to the last 1KB
+	Stderr string `json:"stderr,omitempty"`
+	// Reason the action failed
+	Reason string `json:"reason,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -41,12 +41,15 @@ const (
 	SchedulePolicyTypeWeekly SchedulePolicyType = "Weekly"
 	// SchedulePolicyTypeMonthly is the type for a monthly schedule policy
 	SchedulePolicyTypeMonthly SchedulePolicyType = "Monthly"
+	// SchedulePolicyTypeCron is the type for a schedule policy with a cron
+	// expression
+	SchedulePolicyTypeCron SchedulePolicyType = "Cron"
 )
 
 // GetValidSchedulePolicyTypes returns the valid types of schedule policies that
 // can be configured
 func GetValidSchedulePolicyTypes() []SchedulePolicyType {
-	return []SchedulePolicyType{SchedulePolicyTypeInterval, SchedulePolicyTypeDaily, SchedulePolicyTypeWeekly, SchedulePolicyTypeMonthly}
+	return []SchedulePolicyType{SchedulePolicyTypeInterval, SchedulePolicyTypeDaily, SchedulePolicyTypeWeekly, SchedulePolicyTypeMonthly, SchedulePolicyTypeCron}
 }
 
 // Days is a map of valid Day strings
@@ -81,6 +84,28 @@ type SchedulePolicyItem struct {
 	// Monthly policy that will be triggered on the specified date of the month
 	// at the specified time
 	Monthly *MonthlyPolicy `json:"monthly"`
+	// Cron policy that will be triggered at the times matching a cron
+	// expression
+	Cron *CronPolicy `json:"cron,omitempty"`
+	// Timezone is the IANA name of the time zone used for the Daily, Weekly,
+	// Monthly and Cron policies, for example Europe/Berlin. Defaults to the
+	// local time zone of stork.
+	Timezone string `json:"timezone,omitempty"`
+	// BlackoutWindows are the periods during which no scheduled actions are
+	// started. Runs that are due during a window are deferred until it ends.
+	BlackoutWindows []BlackoutWindow `json:"blackoutWindows,omitempty"`
+	// Retention configures time based and tiered retention for the objects
+	// created by a schedule across all its policy types. When set it is used
+	// instead of the Retain count of each policy type.
+	Retention *RetentionPolicy `json:"retention,omitempty"`
+}
+
+// GetLocation returns the location for the timezone of the policy
+func (s *SchedulePolicyItem) GetLocation() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(s.Timezone)
 }
 
 // Retain Type to specify how many objects should be retained for a policy
@@ -228,6 +253,223 @@ func (m *MonthlyPolicy) Validate() error {
 	return nil
 }
 
+// DefaultCronPolicyRetain Default for objects to be retained for the cron
+// policy
+const DefaultCronPolicyRetain = Retain(10)
+
+// CronPolicy contains the cron expression for when an action should be
+// executed
+type CronPolicy struct {
+	// Expression is a standard 5 field cron expression (minute, hour, day of
+	// month, month and day of week) eg "30 2 * * 1-5" for 2:30AM every weekday.
+	// DAY#N can be used in the day of week field for the Nth occurrence of
+	// the day in the month eg "0 0 * * SUN#1" for the first Sunday
+	Expression string `json:"expression"`
+	// Retain Number of objects to retain for cron policy. Defaults to
+	// @DefaultCronPolicyRetain
+	Retain Retain `json:"retain"`
+	// Options to be passed in to the driver. These will be passed in
+	// to the object being triggered
+	Options map[string]string `json:"options"`
+}
+
+// BlackoutWindow is a period of time during which no scheduled actions are
+// started. The times are in the timezone of the policy.
+type BlackoutWindow struct {
+	// Days of the week on which the window starts. Valid formats are
+	// specified in `Days` above. Defaults to every day.
+	Days []string `json:"days,omitempty"`
+	// StartTime of the window. Expected format is time.Kitchen eg 10:00PM
+	StartTime string `json:"startTime"`
+	// EndTime of the window. Expected format is time.Kitchen eg 06:00AM. If
+	// it isn't after StartTime the window ends on the next day.
+	EndTime string `json:"endTime"`
+}
+
+// GetStartHourMinute parses and return the hour and minute when the window
+// starts
+func (b *BlackoutWindow) GetStartHourMinute() (int, int, error) {
+	return getHourMinute(b.StartTime)
+}
+
+// GetEndHourMinute parses and return the hour and minute when the window ends
+func (b *BlackoutWindow) GetEndHourMinute() (int, int, error) {
+	return getHourMinute(b.EndTime)
+}
+
+// Validate validates a BlackoutWindow
+func (b *BlackoutWindow) Validate() error {
+	if _, _, err := b.GetStartHourMinute(); err != nil {
+		return fmt.Errorf("Invalid start time (%v) in blackout window: %v", b.StartTime, err)
+	}
+	if _, _, err := b.GetEndHourMinute(); err != nil {
+		return fmt.Errorf("Invalid end time (%v) in blackout window: %v", b.EndTime, err)
+	}
+	for _, day := range b.Days {
+		if _, present := Days[day]; !present {
+			return fmt.Errorf("Invalid day of the week (%v) in blackout window", day)
+		}
+	}
+	return nil
+}
+
+// String returns the window in a readable format
+func (b *BlackoutWindow) String() string {
+	if len(b.Days) == 0 {
+		return fmt.Sprintf("%v-%v", b.StartTime, b.EndTime)
+	}
+	return fmt.Sprintf("%v@%v-%v", strings.Join(b.Days, ","), b.StartTime, b.EndTime)
+}
+
+// RetentionTierType is the period used to pick the objects retained by a tier
+type RetentionTierType string
+
+const (
+	// RetentionTierHourly retains the latest object in each hour
+	RetentionTierHourly RetentionTierType = "Hourly"
+	// RetentionTierDaily retains the latest object in each day
+	RetentionTierDaily RetentionTierType = "Daily"
+	// RetentionTierWeekly retains the latest object in each ISO week
+	RetentionTierWeekly RetentionTierType = "Weekly"
+	// RetentionTierMonthly retains the latest object in each month
+	RetentionTierMonthly RetentionTierType = "Monthly"
+	// RetentionTierYearly retains the latest object in each year
+	RetentionTierYearly RetentionTierType = "Yearly"
+)
+
+// GetValidRetentionTierTypes returns the valid types of retention tiers
+func GetValidRetentionTierTypes() []RetentionTierType {
+	return []RetentionTierType{
+		RetentionTierHourly,
+		RetentionTierDaily,
+		RetentionTierWeekly,
+		RetentionTierMonthly,
+		RetentionTierYearly,
+	}
+}
+
+// RetentionPolicy configures which successful objects created by a schedule
+// are retained. An object is retained if it is newer than MaxAgeDays or if
+// any of the tiers claims it. The latest successful object is always
+// retained. Failed objects are retained separately, up to FailedKeep.
+type RetentionPolicy struct {
+	// MaxAgeDays retains all the objects created in the last number of days
+	MaxAgeDays int `json:"maxAgeDays,omitempty"`
+	// Tiers of grandfather-father-son retention. The same object can be
+	// claimed by more than one tier, eg the latest object of a month can
+	// also be the latest object of its week and day.
+	Tiers []RetentionTier `json:"tiers,omitempty"`
+	// FailedKeep is the number of the most recent failed objects that are
+	// retained, as long as they are newer than the oldest retained
+	// successful object. Defaults to DefaultRetentionFailedKeep.
+	FailedKeep int `json:"failedKeep,omitempty"`
+}
+
+// DefaultRetentionFailedKeep is the default number of failed objects kept by
+// a retention policy
+const DefaultRetentionFailedKeep = 5
+
+// GetFailedKeep returns the number of failed objects to retain
+func (r *RetentionPolicy) GetFailedKeep() int {
+	if r.FailedKeep == 0 {
+		return DefaultRetentionFailedKeep
+	}
+	return r.FailedKeep
+}
+
+// Validate validates a RetentionPolicy
+func (r *RetentionPolicy) Validate() error {
+	if r.MaxAgeDays < 0 {
+		return fmt.Errorf("Invalid maxAgeDays (%v) in retention policy", r.MaxAgeDays)
+	}
+	if r.FailedKeep < 0 {
+		return fmt.Errorf("Invalid failedKeep (%v) in retention policy", r.FailedKeep)
+	}
+	if r.MaxAgeDays == 0 && len(r.Tiers) == 0 {
+		return fmt.Errorf("Retention policy should have either maxAgeDays or tiers")
+	}
+	seen := make(map[RetentionTierType]bool)
+	for _, tier := range r.Tiers {
+		if err := tier.Validate(); err != nil {
+			return err
+		}
+		if seen[tier.Type] {
+			return fmt.Errorf("Duplicate retention tier %v", tier.Type)
+		}
+		seen[tier.Type] = true
+	}
+	return nil
+}
+
+// RetentionTier retains the latest object in each period of the tier
+type RetentionTier struct {
+	// Type of the tier
+	Type RetentionTierType `json:"type"`
+	// Keep is the number of most recent periods for which the latest object
+	// is retained
+	Keep int `json:"keep,omitempty"`
+	// MaxAgeDays only retains the objects for the periods in the last number
+	// of days. If Keep isn't set all the periods in that time are retained.
+	MaxAgeDays int `json:"maxAgeDays,omitempty"`
+}
+
+// Validate validates a RetentionTier
+func (r *RetentionTier) Validate() error {
+	valid := false
+	for _, tierType := range GetValidRetentionTierTypes() {
+		if r.Type == tierType {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return fmt.Errorf("Invalid retention tier type (%v)", r.Type)
+	}
+	if r.Keep < 0 || r.MaxAgeDays < 0 {
+		return fmt.Errorf("Invalid keep (%v) or maxAgeDays (%v) for %v retention tier", r.Keep, r.MaxAgeDays, r.Type)
+	}
+	if r.Keep == 0 && r.MaxAgeDays == 0 {
+		return fmt.Errorf("%v retention tier should have either keep or maxAgeDays", r.Type)
+	}
+	return nil
+}
+
+// CatchUpPolicyType is how a schedule handles runs that couldn't be started
+// before their starting deadline
+type CatchUpPolicyType string
+
+const (
+	// CatchUpPolicySkip skips the runs that missed their starting deadline
+	CatchUpPolicySkip CatchUpPolicyType = "Skip"
+	// CatchUpPolicyRunOnce starts one run for all the runs that missed their
+	// starting deadline
+	CatchUpPolicyRunOnce CatchUpPolicyType = "RunOnce"
+	// CatchUpPolicyRunAll starts each of the runs that missed their starting
+	// deadline, one after the other
+	CatchUpPolicyRunAll CatchUpPolicyType = "RunAll"
+)
+
+// MissedScheduleRunAction is what happened to a run that wasn't started at
+// its scheduled time
+type MissedScheduleRunAction string
+
+const (
+	// MissedScheduleRunSkipped is for runs that won't be started
+	MissedScheduleRunSkipped MissedScheduleRunAction = "Skipped"
+	// MissedScheduleRunDeferred is for runs that were due during a blackout
+	// window
+	MissedScheduleRunDeferred MissedScheduleRunAction = "Deferred"
+)
+
+// MissedScheduleRun keeps track of a run of a schedule that wasn't started
+// at its scheduled time
+type MissedScheduleRun struct {
+	PolicyType         SchedulePolicyType      `json:"policyType"`
+	ScheduledTimestamp meta.Time               `json:"scheduledTimestamp"`
+	Action             MissedScheduleRunAction `json:"action"`
+	Reason             string                  `json:"reason"`
+}
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // SchedulePolicyList is a list of schedule policies
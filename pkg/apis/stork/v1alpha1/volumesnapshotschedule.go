@@ -20,6 +20,13 @@ type VolumeSnapshotScheduleSpec struct {
 	ReclaimPolicy      ReclaimPolicyType          `json:"reclaimPolicy"`
 	PreExecRule        string                     `json:"preExecRule"`
 	PostExecRule       string                     `json:"postExecRule"`
+	// StartingDeadlineSeconds is how long after its scheduled time a run can
+	// still be started. Defaults to one hour. Doesn't apply to Interval
+	// policies.
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+	// CatchUpPolicy is how runs that missed their starting deadline are
+	// handled. Defaults to Skip.
+	CatchUpPolicy CatchUpPolicyType `json:"catchUpPolicy,omitempty"`
 }
 
 // VolumeSnapshotTemplateSpec describes the data a VolumeSnapshot should have when created
@@ -43,6 +50,8 @@ const (
 // VolumeSnapshotScheduleStatus is the status of a volumesnapshot schedule
 type VolumeSnapshotScheduleStatus struct {
 	Items map[SchedulePolicyType][]*ScheduledVolumeSnapshotStatus `json:"items"`
+	// MissedRuns are the most recent runs that were skipped or deferred
+	MissedRuns []*MissedScheduleRun `json:"missedRuns,omitempty"`
 }
 
 // ScheduledVolumeSnapshotStatus keeps track of the volumesnapshot that was triggered by a
@@ -52,6 +61,11 @@ type ScheduledVolumeSnapshotStatus struct {
 	CreationTimestamp meta.Time                          `json:"creationTimestamp"`
 	FinishTimestamp   meta.Time                          `json:"finishTimestamp"`
 	Status            snapv1.VolumeSnapshotConditionType `json:"status"`
+	// ScheduledTimestamp is the time the run was scheduled for
+	ScheduledTimestamp meta.Time `json:"scheduledTimestamp,omitempty"`
+	// RetentionTiers are the tiers of the retention policy that retain the
+	// object
+	RetentionTiers []RetentionTierType `json:"retentionTiers,omitempty"`
 }
 
 // +genclient
@@ -27,6 +27,168 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationActivation) DeepCopyInto(out *ApplicationActivation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationActivation.
+func (in *ApplicationActivation) DeepCopy() *ApplicationActivation {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationActivation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApplicationActivation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationActivationList) DeepCopyInto(out *ApplicationActivationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ApplicationActivation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationActivationList.
+func (in *ApplicationActivationList) DeepCopy() *ApplicationActivationList {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationActivationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApplicationActivationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationActivationResourceInfo) DeepCopyInto(out *ApplicationActivationResourceInfo) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationActivationResourceInfo.
+func (in *ApplicationActivationResourceInfo) DeepCopy() *ApplicationActivationResourceInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationActivationResourceInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationActivationSpec) DeepCopyInto(out *ApplicationActivationSpec) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]ApplicationActivationStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationActivationSpec.
+func (in *ApplicationActivationSpec) DeepCopy() *ApplicationActivationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationActivationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationActivationStatus) DeepCopyInto(out *ApplicationActivationStatus) {
+	*out = *in
+	in.StepStartTimestamp.DeepCopyInto(&out.StepStartTimestamp)
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]*ApplicationActivationResourceInfo, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(ApplicationActivationResourceInfo)
+				**out = **in
+			}
+		}
+	}
+	in.FinishTimestamp.DeepCopyInto(&out.FinishTimestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationActivationStatus.
+func (in *ApplicationActivationStatus) DeepCopy() *ApplicationActivationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationActivationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationActivationStep) DeepCopyInto(out *ApplicationActivationStep) {
+	*out = *in
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selectors != nil {
+		in, out := &in.Selectors, &out.Selectors
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationActivationStep.
+func (in *ApplicationActivationStep) DeepCopy() *ApplicationActivationStep {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationActivationStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApplicationBackup) DeepCopyInto(out *ApplicationBackup) {
 	*out = *in
@@ -175,6 +337,11 @@ func (in *ApplicationBackupScheduleSpec) DeepCopyInto(out *ApplicationBackupSche
 		*out = new(bool)
 		**out = **in
 	}
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
 	return
 }
 
@@ -212,6 +379,17 @@ func (in *ApplicationBackupScheduleStatus) DeepCopyInto(out *ApplicationBackupSc
 			(*out)[key] = outVal
 		}
 	}
+	if in.MissedRuns != nil {
+		in, out := &in.MissedRuns, &out.MissedRuns
+		*out = make([]*MissedScheduleRun, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(MissedScheduleRun)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
 	return
 }
 
@@ -298,6 +476,17 @@ func (in *ApplicationBackupStatus) DeepCopyInto(out *ApplicationBackupStatus) {
 	in.TriggerTimestamp.DeepCopyInto(&out.TriggerTimestamp)
 	in.LastUpdateTimestamp.DeepCopyInto(&out.LastUpdateTimestamp)
 	in.FinishTimestamp.DeepCopyInto(&out.FinishTimestamp)
+	if in.RuleResults != nil {
+		in, out := &in.RuleResults, &out.RuleResults
+		*out = make([]*RuleExecutionResult, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(RuleExecutionResult)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
 	return
 }
 
@@ -328,6 +517,127 @@ func (in *ApplicationBackupTemplateSpec) DeepCopy() *ApplicationBackupTemplateSp
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationBackupVerification) DeepCopyInto(out *ApplicationBackupVerification) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationBackupVerification.
+func (in *ApplicationBackupVerification) DeepCopy() *ApplicationBackupVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationBackupVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApplicationBackupVerification) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationBackupVerificationList) DeepCopyInto(out *ApplicationBackupVerificationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ApplicationBackupVerification, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationBackupVerificationList.
+func (in *ApplicationBackupVerificationList) DeepCopy() *ApplicationBackupVerificationList {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationBackupVerificationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApplicationBackupVerificationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationBackupVerificationObjectInfo) DeepCopyInto(out *ApplicationBackupVerificationObjectInfo) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationBackupVerificationObjectInfo.
+func (in *ApplicationBackupVerificationObjectInfo) DeepCopy() *ApplicationBackupVerificationObjectInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationBackupVerificationObjectInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationBackupVerificationSpec) DeepCopyInto(out *ApplicationBackupVerificationSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationBackupVerificationSpec.
+func (in *ApplicationBackupVerificationSpec) DeepCopy() *ApplicationBackupVerificationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationBackupVerificationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationBackupVerificationStatus) DeepCopyInto(out *ApplicationBackupVerificationStatus) {
+	*out = *in
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = make([]*ApplicationBackupVerificationObjectInfo, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(ApplicationBackupVerificationObjectInfo)
+				**out = **in
+			}
+		}
+	}
+	in.FinishTimestamp.DeepCopyInto(&out.FinishTimestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationBackupVerificationStatus.
+func (in *ApplicationBackupVerificationStatus) DeepCopy() *ApplicationBackupVerificationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationBackupVerificationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApplicationBackupVolumeInfo) DeepCopyInto(out *ApplicationBackupVolumeInfo) {
 	*out = *in
@@ -854,6 +1164,11 @@ func (in *BackupLocationItem) DeepCopyInto(out *BackupLocationItem) {
 		*out = new(GoogleConfig)
 		**out = **in
 	}
+	if in.EncryptionKeyProvider != nil {
+		in, out := &in.EncryptionKeyProvider, &out.EncryptionKeyProvider
+		*out = new(EncryptionKeyProvider)
+		**out = **in
+	}
 	return
 }
 
@@ -900,6 +1215,27 @@ func (in *BackupLocationList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlackoutWindow) DeepCopyInto(out *BlackoutWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlackoutWindow.
+func (in *BlackoutWindow) DeepCopy() *BlackoutWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(BlackoutWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterDomainInfo) DeepCopyInto(out *ClusterDomainInfo) {
 	*out = *in
@@ -1223,6 +1559,29 @@ func (in *ClusterPairStatus) DeepCopy() *ClusterPairStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CronPolicy) DeepCopyInto(out *CronPolicy) {
+	*out = *in
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronPolicy.
+func (in *CronPolicy) DeepCopy() *CronPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CronPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DailyPolicy) DeepCopyInto(out *DailyPolicy) {
 	*out = *in
@@ -1367,6 +1726,22 @@ func (in *DataExportSpec) DeepCopy() *DataExportSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EncryptionKeyProvider) DeepCopyInto(out *EncryptionKeyProvider) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EncryptionKeyProvider.
+func (in *EncryptionKeyProvider) DeepCopy() *EncryptionKeyProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(EncryptionKeyProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExportStatus) DeepCopyInto(out *ExportStatus) {
 	*out = *in
@@ -1687,6 +2062,11 @@ func (in *MigrationScheduleSpec) DeepCopyInto(out *MigrationScheduleSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
 	return
 }
 
@@ -1724,6 +2104,17 @@ func (in *MigrationScheduleStatus) DeepCopyInto(out *MigrationScheduleStatus) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.MissedRuns != nil {
+		in, out := &in.MissedRuns, &out.MissedRuns
+		*out = make([]*MissedScheduleRun, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(MissedScheduleRun)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
 	return
 }
 
@@ -1792,6 +2183,13 @@ func (in *MigrationSpec) DeepCopyInto(out *MigrationSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.StorageClassMapping != nil {
+		in, out := &in.StorageClassMapping, &out.StorageClassMapping
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -1838,6 +2236,17 @@ func (in *MigrationStatus) DeepCopyInto(out *MigrationStatus) {
 		*out = new(MigrationSummary)
 		**out = **in
 	}
+	if in.RuleResults != nil {
+		in, out := &in.RuleResults, &out.RuleResults
+		*out = make([]*RuleExecutionResult, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(RuleExecutionResult)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
 	return
 }
 
@@ -1900,6 +2309,23 @@ func (in *MigrationVolumeInfo) DeepCopy() *MigrationVolumeInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MissedScheduleRun) DeepCopyInto(out *MissedScheduleRun) {
+	*out = *in
+	in.ScheduledTimestamp.DeepCopyInto(&out.ScheduledTimestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MissedScheduleRun.
+func (in *MissedScheduleRun) DeepCopy() *MissedScheduleRun {
+	if in == nil {
+		return nil
+	}
+	out := new(MissedScheduleRun)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MonthlyPolicy) DeepCopyInto(out *MonthlyPolicy) {
 	*out = *in
@@ -2079,9 +2505,44 @@ func (in *RancherSpec) DeepCopy() *RancherSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceCondition) DeepCopyInto(out *ResourceCondition) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceCondition.
+func (in *ResourceCondition) DeepCopy() *ResourceCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourcePaths) DeepCopyInto(out *ResourcePaths) {
 	*out = *in
+	if in.Lookup != nil {
+		in, out := &in.Lookup, &out.Lookup
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ResourceCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -2194,6 +2655,43 @@ func (in *RestoreVolumeInfo) DeepCopy() *RestoreVolumeInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetentionPolicy) DeepCopyInto(out *RetentionPolicy) {
+	*out = *in
+	if in.Tiers != nil {
+		in, out := &in.Tiers, &out.Tiers
+		*out = make([]RetentionTier, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionPolicy.
+func (in *RetentionPolicy) DeepCopy() *RetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetentionTier) DeepCopyInto(out *RetentionTier) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionTier.
+func (in *RetentionTier) DeepCopy() *RetentionTier {
+	if in == nil {
+		return nil
+	}
+	out := new(RetentionTier)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Rule) DeepCopyInto(out *Rule) {
 	*out = *in
@@ -2230,6 +2728,16 @@ func (in *Rule) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RuleAction) DeepCopyInto(out *RuleAction) {
 	*out = *in
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(RuleHTTPAction)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(int)
+		**out = **in
+	}
 	return
 }
 
@@ -2243,6 +2751,85 @@ func (in *RuleAction) DeepCopy() *RuleAction {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuleActionResult) DeepCopyInto(out *RuleActionResult) {
+	*out = *in
+	if in.ExitCode != nil {
+		in, out := &in.ExitCode, &out.ExitCode
+		*out = new(int)
+		**out = **in
+	}
+	out.Duration = in.Duration
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleActionResult.
+func (in *RuleActionResult) DeepCopy() *RuleActionResult {
+	if in == nil {
+		return nil
+	}
+	out := new(RuleActionResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuleExecutionResult) DeepCopyInto(out *RuleExecutionResult) {
+	*out = *in
+	in.StartTimestamp.DeepCopyInto(&out.StartTimestamp)
+	in.FinishTimestamp.DeepCopyInto(&out.FinishTimestamp)
+	if in.Actions != nil {
+		in, out := &in.Actions, &out.Actions
+		*out = make([]*RuleActionResult, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(RuleActionResult)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleExecutionResult.
+func (in *RuleExecutionResult) DeepCopy() *RuleExecutionResult {
+	if in == nil {
+		return nil
+	}
+	out := new(RuleExecutionResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuleHTTPAction) DeepCopyInto(out *RuleHTTPAction) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExpectedStatus != nil {
+		in, out := &in.ExpectedStatus, &out.ExpectedStatus
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleHTTPAction.
+func (in *RuleHTTPAction) DeepCopy() *RuleHTTPAction {
+	if in == nil {
+		return nil
+	}
+	out := new(RuleHTTPAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RuleItem) DeepCopyInto(out *RuleItem) {
 	*out = *in
@@ -2256,7 +2843,9 @@ func (in *RuleItem) DeepCopyInto(out *RuleItem) {
 	if in.Actions != nil {
 		in, out := &in.Actions, &out.Actions
 		*out = make([]RuleAction, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
@@ -2370,6 +2959,23 @@ func (in *SchedulePolicyItem) DeepCopyInto(out *SchedulePolicyItem) {
 		*out = new(MonthlyPolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Cron != nil {
+		in, out := &in.Cron, &out.Cron
+		*out = new(CronPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BlackoutWindows != nil {
+		in, out := &in.BlackoutWindows, &out.BlackoutWindows
+		*out = make([]BlackoutWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(RetentionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -2421,6 +3027,12 @@ func (in *ScheduledApplicationBackupStatus) DeepCopyInto(out *ScheduledApplicati
 	*out = *in
 	in.CreationTimestamp.DeepCopyInto(&out.CreationTimestamp)
 	in.FinishTimestamp.DeepCopyInto(&out.FinishTimestamp)
+	in.ScheduledTimestamp.DeepCopyInto(&out.ScheduledTimestamp)
+	if in.RetentionTiers != nil {
+		in, out := &in.RetentionTiers, &out.RetentionTiers
+		*out = make([]RetentionTierType, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -2439,6 +3051,12 @@ func (in *ScheduledMigrationStatus) DeepCopyInto(out *ScheduledMigrationStatus)
 	*out = *in
 	in.CreationTimestamp.DeepCopyInto(&out.CreationTimestamp)
 	in.FinishTimestamp.DeepCopyInto(&out.FinishTimestamp)
+	in.ScheduledTimestamp.DeepCopyInto(&out.ScheduledTimestamp)
+	if in.RetentionTiers != nil {
+		in, out := &in.RetentionTiers, &out.RetentionTiers
+		*out = make([]RetentionTierType, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -2457,6 +3075,12 @@ func (in *ScheduledVolumeSnapshotStatus) DeepCopyInto(out *ScheduledVolumeSnapsh
 	*out = *in
 	in.CreationTimestamp.DeepCopyInto(&out.CreationTimestamp)
 	in.FinishTimestamp.DeepCopyInto(&out.FinishTimestamp)
+	in.ScheduledTimestamp.DeepCopyInto(&out.ScheduledTimestamp)
+	if in.RetentionTiers != nil {
+		in, out := &in.RetentionTiers, &out.RetentionTiers
+		*out = make([]RetentionTierType, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -2499,7 +3123,16 @@ func (in *TransformSpecs) DeepCopyInto(out *TransformSpecs) {
 	if in.Paths != nil {
 		in, out := &in.Paths, &out.Paths
 		*out = make([]ResourcePaths, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ResourceCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
@@ -2695,6 +3328,11 @@ func (in *VolumeSnapshotScheduleSpec) DeepCopyInto(out *VolumeSnapshotScheduleSp
 		*out = new(bool)
 		**out = **in
 	}
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
 	return
 }
 
@@ -2732,6 +3370,17 @@ func (in *VolumeSnapshotScheduleStatus) DeepCopyInto(out *VolumeSnapshotSchedule
 			(*out)[key] = outVal
 		}
 	}
+	if in.MissedRuns != nil {
+		in, out := &in.MissedRuns, &out.MissedRuns
+		*out = make([]*MissedScheduleRun, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(MissedScheduleRun)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
 	return
 }
 
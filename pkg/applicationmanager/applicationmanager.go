@@ -56,6 +56,11 @@ func (a *ApplicationManager) Init(mgr manager.Manager, adminNamespace string, st
 	if err := scheduleController.Init(mgr); err != nil {
 		return err
 	}
+
+	verificationController := controllers.NewApplicationBackupVerification(mgr, a.Recorder)
+	if err := verificationController.Init(mgr); err != nil {
+		return err
+	}
 	syncController := &controllers.BackupSyncController{
 		Recorder:     a.Recorder,
 		SyncInterval: 1 * time.Minute,
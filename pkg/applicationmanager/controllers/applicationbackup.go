@@ -18,8 +18,8 @@ import (
 	"github.com/libopenstorage/stork/drivers/volume"
 	"github.com/libopenstorage/stork/pkg/apis/stork"
 	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/backupformat"
 	"github.com/libopenstorage/stork/pkg/controllers"
-	"github.com/libopenstorage/stork/pkg/crypto"
 	"github.com/libopenstorage/stork/pkg/errors"
 	"github.com/libopenstorage/stork/pkg/k8sutils"
 	"github.com/libopenstorage/stork/pkg/log"
@@ -51,7 +51,6 @@ const (
 	validateCRDInterval time.Duration = 5 * time.Second
 	validateCRDTimeout  time.Duration = 1 * time.Minute
 
-	resourceObjectName = "resources.json"
 	crdObjectName      = "crds.json"
 	nsObjectName       = "namespaces.json"
 	metadataObjectName = "metadata.json"
@@ -351,11 +350,14 @@ func (a *ApplicationBackupController) handle(ctx context.Context, backup *stork_
 				v1.EventTypeWarning,
 				string(stork_api.ApplicationBackupStatusFailed),
 				message)
+			// Keep the results of the rule across the Get
+			ruleResults := backup.Status.RuleResults
 			key := runtimeclient.ObjectKeyFromObject(backup)
 			err = a.client.Get(context.TODO(), key, backup)
 			if err != nil {
 				return err
 			}
+			backup.Status.RuleResults = ruleResults
 			backup.Status.Stage = stork_api.ApplicationBackupStageFinal
 			backup.Status.Status = stork_api.ApplicationBackupStatusFailed
 			backup.Status.Reason = message
@@ -591,6 +593,34 @@ func (a *ApplicationBackupController) backupVolumes(backup *stork_api.Applicatio
 		namespacedName.Namespace = backup.Namespace
 		namespacedName.Name = backup.Name
 		if len(backup.Status.Volumes) != pvcCount {
+			// Generic (KDMP) backups are out of scope for file backup
+			// locations since the data mover jobs only upload to object
+			// stores. Fail before any volume backup is started instead of
+			// after the other drivers have already taken snapshots.
+			if kdmpPVCs := pvcMappings[volume.KDMPDriverName]; len(kdmpPVCs) != 0 {
+				backupLocation, err := storkops.Instance().GetBackupLocation(backup.Spec.BackupLocation, backup.Namespace)
+				if err != nil {
+					return err
+				}
+				if backupLocation.Location.Type == stork_api.BackupLocationFile {
+					message := fmt.Sprintf("Generic backup of volumes is not supported for backup locations of type %v, "+
+						"%v PVCs would need it. Use an s3, azure or google backup location instead",
+						backupLocation.Location.Type, len(kdmpPVCs))
+					log.ApplicationBackupLog(backup).Errorf(message)
+					a.recorder.Event(backup,
+						v1.EventTypeWarning,
+						string(stork_api.ApplicationBackupStatusFailed),
+						message)
+					_, err = a.updateBackupCRInVolumeStage(
+						namespacedName,
+						stork_api.ApplicationBackupStatusFailed,
+						stork_api.ApplicationBackupStageFinal,
+						message,
+						nil,
+					)
+					return err
+				}
+			}
 
 			for driverName, pvcs := range pvcMappings {
 				var driver volume.Driver
@@ -670,7 +700,12 @@ func (a *ApplicationBackupController) backupVolumes(backup *stork_api.Applicatio
 			// If the driver combination of volumes are all non-kdmp, call the post exec rule immediately
 			if driverCombo == nonKdmpDriverOnly && backup.Spec.PostExecRule != "" {
 				err = a.runPostExecRule(backup)
-				if err != nil {
+				if err == nil {
+					// Save the results of the rule
+					if err := a.client.Update(context.TODO(), backup); err != nil {
+						log.ApplicationBackupLog(backup).Warnf("Failed to update results of PostExecRule: %v", err)
+					}
+				} else {
 					message := fmt.Sprintf("Error running PostExecRule: %v", err)
 					log.ApplicationBackupLog(backup).Errorf(message)
 					a.recorder.Event(backup,
@@ -889,6 +924,7 @@ func (a *ApplicationBackupController) runPreExecRule(backup *stork_api.Applicati
 	}
 
 	terminationChannels := make([]chan bool, 0)
+	ruleResults := make([]*stork_api.RuleExecutionResult, 0)
 	r, err := storkops.Instance().GetRule(backup.Spec.PreExecRule, backup.Namespace)
 	if err != nil {
 		// TODO: For now keep this as is from the existing code, not sure the use of this for loop
@@ -899,11 +935,13 @@ func (a *ApplicationBackupController) runPreExecRule(backup *stork_api.Applicati
 		return nil, false, err
 	}
 	for _, ns := range backup.Spec.Namespaces {
-		ch, err := rule.ExecuteRule(r, rule.PreExecRule, backup, ns)
+		ch, result, err := rule.ExecuteRuleWithResult(r, rule.PreExecRule, backup, ns)
+		ruleResults = append(ruleResults, result)
 		if err != nil {
 			for _, channel := range terminationChannels {
 				channel <- true
 			}
+			backup.Status.RuleResults = rule.AppendResults(backup.Status.RuleResults, ruleResults...)
 			return nil, false, fmt.Errorf("error executing PreExecRule for namespace %v: %v", ns, err)
 		}
 		if ch != nil {
@@ -921,6 +959,7 @@ func (a *ApplicationBackupController) runPreExecRule(backup *stork_api.Applicati
 		}
 		return nil, false, err
 	}
+	backup.Status.RuleResults = rule.AppendResults(backup.Status.RuleResults, ruleResults...)
 	return terminationChannels, false, nil
 }
 
@@ -930,7 +969,8 @@ func (a *ApplicationBackupController) runPostExecRule(backup *stork_api.Applicat
 		return err
 	}
 	for _, ns := range backup.Spec.Namespaces {
-		_, err = rule.ExecuteRule(r, rule.PostExecRule, backup, ns)
+		_, result, err := rule.ExecuteRuleWithResult(r, rule.PostExecRule, backup, ns)
+		backup.Status.RuleResults = rule.AppendResults(backup.Status.RuleResults, result)
 		if err != nil {
 			return fmt.Errorf("error executing PreExecRule for namespace %v: %v", ns, err)
 		}
@@ -958,6 +998,7 @@ func (a *ApplicationBackupController) uploadObject(
 	backup *stork_api.ApplicationBackup,
 	objectName string,
 	data []byte,
+	digests backupformat.IntegrityDigests,
 ) error {
 	backupLocation, err := storkops.Instance().GetBackupLocation(backup.Spec.BackupLocation, backup.Namespace)
 	if err != nil {
@@ -967,32 +1008,10 @@ func (a *ApplicationBackupController) uploadObject(
 	if err != nil {
 		return err
 	}
-	if backupLocation.Location.EncryptionKey != "" {
-		return fmt.Errorf("EncryptionKey is deprecated, use EncryptionKeyV2 instead")
-	}
-	if backupLocation.Location.EncryptionV2Key != "" {
-		if data, err = crypto.Encrypt(data, backupLocation.Location.EncryptionV2Key); err != nil {
-			return err
-		}
-	}
 
 	objectPath := GetObjectPath(backup)
-	writer, err := bucket.NewWriter(context.TODO(), filepath.Join(objectPath, objectName), nil)
-	if err != nil {
-		return err
-	}
-
-	_, err = writer.Write(data)
-	if err != nil {
-		closeErr := writer.Close()
-		if closeErr != nil {
-			log.ApplicationBackupLog(backup).Errorf("Error closing writer for objectstore: %v", closeErr)
-		}
-		return err
-	}
-	err = writer.Close()
-	if err != nil {
-		log.ApplicationBackupLog(backup).Errorf("Error closing writer for objectstore: %v", err)
+	if err := backupformat.WriteObject(context.TODO(), bucket, backupLocation, filepath.Join(objectPath, objectName), data, digests); err != nil {
+		log.ApplicationBackupLog(backup).Errorf("Error uploading %v to objectstore: %v", objectName, err)
 		return err
 	}
 	return nil
@@ -1002,27 +1021,49 @@ func (a *ApplicationBackupController) uploadObject(
 func (a *ApplicationBackupController) uploadResources(
 	backup *stork_api.ApplicationBackup,
 	objects []runtime.Unstructured,
+	digests backupformat.IntegrityDigests,
 ) error {
 	resKinds := make(map[string]string)
 	for _, obj := range objects {
 		gvk := obj.GetObjectKind().GroupVersionKind()
 		resKinds[gvk.Kind] = gvk.Version
 	}
-	if err := a.uploadNamespaces(backup); err != nil {
+	if err := a.uploadNamespaces(backup, digests); err != nil {
 		return err
 	}
 	// upload CRD to backuplocation
-	if err := a.uploadCRDResources(backup, resKinds); err != nil {
+	if err := a.uploadCRDResources(backup, resKinds, digests); err != nil {
+		return err
+	}
+	backupLocation, err := storkops.Instance().GetBackupLocation(backup.Spec.BackupLocation, backup.Namespace)
+	if err != nil {
+		return err
+	}
+	bucket, err := objectstore.GetBucket(backupLocation)
+	if err != nil {
+		return err
+	}
+	// Record the compression so that it is uploaded with the metadata
+	backup.Status.Compression = backupLocation.Location.Compression
+	// Stream the resources to chunks instead of marshalling all of them into
+	// one buffer
+	objectPath := GetObjectPath(backup)
+	if err := backupformat.UploadResources(context.TODO(), bucket, backupLocation, objectPath, objects, digests); err != nil {
 		return err
 	}
-	jsonBytes, err := json.MarshalIndent(objects, "", " ")
+	// Record the key the data key was wrapped with so that it can be checked
+	// before restoring or after rotating keys
+	dataKey, err := backupformat.GetDataKey(context.TODO(), bucket, objectPath)
 	if err != nil {
 		return err
 	}
-	// TODO: Encrypt if requested
-	return a.uploadObject(backup, resourceObjectName, jsonBytes)
+	if dataKey != nil {
+		backup.Status.EncryptionKeyID = dataKey.KeyID
+	}
+	return nil
 }
-func (a *ApplicationBackupController) uploadNamespaces(backup *stork_api.ApplicationBackup) error {
+
+func (a *ApplicationBackupController) uploadNamespaces(backup *stork_api.ApplicationBackup, digests backupformat.IntegrityDigests) error {
 	var namespaces []*v1.Namespace
 	for _, namespace := range backup.Spec.Namespaces {
 		ns, err := core.Instance().GetNamespace(namespace)
@@ -1036,13 +1077,13 @@ func (a *ApplicationBackupController) uploadNamespaces(backup *stork_api.Applica
 	if err != nil {
 		return err
 	}
-	if err := a.uploadObject(backup, nsObjectName, jsonBytes); err != nil {
+	if err := a.uploadObject(backup, nsObjectName, jsonBytes, digests); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (a *ApplicationBackupController) uploadCRDResources(backup *stork_api.ApplicationBackup, resKinds map[string]string) error {
+func (a *ApplicationBackupController) uploadCRDResources(backup *stork_api.ApplicationBackup, resKinds map[string]string, digests backupformat.IntegrityDigests) error {
 	crdList, err := storkops.Instance().ListApplicationRegistrations()
 	if err != nil {
 		return err
@@ -1091,7 +1132,7 @@ func (a *ApplicationBackupController) uploadCRDResources(backup *stork_api.Appli
 		if err != nil {
 			return err
 		}
-		if err := a.uploadObject(backup, crdObjectName, jsonBytes); err != nil {
+		if err := a.uploadObject(backup, crdObjectName, jsonBytes, digests); err != nil {
 			return err
 		}
 		return nil
@@ -1130,7 +1171,7 @@ func (a *ApplicationBackupController) uploadCRDResources(backup *stork_api.Appli
 	if err != nil {
 		return err
 	}
-	if err := a.uploadObject(backup, crdObjectName, jsonBytes); err != nil {
+	if err := a.uploadObject(backup, crdObjectName, jsonBytes, digests); err != nil {
 		return err
 	}
 	return nil
@@ -1139,13 +1180,32 @@ func (a *ApplicationBackupController) uploadCRDResources(backup *stork_api.Appli
 // Upload the backup object which should have all the required metadata
 func (a *ApplicationBackupController) uploadMetadata(
 	backup *stork_api.ApplicationBackup,
+	digests backupformat.IntegrityDigests,
 ) error {
 	jsonBytes, err := json.MarshalIndent(backup, "", " ")
 	if err != nil {
 		return err
 	}
 
-	return a.uploadObject(backup, metadataObjectName, jsonBytes)
+	return a.uploadObject(backup, metadataObjectName, jsonBytes, digests)
+}
+
+// Upload the integrity manifest for all the objects of the backup. This
+// should be the last object uploaded for the backup. Objects that aren't in
+// digests are read back from the backup location to checksum them.
+func (a *ApplicationBackupController) uploadIntegrityManifest(
+	backup *stork_api.ApplicationBackup,
+	digests backupformat.IntegrityDigests,
+) error {
+	backupLocation, err := storkops.Instance().GetBackupLocation(backup.Spec.BackupLocation, backup.Namespace)
+	if err != nil {
+		return err
+	}
+	bucket, err := objectstore.GetBucket(backupLocation)
+	if err != nil {
+		return err
+	}
+	return backupformat.UploadIntegrityManifest(context.TODO(), bucket, backupLocation, GetObjectPath(backup), digests)
 }
 
 func (a *ApplicationBackupController) backupResources(
@@ -1304,8 +1364,10 @@ func (a *ApplicationBackupController) backupResources(
 		return err
 	}
 
-	// Upload the resources to the backup location
-	if err = a.uploadResources(backup, allObjects); err != nil {
+	// Upload the resources to the backup location. The checksums of the
+	// uploaded objects are recorded for the integrity manifest.
+	digests := make(backupformat.IntegrityDigests)
+	if err = a.uploadResources(backup, allObjects, digests); err != nil {
 		message := fmt.Sprintf("Error uploading resources: %v", err)
 		backup.Status.Status = stork_api.ApplicationBackupStatusFailed
 		backup.Status.Stage = stork_api.ApplicationBackupStageFinal
@@ -1333,7 +1395,7 @@ func (a *ApplicationBackupController) backupResources(
 		backup.Status.TotalSize += vInfo.TotalSize
 	}
 	// Upload the metadata for the backup to the backup location
-	if err = a.uploadMetadata(backup); err != nil {
+	if err = a.uploadMetadata(backup, digests); err != nil {
 		a.recorder.Event(backup,
 			v1.EventTypeWarning,
 			string(stork_api.ApplicationBackupStatusFailed),
@@ -1341,6 +1403,16 @@ func (a *ApplicationBackupController) backupResources(
 		log.ApplicationBackupLog(backup).Errorf("Error uploading metadata: %v", err)
 		return err
 	}
+	// Checksum everything that was uploaded so that the backup can be
+	// verified without restoring it
+	if err = a.uploadIntegrityManifest(backup, digests); err != nil {
+		a.recorder.Event(backup,
+			v1.EventTypeWarning,
+			string(stork_api.ApplicationBackupStatusFailed),
+			fmt.Sprintf("Error uploading integrity manifest: %v", err))
+		log.ApplicationBackupLog(backup).Errorf("Error uploading integrity manifest: %v", err)
+		return err
+	}
 
 	backup.Status.LastUpdateTimestamp = metav1.Now()
 
@@ -1401,7 +1473,7 @@ func (a *ApplicationBackupController) deleteBackup(backup *stork_api.Application
 
 	objectPath := backup.Status.BackupPath
 	if objectPath != "" {
-		if err = bucket.Delete(context.TODO(), filepath.Join(objectPath, resourceObjectName)); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+		if err = backupformat.DeleteResources(context.TODO(), bucket, objectPath); err != nil {
 			return true, fmt.Errorf("error deleting resources for backup %v/%v: %v", backup.Namespace, backup.Name, err)
 		}
 
@@ -1416,6 +1488,14 @@ func (a *ApplicationBackupController) deleteBackup(backup *stork_api.Application
 		if err = bucket.Delete(context.TODO(), filepath.Join(objectPath, nsObjectName)); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
 			return true, fmt.Errorf("error deleting namespaces for backup %v/%v: %v", backup.Namespace, backup.Name, err)
 		}
+
+		if err = backupformat.DeleteIntegrityManifest(context.TODO(), bucket, objectPath); err != nil {
+			return true, fmt.Errorf("error deleting integrity manifest for backup %v/%v: %v", backup.Namespace, backup.Name, err)
+		}
+
+		if err = backupformat.DeleteDataKey(context.TODO(), bucket, objectPath); err != nil {
+			return true, fmt.Errorf("error deleting data key for backup %v/%v: %v", backup.Namespace, backup.Name, err)
+		}
 	}
 
 	return true, nil
@@ -121,7 +121,7 @@ func (s *ApplicationBackupScheduleController) handle(ctx context.Context, backup
 
 	if backupSchedule.Spec.Suspend == nil || !*backupSchedule.Spec.Suspend {
 		// Then check if any of the policies require a trigger
-		policyType, start, err := s.shouldStartApplicationBackup(backupSchedule)
+		policyType, result, err := s.shouldStartApplicationBackup(backupSchedule)
 		if err != nil {
 			msg := fmt.Sprintf("Error checking if backup should be triggered: %v", err)
 			s.recorder.Event(backupSchedule,
@@ -132,8 +132,8 @@ func (s *ApplicationBackupScheduleController) handle(ctx context.Context, backup
 			return nil
 		}
 		// Start a backup for a policy if required
-		if start {
-			err := s.startApplicationBackup(backupSchedule, policyType)
+		if result != nil && result.Trigger {
+			err := s.startApplicationBackup(backupSchedule, policyType, result.ScheduledTime)
 			if err != nil {
 				msg := fmt.Sprintf("Error triggering backup for schedule(%v): %v", policyType, err)
 				s.recorder.Event(backupSchedule,
@@ -233,43 +233,80 @@ func (s *ApplicationBackupScheduleController) isApplicationBackupComplete(status
 		status == stork_api.ApplicationBackupStatusSuccessful
 }
 
-func (s *ApplicationBackupScheduleController) shouldStartApplicationBackup(backupSchedule *stork_api.ApplicationBackupSchedule) (stork_api.SchedulePolicyType, bool, error) {
+func (s *ApplicationBackupScheduleController) shouldStartApplicationBackup(backupSchedule *stork_api.ApplicationBackupSchedule) (stork_api.SchedulePolicyType, *schedule.TriggerResult, error) {
 	// Don't trigger a new backup if one is already in progress
 	for _, policyType := range stork_api.GetValidSchedulePolicyTypes() {
 		policyApplicationBackup, present := backupSchedule.Status.Items[policyType]
 		if present {
 			for _, backup := range policyApplicationBackup {
 				if !s.isApplicationBackupComplete(backup.Status) {
-					return stork_api.SchedulePolicyTypeInvalid, false, nil
+					return stork_api.SchedulePolicyTypeInvalid, nil, nil
 				}
 			}
 		}
 	}
 
+	options := schedule.GetTriggerOptions(backupSchedule.Spec.StartingDeadlineSeconds, backupSchedule.Spec.CatchUpPolicy)
 	for _, policyType := range stork_api.GetValidSchedulePolicyTypes() {
-		var latestApplicationBackupTimestamp meta.Time
+		latestApplicationBackupTimestamp := schedule.GetLastSkippedTime(backupSchedule.Status.MissedRuns, policyType)
 		policyApplicationBackup, present := backupSchedule.Status.Items[policyType]
 		if present {
 			for _, backup := range policyApplicationBackup {
-				if latestApplicationBackupTimestamp.Before(&backup.CreationTimestamp) {
-					latestApplicationBackupTimestamp = backup.CreationTimestamp
+				triggerTimestamp := backup.CreationTimestamp
+				if !backup.ScheduledTimestamp.IsZero() {
+					triggerTimestamp = backup.ScheduledTimestamp
+				}
+				if latestApplicationBackupTimestamp.Before(&triggerTimestamp) {
+					latestApplicationBackupTimestamp = triggerTimestamp
 				}
 			}
 		}
-		trigger, err := schedule.TriggerRequired(
+		result, err := schedule.CheckTrigger(
 			backupSchedule.Spec.SchedulePolicyName,
 			backupSchedule.Namespace,
 			policyType,
 			latestApplicationBackupTimestamp,
+			options,
 		)
 		if err != nil {
-			return stork_api.SchedulePolicyTypeInvalid, false, err
+			return stork_api.SchedulePolicyTypeInvalid, nil, err
+		}
+		if err := s.recordMissedRuns(backupSchedule, policyType, latestApplicationBackupTimestamp, result); err != nil {
+			return stork_api.SchedulePolicyTypeInvalid, nil, err
 		}
-		if trigger {
-			return policyType, true, nil
+		if result.Trigger {
+			return policyType, result, nil
+		}
+	}
+	return stork_api.SchedulePolicyTypeInvalid, nil, nil
+}
+
+// recordMissedRuns adds the runs that were skipped or deferred to the status
+// of the schedule and raises events for them
+func (s *ApplicationBackupScheduleController) recordMissedRuns(
+	backupSchedule *stork_api.ApplicationBackupSchedule,
+	policyType stork_api.SchedulePolicyType,
+	lastTrigger meta.Time,
+	result *schedule.TriggerResult,
+) error {
+	var added []*stork_api.MissedScheduleRun
+	backupSchedule.Status.MissedRuns, added = schedule.UpdateMissedRuns(backupSchedule.Status.MissedRuns, policyType, lastTrigger, result)
+	if len(added) == 0 {
+		return nil
+	}
+	for _, run := range added {
+		eventType := v1.EventTypeNormal
+		if run.Action == stork_api.MissedScheduleRunSkipped {
+			eventType = v1.EventTypeWarning
 		}
+		msg := fmt.Sprintf("%v backup scheduled for %v (%v): %v", run.Action, run.ScheduledTimestamp, policyType, run.Reason)
+		s.recorder.Event(backupSchedule,
+			eventType,
+			string(run.Action),
+			msg)
+		log.ApplicationBackupScheduleLog(backupSchedule).Info(msg)
 	}
-	return stork_api.SchedulePolicyTypeInvalid, false, nil
+	return s.client.Update(context.TODO(), backupSchedule)
 }
 
 func (s *ApplicationBackupScheduleController) formatApplicationBackupName(backupSchedule *stork_api.ApplicationBackupSchedule, policyType stork_api.SchedulePolicyType) string {
@@ -311,7 +348,11 @@ func getLatestSuccessfullbackupTime(backupSchedule *stork_api.ApplicationBackupS
 	return lastSuccessfulBackupCreateTime
 }
 
-func (s *ApplicationBackupScheduleController) startApplicationBackup(backupSchedule *stork_api.ApplicationBackupSchedule, policyType stork_api.SchedulePolicyType) error {
+func (s *ApplicationBackupScheduleController) startApplicationBackup(
+	backupSchedule *stork_api.ApplicationBackupSchedule,
+	policyType stork_api.SchedulePolicyType,
+	scheduledTime time.Time,
+) error {
 	funct := "startApplicationBackup"
 	backupName := s.formatApplicationBackupName(backupSchedule, policyType)
 	if backupSchedule.Status.Items == nil {
@@ -322,9 +363,10 @@ func (s *ApplicationBackupScheduleController) startApplicationBackup(backupSched
 	}
 	backupSchedule.Status.Items[policyType] = append(backupSchedule.Status.Items[policyType],
 		&stork_api.ScheduledApplicationBackupStatus{
-			Name:              backupName,
-			CreationTimestamp: meta.NewTime(schedule.GetCurrentTime()),
-			Status:            stork_api.ApplicationBackupStatusPending,
+			Name:               backupName,
+			CreationTimestamp:  meta.NewTime(schedule.GetCurrentTime()),
+			Status:             stork_api.ApplicationBackupStatusPending,
+			ScheduledTimestamp: meta.NewTime(scheduledTime),
 		})
 	err := s.client.Update(context.TODO(), backupSchedule)
 	if err != nil {
@@ -464,6 +506,14 @@ func (s *ApplicationBackupScheduleController) startApplicationBackup(backupSched
 }
 
 func (s *ApplicationBackupScheduleController) pruneApplicationBackups(backupSchedule *stork_api.ApplicationBackupSchedule) error {
+	retention, loc, err := schedule.GetRetentionPolicy(backupSchedule.Spec.SchedulePolicyName, backupSchedule.Namespace)
+	if err != nil {
+		return err
+	}
+	if retention != nil {
+		return s.pruneApplicationBackupsWithRetention(backupSchedule, retention, loc)
+	}
+
 	for policyType, policyApplicationBackup := range backupSchedule.Status.Items {
 		numApplicationBackups := len(policyApplicationBackup)
 		deleteBefore := 0
@@ -507,6 +557,50 @@ func (s *ApplicationBackupScheduleController) pruneApplicationBackups(backupSche
 	return s.client.Update(context.TODO(), backupSchedule)
 }
 
+// pruneApplicationBackupsWithRetention deletes the backups of all the policy
+// types that aren't retained by the retention policy
+func (s *ApplicationBackupScheduleController) pruneApplicationBackupsWithRetention(
+	backupSchedule *stork_api.ApplicationBackupSchedule,
+	retention *stork_api.RetentionPolicy,
+	loc *time.Location,
+) error {
+	policyTypes := make([]stork_api.SchedulePolicyType, 0)
+	backups := make([]*stork_api.ScheduledApplicationBackupStatus, 0)
+	candidates := make([]schedule.RetentionCandidate, 0)
+	for policyType, policyApplicationBackup := range backupSchedule.Status.Items {
+		for _, backup := range policyApplicationBackup {
+			timestamp := backup.CreationTimestamp
+			if !backup.ScheduledTimestamp.IsZero() {
+				timestamp = backup.ScheduledTimestamp
+			}
+			policyTypes = append(policyTypes, policyType)
+			backups = append(backups, backup)
+			candidates = append(candidates, schedule.RetentionCandidate{
+				Timestamp:  timestamp.Time,
+				Complete:   s.isApplicationBackupComplete(backup.Status),
+				Successful: backup.Status == stork_api.ApplicationBackupStatusSuccessful,
+			})
+		}
+	}
+
+	decisions := schedule.ApplyRetention(retention, loc, candidates)
+	items := make(map[stork_api.SchedulePolicyType][]*stork_api.ScheduledApplicationBackupStatus)
+	for i, backup := range backups {
+		backup.RetentionTiers = decisions[i].Tiers
+		if !decisions[i].Retain {
+			err := storkops.Instance().DeleteApplicationBackup(backup.Name, backupSchedule.Namespace)
+			if err == nil || errors.IsNotFound(err) {
+				continue
+			}
+			// Keep a track of the failed deletes
+			log.ApplicationBackupScheduleLog(backupSchedule).Warnf("Error deleting %v: %v", backup.Name, err)
+		}
+		items[policyTypes[i]] = append(items[policyTypes[i]], backup)
+	}
+	backupSchedule.Status.Items = items
+	return s.client.Update(context.TODO(), backupSchedule)
+}
+
 func (s *ApplicationBackupScheduleController) createCRD() error {
 	resource := apiextensions.CustomResource{
 		Name:    stork_api.ApplicationBackupScheduleResourceName,
@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/libopenstorage/stork/pkg/apis/stork"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/backupformat"
+	"github.com/libopenstorage/stork/pkg/controllers"
+	"github.com/libopenstorage/stork/pkg/k8sutils"
+	"github.com/libopenstorage/stork/pkg/log"
+	"github.com/libopenstorage/stork/pkg/objectstore"
+	"github.com/libopenstorage/stork/pkg/version"
+	"github.com/portworx/sched-ops/k8s/apiextensions"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// NewApplicationBackupVerification creates a new instance of ApplicationBackupVerificationController.
+func NewApplicationBackupVerification(mgr manager.Manager, r record.EventRecorder) *ApplicationBackupVerificationController {
+	return &ApplicationBackupVerificationController{
+		client:   mgr.GetClient(),
+		recorder: r,
+	}
+}
+
+// ApplicationBackupVerificationController reconciles ApplicationBackupVerification objects
+type ApplicationBackupVerificationController struct {
+	client runtimeclient.Client
+
+	recorder record.EventRecorder
+}
+
+// Init Initialize the backup verification controller
+func (v *ApplicationBackupVerificationController) Init(mgr manager.Manager) error {
+	err := v.createCRD()
+	if err != nil {
+		return err
+	}
+
+	return controllers.RegisterTo(mgr, "application-backup-verification-controller", v, &stork_api.ApplicationBackupVerification{})
+}
+
+// Reconcile updates for ApplicationBackupVerification objects.
+func (v *ApplicationBackupVerificationController) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	logrus.Tracef("Reconciling ApplicationBackupVerification %s/%s", request.Namespace, request.Name)
+
+	verification := &stork_api.ApplicationBackupVerification{}
+	err := v.client.Get(context.TODO(), request.NamespacedName, verification)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// Return and don't requeue
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{RequeueAfter: controllers.DefaultRequeueError}, err
+	}
+
+	if err = v.handle(context.TODO(), verification); err != nil {
+		logrus.Errorf("%s: %s/%s: %s", reflect.TypeOf(v), verification.Namespace, verification.Name, err)
+		return reconcile.Result{RequeueAfter: controllers.DefaultRequeueError}, err
+	}
+
+	return reconcile.Result{RequeueAfter: controllers.DefaultRequeue}, nil
+}
+
+// Handle updates for ApplicationBackupVerification objects
+func (v *ApplicationBackupVerificationController) handle(ctx context.Context, verification *stork_api.ApplicationBackupVerification) error {
+	if verification.DeletionTimestamp != nil {
+		return nil
+	}
+	if verification.Status.Status == stork_api.ApplicationBackupVerificationStatusSuccessful ||
+		verification.Status.Status == stork_api.ApplicationBackupVerificationStatusFailed {
+		return nil
+	}
+
+	backup, err := storkops.Instance().GetApplicationBackup(verification.Spec.BackupName, verification.Namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return v.fail(verification, fmt.Sprintf("ApplicationBackup %v not found", verification.Spec.BackupName))
+		}
+		return err
+	}
+	if backup.Status.Stage != stork_api.ApplicationBackupStageFinal {
+		// Wait for the backup to finish
+		log.ApplicationBackupVerificationLog(verification).Debugf("Waiting for backup %v to complete", backup.Name)
+		return nil
+	}
+	if backup.Status.Status != stork_api.ApplicationBackupStatusSuccessful || backup.Status.BackupPath == "" {
+		return v.fail(verification, fmt.Sprintf("ApplicationBackup %v was not successful", backup.Name))
+	}
+
+	verification.Status.Status = stork_api.ApplicationBackupVerificationStatusInProgress
+	verification.Status.Reason = ""
+	verification.Status.Objects = nil
+	if err := v.client.Update(context.TODO(), verification); err != nil {
+		return err
+	}
+
+	backupLocation, err := storkops.Instance().GetBackupLocation(backup.Spec.BackupLocation, backup.Namespace)
+	if err != nil {
+		return v.fail(verification, fmt.Sprintf("Error getting backup location %v: %v", backup.Spec.BackupLocation, err))
+	}
+	bucket, err := objectstore.GetBucket(backupLocation)
+	if err != nil {
+		return v.fail(verification, fmt.Sprintf("Error getting bucket for backup location %v: %v", backupLocation.Name, err))
+	}
+	// Objects have to be compressed the way it was recorded for the backup
+	ctx = backupformat.WithCompression(ctx, backup.Status.Compression)
+	results, err := backupformat.VerifyBackup(ctx, bucket, backupLocation, backup.Status.BackupPath)
+	if err != nil {
+		return v.fail(verification, fmt.Sprintf("Error verifying backup %v: %v", backup.Name, err))
+	}
+
+	failed := 0
+	for _, result := range results {
+		objectInfo := &stork_api.ApplicationBackupVerificationObjectInfo{
+			Name:   result.Name,
+			Size:   result.Size,
+			Status: stork_api.ApplicationBackupVerificationStatusSuccessful,
+		}
+		if result.Error != nil {
+			objectInfo.Status = stork_api.ApplicationBackupVerificationStatusFailed
+			objectInfo.Reason = result.Error.Error()
+			failed++
+		}
+		verification.Status.Objects = append(verification.Status.Objects, objectInfo)
+	}
+	if failed > 0 {
+		return v.fail(verification, fmt.Sprintf("%v of %v objects failed verification", failed, len(results)))
+	}
+
+	verification.Status.Status = stork_api.ApplicationBackupVerificationStatusSuccessful
+	verification.Status.Reason = fmt.Sprintf("All %v objects were verified successfully", len(results))
+	verification.Status.FinishTimestamp = metav1.Now()
+	v.recorder.Event(verification,
+		v1.EventTypeNormal,
+		string(stork_api.ApplicationBackupVerificationStatusSuccessful),
+		verification.Status.Reason)
+	return v.client.Update(context.TODO(), verification)
+}
+
+func (v *ApplicationBackupVerificationController) fail(
+	verification *stork_api.ApplicationBackupVerification,
+	message string,
+) error {
+	verification.Status.Status = stork_api.ApplicationBackupVerificationStatusFailed
+	verification.Status.Reason = message
+	verification.Status.FinishTimestamp = metav1.Now()
+	v.recorder.Event(verification,
+		v1.EventTypeWarning,
+		string(stork_api.ApplicationBackupVerificationStatusFailed),
+		message)
+	log.ApplicationBackupVerificationLog(verification).Errorf(message)
+	return v.client.Update(context.TODO(), verification)
+}
+
+func (v *ApplicationBackupVerificationController) createCRD() error {
+	resource := apiextensions.CustomResource{
+		Name:    stork_api.ApplicationBackupVerificationResourceName,
+		Plural:  stork_api.ApplicationBackupVerificationResourcePlural,
+		Group:   stork.GroupName,
+		Version: stork_api.SchemeGroupVersion.Version,
+		Scope:   apiextensionsv1beta1.NamespaceScoped,
+		Kind:    reflect.TypeOf(stork_api.ApplicationBackupVerification{}).Name(),
+	}
+	ok, err := version.RequiresV1Registration()
+	if err != nil {
+		return err
+	}
+	if ok {
+		err := k8sutils.CreateCRD(resource)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+		return apiextensions.Instance().ValidateCRD(resource.Plural+"."+resource.Group, validateCRDTimeout, validateCRDInterval)
+	}
+	err = apiextensions.Instance().CreateCRDV1beta1(resource)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return apiextensions.Instance().ValidateCRDV1beta1(resource, validateCRDTimeout, validateCRDInterval)
+}
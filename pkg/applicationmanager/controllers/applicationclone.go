@@ -362,9 +362,12 @@ func (a *ApplicationCloneController) cloneVolumes(clone *stork_api.ApplicationCl
 		}
 	}
 
-	// Start clone of the volumes if it hasn't started yet
+	// Start clone of the volumes if it hasn't started yet. Drivers that clone
+	// volumes asynchronously are called again to update the status of the
+	// volumes until they are all done.
 	if clone.Status.Stage == stork_api.ApplicationCloneStageVolumes &&
 		clone.Status.Status == stork_api.ApplicationCloneStatusInProgress {
+		started := volumeClonesStarted(clone)
 		if err := a.volDriver.CreateVolumeClones(clone); err != nil {
 			return err
 		}
@@ -376,7 +379,7 @@ func (a *ApplicationCloneController) cloneVolumes(clone *stork_api.ApplicationCl
 		}
 
 		// Run any post exec rules once clone is triggered
-		if clone.Spec.PostExecRule != "" {
+		if !started && clone.Spec.PostExecRule != "" {
 			if err := a.runPostExecRule(clone); err != nil {
 				message := fmt.Sprintf("Error running PostExecRule: %v", err)
 				log.ApplicationCloneLog(clone).Errorf(message)
@@ -397,6 +400,12 @@ func (a *ApplicationCloneController) cloneVolumes(clone *stork_api.ApplicationCl
 		}
 	}
 
+	// Wait for the volumes that are still being cloned
+	if volumeClonesPending(clone) {
+		log.ApplicationCloneLog(clone).Infof("Waiting for volumes to be cloned")
+		return a.client.Update(context.TODO(), clone)
+	}
+
 	// Skip checking status if no volumes are being cloned up
 	if len(clone.Status.Volumes) != 0 {
 		// Now check if there is any failure or success
@@ -447,6 +456,29 @@ func (a *ApplicationCloneController) cloneVolumes(clone *stork_api.ApplicationCl
 	return nil
 }
 
+// volumeClonesStarted returns true if the driver has already been called to
+// clone the volumes
+func volumeClonesStarted(clone *stork_api.ApplicationClone) bool {
+	for _, vInfo := range clone.Status.Volumes {
+		if vInfo.Status != stork_api.ApplicationCloneStatusInProgress || vInfo.Reason != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// volumeClonesPending returns true if any of the volumes are still being
+// cloned. Failures are only reported once all the volumes are done.
+func volumeClonesPending(clone *stork_api.ApplicationClone) bool {
+	for _, vInfo := range clone.Status.Volumes {
+		if vInfo.Status == stork_api.ApplicationCloneStatusInProgress ||
+			vInfo.Status == stork_api.ApplicationCloneStatusPending {
+			return true
+		}
+	}
+	return false
+}
+
 func (a *ApplicationCloneController) runPreExecRule(clone *stork_api.ApplicationClone) (chan bool, error) {
 	if clone.Spec.PreExecRule == "" {
 		clone.Status.Stage = stork_api.ApplicationCloneStageVolumes
@@ -510,6 +542,14 @@ func (a *ApplicationCloneController) prepareResources(
 	if err != nil {
 		return nil, err
 	}
+	// PVs that were created by the driver when cloning the volumes are
+	// already bound to the cloned PVCs
+	createdPVs := make(map[string]bool)
+	for _, vInfo := range clone.Status.Volumes {
+		if vInfo.PersistentVolumeCreated {
+			createdPVs[vInfo.Volume] = true
+		}
+	}
 
 	namespaceMapping := make(map[string]string)
 	namespaceMapping[clone.Spec.SourceNamespace] = clone.Spec.DestinationNamespace
@@ -526,6 +566,9 @@ func (a *ApplicationCloneController) prepareResources(
 
 		switch o.GetObjectKind().GroupVersionKind().Kind {
 		case "PersistentVolume":
+			if createdPVs[metadata.GetName()] {
+				continue
+			}
 			err := a.preparePVResource(o)
 			if err != nil {
 				return nil, fmt.Errorf("error preparing PV resource %v: %v", metadata.GetName(), err)
@@ -0,0 +1,119 @@
+//go:build unittest
+// +build unittest
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	_ "github.com/libopenstorage/stork/drivers/volume/mock"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/resourcecollector"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestVolumeClonesStarted(t *testing.T) {
+	clone := &stork_api.ApplicationClone{}
+	require.False(t, volumeClonesStarted(clone), "No volumes")
+
+	clone.Status.Volumes = []*stork_api.ApplicationCloneVolumeInfo{
+		{Volume: "pv1", Status: stork_api.ApplicationCloneStatusInProgress},
+		{Volume: "pv2", Status: stork_api.ApplicationCloneStatusInProgress},
+	}
+	require.False(t, volumeClonesStarted(clone), "Driver hasn't been called yet")
+
+	clone.Status.Volumes[1].Reason = "Volume snapshot in progress"
+	require.True(t, volumeClonesStarted(clone), "Driver updated the reason of a volume")
+
+	clone.Status.Volumes[1].Reason = ""
+	clone.Status.Volumes[0].Status = stork_api.ApplicationCloneStatusSuccessful
+	require.True(t, volumeClonesStarted(clone), "Driver updated the status of a volume")
+}
+
+func TestVolumeClonesPending(t *testing.T) {
+	clone := &stork_api.ApplicationClone{}
+	require.False(t, volumeClonesPending(clone), "No volumes")
+
+	clone.Status.Volumes = []*stork_api.ApplicationCloneVolumeInfo{
+		{Volume: "pv1", Status: stork_api.ApplicationCloneStatusSuccessful},
+		{Volume: "pv2", Status: stork_api.ApplicationCloneStatusFailed},
+	}
+	require.False(t, volumeClonesPending(clone), "All volumes are done")
+
+	clone.Status.Volumes[1].Status = stork_api.ApplicationCloneStatusInProgress
+	require.True(t, volumeClonesPending(clone))
+
+	clone.Status.Volumes[1].Status = stork_api.ApplicationCloneStatusPending
+	require.True(t, volumeClonesPending(clone))
+}
+
+func newUnstructuredObject(t *testing.T, object runtime.Object) runtime.Unstructured {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	require.NoError(t, err)
+	return &unstructured.Unstructured{Object: content}
+}
+
+func TestPrepareResourcesCreatedPVs(t *testing.T) {
+	core.SetInstance(core.New(fake.NewSimpleClientset()))
+	driver, err := volume.Get("MockDriver")
+	require.NoError(t, err)
+	a := &ApplicationCloneController{
+		volDriver:         driver,
+		resourceCollector: resourcecollector.ResourceCollector{},
+	}
+
+	clone := &stork_api.ApplicationClone{
+		Spec: stork_api.ApplicationCloneSpec{
+			SourceNamespace:      "source",
+			DestinationNamespace: "dest",
+		},
+		Status: stork_api.ApplicationCloneStatus{
+			Volumes: []*stork_api.ApplicationCloneVolumeInfo{{
+				PersistentVolumeClaim:   "pvc1",
+				Volume:                  "pv1",
+				CloneVolume:             "clone-pv1",
+				PersistentVolumeCreated: true,
+			}},
+		},
+	}
+	pv := &v1.PersistentVolume{
+		TypeMeta:   metav1.TypeMeta{Kind: "PersistentVolume", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "pv1"},
+	}
+	pvc := &v1.PersistentVolumeClaim{
+		TypeMeta:   metav1.TypeMeta{Kind: "PersistentVolumeClaim", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc1", Namespace: "source"},
+		Spec:       v1.PersistentVolumeClaimSpec{VolumeName: "pv1"},
+	}
+	objects := []runtime.Unstructured{newUnstructuredObject(t, pv), newUnstructuredObject(t, pvc)}
+
+	prepared, err := a.prepareResources(clone, objects)
+	require.NoError(t, err)
+	require.Len(t, prepared, 1, "PV created by the driver shouldn't be cloned")
+	require.Equal(t, "PersistentVolumeClaim", prepared[0].GetObjectKind().GroupVersionKind().Kind)
+	volumeName, _, err := unstructured.NestedString(prepared[0].UnstructuredContent(), "spec", "volumeName")
+	require.NoError(t, err)
+	require.Equal(t, "clone-pv1", volumeName, "PVC should be bound to the PV created by the driver")
+	namespace, _, err := unstructured.NestedString(prepared[0].UnstructuredContent(), "metadata", "namespace")
+	require.NoError(t, err)
+	require.Equal(t, "dest", namespace)
+
+	// PVs that weren't created by the driver are prepared to be cloned with
+	// the resources
+	clone.Status.Volumes[0].PersistentVolumeCreated = false
+	objects = []runtime.Unstructured{newUnstructuredObject(t, pv), newUnstructuredObject(t, pvc)}
+	prepared, err = a.prepareResources(clone, objects)
+	require.NoError(t, err)
+	require.Len(t, prepared, 2)
+	require.Equal(t, "PersistentVolume", prepared[0].GetObjectKind().GroupVersionKind().Kind)
+	name, _, err := unstructured.NestedString(prepared[0].UnstructuredContent(), "metadata", "name")
+	require.NoError(t, err)
+	require.Equal(t, "clone-pv1", name)
+}
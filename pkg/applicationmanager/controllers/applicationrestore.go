@@ -12,8 +12,8 @@ import (
 	"github.com/libopenstorage/stork/drivers/volume/kdmp"
 	"github.com/libopenstorage/stork/pkg/apis/stork"
 	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/backupformat"
 	"github.com/libopenstorage/stork/pkg/controllers"
-	"github.com/libopenstorage/stork/pkg/crypto"
 	"github.com/libopenstorage/stork/pkg/k8sutils"
 	"github.com/libopenstorage/stork/pkg/log"
 	"github.com/libopenstorage/stork/pkg/objectstore"
@@ -717,23 +717,10 @@ func (a *ApplicationRestoreController) downloadObject(
 		}
 	}
 
-	data, err := bucket.ReadAll(context.TODO(), filepath.Join(objectPath, objectName))
-	if err != nil {
-		return nil, err
-	}
-	if restoreLocation.Location.EncryptionKey != "" {
-		return nil, fmt.Errorf("EncryptionKey is deprecated, use EncryptionKeyV2 instead")
-	}
-	if restoreLocation.Location.EncryptionV2Key != "" {
-		var decryptData []byte
-		if decryptData, err = crypto.Decrypt(data, restoreLocation.Location.EncryptionV2Key); err != nil {
-			logrus.Errorf("ApplicationRestoreController/downloadObject: decrypt failed :%v, returing data direclty", err)
-			return data, nil
-		}
-		return decryptData, nil
-	}
-
-	return data, nil
+	// Objects are decoded with the compression that was recorded for the
+	// backup in case the compression of the location has changed since
+	ctx := backupformat.WithCompression(context.TODO(), backup.Status.Compression)
+	return backupformat.ReadObject(ctx, bucket, restoreLocation, filepath.Join(objectPath, objectName))
 }
 
 func (a *ApplicationRestoreController) downloadResources(
@@ -745,20 +732,16 @@ func (a *ApplicationRestoreController) downloadResources(
 	if err := a.downloadCRD(backup, backupLocation, namespace); err != nil {
 		return nil, fmt.Errorf("error downloading CRDs: %v", err)
 	}
-	data, err := a.downloadObject(backup, backupLocation, namespace, resourceObjectName, false)
+	restoreLocation, err := storkops.Instance().GetBackupLocation(backup.Spec.BackupLocation, namespace)
 	if err != nil {
 		return nil, err
 	}
-
-	objects := make([]*unstructured.Unstructured, 0)
-	if err = json.Unmarshal(data, &objects); err != nil {
+	bucket, err := objectstore.GetBucket(restoreLocation)
+	if err != nil {
 		return nil, err
 	}
-	runtimeObjects := make([]runtime.Unstructured, 0)
-	for _, o := range objects {
-		runtimeObjects = append(runtimeObjects, o)
-	}
-	return runtimeObjects, nil
+	ctx := backupformat.WithCompression(context.TODO(), backup.Status.Compression)
+	return backupformat.DownloadResources(ctx, bucket, restoreLocation, backup.Status.BackupPath)
 }
 
 func (a *ApplicationRestoreController) downloadCRD(
@@ -10,7 +10,7 @@ import (
 	"time"
 
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
-	"github.com/libopenstorage/stork/pkg/crypto"
+	"github.com/libopenstorage/stork/pkg/backupformat"
 	"github.com/libopenstorage/stork/pkg/log"
 	"github.com/libopenstorage/stork/pkg/objectstore"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
@@ -97,20 +97,16 @@ func (b *BackupSyncController) syncBackupsFromLocation(location *storkv1.BackupL
 				return err
 			}
 			if object.IsDir {
-				data, err := bucket.ReadAll(context.TODO(), filepath.Join(object.Key, metadataObjectName))
-				if err != nil {
-					log.BackupLocationLog(location).Errorf("Error syncing backup %v: %v", backupName, err)
-					continue
-				}
 				if location.Location.EncryptionKey != "" {
 					return fmt.Errorf("EncryptionKey is deprecated, use EncryptionKeyV2 instead")
 				}
-				if location.Location.EncryptionV2Key != "" {
-					if decryptData, err := crypto.Decrypt(data, location.Location.EncryptionV2Key); err != nil {
-						log.BackupLocationLog(location).Errorf("Error decrypting backup %v during sync: %v", backupName, err)
-					} else {
-						data = decryptData
-					}
+				// Metadata is decrypted and decompressed based on how it
+				// was uploaded, so backups from older versions are
+				// synced too
+				data, err := backupformat.ReadObject(context.TODO(), bucket, location, filepath.Join(object.Key, metadataObjectName))
+				if err != nil {
+					log.BackupLocationLog(location).Errorf("Error syncing backup %v: %v", backupName, err)
+					continue
 				}
 				backupInfo := storkv1.ApplicationBackup{}
 				if err = json.Unmarshal(data, &backupInfo); err != nil {
@@ -0,0 +1,688 @@
+package backupformat
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/crypto"
+	"github.com/sirupsen/logrus"
+	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// ResourcesObjectName is the object all the resources of a backup were
+	// uploaded to before they were split into chunks. It is still read for
+	// backups that don't have a manifest.
+	ResourcesObjectName = "resources.json"
+	// ResourcesManifestObjectName is the object that lists the chunks the
+	// resources of a backup were uploaded to
+	ResourcesManifestObjectName = "resources-manifest.json"
+	// ResourcesManifestVersion is the current version of the manifest
+	ResourcesManifestVersion = 1
+
+	resourcesObjectPrefix      = "resources"
+	resourcesChunkObjectFormat = "resources-%05d.json"
+	// Size of the serialized resources after which a new chunk is started
+	defaultMaxChunkSize = 32 * 1024 * 1024
+)
+
+// ResourcesManifest describes how the resources of a backup are split up in
+// the backup location
+type ResourcesManifest struct {
+	Version int `json:"version"`
+	// Chunks are the objects the resources were written to, in order
+	Chunks []ResourcesChunk `json:"chunks"`
+}
+
+// ResourcesChunk is one object in the backup location with a part of the
+// resources
+type ResourcesChunk struct {
+	// Name of the object relative to the backup path
+	Name string `json:"name"`
+	// Objects is the number of resources in the chunk
+	Objects int `json:"objects"`
+	// Size is the size of the serialized resources before encryption
+	Size int64 `json:"size"`
+}
+
+// UploadResources writes the objects to the backup location at the given
+// path. The objects are serialized one at a time and streamed to size bounded
+// chunks so that the whole backup is never held in memory as one buffer. A
+// manifest listing the chunks is written last. The checksums of the uploaded
+// objects are recorded in digests if it isn't nil.
+func UploadResources(
+	ctx context.Context,
+	bucket *blob.Bucket,
+	backupLocation *stork_api.BackupLocation,
+	objectPath string,
+	objects []runtime.Unstructured,
+	digests IntegrityDigests,
+) error {
+	manifest := &ResourcesManifest{
+		Version: ResourcesManifestVersion,
+		Chunks:  make([]ResourcesChunk, 0),
+	}
+	for start := 0; start < len(objects) || len(manifest.Chunks) == 0; {
+		chunk := ResourcesChunk{
+			Name: fmt.Sprintf(resourcesChunkObjectFormat, len(manifest.Chunks)),
+		}
+		writer, err := NewObjectWriter(ctx, bucket, backupLocation, filepath.Join(objectPath, chunk.Name))
+		if err != nil {
+			return err
+		}
+		chunk.Objects, chunk.Size, err = writeResourcesChunk(writer, objects[start:], defaultMaxChunkSize)
+		if err != nil {
+			writer.Abort()
+			return fmt.Errorf("error uploading resources chunk %v: %v", chunk.Name, err)
+		}
+		if err := writer.Close(); err != nil {
+			return fmt.Errorf("error uploading resources chunk %v: %v", chunk.Name, err)
+		}
+		digests.record(writer)
+		manifest.Chunks = append(manifest.Chunks, chunk)
+		start += chunk.Objects
+	}
+
+	data, err := json.MarshalIndent(manifest, "", " ")
+	if err != nil {
+		return err
+	}
+	if err := WriteObject(ctx, bucket, backupLocation, filepath.Join(objectPath, ResourcesManifestObjectName), data, digests); err != nil {
+		return fmt.Errorf("error uploading resources manifest: %v", err)
+	}
+	return nil
+}
+
+// ResourcesReader streams the objects that were uploaded for a backup. Chunks
+// listed in the manifest are downloaded, decrypted and decoded one object at
+// a time so that the whole backup is never held in memory. Backups without a
+// manifest are read from the single resources object.
+type ResourcesReader struct {
+	ctx            context.Context
+	bucket         *blob.Bucket
+	backupLocation *stork_api.BackupLocation
+	objectPath     string
+	// chunks that haven't been opened yet
+	chunks []ResourcesChunk
+	// legacy holds the objects of backups without a manifest
+	legacy []runtime.Unstructured
+
+	chunk   ResourcesChunk
+	reader  io.ReadCloser
+	decoder *chunkDecoder
+}
+
+// NewResourcesReader returns a reader for the resources of the backup at the
+// given path. The reader must be closed when done.
+func NewResourcesReader(
+	ctx context.Context,
+	bucket *blob.Bucket,
+	backupLocation *stork_api.BackupLocation,
+	objectPath string,
+) (*ResourcesReader, error) {
+	manifest, err := GetResourcesManifest(ctx, bucket, backupLocation, objectPath)
+	if err != nil {
+		return nil, err
+	}
+	r := &ResourcesReader{
+		ctx:            ctx,
+		bucket:         bucket,
+		backupLocation: backupLocation,
+		objectPath:     objectPath,
+	}
+	if manifest == nil {
+		if r.legacy, err = downloadLegacyResources(ctx, bucket, backupLocation, objectPath); err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+	r.chunks = manifest.Chunks
+	return r, nil
+}
+
+// Next returns the next object of the backup. io.EOF is returned once all the
+// objects have been read.
+func (r *ResourcesReader) Next() (runtime.Unstructured, error) {
+	if r.legacy != nil {
+		if len(r.legacy) == 0 {
+			return nil, io.EOF
+		}
+		object := r.legacy[0]
+		r.legacy = r.legacy[1:]
+		return object, nil
+	}
+	for {
+		if r.decoder == nil {
+			if len(r.chunks) == 0 {
+				return nil, io.EOF
+			}
+			if err := r.openChunk(); err != nil {
+				return nil, err
+			}
+		}
+		object, err := r.decoder.next()
+		if err == nil {
+			return object, nil
+		}
+		if err != io.EOF {
+			return nil, fmt.Errorf("error reading resources chunk %v: %v", r.chunk.Name, err)
+		}
+		if err := r.closeChunk(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Close releases the chunk that is being read
+func (r *ResourcesReader) Close() error {
+	if r.reader == nil {
+		return nil
+	}
+	err := r.reader.Close()
+	r.reader = nil
+	r.decoder = nil
+	return err
+}
+
+func (r *ResourcesReader) openChunk() error {
+	r.chunk = r.chunks[0]
+	r.chunks = r.chunks[1:]
+	reader, err := NewObjectReader(r.ctx, r.bucket, r.backupLocation, filepath.Join(r.objectPath, r.chunk.Name))
+	if err != nil {
+		return fmt.Errorf("error downloading resources chunk %v: %v", r.chunk.Name, err)
+	}
+	r.reader = reader
+	r.decoder = newChunkDecoder(reader)
+	return nil
+}
+
+func (r *ResourcesReader) closeChunk() error {
+	count := r.decoder.count
+	if err := r.Close(); err != nil {
+		return err
+	}
+	if count != r.chunk.Objects {
+		return fmt.Errorf("resources chunk %v has %v objects, expected %v", r.chunk.Name, count, r.chunk.Objects)
+	}
+	return nil
+}
+
+// DownloadResources reads all the objects that were uploaded for a backup.
+// Callers that don't need all the objects at once should use
+// NewResourcesReader instead.
+func DownloadResources(
+	ctx context.Context,
+	bucket *blob.Bucket,
+	backupLocation *stork_api.BackupLocation,
+	objectPath string,
+) ([]runtime.Unstructured, error) {
+	reader, err := NewResourcesReader(ctx, bucket, backupLocation, objectPath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close() // nolint: errcheck
+
+	runtimeObjects := make([]runtime.Unstructured, 0)
+	for {
+		object, err := reader.Next()
+		if err == io.EOF {
+			return runtimeObjects, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		runtimeObjects = append(runtimeObjects, object)
+	}
+}
+
+// GetResourcesManifest returns the manifest for the resources of a backup.
+// nil is returned if the backup was uploaded without one.
+func GetResourcesManifest(
+	ctx context.Context,
+	bucket *blob.Bucket,
+	backupLocation *stork_api.BackupLocation,
+	objectPath string,
+) (*ResourcesManifest, error) {
+	manifestPath := filepath.Join(objectPath, ResourcesManifestObjectName)
+	exists, err := bucket.Exists(ctx, manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+	reader, err := NewObjectReader(ctx, bucket, backupLocation, manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close() // nolint: errcheck
+
+	manifest := &ResourcesManifest{}
+	if err := json.NewDecoder(reader).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("error parsing resources manifest: %v", err)
+	}
+	if manifest.Version > ResourcesManifestVersion {
+		return nil, fmt.Errorf("unsupported resources manifest version %v", manifest.Version)
+	}
+	return manifest, nil
+}
+
+// DeleteResources deletes all the objects that hold the resources of a
+// backup, including the manifest
+func DeleteResources(
+	ctx context.Context,
+	bucket *blob.Bucket,
+	objectPath string,
+) error {
+	iterator := bucket.List(&blob.ListOptions{
+		Prefix: filepath.Join(objectPath, resourcesObjectPrefix),
+	})
+	for {
+		object, err := iterator.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if object.IsDir || !strings.HasSuffix(object.Key, ".json") {
+			continue
+		}
+		if err := bucket.Delete(ctx, object.Key); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+// ObjectWriter writes an object to the backup location. The data is
+// compressed and encrypted as configured for the location before it is
+// uploaded. The uploaded data is checksummed as it is written so that the
+// object doesn't have to be read back for the integrity manifest.
+type ObjectWriter struct {
+	io.Writer
+	key          string
+	objectWriter *blob.Writer
+	hasher       hash.Hash
+	counter      *countWriter
+	// streams are closed in order to flush the compression and encryption
+	// streams before the object is closed
+	streams []io.Closer
+	cancel  context.CancelFunc
+}
+
+// NewObjectWriter returns a writer for the given key in the backup location
+func NewObjectWriter(
+	ctx context.Context,
+	bucket *blob.Bucket,
+	backupLocation *stork_api.BackupLocation,
+	key string,
+) (*ObjectWriter, error) {
+	if backupLocation.Location.EncryptionKey != "" {
+		return nil, fmt.Errorf("EncryptionKey is deprecated, use EncryptionKeyV2 instead")
+	}
+	if err := ValidateCompression(backupLocation.Location.Compression); err != nil {
+		return nil, err
+	}
+	passphrase, err := getPassphrase(ctx, bucket, backupLocation, key, true)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	objectWriter, err := bucket.NewWriter(ctx, key, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	writer := &ObjectWriter{
+		key:          key,
+		objectWriter: objectWriter,
+		hasher:       sha256.New(),
+		counter:      &countWriter{},
+		streams:      make([]io.Closer, 0),
+		cancel:       cancel,
+	}
+	writer.Writer = io.MultiWriter(objectWriter, writer.hasher, writer.counter)
+	if passphrase != "" {
+		encrypter, err := crypto.NewEncryptWriter(writer.Writer, passphrase)
+		if err != nil {
+			writer.Abort()
+			return nil, err
+		}
+		writer.Writer = encrypter
+		writer.streams = append([]io.Closer{encrypter}, writer.streams...)
+	}
+	// Compress before encrypting, encrypted data doesn't compress
+	compressor, err := newCompressWriter(writer.Writer, backupLocation.Location.Compression)
+	if err != nil {
+		writer.Abort()
+		return nil, err
+	}
+	if compressor != nil {
+		writer.Writer = compressor
+		writer.streams = append([]io.Closer{compressor}, writer.streams...)
+	}
+	return writer, nil
+}
+
+// Close flushes any pending data and finishes writing the object
+func (w *ObjectWriter) Close() error {
+	defer w.cancel()
+	for _, stream := range w.streams {
+		if err := stream.Close(); err != nil {
+			w.Abort()
+			return err
+		}
+	}
+	return w.objectWriter.Close()
+}
+
+// Digest returns the size and checksum of the data that was uploaded for the
+// object. It is only complete once the writer has been closed.
+func (w *ObjectWriter) Digest() IntegrityObject {
+	return IntegrityObject{
+		Name:   w.key,
+		Size:   w.counter.size,
+		SHA256: hex.EncodeToString(w.hasher.Sum(nil)),
+	}
+}
+
+// Abort stops writing the object. Nothing is written to the backup location
+// for an aborted object.
+func (w *ObjectWriter) Abort() {
+	w.cancel()
+	if err := w.objectWriter.Close(); err != nil {
+		logrus.Debugf("Error closing aborted writer for objectstore: %v", err)
+	}
+}
+
+// WriteObject uploads data to the given key in the backup location. The
+// checksum of the object is recorded in digests if it isn't nil.
+func WriteObject(
+	ctx context.Context,
+	bucket *blob.Bucket,
+	backupLocation *stork_api.BackupLocation,
+	key string,
+	data []byte,
+	digests IntegrityDigests,
+) error {
+	writer, err := NewObjectWriter(ctx, bucket, backupLocation, key)
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(data); err != nil {
+		writer.Abort()
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	digests.record(writer)
+	return nil
+}
+
+// NewObjectReader returns a reader for an object that was written with
+// ObjectWriter. The data is decrypted and decompressed while it is read.
+func NewObjectReader(
+	ctx context.Context,
+	bucket *blob.Bucket,
+	backupLocation *stork_api.BackupLocation,
+	key string,
+) (io.ReadCloser, error) {
+	passphrase, err := getPassphrase(ctx, bucket, backupLocation, key, false)
+	if err != nil {
+		return nil, err
+	}
+	objectReader, err := bucket.NewReader(ctx, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := newDecodeReader(ctx, objectReader, passphrase)
+	if err != nil {
+		objectReader.Close() // nolint: errcheck
+		return nil, fmt.Errorf("error reading object %v: %v", key, err)
+	}
+	return &objectReadCloser{Reader: reader, closer: objectReader}, nil
+}
+
+// ReadObject downloads the object with the given key from the backup
+// location. Objects written by older versions, which were encrypted as one
+// block and never compressed, are read too.
+func ReadObject(
+	ctx context.Context,
+	bucket *blob.Bucket,
+	backupLocation *stork_api.BackupLocation,
+	key string,
+) ([]byte, error) {
+	if backupLocation.Location.EncryptionKey != "" {
+		return nil, fmt.Errorf("EncryptionKey is deprecated, use EncryptionKeyV2 instead")
+	}
+	passphrase, err := getPassphrase(ctx, bucket, backupLocation, key, false)
+	if err != nil {
+		return nil, err
+	}
+	data, err := bucket.ReadAll(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return decodeObject(ctx, data, passphrase)
+}
+
+type objectReadCloser struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (r *objectReadCloser) Close() error {
+	return r.closer.Close()
+}
+
+func newDecodeReader(
+	ctx context.Context,
+	reader io.Reader,
+	passphrase string,
+) (io.Reader, error) {
+	bufferedReader := bufio.NewReader(reader)
+	header, err := bufferedReader.Peek(len(crypto.StreamMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	reader = bufferedReader
+	if crypto.IsEncryptedStream(header) {
+		if passphrase == "" {
+			return nil, fmt.Errorf("object is encrypted but no encryption key is configured")
+		}
+		if reader, err = crypto.NewDecryptReader(bufferedReader, passphrase); err != nil {
+			return nil, err
+		}
+	}
+	return newDecompressReader(ctx, reader)
+}
+
+func decodeObject(
+	ctx context.Context,
+	data []byte,
+	passphrase string,
+) ([]byte, error) {
+	if !crypto.IsEncryptedStream(data) && passphrase != "" {
+		if decryptData, err := crypto.Decrypt(data, passphrase); err != nil {
+			logrus.Debugf("decrypt failed with: %v and returning the data as it is", err)
+		} else {
+			data = decryptData
+		}
+	}
+	reader, err := newDecodeReader(ctx, bytes.NewReader(data), passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(reader)
+}
+
+func downloadLegacyResources(
+	ctx context.Context,
+	bucket *blob.Bucket,
+	backupLocation *stork_api.BackupLocation,
+	objectPath string,
+) ([]runtime.Unstructured, error) {
+	data, err := ReadObject(ctx, bucket, backupLocation, filepath.Join(objectPath, ResourcesObjectName))
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]*unstructured.Unstructured, 0)
+	if err = json.Unmarshal(data, &objects); err != nil {
+		return nil, err
+	}
+	runtimeObjects := make([]runtime.Unstructured, 0)
+	for _, o := range objects {
+		runtimeObjects = append(runtimeObjects, o)
+	}
+	return runtimeObjects, nil
+}
+
+// writeResourcesChunk writes objects as a JSON list until maxSize bytes have
+// been written. At least one object is always written to make progress.
+// Returns the number of objects and bytes written.
+func writeResourcesChunk(
+	writer io.Writer,
+	objects []runtime.Unstructured,
+	maxSize int64,
+) (int, int64, error) {
+	var size int64
+	write := func(data []byte) error {
+		n, err := writer.Write(data)
+		size += int64(n)
+		return err
+	}
+
+	if err := write([]byte("[")); err != nil {
+		return 0, size, err
+	}
+	count := 0
+	for _, object := range objects {
+		if count > 0 && size >= maxSize {
+			break
+		}
+		data, err := json.Marshal(object)
+		if err != nil {
+			return count, size, err
+		}
+		if count > 0 {
+			if err := write([]byte(",")); err != nil {
+				return count, size, err
+			}
+		}
+		if err := write([]byte("\n")); err != nil {
+			return count, size, err
+		}
+		if err := write(data); err != nil {
+			return count, size, err
+		}
+		count++
+	}
+	if err := write([]byte("\n]\n")); err != nil {
+		return count, size, err
+	}
+	return count, size, nil
+}
+
+// chunkDecoder decodes a JSON list of objects written by writeResourcesChunk
+// one object at a time
+type chunkDecoder struct {
+	reader  io.Reader
+	decoder *json.Decoder
+	started bool
+	done    bool
+	// count is the number of objects decoded so far
+	count int
+}
+
+func newChunkDecoder(reader io.Reader) *chunkDecoder {
+	return &chunkDecoder{
+		reader:  reader,
+		decoder: json.NewDecoder(reader),
+	}
+}
+
+// next returns the next object in the list. io.EOF is returned after the end
+// of the list once the rest of the stream has been checked.
+func (d *chunkDecoder) next() (runtime.Unstructured, error) {
+	if d.done {
+		return nil, io.EOF
+	}
+	if !d.started {
+		token, err := d.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return nil, fmt.Errorf("expected start of list, found %v", token)
+		}
+		d.started = true
+	}
+	if d.decoder.More() {
+		object := &unstructured.Unstructured{}
+		if err := d.decoder.Decode(object); err != nil {
+			return nil, err
+		}
+		d.count++
+		return object, nil
+	}
+	if _, err := d.decoder.Token(); err != nil {
+		return nil, err
+	}
+	// The rest of the stream is read so that a truncated or tampered
+	// encrypted stream is detected. Anything but whitespace after the list
+	// means the chunk is corrupt.
+	if err := checkTrailingData(io.MultiReader(d.decoder.Buffered(), d.reader)); err != nil {
+		return nil, err
+	}
+	d.done = true
+	return nil, io.EOF
+}
+
+func checkTrailingData(reader io.Reader) error {
+	buffer := make([]byte, 32*1024)
+	for {
+		n, err := reader.Read(buffer)
+		if len(bytes.TrimSpace(buffer[:n])) > 0 {
+			return fmt.Errorf("corrupt resources chunk: unexpected data after end of list")
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readResourcesChunk decodes all the objects in a JSON list
+func readResourcesChunk(reader io.Reader) ([]runtime.Unstructured, error) {
+	decoder := newChunkDecoder(reader)
+	objects := make([]runtime.Unstructured, 0)
+	for {
+		object, err := decoder.next()
+		if err == io.EOF {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+}
@@ -0,0 +1,514 @@
+//go:build unittest
+// +build unittest
+
+package backupformat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/crypto"
+	"github.com/libopenstorage/stork/pkg/keyprovider"
+	"github.com/libopenstorage/stork/pkg/objectstore/file"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func getTestObjects(count int) []runtime.Unstructured {
+	objects := make([]runtime.Unstructured, 0)
+	for i := 0; i < count; i++ {
+		object := &unstructured.Unstructured{}
+		object.SetAPIVersion("v1")
+		object.SetKind("ConfigMap")
+		object.SetName(fmt.Sprintf("configmap-%v", i))
+		object.SetNamespace("test")
+		object.Object["data"] = map[string]interface{}{"key": "value"}
+		objects = append(objects, object)
+	}
+	return objects
+}
+
+func TestResourcesChunk(t *testing.T) {
+	objects := getTestObjects(10)
+	var buffer bytes.Buffer
+	count, size, err := writeResourcesChunk(&buffer, objects, defaultMaxChunkSize)
+	require.NoError(t, err, "Error writing resources chunk")
+	require.Equal(t, len(objects), count, "All objects should be in one chunk")
+	require.Equal(t, int64(buffer.Len()), size, "Size mismatch for resources chunk")
+
+	readObjects, err := readResourcesChunk(&buffer)
+	require.NoError(t, err, "Error reading resources chunk")
+	require.Equal(t, objects, readObjects, "Objects mismatch after reading chunk")
+}
+
+func TestResourcesChunkEmpty(t *testing.T) {
+	var buffer bytes.Buffer
+	count, _, err := writeResourcesChunk(&buffer, nil, defaultMaxChunkSize)
+	require.NoError(t, err, "Error writing resources chunk")
+	require.Equal(t, 0, count, "No objects should have been written")
+
+	readObjects, err := readResourcesChunk(&buffer)
+	require.NoError(t, err, "Error reading resources chunk")
+	require.Empty(t, readObjects, "No objects should have been read")
+}
+
+func TestResourcesChunkTrailingData(t *testing.T) {
+	objects := getTestObjects(2)
+	var buffer bytes.Buffer
+	_, _, err := writeResourcesChunk(&buffer, objects, defaultMaxChunkSize)
+	require.NoError(t, err, "Error writing resources chunk")
+
+	readObjects, err := readResourcesChunk(bytes.NewReader(append(buffer.Bytes(), " \n\t"...)))
+	require.NoError(t, err, "Trailing whitespace should be ignored")
+	require.Equal(t, objects, readObjects, "Objects mismatch after reading chunk")
+
+	_, err = readResourcesChunk(bytes.NewReader(append(buffer.Bytes(), `{"kind":"Secret"}`...)))
+	require.Error(t, err, "Trailing data after the list should fail")
+	require.Contains(t, err.Error(), "corrupt resources chunk")
+}
+
+func TestResourcesChunkMaxSize(t *testing.T) {
+	objects := getTestObjects(10)
+	remaining := objects
+	chunks := 0
+	for len(remaining) > 0 {
+		var buffer bytes.Buffer
+		// Small enough that every object goes to its own chunk
+		count, _, err := writeResourcesChunk(&buffer, remaining, 1)
+		require.NoError(t, err, "Error writing resources chunk")
+		require.Equal(t, 1, count, "Only one object should be written per chunk")
+
+		readObjects, err := readResourcesChunk(&buffer)
+		require.NoError(t, err, "Error reading resources chunk")
+		require.Equal(t, remaining[:count], readObjects, "Objects mismatch after reading chunk")
+		remaining = remaining[count:]
+		chunks++
+	}
+	require.Equal(t, len(objects), chunks, "Unexpected number of chunks")
+}
+
+func TestResourcesChunkEncrypted(t *testing.T) {
+	objects := getTestObjects(100)
+	var buffer bytes.Buffer
+	writer, err := crypto.NewEncryptWriter(&buffer, "testkey")
+	require.NoError(t, err, "Error creating encryption stream")
+	_, _, err = writeResourcesChunk(writer, objects, defaultMaxChunkSize)
+	require.NoError(t, err, "Error writing resources chunk")
+	require.NoError(t, writer.Close(), "Error closing encryption stream")
+
+	reader, err := crypto.NewDecryptReader(&buffer, "testkey")
+	require.NoError(t, err, "Error creating decryption stream")
+	readObjects, err := readResourcesChunk(reader)
+	require.NoError(t, err, "Error reading resources chunk")
+	require.Equal(t, objects, readObjects, "Objects mismatch after reading chunk")
+}
+
+func TestResourcesChunkCompressed(t *testing.T) {
+	objects := getTestObjects(100)
+	for _, compression := range []stork_api.BackupLocationCompressionType{
+		stork_api.BackupLocationCompressionNone,
+		stork_api.BackupLocationCompressionGzip,
+		stork_api.BackupLocationCompressionZstd,
+	} {
+		var buffer bytes.Buffer
+		var writer io.Writer = &buffer
+		compressor, err := newCompressWriter(&buffer, compression)
+		require.NoError(t, err, "Error creating compression stream for %v", compression)
+		if compressor != nil {
+			writer = compressor
+		}
+		_, _, err = writeResourcesChunk(writer, objects, defaultMaxChunkSize)
+		require.NoError(t, err, "Error writing resources chunk with %v", compression)
+		if compressor != nil {
+			require.NoError(t, compressor.Close(), "Error closing compression stream for %v", compression)
+		}
+
+		reader, err := newDecodeReader(context.Background(), &buffer, "")
+		require.NoError(t, err, "Error creating decode stream for %v", compression)
+		readObjects, err := readResourcesChunk(reader)
+		require.NoError(t, err, "Error reading resources chunk with %v", compression)
+		require.Equal(t, objects, readObjects, "Objects mismatch after reading chunk with %v", compression)
+	}
+}
+
+func TestResourcesChunkCompressedEncrypted(t *testing.T) {
+	objects := getTestObjects(100)
+	var buffer bytes.Buffer
+	encrypter, err := crypto.NewEncryptWriter(&buffer, "testkey")
+	require.NoError(t, err, "Error creating encryption stream")
+	compressor, err := newCompressWriter(encrypter, stork_api.BackupLocationCompressionGzip)
+	require.NoError(t, err, "Error creating compression stream")
+	_, _, err = writeResourcesChunk(compressor, objects, defaultMaxChunkSize)
+	require.NoError(t, err, "Error writing resources chunk")
+	require.NoError(t, compressor.Close(), "Error closing compression stream")
+	require.NoError(t, encrypter.Close(), "Error closing encryption stream")
+
+	_, err = newDecodeReader(context.Background(), bytes.NewReader(buffer.Bytes()), "")
+	require.Error(t, err, "Decoding encrypted data without a key should fail")
+
+	reader, err := newDecodeReader(context.Background(), &buffer, "testkey")
+	require.NoError(t, err, "Error creating decode stream")
+	readObjects, err := readResourcesChunk(reader)
+	require.NoError(t, err, "Error reading resources chunk")
+	require.Equal(t, objects, readObjects, "Objects mismatch after reading chunk")
+}
+
+func TestResourcesChunkRecordedCompression(t *testing.T) {
+	objects := getTestObjects(10)
+	var buffer bytes.Buffer
+	compressor, err := newCompressWriter(&buffer, stork_api.BackupLocationCompressionZstd)
+	require.NoError(t, err, "Error creating compression stream")
+	_, _, err = writeResourcesChunk(compressor, objects, defaultMaxChunkSize)
+	require.NoError(t, err, "Error writing resources chunk")
+	require.NoError(t, compressor.Close(), "Error closing compression stream")
+
+	for _, compression := range []stork_api.BackupLocationCompressionType{
+		stork_api.BackupLocationCompressionNone,
+		stork_api.BackupLocationCompressionGzip,
+	} {
+		ctx := WithCompression(context.Background(), compression)
+		_, err = newDecodeReader(ctx, bytes.NewReader(buffer.Bytes()), "")
+		require.Error(t, err, "Decoding with recorded compression %v should fail", compression)
+	}
+
+	ctx := WithCompression(context.Background(), stork_api.BackupLocationCompressionZstd)
+	reader, err := newDecodeReader(ctx, &buffer, "")
+	require.NoError(t, err, "Error creating decode stream")
+	readObjects, err := readResourcesChunk(reader)
+	require.NoError(t, err, "Error reading resources chunk")
+	require.Equal(t, objects, readObjects, "Objects mismatch after reading chunk")
+}
+
+func TestValidateCompression(t *testing.T) {
+	require.NoError(t, ValidateCompression(stork_api.BackupLocationCompressionNone))
+	require.NoError(t, ValidateCompression(stork_api.BackupLocationCompressionGzip))
+	require.NoError(t, ValidateCompression(stork_api.BackupLocationCompressionZstd))
+	require.Error(t, ValidateCompression("zip"))
+}
+
+func TestWrapDataKey(t *testing.T) {
+	kms := keyprovider.NewLocalKMS()
+	kms.AddKey("key1", []byte("kmskey1"))
+	kms.AddKey("key2", []byte("kmskey2"))
+	require.NoError(t, keyprovider.RegisterKMS("backupformat-test", kms), "Error registering kms")
+	config := &stork_api.EncryptionKeyProvider{
+		Type:  stork_api.EncryptionKeyProviderKMS,
+		KeyID: "key1",
+		KMS:   "backupformat-test",
+	}
+	provider, err := keyprovider.Get(&stork_api.BackupLocation{
+		Location: stork_api.BackupLocationItem{EncryptionKeyProvider: config},
+	})
+	require.NoError(t, err, "Error getting key provider")
+
+	key, err := crypto.GenerateDataKey()
+	require.NoError(t, err, "Error generating data key")
+	wrappedKey, err := provider.WrapKey("key1", key)
+	require.NoError(t, err, "Error wrapping data key")
+	dataKey := &DataKey{
+		Provider:   config.Type,
+		KeyID:      "key1",
+		WrappedKey: wrappedKey,
+	}
+	require.False(t, rewrapDataKey(dataKey, config), "Data key is already wrapped with the current key")
+
+	// Rotate to key2 and remove key1, the data key should still unwrap to
+	// the same key
+	config.KeyID = "key2"
+	require.True(t, rewrapDataKey(dataKey, config), "Data key should be re-wrapped after rotation")
+	rewrappedKey, err := wrapDataKey(provider, dataKey, config)
+	require.NoError(t, err, "Error re-wrapping data key")
+	require.Equal(t, "key2", rewrappedKey.KeyID, "Key ID mismatch for re-wrapped data key")
+	kms.RemoveKey("key1")
+	unwrappedKey, err := provider.UnwrapKey(rewrappedKey.KeyID, rewrappedKey.WrappedKey)
+	require.NoError(t, err, "Error unwrapping re-wrapped data key")
+	require.Equal(t, key, unwrappedKey, "Data key changed after re-wrapping")
+
+	dataKey.Provider = stork_api.EncryptionKeyProviderSecret
+	_, err = wrapDataKey(provider, dataKey, config)
+	require.Error(t, err, "Re-wrapping a data key from a different provider should have failed")
+}
+
+func getKMSTestBackupLocation(t *testing.T, kmsName string) (*stork_api.BackupLocation, *keyprovider.LocalKMS) {
+	kms := keyprovider.NewLocalKMS()
+	kms.AddKey("key1", []byte("kmskey1"))
+	kms.AddKey("key2", []byte("kmskey2"))
+	require.NoError(t, keyprovider.RegisterKMS(kmsName, kms), "Error registering kms")
+	dir, err := ioutil.TempDir("", "backupformat")
+	require.NoError(t, err, "Error creating backup location directory")
+	return &stork_api.BackupLocation{
+		Location: stork_api.BackupLocationItem{
+			Type: stork_api.BackupLocationFile,
+			Path: dir,
+			EncryptionKeyProvider: &stork_api.EncryptionKeyProvider{
+				Type:  stork_api.EncryptionKeyProviderKMS,
+				KeyID: "key1",
+				KMS:   kmsName,
+			},
+		},
+	}, kms
+}
+
+func TestRewrapDataKeys(t *testing.T) {
+	backupLocation, kms := getKMSTestBackupLocation(t, "backupformat-rewrap-test")
+	defer os.RemoveAll(backupLocation.Location.Path)
+	bucket, err := file.GetBucket(backupLocation)
+	require.NoError(t, err, "Error getting bucket")
+	ctx := context.Background()
+	require.NoError(t, WriteObject(ctx, bucket, backupLocation, "ns/backup/uid/metadata.json", []byte("{}"), nil))
+
+	dataKey, err := GetDataKey(ctx, bucket, "ns/backup/uid")
+	require.NoError(t, err, "Error getting data key")
+	require.NotNil(t, dataKey, "Data key wasn't created")
+	require.Equal(t, "key1", dataKey.KeyID)
+	provider, err := keyprovider.Get(backupLocation)
+	require.NoError(t, err, "Error getting key provider")
+	key, err := provider.UnwrapKey(dataKey.KeyID, dataKey.WrappedKey)
+	require.NoError(t, err, "Error unwrapping data key")
+
+	backupLocation.Location.EncryptionKeyProvider.KeyID = "key2"
+	paths, err := RewrapDataKeys(ctx, bucket, backupLocation, "")
+	require.NoError(t, err, "Error re-wrapping data keys")
+	require.Equal(t, []string{"ns/backup/uid"}, paths)
+
+	rewrappedKey, err := GetDataKey(ctx, bucket, "ns/backup/uid")
+	require.NoError(t, err, "Error getting re-wrapped data key")
+	require.Equal(t, stork_api.EncryptionKeyProviderKMS, rewrappedKey.Provider)
+	require.Equal(t, "key2", rewrappedKey.KeyID, "Data key wasn't re-wrapped with the new key")
+	require.NotEqual(t, dataKey.WrappedKey, rewrappedKey.WrappedKey, "Wrapped data key didn't change")
+	kms.RemoveKey("key1")
+	unwrappedKey, err := provider.UnwrapKey(rewrappedKey.KeyID, rewrappedKey.WrappedKey)
+	require.NoError(t, err, "Error unwrapping re-wrapped data key")
+	require.Equal(t, key, unwrappedKey, "Data key changed after re-wrapping")
+
+	// The objects can still be read without the old key
+	dataKeyCacheLock.Lock()
+	dataKeyCache = make(map[string][]byte)
+	dataKeyCacheLock.Unlock()
+	data, err := ReadObject(ctx, bucket, backupLocation, "ns/backup/uid/metadata.json")
+	require.NoError(t, err, "Error reading object after re-wrapping")
+	require.Equal(t, []byte("{}"), data)
+
+	paths, err = RewrapDataKeys(ctx, bucket, backupLocation, "")
+	require.NoError(t, err, "Error re-wrapping data keys")
+	require.Empty(t, paths, "Data keys shouldn't be re-wrapped again")
+}
+
+func TestCreateDataKeyConcurrent(t *testing.T) {
+	backupLocation, _ := getKMSTestBackupLocation(t, "backupformat-create-test")
+	defer os.RemoveAll(backupLocation.Location.Path)
+	bucket, err := file.GetBucket(backupLocation)
+	require.NoError(t, err, "Error getting bucket")
+	provider, err := keyprovider.Get(backupLocation)
+	require.NoError(t, err, "Error getting key provider")
+
+	dataKeys := make(chan *DataKey, 10)
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		go func() {
+			dataKey, err := createDataKey(context.Background(), bucket, provider,
+				backupLocation.Location.EncryptionKeyProvider, "ns/backup/uid")
+			dataKeys <- dataKey
+			errs <- err
+		}()
+	}
+	var first *DataKey
+	for i := 0; i < 10; i++ {
+		require.NoError(t, <-errs, "Error creating data key")
+		dataKey := <-dataKeys
+		if first == nil {
+			first = dataKey
+		}
+		require.Equal(t, first.WrappedKey, dataKey.WrappedKey, "Concurrent writers got different data keys")
+	}
+	stored, err := GetDataKey(context.Background(), bucket, "ns/backup/uid")
+	require.NoError(t, err, "Error getting data key")
+	require.Equal(t, first.WrappedKey, stored.WrappedKey, "Stored data key doesn't match the returned key")
+}
+
+func uploadTestBackup(t *testing.T, encryptionKey string) (*stork_api.BackupLocation, string) {
+	dir, err := ioutil.TempDir("", "backupformat")
+	require.NoError(t, err, "Error creating backup location directory")
+	backupLocation := &stork_api.BackupLocation{
+		Location: stork_api.BackupLocationItem{
+			Type:            stork_api.BackupLocationFile,
+			Path:            dir,
+			EncryptionV2Key: encryptionKey,
+		},
+	}
+	bucket, err := file.GetBucket(backupLocation)
+	require.NoError(t, err, "Error getting bucket")
+	ctx := context.Background()
+	digests := make(IntegrityDigests)
+	require.NoError(t, UploadResources(ctx, bucket, backupLocation, "ns/backup/uid", getTestObjects(10), digests))
+	require.NoError(t, WriteObject(ctx, bucket, backupLocation, "ns/backup/uid/metadata.json", []byte("{}"), digests))
+	require.NoError(t, UploadIntegrityManifest(ctx, bucket, backupLocation, "ns/backup/uid", digests))
+	return backupLocation, dir
+}
+
+func verifyTestBackup(t *testing.T, backupLocation *stork_api.BackupLocation) map[string]error {
+	bucket, err := file.GetBucket(backupLocation)
+	require.NoError(t, err, "Error getting bucket")
+	results, err := VerifyBackup(context.Background(), bucket, backupLocation, "ns/backup/uid")
+	require.NoError(t, err, "Error verifying backup")
+	errors := make(map[string]error)
+	for _, result := range results {
+		errors[result.Name] = result.Error
+	}
+	return errors
+}
+
+func TestVerifyBackup(t *testing.T) {
+	for _, encryptionKey := range []string{"", "testkey"} {
+		backupLocation, dir := uploadTestBackup(t, encryptionKey)
+		defer os.RemoveAll(dir) // nolint: errcheck
+
+		data, err := ioutil.ReadFile(filepath.Join(dir, "ns/backup/uid", IntegrityManifestObjectName))
+		require.NoError(t, err, "Error reading integrity manifest")
+		manifest := &IntegrityManifest{}
+		require.NoError(t, json.Unmarshal(data, manifest), "Error parsing integrity manifest")
+		require.Len(t, manifest.Objects, 3, "Integrity manifest should have the chunk, resources manifest and metadata")
+		require.Equal(t, encryptionKey != "", manifest.Signature != "", "Manifest should only be signed with an encryption key")
+
+		errors := verifyTestBackup(t, backupLocation)
+		require.Len(t, errors, 3, "All objects should have been verified")
+		for name, err := range errors {
+			require.NoError(t, err, "Error verifying %v", name)
+		}
+	}
+}
+
+func TestIntegrityDigests(t *testing.T) {
+	for _, encryptionKey := range []string{"", "testkey"} {
+		backupLocation, dir := uploadTestBackup(t, encryptionKey)
+		defer os.RemoveAll(dir) // nolint: errcheck
+		bucket, err := file.GetBucket(backupLocation)
+		require.NoError(t, err, "Error getting bucket")
+		ctx := context.Background()
+
+		// The checksums recorded while uploading should match the ones
+		// computed by reading the objects back
+		recorded, err := GetIntegrityManifest(ctx, bucket, backupLocation, "ns/backup/uid")
+		require.NoError(t, err, "Error getting integrity manifest")
+		require.NoError(t, UploadIntegrityManifest(ctx, bucket, backupLocation, "ns/backup/uid", nil))
+		computed, err := GetIntegrityManifest(ctx, bucket, backupLocation, "ns/backup/uid")
+		require.NoError(t, err, "Error getting integrity manifest")
+		require.Equal(t, computed.Objects, recorded.Objects, "Recorded checksums mismatch")
+	}
+}
+
+func TestVerifyBackupCorrupted(t *testing.T) {
+	backupLocation, dir := uploadTestBackup(t, "testkey")
+	defer os.RemoveAll(dir) // nolint: errcheck
+	objectDir := filepath.Join(dir, "ns/backup/uid")
+
+	// Truncate one object, remove another and add one that isn't in the
+	// manifest
+	chunk := filepath.Join(objectDir, fmt.Sprintf(resourcesChunkObjectFormat, 0))
+	data, err := ioutil.ReadFile(chunk)
+	require.NoError(t, err, "Error reading resources chunk")
+	require.NoError(t, ioutil.WriteFile(chunk, data[:len(data)-10], 0644))
+	require.NoError(t, os.Remove(filepath.Join(objectDir, "metadata.json")))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(objectDir, "extra.json"), []byte("{}"), 0644))
+
+	errors := verifyTestBackup(t, backupLocation)
+	require.Error(t, errors[fmt.Sprintf(resourcesChunkObjectFormat, 0)], "Truncated object should have failed verification")
+	require.Error(t, errors["metadata.json"], "Missing object should have failed verification")
+	require.Error(t, errors["extra.json"], "Object not in the manifest should have failed verification")
+	require.NoError(t, errors[ResourcesManifestObjectName], "Unmodified object should have passed verification")
+}
+
+func TestVerifyBackupTamperedManifest(t *testing.T) {
+	backupLocation, dir := uploadTestBackup(t, "testkey")
+	defer os.RemoveAll(dir) // nolint: errcheck
+	manifestFile := filepath.Join(dir, "ns/backup/uid", IntegrityManifestObjectName)
+	bucket, err := file.GetBucket(backupLocation)
+	require.NoError(t, err, "Error getting bucket")
+
+	data, err := ioutil.ReadFile(manifestFile)
+	require.NoError(t, err, "Error reading integrity manifest")
+	manifest := &IntegrityManifest{}
+	require.NoError(t, json.Unmarshal(data, manifest), "Error parsing integrity manifest")
+
+	manifest.Objects[0].Size++
+	data, err = json.Marshal(manifest)
+	require.NoError(t, err, "Error marshalling integrity manifest")
+	require.NoError(t, ioutil.WriteFile(manifestFile, data, 0644))
+	_, err = VerifyBackup(context.Background(), bucket, backupLocation, "ns/backup/uid")
+	require.Error(t, err, "Verifying with a modified manifest should have failed")
+
+	manifest.Signature = ""
+	data, err = json.Marshal(manifest)
+	require.NoError(t, err, "Error marshalling integrity manifest")
+	require.NoError(t, ioutil.WriteFile(manifestFile, data, 0644))
+	_, err = VerifyBackup(context.Background(), bucket, backupLocation, "ns/backup/uid")
+	require.Error(t, err, "Verifying with an unsigned manifest should have failed")
+
+	// Backups without a manifest are still decoded and parsed
+	require.NoError(t, os.Remove(manifestFile))
+	errors := verifyTestBackup(t, backupLocation)
+	require.Len(t, errors, 3, "All objects should have been verified")
+	for name, err := range errors {
+		require.NoError(t, err, "Error verifying %v", name)
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	require.NoError(t, validateJSON(bytes.NewReader([]byte(`{"a": [1, 2, {"b": "c"}]}`))))
+	require.NoError(t, validateJSON(bytes.NewReader([]byte("[\n{},\n{}\n]\n"))))
+	require.Error(t, validateJSON(bytes.NewReader([]byte(`{"a": [1, 2`))), "Truncated JSON should be invalid")
+	require.Error(t, validateJSON(bytes.NewReader([]byte(`{}{}`))), "Multiple values should be invalid")
+	require.Error(t, validateJSON(bytes.NewReader(nil)), "Empty object should be invalid")
+}
+
+func TestResourcesReader(t *testing.T) {
+	for _, encryptionKey := range []string{"", "testkey"} {
+		backupLocation, dir := uploadTestBackup(t, encryptionKey)
+		defer os.RemoveAll(dir) // nolint: errcheck
+		bucket, err := file.GetBucket(backupLocation)
+		require.NoError(t, err, "Error getting bucket")
+
+		reader, err := NewResourcesReader(context.Background(), bucket, backupLocation, "ns/backup/uid")
+		require.NoError(t, err, "Error creating resources reader")
+		readObjects := make([]runtime.Unstructured, 0)
+		for {
+			object, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err, "Error reading resources")
+			readObjects = append(readObjects, object)
+		}
+		require.NoError(t, reader.Close(), "Error closing resources reader")
+		require.Equal(t, getTestObjects(10), readObjects, "Objects mismatch after reading resources")
+
+		objects, err := DownloadResources(context.Background(), bucket, backupLocation, "ns/backup/uid")
+		require.NoError(t, err, "Error downloading resources")
+		require.Equal(t, getTestObjects(10), objects, "Objects mismatch after downloading resources")
+	}
+}
+
+func TestResourcesReaderTrailingChunkData(t *testing.T) {
+	backupLocation, dir := uploadTestBackup(t, "")
+	defer os.RemoveAll(dir) // nolint: errcheck
+	bucket, err := file.GetBucket(backupLocation)
+	require.NoError(t, err, "Error getting bucket")
+
+	chunkPath := filepath.Join(dir, "ns/backup/uid", fmt.Sprintf(resourcesChunkObjectFormat, 0))
+	data, err := ioutil.ReadFile(chunkPath)
+	require.NoError(t, err, "Error reading resources chunk")
+	require.NoError(t, ioutil.WriteFile(chunkPath, append(data, "[]"...), 0644))
+
+	_, err = DownloadResources(context.Background(), bucket, backupLocation, "ns/backup/uid")
+	require.Error(t, err, "Downloading a chunk with trailing data should fail")
+	require.Contains(t, err.Error(), "corrupt resources chunk")
+}
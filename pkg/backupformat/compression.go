@@ -0,0 +1,135 @@
+package backupformat
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+)
+
+var (
+	// Compressed objects are detected from the magic bytes at the start of
+	// the object. Uncompressed objects are JSON, so they never start with
+	// either of these.
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+type compressionContextKey struct{}
+
+// WithCompression returns a context for reading the objects of a backup that
+// was recorded to be compressed with the given algorithm. Objects read with
+// it fail to decode if they were compressed differently instead of being
+// decoded based on their magic bytes alone. Objects read without it, e.g. when
+// syncing backups whose compression isn't known yet, are still detected.
+func WithCompression(
+	ctx context.Context,
+	compression stork_api.BackupLocationCompressionType,
+) context.Context {
+	return context.WithValue(ctx, compressionContextKey{}, compression)
+}
+
+func compressionFromContext(ctx context.Context) (stork_api.BackupLocationCompressionType, bool) {
+	compression, ok := ctx.Value(compressionContextKey{}).(stork_api.BackupLocationCompressionType)
+	return compression, ok
+}
+
+// ValidateCompression returns an error if the compression type isn't
+// supported
+func ValidateCompression(compression stork_api.BackupLocationCompressionType) error {
+	switch compression {
+	case stork_api.BackupLocationCompressionNone,
+		stork_api.BackupLocationCompressionGzip,
+		stork_api.BackupLocationCompressionZstd:
+		return nil
+	default:
+		return fmt.Errorf("invalid compression type %v, supported types are %v and %v",
+			compression, stork_api.BackupLocationCompressionGzip, stork_api.BackupLocationCompressionZstd)
+	}
+}
+
+// newCompressWriter returns a writer that compresses data with the given
+// algorithm before writing it to writer. nil is returned if no compression
+// is required.
+func newCompressWriter(
+	writer io.Writer,
+	compression stork_api.BackupLocationCompressionType,
+) (io.WriteCloser, error) {
+	switch compression {
+	case stork_api.BackupLocationCompressionNone:
+		return nil, nil
+	case stork_api.BackupLocationCompressionGzip:
+		return gzip.NewWriter(writer), nil
+	case stork_api.BackupLocationCompressionZstd:
+		return zstd.NewWriter(writer)
+	default:
+		return nil, ValidateCompression(compression)
+	}
+}
+
+// newDecompressReader returns a reader that decompresses data from reader
+// based on the magic bytes at the start of it. Data that wasn't compressed
+// is returned as is. If the context has the compression that was recorded
+// for the backup, data compressed with anything else is rejected.
+func newDecompressReader(ctx context.Context, reader io.Reader) (io.Reader, error) {
+	bufferedReader := bufio.NewReader(reader)
+	header, err := bufferedReader.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	detected := stork_api.BackupLocationCompressionNone
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		detected = stork_api.BackupLocationCompressionGzip
+	case bytes.HasPrefix(header, zstdMagic):
+		detected = stork_api.BackupLocationCompressionZstd
+	}
+	if expected, ok := compressionFromContext(ctx); ok && expected != detected {
+		return nil, fmt.Errorf("object is %v but the backup was recorded as %v",
+			compressionName(detected), compressionName(expected))
+	}
+
+	switch detected {
+	case stork_api.BackupLocationCompressionGzip:
+		return gzip.NewReader(bufferedReader)
+	case stork_api.BackupLocationCompressionZstd:
+		decoder, err := zstd.NewReader(bufferedReader, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return &zstdReader{decoder: decoder}, nil
+	default:
+		return bufferedReader, nil
+	}
+}
+
+func compressionName(compression stork_api.BackupLocationCompressionType) string {
+	if compression == stork_api.BackupLocationCompressionNone {
+		return "uncompressed"
+	}
+	return fmt.Sprintf("compressed with %v", compression)
+}
+
+// zstdReader closes the zstd decoder once the object has been read since
+// the decoded readers aren't closed by their callers
+type zstdReader struct {
+	decoder *zstd.Decoder
+	err     error
+}
+
+func (z *zstdReader) Read(p []byte) (int, error) {
+	if z.err != nil {
+		return 0, z.err
+	}
+	n, err := z.decoder.Read(p)
+	if err != nil {
+		z.err = err
+		z.decoder.Close()
+	}
+	return n, err
+}
@@ -0,0 +1,333 @@
+package backupformat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"cloud.google.com/go/storage"
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/crypto"
+	"github.com/libopenstorage/stork/pkg/keyprovider"
+	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
+)
+
+const (
+	// DataKeyObjectName is the object with the wrapped data key for the
+	// objects in a backup path. It is not encrypted.
+	DataKeyObjectName = "datakey.json"
+	// Unwrapped data keys are cached per backup path so that the data key
+	// isn't downloaded and unwrapped for every object that is read or written
+	maxCachedDataKeys = 1024
+)
+
+var (
+	dataKeyCacheLock sync.Mutex
+	dataKeyCache     = make(map[string][]byte)
+	// dataKeyCreateLock serializes the creation of data keys so that
+	// concurrent writers in a backup path never create different keys
+	dataKeyCreateLock sync.Mutex
+)
+
+// DataKey is the key the objects in a backup path are encrypted with when the
+// backup location uses envelope encryption. Only the wrapped key is stored,
+// along with the ID of the key encryption key needed to unwrap it.
+type DataKey struct {
+	Provider   stork_api.EncryptionKeyProviderType `json:"provider"`
+	KeyID      string                              `json:"keyID"`
+	WrappedKey []byte                              `json:"wrappedKey"`
+}
+
+// GetDataKey returns the data key for the objects in the given path. nil is
+// returned if the objects weren't encrypted with a data key.
+func GetDataKey(
+	ctx context.Context,
+	bucket *blob.Bucket,
+	objectPath string,
+) (*DataKey, error) {
+	data, err := bucket.ReadAll(ctx, filepath.Join(objectPath, DataKeyObjectName))
+	if err != nil {
+		if gcerrors.Code(err) == gcerrors.NotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	dataKey := &DataKey{}
+	if err := json.Unmarshal(data, dataKey); err != nil {
+		return nil, fmt.Errorf("error parsing data key for %v: %v", objectPath, err)
+	}
+	return dataKey, nil
+}
+
+// DeleteDataKey deletes the data key for the objects in the given path. It
+// should only be called once all the objects have been deleted.
+func DeleteDataKey(
+	ctx context.Context,
+	bucket *blob.Bucket,
+	objectPath string,
+) error {
+	err := bucket.Delete(ctx, filepath.Join(objectPath, DataKeyObjectName))
+	if err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+		return err
+	}
+	return nil
+}
+
+// RewrapDataKeys wraps the data keys of all the backup paths under prefix
+// with the current key ID of the key provider of the backup location. The
+// objects themselves are not changed. The paths whose data keys were
+// re-wrapped are returned.
+func RewrapDataKeys(
+	ctx context.Context,
+	bucket *blob.Bucket,
+	backupLocation *stork_api.BackupLocation,
+	prefix string,
+) ([]string, error) {
+	provider, err := keyprovider.Get(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	config := backupLocation.Location.EncryptionKeyProvider
+	rewrapped := make([]string, 0)
+	iterator := bucket.List(&blob.ListOptions{
+		Prefix: prefix,
+	})
+	for {
+		object, err := iterator.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return rewrapped, err
+		}
+		if object.IsDir || filepath.Base(object.Key) != DataKeyObjectName {
+			continue
+		}
+		objectPath := strings.TrimSuffix(filepath.Dir(object.Key), "/")
+		dataKey, err := GetDataKey(ctx, bucket, objectPath)
+		if err != nil {
+			return rewrapped, err
+		}
+		if dataKey == nil || !rewrapDataKey(dataKey, config) {
+			continue
+		}
+		if dataKey, err = wrapDataKey(provider, dataKey, config); err != nil {
+			return rewrapped, fmt.Errorf("error re-wrapping data key for %v: %v", objectPath, err)
+		}
+		if err := writeDataKey(ctx, bucket, objectPath, dataKey); err != nil {
+			return rewrapped, fmt.Errorf("error uploading data key for %v: %v", objectPath, err)
+		}
+		rewrapped = append(rewrapped, objectPath)
+	}
+	return rewrapped, nil
+}
+
+// rewrapDataKey returns true if the data key isn't wrapped with the current
+// key of the provider
+func rewrapDataKey(dataKey *DataKey, config *stork_api.EncryptionKeyProvider) bool {
+	return dataKey.Provider != config.Type || dataKey.KeyID != config.KeyID
+}
+
+// wrapDataKey unwraps the data key with the key it was wrapped with and
+// wraps it again with the current key of the provider
+func wrapDataKey(
+	provider keyprovider.Provider,
+	dataKey *DataKey,
+	config *stork_api.EncryptionKeyProvider,
+) (*DataKey, error) {
+	if dataKey.Provider != config.Type {
+		return nil, fmt.Errorf("data key was wrapped by the %v key provider, current provider is %v",
+			dataKey.Provider, config.Type)
+	}
+	key, err := provider.UnwrapKey(dataKey.KeyID, dataKey.WrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	wrappedKey, err := provider.WrapKey(config.KeyID, key)
+	if err != nil {
+		return nil, err
+	}
+	return &DataKey{
+		Provider:   config.Type,
+		KeyID:      config.KeyID,
+		WrappedKey: wrappedKey,
+	}, nil
+}
+
+func writeDataKey(
+	ctx context.Context,
+	bucket *blob.Bucket,
+	objectPath string,
+	dataKey *DataKey,
+) error {
+	data, err := json.MarshalIndent(dataKey, "", " ")
+	if err != nil {
+		return err
+	}
+	return bucket.WriteAll(ctx, filepath.Join(objectPath, DataKeyObjectName), data, nil)
+}
+
+// getPassphrase returns the passphrase the object with the given key is
+// encrypted with. If the backup location uses envelope encryption this is the
+// data key for the path of the object, which is generated if create is set
+// and the path doesn't have one yet. Objects in paths without a data key were
+// written before envelope encryption was configured and use EncryptionV2Key.
+func getPassphrase(
+	ctx context.Context,
+	bucket *blob.Bucket,
+	backupLocation *stork_api.BackupLocation,
+	key string,
+	create bool,
+) (string, error) {
+	config := backupLocation.Location.EncryptionKeyProvider
+	if config == nil {
+		return backupLocation.Location.EncryptionV2Key, nil
+	}
+	provider, err := keyprovider.Get(backupLocation)
+	if err != nil {
+		return "", err
+	}
+	objectPath := filepath.Dir(key)
+	// Re-wrapping doesn't change the unwrapped key, so the cached key is
+	// valid for as long as the backup path exists
+	cacheKey := fmt.Sprintf("%v/%v/%v/%v/%v", backupLocation.Namespace, backupLocation.Name,
+		backupLocation.UID, backupLocation.Location.Path, objectPath)
+	dataKeyCacheLock.Lock()
+	cachedKey, ok := dataKeyCache[cacheKey]
+	dataKeyCacheLock.Unlock()
+	if ok {
+		return string(cachedKey), nil
+	}
+
+	dataKey, err := GetDataKey(ctx, bucket, objectPath)
+	if err != nil {
+		return "", err
+	}
+	if dataKey == nil {
+		if !create {
+			return backupLocation.Location.EncryptionV2Key, nil
+		}
+		if dataKey, err = createDataKey(ctx, bucket, provider, config, objectPath); err != nil {
+			return "", err
+		}
+	}
+	unwrappedKey, err := unwrapDataKey(provider, dataKey, config, objectPath)
+	if err != nil {
+		return "", err
+	}
+
+	dataKeyCacheLock.Lock()
+	if len(dataKeyCache) >= maxCachedDataKeys {
+		dataKeyCache = make(map[string][]byte)
+	}
+	dataKeyCache[cacheKey] = unwrappedKey
+	dataKeyCacheLock.Unlock()
+	return string(unwrappedKey), nil
+}
+
+func unwrapDataKey(
+	provider keyprovider.Provider,
+	dataKey *DataKey,
+	config *stork_api.EncryptionKeyProvider,
+	objectPath string,
+) ([]byte, error) {
+	if dataKey.Provider != config.Type {
+		return nil, fmt.Errorf("data key for %v was wrapped by the %v key provider, current provider is %v",
+			objectPath, dataKey.Provider, config.Type)
+	}
+	unwrappedKey, err := provider.UnwrapKey(dataKey.KeyID, dataKey.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("error unwrapping data key for %v with key %v: %v", objectPath, dataKey.KeyID, err)
+	}
+	return unwrappedKey, nil
+}
+
+// createDataKey creates the data key for a backup path if it doesn't have one
+// yet. The key is only written if it doesn't exist on object stores that
+// support conditional writes, and it is read again after it was written, so
+// the key that is returned is always the one that is stored even if another
+// writer created one at the same time.
+func createDataKey(
+	ctx context.Context,
+	bucket *blob.Bucket,
+	provider keyprovider.Provider,
+	config *stork_api.EncryptionKeyProvider,
+	objectPath string,
+) (*DataKey, error) {
+	if config.KeyID == "" {
+		return nil, fmt.Errorf("keyID is required for the encryption key provider")
+	}
+	dataKeyCreateLock.Lock()
+	defer dataKeyCreateLock.Unlock()
+
+	dataKey, err := GetDataKey(ctx, bucket, objectPath)
+	if err != nil || dataKey != nil {
+		return dataKey, err
+	}
+	key, err := crypto.GenerateDataKey()
+	if err != nil {
+		return nil, err
+	}
+	wrappedKey, err := provider.WrapKey(config.KeyID, key)
+	if err != nil {
+		return nil, fmt.Errorf("error wrapping data key for %v with key %v: %v", objectPath, config.KeyID, err)
+	}
+	dataKey = &DataKey{
+		Provider:   config.Type,
+		KeyID:      config.KeyID,
+		WrappedKey: wrappedKey,
+	}
+	data, err := json.MarshalIndent(dataKey, "", " ")
+	if err != nil {
+		return nil, err
+	}
+	err = bucket.WriteAll(ctx, filepath.Join(objectPath, DataKeyObjectName), data, &blob.WriterOptions{
+		BeforeWrite: writeIfNotExists,
+	})
+	if err != nil && !isExistsError(bucket, err) {
+		return nil, fmt.Errorf("error uploading data key for %v: %v", objectPath, err)
+	}
+
+	if dataKey, err = GetDataKey(ctx, bucket, objectPath); err != nil {
+		return nil, err
+	} else if dataKey == nil {
+		return nil, fmt.Errorf("data key for %v not found after it was uploaded", objectPath)
+	}
+	return dataKey, nil
+}
+
+// writeIfNotExists makes a write fail if the object already exists for the
+// object stores that support it
+func writeIfNotExists(asFunc func(interface{}) bool) error {
+	var objectHandle **storage.ObjectHandle
+	if asFunc(&objectHandle) {
+		*objectHandle = (*objectHandle).If(storage.Conditions{DoesNotExist: true})
+		return nil
+	}
+	var uploadOptions *azblob.UploadStreamToBlockBlobOptions
+	if asFunc(&uploadOptions) {
+		uploadOptions.AccessConditions.ModifiedAccessConditions.IfNoneMatch = azblob.ETagAny
+	}
+	return nil
+}
+
+// isExistsError returns true if a conditional write failed because the object
+// already exists
+func isExistsError(bucket *blob.Bucket, err error) bool {
+	if gcerrors.Code(err) == gcerrors.FailedPrecondition {
+		return true
+	}
+	var storageErr azblob.StorageError
+	if bucket.ErrorAs(err, &storageErr) && storageErr.Response() != nil {
+		status := storageErr.Response().StatusCode
+		return status == http.StatusConflict || status == http.StatusPreconditionFailed
+	}
+	return false
+}
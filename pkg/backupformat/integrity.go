@@ -0,0 +1,405 @@
+package backupformat
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/crypto"
+	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
+)
+
+const (
+	// IntegrityManifestObjectName is the object that lists the size and
+	// checksum of every object in a backup path. It is not encrypted, but is
+	// signed when the backup location has an encryption key. Without an
+	// encryption key the manifest only detects corruption, not tampering,
+	// since anyone who can modify the objects can also update the manifest.
+	IntegrityManifestObjectName = "integrity-manifest.json"
+	// IntegrityManifestVersion is the current version of the integrity
+	// manifest
+	IntegrityManifestVersion = 1
+)
+
+// IntegrityManifest records the objects that were uploaded for a backup so
+// that corrupted, truncated or missing objects can be detected without
+// restoring the backup
+type IntegrityManifest struct {
+	Version int               `json:"version"`
+	Objects []IntegrityObject `json:"objects"`
+	// Signature is the HMAC of the manifest without the signature, keyed with
+	// the key the objects were encrypted with. It is empty for backup
+	// locations without an encryption key, in which case the manifest can't
+	// be trusted to detect objects that were modified on purpose.
+	Signature string `json:"signature,omitempty"`
+}
+
+// IntegrityDigests are the checksums of objects recorded while they were
+// uploaded, keyed by the key of the object in the backup location
+type IntegrityDigests map[string]IntegrityObject
+
+func (d IntegrityDigests) record(writer *ObjectWriter) {
+	if d == nil {
+		return
+	}
+	d[writer.key] = writer.Digest()
+}
+
+// IntegrityObject is one object in the backup location as it was uploaded
+type IntegrityObject struct {
+	// Name of the object relative to the backup path
+	Name string `json:"name"`
+	// Size is the size of the object in the backup location
+	Size int64 `json:"size"`
+	// SHA256 is the hex encoded checksum of the object in the backup location
+	SHA256 string `json:"sha256"`
+}
+
+// ObjectVerification is the result of verifying one object of a backup
+type ObjectVerification struct {
+	// Name of the object relative to the backup path
+	Name string
+	// Size of the object that was read from the backup location
+	Size int64
+	// Error is set if the object failed verification
+	Error error
+}
+
+// UploadIntegrityManifest writes the integrity manifest for all the objects
+// in the given path. The checksums recorded in digests while the objects were
+// uploaded are used, and only objects that aren't in digests, like the ones
+// uploaded by the volume drivers, are read back to checksum them. It should
+// be called once all the objects for a backup have been uploaded.
+func UploadIntegrityManifest(
+	ctx context.Context,
+	bucket *blob.Bucket,
+	backupLocation *stork_api.BackupLocation,
+	objectPath string,
+	digests IntegrityDigests,
+) error {
+	names, err := listBackupObjects(ctx, bucket, objectPath)
+	if err != nil {
+		return err
+	}
+	manifest := &IntegrityManifest{
+		Version: IntegrityManifestVersion,
+		Objects: make([]IntegrityObject, 0),
+	}
+	for _, name := range names {
+		if digest, ok := digests[filepath.Join(objectPath, name)]; ok {
+			digest.Name = name
+			manifest.Objects = append(manifest.Objects, digest)
+			continue
+		}
+		reader, err := bucket.NewReader(ctx, filepath.Join(objectPath, name), nil)
+		if err != nil {
+			return fmt.Errorf("error reading %v for integrity manifest: %v", name, err)
+		}
+		hasher := sha256.New()
+		size, err := io.Copy(hasher, reader)
+		closeErr := reader.Close()
+		if err != nil {
+			return fmt.Errorf("error reading %v for integrity manifest: %v", name, err)
+		}
+		if closeErr != nil {
+			return closeErr
+		}
+		manifest.Objects = append(manifest.Objects, IntegrityObject{
+			Name:   name,
+			Size:   size,
+			SHA256: hex.EncodeToString(hasher.Sum(nil)),
+		})
+	}
+
+	manifestKey := filepath.Join(objectPath, IntegrityManifestObjectName)
+	passphrase, err := getPassphrase(ctx, bucket, backupLocation, manifestKey, false)
+	if err != nil {
+		return err
+	}
+	if passphrase != "" {
+		if manifest.Signature, err = signIntegrityManifest(manifest, passphrase); err != nil {
+			return err
+		}
+	}
+	data, err := json.MarshalIndent(manifest, "", " ")
+	if err != nil {
+		return err
+	}
+	if err := bucket.WriteAll(ctx, manifestKey, data, nil); err != nil {
+		return fmt.Errorf("error uploading integrity manifest: %v", err)
+	}
+	return nil
+}
+
+// GetIntegrityManifest returns the integrity manifest for the objects in the
+// given path after checking its signature. nil is returned if the backup was
+// uploaded without one.
+func GetIntegrityManifest(
+	ctx context.Context,
+	bucket *blob.Bucket,
+	backupLocation *stork_api.BackupLocation,
+	objectPath string,
+) (*IntegrityManifest, error) {
+	manifestKey := filepath.Join(objectPath, IntegrityManifestObjectName)
+	data, err := bucket.ReadAll(ctx, manifestKey)
+	if err != nil {
+		if gcerrors.Code(err) == gcerrors.NotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	manifest := &IntegrityManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("error parsing integrity manifest: %v", err)
+	}
+	if manifest.Version > IntegrityManifestVersion {
+		return nil, fmt.Errorf("unsupported integrity manifest version %v", manifest.Version)
+	}
+	passphrase, err := getPassphrase(ctx, bucket, backupLocation, manifestKey, false)
+	if err != nil {
+		return nil, err
+	}
+	if passphrase == "" {
+		return manifest, nil
+	}
+	// Don't allow the signature to be stripped from a manifest that should
+	// have been signed
+	if manifest.Signature == "" {
+		return nil, fmt.Errorf("integrity manifest is not signed")
+	}
+	signed := *manifest
+	signed.Signature = ""
+	signedData, err := json.Marshal(&signed)
+	if err != nil {
+		return nil, err
+	}
+	if err := crypto.VerifySignature(signedData, manifest.Signature, passphrase); err != nil {
+		return nil, fmt.Errorf("invalid integrity manifest signature: %v", err)
+	}
+	return manifest, nil
+}
+
+// DeleteIntegrityManifest deletes the integrity manifest for the objects in
+// the given path
+func DeleteIntegrityManifest(
+	ctx context.Context,
+	bucket *blob.Bucket,
+	objectPath string,
+) error {
+	err := bucket.Delete(ctx, filepath.Join(objectPath, IntegrityManifestObjectName))
+	if err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+		return err
+	}
+	return nil
+}
+
+// VerifyBackup downloads every object in the given path and checks it against
+// the integrity manifest. Each object is also decrypted, decompressed and
+// parsed so that objects that can't be restored are detected. Objects listed
+// in the manifest that are missing, and objects that aren't in the manifest,
+// fail verification. Backups without a manifest are only decoded and parsed.
+// An error is returned if the manifest itself can't be trusted. Manifests of
+// backup locations without an encryption key aren't signed, so for those only
+// accidental corruption is detected.
+func VerifyBackup(
+	ctx context.Context,
+	bucket *blob.Bucket,
+	backupLocation *stork_api.BackupLocation,
+	objectPath string,
+) ([]ObjectVerification, error) {
+	manifest, err := GetIntegrityManifest(ctx, bucket, backupLocation, objectPath)
+	if err != nil {
+		return nil, err
+	}
+	names, err := listBackupObjects(ctx, bucket, objectPath)
+	if err != nil {
+		return nil, err
+	}
+	expected := make(map[string]IntegrityObject)
+	if manifest != nil {
+		for _, object := range manifest.Objects {
+			expected[object.Name] = object
+		}
+	}
+
+	results := make([]ObjectVerification, 0)
+	for _, name := range names {
+		key := filepath.Join(objectPath, name)
+		result := ObjectVerification{Name: name}
+		var checksum string
+		result.Size, checksum, result.Error = verifyObject(ctx, bucket, backupLocation, key)
+		if manifest != nil && result.Error == nil {
+			object, ok := expected[name]
+			if !ok {
+				result.Error = fmt.Errorf("object is not in the integrity manifest")
+			} else if object.Size != result.Size {
+				result.Error = fmt.Errorf("size mismatch, expected %v found %v", object.Size, result.Size)
+			} else if object.SHA256 != checksum {
+				result.Error = fmt.Errorf("checksum mismatch, expected %v found %v", object.SHA256, checksum)
+			}
+		}
+		delete(expected, name)
+		results = append(results, result)
+	}
+	if manifest != nil {
+		for _, object := range manifest.Objects {
+			if _, ok := expected[object.Name]; !ok {
+				continue
+			}
+			results = append(results, ObjectVerification{
+				Name:  object.Name,
+				Error: fmt.Errorf("object is missing from the backup location"),
+			})
+		}
+	}
+	return results, nil
+}
+
+// verifyObject reads the object with the given key and returns its size and
+// checksum in the backup location. The object is decoded while it is read
+// and JSON objects are parsed.
+func verifyObject(
+	ctx context.Context,
+	bucket *blob.Bucket,
+	backupLocation *stork_api.BackupLocation,
+	key string,
+) (int64, string, error) {
+	passphrase, err := getPassphrase(ctx, bucket, backupLocation, key, false)
+	if err != nil {
+		return 0, "", err
+	}
+	objectReader, err := bucket.NewReader(ctx, key, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	defer objectReader.Close() // nolint: errcheck
+
+	hasher := sha256.New()
+	counter := &countWriter{}
+	rawReader := bufio.NewReader(io.TeeReader(objectReader, io.MultiWriter(hasher, counter)))
+	checksum := func() string {
+		return hex.EncodeToString(hasher.Sum(nil))
+	}
+
+	var reader io.Reader
+	header, err := rawReader.Peek(len(crypto.StreamMagic))
+	if err != nil && err != io.EOF {
+		return counter.size, "", err
+	}
+	if !crypto.IsEncryptedStream(header) && passphrase != "" {
+		// Objects written by older versions were encrypted as one block
+		data, err := ioutil.ReadAll(rawReader)
+		if err != nil {
+			return counter.size, "", err
+		}
+		data, err = decodeObject(ctx, data, passphrase)
+		if err != nil {
+			return counter.size, checksum(), err
+		}
+		reader = bytes.NewReader(data)
+	} else if reader, err = newDecodeReader(ctx, rawReader, passphrase); err != nil {
+		return counter.size, "", err
+	}
+
+	if strings.HasSuffix(key, ".json") {
+		if err := validateJSON(reader); err != nil {
+			return counter.size, "", fmt.Errorf("error parsing object: %v", err)
+		}
+	}
+	if _, err := io.Copy(ioutil.Discard, reader); err != nil {
+		return counter.size, "", fmt.Errorf("error decoding object: %v", err)
+	}
+	// Make sure the whole object was checksummed even if the decoder didn't
+	// need all of it
+	if _, err := io.Copy(ioutil.Discard, rawReader); err != nil {
+		return counter.size, "", err
+	}
+	return counter.size, checksum(), nil
+}
+
+// validateJSON checks that the reader has exactly one JSON value without
+// holding all of it in memory
+func validateJSON(reader io.Reader) error {
+	decoder := json.NewDecoder(reader)
+	depth := 0
+	values := 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if delim, ok := token.(json.Delim); ok {
+			if delim == '[' || delim == '{' {
+				depth++
+				continue
+			}
+			depth--
+		}
+		if depth == 0 {
+			values++
+		}
+	}
+	if values != 1 || depth != 0 {
+		return fmt.Errorf("expected one JSON value, found %v", values)
+	}
+	return nil
+}
+
+// listBackupObjects returns the names of the objects in the given path,
+// relative to the path. The integrity manifest and data keys are skipped.
+func listBackupObjects(
+	ctx context.Context,
+	bucket *blob.Bucket,
+	objectPath string,
+) ([]string, error) {
+	prefix := strings.TrimSuffix(objectPath, "/") + "/"
+	iterator := bucket.List(&blob.ListOptions{
+		Prefix: prefix,
+	})
+	names := make([]string, 0)
+	for {
+		object, err := iterator.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimPrefix(object.Key, prefix)
+		if object.IsDir || name == IntegrityManifestObjectName || filepath.Base(name) == DataKeyObjectName {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func signIntegrityManifest(manifest *IntegrityManifest, passphrase string) (string, error) {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return "", err
+	}
+	return crypto.Sign(data, passphrase)
+}
+
+type countWriter struct {
+	size int64
+}
+
+func (c *countWriter) Write(data []byte) (int, error) {
+	c.size += int64(len(data))
+	return len(data), nil
+}
@@ -28,6 +28,10 @@ type FakeStorkV1alpha1 struct {
 	*testing.Fake
 }
 
+func (c *FakeStorkV1alpha1) ApplicationActivations(namespace string) v1alpha1.ApplicationActivationInterface {
+	return &FakeApplicationActivations{c, namespace}
+}
+
 func (c *FakeStorkV1alpha1) ApplicationBackups(namespace string) v1alpha1.ApplicationBackupInterface {
 	return &FakeApplicationBackups{c, namespace}
 }
@@ -36,6 +40,10 @@ func (c *FakeStorkV1alpha1) ApplicationBackupSchedules(namespace string) v1alpha
 	return &FakeApplicationBackupSchedules{c, namespace}
 }
 
+func (c *FakeStorkV1alpha1) ApplicationBackupVerifications(namespace string) v1alpha1.ApplicationBackupVerificationInterface {
+	return &FakeApplicationBackupVerifications{c, namespace}
+}
+
 func (c *FakeStorkV1alpha1) ApplicationClones(namespace string) v1alpha1.ApplicationCloneInterface {
 	return &FakeApplicationClones{c, namespace}
 }
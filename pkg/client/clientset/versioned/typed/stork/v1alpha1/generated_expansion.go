@@ -18,10 +18,14 @@ limitations under the License.
 
 package v1alpha1
 
+type ApplicationActivationExpansion interface{}
+
 type ApplicationBackupExpansion interface{}
 
 type ApplicationBackupScheduleExpansion interface{}
 
+type ApplicationBackupVerificationExpansion interface{}
+
 type ApplicationCloneExpansion interface{}
 
 type ApplicationRegistrationExpansion interface{}
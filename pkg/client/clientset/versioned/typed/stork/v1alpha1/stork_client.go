@@ -26,8 +26,10 @@ import (
 
 type StorkV1alpha1Interface interface {
 	RESTClient() rest.Interface
+	ApplicationActivationsGetter
 	ApplicationBackupsGetter
 	ApplicationBackupSchedulesGetter
+	ApplicationBackupVerificationsGetter
 	ApplicationClonesGetter
 	ApplicationRegistrationsGetter
 	ApplicationRestoresGetter
@@ -52,6 +54,10 @@ type StorkV1alpha1Client struct {
 	restClient rest.Interface
 }
 
+func (c *StorkV1alpha1Client) ApplicationActivations(namespace string) ApplicationActivationInterface {
+	return newApplicationActivations(c, namespace)
+}
+
 func (c *StorkV1alpha1Client) ApplicationBackups(namespace string) ApplicationBackupInterface {
 	return newApplicationBackups(c, namespace)
 }
@@ -60,6 +66,10 @@ func (c *StorkV1alpha1Client) ApplicationBackupSchedules(namespace string) Appli
 	return newApplicationBackupSchedules(c, namespace)
 }
 
+func (c *StorkV1alpha1Client) ApplicationBackupVerifications(namespace string) ApplicationBackupVerificationInterface {
+	return newApplicationBackupVerifications(c, namespace)
+}
+
 func (c *StorkV1alpha1Client) ApplicationClones(namespace string) ApplicationCloneInterface {
 	return newApplicationClones(c, namespace)
 }
@@ -53,10 +53,14 @@ func (f *genericInformer) Lister() cache.GenericLister {
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
 	// Group=stork.libopenstorage.org, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithResource("applicationactivations"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Stork().V1alpha1().ApplicationActivations().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("applicationbackups"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Stork().V1alpha1().ApplicationBackups().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("applicationbackupschedules"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Stork().V1alpha1().ApplicationBackupSchedules().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("applicationbackupverifications"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Stork().V1alpha1().ApplicationBackupVerifications().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("applicationclones"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Stork().V1alpha1().ApplicationClones().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("applicationregistrations"):
@@ -24,10 +24,14 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// ApplicationActivations returns a ApplicationActivationInformer.
+	ApplicationActivations() ApplicationActivationInformer
 	// ApplicationBackups returns a ApplicationBackupInformer.
 	ApplicationBackups() ApplicationBackupInformer
 	// ApplicationBackupSchedules returns a ApplicationBackupScheduleInformer.
 	ApplicationBackupSchedules() ApplicationBackupScheduleInformer
+	// ApplicationBackupVerifications returns a ApplicationBackupVerificationInformer.
+	ApplicationBackupVerifications() ApplicationBackupVerificationInformer
 	// ApplicationClones returns a ApplicationCloneInformer.
 	ApplicationClones() ApplicationCloneInformer
 	// ApplicationRegistrations returns a ApplicationRegistrationInformer.
@@ -75,6 +79,11 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// ApplicationActivations returns a ApplicationActivationInformer.
+func (v *version) ApplicationActivations() ApplicationActivationInformer {
+	return &applicationActivationInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // ApplicationBackups returns a ApplicationBackupInformer.
 func (v *version) ApplicationBackups() ApplicationBackupInformer {
 	return &applicationBackupInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -85,6 +94,11 @@ func (v *version) ApplicationBackupSchedules() ApplicationBackupScheduleInformer
 	return &applicationBackupScheduleInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// ApplicationBackupVerifications returns a ApplicationBackupVerificationInformer.
+func (v *version) ApplicationBackupVerifications() ApplicationBackupVerificationInformer {
+	return &applicationBackupVerificationInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // ApplicationClones returns a ApplicationCloneInformer.
 func (v *version) ApplicationClones() ApplicationCloneInformer {
 	return &applicationCloneInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -18,6 +18,14 @@ limitations under the License.
 
 package v1alpha1
 
+// ApplicationActivationListerExpansion allows custom methods to be added to
+// ApplicationActivationLister.
+type ApplicationActivationListerExpansion interface{}
+
+// ApplicationActivationNamespaceListerExpansion allows custom methods to be added to
+// ApplicationActivationNamespaceLister.
+type ApplicationActivationNamespaceListerExpansion interface{}
+
 // ApplicationBackupListerExpansion allows custom methods to be added to
 // ApplicationBackupLister.
 type ApplicationBackupListerExpansion interface{}
@@ -34,6 +42,14 @@ type ApplicationBackupScheduleListerExpansion interface{}
 // ApplicationBackupScheduleNamespaceLister.
 type ApplicationBackupScheduleNamespaceListerExpansion interface{}
 
+// ApplicationBackupVerificationListerExpansion allows custom methods to be added to
+// ApplicationBackupVerificationLister.
+type ApplicationBackupVerificationListerExpansion interface{}
+
+// ApplicationBackupVerificationNamespaceListerExpansion allows custom methods to be added to
+// ApplicationBackupVerificationNamespaceLister.
+type ApplicationBackupVerificationNamespaceListerExpansion interface{}
+
 // ApplicationCloneListerExpansion allows custom methods to be added to
 // ApplicationCloneLister.
 type ApplicationCloneListerExpansion interface{}
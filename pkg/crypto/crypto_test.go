@@ -4,8 +4,10 @@
 package crypto
 
 import (
+	"bytes"
 	"crypto/rand"
 	"io"
+	"io/ioutil"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -58,3 +60,106 @@ func TestDecryptInvalidiData(t *testing.T) {
 	require.Error(t, err, "Decrypting data should have failed")
 	require.Nil(t, decryptedData, "Decrypted data should be nil on error")
 }
+
+func TestEncryptDecryptStream(t *testing.T) {
+	passphrase := "testkey"
+	// Cover empty data, a partial segment and multiple segments
+	for _, size := range []int{0, 128, streamSegmentSize, 3*streamSegmentSize + 17} {
+		originalData := make([]byte, size)
+		_, err := io.ReadFull(rand.Reader, originalData)
+		require.NoError(t, err, "Error generating test data")
+
+		var encryptedData bytes.Buffer
+		writer, err := NewEncryptWriter(&encryptedData, passphrase)
+		require.NoError(t, err, "Error creating encryption stream")
+		// Write in small pieces to make sure segments are split correctly
+		for offset := 0; offset < size; offset += 1000 {
+			_, err = writer.Write(originalData[offset:min(offset+1000, size)])
+			require.NoError(t, err, "Error encrypting data")
+		}
+		require.NoError(t, writer.Close(), "Error closing encryption stream")
+		require.True(t, IsEncryptedStream(encryptedData.Bytes()), "Encrypted stream header missing")
+
+		reader, err := NewDecryptReader(&encryptedData, passphrase)
+		require.NoError(t, err, "Error creating decryption stream")
+		decryptedData, err := ioutil.ReadAll(reader)
+		require.NoError(t, err, "Error decrypting data")
+		require.Equal(t, originalData, decryptedData, "Original and descrypted data mismatch")
+	}
+}
+
+func TestDecryptStreamInvalidKey(t *testing.T) {
+	var encryptedData bytes.Buffer
+	writer, err := NewEncryptWriter(&encryptedData, "testkey")
+	require.NoError(t, err, "Error creating encryption stream")
+	_, err = writer.Write([]byte("test data"))
+	require.NoError(t, err, "Error encrypting data")
+	require.NoError(t, writer.Close(), "Error closing encryption stream")
+
+	reader, err := NewDecryptReader(&encryptedData, "invalidKey")
+	require.NoError(t, err, "Error creating decryption stream")
+	_, err = ioutil.ReadAll(reader)
+	require.Error(t, err, "Decrypting data should have failed")
+}
+
+func TestDecryptStreamTruncated(t *testing.T) {
+	passphrase := "testkey"
+	originalData := make([]byte, 2*streamSegmentSize+10)
+	_, err := io.ReadFull(rand.Reader, originalData)
+	require.NoError(t, err, "Error generating test data")
+
+	var encryptedData bytes.Buffer
+	writer, err := NewEncryptWriter(&encryptedData, passphrase)
+	require.NoError(t, err, "Error creating encryption stream")
+	_, err = writer.Write(originalData)
+	require.NoError(t, err, "Error encrypting data")
+	require.NoError(t, writer.Close(), "Error closing encryption stream")
+
+	// Drop the last segment, the stream should not be accepted as complete
+	segmentSize := streamSegmentHeaderSize + streamSegmentSize + 16
+	truncated := encryptedData.Bytes()[:len(StreamMagic)+streamNoncePrefixSize+2*segmentSize]
+	reader, err := NewDecryptReader(bytes.NewReader(truncated), passphrase)
+	require.NoError(t, err, "Error creating decryption stream")
+	_, err = ioutil.ReadAll(reader)
+	require.Equal(t, io.ErrUnexpectedEOF, err, "Decrypting truncated data should have failed")
+}
+
+func TestWrapUnwrapKey(t *testing.T) {
+	dataKey, err := GenerateDataKey()
+	require.NoError(t, err, "Error generating data key")
+	require.Len(t, dataKey, DataKeySize, "Data key size mismatch")
+
+	wrappedKey, err := WrapKey([]byte("testkey"), dataKey)
+	require.NoError(t, err, "Error wrapping data key")
+	require.NotContains(t, string(wrappedKey), string(dataKey), "Wrapped key should not contain the data key")
+
+	unwrappedKey, err := UnwrapKey([]byte("testkey"), wrappedKey)
+	require.NoError(t, err, "Error unwrapping data key")
+	require.Equal(t, dataKey, unwrappedKey, "Original and unwrapped data key mismatch")
+
+	_, err = UnwrapKey([]byte("invalidKey"), wrappedKey)
+	require.Error(t, err, "Unwrapping with an invalid key should have failed")
+	_, err = UnwrapKey([]byte("testkey"), wrappedKey[:10])
+	require.Error(t, err, "Unwrapping a truncated key should have failed")
+	_, err = WrapKey(nil, dataKey)
+	require.Error(t, err, "Wrapping with an empty key should have failed")
+}
+
+func TestSignVerify(t *testing.T) {
+	data := []byte("data to sign")
+	signature, err := Sign(data, "testkey")
+	require.NoError(t, err, "Error signing data")
+	require.NoError(t, VerifySignature(data, signature, "testkey"), "Error verifying signature")
+
+	require.Error(t, VerifySignature(data, signature, "invalidKey"), "Verifying with an invalid key should have failed")
+	require.Error(t, VerifySignature([]byte("modified data"), signature, "testkey"), "Verifying modified data should have failed")
+	_, err = Sign(data, "")
+	require.Error(t, err, "Signing with an empty key should have failed")
+}
+
+func min(x, y int) int {
+	if x < y {
+		return x
+	}
+	return y
+}
@@ -0,0 +1,82 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// DataKeySize is the size of the data keys returned by GenerateDataKey
+	DataKeySize = 32
+	// Key encryption keys are derived from the key material with PBKDF2 and
+	// a random salt that is stored with the wrapped key
+	wrapSaltSize   = 16
+	wrapIterations = 100000
+)
+
+// GenerateDataKey returns a random key to encrypt data with
+func GenerateDataKey() ([]byte, error) {
+	key := make([]byte, DataKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("error generating data key: %v", err)
+	}
+	return key, nil
+}
+
+// WrapKey encrypts the data key with a key encryption key derived from the
+// given key material
+func WrapKey(keyMaterial []byte, dataKey []byte) ([]byte, error) {
+	salt := make([]byte, wrapSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, fmt.Errorf("error generating salt for key wrapping: %v", err)
+	}
+	gcm, err := getWrapCipher(keyMaterial, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("error generating nonce for key wrapping: %v", err)
+	}
+	wrappedKey := append(salt, nonce...)
+	return gcm.Seal(wrappedKey, nonce, dataKey, nil), nil
+}
+
+// UnwrapKey decrypts a data key that was wrapped with WrapKey
+func UnwrapKey(keyMaterial []byte, wrappedKey []byte) ([]byte, error) {
+	if len(wrappedKey) < wrapSaltSize {
+		return nil, fmt.Errorf("wrapped key is too short")
+	}
+	salt, wrappedKey := wrappedKey[:wrapSaltSize], wrappedKey[wrapSaltSize:]
+	gcm, err := getWrapCipher(keyMaterial, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrappedKey) < gcm.NonceSize() {
+		return nil, fmt.Errorf("wrapped key is too short")
+	}
+	nonce, sealedKey := wrappedKey[:gcm.NonceSize()], wrappedKey[gcm.NonceSize():]
+	dataKey, err := gcm.Open(nil, nonce, sealedKey, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error unwrapping data key: %v", err)
+	}
+	return dataKey, nil
+}
+
+func getWrapCipher(keyMaterial []byte, salt []byte) (cipher.AEAD, error) {
+	if len(keyMaterial) == 0 {
+		return nil, fmt.Errorf("key encryption key is empty")
+	}
+	key := pbkdf2.Key(keyMaterial, salt, wrapIterations, 32, sha256.New)
+	c, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(c)
+}
@@ -0,0 +1,44 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Signing keys are derived from the passphrase so that a signature can't be
+// confused with anything else computed from the same passphrase
+const signKeyContext = "stork-signature-v1"
+
+// Sign returns a hex encoded HMAC-SHA256 of the data keyed with the
+// passphrase
+func Sign(data []byte, passphrase string) (string, error) {
+	if passphrase == "" {
+		return "", fmt.Errorf("passphrase is required for signing")
+	}
+	mac := hmac.New(sha256.New, getSignKey(passphrase))
+	if _, err := mac.Write(data); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// VerifySignature checks that the signature was returned by Sign for the data
+// and passphrase
+func VerifySignature(data []byte, signature string, passphrase string) error {
+	expected, err := Sign(data, passphrase)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+func getSignKey(passphrase string) []byte {
+	mac := hmac.New(sha256.New, []byte(passphrase))
+	mac.Write([]byte(signKeyContext)) // nolint: errcheck
+	return mac.Sum(nil)
+}
@@ -0,0 +1,199 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	// StreamMagic is written at the start of every stream produced by
+	// NewEncryptWriter so that readers can tell it apart from data
+	// encrypted with Encrypt
+	StreamMagic = "STRKENC1"
+	// streamSegmentSize is the maximum amount of plaintext sealed in one
+	// segment. Only one segment is held in memory at a time.
+	streamSegmentSize = 64 * 1024
+	// The nonce for each segment is made up of a random prefix, a segment
+	// counter and a flag marking the last segment of the stream
+	streamNoncePrefixSize = 7
+	streamCounterSize     = 4
+	streamLastSegmentFlag = byte(1)
+	// Each segment is preceded by a header with the flag and the length of
+	// the sealed segment
+	streamSegmentHeaderSize = 5
+)
+
+type encryptWriter struct {
+	writer      io.Writer
+	gcm         cipher.AEAD
+	noncePrefix []byte
+	counter     uint32
+	buffer      []byte
+	closed      bool
+}
+
+type decryptReader struct {
+	reader      io.Reader
+	gcm         cipher.AEAD
+	noncePrefix []byte
+	counter     uint32
+	plaintext   []byte
+	done        bool
+}
+
+// NewEncryptWriter returns a writer that encrypts the data written to it
+// with the passphrase and writes it to the given writer in fixed size
+// segments. Close must be called to write the final segment; it does not
+// close the underlying writer.
+func NewEncryptWriter(writer io.Writer, passphrase string) (io.WriteCloser, error) {
+	gcm, err := getCipher(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	noncePrefix := make([]byte, streamNoncePrefixSize)
+	if _, err = io.ReadFull(rand.Reader, noncePrefix); err != nil {
+		return nil, fmt.Errorf("error generating nonce for encryption: %v", err)
+	}
+	if _, err := writer.Write(append([]byte(StreamMagic), noncePrefix...)); err != nil {
+		return nil, err
+	}
+	return &encryptWriter{
+		writer:      writer,
+		gcm:         gcm,
+		noncePrefix: noncePrefix,
+		buffer:      make([]byte, 0, streamSegmentSize),
+	}, nil
+}
+
+// NewDecryptReader returns a reader that decrypts data written by
+// NewEncryptWriter. An error is returned from Read if the stream has been
+// tampered with or truncated.
+func NewDecryptReader(reader io.Reader, passphrase string) (io.Reader, error) {
+	gcm, err := getCipher(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, len(StreamMagic)+streamNoncePrefixSize)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, fmt.Errorf("error reading encryption header: %v", err)
+	}
+	if !IsEncryptedStream(header) {
+		return nil, fmt.Errorf("invalid encryption header")
+	}
+	return &decryptReader{
+		reader:      reader,
+		gcm:         gcm,
+		noncePrefix: header[len(StreamMagic):],
+	}, nil
+}
+
+// IsEncryptedStream returns true if the data starts with the header written
+// by NewEncryptWriter
+func IsEncryptedStream(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(StreamMagic))
+}
+
+func (e *encryptWriter) Write(data []byte) (int, error) {
+	if e.closed {
+		return 0, fmt.Errorf("write to closed encryption stream")
+	}
+	written := 0
+	for len(data) > 0 {
+		n := copy(e.buffer[len(e.buffer):cap(e.buffer)], data)
+		e.buffer = e.buffer[:len(e.buffer)+n]
+		data = data[n:]
+		written += n
+		// Only seal full segments here, the last one is sealed on Close
+		if len(e.buffer) == cap(e.buffer) && len(data) > 0 {
+			if err := e.writeSegment(false); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+func (e *encryptWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	return e.writeSegment(true)
+}
+
+func (e *encryptWriter) writeSegment(last bool) error {
+	flag := byte(0)
+	if last {
+		flag = streamLastSegmentFlag
+	}
+	sealed := e.gcm.Seal(nil, streamNonce(e.noncePrefix, e.counter, flag), e.buffer, nil)
+	header := make([]byte, streamSegmentHeaderSize)
+	header[0] = flag
+	binary.BigEndian.PutUint32(header[1:], uint32(len(sealed)))
+	if _, err := e.writer.Write(header); err != nil {
+		return err
+	}
+	if _, err := e.writer.Write(sealed); err != nil {
+		return err
+	}
+	e.counter++
+	e.buffer = e.buffer[:0]
+	return nil
+}
+
+func (d *decryptReader) Read(data []byte) (int, error) {
+	for len(d.plaintext) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.readSegment(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(data, d.plaintext)
+	d.plaintext = d.plaintext[n:]
+	return n, nil
+}
+
+func (d *decryptReader) readSegment() error {
+	header := make([]byte, streamSegmentHeaderSize)
+	if _, err := io.ReadFull(d.reader, header); err != nil {
+		if err == io.EOF {
+			// The stream ended without the last segment
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	flag := header[0]
+	length := binary.BigEndian.Uint32(header[1:])
+	if length > streamSegmentSize+uint32(d.gcm.Overhead()) {
+		return fmt.Errorf("invalid encrypted segment length %v", length)
+	}
+	sealed := make([]byte, length)
+	if _, err := io.ReadFull(d.reader, sealed); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	plaintext, err := d.gcm.Open(nil, streamNonce(d.noncePrefix, d.counter, flag), sealed, nil)
+	if err != nil {
+		return err
+	}
+	d.counter++
+	d.plaintext = plaintext
+	d.done = flag == streamLastSegmentFlag
+	return nil
+}
+
+func streamNonce(prefix []byte, counter uint32, flag byte) []byte {
+	nonce := make([]byte, streamNoncePrefixSize+streamCounterSize+1)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[streamNoncePrefixSize:], counter)
+	nonce[len(nonce)-1] = flag
+	return nonce
+}
@@ -0,0 +1,231 @@
+package extender
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	storklog "github.com/libopenstorage/stork/pkg/log"
+	"github.com/portworx/sched-ops/k8s/core"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	explain = "explain"
+	// ExplainPath is the path of the endpoint on the extender that explains
+	// how a pod would be scheduled. A GET request explains the pod with the
+	// name and namespace in the pod and namespace query parameters. A POST
+	// request explains the pod spec in the body.
+	ExplainPath = "/" + explain
+)
+
+// LocalityMatch is how close a node is to the data for a volume
+type LocalityMatch string
+
+const (
+	// LocalityMatchNode means the node has data for the volume
+	LocalityMatchNode LocalityMatch = "node"
+	// LocalityMatchRack means the node is in the same rack as a node with
+	// data for the volume
+	LocalityMatchRack LocalityMatch = "rack"
+	// LocalityMatchZone means the node is in the same zone as a node with
+	// data for the volume
+	LocalityMatchZone LocalityMatch = "zone"
+	// LocalityMatchRegion means the node is in the same region as a node
+	// with data for the volume
+	LocalityMatchRegion LocalityMatch = "region"
+	// LocalityMatchNone means the node isn't close to the data for the volume
+	LocalityMatchNone LocalityMatch = "none"
+)
+
+// SchedulingExplanation is the result of running the filter and prioritize
+// logic of the extender for a pod. Only the extender's verdicts are included,
+// the scheduler can still filter out nodes or change the scores based on its
+// own predicates and priorities.
+type SchedulingExplanation struct {
+	Pod       string `json:"pod"`
+	Namespace string `json:"namespace"`
+	// ScoringPolicy is the name of the scoring policy used for the pod
+	ScoringPolicy string `json:"scoringPolicy,omitempty"`
+	// FilterError is set if the filter request for the pod would fail. The
+	// pod can't be scheduled on any of the nodes in that case.
+	FilterError string `json:"filterError,omitempty"`
+	// PrioritizeError is set if the prioritize request for the pod would
+	// fail
+	PrioritizeError string `json:"prioritizeError,omitempty"`
+	// Messages are the events that would have been raised for the pod and
+	// other notes about how it was scored
+	Messages []string           `json:"messages,omitempty"`
+	Nodes    []*NodeExplanation `json:"nodes"`
+}
+
+// NodeExplanation is the verdict for one node
+type NodeExplanation struct {
+	Name string `json:"name"`
+	// FilterReason is the reason the node was filtered out. It is empty if
+	// the pod can be scheduled on the node.
+	FilterReason string `json:"filterReason,omitempty"`
+	// Volumes are the scores of the node for each of the pod's volumes
+	Volumes []*VolumeExplanation `json:"volumes,omitempty"`
+	// UtilizationFactor is the fraction of the score the node kept based on
+	// its storage capacity and IO load
+	UtilizationFactor float64 `json:"utilizationFactor"`
+	// DefaultScore is true if the node was given the default score because
+	// it isn't close to the data for any of the volumes
+	DefaultScore bool `json:"defaultScore,omitempty"`
+	// Score is the final score of the node. It is only set for nodes that
+	// weren't filtered out.
+	Score int64 `json:"score"`
+}
+
+// VolumeExplanation is the score of a node for one volume
+type VolumeExplanation struct {
+	Volume   string        `json:"volume"`
+	Locality LocalityMatch `json:"locality"`
+	// DegradedPenalty is how much the score was reduced because the storage
+	// driver on the node is degraded
+	DegradedPenalty float64 `json:"degradedPenalty,omitempty"`
+	Score           float64 `json:"score"`
+}
+
+func newSchedulingExplanation(pod *v1.Pod, nodes []v1.Node) *SchedulingExplanation {
+	explanation := &SchedulingExplanation{
+		Pod:       pod.Name,
+		Namespace: pod.Namespace,
+		Nodes:     make([]*NodeExplanation, 0),
+	}
+	for _, node := range nodes {
+		explanation.Nodes = append(explanation.Nodes, &NodeExplanation{
+			Name:              node.Name,
+			UtilizationFactor: 1,
+		})
+	}
+	return explanation
+}
+
+// The methods below can be called on a nil explanation so that the filter
+// and prioritize logic doesn't need to check if it is being explained
+
+func (s *SchedulingExplanation) getNode(name string) *NodeExplanation {
+	if s == nil {
+		return nil
+	}
+	for _, node := range s.Nodes {
+		if node.Name == name {
+			return node
+		}
+	}
+	return nil
+}
+
+func (s *SchedulingExplanation) addMessage(msg string) {
+	if s == nil {
+		return
+	}
+	s.Messages = append(s.Messages, msg)
+}
+
+func (s *SchedulingExplanation) setFilterReason(nodeName string, reason string) {
+	if node := s.getNode(nodeName); node != nil {
+		node.FilterReason = reason
+	}
+}
+
+func (s *SchedulingExplanation) addVolumeScore(nodeName string, volumeName string, score nodeScore) {
+	if node := s.getNode(nodeName); node != nil {
+		node.Volumes = append(node.Volumes, &VolumeExplanation{
+			Volume:          volumeName,
+			Locality:        score.locality,
+			DegradedPenalty: score.degradedPenalty,
+			Score:           score.score,
+		})
+	}
+}
+
+func (s *SchedulingExplanation) setUtilizationFactor(nodeName string, factor float64) {
+	if node := s.getNode(nodeName); node != nil {
+		node.UtilizationFactor = factor
+	}
+}
+
+func (s *SchedulingExplanation) setDefaultScore(nodeName string) {
+	if node := s.getNode(nodeName); node != nil {
+		node.DefaultScore = true
+	}
+}
+
+// explain runs the filter and prioritize logic for the pod against the given
+// nodes without raising events or updating metrics
+func (e *Extender) explain(pod *v1.Pod, nodes []v1.Node) *SchedulingExplanation {
+	explanation := newSchedulingExplanation(pod, nodes)
+	filteredNodes, err := e.filterNodes(pod, nodes, explanation)
+	if err != nil {
+		explanation.FilterError = err.Error()
+		return explanation
+	}
+
+	policyName, policy := e.getScoringPolicy(pod)
+	explanation.ScoringPolicy = policyName
+	priorities, err := e.prioritizeNodes(pod, filteredNodes, policy, explanation)
+	if err != nil {
+		explanation.PrioritizeError = err.Error()
+		return explanation
+	}
+	for _, priority := range priorities {
+		if node := explanation.getNode(priority.Host); node != nil {
+			node.Score = priority.Score
+		}
+	}
+	return explanation
+}
+
+func (e *Extender) processExplainRequest(w http.ResponseWriter, req *http.Request) {
+	var pod *v1.Pod
+	if req.Method == http.MethodPost {
+		decoder := json.NewDecoder(req.Body)
+		defer func() {
+			if err := req.Body.Close(); err != nil {
+				log.Warnf("Error closing decoder")
+			}
+		}()
+		pod = &v1.Pod{}
+		if err := decoder.Decode(pod); err != nil {
+			log.Errorf("Error decoding explain request: %v", err)
+			http.Error(w, "Decode error", http.StatusBadRequest)
+			return
+		}
+		if pod.Namespace == "" {
+			pod.Namespace = metav1.NamespaceDefault
+		}
+	} else {
+		name := req.URL.Query().Get("pod")
+		namespace := req.URL.Query().Get("namespace")
+		if name == "" {
+			http.Error(w, "Pod name is required", http.StatusBadRequest)
+			return
+		}
+		if namespace == "" {
+			namespace = metav1.NamespaceDefault
+		}
+		var err error
+		if pod, err = core.Instance().GetPodByName(name, namespace); err != nil {
+			http.Error(w, fmt.Sprintf("Error getting pod %v/%v: %v", namespace, name, err), http.StatusNotFound)
+			return
+		}
+	}
+
+	nodes, err := core.Instance().GetNodes()
+	if err != nil {
+		storklog.PodLog(pod).Errorf("Error getting nodes to explain scheduling: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	explanation := e.explain(pod, nodes.Items)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(explanation); err != nil {
+		storklog.PodLog(pod).Errorf("Failed to encode explain response: %v", err)
+	}
+}
@@ -26,6 +26,8 @@ import (
 const (
 	filter     = "filter"
 	prioritize = "prioritize"
+	// The scores below are used by the default scoring policy unless they are
+	// overridden in the scoring policy ConfigMap
 	// nodePriorityScore Score by which each node is bumped if it has data for a volume
 	nodePriorityScore float64 = 100
 	// rackPriorityScore Score by which each node is bumped if it is in the same
@@ -42,7 +44,15 @@ const (
 	// degradedNodeScorePenaltyPercentage is the percentage by which a node's score
 	// will take a hit if the node's status is degraded
 	degradedNodeScorePenaltyPercentage float64 = 50
-	schedulingFailureEventReason               = "FailedScheduling"
+	// capacityScorePenaltyPercentage is the percentage by which a node's score
+	// will take a hit if its storage is full. The penalty is scaled by how much
+	// of the storage is used.
+	capacityScorePenaltyPercentage float64 = 20
+	// ioLoadScorePenaltyPercentage is the percentage by which a node's score
+	// will take a hit if its storage is fully loaded. The penalty is scaled by
+	// the IO load of the node.
+	ioLoadScorePenaltyPercentage float64 = 30
+	schedulingFailureEventReason         = "FailedScheduling"
 	// annotation to check if only local nodes should be used to schedule a pod
 	preferLocalNodeOnlyAnnotation = "stork.libopenstorage.org/preferLocalNodeOnly"
 	// annotation to skip a volume and its local node replicas for scoring while
@@ -71,15 +81,25 @@ var (
 		Name: "stork_semi_hyperconverged_pods_total",
 		Help: "The total number of pods that are partially hyper-converged by stork scheduler",
 	}, []string{"pod", "namespace"})
+	// ScoringPolicyRequestsCounter for prioritize requests scored with each
+	// scoring policy
+	ScoringPolicyRequestsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "stork_scoring_policy_requests_total",
+		Help: "The total number of prioritize requests scored with a scoring policy",
+	}, []string{"policy"})
 )
 
 // Extender Scheduler extender
 type Extender struct {
 	Recorder record.EventRecorder
 	Driver   volume.Driver
-	server   *http.Server
-	lock     sync.Mutex
-	started  bool
+	// ScoringPolicyNamespace is the namespace of the ConfigMap with the
+	// scoring policies. Defaults to kube-system.
+	ScoringPolicyNamespace string
+	server                 *http.Server
+	lock                   sync.Mutex
+	started                bool
+	scoringPolicies        *scoringPolicies
 }
 
 // Start Starts the extender
@@ -102,6 +122,10 @@ func (e *Extender) Start() error {
 	prometheus.MustRegister(HyperConvergedPodsCounter)
 	prometheus.MustRegister(NonHyperConvergePodsCounter)
 	prometheus.MustRegister(SemiHyperConvergePodsCounter)
+	prometheus.MustRegister(ScoringPolicyRequestsCounter)
+	if err := e.watchScoringPolicies(); err != nil {
+		return err
+	}
 	if err := e.collectExtenderMetrics(); err != nil {
 		return err
 	}
@@ -130,7 +154,11 @@ func (e *Extender) Stop() error {
 }
 
 func (e *Extender) serveHTTP(w http.ResponseWriter, req *http.Request) {
-	if strings.Contains(req.URL.Path, filter) {
+	if strings.Contains(req.URL.Path, explain) {
+		e.processExplainRequest(w, req)
+	} else if strings.Contains(req.URL.Path, testRule) {
+		e.processTestRuleRequest(w, req)
+	} else if strings.Contains(req.URL.Path, filter) {
 		e.processFilterRequest(w, req)
 	} else if strings.Contains(req.URL.Path, prioritize) {
 		e.processPrioritizeRequest(w, req)
@@ -171,6 +199,52 @@ func (e *Extender) processFilterRequest(w http.ResponseWriter, req *http.Request
 		http.Error(w, msg, http.StatusBadRequest)
 		return
 	}
+
+	storklog.PodLog(pod).Debugf("Nodes in filter request:")
+	for _, node := range args.Nodes.Items {
+		storklog.PodLog(pod).Debugf("%v %+v", node.Name, node.Status.Addresses)
+	}
+
+	filteredNodes, err := e.filterNodes(pod, args.Nodes.Items, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	storklog.PodLog(pod).Debugf("Nodes in filter response:")
+	for _, node := range filteredNodes {
+		log.Debugf("%v %+v", node.Name, node.Status.Addresses)
+	}
+	response := &schedulerapi.ExtenderFilterResult{
+		Nodes: &v1.NodeList{
+			Items: filteredNodes,
+		},
+	}
+	if err := encoder.Encode(response); err != nil {
+		storklog.PodLog(pod).Errorf("Error encoding filter response: %+v : %v", response, err)
+	}
+}
+
+// recordSchedulingFailure raises an event for the pod with the reason it
+// can't be scheduled. When explaining the scheduling decision for a pod the
+// message is added to the explanation instead.
+func (e *Extender) recordSchedulingFailure(pod *v1.Pod, msg string, explanation *SchedulingExplanation) {
+	if explanation != nil {
+		explanation.addMessage(msg)
+		return
+	}
+	e.Recorder.Event(pod, v1.EventTypeWarning, schedulingFailureEventReason, msg)
+}
+
+// filterNodes returns the nodes on which the pod can be scheduled. An error is
+// returned if the pod can't be scheduled on any of the nodes. If explanation
+// isn't nil the reason each node was filtered out is recorded in it and no
+// events are raised.
+func (e *Extender) filterNodes(
+	pod *v1.Pod,
+	nodes []v1.Node,
+	explanation *SchedulingExplanation,
+) ([]v1.Node, error) {
 	for _, vol := range pod.Spec.Volumes {
 		// if any of pvc has restore annotation skip scheduling pod
 		if vol.PersistentVolumeClaim == nil {
@@ -180,33 +254,25 @@ func (e *Extender) processFilterRequest(w http.ResponseWriter, req *http.Request
 		if err != nil {
 			msg := fmt.Sprintf("Unable to find PVC %s, err: %v", vol.Name, err)
 			storklog.PodLog(pod).Warnf(msg)
-			e.Recorder.Event(pod, v1.EventTypeWarning, schedulingFailureEventReason, msg)
-			http.Error(w, msg, http.StatusBadRequest)
-			return
+			e.recordSchedulingFailure(pod, msg, explanation)
+			return nil, fmt.Errorf("%v", msg)
 		} else if pvc.Annotations != nil && pvc.Annotations[restore.RestoreAnnotation] == "true" {
 			msg := "Volume restore is in progress for pvc: " + pvc.Name
 			storklog.PodLog(pod).Warnf(msg)
-			e.Recorder.Event(pod, v1.EventTypeWarning, schedulingFailureEventReason, msg)
-			http.Error(w, msg, http.StatusBadRequest)
-			return
+			e.recordSchedulingFailure(pod, msg, explanation)
+			return nil, fmt.Errorf("%v", msg)
 		}
 	}
 
-	storklog.PodLog(pod).Debugf("Nodes in filter request:")
-	for _, node := range args.Nodes.Items {
-		storklog.PodLog(pod).Debugf("%v %+v", node.Name, node.Status.Addresses)
-	}
-
 	filteredNodes := []v1.Node{}
 	driverVolumes, WFFCVolumes, err := e.Driver.GetPodVolumes(&pod.Spec, pod.Namespace, true)
 
 	if err != nil {
 		msg := fmt.Sprintf("Error getting volumes for Pod for driver: %v", err)
 		storklog.PodLog(pod).Warnf(msg)
-		e.Recorder.Event(pod, v1.EventTypeWarning, schedulingFailureEventReason, msg)
+		e.recordSchedulingFailure(pod, msg, explanation)
 		if _, ok := err.(*volume.ErrPVCPending); ok {
-			http.Error(w, "Waiting for PVC to be bound", http.StatusBadRequest)
-			return
+			return nil, fmt.Errorf("Waiting for PVC to be bound")
 		}
 		// Do driver check even if we only have pending WaitForFirstConsumer volumes
 	} else if len(driverVolumes) > 0 || len(WFFCVolumes) > 0 {
@@ -228,9 +294,8 @@ func (e *Extender) processFilterRequest(w http.ResponseWriter, req *http.Request
 					// those nodes are online
 					storklog.PodLog(pod).Errorf("No online storage nodes have replica for volume, returning error")
 					msg := "No online node found with volume replica"
-					e.Recorder.Event(pod, v1.EventTypeWarning, schedulingFailureEventReason, msg)
-					http.Error(w, msg, http.StatusBadRequest)
-					return
+					e.recordSchedulingFailure(pod, msg, explanation)
+					return nil, fmt.Errorf("%v", msg)
 				}
 			}
 
@@ -253,21 +318,33 @@ func (e *Extender) processFilterRequest(w http.ResponseWriter, req *http.Request
 				}
 			}
 
-			for _, node := range args.Nodes.Items {
+			for _, node := range nodes {
+				reason := "Storage driver is not running on the node"
 				for _, driverNode := range driverNodes {
 					storklog.PodLog(pod).Debugf("nodeInfo: %v", driverNode)
-					if (driverNode.Status == volume.NodeOnline || driverNode.Status == volume.NodeDegraded) &&
-						volume.IsNodeMatch(&node, driverNode) {
-						// If only nodes with replicas are to be preferred,
-						// filter out all nodes that don't have a replica
-						// for all the volumes
-						if preferLocalOnly && nodeVolumeCounts[driverNode.StorageID] != len(driverVolumes) {
-							continue
-						}
-						filteredNodes = append(filteredNodes, node)
-						break
+					if !volume.IsNodeMatch(&node, driverNode) {
+						continue
+					}
+					if driverNode.Status != volume.NodeOnline && driverNode.Status != volume.NodeDegraded {
+						reason = fmt.Sprintf("Storage driver is %v on the node", driverNode.Status)
+						continue
+					}
+					// If only nodes with replicas are to be preferred,
+					// filter out all nodes that don't have a replica
+					// for all the volumes
+					if preferLocalOnly && nodeVolumeCounts[driverNode.StorageID] != len(driverVolumes) {
+						reason = "Node doesn't have replicas for all the volumes"
+						continue
 					}
+					if !isAccessibleFromNode(driverVolumes, driverNode.StorageID) {
+						reason = "Volumes can't be accessed from the node"
+						continue
+					}
+					filteredNodes = append(filteredNodes, node)
+					reason = ""
+					break
 				}
+				explanation.setFilterReason(node.Name, reason)
 			}
 
 			// If we filtered out all the nodes, the driver isn't running on any
@@ -281,30 +358,38 @@ func (e *Extender) processFilterRequest(w http.ResponseWriter, req *http.Request
 					msg = "No node found with storage driver"
 				}
 				storklog.PodLog(pod).Error(msg)
-				e.Recorder.Event(pod, v1.EventTypeWarning, schedulingFailureEventReason, msg)
-				http.Error(w, msg, http.StatusBadRequest)
-				return
+				e.recordSchedulingFailure(pod, msg, explanation)
+				return nil, fmt.Errorf("%v", msg)
 			}
 		}
 	}
 
 	// If we didn't find a PVC that interested us, return all the nodes from the request
 	if len(filteredNodes) == 0 {
-		filteredNodes = args.Nodes.Items
+		filteredNodes = nodes
 	}
+	return filteredNodes, nil
+}
 
-	storklog.PodLog(pod).Debugf("Nodes in filter response:")
-	for _, node := range filteredNodes {
-		log.Debugf("%v %+v", node.Name, node.Status.Addresses)
-	}
-	response := &schedulerapi.ExtenderFilterResult{
-		Nodes: &v1.NodeList{
-			Items: filteredNodes,
-		},
-	}
-	if err := encoder.Encode(response); err != nil {
-		storklog.PodLog(pod).Errorf("Error encoding filter response: %+v : %v", response, err)
+// isAccessibleFromNode returns true if all the volumes can be accessed from
+// the driver node
+func isAccessibleFromNode(volumes []*volume.Info, storageID string) bool {
+	for _, volumeInfo := range volumes {
+		if volumeInfo.AccessibleNodes == nil {
+			continue
+		}
+		accessible := false
+		for _, node := range volumeInfo.AccessibleNodes {
+			if node == storageID {
+				accessible = true
+				break
+			}
+		}
+		if !accessible {
+			return false
+		}
 	}
+	return true
 }
 
 func (e *Extender) collectExtenderMetrics() error {
@@ -392,6 +477,16 @@ func (e *Extender) collectExtenderMetrics() error {
 	return nil
 }
 
+// nodeScore is the score of a node for one volume
+type nodeScore struct {
+	score float64
+	// locality is how close the node is to the data for the volume
+	locality LocalityMatch
+	// degradedPenalty is how much the score was reduced because the storage
+	// driver on the node is degraded
+	degradedPenalty float64
+}
+
 func (e *Extender) getNodeScore(
 	node v1.Node,
 	volumeInfo *volume.Info,
@@ -399,7 +494,8 @@ func (e *Extender) getNodeScore(
 	zoneInfo *localityInfo,
 	regionInfo *localityInfo,
 	storageNode *volume.NodeInfo,
-) float64 {
+	policy *ScoringPolicy,
+) nodeScore {
 	for _, address := range node.Status.Addresses {
 		if address.Type != v1.NodeHostName {
 			continue
@@ -420,39 +516,31 @@ func (e *Extender) getNodeScore(
 											// Even if the volume data is local to the node
 											// the node is in degraded state. So the app won't benefit
 											// from hyperconvergence on this node. So we will not use
-											// the nodePriorityScore but instead rackPriorityScore and
+											// the NodePriorityScore but instead RackPriorityScore and
 											// penalize based on that.
-											return rackPriorityScore * (degradedNodeScorePenaltyPercentage / 100)
+											score := policy.degradedScore(policy.RackPriorityScore)
+											return nodeScore{score, LocalityMatchNode, policy.NodePriorityScore - score}
 										}
-										return nodePriorityScore
+										return nodeScore{policy.NodePriorityScore, LocalityMatchNode, 0}
 									}
 								}
 								if nodeRack != "" {
-									if storageNode.Status == volume.NodeDegraded {
-										return rackPriorityScore * (degradedNodeScorePenaltyPercentage / 100)
-									}
-									return rackPriorityScore
+									return policy.localityScore(LocalityMatchRack, policy.RackPriorityScore, storageNode)
 								}
 							}
 						}
 						if nodeZone != "" {
-							if storageNode.Status == volume.NodeDegraded {
-								return zonePriorityScore * (degradedNodeScorePenaltyPercentage / 100)
-							}
-							return zonePriorityScore
+							return policy.localityScore(LocalityMatchZone, policy.ZonePriorityScore, storageNode)
 						}
 					}
 				}
 				if nodeRegion != "" {
-					if storageNode.Status == volume.NodeDegraded {
-						return regionPriorityScore * (degradedNodeScorePenaltyPercentage / 100)
-					}
-					return regionPriorityScore
+					return policy.localityScore(LocalityMatchRegion, policy.RegionPriorityScore, storageNode)
 				}
 			}
 		}
 	}
-	return 0
+	return nodeScore{0, LocalityMatchNone, 0}
 }
 
 type localityInfo struct {
@@ -477,15 +565,44 @@ func (e *Extender) processPrioritizeRequest(w http.ResponseWriter, req *http.Req
 	}
 
 	pod := args.Pod
+	policyName, policy := e.getScoringPolicy(pod)
+	storklog.PodLog(pod).Debugf("Using scoring policy %v: %+v", policyName, *policy)
+	ScoringPolicyRequestsCounter.With(prometheus.Labels{"policy": policyName}).Inc()
 	storklog.PodLog(pod).Debugf("Nodes in prioritize request:")
 	for _, node := range args.Nodes.Items {
 		storklog.PodLog(pod).Debugf("%+v", node.Status.Addresses)
 	}
+
+	respList, err := e.prioritizeNodes(pod, args.Nodes.Items, policy, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	storklog.PodLog(pod).Debugf("Nodes in response:")
+	for _, node := range respList {
+		storklog.PodLog(pod).Debugf("%+v", node)
+	}
+
+	if err := encoder.Encode(respList); err != nil {
+		storklog.PodLog(pod).Errorf("Failed to encode response: %v", err)
+	}
+}
+
+// prioritizeNodes returns the score for each node based on how close it is
+// to the data for the pod's volumes. If explanation isn't nil the score for
+// each volume is recorded in it and no events are raised.
+func (e *Extender) prioritizeNodes(
+	pod *v1.Pod,
+	nodes []v1.Node,
+	policy *ScoringPolicy,
+	explanation *SchedulingExplanation,
+) (schedulerapi.HostPriorityList, error) {
 	respList := schedulerapi.HostPriorityList{}
 
 	// Intialize scores to 0
 	priorityMap := make(map[string]int)
-	for _, node := range args.Nodes.Items {
+	for _, node := range nodes {
 		for _, address := range node.Status.Addresses {
 			if address.Type == v1.NodeHostName {
 				priorityMap[address.Address] = 0
@@ -493,6 +610,34 @@ func (e *Extender) processPrioritizeRequest(w http.ResponseWriter, req *http.Req
 		}
 	}
 
+	if err := e.scoreNodes(pod, nodes, policy, priorityMap, explanation); err != nil {
+		return nil, err
+	}
+
+	// For any nodes that didn't have any volumes, assign it a
+	// default score so that it doesn't get completely ignored
+	// by the scheduler
+	for _, node := range nodes {
+		score, ok := priorityMap[node.Name]
+		if !ok || score == 0 {
+			score = int(policy.DefaultScore)
+			explanation.setDefaultScore(node.Name)
+		}
+		hostPriority := schedulerapi.HostPriority{Host: node.Name, Score: int64(score)}
+		respList = append(respList, hostPriority)
+	}
+	return respList, nil
+}
+
+// scoreNodes adds the scores for the pod's volumes to priorityMap. An error
+// is only returned if the scheduler should retry the request.
+func (e *Extender) scoreNodes(
+	pod *v1.Pod,
+	nodes []v1.Node,
+	policy *ScoringPolicy,
+	priorityMap map[string]int,
+	explanation *SchedulingExplanation,
+) error {
 	// Score all nodes the same if hyperconvergence is disabled
 	disableHyperconvergence := false
 	var err error
@@ -504,131 +649,144 @@ func (e *Extender) processPrioritizeRequest(w http.ResponseWriter, req *http.Req
 		}
 	}
 	if disableHyperconvergence {
-		goto sendResponse
+		explanation.addMessage("Hyperconvergence is disabled for the pod")
+		return nil
 	}
 
-	{ // Put these variables in their own scope so we can use the goto above
-		driverVolumes, _, err := e.Driver.GetPodVolumes(&pod.Spec, pod.Namespace, true)
-		if err != nil {
-			msg := fmt.Sprintf("Error getting volumes for Pod for driver: %v", err)
-			storklog.PodLog(pod).Warnf(msg)
-			e.Recorder.Event(pod, v1.EventTypeWarning, schedulingFailureEventReason, msg)
-			if _, ok := err.(*volume.ErrPVCPending); ok {
-				http.Error(w, "Waiting for PVC to be bound", http.StatusBadRequest)
-				return
-			}
-			goto sendResponse
-		} else if len(driverVolumes) > 0 {
-			driverNodes, err := e.Driver.GetNodes()
-			if err != nil {
-				storklog.PodLog(pod).Errorf("Error getting nodes for driver: %v", err)
-				goto sendResponse
-			}
+	driverVolumes, _, err := e.Driver.GetPodVolumes(&pod.Spec, pod.Namespace, true)
+	if err != nil {
+		msg := fmt.Sprintf("Error getting volumes for Pod for driver: %v", err)
+		storklog.PodLog(pod).Warnf(msg)
+		e.recordSchedulingFailure(pod, msg, explanation)
+		if _, ok := err.(*volume.ErrPVCPending); ok {
+			return fmt.Errorf("Waiting for PVC to be bound")
+		}
+		return nil
+	} else if len(driverVolumes) == 0 {
+		return nil
+	}
+	driverNodes, err := e.Driver.GetNodes()
+	if err != nil {
+		storklog.PodLog(pod).Errorf("Error getting nodes for driver: %v", err)
+		explanation.addMessage(fmt.Sprintf("Error getting nodes for driver: %v", err))
+		return nil
+	}
 
-			// Create a map for ID->Node and Hostname->Rack/Zone/Region
-			idMap := make(map[string]*volume.NodeInfo)
-			var rackInfo, zoneInfo, regionInfo localityInfo
-			rackInfo.HostnameMap = make(map[string]string)
-			zoneInfo.HostnameMap = make(map[string]string)
-			regionInfo.HostnameMap = make(map[string]string)
-			// Create a map for k8s node index to StorageNode
-			k8sNodeIndexStorageNodeMap := make(map[int]*volume.NodeInfo)
-			for _, dnode := range driverNodes {
-				// Replace driver's hostname with the kubernetes hostname to make it
-				// easier to match nodes when calculating scores
-				for k8sNodeIndex, knode := range args.Nodes.Items {
-					if volume.IsNodeMatch(&knode, dnode) {
-						dnode.Hostname = e.getHostname(&knode)
-						k8sNodeIndexStorageNodeMap[k8sNodeIndex] = dnode
-						break
-					}
-				}
-				idMap[dnode.StorageID] = dnode
-				storklog.PodLog(pod).Debugf("nodeInfo: %v", dnode)
-				// For any node that is offline remove the locality info so that we
-				// don't prioritize nodes close to it
-				if dnode.Status == volume.NodeOnline || dnode.Status == volume.NodeDegraded {
-					// Add region info into zone and zone info into rack so that we can
-					// differentiate same names in different localities
-					regionInfo.HostnameMap[dnode.Hostname] = dnode.Region
-					if regionInfo.HostnameMap[dnode.Hostname] != "" {
-						zoneInfo.HostnameMap[dnode.Hostname] = regionInfo.HostnameMap[dnode.Hostname] + "-" + dnode.Zone
-					} else {
-						zoneInfo.HostnameMap[dnode.Hostname] = dnode.Zone
-					}
-					if zoneInfo.HostnameMap[dnode.Hostname] != "" {
-						rackInfo.HostnameMap[dnode.Hostname] = zoneInfo.HostnameMap[dnode.Hostname] + "-" + dnode.Rack
-					} else {
-						rackInfo.HostnameMap[dnode.Hostname] = dnode.Rack
-					}
-				} else {
-					rackInfo.HostnameMap[dnode.Hostname] = ""
-					zoneInfo.HostnameMap[dnode.Hostname] = ""
-					regionInfo.HostnameMap[dnode.Hostname] = ""
-				}
+	// Create a map for ID->Node and Hostname->Rack/Zone/Region
+	idMap := make(map[string]*volume.NodeInfo)
+	var rackInfo, zoneInfo, regionInfo localityInfo
+	rackInfo.HostnameMap = make(map[string]string)
+	zoneInfo.HostnameMap = make(map[string]string)
+	regionInfo.HostnameMap = make(map[string]string)
+	// Create a map for k8s node index to StorageNode
+	k8sNodeIndexStorageNodeMap := make(map[int]*volume.NodeInfo)
+	for _, dnode := range driverNodes {
+		// Replace driver's hostname with the kubernetes hostname to make it
+		// easier to match nodes when calculating scores
+		for k8sNodeIndex, knode := range nodes {
+			if volume.IsNodeMatch(&knode, dnode) {
+				dnode.Hostname = e.getHostname(&knode)
+				k8sNodeIndexStorageNodeMap[k8sNodeIndex] = dnode
+				break
 			}
-
-			storklog.PodLog(pod).Debugf("rackMap: %v", rackInfo.HostnameMap)
-			storklog.PodLog(pod).Debugf("zoneMap: %v", zoneInfo.HostnameMap)
-			storklog.PodLog(pod).Debugf("regionMap: %v", regionInfo.HostnameMap)
-
-			for _, volume := range driverVolumes {
-				skipVolumeScoring := false
-				if value, exists := volume.Labels[skipScoringLabel]; exists {
-					if skipVolumeScoring, err = strconv.ParseBool(value); err != nil {
-						skipVolumeScoring = false
-					}
-				}
-				if skipVolumeScoring {
-					storklog.PodLog(pod).Debugf("Skipping volume %v from scoring", volume.VolumeName)
-					continue
-				}
-				storklog.PodLog(pod).Debugf("Volume %v allocated on nodes:", volume.VolumeName)
-				// Get the racks, zones and regions where the volume is located
-				rackInfo.PreferredLocality = rackInfo.PreferredLocality[:0]
-				zoneInfo.PreferredLocality = zoneInfo.PreferredLocality[:0]
-				regionInfo.PreferredLocality = regionInfo.PreferredLocality[:0]
-				for _, node := range volume.DataNodes {
-					if _, ok := idMap[node]; ok {
-						log.Debugf("ID: %v Hostname: %v", node, idMap[node].Hostname)
-						regionInfo.PreferredLocality = append(regionInfo.PreferredLocality, regionInfo.HostnameMap[idMap[node].Hostname])
-						zoneInfo.PreferredLocality = append(zoneInfo.PreferredLocality, zoneInfo.HostnameMap[idMap[node].Hostname])
-						rackInfo.PreferredLocality = append(rackInfo.PreferredLocality, rackInfo.HostnameMap[idMap[node].Hostname])
-					} else {
-						log.Warnf("Node %v not found in list of nodes, skipping", node)
-					}
-				}
-				storklog.PodLog(pod).Debugf("Volume %v allocated on racks: %v", volume.VolumeName, rackInfo.PreferredLocality)
-				storklog.PodLog(pod).Debugf("Volume %v allocated in zones: %v", volume.VolumeName, zoneInfo.PreferredLocality)
-				storklog.PodLog(pod).Debugf("Volume %v allocated in regions: %v", volume.VolumeName, regionInfo.PreferredLocality)
-
-				for k8sNodeIndex, node := range args.Nodes.Items {
-					storageNode := k8sNodeIndexStorageNodeMap[k8sNodeIndex]
-					priorityMap[node.Name] += int(e.getNodeScore(node, volume, &rackInfo, &zoneInfo, &regionInfo, storageNode))
-				}
+		}
+		idMap[dnode.StorageID] = dnode
+		storklog.PodLog(pod).Debugf("nodeInfo: %v", dnode)
+		// For any node that is offline remove the locality info so that we
+		// don't prioritize nodes close to it
+		if dnode.Status == volume.NodeOnline || dnode.Status == volume.NodeDegraded {
+			// Add region info into zone and zone info into rack so that we can
+			// differentiate same names in different localities
+			regionInfo.HostnameMap[dnode.Hostname] = dnode.Region
+			if regionInfo.HostnameMap[dnode.Hostname] != "" {
+				zoneInfo.HostnameMap[dnode.Hostname] = regionInfo.HostnameMap[dnode.Hostname] + "-" + dnode.Zone
+			} else {
+				zoneInfo.HostnameMap[dnode.Hostname] = dnode.Zone
 			}
+			if zoneInfo.HostnameMap[dnode.Hostname] != "" {
+				rackInfo.HostnameMap[dnode.Hostname] = zoneInfo.HostnameMap[dnode.Hostname] + "-" + dnode.Rack
+			} else {
+				rackInfo.HostnameMap[dnode.Hostname] = dnode.Rack
+			}
+		} else {
+			rackInfo.HostnameMap[dnode.Hostname] = ""
+			zoneInfo.HostnameMap[dnode.Hostname] = ""
+			regionInfo.HostnameMap[dnode.Hostname] = ""
 		}
 	}
 
-sendResponse:
-	// For any nodes that didn't have any volumes, assign it a
-	// default score so that it doesn't get completely ignored
-	// by the scheduler
-	for _, node := range args.Nodes.Items {
-		score, ok := priorityMap[node.Name]
-		if !ok || score == 0 {
-			score = int(defaultScore)
+	storklog.PodLog(pod).Debugf("rackMap: %v", rackInfo.HostnameMap)
+	storklog.PodLog(pod).Debugf("zoneMap: %v", zoneInfo.HostnameMap)
+	storklog.PodLog(pod).Debugf("regionMap: %v", regionInfo.HostnameMap)
+
+	// Nodes that have data for any of the volumes
+	hasLocality := make(map[string]bool)
+	for _, volume := range driverVolumes {
+		skipVolumeScoring := false
+		if value, exists := volume.Labels[skipScoringLabel]; exists {
+			if skipVolumeScoring, err = strconv.ParseBool(value); err != nil {
+				skipVolumeScoring = false
+			}
+		}
+		if skipVolumeScoring {
+			storklog.PodLog(pod).Debugf("Skipping volume %v from scoring", volume.VolumeName)
+			explanation.addMessage(fmt.Sprintf("Volume %v is skipped from scoring", volume.VolumeName))
+			continue
+		}
+		storklog.PodLog(pod).Debugf("Volume %v allocated on nodes:", volume.VolumeName)
+		// Get the racks, zones and regions where the volume is located
+		rackInfo.PreferredLocality = rackInfo.PreferredLocality[:0]
+		zoneInfo.PreferredLocality = zoneInfo.PreferredLocality[:0]
+		regionInfo.PreferredLocality = regionInfo.PreferredLocality[:0]
+		for _, node := range volume.DataNodes {
+			if _, ok := idMap[node]; ok {
+				log.Debugf("ID: %v Hostname: %v", node, idMap[node].Hostname)
+				regionInfo.PreferredLocality = append(regionInfo.PreferredLocality, regionInfo.HostnameMap[idMap[node].Hostname])
+				zoneInfo.PreferredLocality = append(zoneInfo.PreferredLocality, zoneInfo.HostnameMap[idMap[node].Hostname])
+				rackInfo.PreferredLocality = append(rackInfo.PreferredLocality, rackInfo.HostnameMap[idMap[node].Hostname])
+			} else {
+				log.Warnf("Node %v not found in list of nodes, skipping", node)
+			}
+		}
+		storklog.PodLog(pod).Debugf("Volume %v allocated on racks: %v", volume.VolumeName, rackInfo.PreferredLocality)
+		storklog.PodLog(pod).Debugf("Volume %v allocated in zones: %v", volume.VolumeName, zoneInfo.PreferredLocality)
+		storklog.PodLog(pod).Debugf("Volume %v allocated in regions: %v", volume.VolumeName, regionInfo.PreferredLocality)
+
+		for k8sNodeIndex, node := range nodes {
+			storageNode := k8sNodeIndexStorageNodeMap[k8sNodeIndex]
+			score := e.getNodeScore(node, volume, &rackInfo, &zoneInfo, &regionInfo, storageNode, policy)
+			priorityMap[node.Name] += int(score.score)
+			if score.locality != LocalityMatchNone {
+				hasLocality[node.Name] = true
+			}
+			explanation.addVolumeScore(node.Name, volume.VolumeName, score)
 		}
-		hostPriority := schedulerapi.HostPriority{Host: node.Name, Score: int64(score)}
-		respList = append(respList, hostPriority)
-	}
-
-	storklog.PodLog(pod).Debugf("Nodes in response:")
-	for _, node := range respList {
-		storklog.PodLog(pod).Debugf("%+v", node)
 	}
 
-	if err := encoder.Encode(respList); err != nil {
-		storklog.PodLog(pod).Errorf("Failed to encode response: %v", err)
+	// Reduce the scores of nodes that are full or busy so that pods
+	// using the same volumes don't all pile onto one node
+	for k8sNodeIndex, node := range nodes {
+		storageNode, ok := k8sNodeIndexStorageNodeMap[k8sNodeIndex]
+		if !ok || priorityMap[node.Name] == 0 {
+			continue
+		}
+		factor := policy.utilizationFactor(storageNode)
+		if factor < 1 {
+			storklog.PodLog(pod).Debugf("Scaling score for node %v by %v based on its capacity and IO load",
+				node.Name, factor)
+			score := int(float64(priorityMap[node.Name]) * factor)
+			// Nodes with data for the pod should never end up below nodes
+			// without any data because of the penalty
+			floor := int(policy.DefaultScore)
+			if priorityMap[node.Name] < floor {
+				floor = priorityMap[node.Name]
+			}
+			if hasLocality[node.Name] && score < floor {
+				score = floor
+			}
+			priorityMap[node.Name] = score
+			explanation.setUtilizationFactor(node.Name, factor)
+		}
 	}
+	return nil
 }
@@ -15,7 +15,9 @@ import (
 
 	"github.com/libopenstorage/stork/drivers/volume"
 	"github.com/libopenstorage/stork/drivers/volume/mock"
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	fakeclient "github.com/libopenstorage/stork/pkg/client/clientset/versioned/fake"
+	"github.com/libopenstorage/stork/pkg/rule"
 	restore "github.com/libopenstorage/stork/pkg/snapshot/controllers"
 	fakeocpclient "github.com/openshift/client-go/apps/clientset/versioned/fake"
 	"github.com/portworx/sched-ops/k8s/core"
@@ -332,9 +334,15 @@ func TestExtender(t *testing.T) {
 	t.Run("ipTest", ipTest)
 	t.Run("invalidRequestsTest", invalidRequestsTest)
 	t.Run("noReplicasTest", noReplicasTest)
+	t.Run("accessibleNodesTest", accessibleNodesTest)
 	t.Run("restorePVCTest", restorePVCTest)
 	t.Run("preferLocalNodeTest", preferLocalNodeTest)
 	t.Run("extenderMetricsTest", extenderMetricsTest)
+	t.Run("scoringPolicyTest", scoringPolicyTest)
+	t.Run("capacityLoadTest", capacityLoadTest)
+	t.Run("capacityLoadLocalityTest", capacityLoadLocalityTest)
+	t.Run("explainTest", explainTest)
+	t.Run("testRuleTest", testRuleTest)
 	t.Run("teardown", teardown)
 }
 
@@ -1239,6 +1247,33 @@ func noReplicasTest(t *testing.T) {
 	require.Error(t, err, "Expected error since no replicas are online")
 }
 
+// Create a volume that can only be accessed from some of the nodes
+// The filter response should only return the nodes from which the volume can
+// be accessed, and an error if it can't be accessed from any of them
+func accessibleNodesTest(t *testing.T) {
+	nodes := &v1.NodeList{}
+	nodes.Items = append(nodes.Items, *newNode("node1", "node1", "192.168.0.1", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node2", "node2", "192.168.0.2", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node3", "node3", "192.168.0.3", "rack1", "", ""))
+
+	if err := driver.CreateCluster(3, nodes); err != nil {
+		t.Fatalf("Error creating cluster: %v", err)
+	}
+	pod := newPod("accessibleNodesTest", map[string]bool{"accessibleNodesTest": false})
+
+	if err := driver.ProvisionVolume("accessibleNodesTest", []int{0}, 1, nil); err != nil {
+		t.Fatalf("Error provisioning volume: %v", err)
+	}
+	require.NoError(t, driver.UpdateVolumeAccessibleNodes("accessibleNodesTest", []int{0, 2}))
+	filterResponse, err := sendFilterRequest(pod, nodes)
+	require.NoError(t, err, "Error sending filter request")
+	verifyFilterResponse(t, nodes, []int{0, 2}, filterResponse)
+
+	require.NoError(t, driver.UpdateVolumeAccessibleNodes("accessibleNodesTest", []int{}))
+	_, err = sendFilterRequest(pod, nodes)
+	require.Error(t, err, "Expected error since the volume can't be accessed from any node")
+}
+
 // Verify whether extender is checking restore annotation for pVC
 // Create PVC with restore annotation,
 // verify pod is not scheduled
@@ -1396,3 +1431,396 @@ func extenderMetricsTest(t *testing.T) {
 	time.Sleep(3 * time.Second)
 	require.Equal(t, testutil.ToFloat64(NonHyperConvergePodsCounter), float64(1), "non_hyperconverged_pods_total not matched")
 }
+
+// Create a scoring policy ConfigMap with a custom policy and select it for a
+// pod. Place the data on nodes n1, n2 and degrade n3.
+// The prioritize response should use the weights from the custom policy, and
+// pods without the annotation should still use the default policy.
+func scoringPolicyTest(t *testing.T) {
+	nodes := &v1.NodeList{}
+	nodes.Items = append(nodes.Items, *newNode("node1", "node1", "192.168.0.1", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node2", "node2", "192.168.0.2", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node3", "node3", "192.168.0.3", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node4", "node4", "192.168.0.4", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node5", "node5", "192.168.0.5", "rack3", "", ""))
+
+	if err := driver.CreateCluster(5, nodes); err != nil {
+		t.Fatalf("Error creating cluster: %v", err)
+	}
+	if err := driver.ProvisionVolume("scoringPolicyVolume", []int{0, 1}, 1, nil); err != nil {
+		t.Fatalf("Error provisioning volume: %v", err)
+	}
+	if err := driver.UpdateNodeStatus(2, volume.NodeDegraded); err != nil {
+		t.Fatalf("Error setting node status to Degraded: %v", err)
+	}
+
+	_, err := core.Instance().CreateConfigMap(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ScoringPolicyConfigMapName,
+			Namespace: metav1.NamespaceSystem,
+		},
+		Data: map[string]string{
+			"custom":  `{"nodePriorityScore": 200, "rackPriorityScore": 80, "degradedNodeScorePenaltyPercentage": 25}`,
+			"invalid": `{"degradedNodeScorePenaltyPercentage": 200}`,
+		},
+	})
+	require.NoError(t, err, "Error creating scoring policy configmap")
+	require.Eventually(t, func() bool {
+		_, policy := extender.getScoringPolicy(&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{scoringPolicyAnnotation: "custom"},
+			},
+		})
+		return policy.NodePriorityScore == 200
+	}, 10*time.Second, 100*time.Millisecond, "Scoring policy was not reloaded")
+
+	pod := newPod("scoringPolicyPod", map[string]bool{"scoringPolicyVolume": false})
+	pod.Annotations[scoringPolicyAnnotation] = "custom"
+	prioritizeResponse, err := sendPrioritizeRequest(pod, nodes)
+	if err != nil {
+		t.Fatalf("Error sending prioritize request: %v", err)
+	}
+	verifyPrioritizeResponse(
+		t,
+		nodes,
+		[]float64{200,
+			200,
+			80 * 0.25,
+			80,
+			defaultScore},
+		prioritizeResponse)
+	require.Equal(t, float64(1), testutil.ToFloat64(ScoringPolicyRequestsCounter.WithLabelValues("custom")), "scoring policy requests not matched")
+
+	// The invalid policy is ignored and the default policy is used instead
+	pod.Annotations[scoringPolicyAnnotation] = "invalid"
+	prioritizeResponse, err = sendPrioritizeRequest(pod, nodes)
+	if err != nil {
+		t.Fatalf("Error sending prioritize request: %v", err)
+	}
+	verifyPrioritizeResponse(
+		t,
+		nodes,
+		[]float64{nodePriorityScore,
+			nodePriorityScore,
+			rackPriorityScore * (degradedNodeScorePenaltyPercentage / 100),
+			rackPriorityScore,
+			defaultScore},
+		prioritizeResponse)
+
+	err = core.Instance().DeleteConfigMap(ScoringPolicyConfigMapName, metav1.NamespaceSystem)
+	require.NoError(t, err, "Error deleting scoring policy configmap")
+	require.Eventually(t, func() bool {
+		name, _ := extender.getScoringPolicy(pod)
+		return name == DefaultScoringPolicyName
+	}, 10*time.Second, 100*time.Millisecond, "Scoring policies were not reset")
+}
+
+func TestParseScoringPolicies(t *testing.T) {
+	policies := parseScoringPolicies(nil)
+	require.Equal(t, defaultScoringPolicy(), policies[DefaultScoringPolicyName], "Default policy mismatch")
+
+	policies = parseScoringPolicies(&v1.ConfigMap{
+		Data: map[string]string{
+			DefaultScoringPolicyName: `{"defaultScore": 10}`,
+			"custom":                 `{"zonePriorityScore": 30}`,
+			"negative":               `{"zonePriorityScore": -1}`,
+			"invalid":                `zonePriorityScore`,
+		},
+	})
+	require.Len(t, policies, 2, "Invalid policies should have been skipped")
+	require.Equal(t, float64(10), policies[DefaultScoringPolicyName].DefaultScore, "Default policy should be overridden")
+	require.Equal(t, nodePriorityScore, policies[DefaultScoringPolicyName].NodePriorityScore, "Unset fields should use the built-in defaults")
+	require.Equal(t, float64(30), policies["custom"].ZonePriorityScore, "Custom policy mismatch")
+	require.Equal(t, float64(10), policies["custom"].DefaultScore, "Unset fields should use the default policy")
+}
+
+// Create a pod with a PVC using the mock storage class.
+// Place the data on nodes n1, n2 and n3. Mark n1 as full and busy, and n2 as
+// half full. Send requests with node n1, n2, n3, n4, n5
+// The prioritize response should reduce the scores of n1 and n2 based on how
+// full and busy they are, and leave n3 which doesn't report utilization as is
+func capacityLoadTest(t *testing.T) {
+	nodes := &v1.NodeList{}
+	nodes.Items = append(nodes.Items, *newNode("node1", "node1", "192.168.0.1", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node2", "node2", "192.168.0.2", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node3", "node3", "192.168.0.3", "rack3", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node4", "node4", "192.168.0.4", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node5", "node5", "192.168.0.5", "rack4", "", ""))
+
+	if err := driver.CreateCluster(5, nodes); err != nil {
+		t.Fatalf("Error creating cluster: %v", err)
+	}
+	if err := driver.ProvisionVolume("capacityLoadVolume", []int{0, 1, 2}, 1, nil); err != nil {
+		t.Fatalf("Error provisioning volume: %v", err)
+	}
+	fullLoad := float64(100)
+	require.NoError(t, driver.UpdateNodeCapacity(0, &volume.NodeCapacity{Total: 1024, Free: 0}))
+	require.NoError(t, driver.UpdateNodeIOLoad(0, &fullLoad))
+	require.NoError(t, driver.UpdateNodeCapacity(1, &volume.NodeCapacity{Total: 1024, Free: 512}))
+
+	pod := newPod("capacityLoadPod", map[string]bool{"capacityLoadVolume": false})
+	prioritizeResponse, err := sendPrioritizeRequest(pod, nodes)
+	if err != nil {
+		t.Fatalf("Error sending prioritize request: %v", err)
+	}
+	verifyPrioritizeResponse(
+		t,
+		nodes,
+		[]float64{nodePriorityScore * (1 - (capacityScorePenaltyPercentage+ioLoadScorePenaltyPercentage)/100),
+			nodePriorityScore * (1 - capacityScorePenaltyPercentage/200),
+			nodePriorityScore,
+			rackPriorityScore,
+			defaultScore},
+		prioritizeResponse)
+}
+
+// Create a pod with a PVC using the mock storage class.
+// Place the data on nodes n1 and n2. Mark n1 and n4 as full and busy, and n3
+// as full. Use a policy with penalties that can take a node's whole score.
+// The prioritize response should never score nodes with data or in the same
+// rack as the data below the default score, which n5 gets.
+func capacityLoadLocalityTest(t *testing.T) {
+	nodes := &v1.NodeList{}
+	nodes.Items = append(nodes.Items, *newNode("node1", "node1", "192.168.0.1", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node2", "node2", "192.168.0.2", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node3", "node3", "192.168.0.3", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node4", "node4", "192.168.0.4", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node5", "node5", "192.168.0.5", "rack3", "", ""))
+
+	if err := driver.CreateCluster(5, nodes); err != nil {
+		t.Fatalf("Error creating cluster: %v", err)
+	}
+	if err := driver.ProvisionVolume("capacityLoadLocalityVolume", []int{0, 1}, 1, nil); err != nil {
+		t.Fatalf("Error provisioning volume: %v", err)
+	}
+	fullLoad := float64(100)
+	require.NoError(t, driver.UpdateNodeCapacity(0, &volume.NodeCapacity{Total: 1024, Free: 0}))
+	require.NoError(t, driver.UpdateNodeIOLoad(0, &fullLoad))
+	require.NoError(t, driver.UpdateNodeCapacity(2, &volume.NodeCapacity{Total: 1024, Free: 0}))
+	require.NoError(t, driver.UpdateNodeCapacity(3, &volume.NodeCapacity{Total: 1024, Free: 0}))
+	require.NoError(t, driver.UpdateNodeIOLoad(3, &fullLoad))
+	require.NoError(t, driver.UpdateNodeCapacity(4, &volume.NodeCapacity{Total: 1024, Free: 0}))
+	require.NoError(t, driver.UpdateNodeIOLoad(4, &fullLoad))
+
+	_, err := core.Instance().CreateConfigMap(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ScoringPolicyConfigMapName,
+			Namespace: metav1.NamespaceSystem,
+		},
+		Data: map[string]string{
+			"penalty": `{"defaultScore": 20, "capacityScorePenaltyPercentage": 50, "ioLoadScorePenaltyPercentage": 40}`,
+		},
+	})
+	require.NoError(t, err, "Error creating scoring policy configmap")
+	defer func() {
+		err := core.Instance().DeleteConfigMap(ScoringPolicyConfigMapName, metav1.NamespaceSystem)
+		require.NoError(t, err, "Error deleting scoring policy configmap")
+	}()
+	pod := newPod("capacityLoadLocalityPod", map[string]bool{"capacityLoadLocalityVolume": false})
+	pod.Annotations[scoringPolicyAnnotation] = "penalty"
+	require.Eventually(t, func() bool {
+		name, _ := extender.getScoringPolicy(pod)
+		return name == "penalty"
+	}, 10*time.Second, 100*time.Millisecond, "Scoring policy was not reloaded")
+
+	prioritizeResponse, err := sendPrioritizeRequest(pod, nodes)
+	if err != nil {
+		t.Fatalf("Error sending prioritize request: %v", err)
+	}
+	verifyPrioritizeResponse(
+		t,
+		nodes,
+		[]float64{20,
+			nodePriorityScore,
+			rackPriorityScore / 2,
+			20,
+			20},
+		prioritizeResponse)
+}
+
+func TestUtilizationFactor(t *testing.T) {
+	policy := defaultScoringPolicy()
+	node := &volume.NodeInfo{}
+	require.Equal(t, float64(1), policy.utilizationFactor(node), "Nodes without utilization should keep their score")
+
+	node.Capacity = &volume.NodeCapacity{Total: 100, Free: 25}
+	require.InDelta(t, 1-capacityScorePenaltyPercentage*0.75/100, policy.utilizationFactor(node), 0.0001)
+
+	load := float64(150)
+	node.IOLoad = &load
+	require.InDelta(t, 1-(capacityScorePenaltyPercentage*0.75+ioLoadScorePenaltyPercentage)/100, policy.utilizationFactor(node), 0.0001,
+		"IO load should be capped at 100")
+
+	policy.CapacityScorePenaltyPercentage = 60
+	policy.IOLoadScorePenaltyPercentage = 60
+	require.Error(t, policy.validate(), "Penalties adding up to more than 100 should be invalid")
+}
+
+func sendExplainRequest(t *testing.T, req *http.Request) *SchedulingExplanation {
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err, "Error sending explain request")
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logrus.Warnf("Error closing decoder: %v", err)
+		}
+	}()
+	require.Equal(t, http.StatusOK, resp.StatusCode, "Unexpected status for explain request")
+	explanation := &SchedulingExplanation{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(explanation), "Error decoding explain response")
+	return explanation
+}
+
+// Create a pod with a PVC using the mock storage class.
+// Place the data on nodes n1 and n2. Mark n2 as degraded and n5 as offline.
+// The explain response should filter out n5, show the locality of each node
+// and the penalty for n2, and have the same scores as the prioritize request
+func explainTest(t *testing.T) {
+	nodes := &v1.NodeList{}
+	nodes.Items = append(nodes.Items, *newNode("explain-node1", "explain-node1", "192.168.0.1", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("explain-node2", "explain-node2", "192.168.0.2", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("explain-node3", "explain-node3", "192.168.0.3", "rack3", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("explain-node4", "explain-node4", "192.168.0.4", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("explain-node5", "explain-node5", "192.168.0.5", "rack2", "", ""))
+	for _, node := range nodes.Items {
+		_, err := core.Instance().CreateNode(node.DeepCopy())
+		require.NoError(t, err, "Error creating node")
+	}
+
+	if err := driver.CreateCluster(5, nodes); err != nil {
+		t.Fatalf("Error creating cluster: %v", err)
+	}
+	if err := driver.ProvisionVolume("explainVolume", []int{0, 1}, 1, nil); err != nil {
+		t.Fatalf("Error provisioning volume: %v", err)
+	}
+	require.NoError(t, driver.UpdateNodeStatus(1, volume.NodeDegraded))
+	require.NoError(t, driver.UpdateNodeStatus(4, volume.NodeOffline))
+
+	pod := newPod("explainPod", map[string]bool{"explainVolume": false})
+	_, err := core.Instance().CreatePod(pod)
+	require.NoError(t, err, "Error creating pod")
+
+	req, err := http.NewRequest(http.MethodGet, "http://localhost:8099"+ExplainPath, nil)
+	require.NoError(t, err)
+	query := req.URL.Query()
+	query.Set("pod", pod.Name)
+	query.Set("namespace", pod.Namespace)
+	req.URL.RawQuery = query.Encode()
+	explanation := sendExplainRequest(t, req)
+
+	require.Equal(t, pod.Name, explanation.Pod)
+	require.Equal(t, DefaultScoringPolicyName, explanation.ScoringPolicy)
+	require.Empty(t, explanation.FilterError)
+	require.Len(t, explanation.Nodes, len(nodes.Items))
+
+	expectedLocality := []LocalityMatch{LocalityMatchNode, LocalityMatchNode, LocalityMatchNone, LocalityMatchRack}
+	expectedScores := []int64{int64(nodePriorityScore),
+		int64(rackPriorityScore * (degradedNodeScorePenaltyPercentage / 100)),
+		int64(defaultScore),
+		int64(rackPriorityScore)}
+	for i, node := range nodes.Items[:4] {
+		nodeExplanation := explanation.getNode(node.Name)
+		require.NotNil(t, nodeExplanation, "Node %v missing from explanation", node.Name)
+		require.Empty(t, nodeExplanation.FilterReason, "Node %v should not be filtered out", node.Name)
+		require.Len(t, nodeExplanation.Volumes, 1)
+		require.Equal(t, expectedLocality[i], nodeExplanation.Volumes[0].Locality, "Locality mismatch for node %v", node.Name)
+		require.Equal(t, expectedScores[i], nodeExplanation.Score, "Score mismatch for node %v", node.Name)
+	}
+	degradedNode := explanation.getNode("explain-node2")
+	require.Equal(t, nodePriorityScore-rackPriorityScore*(degradedNodeScorePenaltyPercentage/100),
+		degradedNode.Volumes[0].DegradedPenalty)
+	require.True(t, explanation.getNode("explain-node3").DefaultScore)
+	offlineNode := explanation.getNode("explain-node5")
+	require.Contains(t, offlineNode.FilterReason, string(volume.NodeOffline))
+	require.Empty(t, offlineNode.Volumes)
+
+	// The pod spec can also be explained without creating the pod
+	pod.Annotations[disableHyperconvergenceAnnotation] = "true"
+	data, err := json.Marshal(pod)
+	require.NoError(t, err)
+	req, err = http.NewRequest(http.MethodPost, "http://localhost:8099"+ExplainPath, strings.NewReader(string(data)))
+	require.NoError(t, err)
+	explanation = sendExplainRequest(t, req)
+	require.Empty(t, explanation.FilterError)
+	require.NotEmpty(t, explanation.Messages)
+	for _, node := range explanation.Nodes {
+		if node.Name == "explain-node5" {
+			continue
+		}
+		require.Equal(t, int64(defaultScore), node.Score, "All nodes should have the default score for node %v", node.Name)
+	}
+
+	resp, err := http.Get("http://localhost:8099" + ExplainPath + "?pod=missingPod")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+}
+
+// Create a rule with an action for pods that are running and test it through
+// the extender. Requests for missing rules or invalid types should fail.
+func testRuleTest(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testrule-pod",
+			Namespace: "testrule",
+			Labels:    map[string]string{"app": "testrule"},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: "app"}},
+		},
+		Status: v1.PodStatus{Phase: v1.PodRunning},
+	}
+	_, err := core.Instance().CreatePod(pod)
+	require.NoError(t, err, "Error creating pod")
+	_, err = storkops.Instance().CreateRule(&storkv1.Rule{
+		ObjectMeta: metav1.ObjectMeta{Name: "testrule", Namespace: "testrule"},
+		Rules: []storkv1.RuleItem{
+			{
+				PodSelector: map[string]string{"app": "testrule"},
+				Actions: []storkv1.RuleAction{
+					{
+						Type:       storkv1.RuleActionCommand,
+						Value:      "sync",
+						Background: true,
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err, "Error creating rule")
+
+	resp, err := http.Get("http://localhost:8099" + TestRulePath + "?rule=testrule&namespace=testrule")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	result := &rule.TestRuleResult{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(result), "Error decoding test rule response")
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, "testrule", result.Namespace)
+	require.Equal(t, "testrule", result.PodNamespace)
+	require.Equal(t, rule.PreExecRule, result.Type)
+	require.Len(t, result.Actions, 1)
+	require.Equal(t, pod.Name, result.Actions[0].Pod)
+	require.Equal(t, "app", result.Actions[0].Container)
+	require.Empty(t, result.Actions[0].Error)
+
+	// Background actions aren't supported in post exec rules
+	resp, err = http.Get("http://localhost:8099" + TestRulePath + "?rule=testrule&namespace=testrule&type=postExecRule")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	resp, err = http.Get("http://localhost:8099" + TestRulePath + "?rule=testrule&namespace=testrule&type=midExecRule")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	resp, err = http.Get("http://localhost:8099" + TestRulePath + "?rule=missingrule&namespace=testrule")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	// Actions are never executed through the extender
+	resp, err = http.Get("http://localhost:8099" + TestRulePath + "?rule=testrule&namespace=testrule&execute=true")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+}
@@ -0,0 +1,248 @@
+package extender
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	storklog "github.com/libopenstorage/stork/pkg/log"
+	"github.com/portworx/sched-ops/k8s/core"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// ScoringPolicyConfigMapName is the name of the ConfigMap with the scoring
+	// policies for the extender. Each key in the ConfigMap is the name of a
+	// policy and the value is the policy in JSON. Fields that aren't set in a
+	// policy use the values from the default policy.
+	ScoringPolicyConfigMapName = "stork-scoring-policies"
+	// DefaultScoringPolicyName is the policy used for pods that don't select
+	// one. It can be overridden by adding it to the ConfigMap.
+	DefaultScoringPolicyName = "default"
+	// annotation to select the scoring policy to use for a pod
+	scoringPolicyAnnotation = "stork.libopenstorage.org/scoringPolicy"
+)
+
+// ScoringPolicy is the set of weights used to score nodes for a pod
+type ScoringPolicy struct {
+	// NodePriorityScore is the score for a node that has data for a volume
+	NodePriorityScore float64 `json:"nodePriorityScore"`
+	// RackPriorityScore is the score for a node in the same rack as a node
+	// which has data for a volume
+	RackPriorityScore float64 `json:"rackPriorityScore"`
+	// ZonePriorityScore is the score for a node in the same zone as a node
+	// which has data for a volume
+	ZonePriorityScore float64 `json:"zonePriorityScore"`
+	// RegionPriorityScore is the score for a node in the same region as a
+	// node which has data for a volume
+	RegionPriorityScore float64 `json:"regionPriorityScore"`
+	// DefaultScore is the score for a node which doesn't have data for any
+	// volume
+	DefaultScore float64 `json:"defaultScore"`
+	// DegradedNodeScorePenaltyPercentage is the percentage by which the score
+	// of a degraded node is reduced
+	DegradedNodeScorePenaltyPercentage float64 `json:"degradedNodeScorePenaltyPercentage"`
+	// CapacityScorePenaltyPercentage is the percentage by which the score of
+	// a node with full storage is reduced. It is scaled by the fraction of
+	// the storage that is used.
+	CapacityScorePenaltyPercentage float64 `json:"capacityScorePenaltyPercentage"`
+	// IOLoadScorePenaltyPercentage is the percentage by which the score of a
+	// node with fully loaded storage is reduced. It is scaled by the IO load
+	// of the node.
+	IOLoadScorePenaltyPercentage float64 `json:"ioLoadScorePenaltyPercentage"`
+}
+
+func defaultScoringPolicy() *ScoringPolicy {
+	return &ScoringPolicy{
+		NodePriorityScore:                  nodePriorityScore,
+		RackPriorityScore:                  rackPriorityScore,
+		ZonePriorityScore:                  zonePriorityScore,
+		RegionPriorityScore:                regionPriorityScore,
+		DefaultScore:                       defaultScore,
+		DegradedNodeScorePenaltyPercentage: degradedNodeScorePenaltyPercentage,
+		CapacityScorePenaltyPercentage:     capacityScorePenaltyPercentage,
+		IOLoadScorePenaltyPercentage:       ioLoadScorePenaltyPercentage,
+	}
+}
+
+func (p *ScoringPolicy) validate() error {
+	if p.NodePriorityScore < 0 || p.RackPriorityScore < 0 || p.ZonePriorityScore < 0 ||
+		p.RegionPriorityScore < 0 || p.DefaultScore < 0 {
+		return fmt.Errorf("scores can't be negative")
+	}
+	if p.DegradedNodeScorePenaltyPercentage < 0 || p.DegradedNodeScorePenaltyPercentage > 100 {
+		return fmt.Errorf("degradedNodeScorePenaltyPercentage should be between 0 and 100")
+	}
+	if p.CapacityScorePenaltyPercentage < 0 || p.IOLoadScorePenaltyPercentage < 0 ||
+		p.CapacityScorePenaltyPercentage+p.IOLoadScorePenaltyPercentage > 100 {
+		return fmt.Errorf("capacityScorePenaltyPercentage and ioLoadScorePenaltyPercentage " +
+			"can't be negative and should add up to at most 100")
+	}
+	return nil
+}
+
+// localityScore returns the score for a node that matched the data for a
+// volume at the given locality, penalizing it if the node is degraded
+func (p *ScoringPolicy) localityScore(locality LocalityMatch, score float64, node *volume.NodeInfo) nodeScore {
+	if node.Status == volume.NodeDegraded {
+		degraded := p.degradedScore(score)
+		return nodeScore{degraded, locality, score - degraded}
+	}
+	return nodeScore{score, locality, 0}
+}
+
+// utilizationFactor returns the fraction of its score a node keeps based on
+// how full and busy its storage is. Nodes for which the driver doesn't report
+// capacity or load keep their full score.
+func (p *ScoringPolicy) utilizationFactor(node *volume.NodeInfo) float64 {
+	penalty := 0.0
+	if node.Capacity != nil && node.Capacity.Total > 0 {
+		free := node.Capacity.Free
+		if free > node.Capacity.Total {
+			free = node.Capacity.Total
+		}
+		used := float64(node.Capacity.Total-free) / float64(node.Capacity.Total)
+		penalty += p.CapacityScorePenaltyPercentage * used
+	}
+	if node.IOLoad != nil {
+		load := math.Max(0, math.Min(100, *node.IOLoad))
+		penalty += p.IOLoadScorePenaltyPercentage * load / 100
+	}
+	return 1 - penalty/100
+}
+
+// degradedScore returns the score after applying the penalty for degraded
+// nodes
+func (p *ScoringPolicy) degradedScore(score float64) float64 {
+	return score * (p.DegradedNodeScorePenaltyPercentage / 100)
+}
+
+type scoringPolicies struct {
+	sync.RWMutex
+	policies map[string]*ScoringPolicy
+}
+
+// parseScoringPolicies returns the policies from the ConfigMap. Policies that
+// can't be parsed or are invalid are skipped so that one bad policy doesn't
+// affect the others.
+func parseScoringPolicies(cm *v1.ConfigMap) map[string]*ScoringPolicy {
+	policies := map[string]*ScoringPolicy{
+		DefaultScoringPolicyName: defaultScoringPolicy(),
+	}
+	if cm == nil {
+		return policies
+	}
+	if data, ok := cm.Data[DefaultScoringPolicyName]; ok {
+		policy := defaultScoringPolicy()
+		if err := parseScoringPolicy(data, policy); err != nil {
+			log.Errorf("Invalid scoring policy %v, using built-in defaults: %v", DefaultScoringPolicyName, err)
+		} else {
+			policies[DefaultScoringPolicyName] = policy
+		}
+	}
+	for name, data := range cm.Data {
+		if name == DefaultScoringPolicyName {
+			continue
+		}
+		policy := *policies[DefaultScoringPolicyName]
+		if err := parseScoringPolicy(data, &policy); err != nil {
+			log.Errorf("Invalid scoring policy %v, ignoring it: %v", name, err)
+			continue
+		}
+		policies[name] = &policy
+	}
+	return policies
+}
+
+func parseScoringPolicy(data string, policy *ScoringPolicy) error {
+	if err := json.Unmarshal([]byte(data), policy); err != nil {
+		return err
+	}
+	return policy.validate()
+}
+
+func (e *Extender) getScoringPolicyNamespace() string {
+	if e.ScoringPolicyNamespace != "" {
+		return e.ScoringPolicyNamespace
+	}
+	return metav1.NamespaceSystem
+}
+
+// watchScoringPolicies loads the scoring policies and reloads them whenever
+// the ConfigMap changes
+func (e *Extender) watchScoringPolicies() error {
+	e.scoringPolicies = &scoringPolicies{
+		policies: parseScoringPolicies(nil),
+	}
+	namespace := e.getScoringPolicyNamespace()
+	cm, err := core.Instance().GetConfigMap(ScoringPolicyConfigMapName, namespace)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		log.Infof("ConfigMap %v/%v not found, using default scoring policy", namespace, ScoringPolicyConfigMapName)
+		cm = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      ScoringPolicyConfigMapName,
+				Namespace: namespace,
+			},
+		}
+	} else {
+		e.setScoringPolicies(parseScoringPolicies(cm))
+	}
+
+	fn := func(object runtime.Object) error {
+		if _, ok := object.(*v1.ConfigMap); !ok {
+			return fmt.Errorf("invalid object type on configmap watch: %v", object)
+		}
+		// Get the latest version since the watch doesn't say if the ConfigMap
+		// was deleted
+		cm, err := core.Instance().GetConfigMap(ScoringPolicyConfigMapName, namespace)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			cm = nil
+		}
+		e.setScoringPolicies(parseScoringPolicies(cm))
+		return nil
+	}
+	return core.Instance().WatchConfigMap(cm, fn)
+}
+
+func (e *Extender) setScoringPolicies(policies map[string]*ScoringPolicy) {
+	names := make([]string, 0)
+	for name := range policies {
+		names = append(names, name)
+	}
+	log.Infof("Loaded scheduler scoring policies: %v", names)
+	e.scoringPolicies.Lock()
+	defer e.scoringPolicies.Unlock()
+	e.scoringPolicies.policies = policies
+}
+
+// getScoringPolicy returns the name and the scoring policy to use for the
+// pod. The default policy is used if the pod doesn't select one or the policy
+// it selects doesn't exist.
+func (e *Extender) getScoringPolicy(pod *v1.Pod) (string, *ScoringPolicy) {
+	if e.scoringPolicies == nil {
+		return DefaultScoringPolicyName, defaultScoringPolicy()
+	}
+	e.scoringPolicies.RLock()
+	defer e.scoringPolicies.RUnlock()
+	if pod != nil && pod.Annotations != nil {
+		if name, ok := pod.Annotations[scoringPolicyAnnotation]; ok && name != "" {
+			if policy, ok := e.scoringPolicies.policies[name]; ok {
+				return name, policy
+			}
+			storklog.PodLog(pod).Warnf("Scoring policy %v not found, using %v policy", name, DefaultScoringPolicyName)
+		}
+	}
+	return DefaultScoringPolicyName, e.scoringPolicies.policies[DefaultScoringPolicyName]
+}
@@ -0,0 +1,86 @@
+package extender
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/libopenstorage/stork/pkg/rule"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	testRule = "testrule"
+	// TestRulePath is the path of the endpoint on the extender that tests a
+	// rule. The rule is given by the rule and namespace query parameters,
+	// the namespace of the pods by podNamespace and the type of the rule by
+	// type. The actions are only resolved, never executed, since the
+	// endpoint doesn't authenticate its callers.
+	TestRulePath = "/" + testRule
+)
+
+func (e *Extender) processTestRuleRequest(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	name := query.Get("rule")
+	if name == "" {
+		http.Error(w, "Rule name is required", http.StatusBadRequest)
+		return
+	}
+	namespace := query.Get("namespace")
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	podNamespace := query.Get("podNamespace")
+	if podNamespace == "" {
+		podNamespace = namespace
+	}
+	rType := rule.Type(query.Get("type"))
+	if rType == "" {
+		rType = rule.PreExecRule
+	}
+	if rType != rule.PreExecRule && rType != rule.PostExecRule {
+		http.Error(w, fmt.Sprintf("Invalid rule type %v", rType), http.StatusBadRequest)
+		return
+	}
+	if value := query.Get("execute"); value != "" {
+		execute, err := strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid value for execute: %v", value), http.StatusBadRequest)
+			return
+		}
+		if execute {
+			http.Error(w, "Actions of rules can't be executed through stork, use storkctl test rule --execute instead",
+				http.StatusBadRequest)
+			return
+		}
+	}
+
+	r, err := storkops.Instance().GetRule(name, namespace)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.IsNotFound(err) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, fmt.Sprintf("Error getting rule %v/%v: %v", namespace, name, err), status)
+		return
+	}
+	if err := rule.ValidateRule(r, rType); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	log.Infof("Testing %v %v/%v on pods in namespace %v", rType, namespace, name, podNamespace)
+	result, err := rule.TestRule(r, rType, podNamespace, rule.TestRuleOptions{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		log.Errorf("Failed to encode test rule response: %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package keyprovider
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/libopenstorage/stork/pkg/crypto"
+)
+
+// fileProvider reads key encryption keys from files in a directory, for
+// example a mounted secret or a volume managed by an external agent. Every
+// file in the directory is a key ID.
+type fileProvider struct {
+	path string
+}
+
+func (f *fileProvider) WrapKey(keyID string, dataKey []byte) ([]byte, error) {
+	keyMaterial, err := f.getKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.WrapKey(keyMaterial, dataKey)
+}
+
+func (f *fileProvider) UnwrapKey(keyID string, wrappedKey []byte) ([]byte, error) {
+	keyMaterial, err := f.getKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.UnwrapKey(keyMaterial, wrappedKey)
+}
+
+func (f *fileProvider) getKey(keyID string) ([]byte, error) {
+	if keyID == "" || keyID != filepath.Base(keyID) || strings.HasPrefix(keyID, ".") {
+		return nil, fmt.Errorf("invalid key ID %v", keyID)
+	}
+	keyMaterial, err := ioutil.ReadFile(filepath.Join(f.path, keyID))
+	if err != nil {
+		return nil, fmt.Errorf("error reading key %v: %v", keyID, err)
+	}
+	keyMaterial = bytes.TrimSuffix(keyMaterial, []byte("\n"))
+	if len(keyMaterial) == 0 {
+		return nil, fmt.Errorf("key %v is empty", keyID)
+	}
+	return keyMaterial, nil
+}
@@ -0,0 +1,51 @@
+package keyprovider
+
+import (
+	"fmt"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+)
+
+// Provider wraps and unwraps data keys with key encryption keys. Key
+// encryption keys are identified by their key ID so that data keys wrapped
+// with older keys can still be unwrapped after the key has been rotated.
+type Provider interface {
+	// WrapKey encrypts the data key with the key encryption key keyID
+	WrapKey(keyID string, dataKey []byte) ([]byte, error)
+	// UnwrapKey decrypts a data key that was wrapped with the key
+	// encryption key keyID
+	UnwrapKey(keyID string, wrappedKey []byte) ([]byte, error)
+}
+
+// Get returns the key provider configured for the given backup location
+func Get(backupLocation *stork_api.BackupLocation) (Provider, error) {
+	if backupLocation == nil {
+		return nil, fmt.Errorf("nil backupLocation")
+	}
+	config := backupLocation.Location.EncryptionKeyProvider
+	if config == nil {
+		return nil, fmt.Errorf("no encryption key provider configured for backup location %v/%v",
+			backupLocation.Namespace, backupLocation.Name)
+	}
+
+	switch config.Type {
+	case stork_api.EncryptionKeyProviderSecret:
+		if config.SecretName == "" {
+			return nil, fmt.Errorf("secretName is required for the %v key provider", config.Type)
+		}
+		return &secretProvider{name: config.SecretName, namespace: backupLocation.Namespace}, nil
+	case stork_api.EncryptionKeyProviderFile:
+		if config.Path == "" {
+			return nil, fmt.Errorf("path is required for the %v key provider", config.Type)
+		}
+		return &fileProvider{path: config.Path}, nil
+	case stork_api.EncryptionKeyProviderKMS:
+		kms, err := GetKMS(config.KMS)
+		if err != nil {
+			return nil, err
+		}
+		return &kmsProvider{kms: kms}, nil
+	default:
+		return nil, fmt.Errorf("invalid encryption key provider type: %v", config.Type)
+	}
+}
@@ -0,0 +1,178 @@
+//go:build unittest
+// +build unittest
+
+package keyprovider
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/crypto"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func getBackupLocation(config *stork_api.EncryptionKeyProvider) *stork_api.BackupLocation {
+	return &stork_api.BackupLocation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "backuplocation",
+			Namespace: "test",
+		},
+		Location: stork_api.BackupLocationItem{
+			EncryptionKeyProvider: config,
+		},
+	}
+}
+
+func testWrapUnwrap(t *testing.T, provider Provider) {
+	dataKey, err := crypto.GenerateDataKey()
+	require.NoError(t, err, "Error generating data key")
+
+	wrappedKey, err := provider.WrapKey("key1", dataKey)
+	require.NoError(t, err, "Error wrapping data key")
+	unwrappedKey, err := provider.UnwrapKey("key1", wrappedKey)
+	require.NoError(t, err, "Error unwrapping data key")
+	require.Equal(t, dataKey, unwrappedKey, "Original and unwrapped data key mismatch")
+
+	_, err = provider.UnwrapKey("key2", wrappedKey)
+	require.Error(t, err, "Unwrapping with a different key should have failed")
+	_, err = provider.WrapKey("missing", dataKey)
+	require.Error(t, err, "Wrapping with a missing key should have failed")
+}
+
+func TestSecretProvider(t *testing.T) {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "keys",
+			Namespace: "test",
+		},
+		Data: map[string][]byte{
+			"key1": []byte("secretkey1\n"),
+			"key2": []byte("secretkey2"),
+		},
+	}
+	core.SetInstance(core.New(fake.NewSimpleClientset(secret)))
+
+	provider, err := Get(getBackupLocation(&stork_api.EncryptionKeyProvider{
+		Type:       stork_api.EncryptionKeyProviderSecret,
+		KeyID:      "key1",
+		SecretName: "keys",
+	}))
+	require.NoError(t, err, "Error getting secret provider")
+	testWrapUnwrap(t, provider)
+}
+
+func TestFileProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keyprovider")
+	require.NoError(t, err, "Error creating key directory")
+	defer os.RemoveAll(dir) // nolint: errcheck
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "key1"), []byte("filekey1\n"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "key2"), []byte("filekey2"), 0600))
+
+	provider, err := Get(getBackupLocation(&stork_api.EncryptionKeyProvider{
+		Type:  stork_api.EncryptionKeyProviderFile,
+		KeyID: "key1",
+		Path:  dir,
+	}))
+	require.NoError(t, err, "Error getting file provider")
+	testWrapUnwrap(t, provider)
+
+	_, err = provider.WrapKey("../key1", []byte("datakey"))
+	require.Error(t, err, "Key IDs outside the key directory should be rejected")
+}
+
+func TestKMSProvider(t *testing.T) {
+	kms := NewLocalKMS()
+	kms.AddKey("key1", []byte("kmskey1"))
+	kms.AddKey("key2", []byte("kmskey2"))
+	require.NoError(t, RegisterKMS("test", kms), "Error registering kms")
+	require.Error(t, RegisterKMS("test", kms), "Registering a kms twice should have failed")
+
+	_, err := Get(getBackupLocation(&stork_api.EncryptionKeyProvider{
+		Type: stork_api.EncryptionKeyProviderKMS,
+		KMS:  "missing",
+	}))
+	require.Error(t, err, "Getting an unregistered kms should have failed")
+
+	provider, err := Get(getBackupLocation(&stork_api.EncryptionKeyProvider{
+		Type:  stork_api.EncryptionKeyProviderKMS,
+		KeyID: "key1",
+		KMS:   "test",
+	}))
+	require.NoError(t, err, "Error getting kms provider")
+	testWrapUnwrap(t, provider)
+}
+
+// newFakeVault returns a server that implements the encrypt and decrypt
+// endpoints of the transit secrets engine for the given keys
+func newFakeVault(t *testing.T, keys ...string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		operation, keyID := path.Split(strings.TrimPrefix(r.URL.Path, "/v1/transit/"))
+		found := false
+		for _, key := range keys {
+			found = found || key == keyID
+		}
+		request := make(map[string]string)
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || !found {
+			http.Error(w, `{"errors":["invalid request"]}`, http.StatusBadRequest)
+			return
+		}
+		prefix := "vault:v1:" + keyID + ":"
+		var data map[string]string
+		switch operation {
+		case "encrypt/":
+			data = map[string]string{"ciphertext": prefix + request["plaintext"]}
+		case "decrypt/":
+			if !strings.HasPrefix(request["ciphertext"], prefix) {
+				http.Error(w, `{"errors":["cipher: message authentication failed"]}`, http.StatusBadRequest)
+				return
+			}
+			data = map[string]string{"plaintext": strings.TrimPrefix(request["ciphertext"], prefix)}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{"data": data}))
+	}))
+}
+
+func TestVaultTransit(t *testing.T) {
+	server := newFakeVault(t, "key1", "key2")
+	defer server.Close()
+	client, err := api.NewClient(&api.Config{Address: server.URL})
+	require.NoError(t, err, "Error creating vault client")
+	client.SetToken("token")
+
+	require.NoError(t, RegisterKMS("vault-test", NewVaultTransit(client, "transit")), "Error registering kms")
+	provider, err := Get(getBackupLocation(&stork_api.EncryptionKeyProvider{
+		Type:  stork_api.EncryptionKeyProviderKMS,
+		KeyID: "key1",
+		KMS:   "vault-test",
+	}))
+	require.NoError(t, err, "Error getting kms provider")
+	testWrapUnwrap(t, provider)
+
+	_, err = provider.WrapKey("../sys/key1", []byte("datakey"))
+	require.Error(t, err, "Key IDs outside the transit engine should be rejected")
+}
+
+func TestGetInvalidProvider(t *testing.T) {
+	_, err := Get(getBackupLocation(nil))
+	require.Error(t, err, "Getting a provider without config should have failed")
+	_, err = Get(getBackupLocation(&stork_api.EncryptionKeyProvider{Type: "invalid"}))
+	require.Error(t, err, "Getting an invalid provider should have failed")
+	_, err = Get(getBackupLocation(&stork_api.EncryptionKeyProvider{Type: stork_api.EncryptionKeyProviderSecret}))
+	require.Error(t, err, "Getting a secret provider without a secret should have failed")
+}
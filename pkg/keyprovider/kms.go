@@ -0,0 +1,113 @@
+package keyprovider
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/libopenstorage/stork/pkg/crypto"
+)
+
+// KMS is the interface for key management services that hold key encryption
+// keys. The keys never leave the KMS, data keys are sent to it to be wrapped
+// and unwrapped.
+type KMS interface {
+	// Encrypt encrypts the plaintext with the key keyID
+	Encrypt(keyID string, plaintext []byte) ([]byte, error)
+	// Decrypt decrypts the ciphertext with the key keyID
+	Decrypt(keyID string, ciphertext []byte) ([]byte, error)
+}
+
+var (
+	kmsLock sync.Mutex
+	kmsList = make(map[string]KMS)
+)
+
+// RegisterKMS registers a KMS that can be referenced by backup locations
+// using the kms key provider
+func RegisterKMS(name string, kms KMS) error {
+	kmsLock.Lock()
+	defer kmsLock.Unlock()
+	if _, ok := kmsList[name]; ok {
+		return fmt.Errorf("kms %v is already registered", name)
+	}
+	kmsList[name] = kms
+	return nil
+}
+
+// GetKMS returns the KMS registered with the given name
+func GetKMS(name string) (KMS, error) {
+	kmsLock.Lock()
+	defer kmsLock.Unlock()
+	kms, ok := kmsList[name]
+	if !ok {
+		return nil, fmt.Errorf("kms %v is not registered", name)
+	}
+	return kms, nil
+}
+
+// kmsProvider wraps data keys using a registered KMS
+type kmsProvider struct {
+	kms KMS
+}
+
+func (k *kmsProvider) WrapKey(keyID string, dataKey []byte) ([]byte, error) {
+	return k.kms.Encrypt(keyID, dataKey)
+}
+
+func (k *kmsProvider) UnwrapKey(keyID string, wrappedKey []byte) ([]byte, error) {
+	return k.kms.Decrypt(keyID, wrappedKey)
+}
+
+// LocalKMS is a KMS that keeps its keys in memory. It is meant to stand in
+// for an external KMS in tests and development setups.
+type LocalKMS struct {
+	lock sync.Mutex
+	keys map[string][]byte
+}
+
+// NewLocalKMS returns a LocalKMS without any keys
+func NewLocalKMS() *LocalKMS {
+	return &LocalKMS{keys: make(map[string][]byte)}
+}
+
+// AddKey adds a key with the given ID to the KMS
+func (l *LocalKMS) AddKey(keyID string, key []byte) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.keys[keyID] = key
+}
+
+// RemoveKey removes the key with the given ID from the KMS
+func (l *LocalKMS) RemoveKey(keyID string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	delete(l.keys, keyID)
+}
+
+// Encrypt encrypts the plaintext with the key keyID
+func (l *LocalKMS) Encrypt(keyID string, plaintext []byte) ([]byte, error) {
+	key, err := l.getKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.WrapKey(key, plaintext)
+}
+
+// Decrypt decrypts the ciphertext with the key keyID
+func (l *LocalKMS) Decrypt(keyID string, ciphertext []byte) ([]byte, error) {
+	key, err := l.getKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.UnwrapKey(key, ciphertext)
+}
+
+func (l *LocalKMS) getKey(keyID string) ([]byte, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	key, ok := l.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("key %v not found in kms", keyID)
+	}
+	return key, nil
+}
@@ -0,0 +1,44 @@
+package keyprovider
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/libopenstorage/stork/pkg/crypto"
+	"github.com/portworx/sched-ops/k8s/core"
+)
+
+// secretProvider reads key encryption keys from a kubernetes secret. Every
+// key in the secret is a key ID.
+type secretProvider struct {
+	name      string
+	namespace string
+}
+
+func (s *secretProvider) WrapKey(keyID string, dataKey []byte) ([]byte, error) {
+	keyMaterial, err := s.getKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.WrapKey(keyMaterial, dataKey)
+}
+
+func (s *secretProvider) UnwrapKey(keyID string, wrappedKey []byte) ([]byte, error) {
+	keyMaterial, err := s.getKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.UnwrapKey(keyMaterial, wrappedKey)
+}
+
+func (s *secretProvider) getKey(keyID string) ([]byte, error) {
+	secret, err := core.Instance().GetSecret(s.name, s.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error getting secret %v/%v for encryption keys: %v", s.namespace, s.name, err)
+	}
+	keyMaterial, ok := secret.Data[keyID]
+	if !ok || len(keyMaterial) == 0 {
+		return nil, fmt.Errorf("key %v not found in secret %v/%v", keyID, s.namespace, s.name)
+	}
+	return bytes.TrimSuffix(keyMaterial, []byte("\n")), nil
+}
@@ -0,0 +1,84 @@
+package keyprovider
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path"
+
+	"github.com/hashicorp/vault/api"
+)
+
+const (
+	// VaultKMSName is the name the Vault transit secrets engine is
+	// registered with by RegisterVaultKMS
+	VaultKMSName = "vault"
+)
+
+// VaultTransit is a KMS that wraps keys with the transit secrets engine of
+// HashiCorp Vault. The key IDs are the names of the transit keys.
+type VaultTransit struct {
+	client    *api.Client
+	mountPath string
+}
+
+// NewVaultTransit returns a KMS that uses the transit secrets engine mounted
+// at mountPath
+func NewVaultTransit(client *api.Client, mountPath string) *VaultTransit {
+	return &VaultTransit{client: client, mountPath: mountPath}
+}
+
+// RegisterVaultKMS registers the transit secrets engine mounted at mountPath
+// as the "vault" KMS. The address and credentials of Vault are read from the
+// standard VAULT_* environment variables.
+func RegisterVaultKMS(mountPath string) error {
+	client, err := api.NewClient(nil)
+	if err != nil {
+		return fmt.Errorf("error creating vault client: %v", err)
+	}
+	return RegisterKMS(VaultKMSName, NewVaultTransit(client, mountPath))
+}
+
+// Encrypt encrypts the plaintext with the transit key keyID
+func (v *VaultTransit) Encrypt(keyID string, plaintext []byte) ([]byte, error) {
+	ciphertext, err := v.write("encrypt", keyID, "ciphertext", map[string]interface{}{
+		"plaintext": base64.StdEncoding.EncodeToString(plaintext),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []byte(ciphertext), nil
+}
+
+// Decrypt decrypts the ciphertext with the transit key keyID
+func (v *VaultTransit) Decrypt(keyID string, ciphertext []byte) ([]byte, error) {
+	plaintext, err := v.write("decrypt", keyID, "plaintext", map[string]interface{}{
+		"ciphertext": string(ciphertext),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(plaintext)
+}
+
+func (v *VaultTransit) write(
+	operation string,
+	keyID string,
+	field string,
+	data map[string]interface{},
+) (string, error) {
+	if keyID == "" || keyID != path.Base(keyID) {
+		return "", fmt.Errorf("invalid key ID %v", keyID)
+	}
+	secret, err := v.client.Logical().Write(path.Join(v.mountPath, operation, keyID), data)
+	if err != nil {
+		return "", fmt.Errorf("error calling vault transit %v with key %v: %v", operation, keyID, err)
+	}
+	if secret == nil {
+		return "", fmt.Errorf("empty response from vault transit %v with key %v", operation, keyID)
+	}
+	value, ok := secret.Data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("no %v in response from vault transit %v with key %v", field, operation, keyID)
+	}
+	return value, nil
+}
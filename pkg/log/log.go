@@ -289,6 +289,28 @@ func ApplicationBackupScheduleLog(backupSchedule *storkv1.ApplicationBackupSched
 	return logrus.WithFields(logrus.Fields{})
 }
 
+// ApplicationBackupVerificationLog formats a log message with applicationbackupverification information
+func ApplicationBackupVerificationLog(verification *storkv1.ApplicationBackupVerification) *logrus.Entry {
+	if verification != nil {
+		return logrus.WithFields(logrus.Fields{
+			"ApplicationBackupVerificationName": verification.Name,
+			"Namespace":                         verification.Namespace,
+		})
+	}
+	return logrus.WithFields(logrus.Fields{})
+}
+
+// ApplicationActivationLog formats a log message with applicationactivation information
+func ApplicationActivationLog(activation *storkv1.ApplicationActivation) *logrus.Entry {
+	if activation != nil {
+		return logrus.WithFields(logrus.Fields{
+			"ApplicationActivationName": activation.Name,
+			"Namespace":                 activation.Namespace,
+		})
+	}
+	return logrus.WithFields(logrus.Fields{})
+}
+
 // BackupLocationLog formats a log message with backuplocation information
 func BackupLocationLog(location *storkv1.BackupLocation) *logrus.Entry {
 	if location != nil {
@@ -31,6 +31,8 @@ func TestLog(t *testing.T) {
 	t.Run("applicationRestoreLogTest", applicationRestoreLogTest)
 	t.Run("applicationCloneLogTest", applicationCloneLogTest)
 	t.Run("applicationBackupScheduleLogTest", applicationBackupScheduleLogTest)
+	t.Run("applicationBackupVerificationLogTest", applicationBackupVerificationLogTest)
+	t.Run("applicationActivationLogTest", applicationActivationLogTest)
 	t.Run("volumeSnapshotRestoreLogTest", volumeSnapshotRestoreLogTest)
 	t.Run("backupLocationLogTest", backupLocationLogTest)
 }
@@ -273,6 +275,30 @@ func applicationBackupScheduleLogTest(t *testing.T) {
 	ApplicationBackupScheduleLog(nil).Infof("applicationbackupschedule nil log")
 }
 
+func applicationBackupVerificationLogTest(t *testing.T) {
+	metadata := metav1.ObjectMeta{
+		Name:      "testapplicationbackupverification",
+		Namespace: "testnamespace",
+	}
+	verification := &storkv1.ApplicationBackupVerification{
+		ObjectMeta: metadata,
+	}
+	ApplicationBackupVerificationLog(verification).Infof("applicationbackupverification log")
+	ApplicationBackupVerificationLog(nil).Infof("applicationbackupverification nil log")
+}
+
+func applicationActivationLogTest(t *testing.T) {
+	metadata := metav1.ObjectMeta{
+		Name:      "testapplicationactivation",
+		Namespace: "testnamespace",
+	}
+	activation := &storkv1.ApplicationActivation{
+		ObjectMeta: metadata,
+	}
+	ApplicationActivationLog(activation).Infof("applicationactivation log")
+	ApplicationActivationLog(nil).Infof("applicationactivation nil log")
+}
+
 func backupLocationLogTest(t *testing.T) {
 	metadata := metav1.ObjectMeta{
 		Name:      "testbackuplocation",
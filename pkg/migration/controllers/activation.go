@@ -0,0 +1,692 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	activationKindDeployment       = "Deployment"
+	activationKindStatefulSet      = "StatefulSet"
+	activationKindDeploymentConfig = "DeploymentConfig"
+	activationKindCronJob          = "CronJob"
+)
+
+// activationKinds are the built-in kinds of applications that can be
+// activated, in the order they are activated in if a step doesn't specify
+// any kinds. Applications registered with ApplicationRegistrations are
+// activated before CronJobs.
+var activationKinds = []string{activationKindStatefulSet, activationKindDeployment, activationKindDeploymentConfig, activationKindCronJob}
+
+var deploymentConfigResource = schema.GroupVersionResource{Group: "apps.openshift.io", Version: "v1", Resource: "deploymentconfigs"}
+
+// activationResource is an application that is activated or deactivated
+type activationResource struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// appActivator activates and deactivates the applications in a cluster. The
+// number of replicas of deactivated applications is stored in the same
+// annotation that is used by migrations so that they can be activated again.
+// Applications registered with ApplicationRegistrations are suspended with
+// their suspend options, like migrations and storkctl do.
+type appActivator struct {
+	client kubernetes.Interface
+	// dynamicClient is used for DeploymentConfigs and registered
+	// applications
+	dynamicClient dynamic.Interface
+	// registrations are the registered applications that can be suspended,
+	// keyed by kind
+	registrations map[string]stork_api.ApplicationResource
+}
+
+// newAppActivator returns the activator for the cluster of the config
+func newAppActivator(config *rest.Config) (*appActivator, error) {
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	ops, err := storkops.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	registrations, err := getActivationRegistrations(ops)
+	if err != nil {
+		return nil, err
+	}
+	return &appActivator{
+		client:        client,
+		dynamicClient: dynamicClient,
+		registrations: registrations,
+	}, nil
+}
+
+// getActivationRegistrations returns the resources of the
+// ApplicationRegistrations that can be suspended, keyed by kind
+func getActivationRegistrations(ops storkops.Ops) (map[string]stork_api.ApplicationResource, error) {
+	registrations := make(map[string]stork_api.ApplicationResource)
+	appRegs, err := ops.ListApplicationRegistrations()
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return registrations, nil
+		}
+		return nil, fmt.Errorf("error getting application registrations: %v", err)
+	}
+	for _, appReg := range appRegs.Items {
+		for _, resource := range appReg.Resources {
+			if _, ok := registrations[resource.Kind]; ok || isBuiltinActivationKind(resource.Kind) {
+				continue
+			}
+			if len(getSuspendOptions(resource)) > 0 {
+				registrations[resource.Kind] = resource
+			}
+		}
+	}
+	return registrations, nil
+}
+
+func isBuiltinActivationKind(kind string) bool {
+	for _, k := range activationKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// getActivationKinds returns the kinds of applications that can be
+// activated, in the order they are activated in if a step doesn't specify
+// any kinds
+func getActivationKinds(registrations map[string]stork_api.ApplicationResource) []string {
+	registered := make([]string, 0, len(registrations))
+	for kind := range registrations {
+		registered = append(registered, kind)
+	}
+	sort.Strings(registered)
+	kinds := make([]string, 0, len(activationKinds)+len(registered))
+	kinds = append(kinds, activationKinds[:len(activationKinds)-1]...)
+	kinds = append(kinds, registered...)
+	return append(kinds, activationKindCronJob)
+}
+
+func isActivationKindSupported(kind string, registrations map[string]stork_api.ApplicationResource) bool {
+	_, ok := registrations[kind]
+	return ok || isBuiltinActivationKind(kind)
+}
+
+// getSuspendOptions returns the options used to suspend a registered
+// application. Like migrations, only paths to nested fields are supported.
+func getSuspendOptions(resource stork_api.ApplicationResource) []stork_api.SuspendOptions {
+	options := make([]stork_api.SuspendOptions, 0)
+	for _, suspend := range append([]stork_api.SuspendOptions{resource.SuspendOptions}, resource.NestedSuspendOptions...) {
+		if len(strings.Split(suspend.Path, ".")) > 1 {
+			options = append(options, suspend)
+		}
+	}
+	return options
+}
+
+func getRegistrationResource(resource stork_api.ApplicationResource) schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    resource.Group,
+		Version:  resource.Version,
+		Resource: pluralizeKind(resource.Kind),
+	}
+}
+
+// listObjects lists the objects of a resource that isn't built-in. Nothing
+// is returned if the resource isn't installed in the cluster.
+func (a *appActivator) listObjects(
+	resource schema.GroupVersionResource,
+	namespace string,
+	options metav1.ListOptions,
+) ([]unstructured.Unstructured, error) {
+	objects, err := a.dynamicClient.Resource(resource).Namespace(namespace).List(context.TODO(), options)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return objects.Items, nil
+}
+
+// listResources returns the applications of a kind in the namespace that
+// match the selectors. When activating only applications that have been
+// deactivated are returned, and when deactivating only active applications
+// are returned.
+func (a *appActivator) listResources(
+	namespace string,
+	kind string,
+	selectors map[string]string,
+	activate bool,
+) ([]activationResource, error) {
+	options := metav1.ListOptions{LabelSelector: labels.SelectorFromSet(selectors).String()}
+	resources := make([]activationResource, 0)
+	switch kind {
+	case activationKindDeployment:
+		deployments, err := a.client.AppsV1().Deployments(namespace).List(context.TODO(), options)
+		if err != nil {
+			return nil, err
+		}
+		for _, deployment := range deployments.Items {
+			if isActivationCandidate(deployment.Annotations, deployment.Spec.Replicas, activate) {
+				resources = append(resources, activationResource{Kind: kind, Namespace: namespace, Name: deployment.Name})
+			}
+		}
+	case activationKindStatefulSet:
+		statefulSets, err := a.client.AppsV1().StatefulSets(namespace).List(context.TODO(), options)
+		if err != nil {
+			return nil, err
+		}
+		for _, statefulSet := range statefulSets.Items {
+			if isActivationCandidate(statefulSet.Annotations, statefulSet.Spec.Replicas, activate) {
+				resources = append(resources, activationResource{Kind: kind, Namespace: namespace, Name: statefulSet.Name})
+			}
+		}
+	case activationKindDeploymentConfig:
+		deploymentConfigs, err := a.listObjects(deploymentConfigResource, namespace, options)
+		if err != nil {
+			return nil, err
+		}
+		for _, deploymentConfig := range deploymentConfigs {
+			if isActivationCandidate(deploymentConfig.GetAnnotations(), getUnstructuredReplicas(&deploymentConfig), activate) {
+				resources = append(resources, activationResource{Kind: kind, Namespace: namespace, Name: deploymentConfig.GetName()})
+			}
+		}
+	case activationKindCronJob:
+		cronJobs, err := a.client.BatchV1().CronJobs(namespace).List(context.TODO(), options)
+		if err != nil {
+			return nil, err
+		}
+		for _, cronJob := range cronJobs.Items {
+			suspended := cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend
+			if suspended == activate {
+				resources = append(resources, activationResource{Kind: kind, Namespace: namespace, Name: cronJob.Name})
+			}
+		}
+	default:
+		registration, ok := a.registrations[kind]
+		if !ok {
+			return nil, fmt.Errorf("unsupported kind for activation: %v", kind)
+		}
+		objects, err := a.listObjects(getRegistrationResource(registration), namespace, options)
+		if err != nil {
+			return nil, err
+		}
+		suspendOptions := getSuspendOptions(registration)
+		for _, o := range objects {
+			candidate, err := isRegisteredActivationCandidate(&o, suspendOptions, activate)
+			if err != nil {
+				return nil, fmt.Errorf("error checking if %v %v/%v is suspended: %v", kind, namespace, o.GetName(), err)
+			}
+			if candidate {
+				resources = append(resources, activationResource{Kind: kind, Namespace: namespace, Name: o.GetName()})
+			}
+		}
+	}
+	return resources, nil
+}
+
+// isActivationCandidate returns true if an application with replicas can be
+// activated or deactivated
+func isActivationCandidate(annotations map[string]string, replicas *int32, activate bool) bool {
+	if activate {
+		_, ok := annotations[StorkMigrationReplicasAnnotation]
+		return ok
+	}
+	return replicas == nil || *replicas > 0
+}
+
+// isRegisteredActivationCandidate returns true if a registered application
+// can be activated or deactivated. Suspended applications can be activated
+// if the values to activate them with were saved when they were
+// deactivated.
+func isRegisteredActivationCandidate(
+	o *unstructured.Unstructured,
+	suspendOptions []stork_api.SuspendOptions,
+	activate bool,
+) (bool, error) {
+	suspended, err := isSuspended(o, suspendOptions)
+	if err != nil {
+		return false, err
+	}
+	if !activate || !suspended {
+		return activate == suspended, nil
+	}
+	for _, suspend := range suspendOptions {
+		if _, ok, err := getActivatedValue(o.GetAnnotations(), suspend); err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// isSuspended returns true if all the suspend fields of a registered
+// application are set to their suspended values
+func isSuspended(o *unstructured.Unstructured, suspendOptions []stork_api.SuspendOptions) (bool, error) {
+	for _, suspend := range suspendOptions {
+		suspended, err := getSuspendedValue(suspend)
+		if err != nil {
+			return false, err
+		}
+		value, found, err := unstructured.NestedFieldNoCopy(o.Object, strings.Split(suspend.Path, ".")...)
+		if err != nil {
+			return false, err
+		}
+		if !found || !reflect.DeepEqual(value, suspended) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// getSuspendedValue returns the value that a suspend field is set to when a
+// registered application is deactivated
+func getSuspendedValue(suspend stork_api.SuspendOptions) (interface{}, error) {
+	switch suspend.Type {
+	case "bool":
+		if val, err := strconv.ParseBool(suspend.Value); err == nil {
+			return val, nil
+		}
+		return true, nil
+	case "int":
+		return int64(0), nil
+	case "string":
+		return suspend.Value, nil
+	}
+	return nil, fmt.Errorf("invalid type %v to suspend cr", suspend.Type)
+}
+
+// getActivatedValue returns the value that a suspend field is set to when a
+// registered application is activated. The values of int and string fields
+// are saved in an annotation when the application is deactivated or
+// migrated.
+func getActivatedValue(annotations map[string]string, suspend stork_api.SuspendOptions) (interface{}, bool, error) {
+	if suspend.Type == "bool" {
+		if val, err := strconv.ParseBool(suspend.Value); err == nil {
+			return !val, true, nil
+		}
+		return false, true, nil
+	}
+	value, ok := annotations[StorkAnnotationPrefix+suspend.Path]
+	if ok {
+		value = strings.Split(value, ",")[0]
+	} else if value, ok = annotations[StorkMigrationCRDActivateAnnotation]; !ok {
+		// The annotation is used by CRs migrated by older versions
+		return nil, false, nil
+	}
+	switch suspend.Type {
+	case "int":
+		replicas, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid value to activate %v: %v", suspend.Path, err)
+		}
+		return replicas, true, nil
+	case "string":
+		return value, true, nil
+	}
+	return nil, false, fmt.Errorf("invalid type %v to suspend cr", suspend.Type)
+}
+
+// activate scales up or resumes an application
+func (a *appActivator) activate(resource activationResource) error {
+	switch resource.Kind {
+	case activationKindDeployment:
+		deployments := a.client.AppsV1().Deployments(resource.Namespace)
+		deployment, err := deployments.Get(context.TODO(), resource.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		replicas, err := getActivationReplicas(deployment.Annotations)
+		if err != nil {
+			return err
+		}
+		deployment.Spec.Replicas = &replicas
+		_, err = deployments.Update(context.TODO(), deployment, metav1.UpdateOptions{})
+		return err
+	case activationKindStatefulSet:
+		statefulSets := a.client.AppsV1().StatefulSets(resource.Namespace)
+		statefulSet, err := statefulSets.Get(context.TODO(), resource.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		replicas, err := getActivationReplicas(statefulSet.Annotations)
+		if err != nil {
+			return err
+		}
+		statefulSet.Spec.Replicas = &replicas
+		_, err = statefulSets.Update(context.TODO(), statefulSet, metav1.UpdateOptions{})
+		return err
+	case activationKindDeploymentConfig:
+		deploymentConfigs := a.dynamicClient.Resource(deploymentConfigResource).Namespace(resource.Namespace)
+		deploymentConfig, err := deploymentConfigs.Get(context.TODO(), resource.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		replicas, err := getActivationReplicas(deploymentConfig.GetAnnotations())
+		if err != nil {
+			return err
+		}
+		if err := unstructured.SetNestedField(deploymentConfig.Object, int64(replicas), "spec", "replicas"); err != nil {
+			return err
+		}
+		_, err = deploymentConfigs.Update(context.TODO(), deploymentConfig, metav1.UpdateOptions{})
+		return err
+	case activationKindCronJob:
+		return a.suspendCronJob(resource, false)
+	}
+	if registration, ok := a.registrations[resource.Kind]; ok {
+		return a.activateRegistered(resource, registration)
+	}
+	return fmt.Errorf("unsupported kind for activation: %v", resource.Kind)
+}
+
+// activateRegistered sets the suspend fields of a registered application to
+// the values they had before it was deactivated
+func (a *appActivator) activateRegistered(resource activationResource, registration stork_api.ApplicationResource) error {
+	client := a.dynamicClient.Resource(getRegistrationResource(registration)).Namespace(resource.Namespace)
+	o, err := client.Get(context.TODO(), resource.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	for _, suspend := range getSuspendOptions(registration) {
+		value, ok, err := getActivatedValue(o.GetAnnotations(), suspend)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("annotation %v with the value to activate %v not found", StorkAnnotationPrefix+suspend.Path, suspend.Path)
+		}
+		if err := unstructured.SetNestedField(o.Object, value, strings.Split(suspend.Path, ".")...); err != nil {
+			return err
+		}
+	}
+	_, err = client.Update(context.TODO(), o, metav1.UpdateOptions{})
+	return err
+}
+
+// deactivate scales down or suspends an application, saving the number of
+// replicas it had
+func (a *appActivator) deactivate(resource activationResource) error {
+	switch resource.Kind {
+	case activationKindDeployment:
+		deployments := a.client.AppsV1().Deployments(resource.Namespace)
+		deployment, err := deployments.Get(context.TODO(), resource.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		deployment.Annotations = setDeactivationReplicas(deployment.Annotations, deployment.Spec.Replicas)
+		replicas := int32(0)
+		deployment.Spec.Replicas = &replicas
+		_, err = deployments.Update(context.TODO(), deployment, metav1.UpdateOptions{})
+		return err
+	case activationKindStatefulSet:
+		statefulSets := a.client.AppsV1().StatefulSets(resource.Namespace)
+		statefulSet, err := statefulSets.Get(context.TODO(), resource.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		statefulSet.Annotations = setDeactivationReplicas(statefulSet.Annotations, statefulSet.Spec.Replicas)
+		replicas := int32(0)
+		statefulSet.Spec.Replicas = &replicas
+		_, err = statefulSets.Update(context.TODO(), statefulSet, metav1.UpdateOptions{})
+		return err
+	case activationKindDeploymentConfig:
+		deploymentConfigs := a.dynamicClient.Resource(deploymentConfigResource).Namespace(resource.Namespace)
+		deploymentConfig, err := deploymentConfigs.Get(context.TODO(), resource.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		deploymentConfig.SetAnnotations(setDeactivationReplicas(deploymentConfig.GetAnnotations(), getUnstructuredReplicas(deploymentConfig)))
+		if err := unstructured.SetNestedField(deploymentConfig.Object, int64(0), "spec", "replicas"); err != nil {
+			return err
+		}
+		_, err = deploymentConfigs.Update(context.TODO(), deploymentConfig, metav1.UpdateOptions{})
+		return err
+	case activationKindCronJob:
+		return a.suspendCronJob(resource, true)
+	}
+	if registration, ok := a.registrations[resource.Kind]; ok {
+		return a.deactivateRegistered(resource, registration)
+	}
+	return fmt.Errorf("unsupported kind for activation: %v", resource.Kind)
+}
+
+// deactivateRegistered sets the suspend fields of a registered application
+// to their suspended values, saving the current values in the same
+// annotations that are used by migrations. The pods of the application are
+// deleted if the registration has a path to them, like storkctl does.
+func (a *appActivator) deactivateRegistered(resource activationResource, registration stork_api.ApplicationResource) error {
+	client := a.dynamicClient.Resource(getRegistrationResource(registration)).Namespace(resource.Namespace)
+	o, err := client.Get(context.TODO(), resource.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	annotations := o.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	for _, suspend := range getSuspendOptions(registration) {
+		fields := strings.Split(suspend.Path, ".")
+		suspended, err := getSuspendedValue(suspend)
+		if err != nil {
+			return err
+		}
+		currVal := ""
+		if suspend.Type != "bool" {
+			current, found, err := unstructured.NestedFieldNoCopy(o.Object, fields...)
+			if err != nil {
+				return err
+			}
+			if found {
+				currVal = fmt.Sprintf("%v", current)
+			}
+		}
+		if err := unstructured.SetNestedField(o.Object, suspended, fields...); err != nil {
+			return err
+		}
+		// path : activate/deactivate value
+		annotations[StorkAnnotationPrefix+suspend.Path] = currVal + "," + suspend.Value
+	}
+	o.SetAnnotations(annotations)
+	if o, err = client.Update(context.TODO(), o, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+
+	if registration.PodsPath == "" {
+		return nil
+	}
+	pods, found, err := unstructured.NestedStringSlice(o.Object, strings.Split(registration.PodsPath, ".")...)
+	if err != nil || !found {
+		return err
+	}
+	for _, pod := range pods {
+		err := a.client.CoreV1().Pods(resource.Namespace).Delete(context.TODO(), pod, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("error deleting pod %v: %v", pod, err)
+		}
+	}
+	return nil
+}
+
+func (a *appActivator) suspendCronJob(resource activationResource, suspend bool) error {
+	cronJobs := a.client.BatchV1().CronJobs(resource.Namespace)
+	cronJob, err := cronJobs.Get(context.TODO(), resource.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	cronJob.Spec.Suspend = &suspend
+	_, err = cronJobs.Update(context.TODO(), cronJob, metav1.UpdateOptions{})
+	return err
+}
+
+// isReady returns true if all the replicas of an activated application are
+// ready
+func (a *appActivator) isReady(resource activationResource) (bool, error) {
+	switch resource.Kind {
+	case activationKindDeployment:
+		deployment, err := a.client.AppsV1().Deployments(resource.Namespace).Get(context.TODO(), resource.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return deployment.Status.ObservedGeneration >= deployment.Generation &&
+			deployment.Status.ReadyReplicas >= getReplicas(deployment.Spec.Replicas), nil
+	case activationKindStatefulSet:
+		statefulSet, err := a.client.AppsV1().StatefulSets(resource.Namespace).Get(context.TODO(), resource.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return statefulSet.Status.ObservedGeneration >= statefulSet.Generation &&
+			statefulSet.Status.ReadyReplicas >= getReplicas(statefulSet.Spec.Replicas), nil
+	case activationKindDeploymentConfig:
+		deploymentConfig, err := a.dynamicClient.Resource(deploymentConfigResource).Namespace(resource.Namespace).
+			Get(context.TODO(), resource.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		observedGeneration, _, err := unstructured.NestedInt64(deploymentConfig.Object, "status", "observedGeneration")
+		if err != nil {
+			return false, err
+		}
+		readyReplicas, _, err := unstructured.NestedInt64(deploymentConfig.Object, "status", "readyReplicas")
+		if err != nil {
+			return false, err
+		}
+		return observedGeneration >= deploymentConfig.GetGeneration() &&
+			readyReplicas >= int64(getReplicas(getUnstructuredReplicas(deploymentConfig))), nil
+	}
+	// Nothing to wait for with cronjobs, and the readiness of registered
+	// applications isn't known
+	return true, nil
+}
+
+// isStopped returns true if a deactivated application doesn't have any pods
+// left, including ones that are still terminating. CronJobs and registered
+// applications are stopped once they have been suspended.
+func (a *appActivator) isStopped(resource activationResource) (bool, error) {
+	switch resource.Kind {
+	case activationKindDeployment:
+		deployment, err := a.client.AppsV1().Deployments(resource.Namespace).Get(context.TODO(), resource.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if deployment.Status.ObservedGeneration < deployment.Generation || deployment.Status.Replicas > 0 {
+			return false, nil
+		}
+		return a.hasNoPods(resource.Namespace, deployment.Spec.Selector)
+	case activationKindStatefulSet:
+		statefulSet, err := a.client.AppsV1().StatefulSets(resource.Namespace).Get(context.TODO(), resource.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if statefulSet.Status.ObservedGeneration < statefulSet.Generation || statefulSet.Status.Replicas > 0 {
+			return false, nil
+		}
+		return a.hasNoPods(resource.Namespace, statefulSet.Spec.Selector)
+	case activationKindDeploymentConfig:
+		deploymentConfig, err := a.dynamicClient.Resource(deploymentConfigResource).Namespace(resource.Namespace).
+			Get(context.TODO(), resource.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		observedGeneration, _, err := unstructured.NestedInt64(deploymentConfig.Object, "status", "observedGeneration")
+		if err != nil {
+			return false, err
+		}
+		replicas, _, err := unstructured.NestedInt64(deploymentConfig.Object, "status", "replicas")
+		if err != nil {
+			return false, err
+		}
+		if observedGeneration < deploymentConfig.GetGeneration() || replicas > 0 {
+			return false, nil
+		}
+		selector, _, err := unstructured.NestedStringMap(deploymentConfig.Object, "spec", "selector")
+		if err != nil {
+			return false, err
+		}
+		return a.hasNoPods(resource.Namespace, &metav1.LabelSelector{MatchLabels: selector})
+	}
+	return true, nil
+}
+
+// hasNoPods returns true if none of the pods in the namespace match the
+// selector of an application. Nothing is checked for empty selectors, which
+// would match all the pods.
+func (a *appActivator) hasNoPods(namespace string, labelSelector *metav1.LabelSelector) (bool, error) {
+	if labelSelector == nil {
+		return true, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return false, err
+	}
+	if selector.Empty() {
+		return true, nil
+	}
+	pods, err := a.client.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return false, err
+	}
+	return len(pods.Items) == 0, nil
+}
+
+func getActivationReplicas(annotations map[string]string) (int32, error) {
+	value, ok := annotations[StorkMigrationReplicasAnnotation]
+	if !ok {
+		return 0, fmt.Errorf("annotation %v with the number of replicas not found", StorkMigrationReplicasAnnotation)
+	}
+	replicas, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number of replicas in annotation %v: %v", StorkMigrationReplicasAnnotation, err)
+	}
+	return int32(replicas), nil
+}
+
+func setDeactivationReplicas(annotations map[string]string, replicas *int32) map[string]string {
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[StorkMigrationReplicasAnnotation] = strconv.Itoa(int(getReplicas(replicas)))
+	return annotations
+}
+
+// getUnstructuredReplicas returns the number of replicas in the spec of an
+// object, or nil if not set
+func getUnstructuredReplicas(o *unstructured.Unstructured) *int32 {
+	replicas, found, err := unstructured.NestedInt64(o.Object, "spec", "replicas")
+	if err != nil || !found {
+		return nil
+	}
+	r := int32(replicas)
+	return &r
+}
+
+// getReplicas returns the number of replicas, which defaults to 1 if not set
+func getReplicas(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
@@ -0,0 +1,405 @@
+//go:build unittest
+// +build unittest
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/appregistration"
+	fakeclient "github.com/libopenstorage/stork/pkg/client/clientset/versioned/fake"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func newActivationTestClient() *fake.Clientset {
+	suspend := false
+	return fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app", Labels: map[string]string{"tier": "web"}},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "app", Labels: map[string]string{"tier": "db"}},
+			Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(2)},
+		},
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "report", Namespace: "app"},
+			Spec:       batchv1.CronJobSpec{Suspend: &suspend},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "cb-0", Namespace: "app"},
+		},
+	)
+}
+
+func newActivationTestObject(apiVersion, kind, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": name, "namespace": "app"},
+		"spec":       spec,
+	}}
+}
+
+func newActivationTestDynamicClient(registrations map[string]stork_api.ApplicationResource) *fakedynamic.FakeDynamicClient {
+	listKinds := map[schema.GroupVersionResource]string{deploymentConfigResource: "DeploymentConfigList"}
+	for kind, registration := range registrations {
+		listKinds[getRegistrationResource(registration)] = kind + "List"
+	}
+	couchbase := newActivationTestObject("couchbase.com/v2", "CouchbaseCluster", "cb", map[string]interface{}{"paused": false})
+	couchbase.Object["status"] = map[string]interface{}{
+		"members": map[string]interface{}{"ready": []interface{}{"cb-0"}},
+	}
+	return fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds,
+		newActivationTestObject("apps.openshift.io/v1", "DeploymentConfig", "frontend", map[string]interface{}{"replicas": int64(2)}),
+		newActivationTestObject("ibp.com/v1alpha1", "IBPPeer", "peer", map[string]interface{}{"replicas": int64(1)}),
+		newActivationTestObject("kubevirt.io/v1", "VirtualMachine", "vm", map[string]interface{}{"runStrategy": "Always"}),
+		couchbase,
+	)
+}
+
+// newActivationTestRegistrations returns the registrations of the default
+// ApplicationRegistrations for IBP, Couchbase and VirtualMachines
+func newActivationTestRegistrations(t *testing.T) map[string]stork_api.ApplicationResource {
+	objects := make([]runtime.Object, 0)
+	for _, name := range []string{appregistration.IBMApp, appregistration.CouchBaseApp, appregistration.VirtualMachineApp} {
+		objects = append(objects, &stork_api.ApplicationRegistration{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Resources:  appregistration.GetSupportedCRD()[name],
+		})
+	}
+	ops := storkops.New(fake.NewSimpleClientset(), fakeclient.NewSimpleClientset(objects...), nil)
+	registrations, err := getActivationRegistrations(ops)
+	require.NoError(t, err, "Error getting registrations")
+	return registrations
+}
+
+func newTestActivator(t *testing.T) *appActivator {
+	registrations := newActivationTestRegistrations(t)
+	return &appActivator{
+		client:        newActivationTestClient(),
+		dynamicClient: newActivationTestDynamicClient(registrations),
+		registrations: registrations,
+	}
+}
+
+func TestGetActivationRegistrations(t *testing.T) {
+	registrations := newActivationTestRegistrations(t)
+	// Resources without suspend options can't be activated
+	require.NotContains(t, registrations, "CouchbaseBucket")
+	require.Equal(t, []string{
+		activationKindStatefulSet,
+		activationKindDeployment,
+		activationKindDeploymentConfig,
+		"CouchbaseCluster",
+		"IBPCA",
+		"IBPConsole",
+		"IBPOrderer",
+		"IBPPeer",
+		"VirtualMachine",
+		activationKindCronJob,
+	}, getActivationKinds(registrations))
+	require.Equal(t, "ibppeers", getRegistrationResource(registrations["IBPPeer"]).Resource)
+}
+
+func TestActivatorRoundTrip(t *testing.T) {
+	activator := newTestActivator(t)
+	client := activator.client
+	dynamicClient := activator.dynamicClient
+	kinds := []string{
+		activationKindStatefulSet,
+		activationKindDeployment,
+		activationKindDeploymentConfig,
+		"CouchbaseCluster",
+		"IBPPeer",
+		"VirtualMachine",
+		activationKindCronJob,
+	}
+
+	// Nothing has been deactivated yet
+	for _, kind := range kinds {
+		resources, err := activator.listResources("app", kind, nil, true)
+		require.NoError(t, err, "Error listing %v", kind)
+		require.Empty(t, resources, "Unexpected %v to activate", kind)
+	}
+
+	for _, kind := range kinds {
+		resources, err := activator.listResources("app", kind, nil, false)
+		require.NoError(t, err, "Error listing %v", kind)
+		require.Len(t, resources, 1, "Expected one %v to deactivate", kind)
+		require.NoError(t, activator.deactivate(resources[0]), "Error deactivating %v", kind)
+	}
+
+	deployment, err := client.AppsV1().Deployments("app").Get(context.TODO(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, int32(0), *deployment.Spec.Replicas)
+	require.Equal(t, "3", deployment.Annotations[StorkMigrationReplicasAnnotation])
+	cronJob, err := client.BatchV1().CronJobs("app").Get(context.TODO(), "report", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.True(t, *cronJob.Spec.Suspend)
+	deploymentConfig, err := dynamicClient.Resource(deploymentConfigResource).Namespace("app").Get(context.TODO(), "frontend", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, int64(0), deploymentConfig.Object["spec"].(map[string]interface{})["replicas"])
+	require.Equal(t, "2", deploymentConfig.GetAnnotations()[StorkMigrationReplicasAnnotation])
+	vm, err := dynamicClient.Resource(getRegistrationResource(activator.registrations["VirtualMachine"])).Namespace("app").Get(context.TODO(), "vm", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "Halted", vm.Object["spec"].(map[string]interface{})["runStrategy"])
+	require.Equal(t, "Always,Halted", vm.GetAnnotations()[StorkAnnotationPrefix+"spec.runStrategy"])
+	couchbase, err := dynamicClient.Resource(getRegistrationResource(activator.registrations["CouchbaseCluster"])).Namespace("app").Get(context.TODO(), "cb", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, true, couchbase.Object["spec"].(map[string]interface{})["paused"])
+	// The pods of the application are deleted
+	_, err = client.CoreV1().Pods("app").Get(context.TODO(), "cb-0", metav1.GetOptions{})
+	require.True(t, k8s_errors.IsNotFound(err), "Expected pod to be deleted: %v", err)
+
+	for _, kind := range kinds {
+		resources, err := activator.listResources("app", kind, nil, true)
+		require.NoError(t, err, "Error listing %v", kind)
+		require.Len(t, resources, 1, "Expected one %v to activate", kind)
+		require.NoError(t, activator.activate(resources[0]), "Error activating %v", kind)
+	}
+
+	statefulSet, err := client.AppsV1().StatefulSets("app").Get(context.TODO(), "db", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), *statefulSet.Spec.Replicas)
+	cronJob, err = client.BatchV1().CronJobs("app").Get(context.TODO(), "report", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.False(t, *cronJob.Spec.Suspend)
+	deploymentConfig, err = dynamicClient.Resource(deploymentConfigResource).Namespace("app").Get(context.TODO(), "frontend", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), deploymentConfig.Object["spec"].(map[string]interface{})["replicas"])
+	peer, err := dynamicClient.Resource(getRegistrationResource(activator.registrations["IBPPeer"])).Namespace("app").Get(context.TODO(), "peer", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), peer.Object["spec"].(map[string]interface{})["replicas"])
+	vm, err = dynamicClient.Resource(getRegistrationResource(activator.registrations["VirtualMachine"])).Namespace("app").Get(context.TODO(), "vm", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "Always", vm.Object["spec"].(map[string]interface{})["runStrategy"])
+	couchbase, err = dynamicClient.Resource(getRegistrationResource(activator.registrations["CouchbaseCluster"])).Namespace("app").Get(context.TODO(), "cb", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, false, couchbase.Object["spec"].(map[string]interface{})["paused"])
+
+	// Activated applications aren't activated again
+	for _, kind := range kinds[3:6] {
+		resources, err := activator.listResources("app", kind, nil, true)
+		require.NoError(t, err, "Error listing %v", kind)
+		require.Empty(t, resources, "Unexpected %v to activate", kind)
+	}
+}
+
+func TestActivatorIsReady(t *testing.T) {
+	activator := newTestActivator(t)
+	client := activator.client
+	resource := activationResource{Kind: activationKindDeployment, Namespace: "app", Name: "web"}
+
+	ready, err := activator.isReady(resource)
+	require.NoError(t, err)
+	require.False(t, ready, "Deployment shouldn't be ready without ready replicas")
+
+	deployment, err := client.AppsV1().Deployments("app").Get(context.TODO(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	deployment.Status.ReadyReplicas = 3
+	_, err = client.AppsV1().Deployments("app").Update(context.TODO(), deployment, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	ready, err = activator.isReady(resource)
+	require.NoError(t, err)
+	require.True(t, ready, "Deployment should be ready")
+
+	ready, err = activator.isReady(activationResource{Kind: activationKindCronJob, Namespace: "app", Name: "report"})
+	require.NoError(t, err)
+	require.True(t, ready, "CronJobs should always be ready")
+
+	resource = activationResource{Kind: activationKindDeploymentConfig, Namespace: "app", Name: "frontend"}
+	ready, err = activator.isReady(resource)
+	require.NoError(t, err)
+	require.False(t, ready, "DeploymentConfig shouldn't be ready without ready replicas")
+	deploymentConfigs := activator.dynamicClient.Resource(deploymentConfigResource).Namespace("app")
+	deploymentConfig, err := deploymentConfigs.Get(context.TODO(), "frontend", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NoError(t, unstructured.SetNestedField(deploymentConfig.Object, int64(2), "status", "readyReplicas"))
+	_, err = deploymentConfigs.Update(context.TODO(), deploymentConfig, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	ready, err = activator.isReady(resource)
+	require.NoError(t, err)
+	require.True(t, ready, "DeploymentConfig should be ready")
+}
+
+func TestActivatorIsStopped(t *testing.T) {
+	activator := newTestActivator(t)
+	client := activator.client
+	resource := activationResource{Kind: activationKindDeployment, Namespace: "app", Name: "web"}
+
+	deployment, err := client.AppsV1().Deployments("app").Get(context.TODO(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	deployment.Status.Replicas = 1
+	deployment, err = client.AppsV1().Deployments("app").Update(context.TODO(), deployment, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	stopped, err := activator.isStopped(resource)
+	require.NoError(t, err)
+	require.False(t, stopped, "Deployment with replicas shouldn't be stopped")
+
+	// Pods that are still terminating need to be gone too
+	deployment.Status.Replicas = 0
+	_, err = client.AppsV1().Deployments("app").Update(context.TODO(), deployment, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	_, err = client.CoreV1().Pods("app").Create(context.TODO(), &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "app", Labels: map[string]string{"app": "web"}},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	stopped, err = activator.isStopped(resource)
+	require.NoError(t, err)
+	require.False(t, stopped, "Deployment with pods shouldn't be stopped")
+
+	require.NoError(t, client.CoreV1().Pods("app").Delete(context.TODO(), "web-0", metav1.DeleteOptions{}))
+	stopped, err = activator.isStopped(resource)
+	require.NoError(t, err)
+	require.True(t, stopped, "Deployment should be stopped")
+
+	stopped, err = activator.isStopped(activationResource{Kind: activationKindCronJob, Namespace: "app", Name: "report"})
+	require.NoError(t, err)
+	require.True(t, stopped, "CronJobs should be stopped once suspended")
+}
+
+func TestNewFailbackMigration(t *testing.T) {
+	activation := &stork_api.ApplicationActivation{
+		ObjectMeta: metav1.ObjectMeta{Name: "failback", Namespace: "app", UID: "uid"},
+		Spec: stork_api.ApplicationActivationSpec{
+			Operation:   stork_api.ApplicationActivationOperationFailback,
+			ClusterPair: "remote",
+			Namespaces:  []string{"app"},
+		},
+	}
+	migration := newFailbackMigration(activation)
+	require.Equal(t, "failback-failback", migration.Name)
+	require.Equal(t, "app", migration.Namespace)
+	require.Len(t, migration.OwnerReferences, 1)
+	require.Equal(t, "ApplicationActivation", migration.OwnerReferences[0].Kind)
+	require.Equal(t, "remote", migration.Spec.ClusterPair)
+	require.Equal(t, []string{"app"}, migration.Spec.Namespaces)
+	require.True(t, *migration.Spec.IncludeVolumes)
+	require.False(t, *migration.Spec.StartApplications, "Applications should be activated by the failback")
+}
+
+func TestPrepareApplicationResourceKeepsDeactivatedReplicas(t *testing.T) {
+	startApplications := false
+	migration := &stork_api.Migration{Spec: stork_api.MigrationSpec{StartApplications: &startApplications}}
+	object := newActivationTestObject("apps/v1", "Deployment", "web", map[string]interface{}{"replicas": int64(0)})
+	object.SetAnnotations(map[string]string{StorkMigrationReplicasAnnotation: "3"})
+	m := &MigrationController{}
+	require.NoError(t, m.prepareApplicationResource(migration, &stork_api.ClusterPair{}, object))
+	require.Equal(t, "3", object.GetAnnotations()[StorkMigrationReplicasAnnotation],
+		"Replicas of deactivated applications should be kept")
+
+	object = newActivationTestObject("apps/v1", "Deployment", "web", map[string]interface{}{"replicas": int64(2)})
+	object.SetAnnotations(map[string]string{StorkMigrationReplicasAnnotation: "3"})
+	require.NoError(t, m.prepareApplicationResource(migration, &stork_api.ClusterPair{}, object))
+	require.Equal(t, "2", object.GetAnnotations()[StorkMigrationReplicasAnnotation])
+	replicas, _, err := unstructured.NestedInt64(object.Object, "spec", "replicas")
+	require.NoError(t, err)
+	require.Equal(t, int64(0), replicas)
+}
+
+func TestListStepResources(t *testing.T) {
+	activator := newTestActivator(t)
+	activation := &stork_api.ApplicationActivation{
+		Spec: stork_api.ApplicationActivationSpec{
+			ClusterPair: "remote",
+			Namespaces:  []string{"app"},
+			Steps: []stork_api.ApplicationActivationStep{
+				{Name: "databases", Selectors: map[string]string{"tier": "db"}},
+				{Name: "rest"},
+			},
+		},
+	}
+
+	resources, err := listStepResources(activation, activator, activationClusterLocal, 0, activation.Spec.Steps[0], false)
+	require.NoError(t, err)
+	require.Equal(t, []activationResource{{Kind: activationKindStatefulSet, Namespace: "app", Name: "db"}}, resources)
+
+	// The statefulset was claimed by the first step
+	resources, err = listStepResources(activation, activator, activationClusterLocal, 1, activation.Spec.Steps[1], false)
+	require.NoError(t, err)
+	require.Equal(t, []activationResource{
+		{Kind: activationKindDeployment, Namespace: "app", Name: "web"},
+		{Kind: activationKindDeploymentConfig, Namespace: "app", Name: "frontend"},
+		{Kind: "CouchbaseCluster", Namespace: "app", Name: "cb"},
+		{Kind: "IBPPeer", Namespace: "app", Name: "peer"},
+		{Kind: "VirtualMachine", Namespace: "app", Name: "vm"},
+		{Kind: activationKindCronJob, Namespace: "app", Name: "report"},
+	}, resources)
+
+	// Resources that have already been handled are skipped
+	for _, resource := range resources[:5] {
+		setActivationResourceInfo(activation, newActivationResourceInfo(resource, activationClusterLocal, "rest", false))
+	}
+	resources, err = listStepResources(activation, activator, activationClusterLocal, 1, activation.Spec.Steps[1], false)
+	require.NoError(t, err)
+	require.Equal(t, []activationResource{{Kind: activationKindCronJob, Namespace: "app", Name: "report"}}, resources)
+}
+
+func TestSetActivationResourceInfo(t *testing.T) {
+	activation := &stork_api.ApplicationActivation{}
+	resource := activationResource{Kind: activationKindDeployment, Namespace: "app", Name: "web"}
+	resourceInfo := newActivationResourceInfo(resource, activationClusterRemote, "default", false)
+	resourceInfo.Status = stork_api.ApplicationActivationStatusFailed
+	setActivationResourceInfo(activation, resourceInfo)
+
+	// Running the stage again replaces the result
+	resourceInfo = newActivationResourceInfo(resource, activationClusterRemote, "default", false)
+	resourceInfo.Status = stork_api.ApplicationActivationStatusSuccessful
+	setActivationResourceInfo(activation, resourceInfo)
+	require.Len(t, activation.Status.Resources, 1)
+	require.Equal(t, stork_api.ApplicationActivationStatusSuccessful, activation.Status.Resources[0].Status)
+
+	// The results for other clusters and operations are kept
+	setActivationResourceInfo(activation, newActivationResourceInfo(resource, activationClusterLocal, "default", false))
+	setActivationResourceInfo(activation, newActivationResourceInfo(resource, activationClusterLocal, "default", true))
+	require.Len(t, activation.Status.Resources, 3)
+}
+
+func TestValidateApplicationActivation(t *testing.T) {
+	activation := &stork_api.ApplicationActivation{
+		Spec: stork_api.ApplicationActivationSpec{
+			ClusterPair: "remote",
+			Namespaces:  []string{"app"},
+			Steps:       []stork_api.ApplicationActivationStep{{Kinds: []string{activationKindStatefulSet}}},
+		},
+	}
+	registrations := newActivationTestRegistrations(t)
+	require.NoError(t, validateApplicationActivation(activation, registrations))
+
+	activation.Spec.Steps[0].Kinds = []string{"DaemonSet"}
+	require.Error(t, validateApplicationActivation(activation, registrations), "Expected error for unsupported kind")
+	activation.Spec.Steps[0].Kinds = []string{activationKindDeploymentConfig, "VirtualMachine"}
+	require.NoError(t, validateApplicationActivation(activation, registrations))
+	activation.Spec.Steps[0].Kinds = nil
+
+	activation.Spec.Operation = stork_api.ApplicationActivationOperationFailback
+	activation.Spec.SkipRemoteDeactivation = true
+	require.Error(t, validateApplicationActivation(activation, registrations), "Expected error skipping deactivation on failback")
+	activation.Spec.SkipRemoteDeactivation = false
+
+	activation.Spec.Operation = "Switchover"
+	require.Error(t, validateApplicationActivation(activation, registrations), "Expected error for unsupported operation")
+	activation.Spec.Operation = ""
+
+	activation.Spec.Namespaces = nil
+	require.Error(t, validateApplicationActivation(activation, registrations), "Expected error without namespaces")
+}
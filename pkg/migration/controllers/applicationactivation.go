@@ -0,0 +1,719 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/controllers"
+	"github.com/libopenstorage/stork/pkg/k8sutils"
+	"github.com/libopenstorage/stork/pkg/log"
+	"github.com/libopenstorage/stork/pkg/version"
+	"github.com/portworx/sched-ops/k8s/apiextensions"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	defaultActivationReadinessTimeout = 5 * time.Minute
+	activationClusterLocal            = "Local"
+	activationClusterRemote           = "Remote"
+	activationKindMigrationSchedule   = "MigrationSchedule"
+)
+
+// NewApplicationActivation creates a new instance of ApplicationActivationController.
+func NewApplicationActivation(mgr manager.Manager, r record.EventRecorder) *ApplicationActivationController {
+	return &ApplicationActivationController{
+		client:   mgr.GetClient(),
+		recorder: r,
+	}
+}
+
+// ApplicationActivationController reconciles ApplicationActivation objects
+type ApplicationActivationController struct {
+	client runtimeclient.Client
+
+	recorder record.EventRecorder
+	config   *rest.Config
+}
+
+// Init Initialize the application activation controller
+func (a *ApplicationActivationController) Init(mgr manager.Manager) error {
+	err := a.createCRD()
+	if err != nil {
+		return err
+	}
+	a.config = mgr.GetConfig()
+
+	return controllers.RegisterTo(mgr, "application-activation-controller", a, &stork_api.ApplicationActivation{})
+}
+
+// Reconcile updates for ApplicationActivation objects.
+func (a *ApplicationActivationController) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	logrus.Tracef("Reconciling ApplicationActivation %s/%s", request.Namespace, request.Name)
+
+	activation := &stork_api.ApplicationActivation{}
+	err := a.client.Get(context.TODO(), request.NamespacedName, activation)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// Return and don't requeue
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{RequeueAfter: controllers.DefaultRequeueError}, err
+	}
+
+	if err = a.handle(context.TODO(), activation); err != nil {
+		logrus.Errorf("%s: %s/%s: %s", reflect.TypeOf(a), activation.Namespace, activation.Name, err)
+		return reconcile.Result{RequeueAfter: controllers.DefaultRequeueError}, err
+	}
+
+	return reconcile.Result{RequeueAfter: controllers.DefaultRequeue}, nil
+}
+
+// Handle updates for ApplicationActivation objects
+func (a *ApplicationActivationController) handle(ctx context.Context, activation *stork_api.ApplicationActivation) error {
+	if activation.DeletionTimestamp != nil {
+		return nil
+	}
+
+	switch activation.Status.Stage {
+	case stork_api.ApplicationActivationStageInitial:
+		registrations, err := getActivationRegistrations(storkops.Instance())
+		if err != nil {
+			return err
+		}
+		if err := validateApplicationActivation(activation, registrations); err != nil {
+			return a.fail(activation, fmt.Sprintf("Invalid spec: %v", err))
+		}
+		if activation.Spec.Operation == "" {
+			activation.Spec.Operation = stork_api.ApplicationActivationOperationFailover
+		}
+		activation.Status.Stage = stork_api.ApplicationActivationStageSchedule
+		activation.Status.Status = stork_api.ApplicationActivationStatusInProgress
+		return a.client.Update(ctx, activation)
+	case stork_api.ApplicationActivationStageSchedule:
+		// The migration schedule is only resumed once the applications have
+		// been failed back
+		if activation.Spec.Operation == stork_api.ApplicationActivationOperationFailover {
+			if err := a.updateMigrationSchedule(activation); err != nil {
+				return a.fail(activation, err.Error())
+			}
+		}
+		activation.Status.Stage = stork_api.ApplicationActivationStageDeactivate
+		return a.client.Update(ctx, activation)
+	case stork_api.ApplicationActivationStageDeactivate:
+		if activation.Status.StepStartTimestamp.IsZero() {
+			if err := a.deactivateApplications(activation); err != nil {
+				return a.fail(activation, err.Error())
+			}
+			activation.Status.StepStartTimestamp = metav1.Now()
+			return a.client.Update(ctx, activation)
+		}
+		// Applications are only activated once they have been stopped on the
+		// other cluster
+		stopped, err := a.waitForDeactivation(ctx, activation)
+		if err != nil || !stopped {
+			return err
+		}
+		activation.Status.Stage = stork_api.ApplicationActivationStageActivate
+		if activation.Spec.Operation == stork_api.ApplicationActivationOperationFailback {
+			activation.Status.Stage = stork_api.ApplicationActivationStageMigrate
+		}
+		activation.Status.CurrentStep = 0
+		activation.Status.StepStartTimestamp = metav1.Time{}
+		return a.client.Update(ctx, activation)
+	case stork_api.ApplicationActivationStageMigrate:
+		return a.migrateApplications(ctx, activation)
+	case stork_api.ApplicationActivationStageActivate:
+		return a.activateApplications(ctx, activation)
+	}
+	return nil
+}
+
+func validateApplicationActivation(
+	activation *stork_api.ApplicationActivation,
+	registrations map[string]stork_api.ApplicationResource,
+) error {
+	switch activation.Spec.Operation {
+	case "", stork_api.ApplicationActivationOperationFailover, stork_api.ApplicationActivationOperationFailback:
+	default:
+		return fmt.Errorf("unsupported operation: %v", activation.Spec.Operation)
+	}
+	if activation.Spec.ClusterPair == "" {
+		return fmt.Errorf("clusterPair is required")
+	}
+	if len(activation.Spec.Namespaces) == 0 {
+		return fmt.Errorf("namespaces are required")
+	}
+	if activation.Spec.Operation == stork_api.ApplicationActivationOperationFailback && activation.Spec.SkipRemoteDeactivation {
+		return fmt.Errorf("skipRemoteDeactivation is only supported for failover")
+	}
+	for _, step := range activation.Spec.Steps {
+		for _, kind := range step.Kinds {
+			if !isActivationKindSupported(kind, registrations) {
+				return fmt.Errorf("unsupported kind %v in step %v, supported kinds are %v",
+					kind, step.Name, strings.Join(getActivationKinds(registrations), ", "))
+			}
+		}
+		if step.ReadinessTimeoutSeconds < 0 {
+			return fmt.Errorf("invalid readinessTimeoutSeconds %v in step %v", step.ReadinessTimeoutSeconds, step.Name)
+		}
+	}
+	return nil
+}
+
+// updateMigrationSchedule suspends the migration schedule on the remote
+// cluster on failover, and resumes it on failback
+func (a *ApplicationActivationController) updateMigrationSchedule(activation *stork_api.ApplicationActivation) error {
+	if activation.Spec.MigrationSchedule == "" || activation.Spec.SkipRemoteDeactivation {
+		return nil
+	}
+	suspend := activation.Spec.Operation == stork_api.ApplicationActivationOperationFailover
+	resourceInfo := &stork_api.ApplicationActivationResourceInfo{
+		Kind:      activationKindMigrationSchedule,
+		Namespace: activation.Namespace,
+		Name:      activation.Spec.MigrationSchedule,
+		Cluster:   activationClusterRemote,
+		Activated: !suspend,
+		Status:    stork_api.ApplicationActivationStatusSuccessful,
+	}
+	setActivationResourceInfo(activation, resourceInfo)
+
+	if err := setRemoteMigrationScheduleSuspend(activation, suspend); err != nil {
+		resourceInfo.Status = stork_api.ApplicationActivationStatusFailed
+		resourceInfo.Reason = err.Error()
+		return fmt.Errorf("error updating MigrationSchedule %v on remote cluster: %v", activation.Spec.MigrationSchedule, err)
+	}
+	resourceInfo.Reason = fmt.Sprintf("Set suspend to %v", suspend)
+	log.ApplicationActivationLog(activation).Infof("Set suspend for MigrationSchedule %v on remote cluster to %v",
+		activation.Spec.MigrationSchedule, suspend)
+	return nil
+}
+
+func setRemoteMigrationScheduleSuspend(activation *stork_api.ApplicationActivation, suspend bool) error {
+	remoteConfig, err := getClusterPairSchedulerConfig(activation.Spec.ClusterPair, activation.Namespace)
+	if err != nil {
+		return err
+	}
+	remoteOps, err := storkops.NewForConfig(remoteConfig)
+	if err != nil {
+		return err
+	}
+	schedule, err := remoteOps.GetMigrationSchedule(activation.Spec.MigrationSchedule, activation.Namespace)
+	if err != nil {
+		return err
+	}
+	schedule.Spec.Suspend = &suspend
+	_, err = remoteOps.UpdateMigrationSchedule(schedule)
+	return err
+}
+
+// deactivateApplications deactivates the applications of all the steps in
+// reverse order, on the remote cluster for failover and on the local cluster
+// for failback. The applications are pending until they have been stopped.
+func (a *ApplicationActivationController) deactivateApplications(activation *stork_api.ApplicationActivation) error {
+	if activation.Spec.SkipRemoteDeactivation {
+		log.ApplicationActivationLog(activation).Infof("Skipping deactivation of applications on remote cluster")
+		return nil
+	}
+	activator, cluster, err := a.getActivator(activation, false)
+	if err != nil {
+		return err
+	}
+
+	steps := getActivationSteps(activation)
+	failed := 0
+	for i := len(steps) - 1; i >= 0; i-- {
+		resources, err := listStepResources(activation, activator, cluster, i, steps[i], false)
+		if err != nil {
+			return fmt.Errorf("error getting applications to deactivate on %v cluster: %v", strings.ToLower(cluster), err)
+		}
+		for _, resource := range resources {
+			resourceInfo := newActivationResourceInfo(resource, cluster, getActivationStepName(i, steps[i]), false)
+			if err := activator.deactivate(resource); err != nil {
+				resourceInfo.Status = stork_api.ApplicationActivationStatusFailed
+				resourceInfo.Reason = fmt.Sprintf("Error deactivating: %v", err)
+				failed++
+			} else {
+				resourceInfo.Status = stork_api.ApplicationActivationStatusPending
+				resourceInfo.Reason = "Waiting for application to stop"
+			}
+			setActivationResourceInfo(activation, resourceInfo)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to deactivate %v applications on %v cluster", failed, strings.ToLower(cluster))
+	}
+	return nil
+}
+
+// waitForDeactivation returns true once none of the deactivated applications
+// have pods left, so that they aren't running on both clusters at the same
+// time. The activation fails if they haven't stopped within the default
+// readiness timeout.
+func (a *ApplicationActivationController) waitForDeactivation(
+	ctx context.Context,
+	activation *stork_api.ApplicationActivation,
+) (bool, error) {
+	if activation.Spec.SkipRemoteDeactivation {
+		return true, nil
+	}
+	activator, cluster, err := a.getActivator(activation, false)
+	if err != nil {
+		return false, a.fail(activation, err.Error())
+	}
+
+	pending := make([]*stork_api.ApplicationActivationResourceInfo, 0)
+	updated := false
+	for _, resourceInfo := range activation.Status.Resources {
+		if resourceInfo.Cluster != cluster || resourceInfo.Activated ||
+			resourceInfo.Status != stork_api.ApplicationActivationStatusPending {
+			continue
+		}
+		resource := activationResource{Kind: resourceInfo.Kind, Namespace: resourceInfo.Namespace, Name: resourceInfo.Name}
+		stopped, err := activator.isStopped(resource)
+		if err != nil {
+			log.ApplicationActivationLog(activation).Warnf("Error checking if %v %v/%v is stopped: %v",
+				resource.Kind, resource.Namespace, resource.Name, err)
+		}
+		if stopped {
+			resourceInfo.Status = stork_api.ApplicationActivationStatusSuccessful
+			resourceInfo.Reason = "Deactivated"
+			updated = true
+			continue
+		}
+		pending = append(pending, resourceInfo)
+	}
+	if len(pending) == 0 {
+		return true, nil
+	}
+
+	names := make([]string, 0, len(pending))
+	for _, resourceInfo := range pending {
+		names = append(names, fmt.Sprintf("%v %v/%v", resourceInfo.Kind, resourceInfo.Namespace, resourceInfo.Name))
+	}
+	if time.Since(activation.Status.StepStartTimestamp.Time) > defaultActivationReadinessTimeout {
+		for _, resourceInfo := range pending {
+			resourceInfo.Status = stork_api.ApplicationActivationStatusFailed
+			resourceInfo.Reason = fmt.Sprintf("Not stopped after %v", defaultActivationReadinessTimeout)
+		}
+		return false, a.fail(activation, fmt.Sprintf("Applications on %v cluster weren't stopped after %v: %v",
+			strings.ToLower(cluster), defaultActivationReadinessTimeout, strings.Join(names, ", ")))
+	}
+	log.ApplicationActivationLog(activation).Debugf("Waiting for applications on %v cluster to stop: %v",
+		strings.ToLower(cluster), strings.Join(names, ", "))
+	if updated {
+		return false, a.client.Update(ctx, activation)
+	}
+	return false, nil
+}
+
+// migrateApplications migrates the deactivated applications back to the
+// remote cluster on failback, and moves on to activating them there once the
+// migration has completed successfully
+func (a *ApplicationActivationController) migrateApplications(ctx context.Context, activation *stork_api.ApplicationActivation) error {
+	if activation.Status.Migration == "" {
+		migration := newFailbackMigration(activation)
+		if _, err := storkops.Instance().CreateMigration(migration); err != nil && !errors.IsAlreadyExists(err) {
+			return a.fail(activation, fmt.Sprintf("Error creating migration to remote cluster: %v", err))
+		}
+		log.ApplicationActivationLog(activation).Infof("Started migration %v of the applications to the remote cluster", migration.Name)
+		activation.Status.Migration = migration.Name
+		return a.client.Update(ctx, activation)
+	}
+
+	migration, err := storkops.Instance().GetMigration(activation.Status.Migration, activation.Namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return a.fail(activation, fmt.Sprintf("Migration %v to remote cluster not found", activation.Status.Migration))
+		}
+		return err
+	}
+	if migration.Status.Stage != stork_api.MigrationStageFinal {
+		log.ApplicationActivationLog(activation).Debugf("Waiting for migration %v to remote cluster to complete", migration.Name)
+		return nil
+	}
+	if migration.Status.Status != stork_api.MigrationStatusSuccessful {
+		return a.fail(activation, fmt.Sprintf("Migration %v to remote cluster didn't complete successfully: %v",
+			migration.Name, migration.Status.Status))
+	}
+	a.recorder.Event(activation,
+		v1.EventTypeNormal,
+		string(stork_api.ApplicationActivationStatusInProgress),
+		fmt.Sprintf("Migration %v to remote cluster completed", migration.Name))
+	activation.Status.Stage = stork_api.ApplicationActivationStageActivate
+	activation.Status.CurrentStep = 0
+	activation.Status.StepStartTimestamp = metav1.Time{}
+	return a.client.Update(ctx, activation)
+}
+
+// newFailbackMigration returns the migration that migrates the applications
+// of a failback to the remote cluster without starting them, so that they
+// are activated there with the data from the local cluster
+func newFailbackMigration(activation *stork_api.ApplicationActivation) *stork_api.Migration {
+	includeResources := true
+	includeVolumes := true
+	startApplications := false
+	return &stork_api.Migration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      activation.Name + "-failback",
+			Namespace: activation.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					Name:       activation.Name,
+					UID:        activation.UID,
+					Kind:       reflect.TypeOf(stork_api.ApplicationActivation{}).Name(),
+					APIVersion: stork_api.SchemeGroupVersion.String(),
+				},
+			},
+		},
+		Spec: stork_api.MigrationSpec{
+			ClusterPair:       activation.Spec.ClusterPair,
+			Namespaces:        activation.Spec.Namespaces,
+			IncludeResources:  &includeResources,
+			IncludeVolumes:    &includeVolumes,
+			StartApplications: &startApplications,
+		},
+	}
+}
+
+// activateApplications activates the applications of the current step and
+// moves on to the next step once they are all ready
+func (a *ApplicationActivationController) activateApplications(ctx context.Context, activation *stork_api.ApplicationActivation) error {
+	activator, cluster, err := a.getActivator(activation, true)
+	if err != nil {
+		return a.fail(activation, err.Error())
+	}
+	steps := getActivationSteps(activation)
+	if activation.Status.CurrentStep >= len(steps) {
+		return a.complete(activation)
+	}
+	stepIndex := activation.Status.CurrentStep
+	step := steps[stepIndex]
+	stepName := getActivationStepName(stepIndex, step)
+
+	// Activate the applications of the step if it hasn't been started
+	if activation.Status.StepStartTimestamp.IsZero() {
+		resources, err := listStepResources(activation, activator, cluster, stepIndex, step, true)
+		if err != nil {
+			return a.fail(activation, fmt.Sprintf("Error getting applications to activate in step %v: %v", stepName, err))
+		}
+		failed := 0
+		for _, resource := range resources {
+			resourceInfo := newActivationResourceInfo(resource, cluster, stepName, true)
+			if err := activator.activate(resource); err != nil {
+				resourceInfo.Status = stork_api.ApplicationActivationStatusFailed
+				resourceInfo.Reason = fmt.Sprintf("Error activating: %v", err)
+				failed++
+			} else {
+				resourceInfo.Status = stork_api.ApplicationActivationStatusPending
+				resourceInfo.Reason = "Waiting for application to be ready"
+			}
+			setActivationResourceInfo(activation, resourceInfo)
+		}
+		if failed > 0 {
+			return a.fail(activation, fmt.Sprintf("Failed to activate %v applications in step %v", failed, stepName))
+		}
+		log.ApplicationActivationLog(activation).Infof("Activated %v applications in step %v", len(resources), stepName)
+		activation.Status.StepStartTimestamp = metav1.Now()
+		return a.client.Update(ctx, activation)
+	}
+
+	// Wait for the applications of the step to be ready
+	pending := make([]string, 0)
+	updated := false
+	for _, resourceInfo := range activation.Status.Resources {
+		if resourceInfo.Step != stepName || resourceInfo.Cluster != cluster || !resourceInfo.Activated ||
+			resourceInfo.Status != stork_api.ApplicationActivationStatusPending {
+			continue
+		}
+		resource := activationResource{Kind: resourceInfo.Kind, Namespace: resourceInfo.Namespace, Name: resourceInfo.Name}
+		ready, err := activator.isReady(resource)
+		if err != nil {
+			log.ApplicationActivationLog(activation).Warnf("Error checking if %v %v/%v is ready: %v",
+				resource.Kind, resource.Namespace, resource.Name, err)
+		}
+		if ready {
+			resourceInfo.Status = stork_api.ApplicationActivationStatusSuccessful
+			resourceInfo.Reason = "Activated"
+			updated = true
+			continue
+		}
+		pending = append(pending, fmt.Sprintf("%v %v/%v", resource.Kind, resource.Namespace, resource.Name))
+	}
+	if len(pending) == 0 {
+		a.recorder.Event(activation,
+			v1.EventTypeNormal,
+			string(stork_api.ApplicationActivationStatusInProgress),
+			fmt.Sprintf("Applications in step %v are ready", stepName))
+		activation.Status.CurrentStep++
+		activation.Status.StepStartTimestamp = metav1.Time{}
+		if activation.Status.CurrentStep >= len(steps) {
+			return a.complete(activation)
+		}
+		return a.client.Update(ctx, activation)
+	}
+
+	timeout := defaultActivationReadinessTimeout
+	if step.ReadinessTimeoutSeconds > 0 {
+		timeout = time.Duration(step.ReadinessTimeoutSeconds) * time.Second
+	}
+	if time.Since(activation.Status.StepStartTimestamp.Time) > timeout {
+		for _, resourceInfo := range activation.Status.Resources {
+			if resourceInfo.Step == stepName && resourceInfo.Cluster == cluster && resourceInfo.Activated &&
+				resourceInfo.Status == stork_api.ApplicationActivationStatusPending {
+				resourceInfo.Status = stork_api.ApplicationActivationStatusFailed
+				resourceInfo.Reason = fmt.Sprintf("Not ready after %v", timeout)
+			}
+		}
+		return a.fail(activation, fmt.Sprintf("Applications in step %v weren't ready after %v: %v",
+			stepName, timeout, strings.Join(pending, ", ")))
+	}
+	log.ApplicationActivationLog(activation).Debugf("Waiting for applications in step %v to be ready: %v",
+		stepName, strings.Join(pending, ", "))
+	if updated {
+		return a.client.Update(ctx, activation)
+	}
+	return nil
+}
+
+// getActivator returns the activator for the cluster where applications are
+// activated, or deactivated
+func (a *ApplicationActivationController) getActivator(
+	activation *stork_api.ApplicationActivation,
+	activate bool,
+) (*appActivator, string, error) {
+	failover := activation.Spec.Operation != stork_api.ApplicationActivationOperationFailback
+	if failover == activate {
+		activator, err := newAppActivator(a.config)
+		if err != nil {
+			return nil, "", fmt.Errorf("error getting client for local cluster: %v", err)
+		}
+		return activator, activationClusterLocal, nil
+	}
+	remoteConfig, err := getClusterPairSchedulerConfig(activation.Spec.ClusterPair, activation.Namespace)
+	if err != nil {
+		return nil, "", err
+	}
+	activator, err := newAppActivator(remoteConfig)
+	if err != nil {
+		return nil, "", fmt.Errorf("error getting client for remote cluster: %v", err)
+	}
+	return activator, activationClusterRemote, nil
+}
+
+// getActivationSteps returns the steps of the activation, or a single step
+// with all the applications if none were specified
+func getActivationSteps(activation *stork_api.ApplicationActivation) []stork_api.ApplicationActivationStep {
+	if len(activation.Spec.Steps) == 0 {
+		return []stork_api.ApplicationActivationStep{{Name: "default"}}
+	}
+	return activation.Spec.Steps
+}
+
+func getActivationStepName(index int, step stork_api.ApplicationActivationStep) string {
+	if step.Name != "" {
+		return step.Name
+	}
+	return fmt.Sprintf("step-%v", index)
+}
+
+// listStepResources returns the applications in a step that haven't already
+// been handled by another step
+func listStepResources(
+	activation *stork_api.ApplicationActivation,
+	activator *appActivator,
+	cluster string,
+	stepIndex int,
+	step stork_api.ApplicationActivationStep,
+	activate bool,
+) ([]activationResource, error) {
+	kinds := step.Kinds
+	if len(kinds) == 0 {
+		kinds = getActivationKinds(activator.registrations)
+	}
+	handled := make(map[activationResource]bool)
+	for _, resourceInfo := range activation.Status.Resources {
+		if resourceInfo.Cluster == cluster && resourceInfo.Activated == activate {
+			handled[activationResource{Kind: resourceInfo.Kind, Namespace: resourceInfo.Namespace, Name: resourceInfo.Name}] = true
+		}
+	}
+
+	resources := make([]activationResource, 0)
+	for _, ns := range activation.Spec.Namespaces {
+		for _, kind := range kinds {
+			stepResources, err := activator.listResources(ns, kind, step.Selectors, activate)
+			if err != nil {
+				return nil, err
+			}
+			for _, resource := range stepResources {
+				if handled[resource] {
+					continue
+				}
+				// Applications are only handled by the first step they
+				// match
+				if claimedByEarlierStep(activation, activator, stepIndex, resource, activate) {
+					continue
+				}
+				handled[resource] = true
+				resources = append(resources, resource)
+			}
+		}
+	}
+	return resources, nil
+}
+
+// claimedByEarlierStep returns true if a resource also matches one of the
+// steps before the given one
+func claimedByEarlierStep(
+	activation *stork_api.ApplicationActivation,
+	activator *appActivator,
+	stepIndex int,
+	resource activationResource,
+	activate bool,
+) bool {
+	steps := getActivationSteps(activation)
+	for i := 0; i < stepIndex; i++ {
+		kinds := steps[i].Kinds
+		if len(kinds) == 0 {
+			kinds = getActivationKinds(activator.registrations)
+		}
+		matchesKind := false
+		for _, kind := range kinds {
+			if kind == resource.Kind {
+				matchesKind = true
+			}
+		}
+		if !matchesKind {
+			continue
+		}
+		resources, err := activator.listResources(resource.Namespace, resource.Kind, steps[i].Selectors, activate)
+		if err != nil {
+			continue
+		}
+		for _, r := range resources {
+			if r == resource {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func newActivationResourceInfo(
+	resource activationResource,
+	cluster string,
+	step string,
+	activate bool,
+) *stork_api.ApplicationActivationResourceInfo {
+	return &stork_api.ApplicationActivationResourceInfo{
+		Kind:      resource.Kind,
+		Namespace: resource.Namespace,
+		Name:      resource.Name,
+		Cluster:   cluster,
+		Step:      step,
+		Activated: activate,
+	}
+}
+
+// setActivationResourceInfo records the result for a resource in the status
+// of the activation, replacing the previous result for the resource if the
+// same stage is run again
+func setActivationResourceInfo(
+	activation *stork_api.ApplicationActivation,
+	resourceInfo *stork_api.ApplicationActivationResourceInfo,
+) {
+	for i, existing := range activation.Status.Resources {
+		if existing.Kind == resourceInfo.Kind &&
+			existing.Namespace == resourceInfo.Namespace &&
+			existing.Name == resourceInfo.Name &&
+			existing.Cluster == resourceInfo.Cluster &&
+			existing.Activated == resourceInfo.Activated {
+			activation.Status.Resources[i] = resourceInfo
+			return
+		}
+	}
+	activation.Status.Resources = append(activation.Status.Resources, resourceInfo)
+}
+
+// complete resumes the migration schedule on the remote cluster once the
+// applications have been failed back to it, and finishes the activation
+func (a *ApplicationActivationController) complete(activation *stork_api.ApplicationActivation) error {
+	if activation.Spec.Operation == stork_api.ApplicationActivationOperationFailback {
+		if err := a.updateMigrationSchedule(activation); err != nil {
+			return a.fail(activation, err.Error())
+		}
+	}
+	return a.succeed(activation)
+}
+
+func (a *ApplicationActivationController) succeed(activation *stork_api.ApplicationActivation) error {
+	activation.Status.Stage = stork_api.ApplicationActivationStageFinal
+	activation.Status.Status = stork_api.ApplicationActivationStatusSuccessful
+	activation.Status.Reason = fmt.Sprintf("%v completed successfully", activation.Spec.Operation)
+	activation.Status.FinishTimestamp = metav1.Now()
+	a.recorder.Event(activation,
+		v1.EventTypeNormal,
+		string(stork_api.ApplicationActivationStatusSuccessful),
+		activation.Status.Reason)
+	log.ApplicationActivationLog(activation).Infof(activation.Status.Reason)
+	return a.client.Update(context.TODO(), activation)
+}
+
+func (a *ApplicationActivationController) fail(
+	activation *stork_api.ApplicationActivation,
+	message string,
+) error {
+	activation.Status.Stage = stork_api.ApplicationActivationStageFinal
+	activation.Status.Status = stork_api.ApplicationActivationStatusFailed
+	activation.Status.Reason = message
+	activation.Status.FinishTimestamp = metav1.Now()
+	a.recorder.Event(activation,
+		v1.EventTypeWarning,
+		string(stork_api.ApplicationActivationStatusFailed),
+		message)
+	log.ApplicationActivationLog(activation).Errorf(message)
+	return a.client.Update(context.TODO(), activation)
+}
+
+func (a *ApplicationActivationController) createCRD() error {
+	resource := apiextensions.CustomResource{
+		Name:    stork_api.ApplicationActivationResourceName,
+		Plural:  stork_api.ApplicationActivationResourcePlural,
+		Group:   stork_api.SchemeGroupVersion.Group,
+		Version: stork_api.SchemeGroupVersion.Version,
+		Scope:   apiextensionsv1beta1.NamespaceScoped,
+		Kind:    reflect.TypeOf(stork_api.ApplicationActivation{}).Name(),
+	}
+	ok, err := version.RequiresV1Registration()
+	if err != nil {
+		return err
+	}
+	if ok {
+		err := k8sutils.CreateCRD(resource)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+		return apiextensions.Instance().ValidateCRD(resource.Plural+"."+resource.Group, validateCRDTimeout, validateCRDInterval)
+	}
+	err = apiextensions.Instance().CreateCRDV1beta1(resource)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return apiextensions.Instance().ValidateCRDV1beta1(resource, validateCRDTimeout, validateCRDInterval)
+}
@@ -0,0 +1,240 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/log"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+const (
+	// applyMaxQPS is the rate at which requests are sent to the remote
+	// cluster when applying resources
+	applyMaxQPS = 100
+	// applyMinQPS is the lowest rate used after the remote cluster has
+	// throttled requests
+	applyMinQPS = 1
+	// applyBurst is the number of requests that can be sent at once to the
+	// remote cluster at the max rate. The burst is lowered with the rate so
+	// that a lowered rate doesn't start with a full bucket of requests.
+	applyBurst = 100
+	// applyRampUpRequests is the number of requests that need to succeed
+	// before the rate is increased again after being throttled
+	applyRampUpRequests = 20
+	// applyThrottleDelay is how long to wait before retrying a request that
+	// was throttled if the remote cluster didn't suggest a delay
+	applyThrottleDelay = 2 * time.Second
+)
+
+// applyTiers are the kinds of resources that are applied in order. Resources
+// in a tier are applied in parallel once all the resources in the previous
+// tiers have been applied. Kinds that aren't listed, for example custom
+// resources, are applied last.
+var applyTiers = [][]string{
+	{"StorageClass", "PriorityClass", "ClusterRole", "PodSecurityPolicy", "ResourceQuota", "LimitRange"},
+	{"ServiceAccount", "Secret", "ConfigMap", "Role", "RoleBinding", "ClusterRoleBinding", "NetworkPolicy"},
+	{"Service", "Endpoints", "PodDisruptionBudget"},
+	{"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController", "DeploymentConfig",
+		"Job", "CronJob", "Pod", "ImageStream", "BuildConfig"},
+	{"Ingress", "Route", "HorizontalPodAutoscaler"},
+}
+
+func getApplyTier(kind string) int {
+	for i, kinds := range applyTiers {
+		for _, k := range kinds {
+			if k == kind {
+				return i
+			}
+		}
+	}
+	return len(applyTiers)
+}
+
+// getApplyPlan groups the objects into the tiers they should be applied in.
+// Empty tiers are skipped and the order of the objects in a tier is kept.
+func getApplyPlan(objects []runtime.Unstructured) [][]runtime.Unstructured {
+	tiers := make([][]runtime.Unstructured, len(applyTiers)+1)
+	for _, o := range objects {
+		tier := getApplyTier(o.GetObjectKind().GroupVersionKind().Kind)
+		tiers[tier] = append(tiers[tier], o)
+	}
+	plan := make([][]runtime.Unstructured, 0, len(tiers))
+	for _, tier := range tiers {
+		if len(tier) > 0 {
+			plan = append(plan, tier)
+		}
+	}
+	return plan
+}
+
+// applyRateLimiter limits the requests sent to the remote cluster. The rate
+// is halved every time the remote cluster throttles a request and increased
+// again once requests succeed. Requests that are throttled while waiting for
+// a previous throttle don't lower the rate again, since they were most likely
+// sent by other workers at the same time.
+type applyRateLimiter struct {
+	sync.Mutex
+	limiter   flowcontrol.RateLimiter
+	qps       float32
+	successes int
+	// throttledUntil is when the wait after the last throttle ends
+	throttledUntil time.Time
+}
+
+func newApplyRateLimiter() *applyRateLimiter {
+	return &applyRateLimiter{
+		limiter: flowcontrol.NewTokenBucketRateLimiter(applyMaxQPS, applyBurst),
+		qps:     applyMaxQPS,
+	}
+}
+
+func (a *applyRateLimiter) getLimiter() flowcontrol.RateLimiter {
+	a.Lock()
+	defer a.Unlock()
+	return a.limiter
+}
+
+func (a *applyRateLimiter) setQPS(qps float32) {
+	a.qps = qps
+	a.limiter = flowcontrol.NewTokenBucketRateLimiter(qps, getApplyBurst(qps))
+}
+
+// getApplyBurst returns the burst for a rate, which scales with the rate up
+// to applyBurst
+func getApplyBurst(qps float32) int {
+	burst := int(qps * applyBurst / applyMaxQPS)
+	if burst < 1 {
+		return 1
+	}
+	if burst > applyBurst {
+		return applyBurst
+	}
+	return burst
+}
+
+// TryAccept returns true if a request can be sent now
+func (a *applyRateLimiter) TryAccept() bool {
+	return a.getLimiter().TryAccept()
+}
+
+// Accept blocks until a request can be sent
+func (a *applyRateLimiter) Accept() {
+	a.getLimiter().Accept()
+}
+
+// Stop stops the rate limiter
+func (a *applyRateLimiter) Stop() {
+	a.getLimiter().Stop()
+}
+
+// QPS returns the current rate
+func (a *applyRateLimiter) QPS() float32 {
+	a.Lock()
+	defer a.Unlock()
+	return a.qps
+}
+
+// Wait blocks until a request can be sent or the context is done
+func (a *applyRateLimiter) Wait(ctx context.Context) error {
+	return a.getLimiter().Wait(ctx)
+}
+
+// throttled lowers the rate after the remote cluster returned a 429 and waits
+// for the delay suggested by the remote cluster
+func (a *applyRateLimiter) throttled(err error) {
+	delay := applyThrottleDelay
+	if seconds, ok := errors.SuggestsClientDelay(err); ok && seconds > 0 {
+		delay = time.Duration(seconds) * time.Second
+	}
+
+	a.Lock()
+	a.successes = 0
+	now := time.Now()
+	if now.After(a.throttledUntil) {
+		if qps := a.qps / 2; qps >= applyMinQPS {
+			a.setQPS(qps)
+		} else {
+			a.setQPS(applyMinQPS)
+		}
+		a.throttledUntil = now.Add(delay)
+	}
+	a.Unlock()
+
+	time.Sleep(delay)
+}
+
+// succeeded raises the rate again after enough requests have succeeded
+func (a *applyRateLimiter) succeeded() {
+	a.Lock()
+	defer a.Unlock()
+	if a.qps >= applyMaxQPS {
+		return
+	}
+	a.successes++
+	if a.successes < applyRampUpRequests {
+		return
+	}
+	a.successes = 0
+	if qps := a.qps * 2; qps <= applyMaxQPS {
+		a.setQPS(qps)
+	} else {
+		a.setQPS(applyMaxQPS)
+	}
+}
+
+// parallelApply applies the objects tier by tier, with up to
+// migrationMaxThreads objects applied in parallel in a tier. Errors are
+// recorded in the status of each object so that one failure doesn't stop the
+// rest of the objects from being applied.
+func (m *MigrationController) parallelApply(
+	migration *stork_api.Migration,
+	objects []runtime.Unstructured,
+	apply func(runtime.Unstructured) error,
+) {
+	for i, tier := range getApplyPlan(objects) {
+		workers := m.migrationMaxThreads
+		if workers > len(tier) {
+			workers = len(tier)
+		}
+		log.MigrationLog(migration).Infof("Applying %v objects in tier %v with %v parallel workers", len(tier), i, workers)
+
+		objectChan := make(chan runtime.Unstructured)
+		var wg sync.WaitGroup
+		var statusLock sync.Mutex
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for o := range objectChan {
+					err := apply(o)
+					statusLock.Lock()
+					if err != nil {
+						m.updateResourceStatus(
+							migration,
+							o,
+							stork_api.MigrationStatusFailed,
+							fmt.Sprintf("Error applying resource: %v", err))
+					} else {
+						m.updateResourceStatus(
+							migration,
+							o,
+							stork_api.MigrationStatusSuccessful,
+							"Resource migrated successfully")
+					}
+					statusLock.Unlock()
+				}
+			}()
+		}
+		for _, o := range tier {
+			objectChan <- o
+		}
+		close(objectChan)
+		wg.Wait()
+	}
+}
@@ -0,0 +1,102 @@
+//go:build unittest
+// +build unittest
+
+package controllers
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newApplyTestObject(kind, name string) runtime.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetKind(kind)
+	obj.SetName(name)
+	return obj
+}
+
+func TestGetApplyPlan(t *testing.T) {
+	objects := []runtime.Unstructured{
+		newApplyTestObject("MongoDBCommunity", "mongo"),
+		newApplyTestObject("Deployment", "web"),
+		newApplyTestObject("ServiceAccount", "web"),
+		newApplyTestObject("Service", "web"),
+		newApplyTestObject("Secret", "web"),
+		newApplyTestObject("StatefulSet", "db"),
+	}
+	plan := getApplyPlan(objects)
+	names := make([][]string, 0, len(plan))
+	for _, tier := range plan {
+		tierNames := make([]string, 0, len(tier))
+		for _, o := range tier {
+			tierNames = append(tierNames, o.GetObjectKind().GroupVersionKind().Kind)
+		}
+		names = append(names, tierNames)
+	}
+	require.Equal(t, [][]string{
+		{"ServiceAccount", "Secret"},
+		{"Service"},
+		{"Deployment", "StatefulSet"},
+		{"MongoDBCommunity"},
+	}, names)
+
+	require.Empty(t, getApplyPlan(nil))
+}
+
+func TestApplyRateLimiter(t *testing.T) {
+	limiter := newApplyRateLimiter()
+	require.Equal(t, float32(applyMaxQPS), limiter.QPS())
+
+	// Successes at the max rate don't change anything
+	limiter.succeeded()
+	require.Equal(t, float32(applyMaxQPS), limiter.QPS())
+
+	limiter.throttled(errors.NewTooManyRequests("throttled", 1))
+	require.Equal(t, float32(applyMaxQPS/2), limiter.QPS())
+
+	for i := 0; i < applyRampUpRequests-1; i++ {
+		limiter.succeeded()
+	}
+	require.Equal(t, float32(applyMaxQPS/2), limiter.QPS(), "Rate shouldn't increase yet")
+	limiter.succeeded()
+	require.Equal(t, float32(applyMaxQPS), limiter.QPS(), "Rate should be back to the max")
+	require.True(t, limiter.TryAccept())
+}
+
+func TestApplyRateLimiterConcurrentThrottles(t *testing.T) {
+	limiter := newApplyRateLimiter()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limiter.throttled(errors.NewTooManyRequests("throttled", 1))
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, float32(applyMaxQPS/2), limiter.QPS(), "Throttles while waiting shouldn't lower the rate again")
+
+	// The rate is lowered again once the wait is over
+	limiter.throttled(errors.NewTooManyRequests("throttled", 1))
+	require.Equal(t, float32(applyMaxQPS/4), limiter.QPS())
+
+	// The burst is lowered with the rate
+	accepted := 0
+	for i := 0; i < applyBurst; i++ {
+		if limiter.TryAccept() {
+			accepted++
+		}
+	}
+	require.Less(t, accepted, applyBurst/2, "Lowered rate shouldn't start with a full burst")
+}
+
+func TestGetApplyBurst(t *testing.T) {
+	require.Equal(t, applyBurst, getApplyBurst(applyMaxQPS))
+	require.Equal(t, applyBurst/2, getApplyBurst(applyMaxQPS/2))
+	require.Equal(t, 1, getApplyBurst(applyMinQPS/2))
+}
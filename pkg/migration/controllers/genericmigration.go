@@ -0,0 +1,382 @@
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/log"
+	"github.com/libopenstorage/stork/pkg/resourcecollector"
+	"github.com/portworx/sched-ops/k8s/core"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Volumes that can't be migrated by the storage driver are migrated through
+// the BackupLocation of the ClusterPair. They are backed up on the source
+// cluster with the generic driver, and the backup is then restored on the
+// destination cluster. The ApplicationBackup and ApplicationRestore used for
+// this are deleted once the volumes have been migrated.
+
+const (
+	// genericMigrationPrefix is the prefix of the names of the
+	// ApplicationBackup and ApplicationRestore used to migrate volumes
+	genericMigrationPrefix = "migration-"
+)
+
+func getGenericMigrationName(migration *stork_api.Migration) string {
+	return genericMigrationPrefix + string(migration.UID)
+}
+
+func isGenericMigrationVolume(volumeInfo *stork_api.MigrationVolumeInfo) bool {
+	return volumeInfo.DriverName == volume.KDMPDriverName
+}
+
+// splitMigrationVolumes returns the volumes being migrated by the storage
+// driver and the ones being migrated through the BackupLocation
+func splitMigrationVolumes(
+	volumeInfos []*stork_api.MigrationVolumeInfo,
+) ([]*stork_api.MigrationVolumeInfo, []*stork_api.MigrationVolumeInfo) {
+	nativeVolumes := make([]*stork_api.MigrationVolumeInfo, 0)
+	genericVolumes := make([]*stork_api.MigrationVolumeInfo, 0)
+	for _, volumeInfo := range volumeInfos {
+		if isGenericMigrationVolume(volumeInfo) {
+			genericVolumes = append(genericVolumes, volumeInfo)
+		} else {
+			nativeVolumes = append(nativeVolumes, volumeInfo)
+		}
+	}
+	return nativeVolumes, genericVolumes
+}
+
+// getGenericMigrationPVCs returns the PVCs to migrate that aren't being
+// migrated by the storage driver
+func getGenericMigrationPVCs(
+	migration *stork_api.Migration,
+	nativeVolumes []*stork_api.MigrationVolumeInfo,
+) ([]v1.PersistentVolumeClaim, error) {
+	migrated := make(map[string]bool)
+	for _, volumeInfo := range nativeVolumes {
+		migrated[volumeInfo.Namespace+"/"+volumeInfo.PersistentVolumeClaim] = true
+	}
+	pvcs := make([]v1.PersistentVolumeClaim, 0)
+	for _, namespace := range migration.Spec.Namespaces {
+		pvcList, err := core.Instance().GetPersistentVolumeClaims(namespace, migration.Spec.Selectors)
+		if err != nil {
+			return nil, fmt.Errorf("error getting list of volumes to migrate: %v", err)
+		}
+		for _, pvc := range pvcList.Items {
+			if migrated[pvc.Namespace+"/"+pvc.Name] || resourcecollector.SkipResource(pvc.Annotations) {
+				continue
+			}
+			// Pending and deleting PVCs can't be backed up
+			if pvc.Status.Phase != v1.ClaimBound || pvc.DeletionTimestamp != nil {
+				continue
+			}
+			pvcs = append(pvcs, pvc)
+		}
+	}
+	return pvcs, nil
+}
+
+func getRemoteStorkOps(migration *stork_api.Migration) (*storkops.Client, error) {
+	remoteConfig, err := getClusterPairSchedulerConfig(migration.Spec.ClusterPair, migration.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return storkops.NewForConfig(remoteConfig)
+}
+
+// startGenericVolumeMigration starts a backup of the volumes that aren't being
+// migrated by the storage driver to the BackupLocation of the cluster pair
+func (m *MigrationController) startGenericVolumeMigration(
+	migration *stork_api.Migration,
+	clusterPair *stork_api.ClusterPair,
+	nativeVolumes []*stork_api.MigrationVolumeInfo,
+) ([]*stork_api.MigrationVolumeInfo, error) {
+	pvcs, err := getGenericMigrationPVCs(migration, nativeVolumes)
+	if err != nil {
+		return nil, err
+	}
+	if len(pvcs) == 0 {
+		return nil, nil
+	}
+
+	// Make sure the backup can be restored on the destination cluster before
+	// starting it
+	remoteOps, err := getRemoteStorkOps(migration)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := remoteOps.GetBackupLocation(clusterPair.Spec.BackupLocation, migration.Namespace); err != nil {
+		return nil, fmt.Errorf("error getting BackupLocation %v on destination cluster: %v", clusterPair.Spec.BackupLocation, err)
+	}
+
+	namespaces := make([]string, 0)
+	includeResources := make([]stork_api.ObjectInfo, 0)
+	volumeInfos := make([]*stork_api.MigrationVolumeInfo, 0)
+	for _, pvc := range pvcs {
+		if len(namespaces) == 0 || namespaces[len(namespaces)-1] != pvc.Namespace {
+			namespaces = append(namespaces, pvc.Namespace)
+		}
+		includeResources = append(includeResources, stork_api.ObjectInfo{
+			Name:      pvc.Name,
+			Namespace: pvc.Namespace,
+			GroupVersionKind: metav1.GroupVersionKind{
+				Group:   "core",
+				Version: "v1",
+				Kind:    "PersistentVolumeClaim",
+			},
+		})
+		volumeInfos = append(volumeInfos, &stork_api.MigrationVolumeInfo{
+			PersistentVolumeClaim: pvc.Name,
+			Namespace:             pvc.Namespace,
+			Volume:                pvc.Spec.VolumeName,
+			DriverName:            volume.KDMPDriverName,
+			Status:                stork_api.MigrationStatusInProgress,
+			Reason:                "Volume migration has started. Waiting for backup to start.",
+		})
+	}
+
+	backup := &stork_api.ApplicationBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getGenericMigrationName(migration),
+			Namespace: migration.Namespace,
+			Annotations: map[string]string{
+				StorkMigrationName: migration.Name,
+			},
+		},
+		Spec: stork_api.ApplicationBackupSpec{
+			Namespaces:       namespaces,
+			BackupLocation:   clusterPair.Spec.BackupLocation,
+			IncludeResources: includeResources,
+			ReclaimPolicy:    stork_api.ApplicationBackupReclaimPolicyDelete,
+			BackupType:       stork_api.ApplicationBackupGeneric,
+		},
+	}
+	if _, err := storkops.Instance().CreateApplicationBackup(backup); err != nil && !errors.IsAlreadyExists(err) {
+		return nil, fmt.Errorf("error creating ApplicationBackup to migrate volumes: %v", err)
+	}
+	log.MigrationLog(migration).Infof("Started migration of %v volumes through BackupLocation %v",
+		len(volumeInfos), clusterPair.Spec.BackupLocation)
+	return volumeInfos, nil
+}
+
+// updateGenericVolumeMigrationStatus updates the status of the volumes being
+// migrated through the BackupLocation. The backup is restored on the
+// destination cluster once it is done.
+func (m *MigrationController) updateGenericVolumeMigrationStatus(
+	migration *stork_api.Migration,
+	volumeInfos []*stork_api.MigrationVolumeInfo,
+) error {
+	inProgress := false
+	for _, volumeInfo := range volumeInfos {
+		if volumeInfo.Status == stork_api.MigrationStatusInProgress {
+			inProgress = true
+		}
+	}
+	if !inProgress {
+		return nil
+	}
+
+	name := getGenericMigrationName(migration)
+	backup, err := storkops.Instance().GetApplicationBackup(name, migration.Namespace)
+	if err != nil {
+		return fmt.Errorf("error getting ApplicationBackup %v used to migrate volumes: %v", name, err)
+	}
+	var restore *stork_api.ApplicationRestore
+	if backup.Status.Stage == stork_api.ApplicationBackupStageFinal &&
+		(backup.Status.Status == stork_api.ApplicationBackupStatusSuccessful ||
+			backup.Status.Status == stork_api.ApplicationBackupStatusPartialSuccess) {
+		restore, err = m.getGenericMigrationRestore(migration, backup)
+		if err != nil {
+			return err
+		}
+	}
+	setGenericVolumeMigrationStatus(volumeInfos, backup, restore)
+	return nil
+}
+
+// getGenericMigrationRestore returns the restore of the backup on the
+// destination cluster, creating it if it doesn't exist
+func (m *MigrationController) getGenericMigrationRestore(
+	migration *stork_api.Migration,
+	backup *stork_api.ApplicationBackup,
+) (*stork_api.ApplicationRestore, error) {
+	remoteOps, err := getRemoteStorkOps(migration)
+	if err != nil {
+		return nil, err
+	}
+	restore, err := remoteOps.GetApplicationRestore(backup.Name, backup.Namespace)
+	if err == nil {
+		return restore, nil
+	} else if !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("error getting ApplicationRestore %v on destination cluster: %v", backup.Name, err)
+	}
+
+	// The backup needs to exist on the destination cluster to be restored.
+	// The data is deleted with the backup on the source cluster, so retain it
+	// here.
+	if _, err := remoteOps.GetApplicationBackup(backup.Name, backup.Namespace); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("error getting ApplicationBackup %v on destination cluster: %v", backup.Name, err)
+		}
+		remoteBackup := backup.DeepCopy()
+		remoteBackup.UID = ""
+		remoteBackup.ResourceVersion = ""
+		remoteBackup.SelfLink = ""
+		remoteBackup.OwnerReferences = nil
+		remoteBackup.Finalizers = nil
+		remoteBackup.Spec.ReclaimPolicy = stork_api.ApplicationBackupReclaimPolicyRetain
+		if _, err := remoteOps.CreateApplicationBackup(remoteBackup); err != nil {
+			return nil, fmt.Errorf("error creating ApplicationBackup %v on destination cluster: %v", backup.Name, err)
+		}
+	}
+
+	namespaceMapping := make(map[string]string)
+	for _, namespace := range backup.Spec.Namespaces {
+		namespaceMapping[namespace] = namespace
+	}
+	restore = &stork_api.ApplicationRestore{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      backup.Name,
+			Namespace: backup.Namespace,
+			Annotations: map[string]string{
+				StorkMigrationName: migration.Name,
+			},
+		},
+		Spec: stork_api.ApplicationRestoreSpec{
+			BackupName:       backup.Name,
+			BackupLocation:   backup.Spec.BackupLocation,
+			NamespaceMapping: namespaceMapping,
+			// Volumes on the destination are replaced with the data from the
+			// latest migration
+			ReplacePolicy:       stork_api.ApplicationRestoreReplacePolicyDelete,
+			IncludeResources:    backup.Spec.IncludeResources,
+			StorageClassMapping: migration.Spec.StorageClassMapping,
+		},
+	}
+	restore, err = remoteOps.CreateApplicationRestore(restore)
+	if err != nil {
+		return nil, fmt.Errorf("error creating ApplicationRestore %v on destination cluster: %v", backup.Name, err)
+	}
+	log.MigrationLog(migration).Infof("Started restore of volumes on destination cluster")
+	return restore, nil
+}
+
+// setGenericVolumeMigrationStatus sets the status of the volumes being
+// migrated from the status of the backup, and of the restore on the
+// destination cluster if it has been started
+func setGenericVolumeMigrationStatus(
+	volumeInfos []*stork_api.MigrationVolumeInfo,
+	backup *stork_api.ApplicationBackup,
+	restore *stork_api.ApplicationRestore,
+) {
+	for _, volumeInfo := range volumeInfos {
+		if volumeInfo.Status != stork_api.MigrationStatusInProgress {
+			continue
+		}
+		var backupInfo *stork_api.ApplicationBackupVolumeInfo
+		for _, info := range backup.Status.Volumes {
+			if info.Namespace == volumeInfo.Namespace && info.PersistentVolumeClaim == volumeInfo.PersistentVolumeClaim {
+				backupInfo = info
+				break
+			}
+		}
+		if backupInfo != nil && backupInfo.TotalSize > 0 {
+			volumeInfo.BytesTotal = backupInfo.TotalSize
+		}
+
+		if backup.Status.Stage != stork_api.ApplicationBackupStageFinal {
+			if backupInfo != nil {
+				volumeInfo.Reason = fmt.Sprintf("Volume migration has started. Backup in progress: %v", backupInfo.Reason)
+			}
+			continue
+		}
+		if restore == nil || backupInfo == nil || backupInfo.Status != stork_api.ApplicationBackupStatusSuccessful {
+			volumeInfo.Status = stork_api.MigrationStatusFailed
+			if backupInfo != nil {
+				volumeInfo.Reason = fmt.Sprintf("Backup failed for volume: %v", backupInfo.Reason)
+			} else {
+				volumeInfo.Reason = fmt.Sprintf("Backup failed for volume: %v", backup.Status.Reason)
+			}
+			continue
+		}
+
+		var restoreInfo *stork_api.ApplicationRestoreVolumeInfo
+		for _, info := range restore.Status.Volumes {
+			if info.SourceNamespace == volumeInfo.Namespace && info.PersistentVolumeClaim == volumeInfo.PersistentVolumeClaim {
+				restoreInfo = info
+				break
+			}
+		}
+		if restore.Status.Stage != stork_api.ApplicationRestoreStageFinal {
+			volumeInfo.Reason = "Volume migration has started. Restore in progress on destination cluster"
+			if restoreInfo != nil {
+				volumeInfo.Reason = fmt.Sprintf("%v: %v", volumeInfo.Reason, restoreInfo.Reason)
+			}
+			continue
+		}
+		if restoreInfo == nil {
+			volumeInfo.Status = stork_api.MigrationStatusFailed
+			volumeInfo.Reason = fmt.Sprintf("Volume wasn't restored on destination cluster: %v", restore.Status.Reason)
+		} else if restoreInfo.Status != stork_api.ApplicationRestoreStatusSuccessful {
+			volumeInfo.Status = stork_api.MigrationStatusFailed
+			volumeInfo.Reason = fmt.Sprintf("Restore failed on destination cluster: %v", restoreInfo.Reason)
+		} else {
+			volumeInfo.Status = stork_api.MigrationStatusSuccessful
+			volumeInfo.Reason = "Migration successful for volume"
+		}
+	}
+}
+
+// isGenericMigrationObject returns true if the object is the PVC, or the PV
+// bound to the PVC, of a volume migrated through the BackupLocation
+func isGenericMigrationObject(object runtime.Unstructured, genericVolumes []*stork_api.MigrationVolumeInfo) bool {
+	if len(genericVolumes) == 0 {
+		return false
+	}
+	content := object.UnstructuredContent()
+	var name, namespace string
+	switch object.GetObjectKind().GroupVersionKind().Kind {
+	case "PersistentVolumeClaim":
+		name, _, _ = unstructured.NestedString(content, "metadata", "name")
+		namespace, _, _ = unstructured.NestedString(content, "metadata", "namespace")
+	case "PersistentVolume":
+		name, _, _ = unstructured.NestedString(content, "spec", "claimRef", "name")
+		namespace, _, _ = unstructured.NestedString(content, "spec", "claimRef", "namespace")
+	default:
+		return false
+	}
+	for _, volumeInfo := range genericVolumes {
+		if volumeInfo.PersistentVolumeClaim == name && volumeInfo.Namespace == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanupGenericVolumeMigration deletes the backup and restore used to
+// migrate volumes through the BackupLocation. Deleting the backup on the
+// source cluster deletes its data from the BackupLocation.
+func (m *MigrationController) cleanupGenericVolumeMigration(migration *stork_api.Migration) error {
+	name := getGenericMigrationName(migration)
+	remoteOps, err := getRemoteStorkOps(migration)
+	if err != nil {
+		return err
+	}
+	if err := remoteOps.DeleteApplicationRestore(name, migration.Namespace); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("error deleting ApplicationRestore %v on destination cluster: %v", name, err)
+	}
+	if err := remoteOps.DeleteApplicationBackup(name, migration.Namespace); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("error deleting ApplicationBackup %v on destination cluster: %v", name, err)
+	}
+	if err := storkops.Instance().DeleteApplicationBackup(name, migration.Namespace); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("error deleting ApplicationBackup %v: %v", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+//go:build unittest
+// +build unittest
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestPVC(name string, phase v1.PersistentVolumeClaimPhase) *v1.PersistentVolumeClaim {
+	return &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app"},
+		Spec:       v1.PersistentVolumeClaimSpec{VolumeName: "pv-" + name},
+		Status:     v1.PersistentVolumeClaimStatus{Phase: phase},
+	}
+}
+
+func TestGetGenericMigrationPVCs(t *testing.T) {
+	skipped := newTestPVC("skipped", v1.ClaimBound)
+	skipped.Annotations = map[string]string{"stork.libopenstorage.org/skip-resource": "true"}
+	core.SetInstance(core.New(fake.NewSimpleClientset(
+		newTestPVC("native", v1.ClaimBound),
+		newTestPVC("generic", v1.ClaimBound),
+		newTestPVC("pending", v1.ClaimPending),
+		skipped,
+	)))
+	migration := &stork_api.Migration{
+		Spec: stork_api.MigrationSpec{Namespaces: []string{"app"}},
+	}
+	nativeVolumes := []*stork_api.MigrationVolumeInfo{{PersistentVolumeClaim: "native", Namespace: "app"}}
+
+	pvcs, err := getGenericMigrationPVCs(migration, nativeVolumes)
+	require.NoError(t, err, "Error getting PVCs")
+	require.Len(t, pvcs, 1)
+	require.Equal(t, "generic", pvcs[0].Name)
+}
+
+func TestSplitMigrationVolumes(t *testing.T) {
+	volumeInfos := []*stork_api.MigrationVolumeInfo{
+		{PersistentVolumeClaim: "native"},
+		{PersistentVolumeClaim: "generic", DriverName: volume.KDMPDriverName},
+	}
+	nativeVolumes, genericVolumes := splitMigrationVolumes(volumeInfos)
+	require.Equal(t, volumeInfos[:1], nativeVolumes)
+	require.Equal(t, volumeInfos[1:], genericVolumes)
+}
+
+func TestIsGenericMigrationObject(t *testing.T) {
+	genericVolumes := []*stork_api.MigrationVolumeInfo{
+		{PersistentVolumeClaim: "generic", Namespace: "app", DriverName: volume.KDMPDriverName},
+	}
+	pvc := &unstructured.Unstructured{}
+	pvc.SetKind("PersistentVolumeClaim")
+	pvc.SetName("generic")
+	pvc.SetNamespace("app")
+	require.True(t, isGenericMigrationObject(pvc, genericVolumes))
+	require.False(t, isGenericMigrationObject(pvc, nil))
+
+	pv := &unstructured.Unstructured{}
+	pv.SetKind("PersistentVolume")
+	pv.SetName("pv-generic")
+	require.False(t, isGenericMigrationObject(pv, genericVolumes), "PV without claim shouldn't match")
+	require.NoError(t, unstructured.SetNestedField(pv.Object, "generic", "spec", "claimRef", "name"))
+	require.NoError(t, unstructured.SetNestedField(pv.Object, "app", "spec", "claimRef", "namespace"))
+	require.True(t, isGenericMigrationObject(pv, genericVolumes))
+
+	deployment := &unstructured.Unstructured{}
+	deployment.SetKind("Deployment")
+	deployment.SetName("generic")
+	deployment.SetNamespace("app")
+	require.False(t, isGenericMigrationObject(deployment, genericVolumes))
+}
+
+func TestSetGenericVolumeMigrationStatus(t *testing.T) {
+	newVolumeInfos := func() []*stork_api.MigrationVolumeInfo {
+		return []*stork_api.MigrationVolumeInfo{
+			{PersistentVolumeClaim: "a", Namespace: "app", DriverName: volume.KDMPDriverName, Status: stork_api.MigrationStatusInProgress},
+			{PersistentVolumeClaim: "b", Namespace: "app", DriverName: volume.KDMPDriverName, Status: stork_api.MigrationStatusInProgress},
+		}
+	}
+	backup := &stork_api.ApplicationBackup{
+		Status: stork_api.ApplicationBackupStatus{
+			Stage: stork_api.ApplicationBackupStageVolumes,
+			Volumes: []*stork_api.ApplicationBackupVolumeInfo{
+				{PersistentVolumeClaim: "a", Namespace: "app", Status: stork_api.ApplicationBackupStatusInProgress, Reason: "uploading", TotalSize: 100},
+			},
+		},
+	}
+
+	// Backup in progress
+	volumeInfos := newVolumeInfos()
+	setGenericVolumeMigrationStatus(volumeInfos, backup, nil)
+	require.Equal(t, stork_api.MigrationStatusInProgress, volumeInfos[0].Status)
+	require.Contains(t, volumeInfos[0].Reason, "uploading")
+	require.Equal(t, uint64(100), volumeInfos[0].BytesTotal)
+	require.Equal(t, stork_api.MigrationStatusInProgress, volumeInfos[1].Status)
+
+	// Backup failed
+	backup.Status.Stage = stork_api.ApplicationBackupStageFinal
+	backup.Status.Status = stork_api.ApplicationBackupStatusFailed
+	backup.Status.Reason = "no space"
+	volumeInfos = newVolumeInfos()
+	setGenericVolumeMigrationStatus(volumeInfos, backup, nil)
+	require.Equal(t, stork_api.MigrationStatusFailed, volumeInfos[0].Status)
+	require.Equal(t, stork_api.MigrationStatusFailed, volumeInfos[1].Status)
+	require.Contains(t, volumeInfos[1].Reason, "no space")
+
+	// Backup done, restore in progress on the destination
+	backup.Status.Status = stork_api.ApplicationBackupStatusPartialSuccess
+	backup.Status.Volumes[0].Status = stork_api.ApplicationBackupStatusSuccessful
+	restore := &stork_api.ApplicationRestore{
+		Status: stork_api.ApplicationRestoreStatus{
+			Stage: stork_api.ApplicationRestoreStageVolumes,
+			Volumes: []*stork_api.ApplicationRestoreVolumeInfo{
+				{PersistentVolumeClaim: "a", SourceNamespace: "app", Status: stork_api.ApplicationRestoreStatusInProgress, Reason: "downloading"},
+			},
+		},
+	}
+	volumeInfos = newVolumeInfos()
+	setGenericVolumeMigrationStatus(volumeInfos, backup, restore)
+	require.Equal(t, stork_api.MigrationStatusInProgress, volumeInfos[0].Status)
+	require.Contains(t, volumeInfos[0].Reason, "downloading")
+	require.Equal(t, stork_api.MigrationStatusFailed, volumeInfos[1].Status, "Volume that wasn't backed up should fail")
+
+	// Restore done
+	restore.Status.Stage = stork_api.ApplicationRestoreStageFinal
+	restore.Status.Volumes[0].Status = stork_api.ApplicationRestoreStatusSuccessful
+	setGenericVolumeMigrationStatus(volumeInfos, backup, restore)
+	require.Equal(t, stork_api.MigrationStatusSuccessful, volumeInfos[0].Status)
+	require.Equal(t, uint64(100), volumeInfos[0].BytesTotal)
+}
@@ -0,0 +1,363 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/inflect"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/log"
+	"github.com/libopenstorage/stork/pkg/resourcecollector"
+	"github.com/mitchellh/hashstructure"
+	"github.com/portworx/sched-ops/k8s/core"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// The hash of each resource that was migrated successfully is stored in a
+// ConfigMap per ClusterPair and namespace, and one for the cluster scoped
+// resources. Resources that haven't changed since they were last migrated
+// with the ClusterPair, and that still have the same hash on the destination
+// cluster, aren't applied again. Deleting the ConfigMaps makes the next
+// migration apply all the resources.
+
+const (
+	// migrationStatePrefix is the prefix of the ConfigMaps with the hashes of
+	// the resources migrated with a ClusterPair
+	migrationStatePrefix = "stork-migration-state-"
+	// migrationStateResourcesKey is the key in the ConfigMap with the hashes
+	// of the migrated resources
+	migrationStateResourcesKey = "resources"
+	// migrationStateClusterPairKey is the key in the ConfigMap with the UID
+	// of the ClusterPair. The hashes are ignored if the ClusterPair was
+	// re-created.
+	migrationStateClusterPairKey = "clusterPairUID"
+	// migrationStateNamespaceKey is the key in the ConfigMap with the
+	// namespace of the resources, which is empty for cluster scoped
+	// resources
+	migrationStateNamespaceKey = "namespace"
+	// resourceUnchangedReason is the reason for resources that weren't
+	// applied since they haven't changed since the last migration
+	resourceUnchangedReason = "Resource unchanged since last migration"
+	// destinationListLimit is the number of resources listed at a time from
+	// the destination cluster to check if they have changed
+	destinationListLimit = 500
+	// maxMigrationStateBytes is the maximum size of the hashes stored in a
+	// ConfigMap, which is limited to 1MiB
+	maxMigrationStateBytes = 900 * 1024
+)
+
+// getMigrationStateName returns the name of the ConfigMap with the hashes of
+// the resources in a namespace. The namespace is hashed to keep the name
+// short and to keep the names for different ClusterPairs from colliding.
+func getMigrationStateName(clusterPair *stork_api.ClusterPair, namespace string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(namespace))
+	return fmt.Sprintf("%s%s-%08x", migrationStatePrefix, clusterPair.Name, h.Sum32())
+}
+
+// getMigrationStateKey returns the key used for a resource in the migration
+// state. The namespace comes first so that the resources in a namespace can be
+// found.
+func getMigrationStateKey(group, kind, namespace, name string) string {
+	// core Group is stored as "core" in the status of the migration
+	if group == "core" {
+		group = ""
+	}
+	return namespace + "/" + schema.GroupKind{Group: group, Kind: kind}.String() + "/" + name
+}
+
+// getMigrationObjectHash returns the hash of a resource that is ready to be
+// applied. The status is ignored since it is updated by the resource's
+// controller.
+func getMigrationObjectHash(object runtime.Unstructured) (string, error) {
+	content := make(map[string]interface{})
+	for key, value := range object.UnstructuredContent() {
+		if key != "status" {
+			content[key] = value
+		}
+	}
+	hash, err := hashstructure.Hash(content, &hashstructure.HashOptions{})
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatUint(hash, 10), nil
+}
+
+// isIncrementalMigrationSupported returns false for resources that are
+// always applied. PVs and PVCs need to be updated after their volumes have
+// been migrated.
+func isIncrementalMigrationSupported(kind string) bool {
+	switch kind {
+	case "PersistentVolume", "PersistentVolumeClaim":
+		return false
+	}
+	return true
+}
+
+// getMigrationResourceHash returns the hash that is stamped on a resource
+// when it is applied on the destination cluster
+func getMigrationResourceHash(object runtime.Unstructured) (uint64, error) {
+	return hashstructure.Hash(object, &hashstructure.HashOptions{})
+}
+
+// pluralizeKind returns the name of the resource for a kind, the same way
+// as when resources are applied
+func pluralizeKind(kind string) string {
+	// The default ruleset doesn't pluralize all kinds correctly, so add
+	// those
+	ruleset := inflect.NewDefaultRuleset()
+	ruleset.AddPlural("quota", "quotas")
+	ruleset.AddPlural("prometheus", "prometheuses")
+	ruleset.AddPlural("mongodbcommunity", "mongodbcommunity")
+	return ruleset.Pluralize(strings.ToLower(kind))
+}
+
+// destinationHashes looks up the hashes that resources were applied with on
+// the destination cluster. The resources of a kind in a namespace are listed
+// the first time one of them is looked up, instead of getting each resource.
+type destinationHashes struct {
+	remoteInterface      dynamic.Interface
+	remoteAdminInterface dynamic.Interface
+	// hashes of the listed resources, keyed by name
+	hashes map[destinationListKey]map[string]string
+}
+
+type destinationListKey struct {
+	resource  schema.GroupVersionResource
+	namespace string
+}
+
+func newDestinationHashes(remoteInterface dynamic.Interface, remoteAdminInterface dynamic.Interface) *destinationHashes {
+	return &destinationHashes{
+		remoteInterface:      remoteInterface,
+		remoteAdminInterface: remoteAdminInterface,
+		hashes:               make(map[destinationListKey]map[string]string),
+	}
+}
+
+// isUnchanged returns true if the resource exists on the destination cluster
+// with the hash that it would be applied with. Resources that were deleted or
+// modified on the destination cluster since they were migrated are applied
+// again.
+func (d *destinationHashes) isUnchanged(object runtime.Unstructured) (bool, error) {
+	metadata, err := meta.Accessor(object)
+	if err != nil {
+		return false, err
+	}
+	gvk := object.GetObjectKind().GroupVersionKind()
+	key := destinationListKey{
+		resource:  gvk.GroupVersion().WithResource(pluralizeKind(gvk.Kind)),
+		namespace: metadata.GetNamespace(),
+	}
+	hashes, ok := d.hashes[key]
+	if !ok {
+		// Failures aren't retried for the other resources of the kind, which
+		// are all applied again
+		d.hashes[key] = make(map[string]string)
+		if hashes, err = d.list(key); err != nil {
+			return false, err
+		}
+		d.hashes[key] = hashes
+	}
+	current, ok := hashes[metadata.GetName()]
+	if !ok {
+		return false, nil
+	}
+	hash, err := getMigrationResourceHash(object)
+	if err != nil {
+		return false, err
+	}
+	return current == strconv.FormatUint(hash, 10), nil
+}
+
+// list returns the hashes of the resources of a kind in a namespace on the
+// destination cluster, keyed by name
+func (d *destinationHashes) list(key destinationListKey) (map[string]string, error) {
+	var client dynamic.ResourceInterface
+	if key.namespace != "" {
+		client = d.remoteInterface.Resource(key.resource).Namespace(key.namespace)
+	} else {
+		client = d.remoteAdminInterface.Resource(key.resource)
+	}
+	hashes := make(map[string]string)
+	options := metav1.ListOptions{Limit: destinationListLimit}
+	for {
+		objects, err := client.List(context.TODO(), options)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return hashes, nil
+			}
+			return nil, err
+		}
+		for _, o := range objects.Items {
+			if hash, ok := o.GetAnnotations()[resourcecollector.StorkResourceHash]; ok {
+				hashes[o.GetName()] = hash
+			}
+		}
+		if objects.GetContinue() == "" {
+			return hashes, nil
+		}
+		options.Continue = objects.GetContinue()
+	}
+}
+
+// getMigrationState returns the hashes of the resources in the namespaces
+// that were migrated with the ClusterPair. The hashes of cluster scoped
+// resources are always returned.
+func getMigrationState(
+	migration *stork_api.Migration,
+	clusterPair *stork_api.ClusterPair,
+	namespaces []string,
+) (map[string]string, error) {
+	hashes := make(map[string]string)
+	for _, ns := range append([]string{""}, namespaces...) {
+		configMap, err := core.Instance().GetConfigMap(getMigrationStateName(clusterPair, ns), migration.Namespace)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if configMap.Data[migrationStateClusterPairKey] != string(clusterPair.UID) ||
+			configMap.Data[migrationStateNamespaceKey] != ns {
+			continue
+		}
+		if data := configMap.Data[migrationStateResourcesKey]; data != "" {
+			nsHashes := make(map[string]string)
+			if err := json.Unmarshal([]byte(data), &nsHashes); err != nil {
+				return nil, fmt.Errorf("error parsing migration state for namespace %v: %v", ns, err)
+			}
+			for key, hash := range nsHashes {
+				hashes[key] = hash
+			}
+		}
+	}
+	return hashes, nil
+}
+
+// updateMigrationState updates the hashes of the resources in the migration.
+// The state of each namespace in the migration is replaced, so resources
+// that failed to migrate, or that weren't found, are applied again the next
+// time. The state of cluster scoped resources is shared by all the
+// migrations with the ClusterPair, so only the resources in this migration
+// are updated.
+func updateMigrationState(
+	migration *stork_api.Migration,
+	clusterPair *stork_api.ClusterPair,
+	state map[string]string,
+	hashes map[string]string,
+) error {
+	shards := map[string]map[string]string{"": make(map[string]string)}
+	for key, hash := range state {
+		if strings.HasPrefix(key, "/") {
+			shards[""][key] = hash
+		}
+	}
+	for _, ns := range migration.Spec.Namespaces {
+		shards[ns] = make(map[string]string)
+	}
+	for _, resource := range migration.Status.Resources {
+		key := getMigrationStateKey(resource.Group, resource.Kind, resource.Namespace, resource.Name)
+		shard, ok := shards[resource.Namespace]
+		if !ok {
+			shard = make(map[string]string)
+			shards[resource.Namespace] = shard
+		}
+		if hash, ok := hashes[key]; ok && resource.Status == stork_api.MigrationStatusSuccessful {
+			shard[key] = hash
+		} else {
+			delete(shard, key)
+		}
+	}
+
+	for ns, shard := range shards {
+		if err := updateMigrationStateShard(migration, clusterPair, ns, shard); err != nil {
+			return fmt.Errorf("error updating migration state for namespace %v: %v", ns, err)
+		}
+	}
+	return nil
+}
+
+func updateMigrationStateShard(
+	migration *stork_api.Migration,
+	clusterPair *stork_api.ClusterPair,
+	namespace string,
+	hashes map[string]string,
+) error {
+	data, dropped, err := marshalMigrationState(hashes)
+	if err != nil {
+		return err
+	}
+	if dropped > 0 {
+		log.MigrationLog(migration).Warnf("Migration state for namespace %v is too large, %v resources will be "+
+			"migrated again next time even if they haven't changed", namespace, dropped)
+	}
+	name := getMigrationStateName(clusterPair, namespace)
+	configMap, err := core.Instance().GetConfigMap(name, migration.Namespace)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		configMap = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: migration.Namespace,
+			},
+		}
+		configMap.Data = map[string]string{
+			migrationStateClusterPairKey: string(clusterPair.UID),
+			migrationStateNamespaceKey:   namespace,
+			migrationStateResourcesKey:   string(data),
+		}
+		_, err = core.Instance().CreateConfigMap(configMap)
+		return err
+	}
+	configMap.Data = map[string]string{
+		migrationStateClusterPairKey: string(clusterPair.UID),
+		migrationStateNamespaceKey:   namespace,
+		migrationStateResourcesKey:   string(data),
+	}
+	_, err = core.Instance().UpdateConfigMap(configMap)
+	return err
+}
+
+// marshalMigrationState marshals the hashes of the resources in a namespace.
+// If they don't fit in a ConfigMap only the hashes of the first resources, in
+// the order of their keys, are kept. Returns the number of hashes that
+// weren't kept.
+func marshalMigrationState(hashes map[string]string) ([]byte, int, error) {
+	data, err := json.Marshal(hashes)
+	if err != nil || len(data) <= maxMigrationStateBytes {
+		return data, 0, err
+	}
+	keys := make([]string, 0, len(hashes))
+	for key := range hashes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	kept := make(map[string]string)
+	// Size of the braces of the JSON object
+	size := 2
+	for _, key := range keys {
+		// The key and hash are quoted and separated by a colon and a comma
+		entrySize := len(key) + len(hashes[key]) + 6
+		if size+entrySize > maxMigrationStateBytes {
+			break
+		}
+		kept[key] = hashes[key]
+		size += entrySize
+	}
+	data, err = json.Marshal(kept)
+	return data, len(hashes) - len(kept), err
+}
@@ -0,0 +1,214 @@
+//go:build unittest
+// +build unittest
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/resourcecollector"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetMigrationStateKey(t *testing.T) {
+	require.Equal(t, "app/ConfigMap/config", getMigrationStateKey("core", "ConfigMap", "app", "config"))
+	require.Equal(t, "app/ConfigMap/config", getMigrationStateKey("", "ConfigMap", "app", "config"))
+	require.Equal(t, "/ClusterRole.rbac.authorization.k8s.io/admin",
+		getMigrationStateKey("rbac.authorization.k8s.io", "ClusterRole", "", "admin"))
+}
+
+func TestGetMigrationObjectHash(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetName("config")
+	require.NoError(t, unstructured.SetNestedField(obj.Object, "value", "data", "key"))
+	hash, err := getMigrationObjectHash(obj)
+	require.NoError(t, err)
+
+	require.NoError(t, unstructured.SetNestedField(obj.Object, "Running", "status", "phase"))
+	statusHash, err := getMigrationObjectHash(obj)
+	require.NoError(t, err)
+	require.Equal(t, hash, statusHash, "Status shouldn't change the hash")
+
+	require.NoError(t, unstructured.SetNestedField(obj.Object, "changed", "data", "key"))
+	changedHash, err := getMigrationObjectHash(obj)
+	require.NoError(t, err)
+	require.NotEqual(t, hash, changedHash, "Data should change the hash")
+}
+
+func TestGetMigrationStateName(t *testing.T) {
+	clusterPair := &stork_api.ClusterPair{ObjectMeta: metav1.ObjectMeta{Name: "remote"}}
+	name := getMigrationStateName(clusterPair, "app")
+	require.True(t, strings.HasPrefix(name, migrationStatePrefix+"remote-"), "Unexpected name %v", name)
+	require.Equal(t, name, getMigrationStateName(clusterPair, "app"))
+	require.NotEqual(t, name, getMigrationStateName(clusterPair, ""))
+	require.NotEqual(t, name, getMigrationStateName(clusterPair, "other"))
+}
+
+func TestMigrationState(t *testing.T) {
+	core.SetInstance(core.New(fake.NewSimpleClientset()))
+	clusterPair := &stork_api.ClusterPair{
+		ObjectMeta: metav1.ObjectMeta{Name: "remote", Namespace: "app", UID: "uid1"},
+	}
+	migration := &stork_api.Migration{
+		ObjectMeta: metav1.ObjectMeta{Name: "migration", Namespace: "app"},
+		Spec:       stork_api.MigrationSpec{Namespaces: []string{"app"}},
+	}
+
+	state, err := getMigrationState(migration, clusterPair, []string{"app"})
+	require.NoError(t, err)
+	require.Empty(t, state)
+
+	migration.Status.Resources = []*stork_api.MigrationResourceInfo{
+		{GroupVersionKind: metav1.GroupVersionKind{Kind: "ConfigMap", Group: "core"}, Namespace: "app", Name: "ok", Status: stork_api.MigrationStatusSuccessful},
+		{GroupVersionKind: metav1.GroupVersionKind{Kind: "ConfigMap", Group: "core"}, Namespace: "app", Name: "failed", Status: stork_api.MigrationStatusFailed},
+		{GroupVersionKind: metav1.GroupVersionKind{Kind: "ClusterRole", Group: "rbac.authorization.k8s.io"}, Name: "role", Status: stork_api.MigrationStatusSuccessful},
+	}
+	state["/ClusterRole.rbac.authorization.k8s.io/other"] = "1"
+	state["app/ConfigMap/deleted"] = "2"
+	hashes := map[string]string{
+		"app/ConfigMap/ok":                            "3",
+		"app/ConfigMap/failed":                        "4",
+		"/ClusterRole.rbac.authorization.k8s.io/role": "5",
+	}
+	require.NoError(t, updateMigrationState(migration, clusterPair, state, hashes))
+
+	// Cluster scoped resources of other migrations are kept
+	state, err = getMigrationState(migration, clusterPair, []string{"app"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"/ClusterRole.rbac.authorization.k8s.io/other": "1",
+		"/ClusterRole.rbac.authorization.k8s.io/role":  "5",
+		"app/ConfigMap/ok": "3",
+	}, state)
+
+	// The state of each namespace is stored separately
+	configMap, err := core.Instance().GetConfigMap(getMigrationStateName(clusterPair, "app"), "app")
+	require.NoError(t, err)
+	require.Equal(t, "app", configMap.Data[migrationStateNamespaceKey])
+	require.Equal(t, `{"app/ConfigMap/ok":"3"}`, configMap.Data[migrationStateResourcesKey])
+
+	// Migrations of other namespaces don't change the state of the namespace
+	otherMigration := &stork_api.Migration{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "app"},
+		Spec:       stork_api.MigrationSpec{Namespaces: []string{"other"}},
+		Status: stork_api.MigrationStatus{Resources: []*stork_api.MigrationResourceInfo{
+			{GroupVersionKind: metav1.GroupVersionKind{Kind: "ConfigMap", Group: "core"}, Namespace: "other", Name: "config", Status: stork_api.MigrationStatusSuccessful},
+		}},
+	}
+	otherState, err := getMigrationState(otherMigration, clusterPair, []string{"other"})
+	require.NoError(t, err)
+	require.NoError(t, updateMigrationState(otherMigration, clusterPair, otherState, map[string]string{"other/ConfigMap/config": "6"}))
+	state, err = getMigrationState(migration, clusterPair, []string{"app"})
+	require.NoError(t, err)
+	require.Equal(t, "3", state["app/ConfigMap/ok"])
+	require.Equal(t, "5", state["/ClusterRole.rbac.authorization.k8s.io/role"])
+	require.NotContains(t, state, "other/ConfigMap/config")
+
+	// Update the existing state
+	hashes["app/ConfigMap/ok"] = "7"
+	require.NoError(t, updateMigrationState(migration, clusterPair, state, hashes))
+	state, err = getMigrationState(migration, clusterPair, []string{"app"})
+	require.NoError(t, err)
+	require.Equal(t, "7", state["app/ConfigMap/ok"])
+
+	// State is ignored if the ClusterPair was re-created
+	clusterPair.UID = "uid2"
+	state, err = getMigrationState(migration, clusterPair, []string{"app"})
+	require.NoError(t, err)
+	require.Empty(t, state)
+}
+
+func TestDestinationHashes(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetName("config")
+	obj.SetNamespace("app")
+	require.NoError(t, unstructured.SetNestedField(obj.Object, "value", "data", "key"))
+	hash, err := getMigrationResourceHash(obj)
+	require.NoError(t, err)
+
+	destination := obj.DeepCopy()
+	destination.SetAnnotations(map[string]string{resourcecollector.StorkResourceHash: strconv.FormatUint(hash, 10)})
+	listKinds := map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "configmaps"}:                                       "ConfigMapList",
+		{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}: "ClusterRoleList",
+	}
+	remoteInterface := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, destination)
+	remoteAdminInterface := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+	hashes := newDestinationHashes(remoteInterface, remoteAdminInterface)
+	unchanged, err := hashes.isUnchanged(obj)
+	require.NoError(t, err)
+	require.True(t, unchanged)
+
+	// Resources are only listed once for each kind and namespace
+	lists := 0
+	for _, action := range remoteInterface.Actions() {
+		if action.GetVerb() == "list" {
+			lists++
+		}
+	}
+	require.Equal(t, 1, lists)
+	other := obj.DeepCopy()
+	other.SetName("other")
+	unchanged, err = hashes.isUnchanged(other)
+	require.NoError(t, err)
+	require.False(t, unchanged, "Resources missing on the destination cluster should be applied again")
+	require.Len(t, remoteInterface.Actions(), lists, "Resources shouldn't be listed again")
+
+	// Resources modified on the destination cluster are applied again
+	destination.SetAnnotations(map[string]string{resourcecollector.StorkResourceHash: "1"})
+	_, err = remoteInterface.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).Namespace("app").
+		Update(context.TODO(), destination, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	unchanged, err = newDestinationHashes(remoteInterface, remoteAdminInterface).isUnchanged(obj)
+	require.NoError(t, err)
+	require.False(t, unchanged)
+
+	// Cluster scoped resources are looked up with the admin client
+	role := &unstructured.Unstructured{}
+	role.SetAPIVersion("rbac.authorization.k8s.io/v1")
+	role.SetKind("ClusterRole")
+	role.SetName("role")
+	unchanged, err = hashes.isUnchanged(role)
+	require.NoError(t, err)
+	require.False(t, unchanged, "Deleted resources should be applied again")
+}
+
+func TestMarshalMigrationState(t *testing.T) {
+	hashes := map[string]string{"app/ConfigMap/config": "1"}
+	data, dropped, err := marshalMigrationState(hashes)
+	require.NoError(t, err)
+	require.Equal(t, 0, dropped)
+	require.Equal(t, `{"app/ConfigMap/config":"1"}`, string(data))
+
+	hashes = make(map[string]string)
+	count := maxMigrationStateBytes / 40
+	for i := 0; i < count; i++ {
+		hashes[fmt.Sprintf("app/ConfigMap/config-%06d", i)] = "12345678901234567890"
+	}
+	data, dropped, err = marshalMigrationState(hashes)
+	require.NoError(t, err)
+	require.Greater(t, dropped, 0, "Hashes should be dropped once the state is too large")
+	require.LessOrEqual(t, len(data), maxMigrationStateBytes)
+	kept := make(map[string]string)
+	require.NoError(t, json.Unmarshal(data, &kept))
+	require.Len(t, kept, count-dropped)
+	require.Contains(t, kept, "app/ConfigMap/config-000000", "The first keys should be kept")
+	require.NotContains(t, kept, fmt.Sprintf("app/ConfigMap/config-%06d", count-1))
+}
@@ -3,7 +3,6 @@ package controllers
 import (
 	"context"
 	"fmt"
-	"math/rand"
 	"reflect"
 	"strconv"
 	"strings"
@@ -15,6 +14,7 @@ import (
 	"github.com/libopenstorage/stork/drivers/volume"
 	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	"github.com/libopenstorage/stork/pkg/controllers"
+	storkerrors "github.com/libopenstorage/stork/pkg/errors"
 	"github.com/libopenstorage/stork/pkg/k8sutils"
 	"github.com/libopenstorage/stork/pkg/log"
 	"github.com/libopenstorage/stork/pkg/resourcecollector"
@@ -674,11 +674,14 @@ func (m *MigrationController) migrateVolumes(migration *stork_api.Migration, ter
 	migration.Status.Stage = stork_api.MigrationStageVolumes
 	// Trigger the migration if we don't have any status
 	if migration.Status.Volumes == nil {
-		// Make sure storage is ready in the cluster pair
-		storageStatus, err := getClusterPairStorageStatus(
-			migration.Spec.ClusterPair,
-			migration.Namespace)
-		if err != nil || storageStatus != stork_api.ClusterPairStatusReady {
+		// Make sure storage is ready in the cluster pair. Volumes can still be
+		// migrated through the BackupLocation of the cluster pair if it isn't.
+		storageStatus := stork_api.ClusterPairStatusInitial
+		clusterPair, err := storkops.Instance().GetClusterPair(migration.Spec.ClusterPair, migration.Namespace)
+		if err == nil {
+			storageStatus = clusterPair.Status.StorageStatus
+		}
+		if err != nil || (storageStatus != stork_api.ClusterPairStatusReady && clusterPair.Spec.BackupLocation == "") {
 			// If there was a preExecRule configured, reset the stage so that it
 			// gets retriggered in the next cycle
 			if migration.Spec.PreExecRule != "" {
@@ -692,13 +695,35 @@ func (m *MigrationController) migrateVolumes(migration *stork_api.Migration, ter
 				storageStatus, err)
 		}
 
-		volumeInfos, err := m.volDriver.StartMigration(migration)
-		if err != nil {
-			return err
+		var volumeInfos []*stork_api.MigrationVolumeInfo
+		if storageStatus == stork_api.ClusterPairStatusReady {
+			volumeInfos, err = m.volDriver.StartMigration(migration)
+			if err != nil {
+				// Fall back to migrating all the volumes through the
+				// BackupLocation if the driver doesn't support migration
+				if _, ok := err.(*storkerrors.ErrNotSupported); !ok || clusterPair.Spec.BackupLocation == "" {
+					return err
+				}
+				volumeInfos = nil
+			}
 		}
 		if volumeInfos == nil {
 			volumeInfos = make([]*stork_api.MigrationVolumeInfo, 0)
 		}
+		if clusterPair.Spec.BackupLocation != "" {
+			genericVolumeInfos, err := m.startGenericVolumeMigration(migration, clusterPair, volumeInfos)
+			if err != nil {
+				if len(volumeInfos) != 0 {
+					migration.Status.Volumes = volumeInfos
+					if err := m.cancelVolumeMigration(migration); err != nil {
+						log.MigrationLog(migration).Errorf("Error cancelling migration: %v", err)
+					}
+					migration.Status.Volumes = nil
+				}
+				return err
+			}
+			volumeInfos = append(volumeInfos, genericVolumeInfos...)
+		}
 		migration.Status.Volumes = volumeInfos
 		migration.Status.Status = stork_api.MigrationStatusInProgress
 		err = m.updateMigrationCR(context.TODO(), migration)
@@ -724,7 +749,7 @@ func (m *MigrationController) migrateVolumes(migration *stork_api.Migration, ter
 					message)
 
 				// Cancel the migration and mark it as failed if the postExecRule failed
-				err = m.volDriver.CancelMigration(migration)
+				err = m.cancelVolumeMigration(migration)
 				if err != nil {
 					log.MigrationLog(migration).Errorf("Error cancelling migration: %v", err)
 				}
@@ -744,7 +769,7 @@ func (m *MigrationController) migrateVolumes(migration *stork_api.Migration, ter
 	// Skip checking status if no volumes are being migrated
 	if len(migration.Status.Volumes) != 0 {
 		// Now check the status
-		volumeInfos, err := m.volDriver.GetMigrationStatus(migration)
+		volumeInfos, err := m.getVolumeMigrationStatus(migration)
 		if err != nil {
 			return err
 		}
@@ -785,6 +810,11 @@ func (m *MigrationController) migrateVolumes(migration *stork_api.Migration, ter
 	if inProgress {
 		return nil
 	}
+	if _, genericVolumes := splitMigrationVolumes(migration.Status.Volumes); len(genericVolumes) != 0 {
+		if err := m.cleanupGenericVolumeMigration(migration); err != nil {
+			log.MigrationLog(migration).Warnf("Error cleaning up volume backup: %v", err)
+		}
+	}
 
 	migration.Status.VolumeMigrationFinishTimestamp = metav1.Now()
 	// If the migration hasn't failed move on to the next stage.
@@ -857,7 +887,8 @@ func (m *MigrationController) runPreExecRule(migration *stork_api.Migration) ([]
 			return nil, err
 		}
 
-		ch, err := rule.ExecuteRule(r, rule.PreExecRule, migration, ns)
+		ch, result, err := rule.ExecuteRuleWithResult(r, rule.PreExecRule, migration, ns)
+		migration.Status.RuleResults = rule.AppendResults(migration.Status.RuleResults, result)
 		if err != nil {
 			for _, channel := range terminationChannels {
 				channel <- true
@@ -878,7 +909,8 @@ func (m *MigrationController) runPostExecRule(migration *stork_api.Migration) er
 			return err
 		}
 
-		_, err = rule.ExecuteRule(r, rule.PostExecRule, migration, ns)
+		_, result, err := rule.ExecuteRuleWithResult(r, rule.PostExecRule, migration, ns)
+		migration.Status.RuleResults = rule.AppendResults(migration.Status.RuleResults, result)
 		if err != nil {
 			return fmt.Errorf("error executing PreExecRule for namespace %v: %v", ns, err)
 		}
@@ -949,11 +981,17 @@ func (m *MigrationController) migrateResources(migration *stork_api.Migration, v
 
 	// Save the collected resources infos in the status
 	resourceInfos := make([]*stork_api.MigrationResourceInfo, 0)
+	_, genericVolumes := splitMigrationVolumes(migration.Status.Volumes)
 	for _, obj := range allObjects {
 		metadata, err := meta.Accessor(obj)
 		if err != nil {
 			return err
 		}
+		// The restore on the destination cluster creates the PVCs for volumes
+		// migrated through the BackupLocation
+		if isGenericMigrationObject(obj, genericVolumes) {
+			continue
+		}
 		gvk := obj.GetObjectKind().GroupVersionKind()
 		if volumesOnly {
 			switch gvk.Kind {
@@ -996,6 +1034,67 @@ func (m *MigrationController) migrateResources(migration *stork_api.Migration, v
 		log.MigrationLog(migration).Errorf("Error preparing resources: %v", err)
 		return err
 	}
+
+	// Skip resources that haven't changed since they were last migrated
+	// with the ClusterPair
+	var migrationState, resourceHashes map[string]string
+	if !volumesOnly {
+		namespaces := make([]string, 0)
+		seenNamespaces := make(map[string]bool)
+		for _, resourceInfo := range resourceInfos {
+			if resourceInfo.Namespace != "" && !seenNamespaces[resourceInfo.Namespace] {
+				seenNamespaces[resourceInfo.Namespace] = true
+				namespaces = append(namespaces, resourceInfo.Namespace)
+			}
+		}
+		migrationState, err = getMigrationState(migration, clusterPair, namespaces)
+		if err != nil {
+			log.MigrationLog(migration).Warnf("Error getting migration state, migrating all resources: %v", err)
+			migrationState = make(map[string]string)
+		}
+		var destination *destinationHashes
+		if len(migrationState) > 0 {
+			remoteInterface, remoteAdminInterface, err := m.getRemoteDynamicInterfaces(migration)
+			if err != nil {
+				log.MigrationLog(migration).Warnf("Error getting client for remote cluster, migrating all resources: %v", err)
+				migrationState = make(map[string]string)
+			} else {
+				destination = newDestinationHashes(remoteInterface, remoteAdminInterface)
+			}
+		}
+		resourceHashes = make(map[string]string)
+		changedObjects := make([]runtime.Unstructured, 0, len(updateObjects))
+		for i, obj := range updateObjects {
+			resourceInfo := resourceInfos[i]
+			hash, err := getMigrationObjectHash(obj)
+			if err != nil {
+				log.MigrationLog(migration).Warnf("Error getting hash for %v %v/%v: %v",
+					resourceInfo.Kind, resourceInfo.Namespace, resourceInfo.Name, err)
+				changedObjects = append(changedObjects, obj)
+				continue
+			}
+			key := getMigrationStateKey(resourceInfo.Group, resourceInfo.Kind, resourceInfo.Namespace, resourceInfo.Name)
+			resourceHashes[key] = hash
+			if isIncrementalMigrationSupported(resourceInfo.Kind) && migrationState[key] == hash {
+				unchanged, err := destination.isUnchanged(obj)
+				if err != nil {
+					log.MigrationLog(migration).Warnf("Error checking %v %v/%v on remote cluster: %v",
+						resourceInfo.Kind, resourceInfo.Namespace, resourceInfo.Name, err)
+				}
+				if unchanged {
+					resourceInfo.Status = stork_api.MigrationStatusSuccessful
+					resourceInfo.Reason = resourceUnchangedReason
+					continue
+				}
+			}
+			changedObjects = append(changedObjects, obj)
+		}
+		if skipped := len(updateObjects) - len(changedObjects); skipped > 0 {
+			log.MigrationLog(migration).Infof("Skipping %v resources that haven't changed since the last migration", skipped)
+		}
+		updateObjects = changedObjects
+	}
+
 	err = m.applyResources(migration, updateObjects, resKinds, clusterPair)
 	if err != nil {
 		m.recorder.Event(migration,
@@ -1015,6 +1114,12 @@ func (m *MigrationController) migrateResources(migration *stork_api.Migration, v
 		}
 	}
 
+	if !volumesOnly {
+		if err := updateMigrationState(migration, clusterPair, migrationState, resourceHashes); err != nil {
+			log.MigrationLog(migration).Warnf("Error updating migration state: %v", err)
+		}
+	}
+
 	if *migration.Spec.PurgeDeletedResources {
 		if err := m.purgeMigratedResources(migration, resourceCollectorOpts); err != nil {
 			message := fmt.Sprintf("Error cleaning up resources: %v", err)
@@ -1089,7 +1194,7 @@ func (m *MigrationController) prepareResources(
 			if ns, found := resPatch[metadata.GetNamespace()]; found {
 				// if transformspec present for current resource kind
 				if kind, ok := ns[resource.Kind]; ok {
-					err := resourcecollector.TransformResources(o, kind, metadata.GetName(), metadata.GetNamespace())
+					err := resourcecollector.TransformResources(o, kind, metadata.GetName(), metadata.GetNamespace(), clusterPair)
 					if err != nil {
 						return fmt.Errorf("error updating %v resource %v: %v", o.GetObjectKind().GroupVersionKind().Kind, metadata.GetName(), err)
 					}
@@ -1155,6 +1260,31 @@ func (m *MigrationController) updateResourceStatus(
 	}
 }
 
+// getRemoteDynamicInterfaces returns the dynamic clients for namespaced and
+// cluster scoped resources on the remote cluster
+func (m *MigrationController) getRemoteDynamicInterfaces(migration *stork_api.Migration) (dynamic.Interface, dynamic.Interface, error) {
+	remoteConfig, err := getClusterPairSchedulerConfig(migration.Spec.ClusterPair, migration.Namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	remoteInterface, err := dynamic.NewForConfig(remoteConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	if migration.Spec.AdminClusterPair == "" {
+		return remoteInterface, remoteInterface, nil
+	}
+	remoteAdminConfig, err := getClusterPairSchedulerConfig(migration.Spec.AdminClusterPair, m.migrationAdminNamespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	remoteAdminInterface, err := dynamic.NewForConfig(remoteAdminConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	return remoteInterface, remoteAdminInterface, nil
+}
+
 func (m *MigrationController) getRemoteAdminConfig(migration *stork_api.Migration) (*kubernetes.Clientset, error) {
 	remoteConfig, err := getClusterPairSchedulerConfig(migration.Spec.ClusterPair, migration.Namespace)
 	if err != nil {
@@ -1381,7 +1511,11 @@ func (m *MigrationController) prepareApplicationResource(
 	if !found {
 		annotations = make(map[string]string)
 	}
-	annotations[StorkMigrationReplicasAnnotation] = strconv.FormatInt(replicas, 10)
+	// Applications that were deactivated, for example before they are
+	// failed back, keep the number of replicas they had before
+	if _, ok := annotations[StorkMigrationReplicasAnnotation]; !ok || replicas != 0 {
+		annotations[StorkMigrationReplicasAnnotation] = strconv.FormatInt(replicas, 10)
+	}
 	return unstructured.SetNestedStringMap(content, annotations, "metadata", "annotations")
 }
 
@@ -1503,6 +1637,12 @@ func (m *MigrationController) applyResources(
 		}
 	}
 
+	// Share one rate limiter for all the requests to the remote cluster so
+	// that it can back off when the remote cluster throttles requests
+	rateLimiter := newApplyRateLimiter()
+	remoteConfig.RateLimiter = rateLimiter
+	remoteAdminConfig.RateLimiter = rateLimiter
+
 	adminClient, err := kubernetes.NewForConfig(remoteAdminConfig)
 	if err != nil {
 		return err
@@ -1943,162 +2083,122 @@ func (m *MigrationController) applyResources(
 	}
 
 	// apply remaining objects
-	worker := func(objectChan <-chan runtime.Unstructured, errorChan chan<- error) {
-		for o := range objectChan {
-			metadata, err := meta.Accessor(o)
-			if err != nil {
-				errorChan <- err
-			}
-			objectType, err := meta.TypeAccessor(o)
-			if err != nil {
-				errorChan <- err
-			}
-			resource := &metav1.APIResource{
-				Name:       ruleset.Pluralize(strings.ToLower(objectType.GetKind())),
-				Namespaced: len(metadata.GetNamespace()) > 0,
-			}
-			var dynamicClient dynamic.ResourceInterface
-			if resource.Namespaced {
-				dynamicClient = remoteInterface.Resource(
-					o.GetObjectKind().GroupVersionKind().GroupVersion().WithResource(resource.Name)).Namespace(metadata.GetNamespace())
-			} else {
-				dynamicClient = remoteAdminInterface.Resource(
-					o.GetObjectKind().GroupVersionKind().GroupVersion().WithResource(resource.Name))
-			}
+	apply := func(o runtime.Unstructured) error {
+		metadata, err := meta.Accessor(o)
+		if err != nil {
+			return err
+		}
+		objectType, err := meta.TypeAccessor(o)
+		if err != nil {
+			return err
+		}
+		resource := &metav1.APIResource{
+			Name:       ruleset.Pluralize(strings.ToLower(objectType.GetKind())),
+			Namespaced: len(metadata.GetNamespace()) > 0,
+		}
+		var dynamicClient dynamic.ResourceInterface
+		if resource.Namespaced {
+			dynamicClient = remoteInterface.Resource(
+				o.GetObjectKind().GroupVersionKind().GroupVersion().WithResource(resource.Name)).Namespace(metadata.GetNamespace())
+		} else {
+			dynamicClient = remoteAdminInterface.Resource(
+				o.GetObjectKind().GroupVersionKind().GroupVersion().WithResource(resource.Name))
+		}
 
-			unstructured, ok := o.(*unstructured.Unstructured)
-			if !ok {
-				errorChan <- fmt.Errorf("unable to cast object to unstructured: %v", o)
-			}
+		unstructured, ok := o.(*unstructured.Unstructured)
+		if !ok {
+			return fmt.Errorf("unable to cast object to unstructured: %v", o)
+		}
 
-			// set migration annotations
-			migrAnnot := metadata.GetAnnotations()
-			if migrAnnot == nil {
-				migrAnnot = make(map[string]string)
-			}
-			migrAnnot[StorkMigrationAnnotation] = "true"
-			migrAnnot[StorkMigrationName] = migration.GetName()
-			migrAnnot[StorkMigrationTime] = time.Now().Format(nameTimeSuffixFormat)
-			migrAnnot = m.getParsedAnnotations(migrAnnot, clusterPair)
+		// set migration annotations
+		migrAnnot := metadata.GetAnnotations()
+		if migrAnnot == nil {
+			migrAnnot = make(map[string]string)
+		}
+		migrAnnot[StorkMigrationAnnotation] = "true"
+		migrAnnot[StorkMigrationName] = migration.GetName()
+		migrAnnot[StorkMigrationTime] = time.Now().Format(nameTimeSuffixFormat)
+		migrAnnot = m.getParsedAnnotations(migrAnnot, clusterPair)
 
-			objHash, err := hashstructure.Hash(o, &hashstructure.HashOptions{})
-			if err != nil {
-				log.MigrationLog(migration).Warnf("unable to generate hash for an object %v %v, err: %v", objectType.GetKind(), metadata.GetName(), err)
-			}
-			migrAnnot[resourcecollector.StorkResourceHash] = strconv.FormatUint(objHash, 10)
-			unstructured.SetAnnotations(migrAnnot)
-
-			// parse and set labels on all objects
-			currentLabels := metadata.GetLabels()
-			newLabels := m.getParsedLabels(currentLabels, clusterPair)
-			if len(newLabels) > 0 {
-				unstructured.SetLabels(newLabels)
-			}
-
-			retries := 0
-			log.MigrationLog(migration).Infof("Applying %v %v", objectType.GetKind(), metadata.GetName())
-			for {
-				_, err = dynamicClient.Create(context.TODO(), unstructured, metav1.CreateOptions{})
-				if err != nil && (errors.IsAlreadyExists(err) || strings.Contains(err.Error(), portallocator.ErrAllocated.Error())) {
-					switch objectType.GetKind() {
-					case "ServiceAccount":
-						err = m.checkAndUpdateDefaultSA(migration, o)
-					case "Service":
-						var skipUpdate bool
-						skipUpdate, err = m.checkAndUpdateService(migration, o, objHash)
-						if err == nil && skipUpdate && len(migration.Spec.TransformSpecs) == 0 {
-							break
-						}
-						fallthrough
-					default:
-						// Delete the resource if it already exists on the destination
-						// cluster and try creating again
-						deleteStart := metav1.Now()
-						err = dynamicClient.Delete(context.TODO(), metadata.GetName(), metav1.DeleteOptions{})
-						if err != nil && !errors.IsNotFound(err) {
-							log.MigrationLog(migration).Errorf("Error deleting %v %v during migrate: %v", objectType.GetKind(), metadata.GetName(), err)
-						} else {
-							// wait for resources to get deleted
-							// 2 mins
-							for i := 0; i < deletedMaxRetries; i++ {
-								obj, err := dynamicClient.Get(context.TODO(), metadata.GetName(), metav1.GetOptions{})
-								if err != nil && errors.IsNotFound(err) {
-									break
-								}
-								createTime := obj.GetCreationTimestamp()
-								if deleteStart.Before(&createTime) {
-									log.MigrationLog(migration).Warnf("Object[%v] got re-created after deletion. So, Ignore wait. deleteStart time:[%v], create time:[%v]",
-										obj.GetName(), deleteStart, createTime)
-									break
-								}
-								log.MigrationLog(migration).Warnf("Object %v still present, retrying in %v", metadata.GetName(), deletedRetryInterval)
-								time.Sleep(deletedRetryInterval)
+		objHash, err := getMigrationResourceHash(o)
+		if err != nil {
+			log.MigrationLog(migration).Warnf("unable to generate hash for an object %v %v, err: %v", objectType.GetKind(), metadata.GetName(), err)
+		}
+		migrAnnot[resourcecollector.StorkResourceHash] = strconv.FormatUint(objHash, 10)
+		unstructured.SetAnnotations(migrAnnot)
+
+		// parse and set labels on all objects
+		currentLabels := metadata.GetLabels()
+		newLabels := m.getParsedLabels(currentLabels, clusterPair)
+		if len(newLabels) > 0 {
+			unstructured.SetLabels(newLabels)
+		}
+
+		retries := 0
+		log.MigrationLog(migration).Infof("Applying %v %v", objectType.GetKind(), metadata.GetName())
+		for {
+			_, err = dynamicClient.Create(context.TODO(), unstructured, metav1.CreateOptions{})
+			if err != nil && (errors.IsAlreadyExists(err) || strings.Contains(err.Error(), portallocator.ErrAllocated.Error())) {
+				switch objectType.GetKind() {
+				case "ServiceAccount":
+					err = m.checkAndUpdateDefaultSA(migration, o)
+				case "Service":
+					var skipUpdate bool
+					skipUpdate, err = m.checkAndUpdateService(migration, o, objHash)
+					if err == nil && skipUpdate && len(migration.Spec.TransformSpecs) == 0 {
+						break
+					}
+					fallthrough
+				default:
+					// Delete the resource if it already exists on the destination
+					// cluster and try creating again
+					deleteStart := metav1.Now()
+					err = dynamicClient.Delete(context.TODO(), metadata.GetName(), metav1.DeleteOptions{})
+					if err != nil && !errors.IsNotFound(err) {
+						log.MigrationLog(migration).Errorf("Error deleting %v %v during migrate: %v", objectType.GetKind(), metadata.GetName(), err)
+					} else {
+						// wait for resources to get deleted
+						// 2 mins
+						for i := 0; i < deletedMaxRetries; i++ {
+							obj, err := dynamicClient.Get(context.TODO(), metadata.GetName(), metav1.GetOptions{})
+							if err != nil && errors.IsNotFound(err) {
+								break
+							}
+							createTime := obj.GetCreationTimestamp()
+							if deleteStart.Before(&createTime) {
+								log.MigrationLog(migration).Warnf("Object[%v] got re-created after deletion. So, Ignore wait. deleteStart time:[%v], create time:[%v]",
+									obj.GetName(), deleteStart, createTime)
+								break
 							}
-							_, err = dynamicClient.Create(context.TODO(), unstructured, metav1.CreateOptions{})
+							log.MigrationLog(migration).Warnf("Object %v still present, retrying in %v", metadata.GetName(), deletedRetryInterval)
+							time.Sleep(deletedRetryInterval)
 						}
+						_, err = dynamicClient.Create(context.TODO(), unstructured, metav1.CreateOptions{})
 					}
 				}
-				// Retry a few times for Unauthorized errors
-				if err != nil && errors.IsUnauthorized(err) && retries < maxApplyRetries {
-					retries++
-					continue
-				}
-				break
 			}
-			if err != nil {
-				m.updateResourceStatus(
-					migration,
-					o,
-					stork_api.MigrationStatusFailed,
-					fmt.Sprintf("Error applying resource: %v", err))
-			} else {
-				m.updateResourceStatus(
-					migration,
-					o,
-					stork_api.MigrationStatusSuccessful,
-					"Resource migrated successfully")
+			// Retry a few times for Unauthorized errors
+			if err != nil && errors.IsUnauthorized(err) && retries < maxApplyRetries {
+				retries++
+				continue
 			}
-			errorChan <- nil
-		}
-	}
-
-	return m.parallelWorker(worker, updatedObjects, true)
-}
-
-func (m *MigrationController) parallelWorker(
-	worker func(<-chan runtime.Unstructured, chan<- error),
-	objects []runtime.Unstructured,
-	shuffle bool,
-) error {
-	numObjects := len(objects)
-	objectChan := make(chan runtime.Unstructured)
-	errorChan := make(chan error)
-
-	if shuffle {
-		// Shuffle Object order before applying so we can get parallelism between resource types
-		rand.Seed(time.Now().UnixNano())
-		rand.Shuffle(len(objects), func(i, j int) { objects[i], objects[j] = objects[j], objects[i] })
-	}
-
-	logrus.Infof("Updating %v objects with %v parallel workers", numObjects, m.migrationMaxThreads)
-	for w := 0; w < m.migrationMaxThreads; w++ {
-		go worker(objectChan, errorChan)
-	}
-
-	go func() {
-		for _, o := range objects {
-			objectChan <- o
+			// Slow down and retry if the remote cluster is throttling requests
+			if err != nil && errors.IsTooManyRequests(err) && retries < maxApplyRetries {
+				log.MigrationLog(migration).Warnf("Throttled applying %v %v, retrying at %v QPS: %v",
+					objectType.GetKind(), metadata.GetName(), rateLimiter.QPS()/2, err)
+				rateLimiter.throttled(err)
+				retries++
+				continue
+			}
+			break
 		}
-	}()
-
-	for result := 0; result < numObjects; result++ {
-		workerErr := <-errorChan
-		if workerErr != nil {
-			close(objectChan)
-			return workerErr
+		if err == nil {
+			rateLimiter.succeeded()
 		}
+		return err
 	}
-	close(objectChan)
+
+	m.parallelApply(migration, updatedObjects, apply)
 	return nil
 }
 
@@ -2134,14 +2234,19 @@ func (m *MigrationController) getMigrationSummary(migration *stork_api.Migration
 	if migration.Spec.IncludeResources == nil || *migration.Spec.IncludeResources {
 		totalResources := uint64(len(migration.Status.Resources))
 		doneResources := uint64(0)
+		skippedResources := uint64(0)
 		for _, resource := range migration.Status.Resources {
 			if resource.Status == stork_api.MigrationStatusSuccessful {
 				doneResources++
+				if resource.Reason == resourceUnchangedReason {
+					skippedResources++
+				}
 			}
 		}
 		if totalResources > 0 {
 			migrationSummary.TotalNumberOfResources = totalResources
 			migrationSummary.NumberOfMigratedResources = doneResources
+			migrationSummary.NumberOfSkippedResources = skippedResources
 		}
 		elapsedTimeResources := "NA"
 		if !migration.Status.VolumeMigrationFinishTimestamp.IsZero() {
@@ -2162,11 +2267,51 @@ func (m *MigrationController) getMigrationSummary(migration *stork_api.Migration
 
 func (m *MigrationController) cleanup(migration *stork_api.Migration) error {
 	if migration.Status.Stage != stork_api.MigrationStageFinal {
-		return m.volDriver.CancelMigration(migration)
+		return m.cancelVolumeMigration(migration)
 	}
 	return nil
 }
 
+// getVolumeMigrationStatus returns the status of the volumes being migrated
+// by the storage driver and through the BackupLocation of the cluster pair
+func (m *MigrationController) getVolumeMigrationStatus(migration *stork_api.Migration) ([]*stork_api.MigrationVolumeInfo, error) {
+	nativeVolumes, genericVolumes := splitMigrationVolumes(migration.Status.Volumes)
+	if len(genericVolumes) == 0 {
+		return m.volDriver.GetMigrationStatus(migration)
+	}
+	volumeInfos := make([]*stork_api.MigrationVolumeInfo, 0)
+	if len(nativeVolumes) != 0 {
+		nativeMigration := migration.DeepCopy()
+		nativeMigration.Status.Volumes = nativeVolumes
+		nativeVolumeInfos, err := m.volDriver.GetMigrationStatus(nativeMigration)
+		if err != nil {
+			return nil, err
+		}
+		volumeInfos = append(volumeInfos, nativeVolumeInfos...)
+	}
+	if err := m.updateGenericVolumeMigrationStatus(migration, genericVolumes); err != nil {
+		return nil, err
+	}
+	return append(volumeInfos, genericVolumes...), nil
+}
+
+// cancelVolumeMigration cancels the migration of volumes by the storage
+// driver and through the BackupLocation of the cluster pair
+func (m *MigrationController) cancelVolumeMigration(migration *stork_api.Migration) error {
+	nativeVolumes, genericVolumes := splitMigrationVolumes(migration.Status.Volumes)
+	if len(genericVolumes) == 0 {
+		return m.volDriver.CancelMigration(migration)
+	}
+	if len(nativeVolumes) != 0 {
+		nativeMigration := migration.DeepCopy()
+		nativeMigration.Status.Volumes = nativeVolumes
+		if err := m.volDriver.CancelMigration(nativeMigration); err != nil {
+			return err
+		}
+	}
+	return m.cleanupGenericVolumeMigration(migration)
+}
+
 func (m *MigrationController) createCRD() error {
 	resource := apiextensions.CustomResource{
 		Name:    stork_api.MigrationResourceName,
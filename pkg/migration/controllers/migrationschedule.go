@@ -249,7 +249,7 @@ func (m *MigrationScheduleController) handle(ctx context.Context, migrationSched
 			}
 		}
 
-		policyType, start, err := m.shouldStartMigration(migrationSchedule)
+		policyType, result, err := m.shouldStartMigration(migrationSchedule)
 		if err != nil {
 			msg := fmt.Sprintf("Error checking if migration should be triggered: %v", err)
 			m.recorder.Event(migrationSchedule,
@@ -261,8 +261,8 @@ func (m *MigrationScheduleController) handle(ctx context.Context, migrationSched
 		}
 
 		// Start a migration for a policy if required
-		if start {
-			err := m.startMigration(migrationSchedule, policyType)
+		if result != nil && result.Trigger {
+			err := m.startMigration(migrationSchedule, policyType, result.ScheduledTime)
 			if err != nil {
 				msg := fmt.Sprintf("Error triggering migration for schedule(%v): %v", policyType, err)
 				m.recorder.Event(migrationSchedule,
@@ -363,43 +363,80 @@ func (m *MigrationScheduleController) isMigrationComplete(status stork_api.Migra
 // type of polivy that should trigger it.
 func (m *MigrationScheduleController) shouldStartMigration(
 	migrationSchedule *stork_api.MigrationSchedule,
-) (stork_api.SchedulePolicyType, bool, error) {
+) (stork_api.SchedulePolicyType, *schedule.TriggerResult, error) {
 	// Don't trigger a new migration if one is already in progress
 	for _, policyType := range stork_api.GetValidSchedulePolicyTypes() {
 		policyMigration, present := migrationSchedule.Status.Items[policyType]
 		if present {
 			for _, migration := range policyMigration {
 				if !m.isMigrationComplete(migration.Status) {
-					return stork_api.SchedulePolicyTypeInvalid, false, nil
+					return stork_api.SchedulePolicyTypeInvalid, nil, nil
 				}
 			}
 		}
 	}
 
+	options := schedule.GetTriggerOptions(migrationSchedule.Spec.StartingDeadlineSeconds, migrationSchedule.Spec.CatchUpPolicy)
 	for _, policyType := range stork_api.GetValidSchedulePolicyTypes() {
-		var latestMigrationTimestamp meta.Time
+		latestMigrationTimestamp := schedule.GetLastSkippedTime(migrationSchedule.Status.MissedRuns, policyType)
 		policyMigration, present := migrationSchedule.Status.Items[policyType]
 		if present {
 			for _, migration := range policyMigration {
-				if latestMigrationTimestamp.Before(&migration.CreationTimestamp) {
-					latestMigrationTimestamp = migration.CreationTimestamp
+				triggerTimestamp := migration.CreationTimestamp
+				if !migration.ScheduledTimestamp.IsZero() {
+					triggerTimestamp = migration.ScheduledTimestamp
+				}
+				if latestMigrationTimestamp.Before(&triggerTimestamp) {
+					latestMigrationTimestamp = triggerTimestamp
 				}
 			}
 		}
-		trigger, err := schedule.TriggerRequired(
+		result, err := schedule.CheckTrigger(
 			migrationSchedule.Spec.SchedulePolicyName,
 			migrationSchedule.Namespace,
 			policyType,
 			latestMigrationTimestamp,
+			options,
 		)
 		if err != nil {
-			return stork_api.SchedulePolicyTypeInvalid, false, err
+			return stork_api.SchedulePolicyTypeInvalid, nil, err
+		}
+		if err := m.recordMissedRuns(migrationSchedule, policyType, latestMigrationTimestamp, result); err != nil {
+			return stork_api.SchedulePolicyTypeInvalid, nil, err
 		}
-		if trigger {
-			return policyType, true, nil
+		if result.Trigger {
+			return policyType, result, nil
+		}
+	}
+	return stork_api.SchedulePolicyTypeInvalid, nil, nil
+}
+
+// recordMissedRuns adds the runs that were skipped or deferred to the status
+// of the schedule and raises events for them
+func (m *MigrationScheduleController) recordMissedRuns(
+	migrationSchedule *stork_api.MigrationSchedule,
+	policyType stork_api.SchedulePolicyType,
+	lastTrigger meta.Time,
+	result *schedule.TriggerResult,
+) error {
+	var added []*stork_api.MissedScheduleRun
+	migrationSchedule.Status.MissedRuns, added = schedule.UpdateMissedRuns(migrationSchedule.Status.MissedRuns, policyType, lastTrigger, result)
+	if len(added) == 0 {
+		return nil
+	}
+	for _, run := range added {
+		eventType := v1.EventTypeNormal
+		if run.Action == stork_api.MissedScheduleRunSkipped {
+			eventType = v1.EventTypeWarning
 		}
+		msg := fmt.Sprintf("%v migration scheduled for %v (%v): %v", run.Action, run.ScheduledTimestamp, policyType, run.Reason)
+		m.recorder.Event(migrationSchedule,
+			eventType,
+			string(run.Action),
+			msg)
+		log.MigrationScheduleLog(migrationSchedule).Info(msg)
 	}
-	return stork_api.SchedulePolicyTypeInvalid, false, nil
+	return m.client.Update(context.TODO(), migrationSchedule)
 }
 
 func (m *MigrationScheduleController) formatMigrationName(
@@ -414,6 +451,7 @@ func (m *MigrationScheduleController) formatMigrationName(
 func (m *MigrationScheduleController) startMigration(
 	migrationSchedule *stork_api.MigrationSchedule,
 	policyType stork_api.SchedulePolicyType,
+	scheduledTime time.Time,
 ) error {
 	migrationName := m.formatMigrationName(migrationSchedule, policyType)
 	if migrationSchedule.Status.Items == nil {
@@ -424,9 +462,10 @@ func (m *MigrationScheduleController) startMigration(
 	}
 	migrationSchedule.Status.Items[policyType] = append(migrationSchedule.Status.Items[policyType],
 		&stork_api.ScheduledMigrationStatus{
-			Name:              migrationName,
-			CreationTimestamp: meta.NewTime(schedule.GetCurrentTime()),
-			Status:            stork_api.MigrationStatusPending,
+			Name:               migrationName,
+			CreationTimestamp:  meta.NewTime(schedule.GetCurrentTime()),
+			ScheduledTimestamp: meta.NewTime(scheduledTime),
+			Status:             stork_api.MigrationStatusPending,
 		})
 	err := m.client.Update(context.TODO(), migrationSchedule)
 	if err != nil {
@@ -462,6 +501,14 @@ func (m *MigrationScheduleController) startMigration(
 }
 
 func (m *MigrationScheduleController) pruneMigrations(migrationSchedule *stork_api.MigrationSchedule) error {
+	retention, loc, err := schedule.GetRetentionPolicy(migrationSchedule.Spec.SchedulePolicyName, migrationSchedule.Namespace)
+	if err != nil {
+		return err
+	}
+	if retention != nil {
+		return m.pruneMigrationsWithRetention(migrationSchedule, retention, loc)
+	}
+
 	updated := false
 	for policyType, policyMigration := range migrationSchedule.Status.Items {
 		// Keep only one successful migration status and all failed migrations
@@ -496,6 +543,51 @@ func (m *MigrationScheduleController) pruneMigrations(migrationSchedule *stork_a
 
 }
 
+// pruneMigrationsWithRetention deletes the migrations of all the policy types
+// that aren't retained by the retention policy
+func (m *MigrationScheduleController) pruneMigrationsWithRetention(
+	migrationSchedule *stork_api.MigrationSchedule,
+	retention *stork_api.RetentionPolicy,
+	loc *time.Location,
+) error {
+	policyTypes := make([]stork_api.SchedulePolicyType, 0)
+	migrations := make([]*stork_api.ScheduledMigrationStatus, 0)
+	candidates := make([]schedule.RetentionCandidate, 0)
+	for policyType, policyMigration := range migrationSchedule.Status.Items {
+		for _, migration := range policyMigration {
+			timestamp := migration.CreationTimestamp
+			if !migration.ScheduledTimestamp.IsZero() {
+				timestamp = migration.ScheduledTimestamp
+			}
+			policyTypes = append(policyTypes, policyType)
+			migrations = append(migrations, migration)
+			candidates = append(candidates, schedule.RetentionCandidate{
+				Timestamp: timestamp.Time,
+				Complete:  m.isMigrationComplete(migration.Status),
+				Successful: migration.Status == stork_api.MigrationStatusSuccessful ||
+					migration.Status == stork_api.MigrationStatusPartialSuccess,
+			})
+		}
+	}
+
+	decisions := schedule.ApplyRetention(retention, loc, candidates)
+	items := make(map[stork_api.SchedulePolicyType][]*stork_api.ScheduledMigrationStatus)
+	for i, migration := range migrations {
+		migration.RetentionTiers = decisions[i].Tiers
+		if !decisions[i].Retain {
+			err := storkops.Instance().DeleteMigration(migration.Name, migrationSchedule.Namespace)
+			if err == nil || errors.IsNotFound(err) {
+				continue
+			}
+			// Keep a track of the failed deletes
+			log.MigrationScheduleLog(migrationSchedule).Warnf("Error deleting %v: %v", migration.Name, err)
+		}
+		items[policyTypes[i]] = append(items[policyTypes[i]], migration)
+	}
+	migrationSchedule.Status.Items = items
+	return m.client.Update(context.TODO(), migrationSchedule)
+}
+
 func (m *MigrationScheduleController) deleteMigrations(migrationSchedule *stork_api.MigrationSchedule) error {
 	var lastError error
 	for _, policyMigration := range migrationSchedule.Status.Items {
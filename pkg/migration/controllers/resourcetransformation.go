@@ -16,6 +16,7 @@ import (
 	"github.com/libopenstorage/stork/pkg/version"
 	"github.com/portworx/sched-ops/k8s/apiextensions"
 	coreops "github.com/portworx/sched-ops/k8s/core"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
@@ -150,34 +151,59 @@ func (r *ResourceTransformationController) handle(ctx context.Context, transform
 }
 
 func (r *ResourceTransformationController) validateSpecPath(transform *stork_api.ResourceTransformation) error {
-	for _, spec := range transform.Spec.Objects {
-		_, _, kind, err := getGVK(spec.Resource)
+	if err := resourcecollector.ValidateTransformSpecs(transform.Spec.Objects); err != nil {
+		return err
+	}
+	log.TransformLog(transform).Infof("validated paths ")
+	return nil
+}
+
+// getTransformClusterPair returns the ClusterPair used for templates during
+// the dry-run. Returns nil if the transformation doesn't set one and isn't
+// used by any Migration or MigrationSchedule.
+func (r *ResourceTransformationController) getTransformClusterPair(transform *stork_api.ResourceTransformation) (*stork_api.ClusterPair, error) {
+	name := transform.Spec.ClusterPair
+	if name == "" {
+		migrations, err := storkops.Instance().ListMigrations(transform.Namespace)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		if !resourcecollector.GetSupportedK8SResources(kind, []string{}) {
-			return fmt.Errorf("unsupported resource kind for transformation: %s", kind)
-		}
-		for _, path := range spec.Paths {
-			// TODO: this can be validated via CRDs as well, when we have defined schema
-			// for stork crds
-			// https://portworx.atlassian.net/browse/PWX-26465
-			if path.Operation == stork_api.JsonResourcePatch {
-				return fmt.Errorf("json patch for resources is not supported, operation: %s", path.Operation)
-			}
-			if !(path.Operation == stork_api.AddResourcePath || path.Operation == stork_api.DeleteResourcePath ||
-				path.Operation == stork_api.ModifyResourcePathValue) {
-				return fmt.Errorf("unsupported resource patch operation given for kind :%s, operation: %s", kind, path.Operation)
+		for _, migration := range migrations.Items {
+			if usesTransform(migration.Spec.TransformSpecs, transform.Name) {
+				name = migration.Spec.ClusterPair
+				break
 			}
-			if !(path.Type == stork_api.BoolResourceType || path.Type == stork_api.IntResourceType ||
-				path.Type == stork_api.StringResourceType || path.Type == stork_api.SliceResourceType ||
-				path.Type == stork_api.KeyPairResourceType) {
-				return fmt.Errorf("unsupported type for resource %s, path %s, type: %s", kind, path.Path, path.Type)
+		}
+	}
+	if name == "" {
+		schedules, err := storkops.Instance().ListMigrationSchedules(transform.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, schedule := range schedules.Items {
+			if usesTransform(schedule.Spec.Template.Spec.TransformSpecs, transform.Name) {
+				name = schedule.Spec.Template.Spec.ClusterPair
+				break
 			}
 		}
 	}
-	log.TransformLog(transform).Infof("validated paths ")
-	return nil
+	if name == "" {
+		return nil, nil
+	}
+	clusterPair, err := storkops.Instance().GetClusterPair(name, transform.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error getting ClusterPair %v for templates: %v", name, err)
+	}
+	return clusterPair, nil
+}
+
+func usesTransform(transformSpecs []string, name string) bool {
+	for _, transformSpec := range transformSpecs {
+		if transformSpec == name {
+			return true
+		}
+	}
+	return false
 }
 
 func (r *ResourceTransformationController) validateTransformResource(ctx context.Context, transform *stork_api.ResourceTransformation) error {
@@ -199,6 +225,11 @@ func (r *ResourceTransformationController) validateTransformResource(ctx context
 		return nil
 	}
 
+	clusterPair, err := r.getTransformClusterPair(transform)
+	if err != nil {
+		return err
+	}
+
 	// temp namespace to run dry-run of transformed resource option
 	remoteTempNamespace := getTransformNamespace(transform.Namespace)
 	ns := &v1.Namespace{}
@@ -251,9 +282,12 @@ func (r *ResourceTransformationController) validateTransformResource(ctx context
 		for _, path := range spec.Paths {
 			// This can be handle by CRD validation- v1 version crd support
 			if !(path.Operation == stork_api.AddResourcePath || path.Operation == stork_api.DeleteResourcePath ||
-				path.Operation == stork_api.ModifyResourcePathValue) {
+				path.Operation == stork_api.ModifyResourcePathValue || path.Operation == stork_api.RegexReplaceResourcePath) {
 				return fmt.Errorf("unsupported operation type for given path : %s", path.Operation)
 			}
+		}
+		// All the paths of the spec are applied to each object at once
+		if len(spec.Paths) > 0 {
 			for _, object := range objects.Items {
 				metadata, err := meta.Accessor(object)
 				if err != nil {
@@ -266,12 +300,16 @@ func (r *ResourceTransformationController) validateTransformResource(ctx context
 					GroupVersionKind: metav1.GroupVersionKind(object.GetObjectKind().GroupVersionKind()),
 					Specs:            spec,
 				}
-				if err := resourcecollector.TransformResources(object, []stork_api.TransformResourceInfo{*resInfo}, metadata.GetName(), metadata.GetNamespace()); err != nil {
-					log.TransformLog(transform).Errorf("Unable to apply patch path %s on resource kind: %s/,%s/%s,  err: %v", path, kind, resInfo.Namespace, resInfo.Name, err)
+				// Keep the diff of the transformed object so that it can be
+				// previewed before it is used by a migration
+				diff, err := resourcecollector.TransformResourcesWithDiff(object, []stork_api.TransformResourceInfo{*resInfo}, metadata.GetName(), metadata.GetNamespace(), clusterPair)
+				if err != nil {
+					log.TransformLog(transform).Errorf("Unable to apply patch on resource kind: %s/,%s/%s,  err: %v", kind, resInfo.Namespace, resInfo.Name, err)
 					resInfo.Status = stork_api.ResourceTransformationStatusFailed
 					resInfo.Reason = err.Error()
 					return err
 				}
+				resInfo.Diff = diff
 				unstructured, ok := object.(*unstructured.Unstructured)
 				if !ok {
 					return fmt.Errorf("unable to cast object to unstructured: %v", object)
@@ -289,11 +327,11 @@ func (r *ResourceTransformationController) validateTransformResource(ctx context
 					metadata.GetName())
 				_, err = dynamicClient.Create(context.TODO(), unstructured, metav1.CreateOptions{DryRun: []string{"All"}})
 				if err != nil {
-					log.TransformLog(transform).Errorf("Unable to apply patch path %s on resource kind: %s/,%s/%s,  err: %v", path, kind, resInfo.Namespace, resInfo.Name, err)
+					log.TransformLog(transform).Errorf("Unable to apply patch on resource kind: %s/,%s/%s,  err: %v", kind, resInfo.Namespace, resInfo.Name, err)
 					resInfo.Status = stork_api.ResourceTransformationStatusFailed
 					resInfo.Reason = err.Error()
 				} else {
-					log.TransformLog(transform).Infof("Applied patch path %s on resource kind: %s/,%s/%s", path, kind, resInfo.Namespace, resInfo.Name)
+					log.TransformLog(transform).Infof("Applied patch on resource kind: %s/,%s/%s", kind, resInfo.Namespace, resInfo.Name)
 					resInfo.Status = stork_api.ResourceTransformationStatusReady
 					resInfo.Reason = ""
 				}
@@ -302,6 +340,7 @@ func (r *ResourceTransformationController) validateTransformResource(ctx context
 		}
 	}
 
+	transform.Status.DiffsOmitted = resourcecollector.LimitTransformDiffs(transform.Status.Resources)
 	transform.Status.Status = stork_api.ResourceTransformationStatusReady
 	// verify if all resource dry-run is successful
 	for _, resource := range transform.Status.Resources {
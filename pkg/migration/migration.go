@@ -18,6 +18,7 @@ type Migration struct {
 	clusterPairController       *controllers.ClusterPairController
 	migrationController         *controllers.MigrationController
 	migrationScheduleController *controllers.MigrationScheduleController
+	activationController        *controllers.ApplicationActivationController
 }
 
 // Init init
@@ -39,5 +40,11 @@ func (m *Migration) Init(mgr manager.Manager, migrationAdminNamespace string, mi
 	if err != nil {
 		return fmt.Errorf("error initializing migration schedule controller: %v", err)
 	}
+
+	m.activationController = controllers.NewApplicationActivation(mgr, m.Recorder)
+	err = m.activationController.Init(mgr)
+	if err != nil {
+		return fmt.Errorf("error initializing application activation controller: %v", err)
+	}
 	return nil
 }
@@ -1,6 +1,7 @@
 package monitor
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -12,12 +13,17 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/kubernetes/pkg/util/node"
 )
 
@@ -28,8 +34,21 @@ const (
 	initialNodeWaitDelay = 10 * time.Second
 	nodeWaitFactor       = 2
 	nodeWaitSteps        = 5
+	// defaultEvictionsPerMinute is the number of pods that can be evicted
+	// across the cluster every minute when storage nodes go offline
+	defaultEvictionsPerMinute = 30
 
 	storageDriverOfflineReason = "StorageDriverOffline"
+	podEvictionDeferredReason  = "PodEvictionDeferred"
+	podEvictionBlockedReason   = "PodEvictionBlocked"
+
+	// DisablePodEvictionAnnotation can be set to "true" on a namespace to
+	// stop the monitor from evicting pods in it when the storage driver on
+	// their node goes offline
+	DisablePodEvictionAnnotation = "stork.libopenstorage.org/disablePodEviction"
+
+	evictionDeferredRateLimit   = "rate_limit"
+	evictionDeferredGracePeriod = "grace_period"
 )
 
 var (
@@ -38,6 +57,18 @@ var (
 		Name: "stork_pods_rescheduled_total",
 		Help: "The total number of pods reschduler by stork pod monitor",
 	})
+	// EvictionsDeferredCounter for pod evictions that were delayed by the
+	// eviction rate limit or the node grace period
+	EvictionsDeferredCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "stork_pod_evictions_deferred_total",
+		Help: "The total number of pod evictions deferred by stork pod monitor",
+	}, []string{"reason"})
+	// EvictionsBlockedCounter for pod evictions that were refused because
+	// they would violate a PodDisruptionBudget
+	EvictionsBlockedCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "stork_pod_evictions_blocked_total",
+		Help: "The total number of pod evictions blocked by a PodDisruptionBudget",
+	})
 )
 
 var nodeWaitCallBackoff = wait.Backoff{
@@ -51,11 +82,48 @@ type Monitor struct {
 	Driver      volume.Driver
 	IntervalSec int64
 	Recorder    record.EventRecorder
-	lock        sync.Mutex
-	wg          sync.WaitGroup
-	started     bool
-	stopChannel chan int
-	done        chan int
+	// KubeClient is used to evict pods
+	KubeClient kubernetes.Interface
+	// EvictionsPerMinute is the number of pods that can be evicted across the
+	// cluster every minute. Defaults to 30.
+	EvictionsPerMinute int64
+	// NodeGracePeriodSec is the minimum number of seconds the storage driver
+	// on a node has to be offline before pods are evicted from it
+	NodeGracePeriodSec int64
+	// VolumeHealthCheck enables checking the health of the volumes used by
+	// running pods if the driver supports it. It is opt-in since it evicts
+	// pods that are running.
+	VolumeHealthCheck bool
+	lock              sync.Mutex
+	wg                sync.WaitGroup
+	started           bool
+	stopChannel       chan int
+	done              chan int
+	evictionsDone     chan int
+	clock             clock.Clock
+	evictionLimiter   flowcontrol.RateLimiter
+	// evictionLock protects the queue of evictions deferred by the rate
+	// limit, which is processed by evictionWorker
+	evictionLock    sync.Mutex
+	evictionQueue   []*podEviction
+	queuedEvictions map[types.UID]bool
+	evictionSignal  chan struct{}
+}
+
+// podEviction is an eviction that was deferred by the eviction rate limit
+type podEviction struct {
+	pod         v1.Pod
+	reason      string
+	cause       string
+	isRecovered func() bool
+}
+
+// offlineNode tracks a storage node while its driver is offline
+type offlineNode struct {
+	since time.Time
+	// deferredPods are the pods whose eviction was already deferred for the
+	// grace period
+	deferredPods map[types.UID]bool
 }
 
 // Start Starts the monitor
@@ -72,16 +140,33 @@ func (m *Monitor) Start() error {
 	} else if m.IntervalSec < minimumIntervalSec {
 		return fmt.Errorf("minimum interval for health monitor is %v seconds", minimumIntervalSec)
 	}
+	if m.EvictionsPerMinute == 0 {
+		m.EvictionsPerMinute = defaultEvictionsPerMinute
+	} else if m.EvictionsPerMinute < 0 {
+		return fmt.Errorf("evictions per minute for health monitor can't be negative")
+	}
+	if m.NodeGracePeriodSec < 0 {
+		return fmt.Errorf("node grace period for health monitor can't be negative")
+	}
+	if m.KubeClient == nil {
+		return fmt.Errorf("kubernetes client for health monitor is required")
+	}
 
 	m.stopChannel = make(chan int)
 	m.done = make(chan int)
+	m.evictionsDone = make(chan int)
+	m.initEvictions()
 
 	prometheus.MustRegister(HealthCounter)
+	prometheus.MustRegister(EvictionsDeferredCounter)
+	prometheus.MustRegister(EvictionsBlockedCounter)
+	prometheus.MustRegister(UnhealthyVolumePodsGauge)
 	if err := m.podMonitor(); err != nil {
 		return err
 	}
 
 	go m.driverMonitor()
+	go m.evictionWorker()
 
 	m.started = true
 
@@ -99,6 +184,7 @@ func (m *Monitor) Stop() error {
 
 	close(m.stopChannel)
 	<-m.done
+	<-m.evictionsDone
 
 	m.started = false
 	return nil
@@ -188,6 +274,11 @@ func (m *Monitor) podMonitor() error {
 func (m *Monitor) driverMonitor() {
 	defer close(m.done)
 
+	// Storage nodes that are offline, with the time they were first seen
+	// offline
+	offlineNodes := make(map[string]*offlineNode)
+	// Volumes that weren't healthy in the previous check
+	unhealthyVolumes := make(map[string]volume.VolumeStatus)
 	for {
 		select {
 		default:
@@ -203,14 +294,26 @@ func (m *Monitor) driverMonitor() {
 				// If not online, look at all the pods on that node
 				// For any Running pod on that node using volume by the driver, kill the pod
 				if node.Status != volume.NodeOnline {
+					if _, ok := offlineNodes[node.StorageID]; !ok {
+						offlineNodes[node.StorageID] = &offlineNode{
+							since:        m.clock.Now(),
+							deferredPods: make(map[types.UID]bool),
+						}
+					}
 					m.wg.Add(1)
 					// wait for 1 min if node is upgrading
-					go m.cleanupDriverNodePods(node)
+					go m.cleanupDriverNodePods(node, offlineNodes[node.StorageID])
+				} else {
+					delete(offlineNodes, node.StorageID)
 				}
 			}
 			// lets all node to finish processing and then start sleep
 			m.wg.Wait()
 
+			if m.VolumeHealthCheck && m.Driver.SupportsVolumeHealth() {
+				unhealthyVolumes = m.checkPodVolumes(unhealthyVolumes)
+			}
+
 			// With this default sleep of 2 minutes and the backoff of 2.5 minutes
 			// stork will delete the pods if a driver is down within 4.5 minutes
 			time.Sleep(time.Duration(m.IntervalSec) * time.Second)
@@ -220,25 +323,54 @@ func (m *Monitor) driverMonitor() {
 	}
 }
 
-func (m *Monitor) cleanupDriverNodePods(node *volume.NodeInfo) {
+func (m *Monitor) cleanupDriverNodePods(node *volume.NodeInfo, offline *offlineNode) {
 	defer m.wg.Done()
 	err := wait.ExponentialBackoff(nodeWaitCallBackoff, func() (bool, error) {
-		n, err := m.Driver.InspectNode(node.StorageID)
-		if err != nil {
-			return false, nil
-		}
-		if n.Status != volume.NodeOnline {
-			log.Infof("Volume driver on node %v (%v) is still offline (%v)", node.Hostname, node.StorageID, n.RawStatus)
-			return false, nil
-		}
-		return true, nil
+		return m.isNodeOnline(node), nil
 	})
 	if err == nil {
 		return
 	}
+	m.evictDriverNodePods(node, offline)
+}
+
+// evictDriverNodePods evicts the pods using the driver's volumes from the
+// offline node once the node's grace period has passed
+func (m *Monitor) evictDriverNodePods(node *volume.NodeInfo, offline *offlineNode) {
 	pods, err := core.Instance().GetPods("", nil)
 	if err != nil {
 		log.Errorf("Error getting pods: %v", err)
+		return
+	}
+
+	nodePods := make([]v1.Pod, 0)
+	for _, pod := range pods.Items {
+		owns, err := m.doesDriverOwnPodVolumes(&pod)
+		if err != nil || !owns {
+			continue
+		}
+		if m.isSameNode(pod.Spec.NodeName, node) {
+			nodePods = append(nodePods, pod)
+		}
+	}
+
+	// Give the node more time to come back before evicting pods from it, it
+	// will be checked again in the next interval. The deferral is only
+	// reported once for each pod.
+	gracePeriod := time.Duration(m.NodeGracePeriodSec) * time.Second
+	if offlineFor := m.clock.Since(offline.since); offlineFor < gracePeriod {
+		for _, pod := range nodePods {
+			if offline.deferredPods[pod.UID] {
+				continue
+			}
+			offline.deferredPods[pod.UID] = true
+			msg := fmt.Sprintf("Deferring eviction of Pod from Node %v, volume driver has been %v (%v) for %v, grace period is %v",
+				pod.Spec.NodeName, node.Status, node.RawStatus, offlineFor.Round(time.Second), gracePeriod)
+			storklog.PodLog(&pod).Infof(msg)
+			m.Recorder.Event(&pod, v1.EventTypeNormal, podEvictionDeferredReason, msg)
+			EvictionsDeferredCounter.With(prometheus.Labels{"reason": evictionDeferredGracePeriod}).Inc()
+		}
+		return
 	}
 
 	// delete volume attachments if the node is down for this pod
@@ -247,24 +379,185 @@ func (m *Monitor) cleanupDriverNodePods(node *volume.NodeInfo) {
 		log.Errorf("Error cleaning up volume attachments: %v", err)
 	}
 
-	for _, pod := range pods.Items {
-		owns, err := m.doesDriverOwnPodVolumes(&pod)
-		if err != nil || !owns {
+	m.evictPods(
+		nodePods,
+		storageDriverOfflineReason,
+		fmt.Sprintf("due to volume driver status: %v (%v)", node.Status, node.RawStatus),
+		func() bool {
+			return m.isNodeOnline(node)
+		},
+	)
+}
+
+// evictPods evicts the pods unless eviction is disabled for their namespace,
+// honoring the eviction rate limit and PodDisruptionBudgets. Evictions that
+// are deferred by the rate limit are queued for evictionWorker, which checks
+// isRecovered before evicting them so that pods aren't evicted once the
+// problem has been resolved.
+func (m *Monitor) evictPods(
+	pods []v1.Pod,
+	reason string,
+	cause string,
+	isRecovered func() bool,
+) {
+	namespaceOptOut := make(map[string]bool)
+	for _, pod := range pods {
+		optOut, ok := namespaceOptOut[pod.Namespace]
+		if !ok {
+			optOut = m.isPodEvictionDisabled(pod.Namespace)
+			namespaceOptOut[pod.Namespace] = optOut
+		}
+		if optOut {
+			storklog.PodLog(&pod).Infof("Not evicting Pod from Node %v since eviction is disabled for namespace %v",
+				pod.Spec.NodeName, pod.Namespace)
 			continue
 		}
 
-		if m.isSameNode(pod.Spec.NodeName, node) {
-			msg := fmt.Sprintf("Deleting Pod from Node %v due to volume driver status: %v (%v)", pod.Spec.NodeName, node.Status, node.RawStatus)
-			storklog.PodLog(&pod).Infof(msg)
-			m.Recorder.Event(&pod, v1.EventTypeWarning, storageDriverOfflineReason, msg)
-			err = core.Instance().DeletePods([]v1.Pod{pod}, true)
-			if err != nil {
-				storklog.PodLog(&pod).Errorf("Error deleting pod: %v", err)
-				continue
-			}
-			HealthCounter.Inc()
+		m.evictionLock.Lock()
+		if m.queuedEvictions[pod.UID] {
+			m.evictionLock.Unlock()
+			continue
+		}
+		// Evictions that are already queued go first
+		if len(m.evictionQueue) == 0 && m.evictionLimiter.TryAccept() {
+			m.evictionLock.Unlock()
+			m.evictPod(&pod, reason, cause)
+			continue
+		}
+		m.evictionQueue = append(m.evictionQueue, &podEviction{
+			pod:         pod,
+			reason:      reason,
+			cause:       cause,
+			isRecovered: isRecovered,
+		})
+		m.queuedEvictions[pod.UID] = true
+		m.evictionLock.Unlock()
+
+		msg := fmt.Sprintf("Deferring eviction of Pod from Node %v due to the eviction rate limit of %v pods per minute",
+			pod.Spec.NodeName, m.EvictionsPerMinute)
+		storklog.PodLog(&pod).Infof(msg)
+		m.Recorder.Event(&pod, v1.EventTypeNormal, podEvictionDeferredReason, msg)
+		EvictionsDeferredCounter.With(prometheus.Labels{"reason": evictionDeferredRateLimit}).Inc()
+		select {
+		case m.evictionSignal <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// initEvictions sets up the eviction rate limit and queue
+func (m *Monitor) initEvictions() {
+	if m.clock == nil {
+		m.clock = clock.RealClock{}
+	}
+	m.evictionLimiter = flowcontrol.NewTokenBucketRateLimiterWithClock(float32(m.EvictionsPerMinute)/60, 1, m.clock)
+	m.evictionQueue = make([]*podEviction, 0)
+	m.queuedEvictions = make(map[types.UID]bool)
+	m.evictionSignal = make(chan struct{}, 1)
+}
+
+// evictionWorker evicts the pods in the eviction queue as the rate limit
+// allows, so that the node and volume checks never wait for it
+func (m *Monitor) evictionWorker() {
+	defer close(m.evictionsDone)
+	interval := time.Minute / time.Duration(m.EvictionsPerMinute)
+	for {
+		m.processEvictions()
+		select {
+		case <-m.evictionSignal:
+		case <-m.clock.After(interval):
+		case <-m.stopChannel:
+			return
+		}
+	}
+}
+
+// processEvictions evicts queued pods until the rate limit is reached. Pods
+// whose problem was resolved while they were queued are dropped from the
+// queue without being evicted.
+func (m *Monitor) processEvictions() {
+	for {
+		m.evictionLock.Lock()
+		if len(m.evictionQueue) == 0 {
+			m.evictionLock.Unlock()
+			return
+		}
+		eviction := m.evictionQueue[0]
+		m.evictionLock.Unlock()
+
+		recovered := eviction.isRecovered()
+		m.evictionLock.Lock()
+		if !recovered && !m.evictionLimiter.TryAccept() {
+			m.evictionLock.Unlock()
+			return
 		}
+		m.evictionQueue = m.evictionQueue[1:]
+		delete(m.queuedEvictions, eviction.pod.UID)
+		m.evictionLock.Unlock()
+
+		if recovered {
+			storklog.PodLog(&eviction.pod).Infof("Not evicting Pod from Node %v since it has recovered", eviction.pod.Spec.NodeName)
+			continue
+		}
+		m.evictPod(&eviction.pod, eviction.reason, eviction.cause)
+	}
+}
+
+// evictPod evicts the pod using the Eviction API so that PodDisruptionBudgets
+// are honored. The pod is deleted without a grace period since its storage is
+// unavailable.
+func (m *Monitor) evictPod(pod *v1.Pod, reason string, cause string) {
+	msg := fmt.Sprintf("Evicting Pod from Node %v %v", pod.Spec.NodeName, cause)
+	storklog.PodLog(pod).Infof(msg)
+	m.Recorder.Event(pod, v1.EventTypeWarning, reason, msg)
+	gracePeriod := int64(0)
+	eviction := &policyv1beta1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+		},
+		DeleteOptions: &metav1.DeleteOptions{
+			GracePeriodSeconds: &gracePeriod,
+		},
+	}
+	if err := m.KubeClient.CoreV1().Pods(pod.Namespace).Evict(context.TODO(), eviction); err != nil {
+		if errors.IsNotFound(err) {
+			return
+		}
+		if errors.IsTooManyRequests(err) {
+			msg := fmt.Sprintf("Eviction of Pod from Node %v blocked by PodDisruptionBudget: %v", pod.Spec.NodeName, err)
+			storklog.PodLog(pod).Warnf(msg)
+			m.Recorder.Event(pod, v1.EventTypeWarning, podEvictionBlockedReason, msg)
+			EvictionsBlockedCounter.Inc()
+			return
+		}
+		storklog.PodLog(pod).Errorf("Error evicting pod: %v", err)
+		return
+	}
+	HealthCounter.Inc()
+}
+
+func (m *Monitor) isNodeOnline(node *volume.NodeInfo) bool {
+	n, err := m.Driver.InspectNode(node.StorageID)
+	if err != nil {
+		return false
+	}
+	if n.Status != volume.NodeOnline {
+		log.Infof("Volume driver on node %v (%v) is still offline (%v)", node.Hostname, node.StorageID, n.RawStatus)
+		return false
+	}
+	return true
+}
+
+// isPodEvictionDisabled returns true if the namespace has opted out of pods
+// being evicted when the storage driver goes offline
+func (m *Monitor) isPodEvictionDisabled(namespace string) bool {
+	ns, err := core.Instance().GetNamespace(namespace)
+	if err != nil {
+		log.Debugf("Error getting namespace %v to check if pod eviction is disabled: %v", namespace, err)
+		return false
 	}
+	return ns.Annotations[DisablePodEvictionAnnotation] == "true"
 }
 
 func (m *Monitor) doesDriverOwnPodVolumes(pod *v1.Pod) (bool, error) {
@@ -18,12 +18,17 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/wait"
 	kubernetes "k8s.io/client-go/kubernetes/fake"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	k8stesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/kubernetes/pkg/api/legacyscheme"
 	"k8s.io/kubernetes/pkg/util/node"
@@ -39,12 +44,15 @@ var (
 	driverVolumeName      = "singleVolume"
 	attachmentVolumeName  = "attachmentVolume"
 	unknownPodsVolumeName = "unknownPodsVolume"
+	unavailableVolumeName = "unavailableVolume"
+	degradedVolumeName    = "degradedVolume"
 
 	fakeStorkClient        *fakeclient.Clientset
 	driver                 *mock.Driver
 	monitor                *Monitor
 	nodes                  *v1.NodeList
 	testNodeOfflineTimeout time.Duration
+	evictionBlockedPods    map[string]bool
 )
 
 func TestMonitor(t *testing.T) {
@@ -58,6 +66,10 @@ func TestMonitor(t *testing.T) {
 	t.Run("testOfflineStorageNodeDuplicateIP", testOfflineStorageNodeDuplicateIP)
 	t.Run("testVolumeAttachmentCleanup", testVolumeAttachmentCleanup)
 	t.Run("teardown", teardown)
+	// These use their own monitor with a fake clock
+	t.Run("testEvictionRateLimit", testEvictionRateLimit)
+	t.Run("testNodeGracePeriod", testNodeGracePeriod)
+	t.Run("testUnhealthyVolumeEviction", testUnhealthyVolumeEviction)
 }
 
 func setup(t *testing.T) {
@@ -71,6 +83,19 @@ func setup(t *testing.T) {
 	core.SetInstance(core.New(fakeKubeClient))
 	storage.SetInstance(storage.New(fakeKubeClient.StorageV1()))
 	storkops.SetInstance(storkops.New(fakeKubeClient, fakeStorkClient, nil))
+	// Evict pods from the fake client unless they are blocked by a
+	// PodDisruptionBudget
+	evictionBlockedPods = make(map[string]bool)
+	fakeKubeClient.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1beta1.Eviction)
+		if evictionBlockedPods[eviction.Name] {
+			return true, nil, errors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+		}
+		return true, nil, fakeKubeClient.Tracker().Delete(v1.SchemeGroupVersion.WithResource("pods"), eviction.Namespace, eviction.Name)
+	})
 
 	storkdriver, err := volume.Get(mockDriverName)
 	require.NoError(t, err, "Error getting mock volume driver")
@@ -114,14 +139,22 @@ func setup(t *testing.T) {
 	err = driver.ProvisionVolume(unknownPodsVolumeName, provNodes, 3, nil)
 	require.NoError(t, err, "Error provisioning volume")
 
+	err = driver.ProvisionVolume(unavailableVolumeName, provNodes, 4, nil)
+	require.NoError(t, err, "Error provisioning volume")
+
+	err = driver.ProvisionVolume(degradedVolumeName, provNodes, 5, nil)
+	require.NoError(t, err, "Error provisioning volume")
+
 	eventBroadcaster := record.NewBroadcaster()
 	eventBroadcaster.StartRecordingToSink(&corev1.EventSinkImpl{Interface: corev1.New(fakeKubeClient.CoreV1().RESTClient()).Events("")})
 	recorder := eventBroadcaster.NewRecorder(legacyscheme.Scheme, v1.EventSource{Component: "storktest"})
 
 	monitor = &Monitor{
-		Driver:      storkdriver,
-		IntervalSec: 30,
-		Recorder:    recorder,
+		Driver:            storkdriver,
+		IntervalSec:       30,
+		Recorder:          recorder,
+		KubeClient:        fakeKubeClient,
+		VolumeHealthCheck: true,
 	}
 
 	// overwrite the backoff timers to speed up the tests
@@ -245,7 +278,10 @@ func testLostPod(
 
 func newPod(podName string, volumes []string) *v1.Pod {
 	pod := &v1.Pod{
-		ObjectMeta: metav1.ObjectMeta{Name: podName},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: podName,
+			UID:  types.UID(podName),
+		},
 	}
 	for _, volume := range volumes {
 		pvc := driver.NewPVC(volume)
@@ -493,3 +529,222 @@ func testVolumeAttachmentCleanup(t *testing.T) {
 	// total pods rescheduled during UT's
 	require.Equal(t, testutil.ToFloat64(HealthCounter), float64(8), "pods_reschduled_total not matched")
 }
+
+// newTestMonitor returns a monitor that uses a fake clock. It isn't started,
+// evictions are processed by calling processEvictions.
+func newTestMonitor(evictionsPerMinute int64, nodeGracePeriodSec int64) (*Monitor, *clock.FakeClock) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	m := &Monitor{
+		Driver:             monitor.Driver,
+		Recorder:           monitor.Recorder,
+		KubeClient:         monitor.KubeClient,
+		EvictionsPerMinute: evictionsPerMinute,
+		NodeGracePeriodSec: nodeGracePeriodSec,
+		clock:              fakeClock,
+	}
+	m.initEvictions()
+	return m, fakeClock
+}
+
+func testEvictionRateLimit(t *testing.T) {
+	optOutNamespace := &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "eviction-optout",
+			Annotations: map[string]string{
+				DisablePodEvictionAnnotation: "true",
+			},
+		},
+	}
+	_, err := core.Instance().CreateNamespace(optOutNamespace)
+	require.NoError(t, err, "failed to create namespace")
+
+	firstPod := newPod("evictedPod1", []string{driverVolumeName})
+	blockedPod := newPod("evictionBlockedPod", []string{driverVolumeName})
+	evictionBlockedPods[blockedPod.Name] = true
+	secondPod := newPod("evictedPod2", []string{driverVolumeName})
+	recoveredPod := newPod("evictionRecoveredPod", []string{driverVolumeName})
+	optOutPod := newPod("evictionOptOutPod", []string{driverVolumeName})
+	optOutPod.Namespace = optOutNamespace.Name
+	pods := []v1.Pod{*firstPod, *blockedPod, *secondPod, *recoveredPod, *optOutPod}
+	for _, pod := range pods {
+		_, err := core.Instance().CreatePod(&pod)
+		require.NoError(t, err, "failed to create pod")
+	}
+
+	rescheduled := testutil.ToFloat64(HealthCounter)
+	deferred := testutil.ToFloat64(EvictionsDeferredCounter.WithLabelValues(evictionDeferredRateLimit))
+	blocked := testutil.ToFloat64(EvictionsBlockedCounter)
+	requirePodExists := func(pod *v1.Pod, exists bool) {
+		_, err := core.Instance().GetPodByName(pod.Name, pod.Namespace)
+		if exists {
+			require.NoError(t, err, "expected pod %v to exist", pod.Name)
+		} else {
+			require.Error(t, err, "expected pod %v to be evicted", pod.Name)
+		}
+	}
+
+	// One pod is evicted every 30 seconds, the first one right away
+	m, fakeClock := newTestMonitor(2, 0)
+	recovered := false
+	isRecovered := func() bool { return recovered }
+	m.evictPods(pods, storageDriverOfflineReason, "for test", isRecovered)
+	requirePodExists(firstPod, false)
+	require.Len(t, m.evictionQueue, 3, "Evictions should have been queued")
+	require.Equal(t, deferred+3, testutil.ToFloat64(EvictionsDeferredCounter.WithLabelValues(evictionDeferredRateLimit)),
+		"pod_evictions_deferred_total not matched")
+
+	// Pods that are already queued aren't deferred again in the next check
+	m.evictPods(pods[1:], storageDriverOfflineReason, "for test", isRecovered)
+	require.Len(t, m.evictionQueue, 3, "Evictions should only be queued once")
+	require.Equal(t, deferred+3, testutil.ToFloat64(EvictionsDeferredCounter.WithLabelValues(evictionDeferredRateLimit)),
+		"pod_evictions_deferred_total not matched")
+
+	m.processEvictions()
+	require.Len(t, m.evictionQueue, 3, "No evictions should be processed before the rate limit allows")
+
+	fakeClock.Step(30 * time.Second)
+	m.processEvictions()
+	require.Len(t, m.evictionQueue, 2, "One eviction should have been processed")
+	requirePodExists(blockedPod, true)
+	require.Equal(t, blocked+1, testutil.ToFloat64(EvictionsBlockedCounter), "pod_evictions_blocked_total not matched")
+
+	fakeClock.Step(30 * time.Second)
+	m.processEvictions()
+	require.Len(t, m.evictionQueue, 1, "One eviction should have been processed")
+	requirePodExists(secondPod, false)
+
+	// Queued evictions are dropped once the problem is resolved
+	recovered = true
+	fakeClock.Step(30 * time.Second)
+	m.processEvictions()
+	require.Empty(t, m.evictionQueue, "Recovered eviction should have been dropped")
+	require.Empty(t, m.queuedEvictions, "Recovered eviction should have been dropped")
+	requirePodExists(recoveredPod, true)
+	requirePodExists(optOutPod, true)
+
+	require.Equal(t, rescheduled+2, testutil.ToFloat64(HealthCounter), "pods_rescheduled_total not matched")
+	require.Equal(t, blocked+1, testutil.ToFloat64(EvictionsBlockedCounter), "pod_evictions_blocked_total not matched")
+}
+
+func testNodeGracePeriod(t *testing.T) {
+	// No other tests use the 5th node
+	nodeIndex := 4
+	driverNodes, err := driver.GetNodes()
+	require.NoError(t, err, "Error getting driver nodes")
+	offlineDriverNode := driverNodes[nodeIndex]
+	err = driver.UpdateNodeStatus(nodeIndex, volume.NodeOffline)
+	require.NoError(t, err, "Error setting node status to Offline")
+	defer func() {
+		err = driver.UpdateNodeStatus(nodeIndex, volume.NodeOnline)
+		require.NoError(t, err, "Error setting node status to Online")
+	}()
+
+	pods := []*v1.Pod{
+		newPod("gracePeriodPod1", []string{driverVolumeName}),
+		newPod("gracePeriodPod2", []string{driverVolumeName}),
+	}
+	for _, pod := range pods {
+		pod.Spec.NodeName = offlineDriverNode.Hostname
+		_, err := core.Instance().CreatePod(pod)
+		require.NoError(t, err, "failed to create pod")
+	}
+
+	rescheduled := testutil.ToFloat64(HealthCounter)
+	deferred := testutil.ToFloat64(EvictionsDeferredCounter.WithLabelValues(evictionDeferredGracePeriod))
+
+	m, fakeClock := newTestMonitor(60, 120)
+	offline := &offlineNode{
+		since:        fakeClock.Now(),
+		deferredPods: make(map[types.UID]bool),
+	}
+	m.evictDriverNodePods(offlineDriverNode, offline)
+	require.Equal(t, deferred+2, testutil.ToFloat64(EvictionsDeferredCounter.WithLabelValues(evictionDeferredGracePeriod)),
+		"pod_evictions_deferred_total not matched")
+
+	// The deferral is only reported once for each pod
+	fakeClock.Step(60 * time.Second)
+	m.evictDriverNodePods(offlineDriverNode, offline)
+	require.Equal(t, deferred+2, testutil.ToFloat64(EvictionsDeferredCounter.WithLabelValues(evictionDeferredGracePeriod)),
+		"pod_evictions_deferred_total not matched")
+	require.Equal(t, rescheduled, testutil.ToFloat64(HealthCounter), "No pods should be evicted in the grace period")
+
+	// The first pod is evicted right away once the grace period is over and
+	// the second one once the rate limit allows
+	fakeClock.Step(61 * time.Second)
+	m.evictDriverNodePods(offlineDriverNode, offline)
+	require.Equal(t, rescheduled+1, testutil.ToFloat64(HealthCounter), "pods_rescheduled_total not matched")
+	fakeClock.Step(time.Second)
+	m.processEvictions()
+	require.Equal(t, rescheduled+2, testutil.ToFloat64(HealthCounter), "pods_rescheduled_total not matched")
+	for _, pod := range pods {
+		_, err = core.Instance().GetPodByName(pod.Name, "")
+		require.Error(t, err, "expected error from get pod as pod should be evicted")
+	}
+}
+
+// inspectCountingDriver counts the number of times each volume is inspected
+type inspectCountingDriver struct {
+	volume.Driver
+	inspects map[string]int
+}
+
+func (d *inspectCountingDriver) InspectVolume(volumeID string) (*volume.Info, error) {
+	d.inspects[volumeID]++
+	return d.Driver.InspectVolume(volumeID)
+}
+
+func testUnhealthyVolumeEviction(t *testing.T) {
+	unavailablePod := newPod("unavailableVolumePod", []string{unavailableVolumeName})
+	unavailablePod.Status.Phase = v1.PodRunning
+	_, err := core.Instance().CreatePod(unavailablePod)
+	require.NoError(t, err, "failed to create pod")
+	degradedPods := []*v1.Pod{
+		newPod("degradedVolumePod1", []string{degradedVolumeName}),
+		newPod("degradedVolumePod2", []string{degradedVolumeName}),
+	}
+	for _, pod := range degradedPods {
+		pod.Status.Phase = v1.PodRunning
+		_, err = core.Instance().CreatePod(pod)
+		require.NoError(t, err, "failed to create pod")
+	}
+
+	rescheduled := testutil.ToFloat64(HealthCounter)
+
+	err = driver.UpdateVolumeStatus(unavailableVolumeName, volume.VolumeUnavailable)
+	require.NoError(t, err, "Error setting volume status to Unavailable")
+	err = driver.UpdateVolumeStatus(degradedVolumeName, volume.VolumeDegraded)
+	require.NoError(t, err, "Error setting volume status to Degraded")
+	defer func() {
+		err = driver.UpdateVolumeStatus(unavailableVolumeName, volume.VolumeHealthy)
+		require.NoError(t, err, "Error setting volume status to Healthy")
+		err = driver.UpdateVolumeStatus(degradedVolumeName, volume.VolumeHealthy)
+		require.NoError(t, err, "Error setting volume status to Healthy")
+	}()
+
+	m, _ := newTestMonitor(60, 0)
+	countingDriver := &inspectCountingDriver{Driver: m.Driver, inspects: make(map[string]int)}
+	m.Driver = countingDriver
+
+	// The volume has to be unavailable for two checks before the pod is
+	// evicted
+	unhealthy := m.checkPodVolumes(nil)
+	require.Equal(t, volume.VolumeUnavailable, unhealthy[unavailableVolumeName])
+	require.Equal(t, volume.VolumeDegraded, unhealthy[degradedVolumeName])
+	require.Equal(t, 1, countingDriver.inspects[degradedVolumeName], "volume should only be inspected once per check")
+	_, err = core.Instance().GetPodByName(unavailablePod.Name, "")
+	require.NoError(t, err, "expected no error from get pod as volume has only been unavailable for one check")
+
+	m.checkPodVolumes(unhealthy)
+	_, err = core.Instance().GetPodByName(unavailablePod.Name, "")
+	require.Error(t, err, "expected error from get pod as pod should be evicted")
+	for _, pod := range degradedPods {
+		_, err = core.Instance().GetPodByName(pod.Name, "")
+		require.NoError(t, err, "expected no error from get pod as degraded volumes don't evict pods")
+	}
+
+	require.Equal(t, rescheduled+1, testutil.ToFloat64(HealthCounter), "pods_rescheduled_total not matched")
+	require.Equal(t, float64(2), testutil.ToFloat64(UnhealthyVolumePodsGauge.WithLabelValues(
+		degradedVolumeName, string(volume.VolumeDegraded))), "unhealthy_volume_pods not matched")
+	require.Equal(t, float64(1), testutil.ToFloat64(UnhealthyVolumePodsGauge.WithLabelValues(
+		unavailableVolumeName, string(volume.VolumeUnavailable))), "unhealthy_volume_pods not matched")
+}
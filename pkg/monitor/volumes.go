@@ -0,0 +1,137 @@
+package monitor
+
+import (
+	"fmt"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	storklog "github.com/libopenstorage/stork/pkg/log"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	volumeUnhealthyReason = "VolumeUnhealthy"
+	volumeDegradedReason  = "VolumeDegraded"
+)
+
+var (
+	// UnhealthyVolumePodsGauge for the number of running pods using each
+	// volume that is degraded, unavailable or detached
+	UnhealthyVolumePodsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "stork_unhealthy_volume_pods",
+		Help: "Number of running pods using volumes that are degraded, unavailable or detached",
+	}, []string{"volume", "status"})
+)
+
+// isVolumeUnavailable returns true if pods can't use the volume
+func isVolumeUnavailable(status volume.VolumeStatus) bool {
+	return status == volume.VolumeUnavailable || status == volume.VolumeDetached
+}
+
+// checkPodVolumes inspects the volumes used by running pods. Pods using a
+// volume that was also unavailable or detached in the previous check are
+// evicted so that they can be rescheduled. Pods using degraded volumes only
+// get an event. Each volume is only inspected once per check. Returns the
+// status of the volumes that weren't healthy in this check.
+func (m *Monitor) checkPodVolumes(previous map[string]volume.VolumeStatus) map[string]volume.VolumeStatus {
+	pods, err := core.Instance().GetPods("", nil)
+	if err != nil {
+		log.Errorf("Error getting pods to check volume health: %v", err)
+		return previous
+	}
+
+	unhealthy := make(map[string]volume.VolumeStatus)
+	inspected := make(map[string]*volume.Info)
+	UnhealthyVolumePodsGauge.Reset()
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != v1.PodRunning || pod.DeletionTimestamp != nil || pod.Spec.NodeName == "" {
+			continue
+		}
+		volumes, err := m.inspectPodVolumes(&pod, inspected)
+		if err != nil || len(volumes) == 0 {
+			continue
+		}
+
+		cause := ""
+		for _, vol := range volumes {
+			if vol.Status != volume.VolumeDegraded && !isVolumeUnavailable(vol.Status) {
+				continue
+			}
+			UnhealthyVolumePodsGauge.With(prometheus.Labels{
+				"volume": vol.VolumeName,
+				"status": string(vol.Status),
+			}).Inc()
+			previousStatus, seen := previous[vol.VolumeID]
+			unhealthy[vol.VolumeID] = vol.Status
+
+			if vol.Status == volume.VolumeDegraded {
+				if !seen {
+					msg := fmt.Sprintf("Volume %v used by Pod is degraded (%v)", vol.VolumeName, vol.RawStatus)
+					storklog.PodLog(&pod).Warnf(msg)
+					m.Recorder.Event(&pod, v1.EventTypeWarning, volumeDegradedReason, msg)
+				}
+				continue
+			}
+			// Give the volume one interval to recover before moving the pod
+			if isVolumeUnavailable(previousStatus) {
+				cause = fmt.Sprintf("since volume %v is %v (%v)", vol.VolumeName, vol.Status, vol.RawStatus)
+				continue
+			}
+			msg := fmt.Sprintf("Volume %v used by Pod is %v (%v), Pod will be evicted if it doesn't recover",
+				vol.VolumeName, vol.Status, vol.RawStatus)
+			storklog.PodLog(&pod).Warnf(msg)
+			m.Recorder.Event(&pod, v1.EventTypeWarning, volumeUnhealthyReason, msg)
+		}
+
+		if cause != "" {
+			pod := pod
+			m.evictPods([]v1.Pod{pod}, volumeUnhealthyReason, cause, func() bool {
+				return m.arePodVolumesAvailable(&pod)
+			})
+		}
+	}
+	return unhealthy
+}
+
+// inspectPodVolumes returns the current state of the pod's volumes that are
+// backed by the driver. Volumes found in inspected aren't inspected again and
+// the volumes that are inspected are added to it, if it isn't nil.
+func (m *Monitor) inspectPodVolumes(pod *v1.Pod, inspected map[string]*volume.Info) ([]*volume.Info, error) {
+	podVolumes, _, err := m.Driver.GetPodVolumes(&pod.Spec, pod.Namespace, false)
+	if err != nil {
+		return nil, err
+	}
+	volumes := make([]*volume.Info, 0)
+	for _, podVolume := range podVolumes {
+		vol, ok := inspected[podVolume.VolumeID]
+		if !ok {
+			vol, err = m.Driver.InspectVolume(podVolume.VolumeID)
+			if err != nil {
+				storklog.PodLog(pod).Errorf("Error inspecting volume %v: %v", podVolume.VolumeName, err)
+				vol = nil
+			}
+			if inspected != nil {
+				inspected[podVolume.VolumeID] = vol
+			}
+		}
+		if vol != nil {
+			volumes = append(volumes, vol)
+		}
+	}
+	return volumes, nil
+}
+
+func (m *Monitor) arePodVolumesAvailable(pod *v1.Pod) bool {
+	volumes, err := m.inspectPodVolumes(pod, nil)
+	if err != nil {
+		return false
+	}
+	for _, vol := range volumes {
+		if isVolumeUnavailable(vol.Status) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package file
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/objectstore/common"
+	"github.com/sirupsen/logrus"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/fileblob"
+)
+
+const (
+	// The directory for the backup location is created with these
+	// permissions
+	dirPermissions = 0755
+)
+
+// GetBucket gets a reference to the bucket for that backup location. Objects
+// are files in the directory at the path of the backup location, for example
+// an NFS export mounted in the stork pods.
+func GetBucket(backupLocation *stork_api.BackupLocation) (*blob.Bucket, error) {
+	dir, err := getDir(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := fileblob.OpenBucket(dir, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error accessing path %v for backup location: %v", dir, err)
+	}
+	return bucket, nil
+}
+
+// CreateBucket creates the directory for the backup location
+func CreateBucket(backupLocation *stork_api.BackupLocation) error {
+	dir, err := getDir(backupLocation)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(dir, dirPermissions)
+}
+
+// GetObjLockInfo fetches the object lock configuration of a bucket
+func GetObjLockInfo(backupLocation *stork_api.BackupLocation) (*common.ObjLockInfo, error) {
+	logrus.Infof("object lock is not supported for file provider")
+	return &common.ObjLockInfo{}, nil
+}
+
+func getDir(backupLocation *stork_api.BackupLocation) (string, error) {
+	dir := backupLocation.Location.Path
+	if dir == "" {
+		return "", fmt.Errorf("path is required for backup locations of type %v", stork_api.BackupLocationFile)
+	}
+	if !filepath.IsAbs(dir) {
+		return "", fmt.Errorf("path %v for backup location should be an absolute path", dir)
+	}
+	return filepath.Clean(dir), nil
+}
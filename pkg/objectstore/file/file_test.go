@@ -0,0 +1,179 @@
+//go:build unittest
+// +build unittest
+
+package file
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
+)
+
+func getTestBucket(t *testing.T) (*blob.Bucket, string) {
+	dir, err := ioutil.TempDir("", "filebucket")
+	require.NoError(t, err, "Error creating bucket directory")
+	backupLocation := &stork_api.BackupLocation{
+		Location: stork_api.BackupLocationItem{
+			Type: stork_api.BackupLocationFile,
+			Path: filepath.Join(dir, "backups"),
+		},
+	}
+	_, err = GetBucket(backupLocation)
+	require.Error(t, err, "Getting a bucket for a missing directory should have failed")
+	require.NoError(t, CreateBucket(backupLocation), "Error creating bucket")
+	bucket, err := GetBucket(backupLocation)
+	require.NoError(t, err, "Error getting bucket")
+	return bucket, dir
+}
+
+func TestInvalidPath(t *testing.T) {
+	backupLocation := &stork_api.BackupLocation{
+		Location: stork_api.BackupLocationItem{
+			Type: stork_api.BackupLocationFile,
+		},
+	}
+	require.Error(t, CreateBucket(backupLocation), "Empty path should be rejected")
+	backupLocation.Location.Path = "relative/path"
+	require.Error(t, CreateBucket(backupLocation), "Relative path should be rejected")
+}
+
+func TestReadWriteDelete(t *testing.T) {
+	bucket, dir := getTestBucket(t)
+	defer os.RemoveAll(dir) // nolint: errcheck
+	ctx := context.Background()
+
+	require.NoError(t, bucket.WriteAll(ctx, "ns/backup/uid/metadata.json", []byte("metadata"), nil))
+	exists, err := bucket.Exists(ctx, "ns/backup/uid/metadata.json")
+	require.NoError(t, err, "Error checking if object exists")
+	require.True(t, exists, "Object should exist")
+	data, err := bucket.ReadAll(ctx, "ns/backup/uid/metadata.json")
+	require.NoError(t, err, "Error reading object")
+	require.Equal(t, "metadata", string(data), "Object data mismatch")
+
+	exists, err = bucket.Exists(ctx, "ns/backup/uid")
+	require.NoError(t, err, "Error checking if directory exists")
+	require.False(t, exists, "Directories should not be objects")
+
+	_, err = bucket.ReadAll(ctx, "ns/backup/uid/missing.json")
+	require.Equal(t, gcerrors.NotFound, gcerrors.Code(err), "Reading a missing object should return NotFound")
+	_, err = bucket.ReadAll(ctx, "../outside.json")
+	require.Error(t, err, "Keys outside the directory should be rejected")
+
+	require.NoError(t, bucket.Delete(ctx, "ns/backup/uid/metadata.json"), "Error deleting object")
+	err = bucket.Delete(ctx, "ns/backup/uid/metadata.json")
+	require.Equal(t, gcerrors.NotFound, gcerrors.Code(err), "Deleting a missing object should return NotFound")
+}
+
+func TestAbortedWrite(t *testing.T) {
+	bucket, dir := getTestBucket(t)
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	ctx, cancel := context.WithCancel(context.Background())
+	writer, err := bucket.NewWriter(ctx, "ns/backup/uid/resources.json", nil)
+	require.NoError(t, err, "Error creating writer")
+	_, err = writer.Write(make([]byte, 1024*1024))
+	require.NoError(t, err, "Error writing object")
+	cancel()
+	require.Error(t, writer.Close(), "Closing a cancelled writer should fail")
+
+	exists, err := bucket.Exists(context.Background(), "ns/backup/uid/resources.json")
+	require.NoError(t, err, "Error checking if object exists")
+	require.False(t, exists, "Aborted object should not exist")
+	files, err := ioutil.ReadDir(filepath.Join(dir, "backups", "ns", "backup", "uid"))
+	require.NoError(t, err, "Error reading object directory")
+	require.Empty(t, files, "Temporary files should be removed")
+}
+
+func TestList(t *testing.T) {
+	bucket, dir := getTestBucket(t)
+	defer os.RemoveAll(dir) // nolint: errcheck
+	ctx := context.Background()
+
+	keys := []string{
+		"ns/backup1/uid1/metadata.json",
+		"ns/backup1/uid2/metadata.json",
+		"ns/backup2/uid3/metadata.json",
+		"ns/backup2/uid3/resources.json",
+	}
+	for _, key := range keys {
+		require.NoError(t, bucket.WriteAll(ctx, key, []byte(key), nil))
+	}
+
+	listed := make([]string, 0)
+	iterator := bucket.List(&blob.ListOptions{Prefix: "ns/"})
+	for {
+		object, err := iterator.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err, "Error listing objects")
+		require.False(t, object.IsDir, "No directories should be listed without a delimiter")
+		listed = append(listed, object.Key)
+	}
+	require.Equal(t, keys, listed, "Listed objects mismatch")
+
+	listed = make([]string, 0)
+	iterator = bucket.List(&blob.ListOptions{Prefix: "ns/backup1/", Delimiter: "/"})
+	for {
+		object, err := iterator.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err, "Error listing objects")
+		require.True(t, object.IsDir, "Only directories should be listed with a delimiter")
+		listed = append(listed, object.Key)
+	}
+	require.Equal(t, []string{"ns/backup1/uid1/", "ns/backup1/uid2/"}, listed, "Listed directories mismatch")
+}
+
+func TestListPrefix(t *testing.T) {
+	bucket, dir := getTestBucket(t)
+	defer os.RemoveAll(dir) // nolint: errcheck
+	ctx := context.Background()
+
+	keys := []string{
+		"ns1/backup1/uid1/metadata.json",
+		"ns1/backup1/uid2/metadata.json",
+		"ns1/backup10/uid3/metadata.json",
+		"ns1/backup2/uid4/metadata.json",
+		"ns2/backup1/uid5/metadata.json",
+	}
+	for _, key := range keys {
+		require.NoError(t, bucket.WriteAll(ctx, key, []byte(key), nil))
+	}
+	// Directories without any objects shouldn't be listed
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "backups", "ns1", "backup1", "empty"), 0755))
+
+	listKeys := func(opts *blob.ListOptions) []string {
+		listed := make([]string, 0)
+		iterator := bucket.List(opts)
+		for {
+			object, err := iterator.Next(ctx)
+			if err == io.EOF {
+				return listed
+			}
+			require.NoError(t, err, "Error listing objects")
+			listed = append(listed, object.Key)
+		}
+	}
+
+	require.Equal(t, keys[:3], listKeys(&blob.ListOptions{Prefix: "ns1/backup1"}), "Listed objects mismatch for partial prefix")
+	require.Equal(t, keys[:2], listKeys(&blob.ListOptions{Prefix: "ns1/backup1/"}), "Listed objects mismatch for directory prefix")
+	require.Equal(t,
+		[]string{"ns1/backup1/uid1/", "ns1/backup1/uid2/"},
+		listKeys(&blob.ListOptions{Prefix: "ns1/backup1/", Delimiter: "/"}),
+		"Listed directories mismatch")
+	require.Equal(t,
+		[]string{"ns1/", "ns2/"},
+		listKeys(&blob.ListOptions{Delimiter: "/"}),
+		"Listed top level directories mismatch")
+	require.Empty(t, listKeys(&blob.ListOptions{Prefix: "ns3/backup1/"}), "No objects should be listed for missing prefix")
+}
@@ -5,13 +5,30 @@ import (
 	"strings"
 
 	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/pmezard/go-difflib/difflib"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+const (
+	// maximum size of the diff stored for a transformed resource
+	maxTransformDiffBytes = 8192
+	// maximum total size of the diffs stored in the status of a
+	// transformation
+	maxTransformDiffTotalBytes = 256 * 1024
+	transformDiffContext       = 3
+	// TransformDiffOmitted is stored instead of the diff of a resource once
+	// the diffs of a transformation are too large to be stored
+	TransformDiffOmitted = "... diff omitted since the diffs of the transformation are too large\n"
+	// TransformDiffRedacted replaces the values of the data of secrets in
+	// diffs so that they aren't stored in the status of a transformation
+	TransformDiffRedacted = "<redacted>"
+)
+
 // Since we collect all resources from required migration namespace at once
 // getResourcePatch creates map of namespace: {kind: []resourceinfo{}}
 // to get transform spec for matching resources
@@ -43,15 +60,40 @@ func GetResourcePatch(transformName string, namespaces []string) (map[string]sto
 }
 
 // this method transform object as per resource transformation specified in each namespaces
+// clusterPair is used for templated values and can be nil
 func TransformResources(
 	object runtime.Unstructured,
 	resPatch []stork_api.TransformResourceInfo,
 	objName, objNamespace string,
+	clusterPair *stork_api.ClusterPair,
 ) error {
 	for _, patch := range resPatch {
 		if patch.Name == objName && patch.Namespace == objNamespace {
 			content := object.UnstructuredContent()
+			matched, err := matchResourceConditions(content, patch.Specs.Conditions)
+			if err != nil {
+				return err
+			}
+			if !matched {
+				logrus.Debugf("Conditions not matched for resource kind: %s/,%s/%s, skipping transformation", patch.Kind, patch.Namespace, patch.Name)
+				continue
+			}
 			for _, path := range patch.Specs.Paths {
+				matched, err := matchResourceConditions(content, path.Conditions)
+				if err != nil {
+					return err
+				}
+				if !matched {
+					logrus.Debugf("Conditions not matched for path %s on resource kind: %s/,%s/%s", path.Path, patch.Kind, patch.Namespace, patch.Name)
+					continue
+				}
+				if path.Template != "" {
+					if err := applyTemplatePath(content, path, clusterPair); err != nil {
+						logrus.Errorf("Unable to perform operation %s on path %s on resource kind: %s/,%s/%s,  err: %v", path.Operation, path.Path, patch.Kind, patch.Namespace, patch.Name, err)
+						return err
+					}
+					continue
+				}
 				switch path.Operation {
 				case stork_api.AddResourcePath:
 					value := getNewValueForPath(path.Value, string(path.Type))
@@ -76,6 +118,12 @@ func TransformResources(
 						}
 					}
 
+				case stork_api.RegexReplaceResourcePath:
+					if err := regexReplacePath(content, path); err != nil {
+						logrus.Errorf("Unable to perform operation %s on path %s on resource kind: %s/,%s/%s,  err: %v", path.Operation, path.Path, patch.Kind, patch.Namespace, patch.Name, err)
+						return err
+					}
+
 				case stork_api.DeleteResourcePath:
 					unstructured.RemoveNestedField(content, strings.Split(path.Path, ".")...)
 					logrus.Debugf("Removed patch path %s on resource kind: %s/,%s/%s", path, patch.Kind, patch.Namespace, patch.Name)
@@ -137,6 +185,87 @@ func TransformResources(
 	return nil
 }
 
+// TransformResourcesWithDiff transforms the object like TransformResources
+// and returns a unified diff of the object before and after it was
+// transformed. The diff is truncated if it is too large to be stored.
+func TransformResourcesWithDiff(
+	object runtime.Unstructured,
+	resPatch []stork_api.TransformResourceInfo,
+	objName, objNamespace string,
+	clusterPair *stork_api.ClusterPair,
+) (string, error) {
+	before, err := marshalForDiff(object)
+	if err != nil {
+		return "", err
+	}
+	if err := TransformResources(object, resPatch, objName, objNamespace, clusterPair); err != nil {
+		return "", err
+	}
+	after, err := marshalForDiff(object)
+	if err != nil {
+		return "", err
+	}
+	name := objName
+	if objNamespace != "" {
+		name = objNamespace + "/" + objName
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(before)),
+		B:        difflib.SplitLines(string(after)),
+		FromFile: name,
+		ToFile:   name + " (transformed)",
+		Context:  transformDiffContext,
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(diff) > maxTransformDiffBytes {
+		diff = diff[:maxTransformDiffBytes] + "\n... diff truncated\n"
+	}
+	return diff, nil
+}
+
+// marshalForDiff marshals an object to be diffed. The values of the data of
+// secrets are replaced with TransformDiffRedacted, so only the keys that were
+// added or removed show up in their diffs.
+func marshalForDiff(object runtime.Unstructured) ([]byte, error) {
+	content := object.UnstructuredContent()
+	if object.GetObjectKind().GroupVersionKind().GroupKind().String() == "Secret" {
+		content = runtime.DeepCopyJSON(content)
+		for _, field := range []string{"data", "stringData"} {
+			data, ok := content[field].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for key := range data {
+				data[key] = TransformDiffRedacted
+			}
+		}
+	}
+	return yaml.Marshal(content)
+}
+
+// LimitTransformDiffs replaces the diffs of the resources with
+// TransformDiffOmitted once the total size of the diffs is too large to be
+// stored in the status of a transformation. Returns the number of diffs that
+// were omitted.
+func LimitTransformDiffs(resources []*stork_api.TransformResourceInfo) int {
+	total := 0
+	omitted := 0
+	for _, resource := range resources {
+		if resource.Diff == "" || resource.Diff == TransformDiffOmitted {
+			continue
+		}
+		if total+len(resource.Diff) > maxTransformDiffTotalBytes {
+			resource.Diff = TransformDiffOmitted
+			omitted++
+			continue
+		}
+		total += len(resource.Diff)
+	}
+	return omitted
+}
+
 func getNewValueForPath(oldVal, valType string) interface{} {
 	var updatedValue interface{}
 	if valType == string(stork_api.KeyPairResourceType) {
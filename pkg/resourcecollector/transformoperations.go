@@ -0,0 +1,373 @@
+package resourcecollector
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+)
+
+const (
+	// suffix for path segments that match all the elements of a list
+	pathWildcardSuffix = "[*]"
+)
+
+// pathTarget is a field that a path of a resource resolves to
+type pathTarget struct {
+	value interface{}
+	found bool
+	set   func(interface{})
+}
+
+// transformTemplateData is what the template of a resource path is executed
+// with
+type transformTemplateData struct {
+	Object      map[string]interface{}
+	Value       interface{}
+	Lookup      map[string]string
+	ClusterPair map[string]interface{}
+}
+
+// ValidateTransformSpecs validates the kinds, operations, types and paths of
+// the specs of a resource transformation
+func ValidateTransformSpecs(specs []stork_api.TransformSpecs) error {
+	for _, spec := range specs {
+		gvk := strings.Split(spec.Resource, "/")
+		if len(gvk) != 3 {
+			return fmt.Errorf("invalid resource kind :%s", spec.Resource)
+		}
+		kind := gvk[2]
+		if !GetSupportedK8SResources(kind, []string{}) {
+			return fmt.Errorf("unsupported resource kind for transformation: %s", kind)
+		}
+		for _, path := range spec.Paths {
+			// TODO: this can be validated via CRDs as well, when we have defined schema
+			// for stork crds
+			// https://portworx.atlassian.net/browse/PWX-26465
+			if path.Operation == stork_api.JsonResourcePatch {
+				return fmt.Errorf("json patch for resources is not supported, operation: %s", path.Operation)
+			}
+			if !(path.Operation == stork_api.AddResourcePath || path.Operation == stork_api.DeleteResourcePath ||
+				path.Operation == stork_api.ModifyResourcePathValue || path.Operation == stork_api.RegexReplaceResourcePath) {
+				return fmt.Errorf("unsupported resource patch operation given for kind :%s, operation: %s", kind, path.Operation)
+			}
+			// type isn't used when replacing with regular expressions
+			if !(path.Type == stork_api.BoolResourceType || path.Type == stork_api.IntResourceType ||
+				path.Type == stork_api.StringResourceType || path.Type == stork_api.SliceResourceType ||
+				path.Type == stork_api.KeyPairResourceType ||
+				(path.Type == "" && path.Operation == stork_api.RegexReplaceResourcePath)) {
+				return fmt.Errorf("unsupported type for resource %s, path %s, type: %s", kind, path.Path, path.Type)
+			}
+			if err := ValidateResourcePath(path); err != nil {
+				return fmt.Errorf("invalid path for resource %s: %v", kind, err)
+			}
+		}
+		if err := ValidateResourceConditions(spec.Conditions); err != nil {
+			return fmt.Errorf("invalid conditions for resource %s: %v", kind, err)
+		}
+	}
+	return nil
+}
+
+// ValidateResourcePath validates the regular expression, template and
+// conditions of a resource path
+func ValidateResourcePath(path stork_api.ResourcePaths) error {
+	if strings.Contains(path.Path, pathWildcardSuffix) &&
+		path.Operation != stork_api.RegexReplaceResourcePath && path.Template == "" {
+		return fmt.Errorf("%v in path %v is only supported for %v and templated values",
+			pathWildcardSuffix, path.Path, stork_api.RegexReplaceResourcePath)
+	}
+	if path.Operation == stork_api.RegexReplaceResourcePath {
+		if path.Pattern == "" {
+			return fmt.Errorf("pattern is required for %v of path %v", path.Operation, path.Path)
+		}
+		if _, err := regexp.Compile(path.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern for path %v: %v", path.Path, err)
+		}
+		if path.Template != "" {
+			return fmt.Errorf("template isn't supported for %v of path %v", path.Operation, path.Path)
+		}
+	}
+	if path.Template != "" {
+		if path.Operation != stork_api.AddResourcePath && path.Operation != stork_api.ModifyResourcePathValue {
+			return fmt.Errorf("template isn't supported for %v of path %v", path.Operation, path.Path)
+		}
+		if _, err := newTransformTemplate(path); err != nil {
+			return fmt.Errorf("invalid template for path %v: %v", path.Path, err)
+		}
+	}
+	return ValidateResourceConditions(path.Conditions)
+}
+
+// ValidateResourceConditions validates the operators and values of
+// conditions on resources
+func ValidateResourceConditions(conditions []stork_api.ResourceCondition) error {
+	for _, condition := range conditions {
+		if condition.Path == "" {
+			return fmt.Errorf("path is required for conditions")
+		}
+		switch condition.Operator {
+		case stork_api.ConditionOperatorExists, stork_api.ConditionOperatorDoesNotExist:
+			if len(condition.Values) != 0 {
+				return fmt.Errorf("values aren't supported for operator %v of condition on path %v",
+					condition.Operator, condition.Path)
+			}
+		case stork_api.ConditionOperatorIn, stork_api.ConditionOperatorNotIn:
+			if len(condition.Values) == 0 {
+				return fmt.Errorf("values are required for operator %v of condition on path %v",
+					condition.Operator, condition.Path)
+			}
+		case stork_api.ConditionOperatorMatches:
+			if len(condition.Values) == 0 {
+				return fmt.Errorf("values are required for operator %v of condition on path %v",
+					condition.Operator, condition.Path)
+			}
+			for _, value := range condition.Values {
+				if _, err := regexp.Compile(value); err != nil {
+					return fmt.Errorf("invalid regular expression for condition on path %v: %v", condition.Path, err)
+				}
+			}
+		default:
+			return fmt.Errorf("unsupported operator %v for condition on path %v", condition.Operator, condition.Path)
+		}
+	}
+	return nil
+}
+
+// matchResourceConditions returns true if all the conditions match the
+// content of a resource
+func matchResourceConditions(content map[string]interface{}, conditions []stork_api.ResourceCondition) (bool, error) {
+	for _, condition := range conditions {
+		values := make([]string, 0)
+		for _, target := range getPathTargets(content, condition.Path, false) {
+			if target.found {
+				values = append(values, fmt.Sprintf("%v", target.value))
+			}
+		}
+		matched := false
+		switch condition.Operator {
+		case stork_api.ConditionOperatorExists:
+			matched = len(values) > 0
+		case stork_api.ConditionOperatorDoesNotExist:
+			matched = len(values) == 0
+		case stork_api.ConditionOperatorIn:
+			matched = containsAny(values, condition.Values)
+		case stork_api.ConditionOperatorNotIn:
+			matched = !containsAny(values, condition.Values)
+		case stork_api.ConditionOperatorMatches:
+			for _, pattern := range condition.Values {
+				re, err := regexp.Compile(pattern)
+				if err != nil {
+					return false, fmt.Errorf("invalid regular expression for condition on path %v: %v", condition.Path, err)
+				}
+				for _, value := range values {
+					if re.MatchString(value) {
+						matched = true
+					}
+				}
+			}
+		default:
+			return false, fmt.Errorf("unsupported operator %v for condition on path %v", condition.Operator, condition.Path)
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func containsAny(values []string, expected []string) bool {
+	for _, value := range values {
+		for _, e := range expected {
+			if value == e {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// regexReplacePath replaces the matches of the pattern in the string values
+// at the path. Strings in lists and maps at the path are also replaced.
+func regexReplacePath(content map[string]interface{}, path stork_api.ResourcePaths) error {
+	re, err := regexp.Compile(path.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern for path %v: %v", path.Path, err)
+	}
+	for _, target := range getPathTargets(content, path.Path, false) {
+		if !target.found {
+			continue
+		}
+		switch value := target.value.(type) {
+		case string:
+			target.set(re.ReplaceAllString(value, path.Value))
+		case []interface{}:
+			for i, item := range value {
+				if s, ok := item.(string); ok {
+					value[i] = re.ReplaceAllString(s, path.Value)
+				}
+			}
+		case map[string]interface{}:
+			for key, item := range value {
+				if s, ok := item.(string); ok {
+					value[key] = re.ReplaceAllString(s, path.Value)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// applyTemplatePath sets the value at the path to the result of executing
+// the template of the path
+func applyTemplatePath(
+	content map[string]interface{},
+	path stork_api.ResourcePaths,
+	clusterPair *stork_api.ClusterPair,
+) error {
+	tmpl, err := newTransformTemplate(path)
+	if err != nil {
+		return fmt.Errorf("invalid template for path %v: %v", path.Path, err)
+	}
+	if clusterPair == nil && strings.Contains(path.Template, ".ClusterPair") {
+		return fmt.Errorf("template for path %v uses .ClusterPair but no ClusterPair is known", path.Path)
+	}
+	clusterPairData := make(map[string]interface{})
+	if clusterPair != nil {
+		clusterPairData["Name"] = clusterPair.Name
+		clusterPairData["Namespace"] = clusterPair.Namespace
+		clusterPairData["Labels"] = clusterPair.Labels
+		clusterPairData["Annotations"] = clusterPair.Annotations
+	}
+	for _, target := range getPathTargets(content, path.Path, true) {
+		var buf bytes.Buffer
+		data := transformTemplateData{
+			Object:      content,
+			Value:       target.value,
+			Lookup:      path.Lookup,
+			ClusterPair: clusterPairData,
+		}
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return fmt.Errorf("error executing template for path %v: %v", path.Path, err)
+		}
+		value, err := getTemplateValueForType(buf.String(), path.Type)
+		if err != nil {
+			return fmt.Errorf("invalid value generated by template for path %v: %v", path.Path, err)
+		}
+		target.set(value)
+	}
+	return nil
+}
+
+func newTransformTemplate(path stork_api.ResourcePaths) (*template.Template, error) {
+	funcs := template.FuncMap{
+		"lookup": func(key string) (string, error) {
+			if value, ok := path.Lookup[key]; ok {
+				return value, nil
+			}
+			return "", fmt.Errorf("key %v not found in lookup", key)
+		},
+		"replace": func(old, new, s string) string {
+			return strings.ReplaceAll(s, old, new)
+		},
+		"regexReplace": func(pattern, replacement, s string) (string, error) {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return "", err
+			}
+			return re.ReplaceAllString(s, replacement), nil
+		},
+		"hasPrefix": func(prefix, s string) bool {
+			return strings.HasPrefix(s, prefix)
+		},
+		"trimPrefix": func(prefix, s string) string {
+			return strings.TrimPrefix(s, prefix)
+		},
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+	}
+	return template.New(path.Path).Funcs(funcs).Option("missingkey=error").Parse(path.Template)
+}
+
+// getTemplateValueForType converts the output of a template to the type of
+// the path
+func getTemplateValueForType(value string, valueType stork_api.ResourceTransformationValueType) (interface{}, error) {
+	switch valueType {
+	case stork_api.IntResourceType:
+		return strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	case stork_api.BoolResourceType:
+		return strconv.ParseBool(strings.TrimSpace(value))
+	case stork_api.SliceResourceType:
+		list := make([]interface{}, 0)
+		for _, item := range strings.Split(value, ",") {
+			list = append(list, item)
+		}
+		return list, nil
+	case stork_api.KeyPairResourceType:
+		keyPairs := make(map[string]interface{})
+		for _, item := range strings.Split(value, ",") {
+			keyPair := strings.Split(item, ":")
+			if len(keyPair) != 2 {
+				return nil, fmt.Errorf("invalid keypair value format :%s", item)
+			}
+			keyPairs[keyPair[0]] = keyPair[1]
+		}
+		return keyPairs, nil
+	}
+	return value, nil
+}
+
+// getPathTargets returns the fields that a path resolves to. Segments of the
+// path ending with [*] match all the elements of a list. Missing maps are
+// created if create is set.
+func getPathTargets(content map[string]interface{}, path string, create bool) []pathTarget {
+	targets := make([]pathTarget, 0)
+	resolvePathTargets(content, strings.Split(path, "."), create, &targets)
+	return targets
+}
+
+func resolvePathTargets(object interface{}, segments []string, create bool, targets *[]pathTarget) {
+	fields, ok := object.(map[string]interface{})
+	if !ok || len(segments) == 0 {
+		return
+	}
+	segment := segments[0]
+	if !strings.HasSuffix(segment, pathWildcardSuffix) {
+		value, found := fields[segment]
+		if len(segments) == 1 {
+			*targets = append(*targets, pathTarget{
+				value: value,
+				found: found,
+				set:   func(v interface{}) { fields[segment] = v },
+			})
+			return
+		}
+		if !found && create {
+			value = make(map[string]interface{})
+			fields[segment] = value
+		}
+		resolvePathTargets(value, segments[1:], create, targets)
+		return
+	}
+
+	list, ok := fields[strings.TrimSuffix(segment, pathWildcardSuffix)].([]interface{})
+	if !ok {
+		return
+	}
+	for i := range list {
+		if len(segments) == 1 {
+			index := i
+			*targets = append(*targets, pathTarget{
+				value: list[index],
+				found: true,
+				set:   func(v interface{}) { list[index] = v },
+			})
+			continue
+		}
+		resolvePathTargets(list[i], segments[1:], create, targets)
+	}
+}
@@ -0,0 +1,377 @@
+//go:build unittest
+// +build unittest
+
+package resourcecollector
+
+import (
+	"strings"
+	"testing"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newTestDeployment() *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":      "app",
+				"namespace": "test",
+				"labels": map[string]interface{}{
+					"tier": "frontend",
+				},
+			},
+			"spec": map[string]interface{}{
+				"replicas": int64(3),
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{
+								"name":  "app",
+								"image": "old.registry.io/team/app:v1",
+							},
+							map[string]interface{}{
+								"name":  "sidecar",
+								"image": "docker.io/proxy:v2",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func transformTestDeployment(
+	t *testing.T,
+	object *unstructured.Unstructured,
+	specs stork_api.TransformSpecs,
+	clusterPair *stork_api.ClusterPair,
+) error {
+	patch := stork_api.TransformResourceInfo{
+		Name:      "app",
+		Namespace: "test",
+		Specs:     specs,
+	}
+	return TransformResources(object, []stork_api.TransformResourceInfo{patch}, "app", "test", clusterPair)
+}
+
+func getTestImages(t *testing.T, object *unstructured.Unstructured) []string {
+	containers, _, err := unstructured.NestedSlice(object.Object, "spec", "template", "spec", "containers")
+	require.NoError(t, err, "Error getting containers")
+	images := make([]string, 0)
+	for _, container := range containers {
+		images = append(images, container.(map[string]interface{})["image"].(string))
+	}
+	return images
+}
+
+func TestTransformRegexReplace(t *testing.T) {
+	object := newTestDeployment()
+	err := transformTestDeployment(t, object, stork_api.TransformSpecs{
+		Paths: []stork_api.ResourcePaths{
+			{
+				Path:      "spec.template.spec.containers[*].image",
+				Operation: stork_api.RegexReplaceResourcePath,
+				Pattern:   `^old\.registry\.io/(.*)$`,
+				Value:     "new.registry.io/$1",
+			},
+		},
+	}, nil)
+	require.NoError(t, err, "Error transforming resource")
+	require.Equal(t, []string{"new.registry.io/team/app:v1", "docker.io/proxy:v2"}, getTestImages(t, object))
+	require.Equal(t, "true", object.GetAnnotations()[TransformedResourceName])
+}
+
+func TestTransformTemplate(t *testing.T) {
+	object := newTestDeployment()
+	clusterPair := &stork_api.ClusterPair{
+		ObjectMeta: meta.ObjectMeta{
+			Name:   "remote",
+			Labels: map[string]string{"registry": "dr.registry.io"},
+		},
+	}
+	err := transformTestDeployment(t, object, stork_api.TransformSpecs{
+		Paths: []stork_api.ResourcePaths{
+			{
+				Path:      "spec.template.spec.containers[*].image",
+				Operation: stork_api.ModifyResourcePathValue,
+				Type:      stork_api.StringResourceType,
+				Template:  `{{ .Value | replace "docker.io" (index .ClusterPair.Labels "registry") }}`,
+			},
+			{
+				Path:      "spec.replicas",
+				Operation: stork_api.ModifyResourcePathValue,
+				Type:      stork_api.IntResourceType,
+				Template:  `{{ lookup .Object.metadata.labels.tier }}`,
+				Lookup:    map[string]string{"frontend": "1", "backend": "2"},
+			},
+			{
+				Path:      "metadata.annotations.source",
+				Operation: stork_api.AddResourcePath,
+				Type:      stork_api.StringResourceType,
+				Template:  `{{ .Object.metadata.namespace }}/{{ .ClusterPair.Name }}`,
+			},
+		},
+	}, clusterPair)
+	require.NoError(t, err, "Error transforming resource")
+	require.Equal(t, []string{"old.registry.io/team/app:v1", "dr.registry.io/proxy:v2"}, getTestImages(t, object))
+	replicas, _, err := unstructured.NestedInt64(object.Object, "spec", "replicas")
+	require.NoError(t, err, "Error getting replicas")
+	require.Equal(t, int64(1), replicas)
+	require.Equal(t, "test/remote", object.GetAnnotations()["source"])
+
+	// Invalid value for the type
+	object = newTestDeployment()
+	err = transformTestDeployment(t, object, stork_api.TransformSpecs{
+		Paths: []stork_api.ResourcePaths{
+			{
+				Path:      "spec.replicas",
+				Operation: stork_api.ModifyResourcePathValue,
+				Type:      stork_api.IntResourceType,
+				Template:  `{{ .Object.metadata.name }}`,
+			},
+		},
+	}, nil)
+	require.Error(t, err, "Template generating a string for an int should fail")
+
+	failing := []stork_api.ResourcePaths{
+		{
+			Path:      "spec.replicas",
+			Operation: stork_api.ModifyResourcePathValue,
+			Type:      stork_api.IntResourceType,
+			Template:  `{{ lookup .Object.metadata.labels.tier }}`,
+			Lookup:    map[string]string{"backend": "2"},
+		},
+		{
+			Path:      "metadata.annotations.source",
+			Operation: stork_api.AddResourcePath,
+			Type:      stork_api.StringResourceType,
+			Template:  `{{ .Object.metadata.labels.owner }}`,
+		},
+		{
+			Path:      "metadata.annotations.source",
+			Operation: stork_api.AddResourcePath,
+			Type:      stork_api.StringResourceType,
+			Template:  `{{ .ClusterPair.Name }}`,
+		},
+	}
+	for _, path := range failing {
+		object = newTestDeployment()
+		err = transformTestDeployment(t, object, stork_api.TransformSpecs{
+			Paths: []stork_api.ResourcePaths{path},
+		}, nil)
+		require.Error(t, err, "Template %v should fail", path.Template)
+	}
+}
+
+func TestTransformConditions(t *testing.T) {
+	deletePath := stork_api.ResourcePaths{
+		Path:      "spec.replicas",
+		Operation: stork_api.DeleteResourcePath,
+		Type:      stork_api.IntResourceType,
+	}
+	tests := []struct {
+		conditions []stork_api.ResourceCondition
+		matched    bool
+	}{
+		{[]stork_api.ResourceCondition{{Path: "metadata.labels.tier", Operator: stork_api.ConditionOperatorExists}}, true},
+		{[]stork_api.ResourceCondition{{Path: "metadata.labels.app", Operator: stork_api.ConditionOperatorExists}}, false},
+		{[]stork_api.ResourceCondition{{Path: "metadata.labels.app", Operator: stork_api.ConditionOperatorDoesNotExist}}, true},
+		{[]stork_api.ResourceCondition{{Path: "spec.replicas", Operator: stork_api.ConditionOperatorIn, Values: []string{"2", "3"}}}, true},
+		{[]stork_api.ResourceCondition{{Path: "spec.replicas", Operator: stork_api.ConditionOperatorNotIn, Values: []string{"3"}}}, false},
+		{[]stork_api.ResourceCondition{{Path: "spec.template.spec.containers[*].image", Operator: stork_api.ConditionOperatorMatches, Values: []string{"^docker.io/"}}}, true},
+		{[]stork_api.ResourceCondition{{Path: "spec.template.spec.containers[*].image", Operator: stork_api.ConditionOperatorMatches, Values: []string{"^quay.io/"}}}, false},
+		{[]stork_api.ResourceCondition{
+			{Path: "metadata.labels.tier", Operator: stork_api.ConditionOperatorIn, Values: []string{"frontend"}},
+			{Path: "metadata.labels.tier", Operator: stork_api.ConditionOperatorIn, Values: []string{"backend"}},
+		}, false},
+	}
+	for _, test := range tests {
+		// Conditions on the path
+		object := newTestDeployment()
+		path := deletePath
+		path.Conditions = test.conditions
+		err := transformTestDeployment(t, object, stork_api.TransformSpecs{
+			Paths: []stork_api.ResourcePaths{path},
+		}, nil)
+		require.NoError(t, err, "Error transforming resource")
+		_, found, _ := unstructured.NestedInt64(object.Object, "spec", "replicas")
+		require.Equal(t, test.matched, !found, "Unexpected result for conditions on path %v", test.conditions)
+
+		// Conditions on the resource
+		object = newTestDeployment()
+		err = transformTestDeployment(t, object, stork_api.TransformSpecs{
+			Paths:      []stork_api.ResourcePaths{deletePath},
+			Conditions: test.conditions,
+		}, nil)
+		require.NoError(t, err, "Error transforming resource")
+		_, found, _ = unstructured.NestedInt64(object.Object, "spec", "replicas")
+		require.Equal(t, test.matched, !found, "Unexpected result for conditions on resource %v", test.conditions)
+		_, transformed := object.GetAnnotations()[TransformedResourceName]
+		require.Equal(t, test.matched, transformed)
+	}
+}
+
+func TestValidateResourcePath(t *testing.T) {
+	valid := []stork_api.ResourcePaths{
+		{Path: "spec.template.spec.containers[*].image", Operation: stork_api.RegexReplaceResourcePath, Pattern: "^a", Value: "b"},
+		{Path: "spec.storageClassName", Operation: stork_api.AddResourcePath, Template: "{{ lookup .Value }}"},
+		{Path: "spec.replicas", Operation: stork_api.DeleteResourcePath, Conditions: []stork_api.ResourceCondition{
+			{Path: "spec.replicas", Operator: stork_api.ConditionOperatorIn, Values: []string{"1"}},
+		}},
+	}
+	for _, path := range valid {
+		require.NoError(t, ValidateResourcePath(path), "Path %v should be valid", path)
+	}
+
+	invalid := []stork_api.ResourcePaths{
+		{Path: "spec.template.spec.containers[*].image", Operation: stork_api.AddResourcePath, Value: "b"},
+		{Path: "spec.host", Operation: stork_api.RegexReplaceResourcePath},
+		{Path: "spec.host", Operation: stork_api.RegexReplaceResourcePath, Pattern: "("},
+		{Path: "spec.host", Operation: stork_api.DeleteResourcePath, Template: "{{ .Value }}"},
+		{Path: "spec.host", Operation: stork_api.AddResourcePath, Template: "{{ .Value "},
+		{Path: "spec.host", Operation: stork_api.AddResourcePath, Conditions: []stork_api.ResourceCondition{
+			{Path: "spec.host", Operator: "Equals"},
+		}},
+		{Path: "spec.host", Operation: stork_api.AddResourcePath, Conditions: []stork_api.ResourceCondition{
+			{Path: "spec.host", Operator: stork_api.ConditionOperatorIn},
+		}},
+		{Path: "spec.host", Operation: stork_api.AddResourcePath, Conditions: []stork_api.ResourceCondition{
+			{Path: "spec.host", Operator: stork_api.ConditionOperatorExists, Values: []string{"a"}},
+		}},
+	}
+	for _, path := range invalid {
+		require.Error(t, ValidateResourcePath(path), "Path %v should be invalid", path)
+	}
+}
+
+func TestValidateTransformSpecs(t *testing.T) {
+	path := stork_api.ResourcePaths{Path: "spec.replicas", Operation: stork_api.ModifyResourcePathValue, Type: stork_api.IntResourceType, Value: "0"}
+	valid := []stork_api.TransformSpecs{
+		{Resource: "apps/v1/Deployment", Paths: []stork_api.ResourcePaths{path}},
+		{Resource: "/v1/Service", Paths: []stork_api.ResourcePaths{
+			{Path: "metadata.annotations", Operation: stork_api.RegexReplaceResourcePath, Pattern: "east", Value: "west"},
+		}},
+	}
+	require.NoError(t, ValidateTransformSpecs(valid))
+
+	invalid := []stork_api.TransformSpecs{
+		{Resource: "Deployment", Paths: []stork_api.ResourcePaths{path}},
+		{Resource: "core/v1/Unknown", Paths: []stork_api.ResourcePaths{path}},
+		{Resource: "apps/v1/Deployment", Paths: []stork_api.ResourcePaths{
+			{Path: "spec.replicas", Operation: stork_api.JsonResourcePatch, Type: stork_api.IntResourceType},
+		}},
+		{Resource: "apps/v1/Deployment", Paths: []stork_api.ResourcePaths{
+			{Path: "spec.replicas", Operation: stork_api.ModifyResourcePathValue, Type: "float"},
+		}},
+		{Resource: "apps/v1/Deployment", Paths: []stork_api.ResourcePaths{path}, Conditions: []stork_api.ResourceCondition{
+			{Path: "spec.replicas", Operator: "Equals"},
+		}},
+	}
+	for _, spec := range invalid {
+		require.Error(t, ValidateTransformSpecs([]stork_api.TransformSpecs{spec}), "Spec %v should be invalid", spec)
+	}
+}
+
+func TestTransformResourcesWithDiff(t *testing.T) {
+	object := newTestDeployment()
+	patch := stork_api.TransformResourceInfo{
+		Name:      "app",
+		Namespace: "test",
+		Specs: stork_api.TransformSpecs{
+			Paths: []stork_api.ResourcePaths{
+				{
+					Path:      "spec.template.spec.containers[*].image",
+					Operation: stork_api.RegexReplaceResourcePath,
+					Pattern:   `^old\.registry\.io/`,
+					Value:     "new.registry.io/",
+				},
+			},
+		},
+	}
+	diff, err := TransformResourcesWithDiff(object, []stork_api.TransformResourceInfo{patch}, "app", "test", nil)
+	require.NoError(t, err, "Error transforming resource")
+	require.Contains(t, diff, "--- test/app\n+++ test/app (transformed)\n")
+	require.Contains(t, diff, "\n-      - image: old.registry.io/team/app:v1\n")
+	require.Contains(t, diff, "\n+      - image: new.registry.io/team/app:v1\n")
+	require.Contains(t, diff, "\n+    "+TransformedResourceName+": \"true\"\n")
+	require.NotContains(t, diff, "replicas", "Unchanged lines outside the context shouldn't be in the diff")
+	require.Equal(t, []string{"new.registry.io/team/app:v1", "docker.io/proxy:v2"}, getTestImages(t, object))
+
+	// Resources that aren't selected aren't changed
+	object = newTestDeployment()
+	diff, err = TransformResourcesWithDiff(object, []stork_api.TransformResourceInfo{patch}, "other", "test", nil)
+	require.NoError(t, err, "Error transforming resource")
+	require.Empty(t, diff)
+}
+
+func TestTransformResourcesWithDiffSecret(t *testing.T) {
+	object := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata": map[string]interface{}{
+				"name":      "creds",
+				"namespace": "test",
+			},
+			"data": map[string]interface{}{
+				"password": "b2xkLXBhc3N3b3Jk",
+			},
+			"stringData": map[string]interface{}{
+				"token": "old-token",
+			},
+		},
+	}
+	patch := stork_api.TransformResourceInfo{
+		Name:      "creds",
+		Namespace: "test",
+		Specs: stork_api.TransformSpecs{
+			Paths: []stork_api.ResourcePaths{
+				{
+					Path:      "data.password",
+					Operation: stork_api.ModifyResourcePathValue,
+					Type:      stork_api.StringResourceType,
+					Value:     "bmV3LXBhc3N3b3Jk",
+				},
+				{
+					Path:      "stringData.user",
+					Operation: stork_api.AddResourcePath,
+					Type:      stork_api.StringResourceType,
+					Value:     "admin",
+				},
+			},
+		},
+	}
+	diff, err := TransformResourcesWithDiff(object, []stork_api.TransformResourceInfo{patch}, "creds", "test", nil)
+	require.NoError(t, err, "Error transforming resource")
+	for _, value := range []string{"b2xkLXBhc3N3b3Jk", "bmV3LXBhc3N3b3Jk", "old-token", "admin"} {
+		require.NotContains(t, diff, value, "Values of secrets shouldn't be in the diff")
+	}
+	require.Contains(t, diff, "\n+  user: "+TransformDiffRedacted+"\n")
+	require.Equal(t, "bmV3LXBhc3N3b3Jk", object.Object["data"].(map[string]interface{})["password"],
+		"Secret should still be transformed")
+}
+
+func TestLimitTransformDiffs(t *testing.T) {
+	diff := strings.Repeat("+", maxTransformDiffBytes)
+	resources := make([]*stork_api.TransformResourceInfo, 0)
+	for i := 0; i < maxTransformDiffTotalBytes/maxTransformDiffBytes+2; i++ {
+		resources = append(resources, &stork_api.TransformResourceInfo{Diff: diff})
+	}
+	resources = append(resources, &stork_api.TransformResourceInfo{})
+	require.Equal(t, 2, LimitTransformDiffs(resources))
+	for i, resource := range resources[:len(resources)-3] {
+		require.Equal(t, diff, resource.Diff, "Diff %v shouldn't be omitted", i)
+	}
+	require.Equal(t, TransformDiffOmitted, resources[len(resources)-3].Diff)
+	require.Equal(t, TransformDiffOmitted, resources[len(resources)-2].Diff)
+	require.Empty(t, resources[len(resources)-1].Diff, "Resources without a diff shouldn't be changed")
+
+	// Omitted diffs aren't counted again
+	require.Equal(t, 0, LimitTransformDiffs(resources))
+}
@@ -0,0 +1,252 @@
+package rule
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	defaultHTTPActionTimeout = 30 * time.Second
+	// maximum number of bytes of the response body that is read
+	maxHTTPActionResponseBytes = maxRuleOutputBytes
+)
+
+var httpActionBackoff = wait.Backoff{
+	Duration: execPodCmdRetryInterval,
+	Factor:   execPodCmdRetryFactor,
+	Steps:    execPodStepLow,
+}
+
+var validHTTPActionMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+// validateHTTPAction validates the request of an http action
+func validateHTTPAction(action stork_api.RuleAction) error {
+	httpAction := action.HTTP
+	if httpAction == nil {
+		return fmt.Errorf("http request is required for http actions")
+	}
+	if action.Background {
+		return fmt.Errorf("background is not supported for http actions")
+	}
+	if httpAction.Port <= 0 || httpAction.Port > 65535 {
+		return fmt.Errorf("invalid port: %v", httpAction.Port)
+	}
+	if httpAction.Scheme != "" && httpAction.Scheme != "http" && httpAction.Scheme != "https" {
+		return fmt.Errorf("invalid scheme: %v", httpAction.Scheme)
+	}
+	if httpAction.Method != "" {
+		valid := false
+		for _, method := range validHTTPActionMethods {
+			if httpAction.Method == method {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid method: %v", httpAction.Method)
+		}
+	}
+	for _, status := range httpAction.ExpectedStatus {
+		if status < 100 || status > 599 {
+			return fmt.Errorf("invalid expected status: %v", status)
+		}
+	}
+	return nil
+}
+
+// executeHTTPAction sends the request of the http action to the service or to
+// each of the given pods
+func executeHTTPAction(pods []v1.Pod, namespace string, action stork_api.RuleAction, recorder *actionRecorder) error {
+	httpAction := action.HTTP
+	client, err := getHTTPActionClient(httpAction, namespace, action.Timeout)
+	if err != nil {
+		return err
+	}
+
+	backoff := httpActionBackoff
+	backoff.Steps = getActionSteps(action, execPodStepLow)
+	if httpAction.Service != "" {
+		host := fmt.Sprintf("%s.%s.svc", httpAction.Service, namespace)
+		start := time.Now()
+		status, body, err := sendHTTPActionRequest(client, getHTTPActionURL(httpAction, host), httpAction, backoff)
+		recorder.recordHTTP("", start, status, body, err)
+		return err
+	}
+
+	if len(pods) == 0 {
+		return nil
+	}
+	podsForAction := pods
+	if action.RunInSinglePod {
+		podsForAction = []v1.Pod{pods[0]}
+	}
+	for _, pod := range podsForAction {
+		start := time.Now()
+		if pod.Status.PodIP == "" {
+			err := fmt.Errorf("pod: [%s] %s doesn't have an IP", pod.GetNamespace(), pod.GetName())
+			recorder.recordHTTP(pod.GetName(), start, 0, "", err)
+			return err
+		}
+		status, body, err := sendHTTPActionRequest(client, getHTTPActionURL(httpAction, pod.Status.PodIP), httpAction, backoff)
+		recorder.recordHTTP(pod.GetName(), start, status, body, err)
+		if err != nil {
+			return fmt.Errorf("http action failed on pod: [%s] %s due to: %v", pod.GetNamespace(), pod.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// usesTLS returns true if any of the TLS options are set for the http action
+func usesTLS(httpAction *stork_api.RuleHTTPAction) bool {
+	return httpAction.TLSSecret != "" || httpAction.ServerName != "" || httpAction.InsecureSkipVerify
+}
+
+// getHTTPActionClient returns the client for an http action, using the
+// certificates from the TLS secret if one is configured
+func getHTTPActionClient(httpAction *stork_api.RuleHTTPAction, namespace string, timeout int64) (*http.Client, error) {
+	client := &http.Client{
+		Timeout: defaultHTTPActionTimeout,
+	}
+	if timeout > 0 {
+		client.Timeout = time.Duration(timeout) * time.Second
+	}
+	if !usesTLS(httpAction) {
+		return client, nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         httpAction.ServerName,
+		InsecureSkipVerify: httpAction.InsecureSkipVerify,
+	}
+	client.Transport = &http.Transport{
+		TLSClientConfig: tlsConfig,
+	}
+	if httpAction.TLSSecret == "" {
+		return client, nil
+	}
+
+	secret, err := core.Instance().GetSecret(httpAction.TLSSecret, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get TLS secret: [%s] %s due to: %v", namespace, httpAction.TLSSecret, err)
+	}
+	if caCert, ok := secret.Data[v1.ServiceAccountRootCAKey]; ok {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("failed to parse %v from TLS secret: [%s] %s", v1.ServiceAccountRootCAKey, namespace, httpAction.TLSSecret)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	cert, certOK := secret.Data[v1.TLSCertKey]
+	key, keyOK := secret.Data[v1.TLSPrivateKeyKey]
+	if certOK && keyOK {
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse client certificate from TLS secret: [%s] %s due to: %v", namespace, httpAction.TLSSecret, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{pair}
+	}
+	return client, nil
+}
+
+func getHTTPActionURL(httpAction *stork_api.RuleHTTPAction, host string) string {
+	scheme := httpAction.Scheme
+	if scheme == "" {
+		scheme = "http"
+		if usesTLS(httpAction) {
+			scheme = "https"
+		}
+	}
+	path := httpAction.Path
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return scheme + "://" + net.JoinHostPort(host, strconv.Itoa(int(httpAction.Port))) + path
+}
+
+// sendHTTPActionRequest sends the request to the url, retrying until it
+// returns one of the expected status codes. Returns the status code and body
+// of the last response.
+func sendHTTPActionRequest(client *http.Client, url string, httpAction *stork_api.RuleHTTPAction, backoff wait.Backoff) (int, string, error) {
+	method := httpAction.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	var status int
+	var body string
+	var requestErr error
+	err := wait.ExponentialBackoff(backoff, func() (bool, error) {
+		status, body, requestErr = doHTTPActionRequest(client, method, url, httpAction)
+		if requestErr != nil {
+			logrus.Warnf("Failed to send request: %s %s due to: %v", method, url, requestErr)
+			return false, nil
+		}
+		logrus.Infof("Request: %s %s succeeded", method, url)
+		return true, nil
+	})
+	if err != nil && requestErr != nil {
+		return status, body, fmt.Errorf("request: %s %s failed due to: %v", method, url, requestErr)
+	}
+	return status, body, err
+}
+
+func doHTTPActionRequest(client *http.Client, method string, url string, httpAction *stork_api.RuleHTTPAction) (int, string, error) {
+	request, err := http.NewRequest(method, url, strings.NewReader(httpAction.Body))
+	if err != nil {
+		return 0, "", err
+	}
+	for key, value := range httpAction.Headers {
+		request.Header.Set(key, value)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, "", err
+	}
+	defer func() {
+		if err := response.Body.Close(); err != nil {
+			logrus.Warnf("Error closing response body: %v", err)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(io.LimitReader(response.Body, maxHTTPActionResponseBytes))
+	if err != nil {
+		return response.StatusCode, "", fmt.Errorf("failed to read response: %v", err)
+	}
+	if isExpectedHTTPStatus(response.StatusCode, httpAction.ExpectedStatus) {
+		return response.StatusCode, string(body), nil
+	}
+	return response.StatusCode, string(body), fmt.Errorf("unexpected status: %v: %s", response.Status, strings.TrimSpace(string(body)))
+}
+
+func isExpectedHTTPStatus(status int, expected []int) bool {
+	if len(expected) == 0 {
+		return status >= 200 && status < 300
+	}
+	for _, s := range expected {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
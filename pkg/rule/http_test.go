@@ -0,0 +1,191 @@
+//go:build unittest
+// +build unittest
+
+package rule
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateRuleActions(t *testing.T) {
+	retries := 3
+	negativeRetries := -1
+	valid := []stork_api.RuleAction{
+		{
+			Type:  stork_api.RuleActionCommand,
+			Value: "sync",
+		},
+		{
+			Type:      stork_api.RuleActionCommand,
+			Value:     "sync",
+			Timeout:   60,
+			Retries:   &retries,
+			OnFailure: stork_api.RuleActionFailurePolicyContinue,
+		},
+		{
+			Type: stork_api.RuleActionHTTP,
+			HTTP: &stork_api.RuleHTTPAction{
+				Port:           8080,
+				Method:         http.MethodPost,
+				Path:           "/quiesce",
+				ExpectedStatus: []int{200, 204},
+			},
+			OnFailure: stork_api.RuleActionFailurePolicyFail,
+		},
+	}
+	for _, action := range valid {
+		rule := &stork_api.Rule{
+			ObjectMeta: meta.ObjectMeta{Name: "validrule"},
+			Rules:      []stork_api.RuleItem{{Actions: []stork_api.RuleAction{action}}},
+		}
+		require.NoError(t, ValidateRule(rule, PreExecRule), "Action %v should be valid", action)
+	}
+
+	invalid := []stork_api.RuleAction{
+		{Type: "script"},
+		{Type: stork_api.RuleActionCommand, Timeout: -1},
+		{Type: stork_api.RuleActionCommand, Retries: &negativeRetries},
+		{Type: stork_api.RuleActionCommand, OnFailure: "ignore"},
+		{Type: stork_api.RuleActionHTTP},
+		{Type: stork_api.RuleActionHTTP, HTTP: &stork_api.RuleHTTPAction{}},
+		{Type: stork_api.RuleActionHTTP, HTTP: &stork_api.RuleHTTPAction{Port: 80, Method: "FETCH"}},
+		{Type: stork_api.RuleActionHTTP, HTTP: &stork_api.RuleHTTPAction{Port: 80, Scheme: "ftp"}},
+		{Type: stork_api.RuleActionHTTP, HTTP: &stork_api.RuleHTTPAction{Port: 80, ExpectedStatus: []int{1000}}},
+		{Type: stork_api.RuleActionHTTP, HTTP: &stork_api.RuleHTTPAction{Port: 80}, Background: true},
+	}
+	for _, action := range invalid {
+		rule := &stork_api.Rule{
+			ObjectMeta: meta.ObjectMeta{Name: "invalidrule"},
+			Rules:      []stork_api.RuleItem{{Actions: []stork_api.RuleAction{action}}},
+		}
+		require.Error(t, ValidateRule(rule, PreExecRule), "Action %v should be invalid", action)
+	}
+}
+
+func TestExecuteHTTPAction(t *testing.T) {
+	httpActionBackoff.Duration = 10 * time.Millisecond
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err, "Error reading request body")
+		if r.Method != http.MethodPost || r.URL.Path != "/quiesce" || string(body) != "{}" ||
+			r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// Fail the first request to test the retries
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	host, portString, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err, "Error parsing server address")
+	port, err := strconv.Atoi(portString)
+	require.NoError(t, err, "Error parsing server port")
+	pods := []v1.Pod{
+		{
+			ObjectMeta: meta.ObjectMeta{Name: "pod1", Namespace: "default"},
+			Status:     v1.PodStatus{PodIP: host},
+		},
+	}
+
+	retries := 1
+	action := stork_api.RuleAction{
+		Type: stork_api.RuleActionHTTP,
+		HTTP: &stork_api.RuleHTTPAction{
+			Port:    int32(port),
+			Method:  http.MethodPost,
+			Path:    "quiesce",
+			Headers: map[string]string{"Content-Type": "application/json"},
+			Body:    "{}",
+		},
+		Retries: &retries,
+	}
+	err = executeHTTPAction(pods, "default", action, nil)
+	require.NoError(t, err, "HTTP action should succeed after a retry")
+	require.Equal(t, 2, requests, "Request should have been retried once")
+
+	// Unexpected status without retries
+	requests = 0
+	retries = 0
+	action.HTTP.ExpectedStatus = []int{http.StatusOK}
+	err = executeHTTPAction(pods, "default", action, nil)
+	require.Error(t, err, "HTTP action should fail for unexpected status")
+	require.Equal(t, 1, requests, "Request shouldn't have been retried")
+
+	requests = 1
+	action.HTTP.ExpectedStatus = []int{http.StatusAccepted}
+	err = executeHTTPAction(pods, "default", action, nil)
+	require.NoError(t, err, "HTTP action should succeed for expected status")
+
+	// Pods without an IP
+	pods[0].Status.PodIP = ""
+	err = executeHTTPAction(pods, "default", action, nil)
+	require.Error(t, err, "HTTP action should fail for pod without IP")
+}
+
+func TestHTTPActionTLSOptions(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	host, portString, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portString)
+	require.NoError(t, err)
+	pods := []v1.Pod{{
+		ObjectMeta: meta.ObjectMeta{Name: "pod1", Namespace: "default"},
+		Status:     v1.PodStatus{PodIP: host},
+	}}
+
+	action := stork_api.RuleAction{
+		Type:    stork_api.RuleActionHTTP,
+		Retries: new(int),
+		HTTP: &stork_api.RuleHTTPAction{
+			Port:       int32(port),
+			ServerName: "example.com",
+		},
+	}
+	require.Equal(t, "https://"+net.JoinHostPort(host, portString)+"/", getHTTPActionURL(action.HTTP, host),
+		"Scheme should default to https with TLS options")
+	err = executeHTTPAction(pods, "default", action, nil)
+	require.Error(t, err, "HTTP action should fail to verify the self signed certificate")
+
+	action.HTTP.InsecureSkipVerify = true
+	err = executeHTTPAction(pods, "default", action, nil)
+	require.NoError(t, err, "HTTP action should succeed without verifying the certificate")
+
+	client, err := getHTTPActionClient(action.HTTP, "default", 0)
+	require.NoError(t, err)
+	transport, ok := client.Transport.(*http.Transport)
+	require.True(t, ok, "Client should have a transport for TLS options")
+	require.Equal(t, "example.com", transport.TLSClientConfig.ServerName)
+	require.Equal(t, defaultHTTPActionTimeout, client.Timeout)
+}
+
+func TestCommandWithTimeout(t *testing.T) {
+	require.Equal(t, []string{"sh", "-c", "echo hi"}, commandWithTimeout("echo hi", 0))
+	require.Equal(t, []string{"timeout", "90", "sh", "-c", "echo hi"}, commandWithTimeout("echo hi", 90*time.Second))
+	require.Equal(t, []string{"timeout", "2", "sh", "-c", "echo hi"}, commandWithTimeout("echo hi", 1500*time.Millisecond))
+
+	require.True(t, isCommandTimeout(fmt.Errorf("could not execute: command terminated with exit code 124:  ")))
+	require.False(t, isCommandTimeout(fmt.Errorf("could not execute: command terminated with exit code 1:  ")))
+	require.False(t, isCommandTimeout(nil))
+}
@@ -0,0 +1,198 @@
+package rule
+
+import (
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// maximum number of bytes of the output of an action kept in its result
+	maxRuleOutputBytes = 1024
+	truncatedPrefix    = "..."
+	// maximum number of rule results kept in the status of an owner
+	maxRuleResults = 10
+	// maximum number of action results kept in each rule result
+	maxRuleActionResults = 20
+)
+
+var exitCodeRegex = regexp.MustCompile(`exit code (\d+)`)
+
+var (
+	// RuleExecutionCounter for the number of times rules were run
+	RuleExecutionCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "stork_rule_executions_total",
+		Help: "Number of times rules were run",
+	}, []string{"rule", "namespace", "type", "status"})
+	// RuleDurationGauge for the time taken by the last run of a rule
+	RuleDurationGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "stork_rule_duration_seconds",
+		Help: "Duration of the last run of rules",
+	}, []string{"rule", "namespace", "type"})
+	// RuleActionFailureCounter for the number of times actions failed on pods
+	RuleActionFailureCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "stork_rule_action_failures_total",
+		Help: "Number of times actions of rules failed on pods",
+	}, []string{"rule", "namespace", "type", "action"})
+)
+
+// actionRecorder collects the results of running an action of a rule on pods.
+// It is safe to use from multiple goroutines and a nil recorder ignores the
+// results.
+type actionRecorder struct {
+	sync.Mutex
+	itemIndex   int
+	actionIndex int
+	actionType  stork_api.RuleActionType
+	container   string
+	results     []*stork_api.RuleActionResult
+}
+
+func newActionRecorder(itemIndex int, actionIndex int, action stork_api.RuleAction, container string) *actionRecorder {
+	return &actionRecorder{
+		itemIndex:   itemIndex,
+		actionIndex: actionIndex,
+		actionType:  action.Type,
+		container:   container,
+		results:     make([]*stork_api.RuleActionResult, 0),
+	}
+}
+
+// recordCommand records the output of a command run on a pod
+func (r *actionRecorder) recordCommand(pod string, start time.Time, output string, err error) {
+	if r == nil {
+		return
+	}
+	result := r.newResult(pod, start, err)
+	if err == nil {
+		exitCode := 0
+		result.ExitCode = &exitCode
+		result.Stdout = truncateOutput(output)
+	} else {
+		result.ExitCode = getExitCode(err)
+		result.Stderr = truncateOutput(output)
+	}
+	r.add(result)
+}
+
+// recordHTTP records the response of an http request sent to a pod or
+// service
+func (r *actionRecorder) recordHTTP(pod string, start time.Time, status int, body string, err error) {
+	if r == nil {
+		return
+	}
+	result := r.newResult(pod, start, err)
+	result.HTTPStatus = status
+	result.Stdout = truncateOutput(body)
+	r.add(result)
+}
+
+func (r *actionRecorder) newResult(pod string, start time.Time, err error) *stork_api.RuleActionResult {
+	result := &stork_api.RuleActionResult{
+		ItemIndex:   r.itemIndex,
+		ActionIndex: r.actionIndex,
+		Type:        r.actionType,
+		Pod:         pod,
+		Container:   r.container,
+		Status:      stork_api.RuleExecutionStatusSuccessful,
+		Duration:    metav1.Duration{Duration: time.Since(start)},
+	}
+	if err != nil {
+		result.Status = stork_api.RuleExecutionStatusFailed
+		result.Reason = truncateOutput(err.Error())
+	}
+	return result
+}
+
+func (r *actionRecorder) add(result *stork_api.RuleActionResult) {
+	r.Lock()
+	defer r.Unlock()
+	r.results = append(r.results, result)
+}
+
+// getResults returns the results recorded so far
+func (r *actionRecorder) getResults() []*stork_api.RuleActionResult {
+	r.Lock()
+	defer r.Unlock()
+	return append([]*stork_api.RuleActionResult{}, r.results...)
+}
+
+// AppendResults appends the results of rules to the results in the status of
+// an owner, keeping only the latest ones. The actions of the new results are
+// trimmed to limit the size of the status.
+func AppendResults(
+	results []*stork_api.RuleExecutionResult,
+	newResults ...*stork_api.RuleExecutionResult,
+) []*stork_api.RuleExecutionResult {
+	for _, result := range newResults {
+		trimActions(result)
+	}
+	results = append(results, newResults...)
+	if len(results) > maxRuleResults {
+		results = results[len(results)-maxRuleResults:]
+	}
+	return results
+}
+
+// trimActions counts the successful and failed actions of a result. If there
+// are more than maxRuleActionResults actions only the failed ones are kept, up
+// to maxRuleActionResults of them.
+func trimActions(result *stork_api.RuleExecutionResult) {
+	result.SuccessfulActions, result.FailedActions = 0, 0
+	failed := make([]*stork_api.RuleActionResult, 0)
+	for _, action := range result.Actions {
+		if action.Status == stork_api.RuleExecutionStatusFailed {
+			result.FailedActions++
+			failed = append(failed, action)
+		} else {
+			result.SuccessfulActions++
+		}
+	}
+	if len(result.Actions) <= maxRuleActionResults {
+		return
+	}
+	if len(failed) > maxRuleActionResults {
+		failed = failed[:maxRuleActionResults]
+	}
+	result.Actions = failed
+}
+
+// truncateOutput keeps the end of the output since that is usually where the
+// errors are
+func truncateOutput(output string) string {
+	if len(output) <= maxRuleOutputBytes {
+		return output
+	}
+	return truncatedPrefix + output[len(output)-maxRuleOutputBytes+len(truncatedPrefix):]
+}
+
+// getExitCode parses the exit code of a command from the error returned when
+// running it in a pod
+func getExitCode(err error) *int {
+	matches := exitCodeRegex.FindStringSubmatch(err.Error())
+	if len(matches) != 2 {
+		return nil
+	}
+	exitCode, convErr := strconv.Atoi(matches[1])
+	if convErr != nil {
+		return nil
+	}
+	return &exitCode
+}
+
+// recordRuleMetrics updates the metrics for a rule that was run
+func recordRuleMetrics(rule *stork_api.Rule, result *stork_api.RuleExecutionResult) {
+	RuleExecutionCounter.WithLabelValues(rule.Name, rule.Namespace, result.Type, string(result.Status)).Inc()
+	RuleDurationGauge.WithLabelValues(rule.Name, rule.Namespace, result.Type).Set(
+		result.FinishTimestamp.Sub(result.StartTimestamp.Time).Seconds())
+	for _, action := range result.Actions {
+		if action.Status == stork_api.RuleExecutionStatusFailed {
+			RuleActionFailureCounter.WithLabelValues(rule.Name, rule.Namespace, result.Type, string(action.Type)).Inc()
+		}
+	}
+}
@@ -0,0 +1,114 @@
+//go:build unittest
+// +build unittest
+
+package rule
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionRecorder(t *testing.T) {
+	action := stork_api.RuleAction{
+		Type:  stork_api.RuleActionCommand,
+		Value: "sync",
+	}
+	recorder := newActionRecorder(1, 2, action, "app")
+	start := time.Now()
+	recorder.recordCommand("pod1", start, "synced", nil)
+	recorder.recordCommand("pod2", start, "permission denied",
+		fmt.Errorf("command terminated with exit code 126"))
+
+	results := recorder.getResults()
+	require.Len(t, results, 2)
+	require.Equal(t, 1, results[0].ItemIndex)
+	require.Equal(t, 2, results[0].ActionIndex)
+	require.Equal(t, "pod1", results[0].Pod)
+	require.Equal(t, "app", results[0].Container)
+	require.Equal(t, stork_api.RuleExecutionStatusSuccessful, results[0].Status)
+	require.Equal(t, 0, *results[0].ExitCode)
+	require.Equal(t, "synced", results[0].Stdout)
+
+	require.Equal(t, stork_api.RuleExecutionStatusFailed, results[1].Status)
+	require.Equal(t, 126, *results[1].ExitCode)
+	require.Equal(t, "permission denied", results[1].Stderr)
+	require.Empty(t, results[1].Stdout)
+
+	// Exit code isn't known for other errors
+	recorder.recordCommand("pod3", start, "", fmt.Errorf("timed out after 1s"))
+	require.Nil(t, recorder.getResults()[2].ExitCode)
+
+	// A nil recorder ignores the results
+	var nilRecorder *actionRecorder
+	nilRecorder.recordCommand("pod1", start, "synced", nil)
+	nilRecorder.recordHTTP("pod1", start, 200, "", nil)
+}
+
+func TestTruncateOutput(t *testing.T) {
+	require.Equal(t, "output", truncateOutput("output"))
+
+	output := strings.Repeat("a", maxRuleOutputBytes) + "error"
+	truncated := truncateOutput(output)
+	require.Len(t, truncated, maxRuleOutputBytes)
+	require.True(t, strings.HasPrefix(truncated, truncatedPrefix))
+	require.True(t, strings.HasSuffix(truncated, "error"))
+}
+
+func TestAppendResults(t *testing.T) {
+	results := make([]*stork_api.RuleExecutionResult, 0)
+	for i := 0; i < maxRuleResults+2; i++ {
+		results = AppendResults(results, &stork_api.RuleExecutionResult{Rule: fmt.Sprintf("rule%v", i)})
+	}
+	require.Len(t, results, maxRuleResults)
+	require.Equal(t, "rule2", results[0].Rule)
+	require.Equal(t, fmt.Sprintf("rule%v", maxRuleResults+1), results[maxRuleResults-1].Rule)
+}
+
+func TestAppendResultsTrimsActions(t *testing.T) {
+	result := &stork_api.RuleExecutionResult{Rule: "rule"}
+	for i := 0; i < maxRuleActionResults*2; i++ {
+		status := stork_api.RuleExecutionStatusSuccessful
+		if i%10 == 0 {
+			status = stork_api.RuleExecutionStatusFailed
+		}
+		result.Actions = append(result.Actions, &stork_api.RuleActionResult{
+			Pod:    fmt.Sprintf("pod%v", i),
+			Status: status,
+		})
+	}
+	results := AppendResults(nil, result)
+	require.Len(t, results, 1)
+	require.Len(t, results[0].Actions, 4, "Only the failed actions should be kept")
+	require.Equal(t, 4, results[0].FailedActions)
+	require.Equal(t, maxRuleActionResults*2-4, results[0].SuccessfulActions)
+	for _, action := range results[0].Actions {
+		require.Equal(t, stork_api.RuleExecutionStatusFailed, action.Status)
+	}
+
+	// Failed actions are capped too
+	result = &stork_api.RuleExecutionResult{Rule: "rule"}
+	for i := 0; i < maxRuleActionResults+1; i++ {
+		result.Actions = append(result.Actions, &stork_api.RuleActionResult{
+			Pod:    fmt.Sprintf("pod%v", i),
+			Status: stork_api.RuleExecutionStatusFailed,
+		})
+	}
+	results = AppendResults(nil, result)
+	require.Len(t, results[0].Actions, maxRuleActionResults)
+	require.Equal(t, maxRuleActionResults+1, results[0].FailedActions)
+
+	// All the actions are kept if there aren't too many
+	result = &stork_api.RuleExecutionResult{Rule: "rule", Actions: []*stork_api.RuleActionResult{
+		{Pod: "pod1", Status: stork_api.RuleExecutionStatusSuccessful},
+		{Pod: "pod2", Status: stork_api.RuleExecutionStatusFailed},
+	}}
+	results = AppendResults(nil, result)
+	require.Len(t, results[0].Actions, 2)
+	require.Equal(t, 1, results[0].SuccessfulActions)
+	require.Equal(t, 1, results[0].FailedActions)
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/portworx/sched-ops/k8s/core"
 	"github.com/portworx/sched-ops/k8s/dynamic"
 	errors "github.com/portworx/sched-ops/k8s/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 	"github.com/skyrings/skyring-common/tools/uuid"
 	v1 "k8s.io/api/core/v1"
@@ -50,6 +51,10 @@ const (
 
 	// constants
 	perPodCommandExecTimeout = 900 // 15 minutes
+	// timeout for each attempt to create the kill file of a background command
+	killFileTimeout = 30 * time.Second
+	// exit code of the timeout utility when it kills a command that timed out
+	commandTimeoutExitCode = 124
 
 	execPodCmdRetryInterval = 5 * time.Second
 	execPodCmdRetryFactor   = 1
@@ -86,6 +91,10 @@ type commandTask struct {
 	TaskID    string `json:"taskID"`
 	Pods      []Pod  `json:"pods"`
 	Container string `json:"container"`
+	// Retries and OnFailure of the background action, used when terminating
+	// the commands
+	Retries   *int                              `json:"retries,omitempty"`
+	OnFailure stork_api.RuleActionFailurePolicy `json:"onFailure,omitempty"`
 }
 
 var execCmdBackoff = wait.Backoff{
@@ -102,6 +111,10 @@ var ownerAPICallBackoff = wait.Backoff{
 
 // Init initializes the rule executor
 func Init() error {
+	prometheus.MustRegister(RuleExecutionCounter)
+	prometheus.MustRegister(RuleDurationGauge)
+	prometheus.MustRegister(RuleActionFailureCounter)
+
 	storkRuleResource := apiextensions.CustomResource{
 		Name:    "rule",
 		Plural:  "rules",
@@ -133,26 +146,61 @@ func Init() error {
 func ValidateRule(rule *stork_api.Rule, ruleType Type) error {
 	for _, item := range rule.Rules {
 		for _, action := range item.Actions {
-			if action.Type == stork_api.RuleActionCommand {
+			switch action.Type {
+			case stork_api.RuleActionCommand:
 				if action.Background && ruleType == PostExecRule {
 					return fmt.Errorf("background actions are not supported for post exec rules")
 				}
-			} else {
+			case stork_api.RuleActionHTTP:
+				if err := validateHTTPAction(action); err != nil {
+					return fmt.Errorf("invalid http action in rule: [%s] %s: %v",
+						rule.GetNamespace(), rule.GetName(), err)
+				}
+			default:
 				return fmt.Errorf("unsupported action type: %s in rule: [%s] %s",
 					action.Type, rule.GetNamespace(), rule.GetName())
 			}
+
+			if action.Timeout < 0 {
+				return fmt.Errorf("invalid timeout: %v for action in rule: [%s] %s",
+					action.Timeout, rule.GetNamespace(), rule.GetName())
+			}
+			if action.Retries != nil && *action.Retries < 0 {
+				return fmt.Errorf("invalid retries: %v for action in rule: [%s] %s",
+					*action.Retries, rule.GetNamespace(), rule.GetName())
+			}
+			if action.OnFailure != "" &&
+				action.OnFailure != stork_api.RuleActionFailurePolicyFail &&
+				action.OnFailure != stork_api.RuleActionFailurePolicyContinue {
+				return fmt.Errorf("unsupported onFailure: %s for action in rule: [%s] %s",
+					action.OnFailure, rule.GetNamespace(), rule.GetName())
+			}
 		}
 	}
 
 	return nil
 }
 
-// terminateCommandInPods terminates a previously running background command on given pods for given task ID
-func terminateCommandInPods(owner runtime.Object, pods []v1.Pod, container, taskID string) error {
-	killFile := fmt.Sprintf(cmdexecutor.KillFileFormat, taskID)
-	failedPods, err := runCommandOnPods(pods, container, fmt.Sprintf("touch %s", killFile), execPodStepsHigh, false)
+// getActionSteps returns the number of times an action should be attempted
+// on each pod, using the given default if retries aren't set for the action
+func getActionSteps(action stork_api.RuleAction, defaultSteps int) int {
+	if action.Retries == nil {
+		return defaultSteps
+	}
+	return *action.Retries + 1
+}
+
+// terminateCommandInPods terminates a previously running background command on given pods for given task
+func terminateCommandInPods(owner runtime.Object, pods []v1.Pod, task *commandTask) error {
+	killFile := fmt.Sprintf(cmdexecutor.KillFileFormat, task.TaskID)
+	steps := execPodStepsHigh
+	if task.Retries != nil {
+		steps = *task.Retries + 1
+	}
+	failedPods, err := runCommandOnPods(pods, task.Container, fmt.Sprintf("touch %s", killFile), steps,
+		killFileTimeout, false, nil)
 
-	updateErr := updateRunningCommandPodListInOwner(owner, failedPods, container, taskID)
+	updateErr := updateRunningCommandPodListInOwner(owner, failedPods, task)
 	if updateErr != nil {
 		log.RuleLog(nil, owner).Warnf("Failed to update list of pods with running command in owner due to: %v", updateErr)
 	}
@@ -217,8 +265,12 @@ func PerformRuleRecovery(
 			backgroundPodList = append(backgroundPodList, *p)
 		}
 
-		err = terminateCommandInPods(owner, backgroundPodList, taskTracker.Container, taskTracker.TaskID)
+		err = terminateCommandInPods(owner, backgroundPodList, taskTracker)
 		if err != nil {
+			if taskTracker.OnFailure == stork_api.RuleActionFailurePolicyContinue {
+				log.RuleLog(nil, owner).Warnf("Failed to terminate running commands in pods, continuing: %v", err)
+				return nil
+			}
 			return fmt.Errorf("failed to terminate running commands in pods due to: %v", err)
 		}
 	}
@@ -233,6 +285,44 @@ func ExecuteRule(
 	rType Type,
 	owner runtime.Object,
 	podNamespace string,
+) (chan bool, error) {
+	ch, _, err := ExecuteRuleWithResult(rule, rType, owner, podNamespace)
+	return ch, err
+}
+
+// ExecuteRuleWithResult executes rules for the given owner like ExecuteRule and also returns the results of the
+// actions on each pod so that they can be recorded in the owner. The result is returned even if the rule fails.
+func ExecuteRuleWithResult(
+	rule *stork_api.Rule,
+	rType Type,
+	owner runtime.Object,
+	podNamespace string,
+) (chan bool, *stork_api.RuleExecutionResult, error) {
+	result := &stork_api.RuleExecutionResult{
+		Rule:           rule.GetName(),
+		Type:           string(rType),
+		Namespace:      podNamespace,
+		StartTimestamp: metav1.Now(),
+		Actions:        make([]*stork_api.RuleActionResult, 0),
+	}
+	ch, err := executeRule(rule, rType, owner, podNamespace, result)
+	result.FinishTimestamp = metav1.Now()
+	if err != nil {
+		result.Status = stork_api.RuleExecutionStatusFailed
+		result.Reason = truncateOutput(err.Error())
+	} else {
+		result.Status = stork_api.RuleExecutionStatusSuccessful
+	}
+	recordRuleMetrics(rule, result)
+	return ch, result, err
+}
+
+func executeRule(
+	rule *stork_api.Rule,
+	rType Type,
+	owner runtime.Object,
+	podNamespace string,
+	result *stork_api.RuleExecutionResult,
 ) (chan bool, error) {
 	// Validate the rule. Don't depend on callers to invoke this
 	if err := ValidateRule(rule, rType); err != nil {
@@ -260,14 +350,12 @@ func ExecuteRule(
 		// start a watcher thread that will accumulate pods which have background commands to
 		// terminate and also watch a signal channel that indicates when to terminate them
 		backgroundCommandTermChan := make(chan bool, 1)
-		backgroundPodListChan := make(chan v1.Pod)
-		var container string
-		go cmdTerminationWatcher(backgroundPodListChan, &container, backgroundCommandTermChan, owner, taskID.String())
+		backgroundPodListChan := make(chan backgroundCommandPod)
+		go cmdTerminationWatcher(backgroundPodListChan, backgroundCommandTermChan, owner)
 
 		// backgroundActionPresent is used to track if there is atleast one background action
 		backgroundActionPresent := false
-		for _, item := range rule.Rules {
-			container = item.Container
+		for itemIndex, item := range rule.Rules {
 			filteredPods := make([]v1.Pod, 0)
 			// filter pods and only uses the ones that match this selector
 			for _, pod := range pods {
@@ -281,24 +369,42 @@ func ExecuteRule(
 				continue
 			}
 
-			for _, action := range item.Actions {
+			for actionIndex, action := range item.Actions {
+				// Each action gets its own task so that the watcher never
+				// shares one that is being updated
+				task := &commandTask{
+					TaskID:    taskID.String(),
+					Container: item.Container,
+				}
 				if action.Background {
 					backgroundActionPresent = true
+					task.Retries = action.Retries
+					task.OnFailure = action.OnFailure
 				}
 
-				if action.Type == stork_api.RuleActionCommand {
-					err := executeCommandAction(filteredPods, item.Container, rule, owner, action, backgroundPodListChan, rType, taskID)
-					if err != nil {
-						// if any action fails, terminate all background jobs and don't depend on caller
-						// to clean them up
-						if backgroundActionPresent {
-							backgroundCommandTermChan <- true
-							return nil, err
-						}
-
-						backgroundCommandTermChan <- false
+				var err error
+				recorder := newActionRecorder(itemIndex, actionIndex, action, item.Container)
+				switch action.Type {
+				case stork_api.RuleActionCommand:
+					err = executeCommandAction(filteredPods, item.Container, rule, owner, action, backgroundPodListChan, rType, task, recorder)
+				case stork_api.RuleActionHTTP:
+					err = executeHTTPAction(filteredPods, podNamespace, action, recorder)
+				}
+				result.Actions = append(result.Actions, recorder.getResults()...)
+				if err != nil {
+					if action.OnFailure == stork_api.RuleActionFailurePolicyContinue {
+						log.RuleLog(rule, owner).Warnf("Continuing after %v action failed: %v", action.Type, err)
+						continue
+					}
+					// if any action fails, terminate all background jobs and don't depend on caller
+					// to clean them up
+					if backgroundActionPresent {
+						backgroundCommandTermChan <- true
 						return nil, err
 					}
+
+					backgroundCommandTermChan <- false
+					return nil, err
 				}
 			}
 		}
@@ -321,8 +427,8 @@ func executeCommandAction(
 	rule *stork_api.Rule,
 	owner runtime.Object,
 	action stork_api.RuleAction,
-	backgroundPodNotifyChan chan v1.Pod,
-	rType Type, taskID *uuid.UUID) error {
+	backgroundPodNotifyChan chan backgroundCommandPod,
+	rType Type, task *commandTask, recorder *actionRecorder) error {
 	if len(pods) == 0 {
 		return nil
 	}
@@ -376,7 +482,7 @@ func executeCommandAction(
 
 	if action.Background {
 		for _, podToTerminate := range podsForAction {
-			backgroundPodNotifyChan <- podToTerminate
+			backgroundPodNotifyChan <- backgroundCommandPod{pod: podToTerminate, task: *task}
 		}
 
 		// regardless of the outcome of running the background command, we first update the
@@ -413,17 +519,26 @@ func executeCommandAction(
 			podsForTrackerList = append(podsForTrackerList, pod)
 		}
 
-		updateErr := updateRunningCommandPodListInOwner(owner, podsForTrackerList, container, taskID.String())
+		updateErr := updateRunningCommandPodListInOwner(owner, podsForTrackerList, task)
 		if updateErr != nil {
 			log.RuleLog(rule, owner).Warnf("Failed to update list of pods with running command in owner due to: %v", updateErr)
 		}
 
-		err = runBackgroundCommandOnPods(podsForAction, container, action.Value, taskID.String(), cmdExecutorImage, cmdExecutorImageSecret)
+		timeout := int64(perPodCommandExecTimeout)
+		if action.Timeout > 0 {
+			timeout = action.Timeout
+		}
+		start := time.Now()
+		err = runBackgroundCommandOnPods(podsForAction, container, action.Value, task.TaskID, timeout, cmdExecutorImage, cmdExecutorImageSecret)
+		for _, pod := range podsForAction {
+			recorder.recordCommand(pod.GetName(), start, "", err)
+		}
 		if err != nil {
 			return err
 		}
 	} else {
-		_, err := runCommandOnPods(podsForAction, container, action.Value, execPodStepLow, true)
+		_, err := runCommandOnPods(podsForAction, container, action.Value, getActionSteps(action, execPodStepLow),
+			time.Duration(action.Timeout)*time.Second, true, recorder)
 		if err != nil {
 			return err
 		}
@@ -447,8 +562,7 @@ func podsToString(pods []v1.Pod) string {
 func updateRunningCommandPodListInOwner(
 	owner runtime.Object,
 	pods []v1.Pod,
-	container string,
-	taskID string,
+	task *commandTask,
 ) error {
 	podsWithNs := make([]Pod, 0)
 	for _, p := range pods {
@@ -458,9 +572,11 @@ func updateRunningCommandPodListInOwner(
 	}
 
 	tracker := &commandTask{
-		TaskID:    taskID,
+		TaskID:    task.TaskID,
 		Pods:      podsWithNs,
-		Container: container,
+		Container: task.Container,
+		Retries:   task.Retries,
+		OnFailure: task.OnFailure,
 	}
 
 	trackerBytes, err := json.Marshal(tracker)
@@ -502,9 +618,18 @@ func updateRunningCommandPodListInOwner(
 	return err
 }
 
-// runCommandOnPods runs cmd on given pods. If failFast is true, it will return on the first failure. It will
-// return a list of pods that failed.
-func runCommandOnPods(pods []v1.Pod, container string, cmd string, numRetries int, failFast bool) ([]v1.Pod, error) {
+// runCommandOnPods runs cmd on given pods. If a timeout is set the command is killed in the pod if it doesn't
+// finish within it and isn't retried on that pod. If failFast is true, it will return on the first failure. It will return a list of pods that failed. The
+// result of the last attempt on each pod is recorded in the recorder if one is given.
+func runCommandOnPods(
+	pods []v1.Pod,
+	container string,
+	cmd string,
+	numRetries int,
+	timeout time.Duration,
+	failFast bool,
+	recorder *actionRecorder,
+) ([]v1.Pod, error) {
 	var wg sync.WaitGroup
 	backOff := wait.Backoff{
 		Duration: execPodCmdRetryInterval,
@@ -518,12 +643,17 @@ func runCommandOnPods(pods []v1.Pod, container string, cmd string, numRetries in
 		wg.Add(1)
 		go func(pod v1.Pod, errRespChan chan podErrorResponse) {
 			defer wg.Done()
+			start := time.Now()
+			podFound := false
+			var output string
+			var cmdErr error
 			err := wait.ExponentialBackoff(backOff, func() (bool, error) {
 				ns, name := pod.GetNamespace(), pod.GetName()
 				_, err := core.Instance().GetPodByUID(pod.GetUID(), ns)
 				if err != nil {
 					if err == errors.ErrPodsNotFound {
 						logrus.Infof("Pod with uuid: %s in namespace: %s is no longer present", string(pod.GetUID()), ns)
+						podFound = false
 						return true, nil
 					}
 
@@ -554,15 +684,30 @@ func runCommandOnPods(pods []v1.Pod, container string, cmd string, numRetries in
 					return false, nil
 				}
 
-				_, err = core.Instance().RunCommandInPod([]string{"sh", "-c", cmd}, name, container, ns)
-				if err != nil {
-					logrus.Warnf("Failed to run command: %s on pod: [%s] %s due to: %v", cmd, ns, name, err)
+				podFound = true
+				output, cmdErr = core.Instance().RunCommandInPod(commandWithTimeout(cmd, timeout), name, container, ns)
+				if isCommandTimeout(cmdErr) {
+					// Don't retry a command that timed out since it could be
+					// stuck on something a retry would be stuck on too
+					cmdErr = fmt.Errorf("timed out after %v", timeout)
+					logrus.Warnf("Command: %s on pod: [%s] %s %v", cmd, ns, name, cmdErr)
+					return false, cmdErr
+				}
+				if cmdErr != nil {
+					logrus.Warnf("Failed to run command: %s on pod: [%s] %s due to: %v", cmd, ns, name, cmdErr)
 					return false, nil
 				}
 
 				logrus.Infof("Command: %s succeeded on pod: [%s] %s", cmd, ns, name)
 				return true, nil
 			})
+			if podFound {
+				if err != nil && cmdErr != nil {
+					recorder.recordCommand(pod.GetName(), start, output, cmdErr)
+				} else {
+					recorder.recordCommand(pod.GetName(), start, output, err)
+				}
+			}
 			if err != nil {
 				errChannel <- podErrorResponse{
 					Pod: pod,
@@ -604,6 +749,21 @@ func runCommandOnPods(pods []v1.Pod, container string, cmd string, numRetries in
 	return nil, nil
 }
 
+// commandWithTimeout returns the command to exec into a pod to run cmd. If a timeout is set the command is run
+// with the timeout utility so that it's killed in the pod once the timeout expires.
+func commandWithTimeout(cmd string, timeout time.Duration) []string {
+	if timeout <= 0 {
+		return []string{"sh", "-c", cmd}
+	}
+	seconds := int64(math.Ceil(timeout.Seconds()))
+	return []string{"timeout", strconv.FormatInt(seconds, 10), "sh", "-c", cmd}
+}
+
+// isCommandTimeout returns true if the error is from a command that was killed by the timeout utility
+func isCommandTimeout(err error) bool {
+	return err != nil && strings.Contains(err.Error(), fmt.Sprintf("exit code %d", commandTimeoutExitCode))
+}
+
 // ToImagePullSecret converts a secret name to the ImagePullSecret struct.
 func ToImagePullSecret(name string) []v1.LocalObjectReference {
 	if name == "" {
@@ -619,10 +779,10 @@ func ToImagePullSecret(name string) []v1.LocalObjectReference {
 
 // runBackgroundCommandOnPods will start the given "cmd" on all the given "pods". The taskID is given to
 // the executor pod so it can have unique status files in the target pods where it runs the actual commands
-func runBackgroundCommandOnPods(pods []v1.Pod, container, cmd, taskID, cmdExecutorImage, cmdExecutorImageSecret string) error {
+func runBackgroundCommandOnPods(pods []v1.Pod, container, cmd, taskID string, timeout int64, cmdExecutorImage, cmdExecutorImageSecret string) error {
 	executorArgs := []string{
 		"/cmdexecutor",
-		"-timeout", strconv.FormatInt(perPodCommandExecTimeout, 10),
+		"-timeout", strconv.FormatInt(timeout, 10),
 		"-cmd", cmd,
 		"-taskid", taskID,
 	}
@@ -746,21 +906,28 @@ func waitForExecPodCompletion(pod *v1.Pod) error {
 	})
 }
 
+// backgroundCommandPod is a pod where a background command was started for
+// the task
+type backgroundCommandPod struct {
+	pod  v1.Pod
+	task commandTask
+}
+
 // cmdTerminationWatcher accumulates pods supplied to the given podListChan and when
 // the terminationSignalChan is sent a true signal, it terminates commands on the accumulated
-// pods
+// pods using the task of the last background command
 func cmdTerminationWatcher(
-	podListChan chan v1.Pod,
-	container *string,
+	podListChan chan backgroundCommandPod,
 	terminationSignalChan chan bool,
-	owner runtime.Object,
-	id string) {
+	owner runtime.Object) {
 	// For tracking, use a map/set keyed by uid to handle duplicates
 	podsToTerminate := make(map[string]v1.Pod)
+	var task commandTask
 	for {
 		select {
-		case pod := <-podListChan:
-			podsToTerminate[string(pod.GetUID())] = pod
+		case backgroundPod := <-podListChan:
+			podsToTerminate[string(backgroundPod.pod.GetUID())] = backgroundPod.pod
+			task = backgroundPod.task
 		case terminate := <-terminationSignalChan:
 			if terminate {
 				podList := make([]v1.Pod, 0)
@@ -768,7 +935,7 @@ func cmdTerminationWatcher(
 					podList = append(podList, pod)
 				}
 
-				if err := terminateCommandInPods(owner, podList, *container, id); err != nil {
+				if err := terminateCommandInPods(owner, podList, &task); err != nil {
 					log.RuleLog(nil, owner).Warnf("failed to terminate background command in pods due to: %v", err)
 				}
 			}
@@ -0,0 +1,238 @@
+package rule
+
+import (
+	"fmt"
+	"time"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/portworx/sched-ops/k8s/core"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// TestModeEnvVar is set in the environment of commands that are run when
+	// testing a rule so that they can skip anything disruptive
+	TestModeEnvVar = "STORK_RULE_TEST_MODE"
+	// TestModeHeader is set in requests that are sent for http actions when
+	// testing a rule so that the servers can skip anything disruptive
+	TestModeHeader = "X-Stork-Rule-Test-Mode"
+)
+
+// TestRuleOptions are the options used when testing a rule
+type TestRuleOptions struct {
+	// Execute runs the actions on the pods in test mode instead of only
+	// resolving where they would run
+	Execute bool
+}
+
+// TestRuleResult is what a rule would run, and where
+type TestRuleResult struct {
+	Rule string `json:"rule"`
+	// Namespace of the rule
+	Namespace string `json:"namespace"`
+	// PodNamespace is the namespace of the pods the rule runs on
+	PodNamespace string `json:"podNamespace"`
+	Type         Type   `json:"type"`
+	// Warnings for items of the rule that wouldn't run anything
+	Warnings []string            `json:"warnings,omitempty"`
+	Actions  []*TestActionResult `json:"actions"`
+}
+
+// TestActionResult is what an action of a rule would run on a pod
+type TestActionResult struct {
+	ItemIndex   int                      `json:"itemIndex"`
+	ActionIndex int                      `json:"actionIndex"`
+	Type        stork_api.RuleActionType `json:"type"`
+	Pod         string                   `json:"pod"`
+	Container   string                   `json:"container"`
+	// Action is the command or request that would be run
+	Action     string `json:"action"`
+	Background bool   `json:"background,omitempty"`
+	// Error is set if the action can't be run on the pod
+	Error string `json:"error,omitempty"`
+	// Skipped is set if the action wasn't executed in test mode
+	Skipped bool `json:"skipped,omitempty"`
+	// Result of executing the action, if it was executed
+	Result *stork_api.RuleActionResult `json:"result,omitempty"`
+}
+
+// TestRule resolves the pods in podNamespace that the actions of a rule would
+// run on, the same way ExecuteRule does, and checks that the actions can be
+// run on them. If Execute is set in the options the actions are also run in
+// test mode, with TestModeEnvVar set for commands and TestModeHeader set for
+// http requests. Background actions are never executed since they need to be
+// terminated by an owner.
+func TestRule(
+	rule *stork_api.Rule,
+	rType Type,
+	podNamespace string,
+	options TestRuleOptions,
+) (*TestRuleResult, error) {
+	if err := ValidateRule(rule, rType); err != nil {
+		return nil, err
+	}
+
+	result := &TestRuleResult{
+		Rule:         rule.GetName(),
+		Namespace:    rule.GetNamespace(),
+		PodNamespace: podNamespace,
+		Type:         rType,
+		Warnings:     make([]string, 0),
+		Actions:      make([]*TestActionResult, 0),
+	}
+	for itemIndex, item := range rule.Rules {
+		pods, err := core.Instance().GetPods(podNamespace, item.PodSelector)
+		if err != nil {
+			return nil, err
+		}
+		if len(pods.Items) == 0 {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("None of the pods matched selectors for rule item %v: %v", itemIndex, item.PodSelector))
+			continue
+		}
+
+		for actionIndex, action := range item.Actions {
+			podsForAction := pods.Items
+			if action.RunInSinglePod {
+				podsForAction = []v1.Pod{pods.Items[0]}
+			}
+			// Requests to a service are only sent once for all the pods
+			if action.Type == stork_api.RuleActionHTTP && action.HTTP.Service != "" {
+				podsForAction = []v1.Pod{{}}
+			}
+
+			for _, pod := range podsForAction {
+				actionResult := &TestActionResult{
+					ItemIndex:   itemIndex,
+					ActionIndex: actionIndex,
+					Type:        action.Type,
+					Pod:         pod.GetName(),
+					Container:   item.Container,
+					Action:      getTestActionDescription(action, pod, podNamespace),
+					Background:  action.Background,
+				}
+				result.Actions = append(result.Actions, actionResult)
+				if action.Type == stork_api.RuleActionCommand && actionResult.Container == "" &&
+					len(pod.Spec.Containers) == 1 {
+					actionResult.Container = pod.Spec.Containers[0].Name
+				}
+				if err := checkActionTarget(action, pod, item.Container); err != nil {
+					actionResult.Error = err.Error()
+					continue
+				}
+
+				if !options.Execute {
+					continue
+				}
+				if action.Background {
+					actionResult.Skipped = true
+					continue
+				}
+				actionResult.Result = executeTestAction(itemIndex, actionIndex, action, pod, item.Container, podNamespace)
+			}
+		}
+	}
+	return result, nil
+}
+
+// executeTestAction runs an action on a single pod in test mode and returns
+// its result. Actions are only attempted once unless retries are set.
+func executeTestAction(
+	itemIndex int,
+	actionIndex int,
+	action stork_api.RuleAction,
+	pod v1.Pod,
+	container string,
+	podNamespace string,
+) *stork_api.RuleActionResult {
+	recorder := newActionRecorder(itemIndex, actionIndex, action, container)
+	switch action.Type {
+	case stork_api.RuleActionCommand:
+		_, _ = runCommandOnPods([]v1.Pod{pod}, container, getTestModeCommand(action.Value),
+			getActionSteps(action, 1), time.Duration(action.Timeout)*time.Second, true, recorder)
+	case stork_api.RuleActionHTTP:
+		if action.Retries == nil {
+			noRetries := 0
+			action.Retries = &noRetries
+		}
+		action.HTTP = getTestModeHTTPAction(action.HTTP)
+		pods := []v1.Pod{pod}
+		if action.HTTP.Service != "" {
+			pods = nil
+		}
+		_ = executeHTTPAction(pods, podNamespace, action, recorder)
+	}
+	results := recorder.getResults()
+	if len(results) == 0 {
+		return nil
+	}
+	return results[0]
+}
+
+// checkActionTarget checks that an action can be run on a pod
+func checkActionTarget(action stork_api.RuleAction, pod v1.Pod, container string) error {
+	if action.Type == stork_api.RuleActionHTTP && action.HTTP.Service != "" {
+		return nil
+	}
+	if pod.Status.Phase != v1.PodRunning {
+		return fmt.Errorf("pod is in %v phase", pod.Status.Phase)
+	}
+	if action.Type == stork_api.RuleActionHTTP {
+		if pod.Status.PodIP == "" {
+			return fmt.Errorf("pod doesn't have an IP")
+		}
+		return nil
+	}
+
+	if container == "" {
+		if len(pod.Spec.Containers) != 1 {
+			return fmt.Errorf("container needs to be specified for pods with %v containers", len(pod.Spec.Containers))
+		}
+		container = pod.Spec.Containers[0].Name
+	}
+	for _, c := range pod.Spec.Containers {
+		if c.Name != container {
+			continue
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name == container && !status.Ready {
+				return fmt.Errorf("container %v isn't ready", container)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("container %v not found in pod", container)
+}
+
+// getTestActionDescription returns the command or request that an action
+// would run on a pod
+func getTestActionDescription(action stork_api.RuleAction, pod v1.Pod, podNamespace string) string {
+	if action.Type != stork_api.RuleActionHTTP {
+		return action.Value
+	}
+	host := pod.Status.PodIP
+	if action.HTTP.Service != "" {
+		host = fmt.Sprintf("%s.%s.svc", action.HTTP.Service, podNamespace)
+	}
+	method := action.HTTP.Method
+	if method == "" {
+		method = "GET"
+	}
+	return method + " " + getHTTPActionURL(action.HTTP, host)
+}
+
+// getTestModeCommand sets the test mode environment variable for a command
+func getTestModeCommand(cmd string) string {
+	return fmt.Sprintf("export %s=true; %s", TestModeEnvVar, cmd)
+}
+
+// getTestModeHTTPAction returns a copy of the http action with the test mode
+// header set
+func getTestModeHTTPAction(httpAction *stork_api.RuleHTTPAction) *stork_api.RuleHTTPAction {
+	testAction := httpAction.DeepCopy()
+	if testAction.Headers == nil {
+		testAction.Headers = make(map[string]string)
+	}
+	testAction.Headers[TestModeHeader] = "true"
+	return testAction
+}
@@ -0,0 +1,116 @@
+//go:build unittest
+// +build unittest
+
+package rule
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestTestRuleExecute(t *testing.T) {
+	core.SetInstance(core.New(fake.NewSimpleClientset()))
+	testModeHeaders := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testModeHeaders = append(testModeHeaders, r.Header.Get(TestModeHeader))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("quiesced"))
+	}))
+	defer server.Close()
+	host, portString, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err, "Error parsing server address")
+	port, err := strconv.Atoi(portString)
+	require.NoError(t, err, "Error parsing server port")
+
+	pod := &v1.Pod{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      "app-0",
+			Namespace: "test",
+			Labels:    map[string]string{"app": "test"},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: "app"}},
+		},
+		Status: v1.PodStatus{
+			Phase: v1.PodRunning,
+			PodIP: host,
+		},
+	}
+	_, err = core.Instance().CreatePod(pod)
+	require.NoError(t, err, "Error creating pod")
+
+	rule := &stork_api.Rule{
+		ObjectMeta: meta.ObjectMeta{Name: "testrule", Namespace: "test"},
+		Rules: []stork_api.RuleItem{
+			{
+				PodSelector: map[string]string{"app": "test"},
+				Actions: []stork_api.RuleAction{
+					{
+						Type:       stork_api.RuleActionCommand,
+						Value:      "sleep 60",
+						Background: true,
+					},
+					{
+						Type: stork_api.RuleActionHTTP,
+						HTTP: &stork_api.RuleHTTPAction{
+							Port: int32(port),
+							Path: "/quiesce",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	result, err := TestRule(rule, PreExecRule, "test", TestRuleOptions{})
+	require.NoError(t, err, "Error testing rule")
+	require.Equal(t, "test", result.Namespace)
+	require.Equal(t, "test", result.PodNamespace)
+	require.Len(t, result.Actions, 2)
+	require.Equal(t, "app", result.Actions[0].Container, "Only container should be used")
+	require.Empty(t, result.Actions[0].Error)
+	require.Nil(t, result.Actions[1].Result, "Action shouldn't be executed")
+
+	result, err = TestRule(rule, PreExecRule, "test", TestRuleOptions{Execute: true})
+	require.NoError(t, err, "Error testing rule")
+	require.Len(t, result.Actions, 2)
+	require.True(t, result.Actions[0].Skipped, "Background action should be skipped")
+	require.Nil(t, result.Actions[0].Result)
+	require.NotNil(t, result.Actions[1].Result, "Action should be executed")
+	require.Equal(t, stork_api.RuleExecutionStatusSuccessful, result.Actions[1].Result.Status)
+	require.Equal(t, http.StatusOK, result.Actions[1].Result.HTTPStatus)
+	require.Equal(t, "quiesced", result.Actions[1].Result.Stdout)
+	require.Equal(t, 1, result.Actions[1].Result.ActionIndex)
+	require.Equal(t, []string{"true"}, testModeHeaders, "Request should be sent once in test mode")
+	require.Nil(t, rule.Rules[0].Actions[1].HTTP.Headers, "Rule shouldn't be modified")
+}
+
+func TestCheckActionTarget(t *testing.T) {
+	action := stork_api.RuleAction{Type: stork_api.RuleActionCommand, Value: "sync"}
+	pod := v1.Pod{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: "app"}, {Name: "sidecar"}},
+		},
+		Status: v1.PodStatus{
+			Phase:             v1.PodRunning,
+			ContainerStatuses: []v1.ContainerStatus{{Name: "app", Ready: true}, {Name: "sidecar"}},
+		},
+	}
+	require.NoError(t, checkActionTarget(action, pod, "app"))
+	require.Error(t, checkActionTarget(action, pod, ""), "Container is required for multiple containers")
+	require.Error(t, checkActionTarget(action, pod, "db"), "Container doesn't exist")
+	require.Error(t, checkActionTarget(action, pod, "sidecar"), "Container isn't ready")
+
+	pod.Status.Phase = v1.PodPending
+	require.Error(t, checkActionTarget(action, pod, "app"), "Pod isn't running")
+}
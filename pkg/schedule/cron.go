@@ -0,0 +1,273 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSearchLimit is how far ahead to look for the next trigger time of a cron
+// expression. Expressions like "0 0 30 2 *" never match.
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// cronMaxOccurrence is the highest N allowed for DAY#N
+const cronMaxOccurrence = 5
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var cronMonths = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+var cronWeekdays = map[string]int{
+	"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+}
+
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	cronMinute  = cronField{name: "minute", min: 0, max: 59}
+	cronHour    = cronField{name: "hour", min: 0, max: 23}
+	cronDom     = cronField{name: "day of month", min: 1, max: 31}
+	cronMonth   = cronField{name: "month", min: 1, max: 12, names: cronMonths}
+	cronWeekday = cronField{name: "day of week", min: 0, max: 7, names: cronWeekdays}
+)
+
+// CronSchedule is a parsed cron expression. The standard 5 fields (minute,
+// hour, day of month, month and day of week) are supported with lists, ranges,
+// steps and month and day names. The day of week field also accepts DAY#N for
+// the Nth occurrence of a day in the month, for example SUN#1 for the first
+// Sunday.
+type CronSchedule struct {
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	// nthDow has bit N set for weekday D if the Nth occurrence of D in the
+	// month matches
+	nthDow [7]uint64
+	// domAny and dowAny are set if the field was "*". Like cron, if both day
+	// fields are restricted a day matches if either of them matches.
+	domAny bool
+	dowAny bool
+}
+
+// ParseCronExpression parses a standard 5 field cron expression
+func ParseCronExpression(expression string) (*CronSchedule, error) {
+	expression = strings.TrimSpace(expression)
+	if macro, ok := cronMacros[strings.ToLower(expression)]; ok {
+		expression = macro
+	}
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields in cron expression, found %v", len(fields))
+	}
+
+	var err error
+	schedule := &CronSchedule{}
+	if schedule.minute, _, err = parseCronField(fields[0], cronMinute); err != nil {
+		return nil, err
+	}
+	if schedule.hour, _, err = parseCronField(fields[1], cronHour); err != nil {
+		return nil, err
+	}
+	if schedule.dom, schedule.domAny, err = parseCronField(fields[2], cronDom); err != nil {
+		return nil, err
+	}
+	if schedule.month, _, err = parseCronField(fields[3], cronMonth); err != nil {
+		return nil, err
+	}
+	if err = schedule.parseWeekdays(fields[4]); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (c *CronSchedule) parseWeekdays(field string) error {
+	var lists []string
+	for _, item := range strings.Split(field, ",") {
+		parts := strings.Split(item, "#")
+		if len(parts) == 1 {
+			lists = append(lists, item)
+			continue
+		}
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid %v %q", cronWeekday.name, item)
+		}
+		day, err := parseCronValue(parts[0], cronWeekday)
+		if err != nil {
+			return err
+		}
+		nth, err := strconv.Atoi(parts[1])
+		if err != nil || nth < 1 || nth > cronMaxOccurrence {
+			return fmt.Errorf("invalid occurrence %q in %v, expected 1-%v", parts[1], cronWeekday.name, cronMaxOccurrence)
+		}
+		c.nthDow[day%7] |= 1 << uint(nth)
+	}
+	if len(lists) == 0 {
+		return nil
+	}
+	bits, any, err := parseCronField(strings.Join(lists, ","), cronWeekday)
+	if err != nil {
+		return err
+	}
+	// 7 is also Sunday
+	if bits&(1<<7) != 0 {
+		bits = (bits | 1) &^ (1 << 7)
+	}
+	c.dow = bits
+	c.dowAny = any && len(lists) == len(strings.Split(field, ","))
+	return nil
+}
+
+// parseCronField returns the values that match the field as a bitset and
+// whether the field matches any value
+func parseCronField(field string, f cronField) (uint64, bool, error) {
+	var bits uint64
+	any := false
+	for _, item := range strings.Split(field, ",") {
+		step := 1
+		rangeStr := item
+		if i := strings.Index(item, "/"); i >= 0 {
+			var err error
+			step, err = strconv.Atoi(item[i+1:])
+			if err != nil || step < 1 {
+				return 0, false, fmt.Errorf("invalid step %q in %v", item[i+1:], f.name)
+			}
+			rangeStr = item[:i]
+		}
+
+		start, end := f.min, f.max
+		switch {
+		case rangeStr == "*" || rangeStr == "?":
+			if step == 1 {
+				any = true
+			}
+		case strings.Contains(rangeStr, "-"):
+			bounds := strings.SplitN(rangeStr, "-", 2)
+			var err error
+			if start, err = parseCronValue(bounds[0], f); err != nil {
+				return 0, false, err
+			}
+			if end, err = parseCronValue(bounds[1], f); err != nil {
+				return 0, false, err
+			}
+			if start > end {
+				return 0, false, fmt.Errorf("invalid range %q in %v", rangeStr, f.name)
+			}
+		default:
+			var err error
+			if start, err = parseCronValue(rangeStr, f); err != nil {
+				return 0, false, err
+			}
+			// A single value with a step runs until the end of the range
+			if step == 1 {
+				end = start
+			}
+		}
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+	return bits, any, nil
+}
+
+func parseCronValue(value string, f cronField) (int, error) {
+	if v, ok := f.names[strings.ToUpper(value)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid %v %q, expected %v-%v", f.name, value, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next returns the first time after t that matches the schedule, in the
+// location of t. Returns the zero time if there is no match within the next 5
+// years.
+func (c *CronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	limit := t.Add(cronSearchLimit)
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			// Add instead of using time.Date so that the time always moves
+			// forward when the clocks go back
+			t = t.Add(time.Hour - time.Duration(t.Minute())*time.Minute)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// Previous returns the last time at or before t that matches the schedule, in
+// the location of t. Returns the zero time if there is no match within the
+// previous 5 years.
+func (c *CronSchedule) Previous(t time.Time) time.Time {
+	loc := t.Location()
+	limit := t.Add(-cronSearchLimit)
+	t = t.Truncate(time.Minute)
+	for t.After(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc).Add(-time.Minute)
+			continue
+		}
+		if !c.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc).Add(-time.Minute)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			// Subtract instead of using time.Date so that the time always
+			// moves back when the clocks go forward
+			t = t.Add(-time.Duration(t.Minute()+1) * time.Minute)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(-time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (c *CronSchedule) matchesDay(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	weekday := t.Weekday()
+	nth := (t.Day()-1)/7 + 1
+	dowMatch := c.dow&(1<<uint(weekday)) != 0 || c.nthDow[weekday]&(1<<uint(nth)) != 0
+	if c.domAny || c.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,125 @@
+//go:build unittest
+// +build unittest
+
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCronExpression(t *testing.T) {
+	valid := []string{
+		"* * * * *",
+		"30 2 * * 1-5",
+		"*/15 0-6/2 1,15 JAN-jun sun",
+		"0 0 * * 7",
+		"0 0 * * SUN#1,FRI#5",
+		"0 12 ? * *",
+		"5/10 * * * *",
+		"@daily",
+		"@Weekly",
+	}
+	for _, expression := range valid {
+		_, err := ParseCronExpression(expression)
+		require.NoError(t, err, "Expression %q should be valid", expression)
+	}
+
+	invalid := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * FOO *",
+		"* * * * SUN#6",
+		"* * * * SUN#1#2",
+		"@every",
+	}
+	for _, expression := range invalid {
+		_, err := ParseCronExpression(expression)
+		require.Error(t, err, "Expression %q should be invalid", expression)
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err, "Error loading location")
+
+	// Thursday
+	from := time.Date(2019, time.February, 7, 23, 16, 30, 0, time.UTC)
+	tests := []struct {
+		expression string
+		from       time.Time
+		expected   time.Time
+	}{
+		{"* * * * *", from, time.Date(2019, time.February, 7, 23, 17, 0, 0, time.UTC)},
+		{"30 2 * * 1-5", from, time.Date(2019, time.February, 8, 2, 30, 0, 0, time.UTC)},
+		{"30 2 * * 1-5", from.Add(24 * time.Hour), time.Date(2019, time.February, 11, 2, 30, 0, 0, time.UTC)},
+		{"0 0 * * SUN#1", from, time.Date(2019, time.March, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 0", from, time.Date(2019, time.February, 10, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", from, time.Date(2019, time.February, 10, 0, 0, 0, 0, time.UTC)},
+		// Both day fields are restricted so either can match
+		{"0 0 1 * MON", from, time.Date(2019, time.February, 11, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", from, time.Date(2020, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", from, time.Time{}},
+		{"@monthly", from, time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC)},
+		{"*/20 9-17 * * *", from, time.Date(2019, time.February, 8, 9, 0, 0, 0, time.UTC)},
+		// 2:30AM doesn't exist on the day the clocks go forward in Berlin
+		{"30 2 * * *", time.Date(2019, time.March, 30, 12, 0, 0, 0, berlin), time.Date(2019, time.April, 1, 2, 30, 0, 0, berlin)},
+		{"0 3 * * *", time.Date(2019, time.March, 30, 12, 0, 0, 0, berlin), time.Date(2019, time.March, 31, 3, 0, 0, 0, berlin)},
+		// 2:30AM happens twice on the day the clocks go back
+		{"30 2 * * *", time.Date(2019, time.October, 27, 2, 30, 0, 0, berlin), time.Date(2019, time.October, 28, 2, 30, 0, 0, berlin)},
+	}
+	for _, test := range tests {
+		cronSchedule, err := ParseCronExpression(test.expression)
+		require.NoError(t, err, "Error parsing %q", test.expression)
+		next := cronSchedule.Next(test.from)
+		require.True(t, test.expected.Equal(next), "Wrong next time for %q from %v: expected %v, got %v",
+			test.expression, test.from, test.expected, next)
+	}
+}
+
+func TestCronSchedulePrevious(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err, "Error loading location")
+
+	// Thursday
+	to := time.Date(2019, time.February, 7, 23, 16, 30, 0, time.UTC)
+	tests := []struct {
+		expression string
+		to         time.Time
+		expected   time.Time
+	}{
+		{"* * * * *", to, time.Date(2019, time.February, 7, 23, 16, 0, 0, time.UTC)},
+		// Times that match are returned
+		{"16 23 * * *", time.Date(2019, time.February, 7, 23, 16, 0, 0, time.UTC), time.Date(2019, time.February, 7, 23, 16, 0, 0, time.UTC)},
+		{"30 2 * * 1-5", to, time.Date(2019, time.February, 7, 2, 30, 0, 0, time.UTC)},
+		{"30 2 * * 1-5", to.Add(72 * time.Hour), time.Date(2019, time.February, 8, 2, 30, 0, 0, time.UTC)},
+		{"0 0 * * SUN#1", to, time.Date(2019, time.February, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", to, time.Date(2019, time.February, 3, 0, 0, 0, 0, time.UTC)},
+		// Both day fields are restricted so either can match
+		{"0 0 1 * MON", to, time.Date(2019, time.February, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", to, time.Date(2016, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", to, time.Time{}},
+		{"@monthly", to, time.Date(2019, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"*/20 9-17 * * *", to, time.Date(2019, time.February, 7, 17, 40, 0, 0, time.UTC)},
+		// 2:30AM doesn't exist on the day the clocks go forward in Berlin
+		{"30 2 * * *", time.Date(2019, time.March, 31, 12, 0, 0, 0, berlin), time.Date(2019, time.March, 30, 2, 30, 0, 0, berlin)},
+		{"0 3 * * *", time.Date(2019, time.March, 31, 12, 0, 0, 0, berlin), time.Date(2019, time.March, 31, 3, 0, 0, 0, berlin)},
+	}
+	for _, test := range tests {
+		cronSchedule, err := ParseCronExpression(test.expression)
+		require.NoError(t, err, "Error parsing %q", test.expression)
+		previous := cronSchedule.Previous(test.to)
+		require.True(t, test.expected.Equal(previous), "Wrong previous time for %q to %v: expected %v, got %v",
+			test.expression, test.to, test.expected, previous)
+	}
+}
@@ -0,0 +1,152 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+)
+
+// RetentionCandidate is an object created by a schedule that is considered
+// for retention
+type RetentionCandidate struct {
+	// Timestamp is the time the object was scheduled for
+	Timestamp time.Time
+	// Complete is set once the object is no longer in progress
+	Complete bool
+	// Successful is set if the object completed successfully
+	Successful bool
+}
+
+// RetentionDecision is whether an object should be retained and the tiers
+// that claim it
+type RetentionDecision struct {
+	Retain bool
+	Tiers  []stork_api.RetentionTierType
+}
+
+// GetRetentionPolicy returns the retention policy configured in a schedule
+// policy along with the location used to calculate the periods of its tiers.
+// Returns nil if the schedule policy uses the Retain count of each policy
+// type.
+func GetRetentionPolicy(policyName string, namespace string) (*stork_api.RetentionPolicy, *time.Location, error) {
+	schedulePolicy, err := getSchedulePolicy(policyName, namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	if schedulePolicy.Policy.Retention == nil {
+		return nil, nil, nil
+	}
+	loc, err := schedulePolicy.Policy.GetLocation()
+	if err != nil {
+		return nil, nil, err
+	}
+	return schedulePolicy.Policy.Retention, loc, nil
+}
+
+// ApplyRetention decides which of the objects created by a schedule should be
+// retained. Successful objects are retained if they are within the max age of
+// the policy or claimed by any of its tiers, and the latest one is always
+// retained. Objects that are in progress are retained. Only the most recent
+// failed objects, up to the FailedKeep of the policy, are retained, and only
+// until there is an older successful object that is retained, so that
+// failures don't pile up behind long lived tiers.
+func ApplyRetention(
+	retention *stork_api.RetentionPolicy,
+	loc *time.Location,
+	candidates []RetentionCandidate,
+) []RetentionDecision {
+	now := GetCurrentTime().In(loc)
+	decisions := make([]RetentionDecision, len(candidates))
+
+	// Look at the successful objects from the newest to the oldest
+	successful := make([]int, 0)
+	for i, candidate := range candidates {
+		if candidate.Successful {
+			successful = append(successful, i)
+		}
+	}
+	sort.SliceStable(successful, func(i, j int) bool {
+		return candidates[successful[i]].Timestamp.After(candidates[successful[j]].Timestamp)
+	})
+
+	for n, i := range successful {
+		if n == 0 || isWithinDays(now, candidates[i].Timestamp, retention.MaxAgeDays) {
+			decisions[i].Retain = true
+		}
+	}
+
+	for _, tier := range retention.Tiers {
+		periods := make(map[string]bool)
+		for _, i := range successful {
+			timestamp := candidates[i].Timestamp.In(loc)
+			period := getRetentionPeriod(tier.Type, timestamp)
+			if periods[period] {
+				continue
+			}
+			periods[period] = true
+			if tier.Keep > 0 && len(periods) > tier.Keep {
+				break
+			}
+			if tier.MaxAgeDays > 0 && !isWithinDays(now, timestamp, tier.MaxAgeDays) {
+				break
+			}
+			decisions[i].Retain = true
+			decisions[i].Tiers = append(decisions[i].Tiers, tier.Type)
+		}
+	}
+
+	var oldestRetained time.Time
+	for _, i := range successful {
+		if decisions[i].Retain {
+			oldestRetained = candidates[i].Timestamp
+		}
+	}
+	failed := make([]int, 0)
+	for i, candidate := range candidates {
+		if candidate.Successful {
+			continue
+		}
+		if !candidate.Complete {
+			decisions[i].Retain = true
+			continue
+		}
+		if oldestRetained.IsZero() || candidate.Timestamp.After(oldestRetained) {
+			failed = append(failed, i)
+		}
+	}
+	sort.SliceStable(failed, func(i, j int) bool {
+		return candidates[failed[i]].Timestamp.After(candidates[failed[j]].Timestamp)
+	})
+	for n, i := range failed {
+		if n >= retention.GetFailedKeep() {
+			break
+		}
+		decisions[i].Retain = true
+	}
+	return decisions
+}
+
+func isWithinDays(now time.Time, timestamp time.Time, days int) bool {
+	return days > 0 && now.Sub(timestamp) < time.Duration(days)*24*time.Hour
+}
+
+// getRetentionPeriod returns a key that is the same for all the times in the
+// same period of a tier
+func getRetentionPeriod(tierType stork_api.RetentionTierType, timestamp time.Time) string {
+	switch tierType {
+	case stork_api.RetentionTierHourly:
+		return timestamp.Format("2006-01-02T15")
+	case stork_api.RetentionTierDaily:
+		return timestamp.Format("2006-01-02")
+	case stork_api.RetentionTierWeekly:
+		year, week := timestamp.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case stork_api.RetentionTierMonthly:
+		return timestamp.Format("2006-01")
+	case stork_api.RetentionTierYearly:
+		return timestamp.Format("2006")
+	}
+	return ""
+}
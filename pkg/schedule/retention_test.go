@@ -0,0 +1,247 @@
+//go:build unittest
+// +build unittest
+
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// dailyCandidates returns successful candidates for a daily schedule ending
+// on the given day, newest first
+func dailyCandidates(last time.Time, days int) []RetentionCandidate {
+	candidates := make([]RetentionCandidate, 0)
+	for i := 0; i < days; i++ {
+		candidates = append(candidates, RetentionCandidate{
+			Timestamp:  last.AddDate(0, 0, -i),
+			Complete:   true,
+			Successful: true,
+		})
+	}
+	return candidates
+}
+
+func retainedTimestamps(candidates []RetentionCandidate, decisions []RetentionDecision) []time.Time {
+	retained := make([]time.Time, 0)
+	for i, decision := range decisions {
+		if decision.Retain {
+			retained = append(retained, candidates[i].Timestamp)
+		}
+	}
+	return retained
+}
+
+func TestApplyRetentionTiers(t *testing.T) {
+	// Tuesday
+	last := time.Date(2021, time.June, 1, 2, 30, 0, 0, time.UTC)
+	mockNow := last.Add(time.Hour)
+	setMockTime(&mockNow)
+	defer setMockTime(nil)
+
+	retention := &stork_api.RetentionPolicy{
+		Tiers: []stork_api.RetentionTier{
+			{Type: stork_api.RetentionTierDaily, Keep: 7},
+			{Type: stork_api.RetentionTierWeekly, Keep: 4},
+			{Type: stork_api.RetentionTierMonthly, Keep: 12},
+		},
+	}
+	candidates := dailyCandidates(last, 60)
+	decisions := ApplyRetention(retention, time.UTC, candidates)
+
+	expected := []time.Time{
+		time.Date(2021, time.June, 1, 2, 30, 0, 0, time.UTC),
+		time.Date(2021, time.May, 31, 2, 30, 0, 0, time.UTC),
+		time.Date(2021, time.May, 30, 2, 30, 0, 0, time.UTC),
+		time.Date(2021, time.May, 29, 2, 30, 0, 0, time.UTC),
+		time.Date(2021, time.May, 28, 2, 30, 0, 0, time.UTC),
+		time.Date(2021, time.May, 27, 2, 30, 0, 0, time.UTC),
+		time.Date(2021, time.May, 26, 2, 30, 0, 0, time.UTC),
+		// Weekly
+		time.Date(2021, time.May, 23, 2, 30, 0, 0, time.UTC),
+		time.Date(2021, time.May, 16, 2, 30, 0, 0, time.UTC),
+		// Monthly
+		time.Date(2021, time.April, 30, 2, 30, 0, 0, time.UTC),
+	}
+	require.Equal(t, expected, retainedTimestamps(candidates, decisions))
+
+	// The same backup is promoted instead of being duplicated
+	require.Equal(t, []stork_api.RetentionTierType{
+		stork_api.RetentionTierDaily,
+		stork_api.RetentionTierWeekly,
+		stork_api.RetentionTierMonthly,
+	}, decisions[0].Tiers)
+	require.Equal(t, []stork_api.RetentionTierType{
+		stork_api.RetentionTierDaily,
+		stork_api.RetentionTierMonthly,
+	}, decisions[1].Tiers)
+	require.Equal(t, []stork_api.RetentionTierType{
+		stork_api.RetentionTierDaily,
+		stork_api.RetentionTierWeekly,
+	}, decisions[2].Tiers)
+	require.Empty(t, decisions[10].Tiers, "Unclaimed backups shouldn't have tiers")
+
+	// Tiers limited by age instead of count
+	retention = &stork_api.RetentionPolicy{
+		Tiers: []stork_api.RetentionTier{
+			{Type: stork_api.RetentionTierWeekly, MaxAgeDays: 14},
+		},
+	}
+	decisions = ApplyRetention(retention, time.UTC, candidates)
+	require.Equal(t, []time.Time{
+		time.Date(2021, time.June, 1, 2, 30, 0, 0, time.UTC),
+		time.Date(2021, time.May, 30, 2, 30, 0, 0, time.UTC),
+		time.Date(2021, time.May, 23, 2, 30, 0, 0, time.UTC),
+	}, retainedTimestamps(candidates, decisions))
+}
+
+func TestApplyRetentionMaxAge(t *testing.T) {
+	last := time.Date(2021, time.June, 1, 2, 30, 0, 0, time.UTC)
+	mockNow := last.Add(time.Hour)
+	setMockTime(&mockNow)
+	defer setMockTime(nil)
+
+	retention := &stork_api.RetentionPolicy{
+		MaxAgeDays: 3,
+	}
+	candidates := dailyCandidates(last, 10)
+	decisions := ApplyRetention(retention, time.UTC, candidates)
+	require.Equal(t, []time.Time{
+		time.Date(2021, time.June, 1, 2, 30, 0, 0, time.UTC),
+		time.Date(2021, time.May, 31, 2, 30, 0, 0, time.UTC),
+		time.Date(2021, time.May, 30, 2, 30, 0, 0, time.UTC),
+	}, retainedTimestamps(candidates, decisions))
+
+	// The latest successful backup is always retained
+	mockNow = last.AddDate(0, 1, 0)
+	setMockTime(&mockNow)
+	decisions = ApplyRetention(retention, time.UTC, candidates)
+	require.Equal(t, []time.Time{last}, retainedTimestamps(candidates, decisions))
+
+	// Failed backups are retained until there is an older successful backup
+	// that is retained, and backups in progress are always retained
+	candidates = []RetentionCandidate{
+		{Timestamp: last.AddDate(0, 0, -3), Complete: true},
+		{Timestamp: last.AddDate(0, 0, -2), Complete: true, Successful: true},
+		{Timestamp: last.AddDate(0, 0, -1), Complete: true},
+		{Timestamp: last},
+	}
+	decisions = ApplyRetention(retention, time.UTC, candidates)
+	require.Equal(t, []time.Time{
+		last.AddDate(0, 0, -2),
+		last.AddDate(0, 0, -1),
+		last,
+	}, retainedTimestamps(candidates, decisions))
+
+	candidates = []RetentionCandidate{
+		{Timestamp: last.AddDate(0, 0, -1), Complete: true},
+		{Timestamp: last, Complete: true},
+	}
+	decisions = ApplyRetention(retention, time.UTC, candidates)
+	require.Equal(t, []time.Time{
+		last.AddDate(0, 0, -1),
+		last,
+	}, retainedTimestamps(candidates, decisions))
+}
+
+func TestApplyRetentionFailedKeep(t *testing.T) {
+	last := time.Date(2021, time.June, 1, 2, 30, 0, 0, time.UTC)
+	mockNow := last.Add(time.Hour)
+	setMockTime(&mockNow)
+	defer setMockTime(nil)
+
+	// The yearly tier retains a successful backup from years ago, which
+	// would keep every failed backup since then without a limit
+	candidates := []RetentionCandidate{
+		{Timestamp: last.AddDate(-2, 0, 0), Complete: true, Successful: true},
+	}
+	for i := 10; i > 0; i-- {
+		candidates = append(candidates, RetentionCandidate{Timestamp: last.AddDate(0, 0, -i), Complete: true})
+	}
+	candidates = append(candidates, RetentionCandidate{Timestamp: last})
+	retention := &stork_api.RetentionPolicy{
+		Tiers: []stork_api.RetentionTier{
+			{Type: stork_api.RetentionTierYearly, Keep: 5},
+		},
+		FailedKeep: 2,
+	}
+	decisions := ApplyRetention(retention, time.UTC, candidates)
+	require.Equal(t, []time.Time{
+		last.AddDate(-2, 0, 0),
+		last.AddDate(0, 0, -2),
+		last.AddDate(0, 0, -1),
+		last,
+	}, retainedTimestamps(candidates, decisions), "Only the latest failed backups should be retained")
+
+	retention.FailedKeep = 0
+	decisions = ApplyRetention(retention, time.UTC, candidates)
+	require.Len(t, retainedTimestamps(candidates, decisions), 2+stork_api.DefaultRetentionFailedKeep,
+		"The default number of failed backups should be retained")
+}
+
+func TestApplyRetentionTimezone(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err, "Error loading location")
+	mockNow := time.Date(2021, time.June, 2, 0, 0, 0, 0, time.UTC)
+	setMockTime(&mockNow)
+	defer setMockTime(nil)
+
+	// 31st May in UTC but 1st June in Tokyo
+	candidates := []RetentionCandidate{
+		{Timestamp: time.Date(2021, time.May, 31, 15, 30, 0, 0, time.UTC), Complete: true, Successful: true},
+		{Timestamp: time.Date(2021, time.May, 31, 14, 30, 0, 0, time.UTC), Complete: true, Successful: true},
+	}
+	retention := &stork_api.RetentionPolicy{
+		Tiers: []stork_api.RetentionTier{
+			{Type: stork_api.RetentionTierMonthly, Keep: 2},
+		},
+	}
+	decisions := ApplyRetention(retention, loc, candidates)
+	require.True(t, decisions[0].Retain, "Latest backup in June should be retained")
+	require.True(t, decisions[1].Retain, "Latest backup in May should be retained")
+
+	decisions = ApplyRetention(retention, time.UTC, candidates)
+	require.True(t, decisions[0].Retain, "Latest backup should be retained")
+	require.False(t, decisions[1].Retain, "Older backup in May shouldn't be retained")
+}
+
+func TestValidateRetentionPolicy(t *testing.T) {
+	valid := []*stork_api.RetentionPolicy{
+		{MaxAgeDays: 90},
+		{Tiers: []stork_api.RetentionTier{
+			{Type: stork_api.RetentionTierDaily, Keep: 7},
+			{Type: stork_api.RetentionTierYearly, MaxAgeDays: 3650},
+		}},
+	}
+	for _, retention := range valid {
+		policy := &stork_api.SchedulePolicy{
+			ObjectMeta: meta.ObjectMeta{Name: "retentionpolicy"},
+			Policy:     stork_api.SchedulePolicyItem{Retention: retention},
+		}
+		require.NoError(t, ValidateSchedulePolicy(policy), "Retention %v should be valid", retention)
+	}
+
+	invalid := []*stork_api.RetentionPolicy{
+		{},
+		{MaxAgeDays: -1},
+		{MaxAgeDays: 90, FailedKeep: -1},
+		{Tiers: []stork_api.RetentionTier{{Type: "Fortnightly", Keep: 2}}},
+		{Tiers: []stork_api.RetentionTier{{Type: stork_api.RetentionTierDaily}}},
+		{Tiers: []stork_api.RetentionTier{{Type: stork_api.RetentionTierDaily, Keep: -7}}},
+		{Tiers: []stork_api.RetentionTier{
+			{Type: stork_api.RetentionTierDaily, Keep: 7},
+			{Type: stork_api.RetentionTierDaily, Keep: 14},
+		}},
+	}
+	for _, retention := range invalid {
+		policy := &stork_api.SchedulePolicy{
+			ObjectMeta: meta.ObjectMeta{Name: "retentionpolicy"},
+			Policy:     stork_api.SchedulePolicyItem{Retention: retention},
+		}
+		require.Error(t, ValidateSchedulePolicy(policy), "Retention %v should be invalid", retention)
+	}
+}
@@ -32,6 +32,19 @@ const (
 	MockTimeConfigMapNamespace = "kube-system"
 	// MockTimeConfigMapKey is the key name in the config map data that contains the time
 	MockTimeConfigMapKey = "time"
+	// defaultStartingDeadline is how long after its scheduled time a run can
+	// be started if the schedule doesn't specify it
+	defaultStartingDeadline = time.Hour
+	// maxScheduledRuns is the maximum number of scheduled runs since the last
+	// trigger that are checked at a time. Only the most recent ones are
+	// checked if there are more.
+	maxScheduledRuns = 100
+	// maxBlackoutWindowChain is the maximum number of back to back blackout
+	// windows that are followed to find when a deferred run could start
+	maxBlackoutWindowChain = 16
+	// maxMissedRuns is the number of missed runs kept in the status of a
+	// schedule
+	maxMissedRuns = 10
 )
 
 var mockTime *time.Time
@@ -49,6 +62,52 @@ func GetCurrentTime() time.Time {
 	return time.Now()
 }
 
+// TriggerOptions are the options of a schedule that control when its runs
+// are started
+type TriggerOptions struct {
+	// StartingDeadline is how long after its scheduled time a run can still
+	// be started. For runs that were deferred by blackout windows it is
+	// measured from the end of the windows. Defaults to one hour.
+	StartingDeadline time.Duration
+	// CatchUpPolicy is how runs that missed their starting deadline are
+	// handled. Defaults to skipping them.
+	CatchUpPolicy stork_api.CatchUpPolicyType
+}
+
+// GetTriggerOptions returns the trigger options for the starting deadline and
+// catch-up policy set in the spec of a schedule
+func GetTriggerOptions(
+	startingDeadlineSeconds *int64,
+	catchUpPolicy stork_api.CatchUpPolicyType,
+) TriggerOptions {
+	options := TriggerOptions{
+		CatchUpPolicy: catchUpPolicy,
+	}
+	if startingDeadlineSeconds != nil {
+		options.StartingDeadline = time.Duration(*startingDeadlineSeconds) * time.Second
+	}
+	return options
+}
+
+// TriggerResult is the result of checking if a run of a schedule should be
+// started
+type TriggerResult struct {
+	// Trigger is true if a run should be started now
+	Trigger bool
+	// ScheduledTime is the time the run that is being started or deferred
+	// was scheduled for. For Interval policies it is the current time if the
+	// run is started and the time it was due if it is deferred.
+	ScheduledTime time.Time
+	// Deferred is true if a run is due but is in a blackout window
+	Deferred bool
+	// DeferReason is the reason the run was deferred
+	DeferReason string
+	// Skipped are the scheduled times of the runs that won't be started
+	Skipped []time.Time
+	// SkipReason is the reason the runs were skipped
+	SkipReason string
+}
+
 // TriggerRequired Check if a trigger is required for a policy given the last
 // trigger time
 func TriggerRequired(
@@ -57,95 +116,460 @@ func TriggerRequired(
 	policyType stork_api.SchedulePolicyType,
 	lastTrigger meta.Time,
 ) (bool, error) {
-	schedulePolicy, err := getSchedulePolicy(policyName, namespace)
+	result, err := CheckTrigger(policyName, namespace, policyType, lastTrigger, TriggerOptions{})
 	if err != nil {
 		return false, err
 	}
+	return result.Trigger, nil
+}
+
+// CheckTrigger checks if a run should be started for a policy given the last
+// trigger time. Runs that are due are deferred if the current time is in one
+// of the blackout windows of the policy. Runs that missed their starting
+// deadline are skipped or caught up based on the catch-up policy.
+func CheckTrigger(
+	policyName string,
+	namespace string,
+	policyType stork_api.SchedulePolicyType,
+	lastTrigger meta.Time,
+	options TriggerOptions,
+) (*TriggerResult, error) {
+	schedulePolicy, err := getSchedulePolicy(policyName, namespace)
+	if err != nil {
+		return nil, err
+	}
 
 	if err := ValidateSchedulePolicy(schedulePolicy); err != nil {
-		return false, err
+		return nil, err
 	}
 
-	now := GetCurrentTime()
+	loc, err := schedulePolicy.Policy.GetLocation()
+	if err != nil {
+		return nil, err
+	}
+	now := GetCurrentTime().In(loc)
+	result := &TriggerResult{}
 	switch policyType {
 	case stork_api.SchedulePolicyTypeInterval:
 		if schedulePolicy.Policy.Interval == nil {
-			return false, nil
+			return result, nil
 		}
 		duration := time.Duration(schedulePolicy.Policy.Interval.IntervalMinutes) * time.Minute
 		// Trigger if more than intervalMinutes has passed since
 		// last trigger
-		if lastTrigger.Add(duration).Before(now) {
-			return true, nil
+		if !lastTrigger.Add(duration).Before(now) {
+			return result, nil
+		}
+		if !lastTrigger.IsZero() {
+			result.ScheduledTime = lastTrigger.Add(duration)
+		}
+	case stork_api.SchedulePolicyTypeDaily,
+		stork_api.SchedulePolicyTypeWeekly,
+		stork_api.SchedulePolicyTypeMonthly,
+		stork_api.SchedulePolicyTypeCron:
+		deadline := options.StartingDeadline
+		if deadline <= 0 {
+			deadline = defaultStartingDeadline
+		}
+		scheduled, err := getScheduledTimes(schedulePolicy, policyType, lastTrigger.Time, now, deadline)
+		if err != nil {
+			return nil, err
+		}
+		if len(scheduled) == 0 {
+			return result, nil
 		}
-		return false, nil
 
-	case stork_api.SchedulePolicyTypeDaily:
-		if schedulePolicy.Policy.Daily == nil {
-			return false, nil
+		last := len(scheduled) - 1
+		switch options.CatchUpPolicy {
+		case stork_api.CatchUpPolicyRunAll:
+			result.ScheduledTime = scheduled[0]
+		case stork_api.CatchUpPolicyRunOnce:
+			result.ScheduledTime = scheduled[last]
+			result.Skipped = scheduled[:last]
+			result.SkipReason = fmt.Sprintf("Caught up by the run scheduled for %v", scheduled[last])
+		default:
+			// Only the latest run can be started if it is before the
+			// deadline. Runs that were deferred by blackout windows can be
+			// started until the deadline after the windows end.
+			deadlineStart, err := getDeferredUntil(schedulePolicy, scheduled[last])
+			if err != nil {
+				return nil, err
+			}
+			if now.Sub(deadlineStart) >= deadline {
+				result.Skipped = scheduled
+				result.SkipReason = fmt.Sprintf("Missed the starting deadline of %v", deadline)
+				return result, nil
+			}
+			result.ScheduledTime = scheduled[last]
+			result.Skipped = scheduled[:last]
+			result.SkipReason = fmt.Sprintf("Superseded by the run scheduled for %v", scheduled[last])
 		}
+	default:
+		return result, nil
+	}
 
-		policyHour, policyMinute, err := schedulePolicy.Policy.Daily.GetHourMinute()
+	window, windowStart, _, err := getActiveBlackoutWindow(schedulePolicy, now)
+	if err != nil {
+		return nil, err
+	}
+	if window != nil {
+		result.Deferred = true
+		result.DeferReason = fmt.Sprintf("In blackout window %v", window)
+		// Interval policies that were never triggered are due as soon as
+		// possible
+		if result.ScheduledTime.IsZero() {
+			result.ScheduledTime = windowStart
+		}
+		return result, nil
+	}
+
+	if policyType == stork_api.SchedulePolicyTypeInterval {
+		result.ScheduledTime = now
+	}
+	result.Trigger = true
+	return result, nil
+}
+
+// getScheduledTimes returns the times a policy was scheduled to run after the
+// last trigger, up to the current time. Only the most recent maxScheduledRuns
+// runs are returned. If the policy was never triggered only the runs that can
+// still be started within the deadline are returned.
+func getScheduledTimes(
+	policy *stork_api.SchedulePolicy,
+	policyType stork_api.SchedulePolicyType,
+	lastTrigger time.Time,
+	now time.Time,
+	deadline time.Duration,
+) ([]time.Time, error) {
+	from := lastTrigger
+	if from.IsZero() {
+		from = now.Add(-deadline)
+		// Runs in blackout windows that ended within the deadline can still
+		// be started
+		for i := 0; i < maxBlackoutWindowChain; i++ {
+			window, windowStart, _, err := getActiveBlackoutWindow(policy, from)
+			if err != nil {
+				return nil, err
+			}
+			if window == nil {
+				break
+			}
+			from = windowStart.Add(-time.Nanosecond)
+		}
+	}
+	from = from.In(now.Location())
+
+	// Walk back from the current time so that the latest runs are returned
+	// if there are too many of them
+	scheduled := make([]time.Time, 0)
+	to := now
+	for len(scheduled) < maxScheduledRuns {
+		previous, err := getPreviousScheduledTime(policy, policyType, to)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
+		if previous.IsZero() || !previous.After(from) {
+			break
+		}
+		scheduled = append(scheduled, previous)
+		to = previous.Add(-time.Nanosecond)
+	}
+	for i, j := 0, len(scheduled)-1; i < j; i, j = i+1, j-1 {
+		scheduled[i], scheduled[j] = scheduled[j], scheduled[i]
+	}
+	return scheduled, nil
+}
 
-		nextTrigger := time.Date(now.Year(), now.Month(), now.Day(), policyHour, policyMinute, 0, 0, time.Local)
+// getDeferredUntil returns the time from which the starting deadline of a run
+// is measured. This is the end of the blackout windows the run was scheduled
+// in, or the scheduled time if it wasn't in any.
+func getDeferredUntil(policy *stork_api.SchedulePolicy, scheduled time.Time) (time.Time, error) {
+	deferredUntil := scheduled
+	for i := 0; i < maxBlackoutWindowChain; i++ {
+		window, _, windowEnd, err := getActiveBlackoutWindow(policy, deferredUntil)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if window == nil {
+			break
+		}
+		deferredUntil = windowEnd
+	}
+	return deferredUntil, nil
+}
 
-		return checkTrigger(lastTrigger.Time, nextTrigger, now)
+// getPreviousScheduledTime returns the last time at or before the given time
+// when a Daily, Weekly, Monthly or Cron policy was scheduled to run, in the
+// location of the given time. Returns the zero time if the policy isn't
+// configured.
+func getPreviousScheduledTime(
+	policy *stork_api.SchedulePolicy,
+	policyType stork_api.SchedulePolicyType,
+	to time.Time,
+) (time.Time, error) {
+	loc := to.Location()
+	switch policyType {
+	case stork_api.SchedulePolicyTypeDaily:
+		if policy.Policy.Daily == nil {
+			return time.Time{}, nil
+		}
+		policyHour, policyMinute, err := policy.Policy.Daily.GetHourMinute()
+		if err != nil {
+			return time.Time{}, err
+		}
+		previous := time.Date(to.Year(), to.Month(), to.Day(), policyHour, policyMinute, 0, 0, loc)
+		if previous.After(to) {
+			previous = time.Date(to.Year(), to.Month(), to.Day()-1, policyHour, policyMinute, 0, 0, loc)
+		}
+		return previous, nil
+	case stork_api.SchedulePolicyTypeWeekly:
+		if policy.Policy.Weekly == nil {
+			return time.Time{}, nil
+		}
+		policyHour, policyMinute, err := policy.Policy.Weekly.GetHourMinute()
+		if err != nil {
+			return time.Time{}, err
+		}
+		scheduledDay := stork_api.Days[policy.Policy.Weekly.Day]
+		// Figure out how many days to go back to get to the previous
+		// trigger week day
+		days := int(to.Weekday()-scheduledDay+7) % 7
+		previous := time.Date(to.Year(), to.Month(), to.Day()-days, policyHour, policyMinute, 0, 0, loc)
+		if previous.After(to) {
+			previous = time.Date(to.Year(), to.Month(), to.Day()-days-7, policyHour, policyMinute, 0, 0, loc)
+		}
+		return previous, nil
+	case stork_api.SchedulePolicyTypeMonthly:
+		if policy.Policy.Monthly == nil {
+			return time.Time{}, nil
+		}
+		policyHour, policyMinute, err := policy.Policy.Monthly.GetHourMinute()
+		if err != nil {
+			return time.Time{}, err
+		}
+		// Dates that don't exist in a month rollover to the next month, so
+		// the trigger for this month could be after the given time
+		for months := 0; ; months-- {
+			previous := time.Date(to.Year(), to.Month()+time.Month(months), policy.Policy.Monthly.Date, policyHour, policyMinute, 0, 0, loc)
+			if !previous.After(to) {
+				return previous, nil
+			}
+		}
+	case stork_api.SchedulePolicyTypeCron:
+		if policy.Policy.Cron == nil {
+			return time.Time{}, nil
+		}
+		cronSchedule, err := ParseCronExpression(policy.Policy.Cron.Expression)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return cronSchedule.Previous(to), nil
+	}
+	return time.Time{}, nil
+}
 
+// getNextScheduledTime returns the first time after the given time when a
+// Daily, Weekly, Monthly or Cron policy is scheduled to run, in the location
+// of the given time. Returns the zero time if the policy isn't configured.
+func getNextScheduledTime(
+	policy *stork_api.SchedulePolicy,
+	policyType stork_api.SchedulePolicyType,
+	from time.Time,
+) (time.Time, error) {
+	loc := from.Location()
+	switch policyType {
+	case stork_api.SchedulePolicyTypeDaily:
+		if policy.Policy.Daily == nil {
+			return time.Time{}, nil
+		}
+		policyHour, policyMinute, err := policy.Policy.Daily.GetHourMinute()
+		if err != nil {
+			return time.Time{}, err
+		}
+		nextTrigger := time.Date(from.Year(), from.Month(), from.Day(), policyHour, policyMinute, 0, 0, loc)
+		if !nextTrigger.After(from) {
+			nextTrigger = time.Date(from.Year(), from.Month(), from.Day()+1, policyHour, policyMinute, 0, 0, loc)
+		}
+		return nextTrigger, nil
 	case stork_api.SchedulePolicyTypeWeekly:
-		if schedulePolicy.Policy.Weekly == nil {
-			return false, nil
+		if policy.Policy.Weekly == nil {
+			return time.Time{}, nil
 		}
-		currentDay := now.Weekday()
-		scheduledDay := stork_api.Days[schedulePolicy.Policy.Weekly.Day]
-		policyHour, policyMinute, err := schedulePolicy.Policy.Weekly.GetHourMinute()
+		policyHour, policyMinute, err := policy.Policy.Weekly.GetHourMinute()
 		if err != nil {
-			return false, err
+			return time.Time{}, err
 		}
-		nextTrigger := time.Date(now.Year(), now.Month(), now.Day(), policyHour, policyMinute, 0, 0, time.Local)
+		scheduledDay := stork_api.Days[policy.Policy.Weekly.Day]
 		// Figure out how many days to add to get to the next
 		// trigger week day
-		if currentDay < scheduledDay {
-			nextTrigger = nextTrigger.Add(time.Hour * time.Duration((scheduledDay-currentDay)*24))
-		} else if currentDay > scheduledDay {
-			nextTrigger = nextTrigger.Add(time.Duration((7-(currentDay-scheduledDay))*24) * time.Hour)
+		days := int(scheduledDay-from.Weekday()+7) % 7
+		nextTrigger := time.Date(from.Year(), from.Month(), from.Day()+days, policyHour, policyMinute, 0, 0, loc)
+		if !nextTrigger.After(from) {
+			nextTrigger = time.Date(from.Year(), from.Month(), from.Day()+days+7, policyHour, policyMinute, 0, 0, loc)
 		}
-
-		return checkTrigger(lastTrigger.Time, nextTrigger, now)
+		return nextTrigger, nil
 	case stork_api.SchedulePolicyTypeMonthly:
-		if schedulePolicy.Policy.Monthly == nil {
-			return false, nil
+		if policy.Policy.Monthly == nil {
+			return time.Time{}, nil
+		}
+		policyHour, policyMinute, err := policy.Policy.Monthly.GetHourMinute()
+		if err != nil {
+			return time.Time{}, err
 		}
-		policyHour, policyMinute, err := schedulePolicy.Policy.Monthly.GetHourMinute()
+		// Dates that don't exist in a month rollover to the next month, so
+		// the trigger for the previous month could be in this month
+		for months := -1; ; months++ {
+			nextTrigger := time.Date(from.Year(), from.Month()+time.Month(months), policy.Policy.Monthly.Date, policyHour, policyMinute, 0, 0, loc)
+			if nextTrigger.After(from) {
+				return nextTrigger, nil
+			}
+		}
+	case stork_api.SchedulePolicyTypeCron:
+		if policy.Policy.Cron == nil {
+			return time.Time{}, nil
+		}
+		cronSchedule, err := ParseCronExpression(policy.Policy.Cron.Expression)
 		if err != nil {
-			return false, err
+			return time.Time{}, err
 		}
-		nextTrigger := time.Date(now.Year(), now.Month(), schedulePolicy.Policy.Monthly.Date, policyHour, policyMinute, 0, 0, time.Local)
+		return cronSchedule.Next(from), nil
+	}
+	return time.Time{}, nil
+}
 
-		return checkTrigger(lastTrigger.Time, nextTrigger, now)
+// getActiveBlackoutWindow returns the blackout window of the policy that the
+// given time is in and when it starts and ends, nil if it isn't in any of them
+func getActiveBlackoutWindow(policy *stork_api.SchedulePolicy, now time.Time) (*stork_api.BlackoutWindow, time.Time, time.Time, error) {
+	loc := now.Location()
+	for i := range policy.Policy.BlackoutWindows {
+		window := &policy.Policy.BlackoutWindows[i]
+		startHour, startMinute, err := window.GetStartHourMinute()
+		if err != nil {
+			return nil, time.Time{}, time.Time{}, err
+		}
+		endHour, endMinute, err := window.GetEndHourMinute()
+		if err != nil {
+			return nil, time.Time{}, time.Time{}, err
+		}
+		// Check the windows starting today and yesterday since they can end
+		// on the next day
+		for _, days := range []int{0, -1} {
+			start := time.Date(now.Year(), now.Month(), now.Day()+days, startHour, startMinute, 0, 0, loc)
+			if len(window.Days) > 0 && !isDayInList(start.Weekday(), window.Days) {
+				continue
+			}
+			end := time.Date(start.Year(), start.Month(), start.Day(), endHour, endMinute, 0, 0, loc)
+			if !end.After(start) {
+				end = time.Date(start.Year(), start.Month(), start.Day()+1, endHour, endMinute, 0, 0, loc)
+			}
+			if !now.Before(start) && now.Before(end) {
+				return window, start, end, nil
+			}
+		}
 	}
-	return false, nil
+	return nil, time.Time{}, time.Time{}, nil
 }
 
-func checkTrigger(
-	lastTrigger time.Time,
-	nextTrigger time.Time,
-	now time.Time,
-) (bool, error) {
-	// If we had triggered after the scheduled time this month, don't
-	// triggered again
-	if lastTrigger.After(nextTrigger) || lastTrigger.Equal(nextTrigger) {
-		return false, nil
+func isDayInList(day time.Weekday, days []string) bool {
+	for _, d := range days {
+		if stork_api.Days[d] == day {
+			return true
+		}
 	}
+	return false
+}
 
-	// If we are within one hour after/at the next trigger time, trigger a new
-	// schedule
-	if now.Equal(nextTrigger) || (now.After(nextTrigger) && now.Sub(nextTrigger).Hours() < 1) {
-		return true, nil
+// UpdateMissedRuns records the runs that were skipped or deferred in the
+// result in the list of missed runs of a schedule. Runs that were already
+// recorded are ignored. Once there are more than maxMissedRuns runs, the
+// oldest runs of the policy type that were scheduled before its last trigger
+// are removed. Later runs are kept so that they aren't recorded again.
+// Returns the updated list and the runs that were added to it.
+func UpdateMissedRuns(
+	missedRuns []*stork_api.MissedScheduleRun,
+	policyType stork_api.SchedulePolicyType,
+	lastTrigger meta.Time,
+	result *TriggerResult,
+) ([]*stork_api.MissedScheduleRun, []*stork_api.MissedScheduleRun) {
+	added := make([]*stork_api.MissedScheduleRun, 0)
+	add := func(scheduledTime time.Time, action stork_api.MissedScheduleRunAction, reason string) {
+		for _, run := range missedRuns {
+			if run.PolicyType == policyType && run.Action == action && run.ScheduledTimestamp.Time.Equal(scheduledTime) {
+				return
+			}
+		}
+		run := &stork_api.MissedScheduleRun{
+			PolicyType:         policyType,
+			ScheduledTimestamp: meta.NewTime(scheduledTime),
+			Action:             action,
+			Reason:             reason,
+		}
+		missedRuns = append(missedRuns, run)
+		added = append(added, run)
 	}
-	return false, nil
+
+	for _, skipped := range result.Skipped {
+		add(skipped, stork_api.MissedScheduleRunSkipped, result.SkipReason)
+	}
+	if result.Deferred {
+		add(result.ScheduledTime, stork_api.MissedScheduleRunDeferred, result.DeferReason)
+	}
+	if excess := len(missedRuns) - maxMissedRuns; excess > 0 {
+		trimmed := make([]*stork_api.MissedScheduleRun, 0, len(missedRuns))
+		for _, run := range missedRuns {
+			if excess > 0 && run.PolicyType == policyType && run.ScheduledTimestamp.Before(&lastTrigger) {
+				excess--
+				continue
+			}
+			trimmed = append(trimmed, run)
+		}
+		missedRuns = trimmed
+	}
+	return missedRuns, added
+}
+
+// GetLastSkippedTime returns the latest scheduled time of the runs for a
+// policy type that were skipped. Runs before it shouldn't be triggered again.
+func GetLastSkippedTime(
+	missedRuns []*stork_api.MissedScheduleRun,
+	policyType stork_api.SchedulePolicyType,
+) meta.Time {
+	var lastSkipped meta.Time
+	for _, run := range missedRuns {
+		if run.PolicyType == policyType &&
+			run.Action == stork_api.MissedScheduleRunSkipped &&
+			lastSkipped.Before(&run.ScheduledTimestamp) {
+			lastSkipped = run.ScheduledTimestamp
+		}
+	}
+	return lastSkipped
+}
+
+// NextTriggerTime returns the next time after the given time when the Daily,
+// Weekly, Monthly or Cron policies in the schedule policy will be triggered.
+// Interval policies depend on the last trigger and aren't included. Returns
+// the zero time if none of those policies are configured.
+func NextTriggerTime(policy *stork_api.SchedulePolicy, from time.Time) (time.Time, error) {
+	if err := ValidateSchedulePolicy(policy); err != nil {
+		return time.Time{}, err
+	}
+	loc, err := policy.Policy.GetLocation()
+	if err != nil {
+		return time.Time{}, err
+	}
+	from = from.In(loc)
+
+	var next time.Time
+	for _, policyType := range stork_api.GetValidSchedulePolicyTypes() {
+		trigger, err := getNextScheduledTime(policy, policyType, from)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if !trigger.IsZero() && (next.IsZero() || trigger.Before(next)) {
+			next = trigger
+		}
+	}
+	return next, nil
 }
 
 // ValidateSchedulePolicy Validate if a given schedule policy is valid
@@ -174,6 +598,24 @@ func ValidateSchedulePolicy(policy *stork_api.SchedulePolicy) error {
 			return err
 		}
 	}
+	if policy.Policy.Cron != nil {
+		if _, err := ParseCronExpression(policy.Policy.Cron.Expression); err != nil {
+			return fmt.Errorf("Invalid expression (%v) in Cron policy: %v", policy.Policy.Cron.Expression, err)
+		}
+	}
+	if _, err := policy.Policy.GetLocation(); err != nil {
+		return fmt.Errorf("Invalid timezone (%v) in schedule policy: %v", policy.Policy.Timezone, err)
+	}
+	for _, window := range policy.Policy.BlackoutWindows {
+		if err := window.Validate(); err != nil {
+			return err
+		}
+	}
+	if policy.Policy.Retention != nil {
+		if err := policy.Policy.Retention.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -213,6 +655,13 @@ func GetRetain(policyName string, namespace string, policyType stork_api.Schedul
 			}
 			return schedulePolicy.Policy.Monthly.Retain, nil
 		}
+	case stork_api.SchedulePolicyTypeCron:
+		if schedulePolicy.Policy.Cron != nil {
+			if schedulePolicy.Policy.Cron.Retain == 0 {
+				return stork_api.DefaultCronPolicyRetain, nil
+			}
+			return schedulePolicy.Policy.Cron.Retain, nil
+		}
 	default:
 		return 0, fmt.Errorf("invalid policy type: %v", policyType)
 	}
@@ -248,6 +697,8 @@ func GetOptions(policyName string, namespace string, policyType stork_api.Schedu
 		return schedulePolicy.Policy.Weekly.Options, nil
 	case stork_api.SchedulePolicyTypeMonthly:
 		return schedulePolicy.Policy.Monthly.Options, nil
+	case stork_api.SchedulePolicyTypeCron:
+		return schedulePolicy.Policy.Cron.Options, nil
 	default:
 		return nil, fmt.Errorf("invalid policy type: %v", policyType)
 	}
@@ -34,6 +34,12 @@ func TestSchedule(t *testing.T) {
 	t.Run("triggerDailyRequiredTest", triggerDailyRequiredTest)
 	t.Run("triggerWeeklyRequiredTest", triggerWeeklyRequiredTest)
 	t.Run("triggerMonthlyRequiredTest", triggerMonthlyRequiredTest)
+	t.Run("triggerCronRequiredTest", triggerCronRequiredTest)
+	t.Run("triggerTimezoneRequiredTest", triggerTimezoneRequiredTest)
+	t.Run("triggerCatchUpTest", triggerCatchUpTest)
+	t.Run("triggerBlackoutWindowTest", triggerBlackoutWindowTest)
+	t.Run("missedRunsTest", missedRunsTest)
+	t.Run("nextTriggerTimeTest", nextTriggerTimeTest)
 	t.Run("validateSchedulePolicyTest", validateSchedulePolicyTest)
 	t.Run("policyRetainTest", policyRetainTest)
 	t.Run("policyOptionsTest", policyOptionsTest)
@@ -228,6 +234,385 @@ func triggerMonthlyRequiredTest(t *testing.T) {
 	require.False(t, required, "Trigger should not have been required")
 }
 
+func triggerCronRequiredTest(t *testing.T) {
+	defer func() {
+		err := storkops.Instance().DeleteSchedulePolicy("cronpolicy")
+		require.NoError(t, err, "Error cleaning up schedule policy")
+	}()
+
+	_, err := storkops.Instance().CreateSchedulePolicy(&stork_api.SchedulePolicy{
+		ObjectMeta: meta.ObjectMeta{
+			Name: "cronpolicy",
+		},
+		Policy: stork_api.SchedulePolicyItem{
+			Cron: &stork_api.CronPolicy{
+				// Every weekday at 2:30AM
+				Expression: "30 2 * * 1-5",
+			},
+		},
+	})
+	require.NoError(t, err, "Error creating policy")
+
+	// Friday
+	mockNow := time.Date(2019, time.February, 8, 2, 31, 0, 0, time.Local)
+	setMockTime(&mockNow)
+	// Never triggered
+	required, err := TriggerRequired("cronpolicy", "default", stork_api.SchedulePolicyTypeCron, meta.Time{})
+	require.NoError(t, err, "Error checking if trigger required")
+	require.True(t, required, "Trigger should have been required")
+
+	// Last triggered before schedule
+	required, err = TriggerRequired("cronpolicy", "default", stork_api.SchedulePolicyTypeCron, meta.Date(2019, time.February, 7, 2, 30, 0, 0, time.Local))
+	require.NoError(t, err, "Error checking if trigger required")
+	require.True(t, required, "Trigger should have been required")
+
+	// Last triggered at schedule
+	required, err = TriggerRequired("cronpolicy", "default", stork_api.SchedulePolicyTypeCron, meta.Date(2019, time.February, 8, 2, 30, 0, 0, time.Local))
+	require.NoError(t, err, "Error checking if trigger required")
+	require.False(t, required, "Trigger should not have been required")
+
+	// More than an hour after the schedule
+	mockNow = time.Date(2019, time.February, 8, 3, 31, 0, 0, time.Local)
+	setMockTime(&mockNow)
+	required, err = TriggerRequired("cronpolicy", "default", stork_api.SchedulePolicyTypeCron, meta.Date(2019, time.February, 7, 2, 30, 0, 0, time.Local))
+	require.NoError(t, err, "Error checking if trigger required")
+	require.False(t, required, "Trigger should not have been required")
+
+	// Saturday
+	mockNow = time.Date(2019, time.February, 9, 2, 31, 0, 0, time.Local)
+	setMockTime(&mockNow)
+	required, err = TriggerRequired("cronpolicy", "default", stork_api.SchedulePolicyTypeCron, meta.Date(2019, time.February, 8, 2, 30, 0, 0, time.Local))
+	require.NoError(t, err, "Error checking if trigger required")
+	require.False(t, required, "Trigger should not have been required")
+
+	// Other policy types shouldn't be triggered
+	required, err = TriggerRequired("cronpolicy", "default", stork_api.SchedulePolicyTypeDaily, meta.Time{})
+	require.NoError(t, err, "Error checking if trigger required")
+	require.False(t, required, "Trigger should not have been required")
+}
+
+func triggerTimezoneRequiredTest(t *testing.T) {
+	defer func() {
+		err := storkops.Instance().DeleteSchedulePolicy("timezonepolicy")
+		require.NoError(t, err, "Error cleaning up schedule policy")
+	}()
+
+	_, err := storkops.Instance().CreateSchedulePolicy(&stork_api.SchedulePolicy{
+		ObjectMeta: meta.ObjectMeta{
+			Name: "timezonepolicy",
+		},
+		Policy: stork_api.SchedulePolicyItem{
+			Daily: &stork_api.DailyPolicy{
+				Time: "02:30AM",
+			},
+			Cron: &stork_api.CronPolicy{
+				Expression: "0 9 * * *",
+			},
+			Timezone: "Asia/Tokyo",
+		},
+	})
+	require.NoError(t, err, "Error creating policy")
+
+	// 2:31AM in Tokyo
+	mockNow := time.Date(2019, time.February, 7, 17, 31, 0, 0, time.UTC)
+	setMockTime(&mockNow)
+	required, err := TriggerRequired("timezonepolicy", "default", stork_api.SchedulePolicyTypeDaily, meta.Date(2019, time.February, 6, 17, 30, 0, 0, time.UTC))
+	require.NoError(t, err, "Error checking if trigger required")
+	require.True(t, required, "Trigger should have been required")
+	required, err = TriggerRequired("timezonepolicy", "default", stork_api.SchedulePolicyTypeCron, meta.Date(2019, time.February, 7, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err, "Error checking if trigger required")
+	require.False(t, required, "Trigger should not have been required")
+
+	// 9:01AM in Tokyo
+	mockNow = time.Date(2019, time.February, 8, 0, 1, 0, 0, time.UTC)
+	setMockTime(&mockNow)
+	required, err = TriggerRequired("timezonepolicy", "default", stork_api.SchedulePolicyTypeCron, meta.Date(2019, time.February, 7, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err, "Error checking if trigger required")
+	require.True(t, required, "Trigger should have been required")
+
+	_, err = TriggerRequired("missingpolicy", "default", stork_api.SchedulePolicyTypeCron, meta.Time{})
+	require.Error(t, err, "Should return error for missing policy")
+}
+
+func triggerCatchUpTest(t *testing.T) {
+	defer func() {
+		err := storkops.Instance().DeleteSchedulePolicy("catchuppolicy")
+		require.NoError(t, err, "Error cleaning up schedule policy")
+	}()
+
+	_, err := storkops.Instance().CreateSchedulePolicy(&stork_api.SchedulePolicy{
+		ObjectMeta: meta.ObjectMeta{
+			Name: "catchuppolicy",
+		},
+		Policy: stork_api.SchedulePolicyItem{
+			Daily: &stork_api.DailyPolicy{
+				Time: "02:30AM",
+			},
+		},
+	})
+	require.NoError(t, err, "Error creating policy")
+
+	// Last triggered 3 days ago and 2 hours after the schedule
+	lastTrigger := meta.Date(2019, time.February, 4, 2, 30, 0, 0, time.Local)
+	mockNow := time.Date(2019, time.February, 7, 4, 30, 0, 0, time.Local)
+	setMockTime(&mockNow)
+
+	// Skip all the runs since the latest one missed the default deadline
+	result, err := CheckTrigger("catchuppolicy", "default", stork_api.SchedulePolicyTypeDaily, lastTrigger, TriggerOptions{})
+	require.NoError(t, err, "Error checking trigger")
+	require.False(t, result.Trigger, "Trigger should not have been required")
+	require.Len(t, result.Skipped, 3, "All runs should have been skipped")
+
+	// Start the latest run with a longer deadline
+	deadline := int64(3 * 60 * 60)
+	options := GetTriggerOptions(&deadline, stork_api.CatchUpPolicySkip)
+	result, err = CheckTrigger("catchuppolicy", "default", stork_api.SchedulePolicyTypeDaily, lastTrigger, options)
+	require.NoError(t, err, "Error checking trigger")
+	require.True(t, result.Trigger, "Trigger should have been required")
+	require.Equal(t, time.Date(2019, time.February, 7, 2, 30, 0, 0, time.Local), result.ScheduledTime)
+	require.Len(t, result.Skipped, 2, "Earlier runs should have been skipped")
+
+	// Start one run for all the missed runs
+	options = GetTriggerOptions(nil, stork_api.CatchUpPolicyRunOnce)
+	result, err = CheckTrigger("catchuppolicy", "default", stork_api.SchedulePolicyTypeDaily, lastTrigger, options)
+	require.NoError(t, err, "Error checking trigger")
+	require.True(t, result.Trigger, "Trigger should have been required")
+	require.Equal(t, time.Date(2019, time.February, 7, 2, 30, 0, 0, time.Local), result.ScheduledTime)
+	require.Len(t, result.Skipped, 2, "Earlier runs should have been skipped")
+
+	// Start each of the missed runs, the earliest one first
+	options = GetTriggerOptions(nil, stork_api.CatchUpPolicyRunAll)
+	result, err = CheckTrigger("catchuppolicy", "default", stork_api.SchedulePolicyTypeDaily, lastTrigger, options)
+	require.NoError(t, err, "Error checking trigger")
+	require.True(t, result.Trigger, "Trigger should have been required")
+	require.Equal(t, time.Date(2019, time.February, 5, 2, 30, 0, 0, time.Local), result.ScheduledTime)
+	require.Empty(t, result.Skipped, "No runs should have been skipped")
+
+	result, err = CheckTrigger("catchuppolicy", "default", stork_api.SchedulePolicyTypeDaily, meta.NewTime(result.ScheduledTime), options)
+	require.NoError(t, err, "Error checking trigger")
+	require.True(t, result.Trigger, "Trigger should have been required")
+	require.Equal(t, time.Date(2019, time.February, 6, 2, 30, 0, 0, time.Local), result.ScheduledTime)
+
+	// Only the most recent runs are checked if there are too many of them,
+	// so the latest run is still started
+	_, err = storkops.Instance().CreateSchedulePolicy(&stork_api.SchedulePolicy{
+		ObjectMeta: meta.ObjectMeta{
+			Name: "catchupcronpolicy",
+		},
+		Policy: stork_api.SchedulePolicyItem{
+			Cron: &stork_api.CronPolicy{
+				Expression: "* * * * *",
+			},
+		},
+	})
+	require.NoError(t, err, "Error creating policy")
+	defer func() {
+		err := storkops.Instance().DeleteSchedulePolicy("catchupcronpolicy")
+		require.NoError(t, err, "Error cleaning up schedule policy")
+	}()
+	result, err = CheckTrigger("catchupcronpolicy", "default", stork_api.SchedulePolicyTypeCron, meta.NewTime(mockNow.Add(-3*maxScheduledRuns*time.Minute)), TriggerOptions{})
+	require.NoError(t, err, "Error checking trigger")
+	require.True(t, result.Trigger, "Trigger should have been required")
+	require.Equal(t, mockNow, result.ScheduledTime)
+	require.Len(t, result.Skipped, maxScheduledRuns-1, "Earlier runs should have been skipped")
+	require.Equal(t, mockNow.Add(-(maxScheduledRuns-1)*time.Minute), result.Skipped[0])
+}
+
+func triggerBlackoutWindowTest(t *testing.T) {
+	defer func() {
+		err := storkops.Instance().DeleteSchedulePolicy("blackoutpolicy")
+		require.NoError(t, err, "Error cleaning up schedule policy")
+	}()
+
+	_, err := storkops.Instance().CreateSchedulePolicy(&stork_api.SchedulePolicy{
+		ObjectMeta: meta.ObjectMeta{
+			Name: "blackoutpolicy",
+		},
+		Policy: stork_api.SchedulePolicyItem{
+			Interval: &stork_api.IntervalPolicy{
+				IntervalMinutes: 60,
+			},
+			Daily: &stork_api.DailyPolicy{
+				Time: "11:30PM",
+			},
+			BlackoutWindows: []stork_api.BlackoutWindow{
+				{
+					// Thursday night to Friday morning
+					Days:      []string{"Thursday"},
+					StartTime: "11:00PM",
+					EndTime:   "02:00AM",
+				},
+			},
+		},
+	})
+	require.NoError(t, err, "Error creating policy")
+
+	// Thursday, in the blackout window
+	mockNow := time.Date(2019, time.February, 7, 23, 31, 0, 0, time.Local)
+	setMockTime(&mockNow)
+	result, err := CheckTrigger("blackoutpolicy", "default", stork_api.SchedulePolicyTypeDaily, meta.Date(2019, time.February, 6, 23, 30, 0, 0, time.Local), TriggerOptions{})
+	require.NoError(t, err, "Error checking trigger")
+	require.False(t, result.Trigger, "Trigger should not have been required")
+	require.True(t, result.Deferred, "Run should have been deferred")
+	require.Equal(t, time.Date(2019, time.February, 7, 23, 30, 0, 0, time.Local), result.ScheduledTime)
+
+	// Never triggered interval policy is deferred to the start of the window
+	result, err = CheckTrigger("blackoutpolicy", "default", stork_api.SchedulePolicyTypeInterval, meta.Time{}, TriggerOptions{})
+	require.NoError(t, err, "Error checking trigger")
+	require.True(t, result.Deferred, "Run should have been deferred")
+	require.Equal(t, time.Date(2019, time.February, 7, 23, 0, 0, 0, time.Local), result.ScheduledTime)
+
+	// Friday morning, still in the window that started on Thursday
+	mockNow = time.Date(2019, time.February, 8, 1, 59, 0, 0, time.Local)
+	setMockTime(&mockNow)
+	result, err = CheckTrigger("blackoutpolicy", "default", stork_api.SchedulePolicyTypeInterval, meta.Date(2019, time.February, 7, 22, 0, 0, 0, time.Local), TriggerOptions{})
+	require.NoError(t, err, "Error checking trigger")
+	require.False(t, result.Trigger, "Trigger should not have been required")
+	require.True(t, result.Deferred, "Run should have been deferred")
+
+	// After the window the deferred run is started. The default deadline is
+	// measured from the end of the window.
+	mockNow = time.Date(2019, time.February, 8, 2, 1, 0, 0, time.Local)
+	setMockTime(&mockNow)
+	result, err = CheckTrigger("blackoutpolicy", "default", stork_api.SchedulePolicyTypeDaily, meta.Date(2019, time.February, 6, 23, 30, 0, 0, time.Local), TriggerOptions{})
+	require.NoError(t, err, "Error checking trigger")
+	require.True(t, result.Trigger, "Trigger should have been required")
+	require.False(t, result.Deferred, "Run should not have been deferred")
+	require.Equal(t, time.Date(2019, time.February, 7, 23, 30, 0, 0, time.Local), result.ScheduledTime)
+
+	// Schedules that were never triggered also start the deferred run
+	result, err = CheckTrigger("blackoutpolicy", "default", stork_api.SchedulePolicyTypeDaily, meta.Time{}, TriggerOptions{})
+	require.NoError(t, err, "Error checking trigger")
+	require.True(t, result.Trigger, "Trigger should have been required")
+	require.Equal(t, time.Date(2019, time.February, 7, 23, 30, 0, 0, time.Local), result.ScheduledTime)
+
+	// The deferred run is skipped once the deadline after the window passes
+	mockNow = time.Date(2019, time.February, 8, 3, 0, 0, 0, time.Local)
+	setMockTime(&mockNow)
+	result, err = CheckTrigger("blackoutpolicy", "default", stork_api.SchedulePolicyTypeDaily, meta.Date(2019, time.February, 6, 23, 30, 0, 0, time.Local), TriggerOptions{})
+	require.NoError(t, err, "Error checking trigger")
+	require.False(t, result.Trigger, "Trigger should not have been required")
+	require.Len(t, result.Skipped, 1, "Deferred run should have been skipped")
+
+	// Friday night isn't in the window
+	mockNow = time.Date(2019, time.February, 8, 23, 31, 0, 0, time.Local)
+	setMockTime(&mockNow)
+	result, err = CheckTrigger("blackoutpolicy", "default", stork_api.SchedulePolicyTypeDaily, meta.Date(2019, time.February, 7, 23, 30, 0, 0, time.Local), TriggerOptions{})
+	require.NoError(t, err, "Error checking trigger")
+	require.True(t, result.Trigger, "Trigger should have been required")
+	require.False(t, result.Deferred, "Run should not have been deferred")
+}
+
+func missedRunsTest(t *testing.T) {
+	scheduled := time.Date(2019, time.February, 7, 2, 30, 0, 0, time.Local)
+	result := &TriggerResult{
+		Skipped: []time.Time{
+			scheduled.Add(-48 * time.Hour),
+			scheduled.Add(-24 * time.Hour),
+		},
+		SkipReason: "Superseded",
+	}
+	missedRuns, added := UpdateMissedRuns(nil, stork_api.SchedulePolicyTypeDaily, meta.Time{}, result)
+	require.Len(t, missedRuns, 2, "Skipped runs should have been recorded")
+	require.Len(t, added, 2, "Skipped runs should have been added")
+
+	// Runs that were already recorded shouldn't be added again
+	result.Deferred = true
+	result.ScheduledTime = scheduled
+	result.DeferReason = "In blackout window"
+	missedRuns, added = UpdateMissedRuns(missedRuns, stork_api.SchedulePolicyTypeDaily, meta.Time{}, result)
+	require.Len(t, missedRuns, 3, "Deferred run should have been recorded")
+	require.Len(t, added, 1, "Only the deferred run should have been added")
+	require.Equal(t, stork_api.MissedScheduleRunDeferred, added[0].Action)
+
+	missedRuns, added = UpdateMissedRuns(missedRuns, stork_api.SchedulePolicyTypeDaily, meta.Time{}, result)
+	require.Len(t, missedRuns, 3, "No runs should have been recorded")
+	require.Empty(t, added, "No runs should have been added")
+
+	lastSkipped := GetLastSkippedTime(missedRuns, stork_api.SchedulePolicyTypeDaily)
+	require.True(t, lastSkipped.Time.Equal(scheduled.Add(-24*time.Hour)), "Deferred runs shouldn't be returned as skipped")
+	lastSkipped = GetLastSkippedTime(missedRuns, stork_api.SchedulePolicyTypeWeekly)
+	require.True(t, lastSkipped.IsZero(), "Skipped runs for other policy types shouldn't be returned")
+
+	// Only the most recent runs are kept
+	var lastTrigger meta.Time
+	for i := 0; i < 2*maxMissedRuns; i++ {
+		result = &TriggerResult{
+			Skipped:    []time.Time{scheduled.Add(time.Duration(i) * time.Hour)},
+			SkipReason: "Missed the starting deadline",
+		}
+		missedRuns, _ = UpdateMissedRuns(missedRuns, stork_api.SchedulePolicyTypeInterval, lastTrigger, result)
+		lastTrigger = GetLastSkippedTime(missedRuns, stork_api.SchedulePolicyTypeInterval)
+	}
+	require.Len(t, missedRuns, maxMissedRuns, "Only the most recent runs should have been kept")
+	require.True(t, missedRuns[maxMissedRuns-1].ScheduledTimestamp.Time.Equal(scheduled.Add(time.Duration(2*maxMissedRuns-1)*time.Hour)))
+	require.True(t, lastTrigger.Time.Equal(scheduled.Add(time.Duration(2*maxMissedRuns-1)*time.Hour)), "Last skipped run should have been kept")
+
+	// Runs after the last trigger aren't removed, otherwise they would be
+	// recorded again the next time they are checked
+	result = &TriggerResult{
+		SkipReason: "Superseded",
+	}
+	for i := 0; i < maxMissedRuns; i++ {
+		result.Skipped = append(result.Skipped, scheduled.Add(time.Duration(i)*time.Minute))
+	}
+	missedRuns, added = UpdateMissedRuns(nil, stork_api.SchedulePolicyTypeCron, meta.Time{}, result)
+	require.Len(t, added, maxMissedRuns, "Skipped runs should have been added")
+	result.Skipped = append(result.Skipped, scheduled.Add(time.Duration(maxMissedRuns)*time.Minute))
+	missedRuns, added = UpdateMissedRuns(missedRuns, stork_api.SchedulePolicyTypeCron, meta.NewTime(scheduled.Add(-time.Minute)), result)
+	require.Len(t, added, 1, "Only the new skipped run should have been added")
+	require.Len(t, missedRuns, maxMissedRuns+1, "Runs after the last trigger should have been kept")
+	missedRuns, added = UpdateMissedRuns(missedRuns, stork_api.SchedulePolicyTypeCron, meta.NewTime(scheduled.Add(-time.Minute)), result)
+	require.Empty(t, added, "No runs should have been added again")
+	missedRuns, _ = UpdateMissedRuns(missedRuns, stork_api.SchedulePolicyTypeCron, GetLastSkippedTime(missedRuns, stork_api.SchedulePolicyTypeCron), &TriggerResult{})
+	require.Len(t, missedRuns, maxMissedRuns, "Runs before the last trigger should have been removed")
+}
+
+func nextTriggerTimeTest(t *testing.T) {
+	// Thursday
+	now := time.Date(2019, time.February, 7, 23, 16, 0, 0, time.UTC)
+	policy := &stork_api.SchedulePolicy{
+		Policy: stork_api.SchedulePolicyItem{
+			Interval: &stork_api.IntervalPolicy{
+				IntervalMinutes: 60,
+			},
+			Timezone: "UTC",
+		},
+	}
+	next, err := NextTriggerTime(policy, now)
+	require.NoError(t, err, "Error getting next trigger time")
+	require.True(t, next.IsZero(), "Interval policies shouldn't have a next trigger time")
+
+	policy.Policy.Monthly = &stork_api.MonthlyPolicy{Date: 7, Time: "11:15PM"}
+	next, err = NextTriggerTime(policy, now)
+	require.NoError(t, err, "Error getting next trigger time")
+	require.Equal(t, time.Date(2019, time.March, 7, 23, 15, 0, 0, time.UTC), next, "Wrong next trigger time")
+
+	policy.Policy.Weekly = &stork_api.WeeklyPolicy{Day: "Mon", Time: "11:15PM"}
+	next, err = NextTriggerTime(policy, now)
+	require.NoError(t, err, "Error getting next trigger time")
+	require.Equal(t, time.Date(2019, time.February, 11, 23, 15, 0, 0, time.UTC), next, "Wrong next trigger time")
+
+	policy.Policy.Cron = &stork_api.CronPolicy{Expression: "0 12 * * SUN#2"}
+	next, err = NextTriggerTime(policy, now)
+	require.NoError(t, err, "Error getting next trigger time")
+	require.Equal(t, time.Date(2019, time.February, 10, 12, 0, 0, 0, time.UTC), next, "Wrong next trigger time")
+
+	policy.Policy.Daily = &stork_api.DailyPolicy{Time: "11:15PM"}
+	next, err = NextTriggerTime(policy, now)
+	require.NoError(t, err, "Error getting next trigger time")
+	require.Equal(t, time.Date(2019, time.February, 8, 23, 15, 0, 0, time.UTC), next, "Wrong next trigger time")
+
+	policy.Policy.Timezone = "America/New_York"
+	next, err = NextTriggerTime(policy, now)
+	require.NoError(t, err, "Error getting next trigger time")
+	require.Equal(t, time.Date(2019, time.February, 8, 4, 15, 0, 0, time.UTC), next.UTC(), "Wrong next trigger time")
+
+	policy.Policy.Timezone = "America/Nowhere"
+	_, err = NextTriggerTime(policy, now)
+	require.Error(t, err, "Should return error for invalid timezone")
+}
+
 func validateSchedulePolicyTest(t *testing.T) {
 	policy := &stork_api.SchedulePolicy{
 		ObjectMeta: meta.ObjectMeta{
@@ -245,11 +630,88 @@ func validateSchedulePolicyTest(t *testing.T) {
 				Date: 15,
 				Time: "12:15pm",
 			},
+			Cron: &stork_api.CronPolicy{
+				Expression: "30 2 * * MON-FRI",
+			},
+			Timezone: "Europe/Berlin",
+			BlackoutWindows: []stork_api.BlackoutWindow{
+				{
+					Days:      []string{"Sat", "Sunday"},
+					StartTime: "10:00PM",
+					EndTime:   "04:00AM",
+				},
+			},
 		},
 	}
 	err := ValidateSchedulePolicy(policy)
 	require.NoError(t, err, "Valid policy shouldn't return error")
 
+	policy = &stork_api.SchedulePolicy{
+		ObjectMeta: meta.ObjectMeta{
+			Name: "invalidcronpolicy",
+		},
+		Policy: stork_api.SchedulePolicyItem{
+			Cron: &stork_api.CronPolicy{
+				Expression: "30 2 * *",
+			},
+		},
+	}
+	err = ValidateSchedulePolicy(policy)
+	require.Error(t, err, "Invalid cron policy should return error")
+
+	policy = &stork_api.SchedulePolicy{
+		ObjectMeta: meta.ObjectMeta{
+			Name: "invalidtimezonepolicy",
+		},
+		Policy: stork_api.SchedulePolicyItem{
+			Daily: &stork_api.DailyPolicy{
+				Time: "01:15am",
+			},
+			Timezone: "Europe/Nowhere",
+		},
+	}
+	err = ValidateSchedulePolicy(policy)
+	require.Error(t, err, "Invalid timezone should return error")
+
+	policy = &stork_api.SchedulePolicy{
+		ObjectMeta: meta.ObjectMeta{
+			Name: "invalidblackoutpolicy",
+		},
+		Policy: stork_api.SchedulePolicyItem{
+			Daily: &stork_api.DailyPolicy{
+				Time: "01:15am",
+			},
+			BlackoutWindows: []stork_api.BlackoutWindow{
+				{
+					StartTime: "25:00PM",
+					EndTime:   "04:00AM",
+				},
+			},
+		},
+	}
+	err = ValidateSchedulePolicy(policy)
+	require.Error(t, err, "Invalid blackout window time should return error")
+
+	policy = &stork_api.SchedulePolicy{
+		ObjectMeta: meta.ObjectMeta{
+			Name: "invalidblackoutpolicy",
+		},
+		Policy: stork_api.SchedulePolicyItem{
+			Daily: &stork_api.DailyPolicy{
+				Time: "01:15am",
+			},
+			BlackoutWindows: []stork_api.BlackoutWindow{
+				{
+					Days:      []string{"Funday"},
+					StartTime: "10:00PM",
+					EndTime:   "04:00AM",
+				},
+			},
+		},
+	}
+	err = ValidateSchedulePolicy(policy)
+	require.Error(t, err, "Invalid blackout window day should return error")
+
 	policy = &stork_api.SchedulePolicy{
 		ObjectMeta: meta.ObjectMeta{
 			Name: "invalidintervalpolicy",
@@ -118,7 +118,7 @@ func (s *SnapshotScheduleController) handle(ctx context.Context, snapshotSchedul
 
 	if snapshotSchedule.Spec.Suspend == nil || !*snapshotSchedule.Spec.Suspend {
 		// Then check if any of the policies require a trigger
-		policyType, start, err := s.shouldStartVolumeSnapshot(snapshotSchedule)
+		policyType, result, err := s.shouldStartVolumeSnapshot(snapshotSchedule)
 		if err != nil {
 			msg := fmt.Sprintf("Error checking if snapshot should be triggered: %v", err)
 			s.recorder.Event(snapshotSchedule,
@@ -130,8 +130,8 @@ func (s *SnapshotScheduleController) handle(ctx context.Context, snapshotSchedul
 		}
 
 		// Start a snapshot for a policy if required
-		if start {
-			err := s.startVolumeSnapshot(snapshotSchedule, policyType)
+		if result != nil && result.Trigger {
+			err := s.startVolumeSnapshot(snapshotSchedule, policyType, result.ScheduledTime)
 			if err != nil {
 				msg := fmt.Sprintf("Error triggering snapshot for schedule(%v): %v", policyType, err)
 				s.recorder.Event(snapshotSchedule,
@@ -242,50 +242,87 @@ func (s *SnapshotScheduleController) isVolumeSnapshotComplete(status snapv1.Volu
 	return status != snapv1.VolumeSnapshotConditionPending
 }
 
-func (s *SnapshotScheduleController) shouldStartVolumeSnapshot(snapshotSchedule *stork_api.VolumeSnapshotSchedule) (stork_api.SchedulePolicyType, bool, error) {
+func (s *SnapshotScheduleController) shouldStartVolumeSnapshot(snapshotSchedule *stork_api.VolumeSnapshotSchedule) (stork_api.SchedulePolicyType, *schedule.TriggerResult, error) {
 	// Don't trigger a new snapshot if one is already in progress
 	for _, policyType := range stork_api.GetValidSchedulePolicyTypes() {
 		policyVolumeSnapshot, present := snapshotSchedule.Status.Items[policyType]
 		if present {
 			for _, snapshot := range policyVolumeSnapshot {
 				if !s.isVolumeSnapshotComplete(snapshot.Status) {
-					return stork_api.SchedulePolicyTypeInvalid, false, nil
+					return stork_api.SchedulePolicyTypeInvalid, nil, nil
 				}
 			}
 		}
 	}
 
+	options := schedule.GetTriggerOptions(snapshotSchedule.Spec.StartingDeadlineSeconds, snapshotSchedule.Spec.CatchUpPolicy)
 	for _, policyType := range stork_api.GetValidSchedulePolicyTypes() {
-		var latestVolumeSnapshotTimestamp meta.Time
+		latestVolumeSnapshotTimestamp := schedule.GetLastSkippedTime(snapshotSchedule.Status.MissedRuns, policyType)
 		policyVolumeSnapshot, present := snapshotSchedule.Status.Items[policyType]
 		if present {
 			for _, snapshot := range policyVolumeSnapshot {
-				if latestVolumeSnapshotTimestamp.Before(&snapshot.CreationTimestamp) {
-					latestVolumeSnapshotTimestamp = snapshot.CreationTimestamp
+				triggerTimestamp := snapshot.CreationTimestamp
+				if !snapshot.ScheduledTimestamp.IsZero() {
+					triggerTimestamp = snapshot.ScheduledTimestamp
+				}
+				if latestVolumeSnapshotTimestamp.Before(&triggerTimestamp) {
+					latestVolumeSnapshotTimestamp = triggerTimestamp
 				}
 			}
 		}
-		trigger, err := schedule.TriggerRequired(
+		result, err := schedule.CheckTrigger(
 			snapshotSchedule.Spec.SchedulePolicyName,
 			snapshotSchedule.Namespace,
 			policyType,
 			latestVolumeSnapshotTimestamp,
+			options,
 		)
 		if err != nil {
-			return stork_api.SchedulePolicyTypeInvalid, false, err
+			return stork_api.SchedulePolicyTypeInvalid, nil, err
+		}
+		if err := s.recordMissedRuns(snapshotSchedule, policyType, latestVolumeSnapshotTimestamp, result); err != nil {
+			return stork_api.SchedulePolicyTypeInvalid, nil, err
 		}
-		if trigger {
-			return policyType, true, nil
+		if result.Trigger {
+			return policyType, result, nil
+		}
+	}
+	return stork_api.SchedulePolicyTypeInvalid, nil, nil
+}
+
+// recordMissedRuns adds the runs that were skipped or deferred to the status
+// of the schedule and raises events for them
+func (s *SnapshotScheduleController) recordMissedRuns(
+	snapshotSchedule *stork_api.VolumeSnapshotSchedule,
+	policyType stork_api.SchedulePolicyType,
+	lastTrigger meta.Time,
+	result *schedule.TriggerResult,
+) error {
+	var added []*stork_api.MissedScheduleRun
+	snapshotSchedule.Status.MissedRuns, added = schedule.UpdateMissedRuns(snapshotSchedule.Status.MissedRuns, policyType, lastTrigger, result)
+	if len(added) == 0 {
+		return nil
+	}
+	for _, run := range added {
+		eventType := v1.EventTypeNormal
+		if run.Action == stork_api.MissedScheduleRunSkipped {
+			eventType = v1.EventTypeWarning
 		}
+		msg := fmt.Sprintf("%v snapshot scheduled for %v (%v): %v", run.Action, run.ScheduledTimestamp, policyType, run.Reason)
+		s.recorder.Event(snapshotSchedule,
+			eventType,
+			string(run.Action),
+			msg)
+		log.VolumeSnapshotScheduleLog(snapshotSchedule).Info(msg)
 	}
-	return stork_api.SchedulePolicyTypeInvalid, false, nil
+	return s.client.Update(context.TODO(), snapshotSchedule)
 }
 
 func (s *SnapshotScheduleController) formatVolumeSnapshotName(snapshotSchedule *stork_api.VolumeSnapshotSchedule, policyType stork_api.SchedulePolicyType) string {
 	return strings.Join([]string{snapshotSchedule.Name, strings.ToLower(string(policyType)), time.Now().Format(nameTimeSuffixFormat)}, "-")
 }
 
-func (s *SnapshotScheduleController) startVolumeSnapshot(snapshotSchedule *stork_api.VolumeSnapshotSchedule, policyType stork_api.SchedulePolicyType) error {
+func (s *SnapshotScheduleController) startVolumeSnapshot(snapshotSchedule *stork_api.VolumeSnapshotSchedule, policyType stork_api.SchedulePolicyType, scheduledTime time.Time) error {
 	snapshotName := s.formatVolumeSnapshotName(snapshotSchedule, policyType)
 	if snapshotSchedule.Status.Items == nil {
 		snapshotSchedule.Status.Items = make(map[stork_api.SchedulePolicyType][]*stork_api.ScheduledVolumeSnapshotStatus)
@@ -295,9 +332,10 @@ func (s *SnapshotScheduleController) startVolumeSnapshot(snapshotSchedule *stork
 	}
 	snapshotSchedule.Status.Items[policyType] = append(snapshotSchedule.Status.Items[policyType],
 		&stork_api.ScheduledVolumeSnapshotStatus{
-			Name:              snapshotName,
-			CreationTimestamp: meta.NewTime(schedule.GetCurrentTime()),
-			Status:            snapv1.VolumeSnapshotConditionPending,
+			Name:               snapshotName,
+			CreationTimestamp:  meta.NewTime(schedule.GetCurrentTime()),
+			ScheduledTimestamp: meta.NewTime(scheduledTime),
+			Status:             snapv1.VolumeSnapshotConditionPending,
 		})
 	err := s.client.Update(context.TODO(), snapshotSchedule)
 	if err != nil {
@@ -375,6 +413,14 @@ func (s *SnapshotScheduleController) startVolumeSnapshot(snapshotSchedule *stork
 }
 
 func (s *SnapshotScheduleController) pruneVolumeSnapshots(snapshotSchedule *stork_api.VolumeSnapshotSchedule) error {
+	retention, loc, err := schedule.GetRetentionPolicy(snapshotSchedule.Spec.SchedulePolicyName, snapshotSchedule.Namespace)
+	if err != nil {
+		return err
+	}
+	if retention != nil {
+		return s.pruneVolumeSnapshotsWithRetention(snapshotSchedule, retention, loc)
+	}
+
 	for policyType, policyVolumeSnapshot := range snapshotSchedule.Status.Items {
 		numVolumeSnapshots := len(policyVolumeSnapshot)
 		deleteBefore := 0
@@ -418,6 +464,50 @@ func (s *SnapshotScheduleController) pruneVolumeSnapshots(snapshotSchedule *stor
 	return s.client.Update(context.TODO(), snapshotSchedule)
 }
 
+// pruneVolumeSnapshotsWithRetention deletes the snapshots of all the policy
+// types that aren't retained by the retention policy
+func (s *SnapshotScheduleController) pruneVolumeSnapshotsWithRetention(
+	snapshotSchedule *stork_api.VolumeSnapshotSchedule,
+	retention *stork_api.RetentionPolicy,
+	loc *time.Location,
+) error {
+	policyTypes := make([]stork_api.SchedulePolicyType, 0)
+	snapshots := make([]*stork_api.ScheduledVolumeSnapshotStatus, 0)
+	candidates := make([]schedule.RetentionCandidate, 0)
+	for policyType, policyVolumeSnapshot := range snapshotSchedule.Status.Items {
+		for _, snapshot := range policyVolumeSnapshot {
+			timestamp := snapshot.CreationTimestamp
+			if !snapshot.ScheduledTimestamp.IsZero() {
+				timestamp = snapshot.ScheduledTimestamp
+			}
+			policyTypes = append(policyTypes, policyType)
+			snapshots = append(snapshots, snapshot)
+			candidates = append(candidates, schedule.RetentionCandidate{
+				Timestamp:  timestamp.Time,
+				Complete:   s.isVolumeSnapshotComplete(snapshot.Status),
+				Successful: snapshot.Status == snapv1.VolumeSnapshotConditionReady,
+			})
+		}
+	}
+
+	decisions := schedule.ApplyRetention(retention, loc, candidates)
+	items := make(map[stork_api.SchedulePolicyType][]*stork_api.ScheduledVolumeSnapshotStatus)
+	for i, snapshot := range snapshots {
+		snapshot.RetentionTiers = decisions[i].Tiers
+		if !decisions[i].Retain {
+			err := k8sextops.Instance().DeleteSnapshot(snapshot.Name, snapshotSchedule.Namespace)
+			if err == nil || errors.IsNotFound(err) {
+				continue
+			}
+			// Keep a track of the failed deletes
+			log.VolumeSnapshotScheduleLog(snapshotSchedule).Warnf("Error deleting %v: %v", snapshot.Name, err)
+		}
+		items[policyTypes[i]] = append(items[policyTypes[i]], snapshot)
+	}
+	snapshotSchedule.Status.Items = items
+	return s.client.Update(context.TODO(), snapshotSchedule)
+}
+
 func (s *SnapshotScheduleController) createCRD() error {
 	resource := apiextensions.CustomResource{
 		Name:    stork_api.VolumeSnapshotScheduleResourceName,
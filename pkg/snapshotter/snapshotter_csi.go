@@ -15,7 +15,7 @@ import (
 	kSnapshotClient "github.com/kubernetes-csi/external-snapshotter/client/v4/clientset/versioned"
 	"github.com/libopenstorage/stork/drivers"
 	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
-	"github.com/libopenstorage/stork/pkg/crypto"
+	"github.com/libopenstorage/stork/pkg/backupformat"
 	"github.com/libopenstorage/stork/pkg/k8sutils"
 	"github.com/libopenstorage/stork/pkg/objectstore"
 	"github.com/libopenstorage/stork/pkg/version"
@@ -1031,31 +1031,9 @@ func (c *csiDriver) UploadSnapshotObjects(
 	if err != nil {
 		return err
 	}
-	if backupLocation.Location.EncryptionKey != "" {
-		return fmt.Errorf("EncryptionKey is deprecated, use EncryptionKeyV2 instead")
-	}
-	if backupLocation.Location.EncryptionV2Key != "" {
-		if data, err = crypto.Encrypt(data, backupLocation.Location.EncryptionV2Key); err != nil {
-			return err
-		}
-	}
-
-	writer, err := bucket.NewWriter(context.TODO(), filepath.Join(objectPath, objectName), nil)
-	if err != nil {
-		return err
-	}
 
-	_, err = writer.Write(data)
-	if err != nil {
-		closeErr := writer.Close()
-		if closeErr != nil {
-			logrus.Errorf("error closing writer for objectstore: %v", closeErr)
-		}
-		return err
-	}
-	err = writer.Close()
-	if err != nil {
-		logrus.Errorf("error closing writer for objectstore: %v", err)
+	if err := backupformat.WriteObject(context.TODO(), bucket, backupLocation, filepath.Join(objectPath, objectName), data, nil); err != nil {
+		logrus.Errorf("error uploading %v to objectstore: %v", objectName, err)
 		return err
 	}
 	return nil
@@ -1075,16 +1053,10 @@ func (c *csiDriver) DownloadSnapshotObjects(
 	if err != nil || !exists {
 		return snapshotInfoList, err
 	}
-	data, err := bucket.ReadAll(context.TODO(), objectPath)
+	data, err := backupformat.ReadObject(context.TODO(), bucket, backupLocation, objectPath)
 	if err != nil {
 		return snapshotInfoList, err
 	}
-	if backupLocation.Location.EncryptionV2Key != "" {
-		if decryptData, err := crypto.Decrypt(data, backupLocation.Location.EncryptionV2Key); err != nil {
-		} else {
-			data = decryptData
-		}
-	}
 
 	cboCommon := &csiBackupObject{}
 	if c.v1SnapshotRequired {
@@ -1,12 +1,14 @@
 package storkctl
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"time"
 
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	storkclientset "github.com/libopenstorage/stork/pkg/client/clientset/versioned"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/portworx/sched-ops/task"
 	"github.com/spf13/cobra"
@@ -18,8 +20,9 @@ import (
 )
 
 var (
-	backupStatusRetryInterval = 30 * time.Second
-	backupStatusRetryTimeout  = 6 * time.Hour
+	backupStatusRetryInterval       = 30 * time.Second
+	backupStatusRetryTimeout        = 6 * time.Hour
+	verificationStatusRetryInterval = 10 * time.Second
 )
 
 var applicationBackupColumns = []string{"NAME", "STAGE", "STATUS", "VOLUMES", "RESOURCES", "CREATED", "ELAPSED"}
@@ -272,3 +275,95 @@ func waitForApplicationBackup(name, namespace string, ioStreams genericclioption
 
 	return msg, err
 }
+
+func newVerifyApplicationBackupCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var waitForCompletion bool
+
+	verifyApplicationBackupCommand := &cobra.Command{
+		Use:     applicationBackupSubcommand,
+		Aliases: applicationBackupAliases,
+		Short:   "Verify the objects of an applicationbackup in the backup location",
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) != 1 {
+				util.CheckErr(fmt.Errorf("exactly one name needs to be provided for applicationbackup name"))
+				return
+			}
+			backupName := args[0]
+			namespace := cmdFactory.GetNamespace()
+			if _, err := storkops.Instance().GetApplicationBackup(backupName, namespace); err != nil {
+				util.CheckErr(err)
+				return
+			}
+			storkClient, err := cmdFactory.GetStorkClient()
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			verification := &storkv1.ApplicationBackupVerification{
+				Spec: storkv1.ApplicationBackupVerificationSpec{
+					BackupName: backupName,
+				},
+			}
+			verification.Name = backupName + "-verify-" + time.Now().Format("2006-01-02-150405")
+			verification.Namespace = namespace
+			_, err = storkClient.StorkV1alpha1().ApplicationBackupVerifications(namespace).Create(context.TODO(), verification, metav1.CreateOptions{})
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+
+			msg := "ApplicationBackupVerification " + verification.Name + " started successfully"
+			printMsg(msg, ioStreams.Out)
+
+			if waitForCompletion {
+				msg, err := waitForApplicationBackupVerification(storkClient, verification.Name, namespace, ioStreams)
+				if err != nil {
+					util.CheckErr(err)
+					return
+				}
+				printMsg(msg, ioStreams.Out)
+			}
+		},
+	}
+	verifyApplicationBackupCommand.Flags().BoolVarP(&waitForCompletion, "wait", "", false, "Wait for verification to complete and print the status of each object")
+
+	return verifyApplicationBackupCommand
+}
+
+func waitForApplicationBackupVerification(
+	storkClient storkclientset.Interface,
+	name, namespace string,
+	ioStreams genericclioptions.IOStreams,
+) (string, error) {
+	var msg string
+	var verification *storkv1.ApplicationBackupVerification
+
+	log.SetFlags(0)
+	log.SetOutput(ioutil.Discard)
+	t := func() (interface{}, bool, error) {
+		var err error
+		verification, err = storkClient.StorkV1alpha1().ApplicationBackupVerifications(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return "", false, err
+		}
+		if verification.Status.Status == storkv1.ApplicationBackupVerificationStatusSuccessful ||
+			verification.Status.Status == storkv1.ApplicationBackupVerificationStatusFailed {
+			return "", false, nil
+		}
+		return "", true, fmt.Errorf("%v", verification.Status.Status)
+	}
+	if _, err := task.DoRetryWithTimeout(t, backupStatusRetryTimeout, verificationStatusRetryInterval); err != nil {
+		return "Timed out performing task", err
+	}
+
+	printMsg(fmt.Sprintf("%-40s\t%-12s\t%-12s\t%s", "OBJECT", "SIZE", "STATUS", "REASON"), ioStreams.Out)
+	for _, object := range verification.Status.Objects {
+		printMsg(fmt.Sprintf("%-40s\t%-12v\t%-12s\t%s", object.Name, object.Size, object.Status, object.Reason), ioStreams.Out)
+	}
+	if verification.Status.Status == storkv1.ApplicationBackupVerificationStatusSuccessful {
+		msg = fmt.Sprintf("ApplicationBackupVerification %v completed successfully", name)
+	} else {
+		msg = fmt.Sprintf("ApplicationBackupVerification %v failed: %v", name, verification.Status.Reason)
+	}
+	return msg, nil
+}
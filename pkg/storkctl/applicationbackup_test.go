@@ -4,6 +4,7 @@
 package storkctl
 
 import (
+	"context"
 	"strings"
 	"testing"
 	"time"
@@ -14,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
 )
 
 func TestGetBackupsNoBackup(t *testing.T) {
@@ -233,3 +235,67 @@ func setApplicationBackupStatus(name, namespace string, isFail bool, t *testing.
 	_, err = storkops.Instance().UpdateApplicationBackup(backup)
 	require.NoError(t, err, "Error updating ApplicationBackups")
 }
+
+func TestVerifyApplicationBackupNoName(t *testing.T) {
+	cmdArgs := []string{"verify", "applicationbackup"}
+
+	expected := "error: exactly one name needs to be provided for applicationbackup name"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestVerifyApplicationBackupNotFound(t *testing.T) {
+	defer resetTest()
+	cmdArgs := []string{"verify", "applicationbackup", "-n", "test", "missing"}
+
+	expected := "Error from server (NotFound): applicationbackups.stork.libopenstorage.org \"missing\" not found"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestVerifyApplicationBackup(t *testing.T) {
+	defer resetTest()
+	createApplicationBackupAndVerify(t, "verifybackup", "test", []string{"namespace1"}, "backuplocation", "", "")
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := NewCommand(testFactory, streams.In, streams.Out, streams.ErrOut)
+	cmd.SetArgs([]string{"verify", "applicationbackup", "-n", "test", "verifybackup"})
+	require.NoError(t, cmd.Execute(), "Error running verify command")
+
+	verifications, err := testFactory.storkClient.StorkV1alpha1().ApplicationBackupVerifications("test").List(context.TODO(), metav1.ListOptions{})
+	require.NoError(t, err, "Error listing verifications")
+	require.Len(t, verifications.Items, 1, "One verification should have been created")
+	verification := verifications.Items[0]
+	require.Equal(t, "verifybackup", verification.Spec.BackupName, "Verification backup name mismatch")
+	require.Equal(t, "ApplicationBackupVerification "+verification.Name+" started successfully\n", out.String())
+}
+
+func TestWaitForApplicationBackupVerification(t *testing.T) {
+	defer resetTest()
+	verification := &storkv1.ApplicationBackupVerification{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "verification",
+			Namespace: "test",
+		},
+		Spec: storkv1.ApplicationBackupVerificationSpec{
+			BackupName: "backup",
+		},
+		Status: storkv1.ApplicationBackupVerificationStatus{
+			Status: storkv1.ApplicationBackupVerificationStatusFailed,
+			Reason: "1 of 2 objects failed verification",
+			Objects: []*storkv1.ApplicationBackupVerificationObjectInfo{
+				{Name: "metadata.json", Size: 10, Status: storkv1.ApplicationBackupVerificationStatusSuccessful},
+				{Name: "namespaces.json", Size: 20, Status: storkv1.ApplicationBackupVerificationStatusFailed, Reason: "checksum mismatch"},
+			},
+		},
+	}
+	_, err := testFactory.storkClient.StorkV1alpha1().ApplicationBackupVerifications("test").Create(context.TODO(), verification, metav1.CreateOptions{})
+	require.NoError(t, err, "Error creating verification")
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	msg, err := waitForApplicationBackupVerification(testFactory.storkClient, "verification", "test", streams)
+	require.NoError(t, err, "Error waiting for verification")
+	require.Equal(t, "ApplicationBackupVerification verification failed: 1 of 2 objects failed verification", msg)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3, "Status should be printed for each object")
+	require.Contains(t, lines[1], "metadata.json")
+	require.Contains(t, lines[2], "checksum mismatch")
+}
@@ -1,9 +1,15 @@
 package storkctl
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/backupformat"
+	"github.com/libopenstorage/stork/pkg/keyprovider"
+	"github.com/libopenstorage/stork/pkg/objectstore"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -21,6 +27,74 @@ const (
 var s3BackupLocationColumns = []string{"NAME", "PATH", "ACCESS-KEY-ID", "SECRET-ACCESS-KEY", "REGION", "ENDPOINT", "SSL-DISABLED"}
 var azureBackupLocationColumns = []string{"NAME", "PATH", "STORAGE-ACCOUNT-NAME", "STORAGE-ACCOUNT-KEY"}
 var googleBackupLocationColumns = []string{"NAME", "PATH", "PROJECT-ID"}
+var fileBackupLocationColumns = []string{"NAME", "PATH"}
+
+func newCreateBackupLocationCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var locationType string
+	var path string
+	var secretConfig string
+	var sync bool
+	createBackupLocationCommand := &cobra.Command{
+		Use:     backupLocationSubcommand,
+		Aliases: []string{"bl"},
+		Short:   "Create a BackupLocation",
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) != 1 {
+				util.CheckErr(fmt.Errorf("exactly one name needs to be provided for backuplocation name"))
+				return
+			}
+			backupLocation := &storkv1.BackupLocation{
+				Location: storkv1.BackupLocationItem{
+					Type:         storkv1.BackupLocationType(locationType),
+					Path:         path,
+					SecretConfig: secretConfig,
+					Sync:         sync,
+				},
+			}
+			backupLocation.Name = args[0]
+			backupLocation.Namespace = cmdFactory.GetNamespace()
+			if err := validateBackupLocation(backupLocation); err != nil {
+				util.CheckErr(err)
+				return
+			}
+			_, err := storkops.Instance().CreateBackupLocation(backupLocation)
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			msg := "BackupLocation " + backupLocation.Name + " created successfully"
+			printMsg(msg, ioStreams.Out)
+		},
+	}
+	createBackupLocationCommand.Flags().StringVarP(&locationType, "type", "t", "", "Type of the BackupLocation: s3, azure, google or file")
+	createBackupLocationCommand.Flags().StringVarP(&path, "path", "p", "", "Bucket for object stores, or the absolute path the filesystem is mounted at in the stork pods for file. "+
+		"Volumes backed up with the generic (KDMP) driver can't use file backup locations")
+	createBackupLocationCommand.Flags().StringVarP(&secretConfig, "secretConfig", "", "", "Secret with the credentials and other configuration for the BackupLocation")
+	createBackupLocationCommand.Flags().BoolVarP(&sync, "sync", "", false, "Sync backups from the BackupLocation to the cluster")
+
+	return createBackupLocationCommand
+}
+
+func validateBackupLocation(backupLocation *storkv1.BackupLocation) error {
+	switch backupLocation.Location.Type {
+	case storkv1.BackupLocationS3, storkv1.BackupLocationAzure, storkv1.BackupLocationGoogle:
+		if backupLocation.Location.SecretConfig == "" {
+			return fmt.Errorf("need to provide secretConfig with the credentials for backuplocation of type %v", backupLocation.Location.Type)
+		}
+	case storkv1.BackupLocationFile:
+		if backupLocation.Location.Path == "" {
+			return fmt.Errorf("need to provide path for backuplocation of type %v", backupLocation.Location.Type)
+		}
+		if !filepath.IsAbs(backupLocation.Location.Path) {
+			return fmt.Errorf("path for backuplocation of type %v should be an absolute path", backupLocation.Location.Type)
+		}
+	default:
+		return fmt.Errorf("invalid type %v for backuplocation, supported types are %v, %v, %v and %v",
+			backupLocation.Location.Type, storkv1.BackupLocationS3, storkv1.BackupLocationAzure,
+			storkv1.BackupLocationGoogle, storkv1.BackupLocationFile)
+	}
+	return nil
+}
 
 func newGetBackupLocationCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
 	var showSecrets bool
@@ -74,6 +148,7 @@ func newGetBackupLocationCommand(cmdFactory Factory, ioStreams genericclioptions
 			s3BackupLocations := &storkv1.BackupLocationList{}
 			azureBackupLocations := &storkv1.BackupLocationList{}
 			googleBackupLocations := &storkv1.BackupLocationList{}
+			fileBackupLocations := &storkv1.BackupLocationList{}
 			unknownBackupLocations := &storkv1.BackupLocationList{}
 			for _, bl := range backupLocations.Items {
 				switch bl.Location.Type {
@@ -92,6 +167,8 @@ func newGetBackupLocationCommand(cmdFactory Factory, ioStreams genericclioptions
 						bl.Location.GoogleConfig.AccountKey = hiddenString
 					}
 					googleBackupLocations.Items = append(googleBackupLocations.Items, bl)
+				case storkv1.BackupLocationFile:
+					fileBackupLocations.Items = append(fileBackupLocations.Items, bl)
 				default:
 					unknownBackupLocations.Items = append(unknownBackupLocations.Items, bl)
 				}
@@ -133,6 +210,16 @@ func newGetBackupLocationCommand(cmdFactory Factory, ioStreams genericclioptions
 						return
 					}
 				}
+				if len(fileBackupLocations.Items) != 0 {
+					if _, err := fmt.Fprintf(ioStreams.Out, "\nFile:\n-----\n"); err != nil {
+						util.CheckErr(err)
+						return
+					}
+					if err := printObjects(c, fileBackupLocations, cmdFactory, fileBackupLocationColumns, fileBackupLocationPrinter, ioStreams.Out); err != nil {
+						util.CheckErr(err)
+						return
+					}
+				}
 			} else {
 				if err := printObjects(c, backupLocations, cmdFactory, nil, nil, ioStreams.Out); err != nil {
 					util.CheckErr(err)
@@ -148,6 +235,81 @@ func newGetBackupLocationCommand(cmdFactory Factory, ioStreams genericclioptions
 	return getBackupLocationCommand
 }
 
+func newRewrapBackupLocationCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var vaultTransitPath string
+	rewrapBackupLocationCommand := &cobra.Command{
+		Use:     backupLocationSubcommand,
+		Aliases: []string{"bl"},
+		Short:   "Re-wrap the data keys of the backups in a BackupLocation with its current key ID",
+		Long: "Re-wraps the data keys of the backups in a BackupLocation with its current key ID. " +
+			"The data keys are unwrapped and wrapped by storkctl, not by stork, so the key provider has to be " +
+			"reachable from where storkctl runs: keys for the secret provider are read with the current kubeconfig, " +
+			"keys for the file provider are read from the path on this machine, and the kms provider needs " +
+			"--kms-vault-transit-path with the VAULT_* environment variables set.",
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) != 1 {
+				util.CheckErr(fmt.Errorf("exactly one name needs to be provided for backuplocation name"))
+				return
+			}
+			name := args[0]
+			namespace := cmdFactory.GetNamespace()
+			backupLocation, err := storkops.Instance().GetBackupLocation(name, namespace)
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			config := backupLocation.Location.EncryptionKeyProvider
+			if config == nil {
+				util.CheckErr(fmt.Errorf("backuplocation %v/%v does not have an encryption key provider", namespace, name))
+				return
+			}
+			if vaultTransitPath != "" {
+				if err := keyprovider.RegisterVaultKMS(vaultTransitPath); err != nil {
+					util.CheckErr(err)
+					return
+				}
+			}
+			bucket, err := objectstore.GetBucket(backupLocation)
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			objectPaths, err := backupformat.RewrapDataKeys(context.TODO(), bucket, backupLocation, "")
+			for _, objectPath := range objectPaths {
+				printMsg(fmt.Sprintf("Re-wrapped data key for %v with key %v", objectPath, config.KeyID), ioStreams.Out)
+				updateBackupEncryptionKeyID(objectPath, config.KeyID, ioStreams)
+			}
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			printMsg(fmt.Sprintf("Re-wrapped %v data keys in BackupLocation %v/%v", len(objectPaths), namespace, name), ioStreams.Out)
+		},
+	}
+
+	rewrapBackupLocationCommand.Flags().StringVarP(&vaultTransitPath, "kms-vault-transit-path", "", "", "Mount path of the Vault transit secrets engine to use as the \"vault\" KMS")
+
+	return rewrapBackupLocationCommand
+}
+
+// updateBackupEncryptionKeyID updates the key ID in the status of the
+// ApplicationBackup the objects in objectPath belong to, if it still exists
+func updateBackupEncryptionKeyID(objectPath string, keyID string, ioStreams genericclioptions.IOStreams) {
+	// Backups are uploaded to <namespace>/<name>/<uid>
+	parts := strings.Split(objectPath, "/")
+	if len(parts) != 3 {
+		return
+	}
+	backup, err := storkops.Instance().GetApplicationBackup(parts[1], parts[0])
+	if err != nil || string(backup.UID) != parts[2] {
+		return
+	}
+	backup.Status.EncryptionKeyID = keyID
+	if _, err := storkops.Instance().UpdateApplicationBackup(backup); err != nil {
+		printMsg(fmt.Sprintf("Error updating key ID for ApplicationBackup %v/%v: %v", backup.Namespace, backup.Name, err), ioStreams.ErrOut)
+	}
+}
+
 func s3BackupLocationPrinter(
 	backupLocationList *storkv1.BackupLocationList,
 	options printers.GenerateOptions,
@@ -212,3 +374,22 @@ func googleBackupLocationPrinter(
 	}
 	return rows, nil
 }
+
+func fileBackupLocationPrinter(
+	backupLocationList *storkv1.BackupLocationList,
+	options printers.GenerateOptions,
+) ([]metav1beta1.TableRow, error) {
+	if backupLocationList == nil {
+		return nil, nil
+	}
+
+	rows := make([]metav1beta1.TableRow, 0)
+	for _, backupLocation := range backupLocationList.Items {
+		row := getRow(&backupLocation,
+			[]interface{}{backupLocation.Name,
+				backupLocation.Location.Path},
+		)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -261,3 +261,66 @@ func TestAllBackupLocation(t *testing.T) {
 	cmdArgs = []string{"get", "backuplocation", "--all-namespaces"}
 	testCommon(t, cmdArgs, nil, expected, false)
 }
+
+func TestRewrapBackupLocationNoName(t *testing.T) {
+	cmdArgs := []string{"rewrap", "backuplocation"}
+
+	expected := "error: exactly one name needs to be provided for backuplocation name"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestRewrapBackupLocationNoKeyProvider(t *testing.T) {
+	defer resetTest()
+
+	backupLocation := &storkv1.BackupLocation{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      "rewraplocation",
+			Namespace: "default",
+		},
+		Location: storkv1.BackupLocationItem{
+			Type: storkv1.BackupLocationS3,
+		},
+	}
+	_, err := storkops.Instance().CreateBackupLocation(backupLocation)
+	require.NoError(t, err, "Error creating backuplocation")
+
+	cmdArgs := []string{"rewrap", "backuplocation", "rewraplocation"}
+	expected := "error: backuplocation default/rewraplocation does not have an encryption key provider"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestCreateBackupLocationInvalid(t *testing.T) {
+	cmdArgs := []string{"create", "backuplocation"}
+	expected := "error: exactly one name needs to be provided for backuplocation name"
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	cmdArgs = []string{"create", "backuplocation", "invalidlocation", "--type", "nfs"}
+	expected = "error: invalid type nfs for backuplocation, supported types are s3, azure, google and file"
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	cmdArgs = []string{"create", "backuplocation", "invalidlocation", "--type", "s3", "--path", "bucket"}
+	expected = "error: need to provide secretConfig with the credentials for backuplocation of type s3"
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	cmdArgs = []string{"create", "backuplocation", "invalidlocation", "--type", "file"}
+	expected = "error: need to provide path for backuplocation of type file"
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	cmdArgs = []string{"create", "backuplocation", "invalidlocation", "--type", "file", "--path", "mnt/nfs"}
+	expected = "error: path for backuplocation of type file should be an absolute path"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestFileBackupLocation(t *testing.T) {
+	defer resetTest()
+
+	cmdArgs := []string{"create", "backuplocation", "filelocation", "--type", "file", "--path", "/mnt/nfs"}
+	expected := "BackupLocation filelocation created successfully\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	expected = "\nFile:\n-----\n" +
+		"NAME           PATH\n" +
+		"filelocation   /mnt/nfs\n"
+	cmdArgs = []string{"get", "backuplocation", "filelocation"}
+	testCommon(t, cmdArgs, nil, expected, false)
+}
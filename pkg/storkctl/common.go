@@ -1,9 +1,13 @@
 package storkctl
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 	"time"
+
+	"gopkg.in/yaml.v2"
 )
 
 func toTimeString(t time.Time) string {
@@ -23,3 +27,33 @@ func printMsg(msg string, out io.Writer) {
 		fmt.Println(msg)
 	}
 }
+
+// printJSONOrYaml prints objects that aren't kubernetes objects if the output
+// format is JSON or YAML. The YAML is converted from the JSON so that the
+// field names of both match. Returns false if the object wasn't printed.
+func printJSONOrYaml(object interface{}, outputFormat string, out io.Writer) (bool, error) {
+	switch outputFormat {
+	case outputFormatJSON:
+		data, err := json.MarshalIndent(object, "", "    ")
+		if err != nil {
+			return false, err
+		}
+		printMsg(string(data), out)
+		return true, nil
+	case outputFormatYaml:
+		data, err := json.Marshal(object)
+		if err != nil {
+			return false, err
+		}
+		var converted interface{}
+		if err := yaml.Unmarshal(data, &converted); err != nil {
+			return false, err
+		}
+		if data, err = yaml.Marshal(converted); err != nil {
+			return false, err
+		}
+		printMsg(strings.TrimSpace(string(data)), out)
+		return true, nil
+	}
+	return false, nil
+}
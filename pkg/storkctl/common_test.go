@@ -72,9 +72,11 @@ func resetTest() {
 	}
 	testFactory = NewTestFactory()
 	testFactory.setOutputFormat(outputFormatTable)
+	testFactory.storkClient = fakeStorkClient
 	tf := testFactory.TestFactory
 	tf.Client = fakeRestClient
 	fakeKubeClient := kubernetes.NewSimpleClientset()
+	testFactory.kubeClient = fakeKubeClient
 
 	core.SetInstance(core.New(fakeKubeClient))
 	storkops.SetInstance(storkops.New(fakeKubeClient, fakeStorkClient, fakeRestClient))
@@ -0,0 +1,19 @@
+package storkctl
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func newExplainCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	explainCommands := &cobra.Command{
+		Use:   "explain",
+		Short: "Explain decisions made by stork",
+	}
+
+	explainCommands.AddCommand(
+		newExplainSchedulingCommand(cmdFactory, ioStreams),
+	)
+
+	return explainCommands
+}
@@ -3,6 +3,7 @@ package storkctl
 import (
 	"fmt"
 
+	storkclientset "github.com/libopenstorage/stork/pkg/client/clientset/versioned"
 	appsops "github.com/portworx/sched-ops/k8s/apps"
 	"github.com/portworx/sched-ops/k8s/batch"
 	"github.com/portworx/sched-ops/k8s/core"
@@ -11,6 +12,7 @@ import (
 	ocpops "github.com/portworx/sched-ops/k8s/openshift"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/spf13/pflag"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
@@ -48,6 +50,12 @@ type Factory interface {
 	GetAllNamespaces() ([]string, error)
 	// GetConfig Get the merged config for the server
 	GetConfig() (*rest.Config, error)
+	// GetStorkClient Get a client for the stork APIs that aren't available
+	// through sched-ops
+	GetStorkClient() (storkclientset.Interface, error)
+	// GetKubernetesClient Get a client for the kubernetes APIs that aren't
+	// available through sched-ops
+	GetKubernetesClient() (kubernetes.Interface, error)
 	// RawConfig Gets the raw merged config for the server
 	RawConfig() (clientcmdapi.Config, error)
 	// UpdateConfig Updates the config to be used for API calls
@@ -131,6 +139,22 @@ func (f *factory) GetConfig() (*rest.Config, error) {
 	return f.getKubeconfig().ClientConfig()
 }
 
+func (f *factory) GetStorkClient() (storkclientset.Interface, error) {
+	config, err := f.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	return storkclientset.NewForConfig(config)
+}
+
+func (f *factory) GetKubernetesClient() (kubernetes.Interface, error) {
+	config, err := f.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(config)
+}
+
 func (f *factory) IsWatchSet() bool {
 	return f.watch
 }
@@ -4,6 +4,8 @@
 package storkctl
 
 import (
+	storkclientset "github.com/libopenstorage/stork/pkg/client/clientset/versioned"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
 )
@@ -11,6 +13,8 @@ import (
 type TestFactory struct {
 	cmdtesting.TestFactory
 	Factory
+	storkClient storkclientset.Interface
+	kubeClient  kubernetes.Interface
 }
 
 func NewTestFactory() *TestFactory {
@@ -27,3 +31,11 @@ func (t *TestFactory) GetConfig() (*rest.Config, error) {
 func (t *TestFactory) UpdateConfig() error {
 	return nil
 }
+
+func (t *TestFactory) GetStorkClient() (storkclientset.Interface, error) {
+	return t.storkClient, nil
+}
+
+func (t *TestFactory) GetKubernetesClient() (kubernetes.Interface, error) {
+	return t.kubeClient, nil
+}
@@ -35,6 +35,7 @@ func newGetCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *c
 		newGetApplicationCloneCommand(cmdFactory, ioStreams),
 		newGetBackupLocationCommand(cmdFactory, ioStreams),
 		newGetapplicationRegistrationCommand(cmdFactory, ioStreams),
+		newGetResourceTransformationCommand(cmdFactory, ioStreams),
 	)
 
 	return getCommands
@@ -0,0 +1,136 @@
+package storkctl
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubernetes/pkg/printers"
+)
+
+var resourceTransformationColumns = []string{"NAME", "STATUS", "RESOURCES", "FAILED", "CREATED"}
+var resourceTransformationSubcommand = "resourcetransformations"
+var resourceTransformationAliases = []string{"resourcetransformation", "transform", "transforms"}
+
+func newGetResourceTransformationCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var showDiff bool
+	getResourceTransformationCommand := &cobra.Command{
+		Use:     resourceTransformationSubcommand,
+		Aliases: resourceTransformationAliases,
+		Short:   "Get resourcetransformation resources",
+		Run: func(c *cobra.Command, args []string) {
+			var transforms *storkv1.ResourceTransformationList
+			if len(args) > 0 {
+				if cmdFactory.AllNamespaces() {
+					util.CheckErr(fmt.Errorf("a resource cannot be retrieved by name across all namespaces"))
+					return
+				}
+
+				transforms = new(storkv1.ResourceTransformationList)
+				for _, name := range args {
+					transform, err := storkops.Instance().GetResourceTransformation(name, cmdFactory.GetNamespace())
+					if err != nil {
+						util.CheckErr(err)
+						return
+					}
+					transforms.Items = append(transforms.Items, *transform)
+				}
+			} else {
+				namespaces, err := cmdFactory.GetAllNamespaces()
+				if err != nil {
+					util.CheckErr(err)
+					return
+				}
+
+				var tempTransforms storkv1.ResourceTransformationList
+				for _, ns := range namespaces {
+					transforms, err := storkops.Instance().ListResourceTransformations(ns, metav1.ListOptions{})
+					if err != nil {
+						util.CheckErr(err)
+						return
+					}
+					tempTransforms.Items = append(tempTransforms.Items, transforms.Items...)
+				}
+				transforms = &tempTransforms
+			}
+
+			if len(transforms.Items) == 0 {
+				handleEmptyList(ioStreams.Out)
+				return
+			}
+
+			if showDiff {
+				printResourceTransformationDiffs(transforms, ioStreams.Out)
+				return
+			}
+			if err := printObjects(c, transforms, cmdFactory, resourceTransformationColumns, resourceTransformationPrinter, ioStreams.Out); err != nil {
+				util.CheckErr(err)
+				return
+			}
+		},
+	}
+	cmdFactory.BindGetFlags(getResourceTransformationCommand.Flags())
+	getResourceTransformationCommand.Flags().BoolVarP(&showDiff, "diff", "", false, "Print the diff of each resource transformed during the dry-run")
+
+	return getResourceTransformationCommand
+}
+
+func resourceTransformationPrinter(
+	transformList *storkv1.ResourceTransformationList,
+	options printers.GenerateOptions,
+) ([]metav1beta1.TableRow, error) {
+	if transformList == nil {
+		return nil, nil
+	}
+
+	rows := make([]metav1beta1.TableRow, 0)
+	for _, transform := range transformList.Items {
+		failed := 0
+		for _, resource := range transform.Status.Resources {
+			if resource.Status == storkv1.ResourceTransformationStatusFailed {
+				failed++
+			}
+		}
+		row := getRow(&transform,
+			[]interface{}{transform.Name,
+				transform.Status.Status,
+				len(transform.Status.Resources),
+				failed,
+				toTimeString(transform.CreationTimestamp.Time)},
+		)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func printResourceTransformationDiffs(transforms *storkv1.ResourceTransformationList, out io.Writer) {
+	for _, transform := range transforms.Items {
+		printMsg(fmt.Sprintf("ResourceTransformation: %v/%v (%v)", transform.Namespace, transform.Name, transform.Status.Status), out)
+		if len(transform.Status.Resources) == 0 {
+			printMsg("No resources transformed.", out)
+			continue
+		}
+		for _, resource := range transform.Status.Resources {
+			gvk := strings.Trim(resource.Group+"/"+resource.Version+"/"+resource.Kind, "/")
+			printMsg(fmt.Sprintf("\n%v %v/%v (%v)", gvk, resource.Namespace, resource.Name, resource.Status), out)
+			if resource.Reason != "" {
+				printMsg("Reason: "+resource.Reason, out)
+			}
+			if resource.Diff == "" {
+				printMsg("No changes.", out)
+				continue
+			}
+			printMsg(strings.TrimSuffix(resource.Diff, "\n"), out)
+		}
+		if transform.Status.DiffsOmitted > 0 {
+			printMsg(fmt.Sprintf("\n%v diffs omitted since the diffs of the transformation are too large", transform.Status.DiffsOmitted), out)
+		}
+	}
+}
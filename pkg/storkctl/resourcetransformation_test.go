@@ -0,0 +1,134 @@
+//go:build unittest
+// +build unittest
+
+package storkctl
+
+import (
+	"testing"
+
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/resourcecollector"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/stretchr/testify/require"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func createResourceTransformationAndVerify(t *testing.T, name string, namespace string) {
+	transform := &storkv1.ResourceTransformation{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Status: storkv1.ResourceTransformationStatus{
+			Status: storkv1.ResourceTransformationStatusFailed,
+			Resources: []*storkv1.TransformResourceInfo{
+				{
+					Name:      "app",
+					Namespace: namespace,
+					GroupVersionKind: meta.GroupVersionKind{
+						Group:   "apps",
+						Version: "v1",
+						Kind:    "Deployment",
+					},
+					Status: storkv1.ResourceTransformationStatusReady,
+					Diff: "--- " + namespace + "/app\n" +
+						"+++ " + namespace + "/app (transformed)\n" +
+						"@@ -1 +1 @@\n" +
+						"-image: old.registry.io/app\n" +
+						"+image: new.registry.io/app\n",
+				},
+				{
+					Name:      "db",
+					Namespace: namespace,
+					GroupVersionKind: meta.GroupVersionKind{
+						Version: "v1",
+						Kind:    "Service",
+					},
+					Status: storkv1.ResourceTransformationStatusFailed,
+					Reason: "invalid port",
+				},
+			},
+		},
+	}
+	_, err := storkops.Instance().CreateResourceTransformation(transform)
+	require.NoError(t, err, "Error creating resourcetransformation")
+}
+
+func TestGetResourceTransformationsNoTransformation(t *testing.T) {
+	cmdArgs := []string{"get", "resourcetransformations"}
+
+	expected := "No resources found.\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
+func TestGetResourceTransformationNotFound(t *testing.T) {
+	defer resetTest()
+	cmdArgs := []string{"get", "resourcetransformations", "-n", "test", "transform1"}
+
+	expected := `Error from server (NotFound): resourcetransformations.stork.libopenstorage.org "transform1" not found`
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestGetResourceTransformations(t *testing.T) {
+	defer resetTest()
+	createResourceTransformationAndVerify(t, "transform1", "test")
+
+	cmdArgs := []string{"get", "resourcetransformations", "-n", "test", "transform1"}
+	expected := "NAME         STATUS   RESOURCES   FAILED   CREATED\n" +
+		"transform1   Failed   2           1        \n"
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
+func TestGetResourceTransformationDiff(t *testing.T) {
+	defer resetTest()
+	createResourceTransformationAndVerify(t, "transform1", "test")
+
+	cmdArgs := []string{"get", "resourcetransformations", "-n", "test", "--diff", "transform1"}
+	expected := "ResourceTransformation: test/transform1 (Failed)\n" +
+		"\napps/v1/Deployment test/app (Ready)\n" +
+		"--- test/app\n" +
+		"+++ test/app (transformed)\n" +
+		"@@ -1 +1 @@\n" +
+		"-image: old.registry.io/app\n" +
+		"+image: new.registry.io/app\n" +
+		"\nv1/Service test/db (Failed)\n" +
+		"Reason: invalid port\n" +
+		"No changes.\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
+func TestGetResourceTransformationDiffOmitted(t *testing.T) {
+	defer resetTest()
+	transform := &storkv1.ResourceTransformation{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      "transform1",
+			Namespace: "test",
+		},
+		Status: storkv1.ResourceTransformationStatus{
+			Status: storkv1.ResourceTransformationStatusReady,
+			Resources: []*storkv1.TransformResourceInfo{
+				{
+					Name:      "app",
+					Namespace: "test",
+					GroupVersionKind: meta.GroupVersionKind{
+						Group:   "apps",
+						Version: "v1",
+						Kind:    "Deployment",
+					},
+					Status: storkv1.ResourceTransformationStatusReady,
+					Diff:   resourcecollector.TransformDiffOmitted,
+				},
+			},
+			DiffsOmitted: 1,
+		},
+	}
+	_, err := storkops.Instance().CreateResourceTransformation(transform)
+	require.NoError(t, err, "Error creating resourcetransformation")
+
+	cmdArgs := []string{"get", "resourcetransformations", "-n", "test", "--diff", "transform1"}
+	expected := "ResourceTransformation: test/transform1 (Ready)\n" +
+		"\napps/v1/Deployment test/app (Ready)\n" +
+		"... diff omitted since the diffs of the transformation are too large\n" +
+		"\n1 diffs omitted since the diffs of the transformation are too large\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+}
@@ -0,0 +1,19 @@
+package storkctl
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func newRewrapCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	rewrapCommands := &cobra.Command{
+		Use:   "rewrap",
+		Short: "Re-wrap encryption keys after a key rotation",
+	}
+
+	rewrapCommands.AddCommand(
+		newRewrapBackupLocationCommand(cmdFactory, ioStreams),
+	)
+
+	return rewrapCommands
+}
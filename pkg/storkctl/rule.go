@@ -0,0 +1,190 @@
+package storkctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/extender"
+	"github.com/libopenstorage/stork/pkg/rule"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/cmd/util"
+)
+
+const (
+	ruleSubcommand = "rule"
+)
+
+var ruleAliases = []string{"rules"}
+
+func newTestRuleCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var podNamespace string
+	var ruleType string
+	var execute bool
+	var service string
+	var serviceNamespace string
+	var servicePort string
+
+	testRuleCommand := &cobra.Command{
+		Use:     ruleSubcommand + " <name>",
+		Aliases: ruleAliases,
+		Short:   "Show the pods and containers the actions of a rule would run on",
+		Long: "Resolves the pods the actions of a rule would run on and checks that their containers exist. " +
+			"The rule is tested by stork through its service. With --execute the rule is instead tested by storkctl " +
+			"with the credentials of the kubeconfig, and the actions are also run, with " +
+			rule.TestModeEnvVar + "=true set in the environment of commands and the " + rule.TestModeHeader +
+			": true header set in http requests so that they can skip anything disruptive. " +
+			"The http requests are sent from where storkctl runs, so the pods or services need to be reachable " +
+			"from there. Background actions are never executed.",
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) != 1 {
+				util.CheckErr(fmt.Errorf("exactly one name needs to be provided for rule name"))
+				return
+			}
+			outputFormat, err := cmdFactory.GetOutputFormat()
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			rType := rule.Type(ruleType)
+			if rType != rule.PreExecRule && rType != rule.PostExecRule {
+				util.CheckErr(fmt.Errorf("invalid rule type %v, must be one of %v or %v", ruleType, rule.PreExecRule, rule.PostExecRule))
+				return
+			}
+			namespace := cmdFactory.GetNamespace()
+			r, err := storkops.Instance().GetRule(args[0], namespace)
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			if err := rule.ValidateRule(r, rType); err != nil {
+				util.CheckErr(fmt.Errorf("error testing rule %v: %v", args[0], err))
+				return
+			}
+			if podNamespace == "" {
+				podNamespace = namespace
+			}
+			var result *rule.TestRuleResult
+			if execute {
+				// The actions are executed with the credentials of the user
+				// instead of the ones of stork
+				result, err = rule.TestRule(r, rType, podNamespace, rule.TestRuleOptions{Execute: true})
+			} else {
+				result, err = testRuleThroughService(cmdFactory, args[0], namespace, podNamespace, ruleType,
+					service, serviceNamespace, servicePort)
+			}
+			if err != nil {
+				util.CheckErr(fmt.Errorf("error testing rule %v: %v", args[0], err))
+				return
+			}
+			if err := printTestRuleResult(result, outputFormat, ioStreams.Out); err != nil {
+				util.CheckErr(err)
+				return
+			}
+		},
+	}
+	testRuleCommand.Flags().StringVar(&podNamespace, "pod-namespace", "", "Namespace of the pods to run the rule on. Defaults to the namespace of the rule")
+	testRuleCommand.Flags().StringVar(&ruleType, "type", string(rule.PreExecRule), "Type of the rule. One of: preExecRule|postExecRule")
+	testRuleCommand.Flags().BoolVar(&execute, "execute", false,
+		"Execute the actions on the pods in test mode. Commands have "+rule.TestModeEnvVar+"=true set and http requests have the "+
+			rule.TestModeHeader+" header set")
+	testRuleCommand.Flags().StringVar(&service, "service", defaultExtenderService, "Name of the service for stork")
+	testRuleCommand.Flags().StringVar(&serviceNamespace, "service-namespace", defaultExtenderServiceNamespace, "Namespace of the service for stork")
+	testRuleCommand.Flags().StringVar(&servicePort, "service-port", defaultExtenderServicePort, "Port of the service for stork")
+
+	return testRuleCommand
+}
+
+// testRuleThroughService asks stork to resolve where the actions of a rule
+// would run
+func testRuleThroughService(
+	cmdFactory Factory,
+	name string,
+	namespace string,
+	podNamespace string,
+	ruleType string,
+	service string,
+	serviceNamespace string,
+	servicePort string,
+) (*rule.TestRuleResult, error) {
+	client, err := cmdFactory.GetKubernetesClient()
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]string{
+		"rule":         name,
+		"namespace":    namespace,
+		"podNamespace": podNamespace,
+		"type":         ruleType,
+	}
+	data, err := client.CoreV1().Services(serviceNamespace).
+		ProxyGet("http", service, servicePort, extender.TestRulePath, params).
+		DoRaw(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	result := &rule.TestRuleResult{}
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, fmt.Errorf("error parsing response from stork: %v", err)
+	}
+	return result, nil
+}
+
+func printTestRuleResult(result *rule.TestRuleResult, outputFormat string, out io.Writer) error {
+	if printed, err := printJSONOrYaml(result, outputFormat, out); printed || err != nil {
+		return err
+	}
+
+	printMsg(fmt.Sprintf("Rule: %v/%v (%v)", result.Namespace, result.Rule, result.Type), out)
+	if result.PodNamespace != result.Namespace {
+		printMsg(fmt.Sprintf("Pod namespace: %v", result.PodNamespace), out)
+	}
+	for _, warning := range result.Warnings {
+		printMsg(fmt.Sprintf("Warning: %v", warning), out)
+	}
+	printMsg(fmt.Sprintf("%-8s\t%-30s\t%-20s\t%-8s\t%-40s\t%s", "ACTION", "POD", "CONTAINER", "TYPE", "RUN", "STATUS"), out)
+	for _, action := range result.Actions {
+		pod := action.Pod
+		if pod == "" {
+			pod = "-"
+		}
+		container := action.Container
+		if container == "" || action.Type == storkv1.RuleActionHTTP {
+			container = "-"
+		}
+		run := action.Action
+		if action.Background {
+			run += " (background)"
+		}
+		printMsg(fmt.Sprintf("%-8s\t%-30s\t%-20s\t%-8s\t%-40s\t%s",
+			fmt.Sprintf("%v.%v", action.ItemIndex, action.ActionIndex), pod, container, action.Type, run,
+			getTestActionStatus(action)), out)
+	}
+	return nil
+}
+
+func getTestActionStatus(action *rule.TestActionResult) string {
+	if action.Error != "" {
+		return "Error: " + action.Error
+	}
+	if action.Skipped {
+		return "Skipped"
+	}
+	if action.Result == nil {
+		return "Ready"
+	}
+	status := string(action.Result.Status)
+	if action.Result.ExitCode != nil {
+		status += fmt.Sprintf(" (exit code %v)", *action.Result.ExitCode)
+	} else if action.Result.HTTPStatus != 0 {
+		status += fmt.Sprintf(" (status %v)", action.Result.HTTPStatus)
+	}
+	if action.Result.Reason != "" {
+		status += ": " + action.Result.Reason
+	}
+	return status
+}
@@ -0,0 +1,203 @@
+//go:build unittest
+// +build unittest
+
+package storkctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/extender"
+	"github.com/libopenstorage/stork/pkg/rule"
+	"github.com/portworx/sched-ops/k8s/core"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubernetes "k8s.io/client-go/kubernetes/fake"
+	restclient "k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// setTestRuleResponse responds to requests sent to stork to test a rule with
+// the result of testing the rule in the same way as stork
+func setTestRuleResponse(t *testing.T, name string, podNamespace string) {
+	r, err := storkops.Instance().GetRule(name, "test")
+	require.NoError(t, err)
+	result, err := rule.TestRule(r, rule.PreExecRule, podNamespace, rule.TestRuleOptions{})
+	require.NoError(t, err)
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	fakeKubeClient, ok := testFactory.kubeClient.(*kubernetes.Clientset)
+	require.True(t, ok, "Unexpected kubernetes client type")
+	fakeKubeClient.PrependProxyReactor("services", func(action k8stesting.Action) (bool, restclient.ResponseWrapper, error) {
+		proxyAction := action.(k8stesting.ProxyGetAction)
+		require.Equal(t, "stork-service", proxyAction.GetName())
+		require.Equal(t, "kube-system", proxyAction.GetNamespace())
+		require.Equal(t, extender.TestRulePath, proxyAction.GetPath())
+		params := proxyAction.GetParams()
+		require.Equal(t, name, params["rule"])
+		require.Equal(t, "test", params["namespace"])
+		require.Equal(t, podNamespace, params["podNamespace"])
+		require.Equal(t, string(rule.PreExecRule), params["type"])
+		require.NotContains(t, params, "execute", "Actions shouldn't be executed through stork")
+		return true, &fakeProxyResponse{data: data}, nil
+	})
+}
+
+func createTestRulePod(t *testing.T, name string, phase v1.PodPhase, containers ...string) {
+	pod := &v1.Pod{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      name,
+			Namespace: "test",
+			Labels:    map[string]string{"app": "mysql"},
+		},
+		Status: v1.PodStatus{
+			Phase: phase,
+			PodIP: "10.0.0.1",
+		},
+	}
+	for _, container := range containers {
+		pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{Name: container})
+		pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, v1.ContainerStatus{
+			Name:  container,
+			Ready: phase == v1.PodRunning,
+		})
+	}
+	_, err := core.Instance().CreatePod(pod)
+	require.NoError(t, err, "Error creating pod")
+}
+
+func createTestRule(t *testing.T, name string) {
+	rule := &storkv1.Rule{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      name,
+			Namespace: "test",
+		},
+		Rules: []storkv1.RuleItem{
+			{
+				PodSelector: map[string]string{"app": "mysql"},
+				Container:   "mysql",
+				Actions: []storkv1.RuleAction{
+					{
+						Type:       storkv1.RuleActionCommand,
+						Value:      "flush tables with read lock",
+						Background: true,
+					},
+					{
+						Type: storkv1.RuleActionHTTP,
+						HTTP: &storkv1.RuleHTTPAction{
+							Port:   8080,
+							Method: "POST",
+							Path:   "/quiesce",
+						},
+						RunInSinglePod: true,
+					},
+				},
+			},
+			{
+				PodSelector: map[string]string{"app": "postgres"},
+				Actions: []storkv1.RuleAction{
+					{
+						Type:  storkv1.RuleActionCommand,
+						Value: "sync",
+					},
+				},
+			},
+		},
+	}
+	_, err := storkops.Instance().CreateRule(rule)
+	require.NoError(t, err, "Error creating rule")
+}
+
+func TestTestRuleNoName(t *testing.T) {
+	cmdArgs := []string{"test", "rule"}
+
+	expected := "error: exactly one name needs to be provided for rule name"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestTestRuleNotFound(t *testing.T) {
+	defer resetTest()
+	cmdArgs := []string{"test", "rule", "-n", "test", "missingrule"}
+
+	expected := `Error from server (NotFound): rules.stork.libopenstorage.org "missingrule" not found`
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestTestRuleInvalidType(t *testing.T) {
+	defer resetTest()
+	createTestRule(t, "testrule")
+	cmdArgs := []string{"test", "rule", "-n", "test", "--type", "midExecRule", "testrule"}
+
+	expected := "error: invalid rule type midExecRule, must be one of preExecRule or postExecRule"
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	// Background actions aren't allowed in post exec rules
+	cmdArgs = []string{"test", "rule", "-n", "test", "--type", "postExecRule", "testrule"}
+	expected = "error: error testing rule testrule: background actions are not supported for post exec rules"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestTestRule(t *testing.T) {
+	defer resetTest()
+	createTestRule(t, "testrule")
+	createTestRulePod(t, "mysql-0", v1.PodRunning, "mysql", "sidecar")
+	createTestRulePod(t, "mysql-1", v1.PodPending, "mysql")
+	createTestRulePod(t, "mysql-2", v1.PodRunning, "sidecar")
+	setTestRuleResponse(t, "testrule", "test")
+
+	cmdArgs := []string{"test", "rule", "-n", "test", "testrule"}
+	expected := "Rule: test/testrule (preExecRule)\n" +
+		"Warning: None of the pods matched selectors for rule item 1: map[app:postgres]\n" +
+		fmt.Sprintf("%-8s\t%-30s\t%-20s\t%-8s\t%-40s\t%s\n", "ACTION", "POD", "CONTAINER", "TYPE", "RUN", "STATUS") +
+		fmt.Sprintf("%-8s\t%-30s\t%-20s\t%-8s\t%-40s\t%s\n", "0.0", "mysql-0", "mysql", "command",
+			"flush tables with read lock (background)", "Ready") +
+		fmt.Sprintf("%-8s\t%-30s\t%-20s\t%-8s\t%-40s\t%s\n", "0.0", "mysql-1", "mysql", "command",
+			"flush tables with read lock (background)", "Error: pod is in Pending phase") +
+		fmt.Sprintf("%-8s\t%-30s\t%-20s\t%-8s\t%-40s\t%s\n", "0.0", "mysql-2", "mysql", "command",
+			"flush tables with read lock (background)", "Error: container mysql not found in pod") +
+		fmt.Sprintf("%-8s\t%-30s\t%-20s\t%-8s\t%-40s\t%s\n", "0.1", "mysql-0", "-", "http",
+			"POST http://10.0.0.1:8080/quiesce", "Ready")
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
+func TestTestRulePodNamespace(t *testing.T) {
+	defer resetTest()
+	createTestRule(t, "testrule")
+	setTestRuleResponse(t, "testrule", "other")
+
+	cmdArgs := []string{"test", "rule", "-n", "test", "--pod-namespace", "other", "testrule"}
+	expected := "Rule: test/testrule (preExecRule)\n" +
+		"Pod namespace: other\n" +
+		"Warning: None of the pods matched selectors for rule item 0: map[app:mysql]\n" +
+		"Warning: None of the pods matched selectors for rule item 1: map[app:postgres]\n" +
+		fmt.Sprintf("%-8s\t%-30s\t%-20s\t%-8s\t%-40s\t%s\n", "ACTION", "POD", "CONTAINER", "TYPE", "RUN", "STATUS")
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
+func TestTestRuleExecute(t *testing.T) {
+	defer resetTest()
+	createTestRule(t, "testrule")
+	createTestRulePod(t, "mysql-1", v1.PodPending, "mysql")
+
+	// The rule is tested by storkctl without going through stork
+	fakeKubeClient, ok := testFactory.kubeClient.(*kubernetes.Clientset)
+	require.True(t, ok, "Unexpected kubernetes client type")
+	fakeKubeClient.PrependProxyReactor("services", func(action k8stesting.Action) (bool, restclient.ResponseWrapper, error) {
+		require.Fail(t, "Rule shouldn't be executed through stork")
+		return true, nil, nil
+	})
+
+	cmdArgs := []string{"test", "rule", "-n", "test", "--execute", "testrule"}
+	expected := "Rule: test/testrule (preExecRule)\n" +
+		"Warning: None of the pods matched selectors for rule item 1: map[app:postgres]\n" +
+		fmt.Sprintf("%-8s\t%-30s\t%-20s\t%-8s\t%-40s\t%s\n", "ACTION", "POD", "CONTAINER", "TYPE", "RUN", "STATUS") +
+		fmt.Sprintf("%-8s\t%-30s\t%-20s\t%-8s\t%-40s\t%s\n", "0.0", "mysql-1", "mysql", "command",
+			"flush tables with read lock (background)", "Error: pod is in Pending phase") +
+		fmt.Sprintf("%-8s\t%-30s\t%-20s\t%-8s\t%-40s\t%s\n", "0.1", "mysql-1", "-", "http",
+			"POST http://10.0.0.1:8080/quiesce", "Error: pod is in Pending phase")
+	testCommon(t, cmdArgs, nil, expected, false)
+}
@@ -2,8 +2,10 @@ package storkctl
 
 import (
 	"fmt"
+	"time"
 
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/schedule"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/spf13/cobra"
 	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
@@ -16,7 +18,10 @@ const (
 	schedulePolicySubcommand = "schedulepolicy"
 )
 
-var schedulePolicyColumns = []string{"NAME", "INTERVAL-MINUTES", "DAILY", "WEEKLY", "MONTHLY"}
+var schedulePolicyColumns = []string{"NAME", "INTERVAL-MINUTES", "DAILY", "WEEKLY", "MONTHLY", "CRON", "TIMEZONE", "NEXT-TRIGGER"}
+
+// currentTime is used to calculate the next trigger times, replaced in tests
+var currentTime = time.Now
 
 func newGetSchedulePolicyCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
 	var err error
@@ -108,12 +113,40 @@ func schedulePolicyPrinter(
 			}
 		}
 
+		cron := notConfiguredString
+		if schedulePolicy.Policy.Cron != nil {
+			if _, err := schedule.ParseCronExpression(schedulePolicy.Policy.Cron.Expression); err == nil {
+				cron = schedulePolicy.Policy.Cron.Expression
+			} else {
+				cron = invalidString
+			}
+		}
+
+		timezone := notConfiguredString
+		if schedulePolicy.Policy.Timezone != "" {
+			if _, err := schedulePolicy.Policy.GetLocation(); err == nil {
+				timezone = schedulePolicy.Policy.Timezone
+			} else {
+				timezone = invalidString
+			}
+		}
+
+		nextTrigger := notConfiguredString
+		if next, err := schedule.NextTriggerTime(&schedulePolicy, currentTime()); err != nil {
+			nextTrigger = invalidString
+		} else if !next.IsZero() {
+			nextTrigger = toTimeString(next)
+		}
+
 		row := getRow(&schedulePolicy,
 			[]interface{}{schedulePolicy.Name,
 				interval,
 				daily,
 				weekly,
-				monthly},
+				monthly,
+				cron,
+				timezone,
+				nextTrigger},
 		)
 		rows = append(rows, row)
 	}
@@ -5,6 +5,7 @@ package storkctl
 
 import (
 	"testing"
+	"time"
 
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
@@ -12,6 +13,24 @@ import (
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+func init() {
+	// Use a fixed time for the next trigger times
+	currentTime = func() time.Time {
+		// Tuesday
+		return time.Date(2021, time.June, 1, 10, 0, 0, 0, time.UTC)
+	}
+}
+
+// useUTC makes UTC the local time zone, which policies without a timezone
+// are triggered in, until the test is done
+func useUTC(t *testing.T) {
+	local := time.Local
+	time.Local = time.UTC
+	t.Cleanup(func() {
+		time.Local = local
+	})
+}
+
 func TestNoSchedulePolicy(t *testing.T) {
 	cmdArgs := []string{"get", "schedulepolicy"}
 
@@ -36,13 +55,14 @@ func TestSchedulePolicyNotFound(t *testing.T) {
 	expected = `Error from server (NotFound): schedulepolicies.stork.libopenstorage.org "testpolicy" not found`
 	testCommon(t, cmdArgs, nil, expected, true)
 
-	expected = "NAME          INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY\n" +
-		"testpolicy1   N/A                N/A     N/A      N/A\n"
+	expected = "NAME          INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY   CRON   TIMEZONE   NEXT-TRIGGER\n" +
+		"testpolicy1   N/A                N/A     N/A      N/A       N/A    N/A        N/A\n"
 	cmdArgs = []string{"get", "schedulepolicy", "testpolicy1"}
 	testCommon(t, cmdArgs, nil, expected, false)
 }
 
 func TestIntervalSchedulePolicy(t *testing.T) {
+	useUTC(t)
 	defer resetTest()
 
 	schedulePolicy := &storkv1.SchedulePolicy{
@@ -59,8 +79,8 @@ func TestIntervalSchedulePolicy(t *testing.T) {
 	_, err := storkops.Instance().CreateSchedulePolicy(schedulePolicy)
 	require.NoError(t, err, "Error creating schedulepolicy")
 
-	expected := "NAME             INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY\n" +
-		"intervalpolicy   Invalid            N/A     N/A      N/A\n"
+	expected := "NAME             INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY   CRON   TIMEZONE   NEXT-TRIGGER\n" +
+		"intervalpolicy   Invalid            N/A     N/A      N/A       N/A    N/A        Invalid\n"
 	cmdArgs := []string{"get", "schedulepolicy", "intervalpolicy"}
 	testCommon(t, cmdArgs, nil, expected, false)
 
@@ -69,12 +89,13 @@ func TestIntervalSchedulePolicy(t *testing.T) {
 	_, err = storkops.Instance().UpdateSchedulePolicy(schedulePolicy)
 	require.NoError(t, err, "Error creating schedulepolicy")
 
-	expected = "NAME             INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY\n" +
-		"intervalpolicy   60                 N/A     N/A      N/A\n"
+	expected = "NAME             INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY   CRON   TIMEZONE   NEXT-TRIGGER\n" +
+		"intervalpolicy   60                 N/A     N/A      N/A       N/A    N/A        N/A\n"
 	testCommon(t, cmdArgs, nil, expected, false)
 }
 
 func TestDailySchedulePolicy(t *testing.T) {
+	useUTC(t)
 	defer resetTest()
 
 	schedulePolicy := &storkv1.SchedulePolicy{
@@ -91,8 +112,8 @@ func TestDailySchedulePolicy(t *testing.T) {
 	_, err := storkops.Instance().CreateSchedulePolicy(schedulePolicy)
 	require.NoError(t, err, "Error creating schedulepolicy")
 
-	expected := "NAME          INTERVAL-MINUTES   DAILY     WEEKLY   MONTHLY\n" +
-		"dailypolicy   N/A                Invalid   N/A      N/A\n"
+	expected := "NAME          INTERVAL-MINUTES   DAILY     WEEKLY   MONTHLY   CRON   TIMEZONE   NEXT-TRIGGER\n" +
+		"dailypolicy   N/A                Invalid   N/A      N/A       N/A    N/A        Invalid\n"
 	cmdArgs := []string{"get", "schedulepolicy", "dailypolicy"}
 	testCommon(t, cmdArgs, nil, expected, false)
 
@@ -105,12 +126,13 @@ func TestDailySchedulePolicy(t *testing.T) {
 	_, err = storkops.Instance().UpdateSchedulePolicy(schedulePolicy)
 	require.NoError(t, err, "Error creating schedulepolicy")
 
-	expected = "NAME          INTERVAL-MINUTES   DAILY     WEEKLY   MONTHLY\n" +
-		"dailypolicy   N/A                12:15pm   N/A      N/A\n"
+	expected = "NAME          INTERVAL-MINUTES   DAILY     WEEKLY   MONTHLY   CRON   TIMEZONE   NEXT-TRIGGER\n" +
+		"dailypolicy   N/A                12:15pm   N/A      N/A       N/A    N/A        01 Jun 21 12:15 UTC\n"
 	testCommon(t, cmdArgs, nil, expected, false)
 }
 
 func TestWeeklySchedulePolicy(t *testing.T) {
+	useUTC(t)
 	defer resetTest()
 
 	schedulePolicy := &storkv1.SchedulePolicy{
@@ -128,8 +150,8 @@ func TestWeeklySchedulePolicy(t *testing.T) {
 	_, err := storkops.Instance().CreateSchedulePolicy(schedulePolicy)
 	require.NoError(t, err, "Error creating schedulepolicy")
 
-	expected := "NAME           INTERVAL-MINUTES   DAILY   WEEKLY    MONTHLY\n" +
-		"weeklypolicy   N/A                N/A     Invalid   N/A\n"
+	expected := "NAME           INTERVAL-MINUTES   DAILY   WEEKLY    MONTHLY   CRON   TIMEZONE   NEXT-TRIGGER\n" +
+		"weeklypolicy   N/A                N/A     Invalid   N/A       N/A    N/A        Invalid\n"
 	cmdArgs := []string{"get", "schedulepolicy", "weeklypolicy"}
 	testCommon(t, cmdArgs, nil, expected, false)
 
@@ -144,12 +166,13 @@ func TestWeeklySchedulePolicy(t *testing.T) {
 	schedulePolicy.Policy.Weekly.Time = "12:15pm"
 	_, err = storkops.Instance().UpdateSchedulePolicy(schedulePolicy)
 	require.NoError(t, err, "Error creating schedulepolicy")
-	expected = "NAME           INTERVAL-MINUTES   DAILY   WEEKLY        MONTHLY\n" +
-		"weeklypolicy   N/A                N/A     Sun@12:15pm   N/A\n"
+	expected = "NAME           INTERVAL-MINUTES   DAILY   WEEKLY        MONTHLY   CRON   TIMEZONE   NEXT-TRIGGER\n" +
+		"weeklypolicy   N/A                N/A     Sun@12:15pm   N/A       N/A    N/A        06 Jun 21 12:15 UTC\n"
 	testCommon(t, cmdArgs, nil, expected, false)
 }
 
 func TestMonthlySchedulePolicy(t *testing.T) {
+	useUTC(t)
 	defer resetTest()
 
 	schedulePolicy := &storkv1.SchedulePolicy{
@@ -167,8 +190,8 @@ func TestMonthlySchedulePolicy(t *testing.T) {
 	_, err := storkops.Instance().CreateSchedulePolicy(schedulePolicy)
 	require.NoError(t, err, "Error creating schedulepolicy")
 
-	expected := "NAME            INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY\n" +
-		"monthlypolicy   N/A                N/A     N/A      Invalid\n"
+	expected := "NAME            INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY   CRON   TIMEZONE   NEXT-TRIGGER\n" +
+		"monthlypolicy   N/A                N/A     N/A      Invalid   N/A    N/A        Invalid\n"
 	cmdArgs := []string{"get", "schedulepolicy", "monthlypolicy"}
 	testCommon(t, cmdArgs, nil, expected, false)
 
@@ -183,7 +206,56 @@ func TestMonthlySchedulePolicy(t *testing.T) {
 	schedulePolicy.Policy.Monthly.Time = "12:15pm"
 	_, err = storkops.Instance().UpdateSchedulePolicy(schedulePolicy)
 	require.NoError(t, err, "Error creating schedulepolicy")
-	expected = "NAME            INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY\n" +
-		"monthlypolicy   N/A                N/A     N/A      15@12:15pm\n"
+	expected = "NAME            INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY      CRON   TIMEZONE   NEXT-TRIGGER\n" +
+		"monthlypolicy   N/A                N/A     N/A      15@12:15pm   N/A    N/A        15 Jun 21 12:15 UTC\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
+func TestCronSchedulePolicy(t *testing.T) {
+	defer resetTest()
+
+	schedulePolicy := &storkv1.SchedulePolicy{
+		ObjectMeta: meta.ObjectMeta{
+			Name: "cronpolicy",
+		},
+		Policy: storkv1.SchedulePolicyItem{
+			Cron: &storkv1.CronPolicy{
+				//Invalid hour
+				Expression: "30 25 * * 1-5",
+			},
+			Timezone: "Europe/Berlin",
+		},
+	}
+	_, err := storkops.Instance().CreateSchedulePolicy(schedulePolicy)
+	require.NoError(t, err, "Error creating schedulepolicy")
+
+	expected := "NAME         INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY   CRON      TIMEZONE        NEXT-TRIGGER\n" +
+		"cronpolicy   N/A                N/A     N/A      N/A       Invalid   Europe/Berlin   Invalid\n"
+	cmdArgs := []string{"get", "schedulepolicy", "cronpolicy"}
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	// Update with valid expression but invalid timezone
+	schedulePolicy.Policy.Cron.Expression = "30 2 * * 1-5"
+	schedulePolicy.Policy.Timezone = "Europe/Nowhere"
+	_, err = storkops.Instance().UpdateSchedulePolicy(schedulePolicy)
+	require.NoError(t, err, "Error updating schedulepolicy")
+	expected = "NAME         INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY   CRON           TIMEZONE   NEXT-TRIGGER\n" +
+		"cronpolicy   N/A                N/A     N/A      N/A       30 2 * * 1-5   Invalid    Invalid\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	// Update with valid timezone, the next trigger is on Wednesday in Berlin
+	schedulePolicy.Policy.Timezone = "Europe/Berlin"
+	_, err = storkops.Instance().UpdateSchedulePolicy(schedulePolicy)
+	require.NoError(t, err, "Error updating schedulepolicy")
+	expected = "NAME         INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY   CRON           TIMEZONE        NEXT-TRIGGER\n" +
+		"cronpolicy   N/A                N/A     N/A      N/A       30 2 * * 1-5   Europe/Berlin   02 Jun 21 02:30 CEST\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	// First Sunday of the month
+	schedulePolicy.Policy.Cron.Expression = "0 0 * * SUN#1"
+	_, err = storkops.Instance().UpdateSchedulePolicy(schedulePolicy)
+	require.NoError(t, err, "Error updating schedulepolicy")
+	expected = "NAME         INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY   CRON            TIMEZONE        NEXT-TRIGGER\n" +
+		"cronpolicy   N/A                N/A     N/A      N/A       0 0 * * SUN#1   Europe/Berlin   06 Jun 21 00:00 CEST\n"
 	testCommon(t, cmdArgs, nil, expected, false)
 }
@@ -0,0 +1,125 @@
+package storkctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/libopenstorage/stork/pkg/extender"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/cmd/util"
+)
+
+const (
+	schedulingSubcommand = "scheduling"
+
+	defaultExtenderService          = "stork-service"
+	defaultExtenderServiceNamespace = "kube-system"
+	defaultExtenderServicePort      = "8099"
+)
+
+func newExplainSchedulingCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var service string
+	var serviceNamespace string
+	var servicePort string
+
+	explainSchedulingCommand := &cobra.Command{
+		Use:   schedulingSubcommand + " <pod>",
+		Short: "Explain how stork filters and scores the nodes for a pod",
+		Long: "Runs the scheduler extender's filter and prioritize logic for a pod without any side effects " +
+			"and prints the verdict for each node. The scheduler's own predicates and priorities aren't included.",
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) != 1 {
+				util.CheckErr(fmt.Errorf("exactly one name needs to be provided for pod name"))
+				return
+			}
+			outputFormat, err := cmdFactory.GetOutputFormat()
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			client, err := cmdFactory.GetKubernetesClient()
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			params := map[string]string{
+				"pod":       args[0],
+				"namespace": cmdFactory.GetNamespace(),
+			}
+			data, err := client.CoreV1().Services(serviceNamespace).
+				ProxyGet("http", service, servicePort, extender.ExplainPath, params).
+				DoRaw(context.TODO())
+			if err != nil {
+				util.CheckErr(fmt.Errorf("error explaining scheduling for pod %v: %v", args[0], err))
+				return
+			}
+			explanation := &extender.SchedulingExplanation{}
+			if err := json.Unmarshal(data, explanation); err != nil {
+				util.CheckErr(fmt.Errorf("error parsing response from scheduler extender: %v", err))
+				return
+			}
+			if err := printSchedulingExplanation(explanation, outputFormat, ioStreams.Out); err != nil {
+				util.CheckErr(err)
+				return
+			}
+		},
+	}
+	explainSchedulingCommand.Flags().StringVar(&service, "service", defaultExtenderService, "Name of the service for the stork scheduler extender")
+	explainSchedulingCommand.Flags().StringVar(&serviceNamespace, "service-namespace", defaultExtenderServiceNamespace, "Namespace of the service for the stork scheduler extender")
+	explainSchedulingCommand.Flags().StringVar(&servicePort, "service-port", defaultExtenderServicePort, "Port of the service for the stork scheduler extender")
+
+	return explainSchedulingCommand
+}
+
+func printSchedulingExplanation(explanation *extender.SchedulingExplanation, outputFormat string, out io.Writer) error {
+	if printed, err := printJSONOrYaml(explanation, outputFormat, out); printed || err != nil {
+		return err
+	}
+
+	printMsg(fmt.Sprintf("Pod: %v/%v", explanation.Namespace, explanation.Pod), out)
+	if explanation.ScoringPolicy != "" {
+		printMsg(fmt.Sprintf("Scoring policy: %v", explanation.ScoringPolicy), out)
+	}
+	if explanation.FilterError != "" {
+		printMsg(fmt.Sprintf("Filter error: %v", explanation.FilterError), out)
+	}
+	if explanation.PrioritizeError != "" {
+		printMsg(fmt.Sprintf("Prioritize error: %v", explanation.PrioritizeError), out)
+	}
+	for _, msg := range explanation.Messages {
+		printMsg(fmt.Sprintf("Message: %v", msg), out)
+	}
+
+	printMsg(fmt.Sprintf("%-30s\t%-8s\t%-20s\t%-16s\t%-12s\t%s", "NODE", "SCORE", "LOCALITY", "DEGRADED PENALTY", "UTILIZATION", "REASON"), out)
+	for _, node := range explanation.Nodes {
+		if node.FilterReason != "" || explanation.FilterError != "" {
+			reason := node.FilterReason
+			if reason == "" {
+				reason = explanation.FilterError
+			}
+			printMsg(fmt.Sprintf("%-30s\t%-8s\t%-20s\t%-16s\t%-12s\t%s", node.Name, "-", "-", "-", "-", "Filtered out: "+reason), out)
+			continue
+		}
+		localities := make([]string, 0)
+		penalty := 0.0
+		for _, volume := range node.Volumes {
+			localities = append(localities, string(volume.Locality))
+			penalty += volume.DegradedPenalty
+		}
+		locality := strings.Join(localities, ",")
+		if locality == "" {
+			locality = "-"
+		}
+		reason := ""
+		if node.DefaultScore {
+			reason = "Default score"
+		}
+		printMsg(fmt.Sprintf("%-30s\t%-8v\t%-20s\t%-16v\t%-12.2f\t%s",
+			node.Name, node.Score, locality, penalty, node.UtilizationFactor, reason), out)
+	}
+	return nil
+}
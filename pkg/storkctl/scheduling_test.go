@@ -0,0 +1,150 @@
+//go:build unittest
+// +build unittest
+
+package storkctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/libopenstorage/stork/pkg/extender"
+	"github.com/stretchr/testify/require"
+	kubernetes "k8s.io/client-go/kubernetes/fake"
+	restclient "k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+type fakeProxyResponse struct {
+	data []byte
+	err  error
+}
+
+func (f *fakeProxyResponse) DoRaw(context.Context) ([]byte, error) {
+	return f.data, f.err
+}
+
+func (f *fakeProxyResponse) Stream(context.Context) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func setExplainResponse(t *testing.T, explanation *extender.SchedulingExplanation, err error) {
+	data, marshalErr := json.Marshal(explanation)
+	require.NoError(t, marshalErr)
+	fakeKubeClient, ok := testFactory.kubeClient.(*kubernetes.Clientset)
+	require.True(t, ok, "Unexpected kubernetes client type")
+	fakeKubeClient.PrependProxyReactor("services", func(action k8stesting.Action) (bool, restclient.ResponseWrapper, error) {
+		proxyAction := action.(k8stesting.ProxyGetAction)
+		require.Equal(t, "stork-service", proxyAction.GetName())
+		require.Equal(t, "kube-system", proxyAction.GetNamespace())
+		require.Equal(t, extender.ExplainPath, proxyAction.GetPath())
+		require.Equal(t, explanation.Pod, proxyAction.GetParams()["pod"])
+		return true, &fakeProxyResponse{data: data, err: err}, nil
+	})
+}
+
+func newTestSchedulingExplanation() *extender.SchedulingExplanation {
+	return &extender.SchedulingExplanation{
+		Pod:           "pod1",
+		Namespace:     "test",
+		ScoringPolicy: "default",
+		Messages:      []string{"Volume vol2 is skipped from scoring"},
+		Nodes: []*extender.NodeExplanation{
+			{
+				Name: "node1",
+				Volumes: []*extender.VolumeExplanation{
+					{Volume: "vol1", Locality: extender.LocalityMatchNode, Score: 100},
+				},
+				UtilizationFactor: 0.5,
+				Score:             50,
+			},
+			{
+				Name: "node2",
+				Volumes: []*extender.VolumeExplanation{
+					{Volume: "vol1", Locality: extender.LocalityMatchNode, DegradedPenalty: 75, Score: 25},
+				},
+				UtilizationFactor: 1,
+				Score:             25,
+			},
+			{
+				Name: "node3",
+				Volumes: []*extender.VolumeExplanation{
+					{Volume: "vol1", Locality: extender.LocalityMatchNone},
+				},
+				UtilizationFactor: 1,
+				DefaultScore:      true,
+				Score:             5,
+			},
+			{
+				Name:              "node4",
+				FilterReason:      "Storage driver is Offline on the node",
+				UtilizationFactor: 1,
+			},
+		},
+	}
+}
+
+func TestExplainSchedulingNoPod(t *testing.T) {
+	cmdArgs := []string{"explain", "scheduling"}
+
+	expected := "error: exactly one name needs to be provided for pod name"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestExplainScheduling(t *testing.T) {
+	defer resetTest()
+	setExplainResponse(t, newTestSchedulingExplanation(), nil)
+	cmdArgs := []string{"explain", "scheduling", "pod1", "-n", "test"}
+
+	expected := "Pod: test/pod1\n" +
+		"Scoring policy: default\n" +
+		"Message: Volume vol2 is skipped from scoring\n" +
+		"NODE                          \tSCORE   \tLOCALITY            \tDEGRADED PENALTY\tUTILIZATION \tREASON\n" +
+		"node1                         \t50      \tnode                \t0               \t0.50        \t\n" +
+		"node2                         \t25      \tnode                \t75              \t1.00        \t\n" +
+		"node3                         \t5       \tnone                \t0               \t1.00        \tDefault score\n" +
+		"node4                         \t-       \t-                   \t-               \t-           \tFiltered out: Storage driver is Offline on the node\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
+func TestExplainSchedulingJSON(t *testing.T) {
+	defer resetTest()
+	explanation := newTestSchedulingExplanation()
+	setExplainResponse(t, explanation, nil)
+	cmdArgs := []string{"explain", "scheduling", "pod1", "-n", "test", "-o", "json"}
+
+	data, err := json.MarshalIndent(explanation, "", "    ")
+	require.NoError(t, err)
+	testCommon(t, cmdArgs, nil, string(data)+"\n", false)
+}
+
+func TestExplainSchedulingFilterError(t *testing.T) {
+	defer resetTest()
+	explanation := &extender.SchedulingExplanation{
+		Pod:         "pod1",
+		Namespace:   "test",
+		FilterError: "No online node found with volume replica",
+		Nodes: []*extender.NodeExplanation{
+			{Name: "node1", UtilizationFactor: 1},
+		},
+	}
+	setExplainResponse(t, explanation, nil)
+	cmdArgs := []string{"explain", "scheduling", "pod1", "-n", "test"}
+
+	expected := "Pod: test/pod1\n" +
+		"Filter error: No online node found with volume replica\n" +
+		"NODE                          \tSCORE   \tLOCALITY            \tDEGRADED PENALTY\tUTILIZATION \tREASON\n" +
+		"node1                         \t-       \t-                   \t-               \t-           \tFiltered out: No online node found with volume replica\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
+func TestExplainSchedulingExtenderError(t *testing.T) {
+	defer resetTest()
+	setExplainResponse(t, &extender.SchedulingExplanation{Pod: "pod1"}, fmt.Errorf("pod not found"))
+	cmdArgs := []string{"explain", "scheduling", "pod1", "-n", "test"}
+
+	expected := "error: error explaining scheduling for pod pod1: pod not found"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
@@ -36,6 +36,10 @@ func NewCommand(cmdFactory Factory, in io.Reader, out io.Writer, errOut io.Write
 		newGenerateCommand(cmdFactory, ioStreams),
 		newSuspendCommand(cmdFactory, ioStreams),
 		newResumeCommand(cmdFactory, ioStreams),
+		newRewrapCommand(cmdFactory, ioStreams),
+		newVerifyCommand(cmdFactory, ioStreams),
+		newTestCommand(cmdFactory, ioStreams),
+		newExplainCommand(cmdFactory, ioStreams),
 		newVersionCommand(cmdFactory, ioStreams),
 	)
 
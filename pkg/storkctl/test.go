@@ -0,0 +1,19 @@
+package storkctl
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func newTestCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	testCommands := &cobra.Command{
+		Use:   "test",
+		Short: "Test resources before they are used",
+	}
+
+	testCommands.AddCommand(
+		newTestRuleCommand(cmdFactory, ioStreams),
+	)
+
+	return testCommands
+}
@@ -2,6 +2,7 @@ package webhookadmission
 
 import (
 	"bytes"
+	"context"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
@@ -12,6 +13,7 @@ import (
 	"math/big"
 	"time"
 
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	"github.com/libopenstorage/stork/pkg/version"
 	"github.com/portworx/sched-ops/k8s/admissionregistration"
 	"github.com/portworx/sched-ops/k8s/core"
@@ -21,6 +23,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 const (
@@ -31,7 +34,8 @@ const (
 )
 
 var (
-	webhookPath = "/mutate"
+	webhookPath         = "/mutate"
+	validateWebhookPath = "/validate"
 )
 
 // CreateMutateWebhook create new webhookconfig for stork if not exist already
@@ -217,3 +221,84 @@ func createWebhookV1(caBundle []byte, ns string) error {
 	log.Debugf("stork webhook v1 configured: %v", webhookName)
 	return nil
 }
+
+// CreateValidateWebhook creates the webhookconfig to validate stork resources
+// when they are created or updated. The admission v1 API is required for
+// validation, so it is only registered on clusters that support it.
+func CreateValidateWebhook(client kubernetes.Interface, caBundle []byte, ns string) error {
+	ok, err := version.RequiresV1Registration()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		log.Infof("Skipping registration of %v, admission v1 is not supported", validatingWebhookName)
+		return nil
+	}
+
+	// Reject invalid specs, but don't block creation of resources if stork
+	// isn't available
+	sideEffect := admissionv1.SideEffectClassNone
+	failurePolicy := admissionv1.Ignore
+	matchPolicy := admissionv1.Equivalent
+	webhook := admissionv1.ValidatingWebhook{
+		Name: validatingWebhookName,
+		ClientConfig: admissionv1.WebhookClientConfig{
+			Service: &admissionv1.ServiceReference{
+				Name:      storkService,
+				Namespace: ns,
+				Path:      &validateWebhookPath,
+			},
+			CABundle: caBundle,
+		},
+		Rules: []admissionv1.RuleWithOperations{
+			{
+				Operations: []admissionv1.OperationType{admissionv1.Create, admissionv1.Update},
+				Rule: admissionv1.Rule{
+					APIGroups:   []string{stork_api.SchemeGroupVersion.Group},
+					APIVersions: []string{stork_api.SchemeGroupVersion.Version},
+					Resources: []string{
+						stork_api.ApplicationBackupResourcePlural,
+						stork_api.MigrationResourcePlural,
+						stork_api.SchedulePolicyResourcePlural,
+						stork_api.NamespacedSchedulePolicyResourcePlural,
+						"rules",
+						stork_api.ResourceTransformationResourcePlural,
+					},
+				},
+			},
+		},
+		SideEffects:             &sideEffect,
+		FailurePolicy:           &failurePolicy,
+		AdmissionReviewVersions: []string{"v1"},
+		MatchPolicy:             &matchPolicy,
+	}
+	req := &admissionv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: storkValidatingAdmissionController,
+		},
+		Webhooks: []admissionv1.ValidatingWebhook{webhook},
+	}
+
+	// recreate webhook
+	err = client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Delete(context.TODO(), storkValidatingAdmissionController, metav1.DeleteOptions{})
+	if err != nil && !k8serr.IsNotFound(err) {
+		return err
+	}
+	if _, err := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Create(context.TODO(), req, metav1.CreateOptions{}); err != nil {
+		log.Errorf("unable to create validating webhook configuration: %v", err)
+		return err
+	}
+	log.Debugf("stork validating webhook v1 configured: %v", validatingWebhookName)
+	return nil
+}
+
+// DeleteValidateWebhook deletes the webhookconfig validating stork resources.
+// ValidatingWebhookConfigurations aren't supported by sched-ops, so the
+// client-go client is used directly.
+func DeleteValidateWebhook(client kubernetes.Interface) error {
+	err := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Delete(context.TODO(), storkValidatingAdmissionController, metav1.DeleteOptions{})
+	if err != nil && !k8serr.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
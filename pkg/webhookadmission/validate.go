@@ -0,0 +1,224 @@
+package webhookadmission
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/resourcecollector"
+	"github.com/libopenstorage/stork/pkg/rule"
+	"github.com/libopenstorage/stork/pkg/schedule"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	log "github.com/sirupsen/logrus"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	validatingWebhookName              = "validate.stork.libopenstorage.org"
+	storkValidatingAdmissionController = "stork-validating-webhooks-cfg"
+)
+
+func (c *Controller) processValidateRequest(w http.ResponseWriter, req *http.Request) {
+	admissionReview := admissionv1.AdmissionReview{}
+	webhookConfig := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: storkValidatingAdmissionController,
+		},
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	defer func() {
+		if err := req.Body.Close(); err != nil {
+			log.Warnf("Error closing decoder")
+		}
+	}()
+	if err := decoder.Decode(&admissionReview); err != nil || admissionReview.Request == nil {
+		log.Errorf("Error decoding admission review request: %v", err)
+		if err != nil {
+			c.Recorder.Event(webhookConfig, v1.EventTypeWarning, "invalid admission review request", err.Error())
+		}
+		http.Error(w, "Decode error", http.StatusBadRequest)
+		return
+	}
+
+	skipHookAnnotation := defaultSkipAnnotation
+	if c.SkipResource != "" {
+		skipHookAnnotation = c.SkipResource
+	}
+	arReq := admissionReview.Request
+	admissionResponse := &admissionv1.AdmissionResponse{
+		UID:     arReq.UID,
+		Allowed: true,
+	}
+	adminNamespace := c.AdminNamespace
+	if adminNamespace == "" {
+		adminNamespace = defaultNamespace
+	}
+	if err := validateResource(arReq, skipHookAnnotation, adminNamespace); err != nil {
+		log.Infof("Rejecting %v %v/%v: %v", arReq.Kind.Kind, arReq.Namespace, arReq.Name, err)
+		admissionResponse.Allowed = false
+		admissionResponse.Result = &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Message: err.Error(),
+			Reason:  metav1.StatusReasonInvalid,
+			Code:    http.StatusUnprocessableEntity,
+		}
+	}
+
+	admissionReview.Response = admissionResponse
+	resp, err := json.Marshal(admissionReview)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("could not marshal response: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if _, err := w.Write(resp); err != nil {
+		http.Error(w, fmt.Sprintf("could not write http response: %v", err), http.StatusInternalServerError)
+	}
+}
+
+// validateResource validates the spec of a stork resource and checks that the
+// resources referenced by it exist
+func validateResource(arReq *admissionv1.AdmissionRequest, skipHookAnnotation string, adminNamespace string) error {
+	if arReq.Operation == admissionv1.Update {
+		unchanged, err := isSpecUnchanged(arReq)
+		if err != nil {
+			return err
+		}
+		// Updates of the metadata and status by the controllers shouldn't
+		// fail if a referenced resource has been removed since the
+		// resource was created
+		if unchanged {
+			return nil
+		}
+	}
+
+	switch arReq.Kind.Kind {
+	case "ApplicationBackup":
+		var backup stork_api.ApplicationBackup
+		if err := json.Unmarshal(arReq.Object.Raw, &backup); err != nil {
+			return fmt.Errorf("could not unmarshal ApplicationBackup: %v", err)
+		}
+		if skipSchedulerUpdate(skipHookAnnotation, backup.Annotations) {
+			return nil
+		}
+		return validateApplicationBackup(&backup, arReq.Namespace)
+	case "Migration":
+		var migration stork_api.Migration
+		if err := json.Unmarshal(arReq.Object.Raw, &migration); err != nil {
+			return fmt.Errorf("could not unmarshal Migration: %v", err)
+		}
+		if skipSchedulerUpdate(skipHookAnnotation, migration.Annotations) {
+			return nil
+		}
+		return validateMigration(&migration, arReq.Namespace, adminNamespace)
+	case "SchedulePolicy", "NamespacedSchedulePolicy":
+		var policy stork_api.SchedulePolicy
+		if err := json.Unmarshal(arReq.Object.Raw, &policy); err != nil {
+			return fmt.Errorf("could not unmarshal %v: %v", arReq.Kind.Kind, err)
+		}
+		if skipSchedulerUpdate(skipHookAnnotation, policy.Annotations) {
+			return nil
+		}
+		if err := schedule.ValidateSchedulePolicy(&policy); err != nil {
+			return fmt.Errorf("invalid schedule policy: %v", err)
+		}
+	case "Rule":
+		var r stork_api.Rule
+		if err := json.Unmarshal(arReq.Object.Raw, &r); err != nil {
+			return fmt.Errorf("could not unmarshal Rule: %v", err)
+		}
+		if skipSchedulerUpdate(skipHookAnnotation, r.Annotations) {
+			return nil
+		}
+		// The type of the rule is only known once it is used, so only
+		// validate what is common to all rules
+		return rule.ValidateRule(&r, rule.PreExecRule)
+	case "ResourceTransformation":
+		var transform stork_api.ResourceTransformation
+		if err := json.Unmarshal(arReq.Object.Raw, &transform); err != nil {
+			return fmt.Errorf("could not unmarshal ResourceTransformation: %v", err)
+		}
+		if skipSchedulerUpdate(skipHookAnnotation, transform.Annotations) {
+			return nil
+		}
+		if err := resourcecollector.ValidateTransformSpecs(transform.Spec.Objects); err != nil {
+			return fmt.Errorf("invalid resource transformation: %v", err)
+		}
+	}
+	return nil
+}
+
+func validateApplicationBackup(backup *stork_api.ApplicationBackup, namespace string) error {
+	if backup.Spec.BackupLocation == "" {
+		return fmt.Errorf("backupLocation is required")
+	}
+	if _, err := storkops.Instance().GetBackupLocation(backup.Spec.BackupLocation, namespace); err != nil {
+		return fmt.Errorf("error getting BackupLocation %v: %v", backup.Spec.BackupLocation, err)
+	}
+	return validateRuleReferences(backup.Spec.PreExecRule, backup.Spec.PostExecRule, namespace)
+}
+
+func validateMigration(migration *stork_api.Migration, namespace string, adminNamespace string) error {
+	if migration.Spec.ClusterPair == "" {
+		return fmt.Errorf("clusterPair is required")
+	}
+	if _, err := storkops.Instance().GetClusterPair(migration.Spec.ClusterPair, namespace); err != nil {
+		return fmt.Errorf("error getting ClusterPair %v: %v", migration.Spec.ClusterPair, err)
+	}
+	if migration.Spec.AdminClusterPair != "" {
+		if _, err := storkops.Instance().GetClusterPair(migration.Spec.AdminClusterPair, adminNamespace); err != nil {
+			return fmt.Errorf("error getting admin ClusterPair %v: %v", migration.Spec.AdminClusterPair, err)
+		}
+	}
+	if len(migration.Spec.TransformSpecs) > 1 {
+		return fmt.Errorf("providing multiple transformation specs is not supported: %v", migration.Spec.TransformSpecs)
+	}
+	return validateRuleReferences(migration.Spec.PreExecRule, migration.Spec.PostExecRule, namespace)
+}
+
+// isSpecUnchanged returns true if only the metadata or status of the object
+// are changed by the update
+func isSpecUnchanged(arReq *admissionv1.AdmissionRequest) (bool, error) {
+	var oldObject, newObject map[string]interface{}
+	if err := json.Unmarshal(arReq.OldObject.Raw, &oldObject); err != nil {
+		return false, fmt.Errorf("could not unmarshal old %v: %v", arReq.Kind.Kind, err)
+	}
+	if err := json.Unmarshal(arReq.Object.Raw, &newObject); err != nil {
+		return false, fmt.Errorf("could not unmarshal %v: %v", arReq.Kind.Kind, err)
+	}
+	for _, field := range []string{"metadata", "status"} {
+		delete(oldObject, field)
+		delete(newObject, field)
+	}
+	return reflect.DeepEqual(oldObject, newObject), nil
+}
+
+// validateRuleReferences checks that the pre and post exec rules exist and
+// are valid for their type
+func validateRuleReferences(preExecRule string, postExecRule string, namespace string) error {
+	rules := []struct {
+		name  string
+		rType rule.Type
+	}{
+		{preExecRule, rule.PreExecRule},
+		{postExecRule, rule.PostExecRule},
+	}
+	for _, r := range rules {
+		if r.name == "" {
+			continue
+		}
+		storkRule, err := storkops.Instance().GetRule(r.name, namespace)
+		if err != nil {
+			return fmt.Errorf("error getting %v %v: %v", r.rType, r.name, err)
+		}
+		if err := rule.ValidateRule(storkRule, r.rType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
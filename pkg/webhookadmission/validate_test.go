@@ -0,0 +1,242 @@
+//go:build unittest
+// +build unittest
+
+package webhookadmission
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	fakeclient "github.com/libopenstorage/stork/pkg/client/clientset/versioned/fake"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubernetes "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func setupValidateTest(t *testing.T) {
+	fakeStorkClient := fakeclient.NewSimpleClientset(
+		&stork_api.BackupLocation{
+			ObjectMeta: metav1.ObjectMeta{Name: "location", Namespace: "test"},
+			Location:   stork_api.BackupLocationItem{Type: stork_api.BackupLocationFile},
+		},
+		&stork_api.ClusterPair{
+			ObjectMeta: metav1.ObjectMeta{Name: "remote", Namespace: "test"},
+		},
+		&stork_api.ClusterPair{
+			ObjectMeta: metav1.ObjectMeta{Name: "admin-remote", Namespace: "admin"},
+		},
+		&stork_api.Rule{
+			ObjectMeta: metav1.ObjectMeta{Name: "freeze", Namespace: "test"},
+			Rules: []stork_api.RuleItem{{
+				Actions: []stork_api.RuleAction{{Type: stork_api.RuleActionCommand, Value: "fsfreeze", Background: true}},
+			}},
+		},
+		&stork_api.Rule{
+			ObjectMeta: metav1.ObjectMeta{Name: "sync", Namespace: "test"},
+			Rules: []stork_api.RuleItem{{
+				Actions: []stork_api.RuleAction{{Type: stork_api.RuleActionCommand, Value: "sync"}},
+			}},
+		},
+	)
+	storkops.SetInstance(storkops.New(kubernetes.NewSimpleClientset(), fakeStorkClient, nil))
+}
+
+// sendValidateRequest sends an admission review for the creation of the
+// object to the validating webhook and returns the response
+func sendValidateRequest(t *testing.T, kind string, obj runtime.Object) *admissionv1.AdmissionResponse {
+	return sendValidateUpdateRequest(t, kind, nil, obj)
+}
+
+// sendValidateUpdateRequest sends an admission review for the update of the
+// object to the validating webhook and returns the response. The object is
+// being created if oldObj is nil.
+func sendValidateUpdateRequest(t *testing.T, kind string, oldObj runtime.Object, obj runtime.Object) *admissionv1.AdmissionResponse {
+	raw, err := json.Marshal(obj)
+	require.NoError(t, err, "Error marshaling object")
+	review := admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request: &admissionv1.AdmissionRequest{
+			UID:       types.UID("uid"),
+			Kind:      metav1.GroupVersionKind{Group: stork_api.SchemeGroupVersion.Group, Version: "v1alpha1", Kind: kind},
+			Namespace: "test",
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+	if oldObj != nil {
+		oldRaw, err := json.Marshal(oldObj)
+		require.NoError(t, err, "Error marshaling old object")
+		review.Request.Operation = admissionv1.Update
+		review.Request.OldObject = runtime.RawExtension{Raw: oldRaw}
+	}
+	body, err := json.Marshal(review)
+	require.NoError(t, err, "Error marshaling admission review")
+
+	c := &Controller{Recorder: record.NewFakeRecorder(10), AdminNamespace: "admin"}
+	w := httptest.NewRecorder()
+	c.serveHTTP(w, httptest.NewRequest(http.MethodPost, validateWebHook, bytes.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code, "Unexpected status: %v", w.Body.String())
+
+	response := admissionv1.AdmissionReview{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), "Error decoding admission review response")
+	require.Equal(t, "AdmissionReview", response.Kind)
+	require.NotNil(t, response.Response)
+	require.Equal(t, types.UID("uid"), response.Response.UID)
+	return response.Response
+}
+
+func TestValidateApplicationBackup(t *testing.T) {
+	setupValidateTest(t)
+	backup := &stork_api.ApplicationBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "test"},
+		Spec: stork_api.ApplicationBackupSpec{
+			BackupLocation: "location",
+			PreExecRule:    "freeze",
+			PostExecRule:   "sync",
+		},
+	}
+	require.True(t, sendValidateRequest(t, "ApplicationBackup", backup).Allowed)
+
+	backup.Spec.BackupLocation = "missing"
+	response := sendValidateRequest(t, "ApplicationBackup", backup)
+	require.False(t, response.Allowed)
+	require.Contains(t, response.Result.Message, "error getting BackupLocation missing")
+
+	backup.Spec.BackupLocation = "location"
+	backup.Spec.PreExecRule = "missing"
+	require.False(t, sendValidateRequest(t, "ApplicationBackup", backup).Allowed)
+
+	// Background actions aren't supported for post exec rules
+	backup.Spec.PreExecRule = ""
+	backup.Spec.PostExecRule = "freeze"
+	require.False(t, sendValidateRequest(t, "ApplicationBackup", backup).Allowed)
+
+	// Validation can be disabled with the annotation
+	backup.Annotations = map[string]string{defaultSkipAnnotation: "true"}
+	require.True(t, sendValidateRequest(t, "ApplicationBackup", backup).Allowed)
+}
+
+func TestValidateMigration(t *testing.T) {
+	setupValidateTest(t)
+	migration := &stork_api.Migration{
+		ObjectMeta: metav1.ObjectMeta{Name: "migration", Namespace: "test"},
+		Spec: stork_api.MigrationSpec{
+			ClusterPair: "remote",
+			Namespaces:  []string{"test"},
+			PreExecRule: "freeze",
+		},
+	}
+	require.True(t, sendValidateRequest(t, "Migration", migration).Allowed)
+
+	migration.Spec.ClusterPair = ""
+	require.False(t, sendValidateRequest(t, "Migration", migration).Allowed)
+
+	migration.Spec.ClusterPair = "missing"
+	require.False(t, sendValidateRequest(t, "Migration", migration).Allowed)
+
+	migration.Spec.ClusterPair = "remote"
+	migration.Spec.TransformSpecs = []string{"a", "b"}
+	require.False(t, sendValidateRequest(t, "Migration", migration).Allowed)
+
+	// Admin ClusterPairs are looked up in the admin namespace
+	migration.Spec.TransformSpecs = nil
+	migration.Spec.AdminClusterPair = "admin-remote"
+	require.True(t, sendValidateRequest(t, "Migration", migration).Allowed)
+	migration.Spec.AdminClusterPair = "remote"
+	response := sendValidateRequest(t, "Migration", migration)
+	require.False(t, response.Allowed)
+	require.Contains(t, response.Result.Message, "error getting admin ClusterPair remote")
+}
+
+func TestValidateUpdate(t *testing.T) {
+	setupValidateTest(t)
+	migration := &stork_api.Migration{
+		ObjectMeta: metav1.ObjectMeta{Name: "migration", Namespace: "test"},
+		Spec: stork_api.MigrationSpec{
+			ClusterPair: "remote",
+			Namespaces:  []string{"test"},
+		},
+	}
+	updated := migration.DeepCopy()
+	updated.Spec.ClusterPair = "missing"
+	response := sendValidateUpdateRequest(t, "Migration", migration, updated)
+	require.False(t, response.Allowed)
+	require.Contains(t, response.Result.Message, "error getting ClusterPair missing")
+
+	// Updates of the status and metadata aren't validated again
+	migration.Spec.ClusterPair = "missing"
+	updated = migration.DeepCopy()
+	updated.Finalizers = []string{"finalizer"}
+	updated.Status.Status = stork_api.MigrationStatusSuccessful
+	require.True(t, sendValidateUpdateRequest(t, "Migration", migration, updated).Allowed)
+
+	rule := &stork_api.Rule{
+		ObjectMeta: metav1.ObjectMeta{Name: "rule", Namespace: "test"},
+		Rules: []stork_api.RuleItem{{
+			Actions: []stork_api.RuleAction{{Type: stork_api.RuleActionCommand, Value: "sync"}},
+		}},
+	}
+	updatedRule := rule.DeepCopy()
+	updatedRule.Rules[0].Actions[0].Type = "script"
+	require.False(t, sendValidateUpdateRequest(t, "Rule", rule, updatedRule).Allowed)
+}
+
+func TestValidateSpecs(t *testing.T) {
+	setupValidateTest(t)
+	policy := &stork_api.SchedulePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+		Policy: stork_api.SchedulePolicyItem{
+			Interval: &stork_api.IntervalPolicy{IntervalMinutes: 10},
+		},
+	}
+	require.True(t, sendValidateRequest(t, "SchedulePolicy", policy).Allowed)
+	policy.Policy.Interval.IntervalMinutes = 0
+	require.False(t, sendValidateRequest(t, "SchedulePolicy", policy).Allowed)
+
+	rule := &stork_api.Rule{
+		ObjectMeta: metav1.ObjectMeta{Name: "rule", Namespace: "test"},
+		Rules: []stork_api.RuleItem{{
+			Actions: []stork_api.RuleAction{{Type: stork_api.RuleActionCommand, Value: "sync"}},
+		}},
+	}
+	require.True(t, sendValidateRequest(t, "Rule", rule).Allowed)
+	rule.Rules[0].Actions[0].Type = "script"
+	require.False(t, sendValidateRequest(t, "Rule", rule).Allowed)
+
+	transform := &stork_api.ResourceTransformation{
+		ObjectMeta: metav1.ObjectMeta{Name: "transform", Namespace: "test"},
+		Spec: stork_api.ResourceTransformationSpec{
+			Objects: []stork_api.TransformSpecs{{
+				Resource: "apps/v1/Deployment",
+				Paths: []stork_api.ResourcePaths{{
+					Path:      "spec.replicas",
+					Operation: stork_api.ModifyResourcePathValue,
+					Type:      stork_api.IntResourceType,
+					Value:     "0",
+				}},
+			}},
+		},
+	}
+	require.True(t, sendValidateRequest(t, "ResourceTransformation", transform).Allowed)
+	transform.Spec.Objects[0].Paths[0].Operation = stork_api.JsonResourcePatch
+	require.False(t, sendValidateRequest(t, "ResourceTransformation", transform).Allowed)
+
+	// Other kinds are allowed
+	require.True(t, sendValidateRequest(t, "VolumeSnapshotRestore", &stork_api.VolumeSnapshotRestore{}).Allowed)
+}
+
+func TestValidateInvalidRequest(t *testing.T) {
+	c := &Controller{Recorder: record.NewFakeRecorder(10)}
+	w := httptest.NewRecorder()
+	c.serveHTTP(w, httptest.NewRequest(http.MethodPost, validateWebHook, bytes.NewReader([]byte("{"))))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -23,6 +23,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/record"
 )
 
@@ -50,12 +51,19 @@ type Controller struct {
 	lock         sync.Mutex
 	started      bool
 	SkipResource string
+	// KubeClient is used to register the validating webhook
+	KubeClient kubernetes.Interface
+	// AdminNamespace is the namespace of the admin ClusterPairs used by
+	// migrations
+	AdminNamespace string
 }
 
 // Serve method for webhook server
 func (c *Controller) serveHTTP(w http.ResponseWriter, req *http.Request) {
 	if strings.Contains(req.URL.Path, mutateWebHook) {
 		c.processMutateRequest(w, req)
+	} else if strings.Contains(req.URL.Path, validateWebHook) {
+		c.processValidateRequest(w, req)
 	} else {
 		http.Error(w, "Unsupported request", http.StatusNotFound)
 	}
@@ -296,6 +304,7 @@ func (c *Controller) Start() error {
 		TLSConfig: &tls.Config{Certificates: []tls.Certificate{tlsCert}}}
 
 	http.HandleFunc("/mutate", c.serveHTTP)
+	http.HandleFunc(validateWebHook, c.serveHTTP)
 	go func() {
 		if err := c.server.ListenAndServeTLS("", ""); err != http.ErrServerClosed {
 			log.Errorf("Error starting webhook server: %v", err)
@@ -303,7 +312,10 @@ func (c *Controller) Start() error {
 	}()
 	c.started = true
 	log.Debugf("Webhook server started")
-	return CreateMutateWebhook(caBundle, ns)
+	if err := CreateMutateWebhook(caBundle, ns); err != nil {
+		return err
+	}
+	return CreateValidateWebhook(c.KubeClient, caBundle, ns)
 }
 
 // Stop Stops the webhook server
@@ -318,6 +330,10 @@ func (c *Controller) Stop() error {
 		log.Errorf("unable to delete webhook configuration, %v", err)
 		return err
 	}
+	if err := DeleteValidateWebhook(c.KubeClient); err != nil {
+		log.Errorf("unable to delete validating webhook configuration, %v", err)
+		return err
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 